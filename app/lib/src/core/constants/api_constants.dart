@@ -17,7 +17,11 @@
  */
 
 class ApiConstants {
-  static const String baseUrl = 'http://localhost:8080/api/v1';
+  // Builds served by the backend itself pass --dart-define=API_BASE_URL=/api/v1
+  static const String baseUrl = String.fromEnvironment(
+    'API_BASE_URL',
+    defaultValue: 'http://localhost:8080/api/v1',
+  );
 
   // Auth endpoints
   static const String authRegister = '/auth/register';
@@ -0,0 +1,117 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/backup"
+	"github.com/rghsoftware/space-food/internal/config"
+)
+
+// runBackup handles `spacefood backup`, writing the database and uploaded
+// files to an archive
+func runBackup(ctx context.Context, cfg *config.Config, args []string) error {
+	flags := newFlagSet("backup", "[--output <file>]")
+	output := flags.String("output", "", "archive to write (default space-food-backup-<time>.zip)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return usageError(flags)
+	}
+	if *output == "" {
+		*output = "space-food-backup-" + time.Now().UTC().Format("20060102-150405") + ".zip"
+	}
+
+	db, err := openDatabase(ctx, cfg, true)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// Write next to the target and rename, so a failed backup never leaves
+	// a truncated archive under the expected name
+	tmp := *output + ".partial"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	manifest, err := backup.Write(ctx, cfg, db, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, *output)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	rows := 0
+	for _, n := range manifest.Tables {
+		rows += n
+	}
+	fmt.Printf("Wrote %s: schema version %d, %d tables, %d rows, %d uploads\n", *output, manifest.SchemaVersion, len(manifest.Tables), rows, manifest.Uploads)
+	return nil
+}
+
+// runRestore handles `spacefood restore`, replacing all data with a backup
+func runRestore(ctx context.Context, cfg *config.Config, args []string) error {
+	flags := newFlagSet("restore", "--yes <file>")
+	confirmed := flags.Bool("yes", false, "confirm that every table and uploaded file is replaced")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return usageError(flags)
+	}
+	if !*confirmed {
+		return errors.New("restoring replaces all data; pass --yes to confirm")
+	}
+
+	f, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	// The backup decides the schema version, so the current one may differ
+	db, err := openDatabase(ctx, cfg, false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	manifest, err := backup.Restore(ctx, cfg, db, f, info.Size())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Restored backup from %s: %d tables, %d uploads\n", manifest.CreatedAt.Local().Format(time.DateTime), len(manifest.Tables), manifest.Uploads)
+	return nil
+}
@@ -0,0 +1,121 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/rghsoftware/space-food/internal/config"
+)
+
+const configCommands = `
+commands:
+  init   write a commented config file with every setting at its default
+  check  load the configuration and list everything wrong with it
+`
+
+// runConfig handles `spacefood config <command>`. It runs before the
+// configuration is loaded, so it works while the configuration is invalid.
+func runConfig(ctx context.Context, _ *config.Config, args []string) error {
+	flags := newFlagSet("config", "<command>")
+	usage := flags.Usage
+	flags.Usage = func() {
+		usage()
+		fmt.Fprint(flags.Output(), configCommands)
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	args = flags.Args()
+	if len(args) == 0 {
+		return usageError(flags)
+	}
+
+	switch args[0] {
+	case "init":
+		return runConfigInit(args[1:])
+	case "check":
+		if len(args) != 1 {
+			return usageError(flags)
+		}
+		return runConfigCheck()
+	}
+	return usageError(flags)
+}
+
+// runConfigInit writes an example config file with a freshly generated
+// JWT secret, so it is ready to start with
+func runConfigInit(args []string) error {
+	flags := newFlagSet("config init", "[--output <file>] [--force]")
+	output := flags.String("output", "-", "file to write, - for standard output")
+	force := flags.Bool("force", false, "overwrite an existing file")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return usageError(flags)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+	values := map[string]any{"auth.jwtsecret": hex.EncodeToString(secret)}
+
+	if *output == "-" {
+		return config.WriteExample(os.Stdout, values)
+	}
+	mode := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if *force {
+		mode = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	// The file holds the JWT secret
+	f, err := os.OpenFile(*output, mode, 0o600)
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("%s already exists; pass --force to overwrite it", *output)
+	}
+	if err != nil {
+		return err
+	}
+	err = config.WriteExample(f, values)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %s\n", *output)
+	return nil
+}
+
+// runConfigCheck loads the configuration as the server would and reports
+// whether it is valid
+func runConfigCheck() error {
+	if _, err := config.Load(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return errReported
+	}
+	fmt.Println("Configuration is valid")
+	return nil
+}
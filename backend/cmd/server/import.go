@@ -0,0 +1,205 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/rghsoftware/space-food/internal/config"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/images"
+	"github.com/rghsoftware/space-food/internal/storage"
+)
+
+// runImport handles `spacefood import recipes`. The file is a JSON recipe,
+// a JSON array of recipes, or a zip of such files, in the format the
+// recipes API returns. Every recipe is imported or none is. Images hosted
+// elsewhere are copied into storage unless storage.importimages is off.
+func runImport(ctx context.Context, cfg *config.Config, args []string) error {
+	flags := newFlagSet("import", "recipes --user <email> <file>")
+	owner := flags.String("user", "", "email of the user who will own the recipes")
+	if len(args) == 0 || args[0] != "recipes" {
+		return usageError(flags)
+	}
+	if err := parseFlags(flags, args[1:]); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 || *owner == "" {
+		return usageError(flags)
+	}
+
+	recipes, err := readRecipes(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	db, err := openDatabase(ctx, cfg, true)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	user, err := db.GetUserByEmail(ctx, *owner)
+	if err != nil {
+		return fmt.Errorf("user %s not found: %w", *owner, err)
+	}
+
+	store, err := storage.New(cfg.Storage)
+	if err != nil {
+		return err
+	}
+	saved, err := importImages(ctx, cfg, store, user.ID, recipes)
+	if err != nil {
+		return err
+	}
+
+	err = db.WithTx(ctx, func(ctx context.Context) error {
+		for _, recipe := range recipes {
+			// IDs from another instance are replaced with new ones
+			recipe.ID = ""
+			recipe.UserID = user.ID
+			for i := range recipe.Ingredients {
+				recipe.Ingredients[i].ID = ""
+				recipe.Ingredients[i].RecipeID = ""
+			}
+			if err := db.CreateRecipe(ctx, recipe); err != nil {
+				return fmt.Errorf("failed to import %q: %w", recipe.Title, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		removeImages(store, saved)
+		return err
+	}
+
+	fmt.Printf("Imported %d recipes for %s, %d images\n", len(recipes), user.Email, len(saved))
+	return nil
+}
+
+// importImages replaces remote ImageURLs with copies in storage and returns
+// the new URLs. An image that can't be fetched is dropped rather than left
+// hotlinked.
+func importImages(ctx context.Context, cfg *config.Config, store storage.Provider, userID string, recipes []*database.Recipe) ([]string, error) {
+	if !cfg.Storage.ImportImages {
+		return nil, nil
+	}
+	downloader := images.NewDownloader(cfg, store)
+	var saved []string
+	for _, recipe := range recipes {
+		if !images.IsRemote(recipe.ImageURL) {
+			continue
+		}
+		imageURL, err := downloader.Download(ctx, userID, recipe.ImageURL)
+		if ctx.Err() != nil {
+			removeImages(store, saved)
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Skipping image of %q: %v\n", recipe.Title, err)
+			recipe.ImageURL = ""
+			continue
+		}
+		recipe.ImageURL = imageURL
+		saved = append(saved, imageURL)
+	}
+	return saved, nil
+}
+
+// removeImages deletes images copied for an import that failed. It ignores
+// cancellation so an interrupted import still cleans up.
+func removeImages(store storage.Provider, imageURLs []string) {
+	for _, imageURL := range imageURLs {
+		_ = images.Remove(context.Background(), store, imageURL)
+	}
+}
+
+// readRecipes reads the recipes in a JSON file or in the JSON files of a
+// zip archive
+func readRecipes(name string) ([]*database.Recipe, error) {
+	if !strings.EqualFold(path.Ext(name), ".zip") {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		return decodeRecipes(name, data)
+	}
+
+	zr, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	var recipes []*database.Recipe
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || !strings.EqualFold(path.Ext(f.Name), ".json") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		decoded, err := decodeRecipes(f.Name, data)
+		if err != nil {
+			return nil, err
+		}
+		recipes = append(recipes, decoded...)
+	}
+	if len(recipes) == 0 {
+		return nil, fmt.Errorf("%s contains no JSON recipes", name)
+	}
+	return recipes, nil
+}
+
+// decodeRecipes accepts a single recipe object or an array of them
+func decodeRecipes(name string, data []byte) ([]*database.Recipe, error) {
+	var recipes []*database.Recipe
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &recipes); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	} else {
+		var recipe database.Recipe
+		if err := json.Unmarshal(trimmed, &recipe); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		recipes = append(recipes, &recipe)
+	}
+
+	for _, recipe := range recipes {
+		if recipe == nil || strings.TrimSpace(recipe.Title) == "" {
+			return nil, fmt.Errorf("%s: every recipe needs a title", name)
+		}
+	}
+	return recipes, nil
+}
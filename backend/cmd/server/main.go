@@ -16,84 +16,168 @@
  * along with this program. If not, see <https://www.gnu.org/licenses/>.
  */
 
+// Command spacefood runs the Space Food API server and its administration
+// commands.
 package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
-	"os/signal"
-	"syscall"
-	"time"
+	"strings"
 
-	"github.com/rghsoftware/space-food/internal/api/rest"
-	"github.com/rghsoftware/space-food/internal/auth/argon2"
 	"github.com/rghsoftware/space-food/internal/config"
 	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/database/migrate"
+	"github.com/rghsoftware/space-food/internal/dbfactory"
 	"github.com/rghsoftware/space-food/pkg/logger"
 )
 
-func main() {
-	// Load configuration
-	cfg, err := config.Load()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
-		os.Exit(1)
-	}
+// errUsage is returned by a command after it printed its usage
+var errUsage = errors.New("invalid arguments")
 
-	// Initialize logger
-	logger.Init(cfg.Logging.Level, cfg.Logging.Format)
-	log := logger.Get()
+// errReported is returned by a command that failed after it printed why
+var errReported = errors.New("failed")
 
-	log.Info().Msg("Starting Space Food API server")
+// command is one `spacefood` subcommand
+type command struct {
+	name    string
+	args    string // argument synopsis shown in usage
+	summary string
+	run     func(ctx context.Context, cfg *config.Config, args []string) error
+}
 
-	// Initialize database
-	db, err := database.NewDatabase(cfg)
-	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to create database")
+var commands []command
+
+func init() {
+	commands = []command{
+		{"serve", "", "run the API server (the default)", runServe},
+		{"migrate", "<command>", "show, apply or revert schema migrations", runMigrate},
+		{"user", "create --email <email> [--admin]", "manage user accounts", runUser},
+		{"backup", "[--output <file>]", "write all data to a backup archive", runBackup},
+		{"restore", "--yes <file>", "replace all data with a backup archive", runRestore},
+		{"import", "recipes --user <email> <file>", "import recipes from a JSON or zip file", runImport},
+		{"config", "init|check", "write an example config file or check the current one", runConfig},
 	}
+}
 
-	ctx := context.Background()
-	if err := db.Connect(ctx); err != nil {
-		log.Fatal().Err(err).Msg("Failed to connect to database")
+func main() {
+	// Without a command the server runs, as it always has
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 {
+		switch {
+		case args[0] == "help" || args[0] == "-h" || args[0] == "--help":
+			printUsage(os.Stdout)
+			return
+		case !strings.HasPrefix(args[0], "-"):
+			name, args = args[0], args[1:]
+		}
 	}
-	defer db.Close()
-
-	log.Info().Msg("Connected to database")
 
-	// Run migrations
-	if err := db.Migrate(ctx); err != nil {
-		log.Fatal().Err(err).Msg("Failed to run migrations")
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == name {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		printUsage(os.Stderr)
+		os.Exit(2)
 	}
 
-	log.Info().Msg("Database migrations completed")
+	// Load configuration. The config command goes without, as it helps
+	// write and fix one.
+	cfg := &config.Config{}
+	if cmd.name != "config" {
+		var err error
+		if cfg, err = config.Load(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
-	// Initialize authentication provider
-	authProvider := argon2.NewArgon2AuthProvider(db, cfg)
+	// Initialize logger
+	logger.Init(cfg.Logging.Level, cfg.Logging.Format)
 
-	// Setup router
-	router := rest.SetupRouter(db, authProvider)
+	if err := cmd.run(context.Background(), cfg, args); err != nil {
+		switch {
+		case errors.Is(err, flag.ErrHelp):
+			return
+		case errors.Is(err, errUsage):
+			os.Exit(2)
+		case errors.Is(err, errReported):
+			os.Exit(1)
+		}
+		logger.Get().Fatal().Err(err).Str("command", name).Msg("Command failed")
+	}
+}
 
-	// Start server
-	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
-	log.Info().Str("address", addr).Msg("Starting HTTP server")
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: spacefood <command> [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-8s %-36s %s\n", cmd.name, cmd.args, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Configuration is read from config.yaml and SPACE_FOOD_* environment variables.")
+}
 
-	// Graceful shutdown
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+// newFlagSet creates the flags of a command, printing usage for args
+func newFlagSet(name, args string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: spacefood %s %s\n", name, args)
+		flags.PrintDefaults()
+	}
+	return flags
+}
 
-	go func() {
-		if err := router.Run(addr); err != nil {
-			log.Fatal().Err(err).Msg("Failed to start server")
+// parseFlags parses a command's flags; on failure the flag package has
+// already printed the problem and the usage
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
 		}
-	}()
+		return errUsage
+	}
+	return nil
+}
 
-	<-quit
-	log.Info().Msg("Shutting down server...")
+// usageError prints a command's usage and returns errUsage
+func usageError(flags *flag.FlagSet) error {
+	flags.Usage()
+	return errUsage
+}
 
-	// Give time for cleanup
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+// openDatabase connects to the configured database. Unless the caller
+// manages the schema itself, the schema must match this build.
+func openDatabase(ctx context.Context, cfg *config.Config, checkSchema bool) (database.Database, error) {
+	db, err := dbfactory.NewDatabase(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create database: %w", err)
+	}
+	if err := db.Connect(ctx); err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	if checkSchema {
+		if err := db.Migrations().Check(ctx); err != nil {
+			db.Close()
+			return nil, schemaError(err)
+		}
+	}
+	return db, nil
+}
 
-	log.Info().Msg("Server stopped")
+// schemaError explains how to resolve a schema that does not match
+func schemaError(err error) error {
+	if errors.Is(err, migrate.ErrPending) {
+		return fmt.Errorf("%w; run `spacefood migrate up` to apply them", err)
+	}
+	return err
 }
@@ -0,0 +1,136 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/rghsoftware/space-food/internal/config"
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+const migrateCommands = `
+commands:
+  status           list migrations and whether each is applied
+  up               apply all pending migrations
+  down [n]         revert the n most recent migrations (default 1)
+  to <version>     apply or revert migrations until version is the newest applied
+  force <version>  record version as the newest applied without running anything
+`
+
+// runMigrate handles `spacefood migrate <command>`
+func runMigrate(ctx context.Context, cfg *config.Config, args []string) error {
+	flags := newFlagSet("migrate", "<command>")
+	usage := flags.Usage
+	flags.Usage = func() {
+		usage()
+		fmt.Fprint(flags.Output(), migrateCommands)
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	args = flags.Args()
+	if len(args) == 0 {
+		return usageError(flags)
+	}
+
+	// Check nothing: fixing a mismatched schema is what this command is for
+	db, err := openDatabase(ctx, cfg, false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	migrator := db.Migrations()
+
+	switch args[0] {
+	case "status":
+		if len(args) != 1 {
+			return usageError(flags)
+		}
+		return printMigrationStatus(ctx, db)
+	case "up":
+		if len(args) != 1 {
+			return usageError(flags)
+		}
+		return migrator.Up(ctx)
+	case "down":
+		n := 1
+		if len(args) == 2 {
+			if n, err = strconv.Atoi(args[1]); err != nil || n < 1 {
+				return fmt.Errorf("invalid number of migrations: %s", args[1])
+			}
+		} else if len(args) != 1 {
+			return usageError(flags)
+		}
+		return migrator.Down(ctx, n)
+	case "to", "force":
+		if len(args) != 2 {
+			return usageError(flags)
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil || version < 0 {
+			return fmt.Errorf("invalid version: %s", args[1])
+		}
+		if args[0] == "force" {
+			return migrator.Force(ctx, version)
+		}
+		return migrator.To(ctx, version)
+	default:
+		return usageError(flags)
+	}
+}
+
+// printMigrationStatus writes a table of migrations to stdout
+func printMigrationStatus(ctx context.Context, db database.Database) error {
+	migrator := db.Migrations()
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	current, err := migrator.Version(ctx)
+	if err != nil {
+		return err
+	}
+
+	pending := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS")
+	for _, s := range statuses {
+		state := "applied"
+		switch {
+		case s.Unknown:
+			state = "applied, unknown to this build"
+		case !s.Applied:
+			state = "pending"
+			pending++
+		}
+		fmt.Fprintf(w, "%03d\t%s\t%s\n", s.Version, s.Name, state)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nschema version %d, this build knows up to %d, %d pending\n", current, migrator.Latest(), pending)
+	return nil
+}
@@ -0,0 +1,199 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/api/rest"
+	"github.com/rghsoftware/space-food/internal/auth"
+	"github.com/rghsoftware/space-food/internal/auth/argon2"
+	"github.com/rghsoftware/space-food/internal/auth/oidc"
+	"github.com/rghsoftware/space-food/internal/config"
+	"github.com/rghsoftware/space-food/internal/events"
+	"github.com/rghsoftware/space-food/internal/features/setup"
+	"github.com/rghsoftware/space-food/internal/health"
+	"github.com/rghsoftware/space-food/internal/mqtt"
+	"github.com/rghsoftware/space-food/internal/scheduler"
+	"github.com/rghsoftware/space-food/internal/storage"
+	"github.com/rghsoftware/space-food/internal/telemetry"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// runServe runs the API server until SIGINT or SIGTERM
+func runServe(ctx context.Context, cfg *config.Config, args []string) error {
+	flags := newFlagSet("serve", "")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return usageError(flags)
+	}
+
+	log := logger.Get()
+
+	log.Info().Msg("Starting Space Food API server")
+
+	// Start tracing first so startup queries are traced too
+	var tracer *telemetry.Tracer
+	if cfg.Telemetry.Tracing.Enabled {
+		tracer = telemetry.NewTracer(cfg.Telemetry.Tracing)
+		tracer.Start(context.Background())
+		log.Info().Str("endpoint", cfg.Telemetry.Tracing.Endpoint).Float64("sample_ratio", cfg.Telemetry.Tracing.SampleRatio).Msg("Tracing enabled")
+	}
+
+	// Initialize database
+	db, err := openDatabase(ctx, cfg, false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	log.Info().Msg("Connected to database")
+
+	// Run migrations; a schema newer than this build always stops startup.
+	// An in-memory database starts empty, so it is always migrated.
+	if cfg.Database.Type == "memory" {
+		log.Warn().Msg("Using an in-memory database; all data is lost when the server stops")
+	}
+	if cfg.Database.AutoMigrate || cfg.Database.Type == "memory" {
+		if err := db.Migrate(ctx); err != nil {
+			return fmt.Errorf("failed to run migrations: %w", err)
+		}
+		log.Info().Msg("Database migrations completed")
+	} else if err := db.Migrations().Check(ctx); err != nil {
+		return schemaError(err)
+	}
+
+	// Initialize authentication provider
+	passwordProvider := argon2.NewArgon2AuthProvider(db, cfg)
+	var authProvider auth.AuthProvider = passwordProvider
+	if cfg.Auth.OIDC.Enabled {
+		authProvider = oidc.NewOIDCAuthProvider(db, cfg, passwordProvider)
+		log.Info().Str("issuer", cfg.Auth.OIDC.IssuerURL).Bool("password_login", !cfg.Auth.DisablePasswordLogin).Msg("OIDC single sign-on enabled")
+	}
+
+	// File storage for uploaded images
+	store, err := storage.New(cfg.Storage)
+	if err != nil {
+		return fmt.Errorf("failed to set up storage: %w", err)
+	}
+
+	// Setup router; features register their background jobs as they are wired up
+	jobScheduler := scheduler.NewScheduler(db, cfg.Jobs)
+	eventBus := events.NewBus()
+	healthChecker := health.NewChecker(cfg, db)
+	live := config.NewLive(cfg)
+	live.OnReload(func(cfg *config.Config) {
+		logger.SetLevel(cfg.Logging.Level)
+		healthChecker.SetAI(cfg.AI)
+	})
+	// The AI provider chosen in the setup wizard applies over the file
+	if err := setup.RestoreAI(ctx, db, live); err != nil {
+		return fmt.Errorf("failed to restore setup choices: %w", err)
+	}
+	if err := setup.IssueToken(ctx, db); err != nil {
+		return fmt.Errorf("failed to issue a setup token: %w", err)
+	}
+	router := rest.SetupRouter(cfg, live, db, store, authProvider, jobScheduler, eventBus, healthChecker)
+
+	// Reload safe-to-change settings when the config file changes or on
+	// SIGHUP
+	live.Watch(ctx)
+
+	// Publish events to MQTT for home automation
+	var mqttClient *mqtt.Client
+	if cfg.MQTT.Enabled {
+		mqttClient = mqtt.NewClient(cfg.MQTT)
+		mqttClient.Start(ctx)
+		eventBus.Subscribe(mqtt.NewPublisher(mqttClient, cfg.MQTT.TopicPrefix).Handle)
+	}
+
+	// Start background jobs
+	if cfg.Jobs.Enabled {
+		if err := jobScheduler.Start(ctx); err != nil {
+			return fmt.Errorf("failed to start job scheduler: %w", err)
+		}
+		log.Info().Msg("Job scheduler started")
+	}
+
+	// Start server
+	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
+	log.Info().Str("address", addr).Msg("Starting HTTP server")
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           router,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Graceful shutdown
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal().Err(err).Msg("Failed to start server")
+		}
+	}()
+
+	<-quit
+	log.Info().Msg("Shutting down server...")
+
+	// Fail readiness and keep serving for a while, so load balancers notice
+	// and stop sending traffic before connections are refused. A second
+	// signal skips the wait.
+	healthChecker.MarkShuttingDown()
+	if delay := time.Duration(cfg.Server.ShutdownDelay) * time.Second; delay > 0 {
+		log.Info().Dur("delay", delay).Msg("Waiting for load balancers to stop sending traffic")
+		select {
+		case <-time.After(delay):
+		case <-quit:
+		}
+	}
+
+	// One deadline covers draining requests and the background work they
+	// started
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(max(cfg.Server.ShutdownTimeout, 1))*time.Second)
+	defer cancel()
+
+	// Stop accepting connections and wait for in-flight requests
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Warn().Err(err).Msg("Stopped waiting for in-flight requests to finish")
+	}
+
+	jobScheduler.Stop(shutdownCtx)
+	eventBus.Wait(shutdownCtx)
+	if mqttClient != nil {
+		mqttClient.Close()
+	}
+	if tracer != nil {
+		tracer.Shutdown(shutdownCtx)
+	}
+
+	log.Info().Msg("Server stopped")
+	return nil
+}
@@ -0,0 +1,82 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rghsoftware/space-food/internal/auth"
+	"github.com/rghsoftware/space-food/internal/auth/argon2"
+	"github.com/rghsoftware/space-food/internal/config"
+)
+
+// runUser handles `spacefood user create`, which adds an account regardless
+// of the registration mode
+func runUser(ctx context.Context, cfg *config.Config, args []string) error {
+	flags := newFlagSet("user", "create --email <email> [--admin] [--first-name <name>] [--last-name <name>] [--password <password>]")
+	email := flags.String("email", "", "email address to sign in with")
+	firstName := flags.String("first-name", "", "first name")
+	lastName := flags.String("last-name", "", "last name")
+	password := flags.String("password", "", "password; a temporary one is generated and printed when empty")
+	admin := flags.Bool("admin", false, "make the user an instance administrator")
+	if len(args) == 0 || args[0] != "create" {
+		return usageError(flags)
+	}
+	if err := parseFlags(flags, args[1:]); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 || *email == "" {
+		return usageError(flags)
+	}
+
+	generated := *password == ""
+	if generated {
+		var err error
+		if *password, err = auth.GenerateTemporaryPassword(); err != nil {
+			return err
+		}
+	}
+
+	db, err := openDatabase(ctx, cfg, true)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	user, err := argon2.NewArgon2AuthProvider(db, cfg).CreateUser(ctx, auth.RegisterRequest{
+		Email:     *email,
+		Password:  *password,
+		FirstName: *firstName,
+		LastName:  *lastName,
+	}, *admin)
+	if err != nil {
+		return err
+	}
+
+	role := "user"
+	if user.IsAdmin {
+		role = "administrator"
+	}
+	fmt.Printf("Created %s %s (%s)\n", role, user.Email, user.ID)
+	if generated {
+		fmt.Printf("Temporary password: %s\n", *password)
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package ai generates text with the configured AI provider. Features use
+// it for what rules and curated tables can't answer, and fall back to
+// non-AI behaviour when no provider is configured.
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/config"
+)
+
+// maxResponseSize bounds what is read back from a provider
+const maxResponseSize = 4 << 20
+
+// ErrEmptyResponse is returned when a provider answers without any text
+var ErrEmptyResponse = errors.New("AI provider returned no text")
+
+// Request is one prompt for a provider
+type Request struct {
+	System    string // instructions that frame the prompt
+	Prompt    string
+	JSON      bool // ask for a JSON object; the prompt should describe its shape
+	MaxTokens int  // 0 uses defaultMaxTokens
+}
+
+// defaultMaxTokens caps the length of a response when a request doesn't
+const defaultMaxTokens = 1024
+
+// Response is a provider's answer with the tokens it counted, for usage
+// tracking. Provider and Model name the provider that answered, which is
+// not the first one tried when it failed over.
+type Response struct {
+	Text         string
+	Provider     string
+	Model        string
+	InputTokens  int64
+	OutputTokens int64
+}
+
+// Provider generates text from prompts
+type Provider interface {
+	Generate(ctx context.Context, req Request) (*Response, error)
+	// Name is the provider's config name, e.g. ollama; usage is recorded
+	// against it
+	Name() string
+	// Model identifies the model, so cached outputs are not reused after
+	// it changes
+	Model() string
+}
+
+// NewProvider creates the active provider, or nil when none is usable.
+// When ai.fallback names other usable providers, it returns a chain that
+// tries them in turn.
+func NewProvider(cfg config.AIConfig) Provider {
+	return buildProvider(cfg, func(provider Provider) Provider { return provider })
+}
+
+// buildProvider creates the active provider or chain, passing each
+// provider in it through wrap
+func buildProvider(cfg config.AIConfig, wrap func(Provider) Provider) Provider {
+	client := &http.Client{Timeout: time.Duration(cfg.Timeout) * time.Second}
+	providers := []Provider{}
+	for _, name := range cfg.ProviderChain() {
+		providers = append(providers, wrap(newProvider(name, cfg, client)))
+	}
+	switch len(providers) {
+	case 0:
+		return nil
+	case 1:
+		return providers[0]
+	}
+	return newFailover(providers, cfg)
+}
+
+// newProvider creates one provider by its config name
+func newProvider(name string, cfg config.AIConfig, client *http.Client) Provider {
+	switch name {
+	case "ollama":
+		return &ollama{host: strings.TrimRight(cfg.Ollama.Host, "/"), model: cfg.Ollama.Model, client: client}
+	case "openai":
+		return &openAI{
+			baseURL: strings.TrimRight(cfg.OpenAI.BaseURL, "/"),
+			apiKey:  cfg.OpenAI.APIKey,
+			model:   cfg.OpenAI.Model,
+			client:  client,
+		}
+	case "gemini":
+		return &gemini{apiKey: cfg.Gemini.APIKey, model: cfg.Gemini.Model, client: client}
+	case "claude":
+		return &claude{apiKey: cfg.Claude.APIKey, model: cfg.Claude.Model, client: client}
+	}
+	return nil
+}
+
+// Source hands out the active provider, which changes when the AI settings
+// are reloaded. Features hold a Source and ask it for the provider on each
+// request.
+type Source struct {
+	current  atomic.Value // sourced
+	observer atomic.Pointer[Observer]
+}
+
+type sourced struct{ provider Provider }
+
+// NewSource creates a source of the provider the settings make active
+func NewSource(cfg config.AIConfig) *Source {
+	s := &Source{}
+	s.Reload(cfg)
+	return s
+}
+
+// Reload switches to the provider reloaded settings make active. Requests
+// already talking to the previous provider finish with it.
+func (s *Source) Reload(cfg config.AIConfig) {
+	s.current.Store(sourced{buildProvider(cfg, func(provider Provider) Provider {
+		return &observed{Provider: provider, source: s}
+	})})
+}
+
+// Observe has every call to a provider from this source reported to fn,
+// including each attempt of a failover and each retry
+func (s *Source) Observe(fn Observer) {
+	s.observer.Store(&fn)
+}
+
+// Provider returns the active provider, or nil when none is usable
+func (s *Source) Provider() Provider {
+	return s.current.Load().(sourced).provider
+}
+
+// postJSON sends a JSON request to a provider and decodes its answer into
+// dest
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, body, dest any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("AI provider returned %d: %s", resp.StatusCode, truncate(string(data), 200))
+	}
+	if err := json.Unmarshal(data, dest); err != nil {
+		return fmt.Errorf("failed to decode AI provider response: %w", err)
+	}
+	return nil
+}
+
+// truncate shortens text for error messages
+func truncate(text string, n int) string {
+	if len(text) <= n {
+		return text
+	}
+	return text[:n] + "…"
+}
+
+func maxTokens(req Request) int {
+	if req.MaxTokens > 0 {
+		return req.MaxTokens
+	}
+	return defaultMaxTokens
+}
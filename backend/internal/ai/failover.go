@@ -0,0 +1,125 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/config"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// failover tries an ordered chain of providers until one answers. A
+// provider that fails is tried after the healthy ones until ai.cooldown
+// has passed, so a provider that is down doesn't cost every request its
+// timeout. Names and models are the first provider's, so cached outputs
+// stay keyed to it; responses name the provider that actually answered.
+type failover struct {
+	providers []Provider
+	attempt   time.Duration // longest one provider is given, ai.timeout
+	deadline  time.Duration // longest the whole chain is given, ai.deadline
+	cooldown  time.Duration
+
+	mu     sync.Mutex
+	failed map[string]time.Time // provider name to when it last failed
+}
+
+func newFailover(providers []Provider, cfg config.AIConfig) *failover {
+	return &failover{
+		providers: providers,
+		attempt:   time.Duration(cfg.Timeout) * time.Second,
+		deadline:  time.Duration(max(cfg.Deadline, cfg.Timeout)) * time.Second,
+		cooldown:  time.Duration(cfg.Cooldown) * time.Second,
+		failed:    map[string]time.Time{},
+	}
+}
+
+func (f *failover) Name() string  { return f.providers[0].Name() }
+func (f *failover) Model() string { return f.providers[0].Model() }
+
+// Generate asks each provider in turn until one answers or ai.deadline
+// runs out. Each attempt gets ai.timeout or whatever is left of the
+// deadline, whichever is shorter.
+func (f *failover) Generate(ctx context.Context, req Request) (*Response, error) {
+	chainCtx, cancel := context.WithTimeout(ctx, f.deadline)
+	defer cancel()
+	deadline, _ := chainCtx.Deadline()
+
+	var errs []error
+	for _, provider := range f.order() {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			errs = append(errs, fmt.Errorf("%s: not tried, out of time", provider.Name()))
+			break
+		}
+
+		attemptCtx, cancelAttempt := context.WithTimeout(chainCtx, min(f.attempt, remaining))
+		resp, err := provider.Generate(attemptCtx, req)
+		cancelAttempt()
+		if err == nil {
+			f.markHealthy(provider)
+			return resp, nil
+		}
+		// The caller gave up; that says nothing about the provider
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		f.markFailed(provider)
+		errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+		logger.Ctx(ctx).Warn().Err(err).Str("provider", provider.Name()).Msg("AI provider failed")
+	}
+	return nil, errors.Join(errs...)
+}
+
+// order lists the healthy providers first, then those still cooling down
+// after a failure, each in chain order
+func (f *failover) order() []Provider {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	healthy := make([]Provider, 0, len(f.providers))
+	cooling := []Provider{}
+	for _, provider := range f.providers {
+		if failedAt, ok := f.failed[provider.Name()]; ok && time.Since(failedAt) < f.cooldown {
+			cooling = append(cooling, provider)
+		} else {
+			healthy = append(healthy, provider)
+		}
+	}
+	return append(healthy, cooling...)
+}
+
+// markFailed marks a provider as failing from now
+func (f *failover) markFailed(provider Provider) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failed[provider.Name()] = time.Now()
+}
+
+// markHealthy marks a provider as healthy again
+func (f *failover) markHealthy(provider Provider) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.failed, provider.Name())
+}
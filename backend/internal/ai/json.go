@@ -0,0 +1,187 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// maxRepairEcho bounds how much of an unusable answer is shown back to the
+// provider when asking for a repair
+const maxRepairEcho = 4000
+
+// repairPrompt follows an unusable answer when asking again
+const repairPrompt = `
+
+Your previous answer was:
+%s
+
+It could not be used: %s
+Return only valid JSON in the shape asked for, with no other text.`
+
+// Validator is implemented by answers with rules beyond their JSON shape,
+// such as required fields or bounds on counts. DecodeJSON fails, and
+// GenerateJSON asks again, when Validate returns an error.
+type Validator interface {
+	Validate() error
+}
+
+// GenerateJSON asks the provider for a JSON answer and decodes it into
+// dest. When the answer can't be decoded or fails validation, the provider
+// is asked once more with its answer and what was wrong with it. The
+// response is returned whenever the provider answered, even with an
+// unusable answer; after a retry it counts the tokens of both requests and
+// names the provider of the second.
+func GenerateJSON(ctx context.Context, provider Provider, req Request, dest any) (*Response, error) {
+	// dest may carry settings its Validate uses, so it is put back as given
+	// before the second answer is decoded
+	initial := reflect.ValueOf(dest).Elem().Interface()
+
+	req.JSON = true
+	resp, err := provider.Generate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	decodeErr := DecodeJSON(resp.Text, dest)
+	if decodeErr == nil {
+		return resp, nil
+	}
+
+	retry := req
+	retry.Prompt += fmt.Sprintf(repairPrompt, truncate(resp.Text, maxRepairEcho), decodeErr)
+	repaired, err := provider.Generate(ctx, retry)
+	if err != nil {
+		return resp, decodeErr
+	}
+	repaired.InputTokens += resp.InputTokens
+	repaired.OutputTokens += resp.OutputTokens
+
+	reflect.ValueOf(dest).Elem().Set(reflect.ValueOf(initial))
+	if err := DecodeJSON(repaired.Text, dest); err != nil {
+		return repaired, err
+	}
+	return repaired, nil
+}
+
+// DecodeJSON reads a JSON response into dest and validates it when dest is
+// a Validator. Models sometimes wrap JSON in a Markdown code fence, add a
+// sentence around it, leave a trailing comma or break a line inside a
+// string, so the first object is picked out and those slips repaired
+// before decoding.
+func DecodeJSON(text string, dest any) error {
+	object, err := extractJSON(text)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(repairJSON(object)), dest); err != nil {
+		return fmt.Errorf("failed to decode AI response: %w", err)
+	}
+	if v, ok := dest.(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("AI response is unusable: %w", err)
+		}
+	}
+	return nil
+}
+
+// extractJSON returns the first JSON object in text, looking inside a
+// Markdown code fence when there is one
+func extractJSON(text string) (string, error) {
+	if _, fenced, ok := strings.Cut(text, "```"); ok {
+		// Skip the fence's language tag, e.g. ```json
+		if _, body, ok := strings.Cut(fenced, "\n"); ok {
+			if body, _, ok := strings.Cut(body, "```"); ok && strings.Contains(body, "{") {
+				text = body
+			}
+		}
+	}
+
+	start := strings.Index(text, "{")
+	if start < 0 {
+		return "", fmt.Errorf("AI response is not JSON: %q", truncate(text, 100))
+	}
+	depth, inString, escaped := 0, false, false
+	for i := start; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			depth--
+			if depth == 0 {
+				return text[start : i+1], nil
+			}
+		}
+	}
+	return "", fmt.Errorf("AI response has incomplete JSON: %q", truncate(text[start:], 100))
+}
+
+// repairJSON drops trailing commas before a closing bracket and escapes
+// line breaks and tabs inside strings
+func repairJSON(object string) string {
+	var b strings.Builder
+	b.Grow(len(object))
+	inString, escaped := false, false
+	for i := 0; i < len(object); i++ {
+		c := object[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			case c == '\n':
+				b.WriteString(`\n`)
+				continue
+			case c == '\r':
+				continue
+			case c == '\t':
+				b.WriteString(`\t`)
+				continue
+			}
+			b.WriteByte(c)
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case ',':
+			next := strings.TrimLeft(object[i+1:], " \t\r\n")
+			if strings.HasPrefix(next, "}") || strings.HasPrefix(next, "]") {
+				continue
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
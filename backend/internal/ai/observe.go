@@ -0,0 +1,82 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package ai
+
+import (
+	"context"
+	"time"
+)
+
+// Invocation describes one call to a provider: a single attempt, so a
+// request that fails over or is retried makes several
+type Invocation struct {
+	Provider     string
+	Model        string
+	InputTokens  int64
+	OutputTokens int64
+	Latency      time.Duration
+	Err          error // nil when the provider answered
+}
+
+// Observer is told about every provider call, for usage accounting
+type Observer func(ctx context.Context, invocation Invocation)
+
+type callerKey struct{}
+
+type caller struct{ userID, feature string }
+
+// WithCaller notes on ctx who a provider call is made for and by which
+// feature, so observers can attribute it
+func WithCaller(ctx context.Context, userID, feature string) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller{userID, feature})
+}
+
+// Caller returns the user and feature noted by WithCaller, empty when none
+// was
+func Caller(ctx context.Context) (userID, feature string) {
+	c, _ := ctx.Value(callerKey{}).(caller)
+	return c.userID, c.feature
+}
+
+// observed reports each call to a provider to the source's observer
+type observed struct {
+	Provider
+	source *Source
+}
+
+func (o *observed) Generate(ctx context.Context, req Request) (*Response, error) {
+	start := time.Now()
+	resp, err := o.Provider.Generate(ctx, req)
+	observer := o.source.observer.Load()
+	if observer == nil {
+		return resp, err
+	}
+
+	invocation := Invocation{
+		Provider: o.Name(),
+		Model:    o.Model(),
+		Latency:  time.Since(start),
+		Err:      err,
+	}
+	if resp != nil {
+		invocation.InputTokens, invocation.OutputTokens = resp.InputTokens, resp.OutputTokens
+	}
+	(*observer)(ctx, invocation)
+	return resp, err
+}
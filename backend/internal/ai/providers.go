@@ -0,0 +1,223 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package ai
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// message is a chat message in the formats of Ollama, OpenAI and Claude
+type message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// chatMessages puts the system prompt and the prompt in chat order
+func chatMessages(req Request) []message {
+	messages := []message{}
+	if req.System != "" {
+		messages = append(messages, message{Role: "system", Content: req.System})
+	}
+	return append(messages, message{Role: "user", Content: req.Prompt})
+}
+
+// ollama generates with a self-hosted Ollama server
+type ollama struct {
+	host   string
+	model  string
+	client *http.Client
+}
+
+func (o *ollama) Name() string  { return "ollama" }
+func (o *ollama) Model() string { return "ollama/" + o.model }
+
+func (o *ollama) Generate(ctx context.Context, req Request) (*Response, error) {
+	body := map[string]any{
+		"model":    o.model,
+		"messages": chatMessages(req),
+		"stream":   false,
+		"options":  map[string]any{"num_predict": maxTokens(req)},
+	}
+	if req.JSON {
+		body["format"] = "json"
+	}
+	var out struct {
+		Message         message `json:"message"`
+		PromptEvalCount int64   `json:"prompt_eval_count"`
+		EvalCount       int64   `json:"eval_count"`
+	}
+	if err := postJSON(ctx, o.client, o.host+"/api/chat", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return response(o, out.Message.Content, out.PromptEvalCount, out.EvalCount)
+}
+
+// openAI generates with OpenAI's chat completions API, or a server that
+// speaks it
+type openAI struct {
+	baseURL string
+	apiKey  string
+	model   string
+	client  *http.Client
+}
+
+func (o *openAI) Name() string  { return "openai" }
+func (o *openAI) Model() string { return "openai/" + o.model }
+
+func (o *openAI) Generate(ctx context.Context, req Request) (*Response, error) {
+	body := map[string]any{
+		"model":      o.model,
+		"messages":   chatMessages(req),
+		"max_tokens": maxTokens(req),
+	}
+	if req.JSON {
+		body["response_format"] = map[string]string{"type": "json_object"}
+	}
+	var out struct {
+		Choices []struct {
+			Message message `json:"message"`
+		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int64 `json:"prompt_tokens"`
+			CompletionTokens int64 `json:"completion_tokens"`
+		} `json:"usage"`
+	}
+	headers := map[string]string{"Authorization": "Bearer " + o.apiKey}
+	if err := postJSON(ctx, o.client, o.baseURL+"/chat/completions", headers, body, &out); err != nil {
+		return nil, err
+	}
+	text := ""
+	if len(out.Choices) > 0 {
+		text = out.Choices[0].Message.Content
+	}
+	return response(o, text, out.Usage.PromptTokens, out.Usage.CompletionTokens)
+}
+
+// gemini generates with Google's Gemini API
+type gemini struct {
+	apiKey string
+	model  string
+	client *http.Client
+}
+
+func (g *gemini) Name() string  { return "gemini" }
+func (g *gemini) Model() string { return "gemini/" + g.model }
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiPart struct {
+	Text string `json:"text"`
+}
+
+func (g *gemini) Generate(ctx context.Context, req Request) (*Response, error) {
+	config := map[string]any{"maxOutputTokens": maxTokens(req)}
+	if req.JSON {
+		config["responseMimeType"] = "application/json"
+	}
+	body := map[string]any{
+		"contents":         []geminiContent{{Role: "user", Parts: []geminiPart{{Text: req.Prompt}}}},
+		"generationConfig": config,
+	}
+	if req.System != "" {
+		body["systemInstruction"] = geminiContent{Parts: []geminiPart{{Text: req.System}}}
+	}
+	var out struct {
+		Candidates []struct {
+			Content geminiContent `json:"content"`
+		} `json:"candidates"`
+		UsageMetadata struct {
+			PromptTokenCount     int64 `json:"promptTokenCount"`
+			CandidatesTokenCount int64 `json:"candidatesTokenCount"`
+		} `json:"usageMetadata"`
+	}
+	endpoint := "https://generativelanguage.googleapis.com/v1beta/models/" + url.PathEscape(g.model) + ":generateContent"
+	headers := map[string]string{"x-goog-api-key": g.apiKey}
+	if err := postJSON(ctx, g.client, endpoint, headers, body, &out); err != nil {
+		return nil, err
+	}
+	var text strings.Builder
+	if len(out.Candidates) > 0 {
+		for _, part := range out.Candidates[0].Content.Parts {
+			text.WriteString(part.Text)
+		}
+	}
+	return response(g, text.String(), out.UsageMetadata.PromptTokenCount, out.UsageMetadata.CandidatesTokenCount)
+}
+
+// claude generates with Anthropic's Messages API
+type claude struct {
+	apiKey string
+	model  string
+	client *http.Client
+}
+
+func (c *claude) Name() string  { return "claude" }
+func (c *claude) Model() string { return "claude/" + c.model }
+
+func (c *claude) Generate(ctx context.Context, req Request) (*Response, error) {
+	body := map[string]any{
+		"model":      c.model,
+		"max_tokens": maxTokens(req),
+		"messages":   []message{{Role: "user", Content: req.Prompt}},
+	}
+	if req.System != "" {
+		body["system"] = req.System
+	}
+	var out struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		Usage struct {
+			InputTokens  int64 `json:"input_tokens"`
+			OutputTokens int64 `json:"output_tokens"`
+		} `json:"usage"`
+	}
+	headers := map[string]string{"x-api-key": c.apiKey, "anthropic-version": "2023-06-01"}
+	if err := postJSON(ctx, c.client, "https://api.anthropic.com/v1/messages", headers, body, &out); err != nil {
+		return nil, err
+	}
+	var text strings.Builder
+	for _, block := range out.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	return response(c, text.String(), out.Usage.InputTokens, out.Usage.OutputTokens)
+}
+
+// response wraps a provider's answer, failing when it has no text
+func response(provider Provider, text string, inputTokens, outputTokens int64) (*Response, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, ErrEmptyResponse
+	}
+	return &Response{
+		Text:         text,
+		Provider:     provider.Name(),
+		Model:        provider.Model(),
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+	}, nil
+}
@@ -0,0 +1,92 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package params
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// TotalCountHeader carries the total of an offset-paged list
+const TotalCountHeader = "X-Total-Count"
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// List is the body of a keyset-paged list
+type List[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+	Total      *int   `json:"total,omitempty"`
+}
+
+// EncodeCursor turns a keyset position into an opaque cursor
+func EncodeCursor(cursor database.Cursor) string {
+	raw := cursor.Time.UTC().Format(time.RFC3339Nano) + "|" + cursor.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor reads a cursor made by EncodeCursor
+func DecodeCursor(s string) (*database.Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	stamp, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return nil, errInvalidCursor
+	}
+	t, err := time.Parse(time.RFC3339Nano, stamp)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	return &database.Cursor{Time: t, ID: id}, nil
+}
+
+// Trim drops the extra row fetched for a keyset page, returning the page
+// and the cursor of the next one, or "" on the last page. The cursor comes
+// from the key of the last row, so trim before filtering rows in memory.
+func Trim[T any](items []T, page Page, key func(T) database.Cursor) ([]T, string) {
+	if !page.Keyset || len(items) <= page.Limit {
+		return items, ""
+	}
+	items = items[:page.Limit]
+	return items, EncodeCursor(key(items[page.Limit-1]))
+}
+
+// WriteList responds with a page of items. Keyset pages are wrapped in a
+// List; offset pages stay a plain array for existing clients, with any
+// total in the X-Total-Count header.
+func WriteList[T any](c *gin.Context, items []T, page Page, next string, total *int) {
+	if !page.Keyset {
+		if total != nil {
+			c.Header(TotalCountHeader, strconv.Itoa(*total))
+		}
+		c.JSON(http.StatusOK, items)
+		return
+	}
+	c.JSON(http.StatusOK, List[T]{Items: items, NextCursor: next, HasMore: next != "", Total: total})
+}
@@ -0,0 +1,176 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package params parses and validates query parameters. Invalid values are
+// rejected with a 400 listing every offending parameter instead of being
+// silently replaced by defaults.
+package params
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Page size and offset limits for list endpoints
+const (
+	MaxLimit  = 200
+	MaxOffset = 10000
+)
+
+// Page is the window of a list request. Lists are paged by offset unless
+// the cursor parameter is present, which switches to keyset paging; an
+// empty cursor asks for the first page.
+type Page struct {
+	Limit  int
+	Offset int
+	Keyset bool
+	After  *database.Cursor // position to continue after; nil on the first page
+}
+
+// Fetch is the number of rows to request: keyset pages fetch one extra row
+// to learn whether there is a next page
+func (p Page) Fetch() int {
+	if p.Keyset {
+		return p.Limit + 1
+	}
+	return p.Limit
+}
+
+// Parser reads query parameters, collecting errors until Valid is called
+type Parser struct {
+	c    *gin.Context
+	errs []apierror.FieldError
+}
+
+// Query creates a parser for the request's query parameters
+func Query(c *gin.Context) *Parser {
+	return &Parser{c: c}
+}
+
+// Int reads an integer between min and max, or fallback when absent
+func (p *Parser) Int(name string, fallback, min, max int) int {
+	raw := strings.TrimSpace(p.c.Query(name))
+	if raw == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(raw)
+	switch {
+	case err != nil:
+		p.Fail(name, "type", "int")
+		return fallback
+	case n < min:
+		p.Fail(name, "min", strconv.Itoa(min))
+		return fallback
+	case n > max:
+		p.Fail(name, "max", strconv.Itoa(max))
+		return fallback
+	}
+	return n
+}
+
+// Bool reads a boolean; an absent parameter is false
+func (p *Parser) Bool(name string) bool {
+	raw := strings.TrimSpace(p.c.Query(name))
+	if raw == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(raw)
+	if err != nil {
+		p.Fail(name, "type", "bool")
+		return false
+	}
+	return b
+}
+
+// String reads a string. When allowed values are given, anything else is
+// rejected; an absent parameter is always accepted as "".
+func (p *Parser) String(name string, allowed ...string) string {
+	raw := strings.TrimSpace(p.c.Query(name))
+	if raw == "" || len(allowed) == 0 {
+		return raw
+	}
+	for _, v := range allowed {
+		if raw == v {
+			return raw
+		}
+	}
+	p.Fail(name, "oneof", strings.Join(allowed, " "))
+	return ""
+}
+
+// Date reads a YYYY-MM-DD date as midnight in loc. The second result is
+// false when the parameter is absent or invalid.
+func (p *Parser) Date(name string, loc *time.Location) (time.Time, bool) {
+	raw := strings.TrimSpace(p.c.Query(name))
+	if raw == "" {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation("2006-01-02", raw, loc)
+	if err != nil {
+		p.Fail(name, "type", "date")
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// Page reads limit with offset or cursor, defaulting to defaultLimit items
+// from the start
+func (p *Parser) Page(defaultLimit int) Page {
+	page := Page{
+		Limit:  p.Int("limit", defaultLimit, 1, MaxLimit),
+		Offset: p.Int("offset", 0, 0, MaxOffset),
+	}
+
+	raw, ok := p.c.GetQuery("cursor")
+	if !ok {
+		return page
+	}
+	page.Keyset = true
+	if page.Offset > 0 {
+		p.Fail("offset", "excluded_with", "cursor")
+	}
+	if raw = strings.TrimSpace(raw); raw != "" {
+		cursor, err := DecodeCursor(raw)
+		if err != nil {
+			p.Fail("cursor", "format", "")
+		}
+		page.After = cursor
+	}
+	return page
+}
+
+// Valid reports whether every parameter read so far was valid. If not, it
+// responds 400 with the offending parameters and the handler should return.
+func (p *Parser) Valid() bool {
+	if len(p.errs) == 0 {
+		return true
+	}
+	apierror.Abort(p.c, apierror.New(http.StatusBadRequest, apierror.CodeValidationFailed, "invalid query parameters").WithDetails(p.errs))
+	return false
+}
+
+// Fail records a parameter that breaks a rule checked by the caller
+func (p *Parser) Fail(name, rule, param string) {
+	p.errs = append(p.errs, apierror.FieldError{Field: name, Rule: rule, Param: param})
+}
@@ -0,0 +1,357 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package patch applies partial updates to a record. Clients send a JSON
+// Patch (RFC 6902) or a JSON merge patch (RFC 7386) against the record's
+// JSON, so they change only the fields they mean to rather than sending
+// the whole record back over edits made elsewhere.
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/api/precondition"
+	"github.com/rghsoftware/space-food/internal/apierror"
+)
+
+// Media types of patches. Plain JSON is read as a merge patch.
+const (
+	JSONPatch  = "application/json-patch+json"
+	MergePatch = "application/merge-patch+json"
+)
+
+var (
+	// ErrUnsupportedType is a patch in a format other than the above
+	ErrUnsupportedType = errors.New("send a JSON Patch (application/json-patch+json) or a merge patch (application/merge-patch+json)")
+	// ErrTestFailed is a JSON Patch whose test operation doesn't hold
+	ErrTestFailed = errors.New("the record no longer matches the patch's test")
+)
+
+// Operation is one step of a JSON Patch
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Bind applies the request's patch to current and decodes the result into
+// patched, refusing fields patched doesn't have. Other formats get a 415,
+// failed tests a 409 with the version and record the patch was tested
+// against, and patches that can't be applied a 400.
+func Bind(c *gin.Context, version string, current, patched any) bool {
+	doc, err := json.Marshal(current)
+	if err != nil {
+		apierror.Internal(c, err)
+		return false
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		apierror.BadRequest(c, "failed to read the patch")
+		return false
+	}
+
+	result, err := Apply(doc, c.ContentType(), body)
+	switch {
+	case errors.Is(err, ErrUnsupportedType):
+		apierror.Abort(c, apierror.New(http.StatusUnsupportedMediaType, apierror.CodeUnsupportedMedia, err.Error()))
+		return false
+	case errors.Is(err, ErrTestFailed):
+		apierror.Abort(c, apierror.New(http.StatusConflict, apierror.CodeEditConflict, err.Error()).WithDetails(precondition.Conflict{
+			Version: version,
+			Changes: current,
+		}))
+		return false
+	case err != nil:
+		apierror.BadRequest(c, err.Error())
+		return false
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(result))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(patched); err != nil {
+		apierror.BadRequest(c, "invalid patch: "+strings.TrimPrefix(err.Error(), "json: "))
+		return false
+	}
+	return true
+}
+
+// Apply patches the JSON document doc with a patch of the given media type
+func Apply(doc []byte, contentType string, body []byte) ([]byte, error) {
+	var target any
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, err
+	}
+
+	switch contentType {
+	case JSONPatch:
+		var ops []Operation
+		if err := json.Unmarshal(body, &ops); err != nil {
+			return nil, errors.New("a JSON Patch must be an array of operations")
+		}
+		for i, op := range ops {
+			var err error
+			if target, err = apply(target, op); err != nil {
+				if errors.Is(err, ErrTestFailed) {
+					return nil, err
+				}
+				return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+			}
+		}
+	case MergePatch, "application/json", "":
+		var p any
+		if err := json.Unmarshal(body, &p); err != nil {
+			return nil, errors.New("a merge patch must be JSON")
+		}
+		if _, ok := p.(map[string]any); !ok {
+			return nil, errors.New("a merge patch must be an object")
+		}
+		target = merge(target, p)
+	default:
+		return nil, ErrUnsupportedType
+	}
+	return json.Marshal(target)
+}
+
+// merge applies a merge patch: members set to null are removed, objects
+// are merged member by member and anything else replaces what was there
+func merge(target, p any) any {
+	patchObject, ok := p.(map[string]any)
+	if !ok {
+		return p
+	}
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = map[string]any{}
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+		} else {
+			targetObject[name] = merge(targetObject[name], value)
+		}
+	}
+	return targetObject
+}
+
+// apply carries out one JSON Patch operation
+func apply(doc any, op Operation) (any, error) {
+	path, err := pointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+	value := func() (any, error) {
+		var v any
+		if len(op.Value) == 0 {
+			return nil, errors.New("value is required")
+		}
+		if err := json.Unmarshal(op.Value, &v); err != nil {
+			return nil, errors.New("invalid value")
+		}
+		return v, nil
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		v, err := value()
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "add" {
+			return add(doc, path, v)
+		}
+		existing, err := get(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "test" {
+			if !reflect.DeepEqual(existing, v) {
+				return nil, ErrTestFailed
+			}
+			return doc, nil
+		}
+		if len(path) == 0 {
+			return v, nil
+		}
+		doc, _ = remove(doc, path)
+		return add(doc, path, v)
+
+	case "remove":
+		if len(path) == 0 {
+			return nil, errors.New("the whole record can't be removed")
+		}
+		return remove(doc, path)
+
+	case "move", "copy":
+		from, err := pointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		v, err := get(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "copy" {
+			// Decoded afresh so the copy shares nothing with the original
+			raw, _ := json.Marshal(v)
+			json.Unmarshal(raw, &v)
+			return add(doc, path, v)
+		}
+		if len(path) > len(from) && reflect.DeepEqual(path[:len(from)], from) {
+			return nil, errors.New("a value can't be moved into itself")
+		}
+		if doc, err = remove(doc, from); err != nil {
+			return nil, err
+		}
+		return add(doc, path, v)
+	}
+	return nil, errors.New("op must be add, remove, replace, move, copy or test")
+}
+
+// pointer splits a JSON Pointer (RFC 6901) into its reference tokens
+func pointer(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("path %q must start with /", path)
+	}
+	tokens := strings.Split(path[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// index reads an array index token, which may be - for the end of the
+// array
+func index(token string, n int) (int, bool) {
+	if token == "-" {
+		return n, true
+	}
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, false
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > n {
+		return 0, false
+	}
+	return i, true
+}
+
+func notFound(path []string) error {
+	return fmt.Errorf("/%s doesn't exist", strings.Join(path, "/"))
+}
+
+// get returns the value at a path
+func get(doc any, path []string) (any, error) {
+	for i, token := range path {
+		switch v := doc.(type) {
+		case map[string]any:
+			child, ok := v[token]
+			if !ok {
+				return nil, notFound(path[:i+1])
+			}
+			doc = child
+		case []any:
+			n, ok := index(token, len(v))
+			if !ok || n == len(v) {
+				return nil, notFound(path[:i+1])
+			}
+			doc = v[n]
+		default:
+			return nil, notFound(path[:i+1])
+		}
+	}
+	return doc, nil
+}
+
+// edit returns doc with fn applied to the container of the last token of
+// path, replacing the containers above it as arrays change length
+func edit(doc any, path []string, fn func(container any, token string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+	child, err := get(doc, path[:1])
+	if err != nil {
+		return nil, err
+	}
+	child, err = edit(child, path[1:], fn)
+	if err != nil {
+		return nil, err
+	}
+	switch v := doc.(type) {
+	case map[string]any:
+		v[path[0]] = child
+	case []any:
+		n, _ := index(path[0], len(v))
+		v[n] = child
+	}
+	return doc, nil
+}
+
+// add sets an object member or inserts into an array
+func add(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return edit(doc, path, func(container any, token string) (any, error) {
+		switch v := container.(type) {
+		case map[string]any:
+			v[token] = value
+			return v, nil
+		case []any:
+			n, ok := index(token, len(v))
+			if !ok {
+				return nil, notFound(path)
+			}
+			return append(v[:n], append([]any{value}, v[n:]...)...), nil
+		}
+		return nil, notFound(path[:len(path)-1])
+	})
+}
+
+// remove deletes an object member or an array element
+func remove(doc any, path []string) (any, error) {
+	return edit(doc, path, func(container any, token string) (any, error) {
+		switch v := container.(type) {
+		case map[string]any:
+			if _, ok := v[token]; !ok {
+				return nil, notFound(path)
+			}
+			delete(v, token)
+			return v, nil
+		case []any:
+			n, ok := index(token, len(v))
+			if !ok || n == len(v) {
+				return nil, notFound(path)
+			}
+			return append(v[:n], v[n+1:]...), nil
+		}
+		return nil, notFound(path)
+	})
+}
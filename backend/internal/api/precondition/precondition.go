@@ -0,0 +1,101 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package precondition stops edits from silently overwriting each other.
+// A client that loaded a record sends back the version it edited, and the
+// edit is refused with a 409 if someone saved the record since. Saves are
+// conditional on the version that was checked, so an edit that races
+// another save is refused the same way.
+package precondition
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/apierror"
+)
+
+// ErrChanged is returned by a save that found the record no longer at the
+// version it was checked against, because another save came in between
+var ErrChanged = errors.New("the record was saved by someone else in the meantime")
+
+// Conflict is the details of an edit_conflict error
+type Conflict struct {
+	Version string `json:"version"` // the version now saved, to retry against
+	Changes any    `json:"changes"` // how the edit differs from what is saved
+}
+
+// Version identifies a saved state of a record: the time it was last
+// updated, to the microsecond both databases keep
+func Version(updatedAt time.Time) string {
+	return updatedAt.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano)
+}
+
+// Check reports whether an edit may go ahead. The version the client
+// edited comes from If-Match, or else from the updated time it sent back
+// in the body; clients that send neither are not checked. When the
+// version is stale the request is aborted with a 409 carrying the saved
+// version and the changes returned by diff; a malformed If-Match gets a
+// 400.
+func Check(c *gin.Context, saved, sent time.Time, diff func() any) bool {
+	if saved.IsZero() {
+		return true
+	}
+	expected := ""
+	if !sent.IsZero() {
+		expected = Version(sent)
+	}
+	if header := c.GetHeader("If-Match"); header != "" {
+		expected = ""
+		for _, candidate := range strings.Split(header, ",") {
+			candidate = strings.Trim(strings.TrimPrefix(strings.TrimSpace(candidate), "W/"), `"`)
+			if candidate == "*" {
+				return true
+			}
+			t, err := time.Parse(time.RFC3339Nano, candidate)
+			if err != nil {
+				apierror.BadRequest(c, "If-Match must hold the updated time of the version you edited")
+				return false
+			}
+			if Version(t) == Version(saved) {
+				return true
+			}
+			expected = Version(t)
+		}
+	}
+	if expected == "" || expected == Version(saved) {
+		return true
+	}
+
+	Refuse(c, saved, diff)
+	return false
+}
+
+// Refuse aborts an edit of a record saved since the client loaded it with
+// a 409 carrying the saved version and the changes returned by diff. Check
+// calls it, and so do saves that fail with ErrChanged.
+func Refuse(c *gin.Context, saved time.Time, diff func() any) {
+	apierror.Abort(c, apierror.New(http.StatusConflict, apierror.CodeEditConflict,
+		"this was changed after you loaded it; review the changes and try again").WithDetails(Conflict{
+		Version: Version(saved),
+		Changes: diff(),
+	}))
+}
@@ -19,23 +19,85 @@
 package rest
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/ai"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/audit"
 	"github.com/rghsoftware/space-food/internal/auth"
+	"github.com/rghsoftware/space-food/internal/authz"
+	"github.com/rghsoftware/space-food/internal/config"
 	authfeature "github.com/rghsoftware/space-food/internal/features/auth"
+	"github.com/rghsoftware/space-food/internal/features/account"
+	"github.com/rghsoftware/space-food/internal/features/admin"
+	"github.com/rghsoftware/space-food/internal/features/aicache"
+	"github.com/rghsoftware/space-food/internal/features/aiprompts"
+	"github.com/rghsoftware/space-food/internal/features/aiusage"
+	"github.com/rghsoftware/space-food/internal/features/apitokens"
+	"github.com/rghsoftware/space-food/internal/features/batchcook"
+	"github.com/rghsoftware/space-food/internal/features/budget"
+	"github.com/rghsoftware/space-food/internal/features/calendar"
 	"github.com/rghsoftware/space-food/internal/features/recipes"
+	"github.com/rghsoftware/space-food/internal/features/leftovers"
 	"github.com/rghsoftware/space-food/internal/features/meal_planning"
+	"github.com/rghsoftware/space-food/internal/features/mealrequests"
 	"github.com/rghsoftware/space-food/internal/features/pantry"
+	"github.com/rghsoftware/space-food/internal/features/safefoods"
+	"github.com/rghsoftware/space-food/internal/features/sensory"
+	"github.com/rghsoftware/space-food/internal/features/setup"
 	"github.com/rghsoftware/space-food/internal/features/shopping_list"
+	syncfeature "github.com/rghsoftware/space-food/internal/features/sync"
+	"github.com/rghsoftware/space-food/internal/features/substitutions"
+	"github.com/rghsoftware/space-food/internal/features/translation"
+	"github.com/rghsoftware/space-food/internal/features/webhooks"
+	"github.com/rghsoftware/space-food/internal/features/email"
+	"github.com/rghsoftware/space-food/internal/features/weeklyreview"
+	"github.com/rghsoftware/space-food/internal/features/suggestions"
 	"github.com/rghsoftware/space-food/internal/features/nutrition"
+	"github.com/rghsoftware/space-food/internal/features/capabilities"
+	"github.com/rghsoftware/space-food/internal/features/cooking"
+	"github.com/rghsoftware/space-food/internal/features/dietary"
+	"github.com/rghsoftware/space-food/internal/features/energy"
+	"github.com/rghsoftware/space-food/internal/features/foods"
+	"github.com/rghsoftware/space-food/internal/features/household"
+	"github.com/rghsoftware/space-food/internal/features/images"
+	"github.com/rghsoftware/space-food/internal/features/jobs"
+	"github.com/rghsoftware/space-food/internal/features/locale"
+	"github.com/rghsoftware/space-food/internal/features/mealtime"
 	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/events"
+	"github.com/rghsoftware/space-food/internal/health"
+	"github.com/rghsoftware/space-food/internal/mail"
 	"github.com/rghsoftware/space-food/internal/middleware"
+	"github.com/rghsoftware/space-food/internal/scheduler"
+	"github.com/rghsoftware/space-food/internal/storage"
+	"github.com/rghsoftware/space-food/internal/telemetry"
+	"github.com/rghsoftware/space-food/internal/web"
 )
 
-// SetupRouter sets up the API router
-func SetupRouter(db database.Database, authProvider auth.AuthProvider) *gin.Engine {
-	router := gin.Default()
+// SetupRouter sets up the API router. Features whose settings can be
+// reloaded while the server runs register with live.
+func SetupRouter(cfg *config.Config, live *config.Live, db database.Database, store storage.Provider, authProvider auth.AuthProvider, jobScheduler *scheduler.Scheduler, eventBus *events.Bus, healthChecker *health.Checker) *gin.Engine {
+	router := gin.New()
+	router.Use(gin.Recovery(), middleware.RequestID(), middleware.AccessLog())
+	if cfg.Telemetry.Metrics.Enabled || cfg.Telemetry.Tracing.Enabled {
+		router.Use(telemetry.Middleware())
+	}
+
+	// Unknown routes get the same error envelope as everything else, unless
+	// the web app built into the binary routes them
+	notFound := func(c *gin.Context) {
+		apierror.NotFound(c, "no such endpoint")
+	}
+	if app := web.Load(); app != nil {
+		router.NoRoute(app.Handler(notFound))
+	} else {
+		router.NoRoute(notFound)
+	}
 
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
@@ -52,26 +114,188 @@ func SetupRouter(db database.Database, authProvider auth.AuthProvider) *gin.Engi
 		})
 	})
 
+	// Liveness and readiness probes for Docker and Kubernetes
+	router.GET("/healthz", healthChecker.Live)
+	router.GET("/readyz", healthChecker.Ready)
+
+	// Prometheus scrape endpoint
+	if cfg.Telemetry.Metrics.Enabled {
+		observeDBPool(db)
+		router.GET(cfg.Telemetry.Metrics.Path, telemetry.MetricsHandler(cfg.Telemetry.Metrics.Token))
+	}
+
 	// API v1 routes
 	v1 := router.Group("/api/v1")
+	// Pollers of unchanged data get 304 Not Modified instead of the body
+	v1.Use(middleware.ConditionalGET())
+	// Handlers record sensitive actions in the audit log
+	auditLog := audit.NewRecorder(db, cfg.Audit)
+	v1.Use(auditLog.Middleware())
+	jobScheduler.Register("audit-purge", "@daily", auditLog.Purge)
+
+	// Email, sent by features such as password resets, household
+	// invitations and the weekly review
+	emailNotifier := email.NewNotifier(cfg, db, mail.New(cfg.Mail))
+	eventBus.Subscribe(emailNotifier.Handle)
 
 	// Auth routes (public)
-	authHandler := authfeature.NewHandler(authProvider)
+	authHandler := authfeature.NewHandler(authProvider, cfg.Auth.OIDC.PostLoginURL)
 	authGroup := v1.Group("/auth")
+	if cfg.RateLimit.Enabled && cfg.RateLimit.AuthPerMinute > 0 {
+		// Slow down credential stuffing against login and registration
+		authGroup.Use(middleware.RateLimit(
+			middleware.NewRateLimiter(cfg.RateLimit.AuthPerMinute),
+			middleware.ClientIPKey,
+			"Too many sign-in attempts. Please wait a moment and try again.",
+		))
+	}
 	authHandler.RegisterRoutes(authGroup)
+	passwordHandler := authfeature.NewPasswordHandler(cfg, authProvider, emailNotifier)
+	passwordHandler.RegisterRoutes(authGroup)
+	jobScheduler.Register("password-reset-purge", "@daily", func(ctx context.Context) (string, error) {
+		n, err := db.PurgePasswordResetTokens(ctx, time.Now())
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("removed %d expired reset tokens", n), nil
+	})
+
+	// First-run setup (public, locks itself once an account exists)
+	setupHandler := setup.NewHandler(db, live, authProvider)
+	setupGroup := v1.Group("/setup")
+	setupHandler.RegisterRoutes(setupGroup)
+
+	// Capability discovery (public, so clients can adapt before login)
+	capabilitiesHandler := capabilities.NewHandler(live)
+	capabilitiesGroup := v1.Group("/capabilities")
+	capabilitiesHandler.RegisterRoutes(capabilitiesGroup)
+
+	// Error code catalog (public, documents the codes in error responses)
+	v1.GET("/error-codes", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"error_codes": apierror.Catalog})
+	})
+
+	// Stored images (public, the random ID in the URL is the credential)
+	imageHandler := images.NewHandler(cfg, store)
+	imageGroup := v1.Group("/images")
+	imageHandler.RegisterRoutes(imageGroup)
+
+	// External calendar import
+	var calendarImporter *calendar.Importer
+	if cfg.Calendar.Import.Enabled {
+		calendarImporter = calendar.NewImporter(db, cfg)
+		// Checked often, but each calendar is only fetched once per sync interval
+		jobScheduler.Register("calendar-import", "@every 5m", calendarImporter.SyncDue)
+	}
+
+	// Calendar feeds (public, the token in the URL authenticates the subscriber)
+	calendarHandler := calendar.NewHandler(db, cfg.Server.PublicURL, calendarImporter)
+	calendarGroup := v1.Group("/calendar")
+	calendarHandler.RegisterFeedRoutes(calendarGroup)
 
 	// Protected routes
 	protected := v1.Group("")
 	protected.Use(middleware.AuthMiddleware(authProvider))
+	if cfg.RateLimit.Enabled {
+		if cfg.RateLimit.UserPerMinute > 0 {
+			protected.Use(middleware.RateLimit(
+				middleware.NewRateLimiter(cfg.RateLimit.UserPerMinute),
+				middleware.UserKey,
+				"You're going a little fast. Please wait a moment and try again.",
+			))
+		}
+		if cfg.RateLimit.InstancePerMinute > 0 {
+			protected.Use(middleware.RateLimit(
+				middleware.NewRateLimiter(cfg.RateLimit.InstancePerMinute),
+				middleware.InstanceKey,
+				"The server is busy right now. Please try again shortly.",
+			))
+		}
+	}
+	if cfg.Auth.RequireTwoFactor {
+		// Leave enrollment and sign-out reachable for users not yet enrolled
+		protected.Use(middleware.RequireTwoFactor(db, "/api/v1/me/2fa", "/api/v1/me/sessions", "/api/v1/me/password"))
+	}
+	// After an administrator resets a password, only changing it, signing
+	// out and two-factor enrollment work until the user picks a new one
+	protected.Use(middleware.RequirePasswordChange("/api/v1/me/password", "/api/v1/me/sessions", "/api/v1/me/2fa"))
+	protected.Use(middleware.EnforceTokenScope(
+		[]string{"/api/v1/nutrition"},
+		[]string{"/api/v1/me/api-tokens", "/api/v1/me/sessions", "/api/v1/me/2fa", "/api/v1/me/password", "/api/v1/me/export", "/api/v1/me/deletion", "/api/v1/admin", "/api/v1/cooking-assistant/sessions/:id/handoff"},
+	))
+	// Retried creates and expensive requests replay their first response
+	// instead of running twice. Routes whose responses hold secrets, such
+	// as share links and API tokens, are left out so none are stored.
+	protected.Use(middleware.Idempotency(db, time.Duration(cfg.Server.IdempotencyWindow)*time.Hour,
+		"/api/v1/recipes",
+		"/api/v1/recipes/import",
+		"/api/v1/recipes/import/shared",
+		"/api/v1/meal-plans",
+		"/api/v1/meal-plans/generate",
+		"/api/v1/pantry",
+		"/api/v1/leftovers",
+		"/api/v1/batch-cook/freeze",
+		"/api/v1/shopping-list",
+		"/api/v1/budget/prices",
+		"/api/v1/nutrition/logs",
+		"/api/v1/cooking-assistant/sessions",
+		"/api/v1/cooking-assistant/sessions/:id/timers",
+		"/api/v1/cooking-assistant/sessions/:id/timers/from-step/:step_index",
+		"/api/v1/me/timers",
+		"/api/v1/me/energy",
+		"/api/v1/households",
+		"/api/v1/households/:id/meal-requests",
+		"/api/v1/sync/push",
+	))
+	jobScheduler.Register("idempotency-keys-purge", "@hourly", func(ctx context.Context) (string, error) {
+		n, err := db.PurgeIdempotencyKeys(ctx, time.Now().Add(-time.Duration(cfg.Server.IdempotencyWindow)*time.Hour))
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("removed %d expired keys", n), nil
+	})
+
+	// AI provider, switched when its settings are reloaded, the prompts
+	// sent to it and the shared cache for its outputs
+	aiSource := ai.NewSource(live.Get().AI)
+	live.OnReload(func(cfg *config.Config) { aiSource.Reload(cfg.AI) })
+	aiPrompts := aiprompts.NewRegistry(live.Get().AI.PromptsDir)
+	live.OnReload(func(cfg *config.Config) { aiPrompts.Reload(cfg.AI.PromptsDir) })
+	aiCache := aicache.NewCache(db, live.Get().AI.CacheTTL)
+	jobScheduler.Register("ai-cache-purge", "@daily", func(ctx context.Context) (string, error) {
+		n, err := aiCache.PurgeExpired(ctx)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("removed %d expired entries", n), nil
+	})
 
 	// Recipe routes
-	recipeHandler := recipes.NewHandler(db)
+	recipeHandler := recipes.NewHandler(cfg, db, store)
+	recipeHandler.OnChange(aiCache.InvalidateRecipe)
 	recipeGroup := protected.Group("/recipes")
+	// Household participants can read recipes but not change them
+	recipeGroup.Use(authz.Require(db, authz.EditRecipes))
 	recipeHandler.RegisterRoutes(recipeGroup)
+	recipeImportGroup := recipeGroup.Group("/import")
+	if cfg.RateLimit.Enabled && cfg.RateLimit.ImportPerHour > 0 {
+		// Each import fetches another site on the user's behalf
+		recipeImportGroup.Use(middleware.RateLimit(
+			middleware.NewRateLimiterPer(cfg.RateLimit.ImportPerHour, time.Hour),
+			middleware.UserKey,
+			"You've imported a lot of recipes recently. Please wait a while before importing more.",
+		))
+	}
+	recipeHandler.RegisterImportRoutes(recipeImportGroup)
+
+	// Shared recipes (public, the token in the URL is the credential)
+	sharedRecipeGroup := v1.Group("/shared/recipes")
+	recipeHandler.RegisterShareRoutes(sharedRecipeGroup)
 
 	// Meal planning routes
 	mealPlanningHandler := meal_planning.NewHandler(db)
 	mealPlanGroup := protected.Group("/meal-plans")
+	mealPlanGroup.Use(authz.Require(db, authz.EditMealPlans))
 	mealPlanningHandler.RegisterRoutes(mealPlanGroup)
 
 	// Pantry routes
@@ -79,15 +303,232 @@ func SetupRouter(db database.Database, authProvider auth.AuthProvider) *gin.Engi
 	pantryGroup := protected.Group("/pantry")
 	pantryHandler.RegisterRoutes(pantryGroup)
 
+	// Leftover routes
+	leftoverHandler := leftovers.NewHandler(db, eventBus)
+	leftoverGroup := protected.Group("/leftovers")
+	leftoverHandler.RegisterRoutes(leftoverGroup)
+	jobScheduler.Register("thaw-reminders", "@every 15m", leftoverHandler.RemindThaw)
+
+	// Freezer batch-cooking routes
+	batchCookHandler := batchcook.NewHandler(db)
+	batchCookGroup := protected.Group("/batch-cook")
+	batchCookHandler.RegisterRoutes(batchCookGroup)
+
+	// Cooking assistant routes
+	cookingHandler := cooking.NewHandler(cfg, db, store, eventBus, authProvider)
+	cookingGroup := protected.Group("/cooking-assistant")
+	cookingHandler.RegisterRoutes(cookingGroup)
+	cookingHandler.RegisterHandoffRoutes(authGroup)
+	jobScheduler.Register("handoff-purge", "@daily", func(ctx context.Context) (string, error) {
+		n, err := db.PurgeHandoffTokens(ctx, time.Now())
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("removed %d expired handoff tokens", n), nil
+	})
+	cookingHandler.RegisterRecipeRoutes(recipeGroup)
+	if cfg.Cooking.AutoPause > 0 {
+		jobScheduler.Register("cooking-auto-pause", "@every 1m", cookingHandler.AutoPause)
+	}
+
 	// Shopping list routes
-	shoppingListHandler := shopping_list.NewHandler(db)
+	shoppingListHandler := shopping_list.NewHandler(db, eventBus)
 	shoppingListGroup := protected.Group("/shopping-list")
 	shoppingListHandler.RegisterRoutes(shoppingListGroup)
 
+	// Grocery budget routes
+	budgetHandler := budget.NewHandler(db)
+	budgetGroup := protected.Group("/budget")
+	budgetHandler.RegisterRoutes(budgetGroup)
+	budgetHandler.RegisterRecipeRoutes(recipeGroup)
+	budgetHandler.RegisterMealPlanRoutes(mealPlanGroup)
+	budgetHandler.RegisterShoppingListRoutes(shoppingListGroup)
+
+	// Offline sync routes
+	syncHandler := syncfeature.NewHandler(cfg, db, eventBus)
+	syncGroup := protected.Group("/sync")
+	syncHandler.RegisterRoutes(syncGroup)
+	jobScheduler.Register("sync-tombstone-purge", "@daily", syncHandler.PurgeTombstones)
+
 	// Nutrition tracking routes
-	nutritionHandler := nutrition.NewHandler(db)
+	nutritionHandler := nutrition.NewHandler(db, eventBus)
 	nutritionGroup := protected.Group("/nutrition")
 	nutritionHandler.RegisterRoutes(nutritionGroup)
 
+	// Packaged food lookup routes
+	foodHandler := foods.NewHandler(db, cfg.Foods.OpenFoodFacts)
+	foodGroup := protected.Group("/foods")
+	foodHandler.RegisterRoutes(foodGroup)
+
+	// Meal suggestion routes
+	suggestionHandler := suggestions.NewHandler(db)
+	suggestionGroup := protected.Group("/suggestions")
+	suggestionHandler.RegisterRoutes(suggestionGroup)
+
+	// Household routes
+	householdHandler := household.NewHandler(db, cfg.Server.PublicURL, emailNotifier)
+	householdGroup := protected.Group("/households")
+	householdHandler.RegisterRoutes(householdGroup)
+	shoppingListHandler.RegisterHouseholdRoutes(householdGroup)
+	recipeHandler.RegisterHouseholdRoutes(householdGroup)
+	mealPlanningHandler.RegisterHouseholdRoutes(householdGroup)
+	mealRequestHandler := mealrequests.NewHandler(db)
+	mealRequestHandler.RegisterHouseholdRoutes(householdGroup)
+
+	// Instance administration routes
+	adminHandler := admin.NewHandler(cfg, live, db, authProvider, emailNotifier)
+	adminGroup := protected.Group("/admin")
+	adminGroup.Use(middleware.RequireAdmin())
+	adminHandler.RegisterRoutes(adminGroup)
+	jobScheduler.Register("instance-metrics-rollup", "@hourly", adminHandler.RollupMetrics)
+
+	// AI cache administration routes
+	aiCacheHandler := aicache.NewHandler(aiCache)
+	aiCacheGroup := adminGroup.Group("/ai-cache")
+	aiCacheHandler.RegisterRoutes(aiCacheGroup)
+
+	// AI prompt administration routes
+	aiPromptsHandler := aiprompts.NewHandler(aiPrompts, live)
+	aiPromptsGroup := adminGroup.Group("/ai-prompts")
+	aiPromptsHandler.RegisterRoutes(aiPromptsGroup)
+
+	// Background job administration routes
+	jobsHandler := jobs.NewHandler(db, jobScheduler)
+	jobsGroup := adminGroup.Group("/jobs")
+	jobsHandler.RegisterRoutes(jobsGroup)
+
+	// Current user routes
+	me := protected.Group("/me")
+
+	// Data export and account deletion routes
+	accountHandler := account.NewHandler(cfg, db, store)
+	accountHandler.RegisterRoutes(me)
+	jobScheduler.Register("account-deletion-purge", "@hourly", accountHandler.PurgeDeletedAccounts)
+
+	// Session management routes
+	sessionGroup := me.Group("/sessions")
+	authHandler.RegisterSessionRoutes(sessionGroup)
+
+	// Password change route
+	passwordGroup := me.Group("/password")
+	passwordHandler.RegisterChangeRoutes(passwordGroup)
+
+	// API token routes
+	apiTokenHandler := apitokens.NewHandler(db)
+	apiTokenGroup := me.Group("/api-tokens")
+	apiTokenHandler.RegisterRoutes(apiTokenGroup)
+
+	// Calendar feed routes
+	calendarFeedGroup := me.Group("/calendar-feeds")
+	calendarHandler.RegisterRoutes(calendarFeedGroup)
+
+	// External calendar routes
+	if calendarImporter != nil {
+		externalCalendarGroup := me.Group("/external-calendars")
+		calendarHandler.RegisterExternalRoutes(externalCalendarGroup)
+	}
+
+	// Webhook routes
+	if cfg.Webhooks.Enabled {
+		webhookDispatcher := webhooks.NewDispatcher(db, cfg.Webhooks)
+		eventBus.Subscribe(webhookDispatcher.Handle)
+		jobScheduler.Register("webhook-retry", "@every 1m", webhookDispatcher.RetryDue)
+		jobScheduler.Register("webhook-deliveries-prune", "@daily", webhookDispatcher.Purge)
+
+		webhookHandler := webhooks.NewHandler(db, webhookDispatcher)
+		webhookGroup := me.Group("/webhooks")
+		webhookHandler.RegisterRoutes(webhookGroup)
+	}
+
+	// Two-factor authentication routes
+	twoFactorGroup := me.Group("/2fa")
+	authHandler.RegisterTwoFactorRoutes(twoFactorGroup)
+
+	// AI usage routes
+	aiUsageTracker := aiusage.NewTracker(db, live.Get().AI)
+	live.OnReload(func(cfg *config.Config) { aiUsageTracker.SetConfig(cfg.AI) })
+	aiSource.Observe(aiUsageTracker.Observe)
+	jobScheduler.Register("ai-invocations-purge", "@daily", aiUsageTracker.Purge)
+	aiUsageHandler := aiusage.NewHandler(aiUsageTracker)
+	aiUsageGroup := me.Group("/ai-usage")
+	aiUsageHandler.RegisterRoutes(aiUsageGroup)
+	aiInvocationsGroup := adminGroup.Group("/ai-invocations")
+	aiUsageHandler.RegisterAdminRoutes(aiInvocationsGroup)
+
+	// Timer routes, across cooking sessions and standalone timers
+	timerGroup := me.Group("/timers")
+	cookingHandler.RegisterTimerRoutes(timerGroup)
+
+	// Email preference routes
+	emailHandler := email.NewHandler(db, emailNotifier)
+	emailGroup := me.Group("/email-preferences")
+	emailHandler.RegisterRoutes(emailGroup)
+
+	// Locale routes
+	localeHandler := locale.NewHandler(db)
+	localeGroup := me.Group("/locale")
+	localeHandler.RegisterRoutes(localeGroup)
+
+	// Weekly review routes
+	weeklyReviewHandler := weeklyreview.NewHandler(db, eventBus)
+	weeklyReviewHandler.RegisterRoutes(me)
+	jobScheduler.Register("weekly-review", "@hourly", weeklyReviewHandler.SendDue)
+
+	// Energy check-in routes
+	energyHandler := energy.NewHandler(db)
+	energyGroup := me.Group("/energy")
+	energyHandler.RegisterRoutes(energyGroup)
+
+	// Meal window routes
+	mealTimeHandler := mealtime.NewHandler(db)
+	mealTimeGroup := me.Group("/meal-windows")
+	mealTimeHandler.RegisterRoutes(mealTimeGroup)
+
+	// Dietary restriction routes
+	dietaryHandler := dietary.NewHandler(db)
+	dietaryGroup := me.Group("/dietary-restrictions")
+	dietaryHandler.RegisterRoutes(dietaryGroup)
+	dietaryHandler.RegisterHouseholdRoutes(householdGroup)
+	dietaryHandler.RegisterRecipeRoutes(recipeGroup)
+	recipeHandler.OnChange(dietary.RefreshTags(db))
+
+	// Ingredient substitution routes
+	substitutionHandler := substitutions.NewHandler(db, aiSource, aiPrompts, aiCache, aiUsageTracker)
+	substitutionHandler.RegisterRecipeRoutes(recipeGroup)
+
+	// Recipe translation routes
+	translationHandler := translation.NewHandler(db, aiSource, aiPrompts, aiUsageTracker)
+	translationHandler.RegisterRecipeRoutes(recipeGroup)
+
+	// Safe food routes
+	safeFoodHandler := safefoods.NewHandler(db)
+	safeFoodGroup := me.Group("/safe-foods")
+	safeFoodHandler.RegisterRoutes(safeFoodGroup)
+
+	// Sensory profile routes
+	sensoryHandler := sensory.NewHandler(db)
+	sensoryGroup := me.Group("/sensory-profile")
+	sensoryHandler.RegisterRoutes(sensoryGroup)
+
 	return router
 }
+
+// observeDBPool reports the database connection pool on every scrape
+func observeDBPool(db database.Database) {
+	telemetry.Default.NewGaugeFunc("spacefood_db_connections", "Database connections by state.", []string{"state"},
+		func(emit func(float64, ...string)) {
+			stats := db.PoolStats()
+			emit(float64(stats.MaxConns), "max")
+			emit(float64(stats.OpenConns), "open")
+			emit(float64(stats.InUseConns), "in_use")
+			emit(float64(stats.IdleConns), "idle")
+		})
+	telemetry.Default.NewGaugeFunc("spacefood_db_connection_waits", "Connection acquisitions that had to wait.", nil,
+		func(emit func(float64, ...string)) {
+			emit(float64(db.PoolStats().WaitCount))
+		})
+	telemetry.Default.NewGaugeFunc("spacefood_db_connection_wait_seconds", "Total time spent waiting for a connection.", nil,
+		func(emit func(float64, ...string)) {
+			emit(db.PoolStats().WaitDuration.Seconds())
+		})
+}
@@ -0,0 +1,252 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package apierror defines the error envelope returned by every API
+// endpoint and the catalog of error codes clients can rely on.
+package apierror
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// Error codes. Codes are stable; messages are for people and may change.
+const (
+	CodeBadRequest          = "bad_request"
+	CodeValidationFailed    = "validation_failed"
+	CodeUnauthorized        = "unauthorized"
+	CodeForbidden           = "forbidden"
+	CodeInsufficientScope   = "insufficient_scope"
+	CodeTwoFactorEnrollment = "two_factor_enrollment_required"
+	CodePasswordChange      = "password_change_required"
+	CodeNotFound            = "not_found"
+	CodeConflict            = "conflict"
+	CodeEditConflict        = "edit_conflict"
+	CodeGone                = "gone"
+	CodePayloadTooLarge     = "payload_too_large"
+	CodeUnsupportedMedia    = "unsupported_media_type"
+	CodeIdempotencyReused   = "idempotency_key_reused"
+	CodeRateLimited         = "rate_limited"
+	CodeAIBudgetExceeded    = "ai_budget_exceeded"
+	CodeInternal            = "internal_error"
+	CodeNotImplemented      = "not_implemented"
+	CodeUpstream            = "upstream_error"
+	CodeServiceUnavailable  = "service_unavailable"
+)
+
+// RequestIDHeader carries the request ID set by the RequestID middleware
+const RequestIDHeader = "X-Request-ID"
+
+// internalMessage replaces the details of unexpected errors, which are
+// logged instead of being shown to clients
+const internalMessage = "something went wrong on our side, please try again"
+
+// Error is the body of every error response, wrapped as {"error": {...}}
+type Error struct {
+	Status    int    `json:"-"`
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Details   any    `json:"details,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// FieldError describes one invalid field of a request
+type FieldError struct {
+	Field string `json:"field"`
+	Rule  string `json:"rule"`
+	Param string `json:"param,omitempty"`
+}
+
+// New creates an API error
+func New(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// WithDetails returns a copy of the error carrying extra details
+func (e *Error) WithDetails(details any) *Error {
+	copied := *e
+	copied.Details = details
+	return &copied
+}
+
+// Abort writes an error response and stops the handler chain
+func Abort(c *gin.Context, err *Error) {
+	body := *err
+	body.RequestID = c.Writer.Header().Get(RequestIDHeader)
+	c.AbortWithStatusJSON(body.Status, gin.H{"error": body})
+}
+
+// BadRequest responds 400 for a malformed request or parameter
+func BadRequest(c *gin.Context, message string) {
+	Abort(c, New(http.StatusBadRequest, CodeBadRequest, message))
+}
+
+// Unauthorized responds 401 when the caller is not signed in
+func Unauthorized(c *gin.Context, message string) {
+	Abort(c, New(http.StatusUnauthorized, CodeUnauthorized, message))
+}
+
+// Forbidden responds 403 when the caller may not do this
+func Forbidden(c *gin.Context, message string) {
+	Abort(c, New(http.StatusForbidden, CodeForbidden, message))
+}
+
+// NotFound responds 404
+func NotFound(c *gin.Context, message string) {
+	Abort(c, New(http.StatusNotFound, CodeNotFound, message))
+}
+
+// Conflict responds 409 when the request clashes with existing data
+func Conflict(c *gin.Context, message string) {
+	Abort(c, New(http.StatusConflict, CodeConflict, message))
+}
+
+// Upstream responds 502 when a service this server depends on failed
+func Upstream(c *gin.Context, message string) {
+	Abort(c, New(http.StatusBadGateway, CodeUpstream, message))
+}
+
+// Invalid responds 400 for a request body or query that failed binding.
+// Validation failures list the offending fields in details.
+func Invalid(c *gin.Context, err error) {
+	var validationErrs validator.ValidationErrors
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &validationErrs):
+		fields := make([]FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields = append(fields, FieldError{Field: fieldPath(fe), Rule: fe.Tag(), Param: fe.Param()})
+		}
+		Abort(c, New(http.StatusBadRequest, CodeValidationFailed, "request validation failed").WithDetails(fields))
+	case errors.As(err, &typeErr):
+		Abort(c, New(http.StatusBadRequest, CodeValidationFailed, "request validation failed").WithDetails(
+			[]FieldError{{Field: typeErr.Field, Rule: "type", Param: typeErr.Type.String()}},
+		))
+	case errors.As(err, &syntaxErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		BadRequest(c, "request body is not valid JSON")
+	default:
+		BadRequest(c, err.Error())
+	}
+}
+
+// Internal logs an unexpected error and responds 500 without revealing it
+func Internal(c *gin.Context, err error) {
+	// The request's logger adds its ID, feature and user
+	logger.Ctx(c.Request.Context()).Error().
+		Err(err).
+		Str("method", c.Request.Method).
+		Str("path", c.FullPath()).
+		Msg("Request failed")
+	Abort(c, New(http.StatusInternalServerError, CodeInternal, internalMessage))
+}
+
+// Respond maps an error from a service or the database to a response:
+// API errors as they are, missing rows as 404, uniqueness violations as 409
+// and anything else as 500
+func Respond(c *gin.Context, err error) {
+	var apiErr *Error
+	switch {
+	case errors.As(err, &apiErr):
+		Abort(c, apiErr)
+	case IsNotFound(err):
+		NotFound(c, "not found")
+	case IsConflict(err):
+		Conflict(c, "already exists")
+	default:
+		Internal(c, err)
+	}
+}
+
+// Lookup responds to a failed lookup: 404 with the given message when the
+// record does not exist, otherwise as Respond does
+func Lookup(c *gin.Context, err error, notFoundMessage string) {
+	if IsNotFound(err) {
+		NotFound(c, notFoundMessage)
+		return
+	}
+	Respond(c, err)
+}
+
+// IsNotFound reports whether err means a record does not exist
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Status == http.StatusNotFound
+	}
+	return errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows)
+}
+
+// IsConflict reports whether err is a uniqueness violation
+func IsConflict(err error) bool {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Status == http.StatusConflict
+	}
+	// PostgreSQL reports SQLSTATE 23505; SQLite only says so in the message
+	var stateErr interface{ SQLState() string }
+	if errors.As(err, &stateErr) {
+		return stateErr.SQLState() == "23505"
+	}
+	return err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
+
+// fieldPath names a field by its JSON path rather than the Go struct path
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if i := strings.IndexByte(ns, '.'); i >= 0 {
+		// Drop the struct name
+		return ns[i+1:]
+	}
+	return fe.Field()
+}
+
+func init() {
+	// Report JSON field names, not Go field names, in validation errors
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			for _, tag := range []string{"json", "form"} {
+				name := strings.Split(f.Tag.Get(tag), ",")[0]
+				if name == "-" {
+					return ""
+				}
+				if name != "" {
+					return name
+				}
+			}
+			return f.Name
+		})
+	}
+}
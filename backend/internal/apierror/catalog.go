@@ -0,0 +1,52 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package apierror
+
+import "net/http"
+
+// CodeInfo documents an error code
+type CodeInfo struct {
+	Code        string `json:"code"`
+	Status      int    `json:"status"`
+	Description string `json:"description"`
+}
+
+// Catalog lists every error code the API returns
+var Catalog = []CodeInfo{
+	{CodeBadRequest, http.StatusBadRequest, "The request or one of its parameters is malformed"},
+	{CodeValidationFailed, http.StatusBadRequest, "The request body failed validation; details lists each field and rule"},
+	{CodeUnauthorized, http.StatusUnauthorized, "Missing, invalid or expired credentials"},
+	{CodeForbidden, http.StatusForbidden, "Signed in, but not allowed to do this"},
+	{CodeInsufficientScope, http.StatusForbidden, "The API token's scope does not allow this request"},
+	{CodeTwoFactorEnrollment, http.StatusForbidden, "The instance requires two-factor authentication; enroll first"},
+	{CodePasswordChange, http.StatusForbidden, "An administrator reset the password; change it first"},
+	{CodeNotFound, http.StatusNotFound, "The resource does not exist or is not visible to you"},
+	{CodeConflict, http.StatusConflict, "The request clashes with existing data, such as a duplicate name"},
+	{CodeEditConflict, http.StatusConflict, "Someone saved the record after you loaded it; details has the saved version and how your edit differs"},
+	{CodeGone, http.StatusGone, "The resource existed but has expired or been used up"},
+	{CodePayloadTooLarge, http.StatusRequestEntityTooLarge, "The upload is bigger than the instance allows"},
+	{CodeUnsupportedMedia, http.StatusUnsupportedMediaType, "The upload is not in a supported format"},
+	{CodeIdempotencyReused, http.StatusUnprocessableEntity, "The Idempotency-Key was already used for a request with a different body"},
+	{CodeRateLimited, http.StatusTooManyRequests, "Too many requests; details.retry_after gives the seconds to wait"},
+	{CodeAIBudgetExceeded, http.StatusTooManyRequests, "The AI budget for this period is used up; details.resets_at says when it renews"},
+	{CodeInternal, http.StatusInternalServerError, "An unexpected server error; quote the request_id when reporting it"},
+	{CodeNotImplemented, http.StatusNotImplemented, "The feature is not available on this instance"},
+	{CodeUpstream, http.StatusBadGateway, "A service this server depends on, such as an AI provider, failed"},
+	{CodeServiceUnavailable, http.StatusServiceUnavailable, "The server or one of its dependencies is temporarily unavailable"},
+}
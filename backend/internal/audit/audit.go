@@ -0,0 +1,164 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package audit keeps a trail of sensitive actions: who did what, when
+// and from which address. Handlers record actions with Record; the
+// recorder travels in the request context, put there by Middleware.
+package audit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/config"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/middleware"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// Actions. Each is prefixed by its area, so a filter such as "auth."
+// selects a whole area.
+const (
+	Login                    = "auth.login"
+	LoginFailed              = "auth.login_failed"
+	Registered               = "auth.registered"
+	SessionRevoked           = "auth.session_revoked"
+	SessionsRevoked          = "auth.sessions_revoked"
+	TwoFactorEnabled         = "auth.two_factor_enabled"
+	TwoFactorDisabled        = "auth.two_factor_disabled"
+	RecoveryCodesRegenerated = "auth.recovery_codes_regenerated"
+	APITokenCreated          = "auth.api_token_created"
+	APITokenRevoked          = "auth.api_token_revoked"
+	PasswordChanged          = "auth.password_changed"
+	PasswordResetRequested   = "auth.password_reset_requested"
+	PasswordResetCompleted   = "auth.password_reset_completed"
+	PasswordResetFailed      = "auth.password_reset_failed"
+	HandoffCreated           = "auth.handoff_created"
+
+	HouseholdDeleted   = "household.deleted"
+	MemberAdded        = "household.member_added"
+	MemberRemoved      = "household.member_removed"
+	MemberLeft         = "household.member_left"
+	InvitationCreated  = "household.invitation_created"
+	InvitationRevoked  = "household.invitation_revoked"
+	InvitationAccepted = "household.invitation_accepted"
+
+	RecipeDeleted   = "data.recipe_deleted"
+	MealPlanDeleted = "data.meal_plan_deleted"
+	DataExported    = "data.exported"
+
+	AccountDeletionRequested = "account.deletion_requested"
+	AccountDeletionCancelled = "account.deletion_cancelled"
+
+	UserUpdated       = "admin.user_updated"
+	PasswordReset     = "admin.password_reset"
+	SettingsUpdated   = "admin.settings_updated"
+	BackupDownloaded  = "admin.backup_downloaded"
+	BackupRestored    = "admin.backup_restored"
+	JobTriggered      = "admin.job_triggered"
+	AICacheCleared    = "admin.ai_cache_cleared"
+	AIPromptsReloaded = "admin.ai_prompts_reloaded"
+	ConfigReloaded    = "admin.config_reloaded"
+	SetupCompleted    = "admin.setup_completed"
+)
+
+// contextKey is where Middleware puts the recorder
+const contextKey = "audit"
+
+// Entry describes an action to record
+type Entry struct {
+	Action     string
+	UserID     string // who acted; defaults to the signed-in user
+	TargetType string // e.g. user, household, recipe
+	TargetID   string
+	Details    map[string]any
+}
+
+// Recorder writes audit events to the database
+type Recorder struct {
+	db  database.Database
+	cfg config.AuditConfig
+}
+
+// NewRecorder creates an audit recorder
+func NewRecorder(db database.Database, cfg config.AuditConfig) *Recorder {
+	return &Recorder{db: db, cfg: cfg}
+}
+
+// Middleware makes the recorder available to the handlers after it
+func (r *Recorder) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(contextKey, r)
+		c.Next()
+	}
+}
+
+// Record records an action taken in a request, with the address and user
+// agent it came from. Failing to record is logged rather than failing the
+// request, which has already done what it was asked.
+func Record(c *gin.Context, entry Entry) {
+	value, ok := c.Get(contextKey)
+	if !ok {
+		return
+	}
+	r := value.(*Recorder)
+	if !r.cfg.Enabled {
+		return
+	}
+
+	if entry.UserID == "" {
+		if user, ok := middleware.GetUserFromContext(c); ok {
+			entry.UserID = user.ID
+		}
+	}
+	event := &database.AuditEvent{
+		ID:         uuid.New().String(),
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		Details:    entry.Details,
+		IPAddress:  c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+		CreatedAt:  time.Now().UTC(),
+	}
+	if entry.UserID != "" {
+		event.UserID = &entry.UserID
+	}
+
+	// The request may be cancelled as soon as the response is written
+	ctx := context.WithoutCancel(c.Request.Context())
+	if err := r.db.CreateAuditEvent(ctx, event); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("action", event.Action).Msg("Failed to record audit event")
+	}
+}
+
+// Purge removes audit events older than the retention period. Run by the
+// scheduler.
+func (r *Recorder) Purge(ctx context.Context) (string, error) {
+	if r.cfg.Retention <= 0 {
+		return "retention is unlimited", nil
+	}
+	n, err := r.db.PurgeAuditEvents(ctx, time.Now().AddDate(0, 0, -r.cfg.Retention))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("removed %d audit events", n), nil
+}
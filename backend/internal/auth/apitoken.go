@@ -0,0 +1,69 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// APITokenPrefix marks personal access tokens, so they can be told apart
+// from session JWTs and spotted by secret scanners
+const APITokenPrefix = "sf_"
+
+// API token scopes
+const (
+	ScopeRead         = "read"           // GET requests only
+	ScopeMealLogWrite = "meal-log-write" // read, plus writing nutrition logs
+	ScopeFull         = "full"           // everything except credential management
+)
+
+// IsValidScope reports whether s is a known API token scope
+func IsValidScope(s string) bool {
+	switch s {
+	case ScopeRead, ScopeMealLogWrite, ScopeFull:
+		return true
+	}
+	return false
+}
+
+// IsAPIToken reports whether a bearer token is a personal access token
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, APITokenPrefix)
+}
+
+// GenerateAPIToken returns a new personal access token, the hash to store,
+// and a short display prefix
+func GenerateAPIToken() (token, hash, prefix string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+	token = APITokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return token, HashAPIToken(token), token[:len(APITokenPrefix)+6], nil
+}
+
+// HashAPIToken returns the stored form of a personal access token
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
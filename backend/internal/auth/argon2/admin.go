@@ -0,0 +1,103 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package argon2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/auth"
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// RegistrationMode returns the effective registration mode: the instance
+// setting when an administrator has set one, otherwise the configured default
+func (a *Argon2AuthProvider) RegistrationMode(ctx context.Context) string {
+	if mode, err := a.db.GetInstanceSetting(ctx, auth.RegistrationModeSetting); err == nil && mode != "" {
+		return mode
+	}
+	if a.registration == "" {
+		return auth.RegistrationOpen
+	}
+	return a.registration
+}
+
+// checkRegistration enforces the registration mode for a new account,
+// returning the invitation it registers with when one is required. Used
+// invitations and those addressed to another email are refused.
+func (a *Argon2AuthProvider) checkRegistration(ctx context.Context, email, inviteCode string) (*database.HouseholdInvitation, error) {
+	switch a.RegistrationMode(ctx) {
+	case auth.RegistrationOpen:
+		return nil, nil
+	case auth.RegistrationInviteOnly:
+		if inviteCode == "" {
+			return nil, auth.ErrInviteRequired
+		}
+		invitation, err := a.db.GetHouseholdInvitationByCodeHash(ctx, database.HashInvitationCode(inviteCode))
+		if err != nil ||
+			invitation.Status != database.InvitationStatusPending ||
+			!time.Now().Before(invitation.ExpiresAt) ||
+			(invitation.Email != "" && !strings.EqualFold(invitation.Email, email)) {
+			return nil, auth.ErrInviteRequired
+		}
+		return invitation, nil
+	default:
+		return nil, auth.ErrRegistrationClosed
+	}
+}
+
+// CreateUser creates an account regardless of the registration mode, for
+// administrators and the command line
+func (a *Argon2AuthProvider) CreateUser(ctx context.Context, req auth.RegisterRequest, admin bool) (*auth.User, error) {
+	return a.createUser(ctx, req, admin)
+}
+
+// SetPassword replaces a user's password and signs them out everywhere.
+// With requireChange the user must choose a new password after signing in.
+func (a *Argon2AuthProvider) SetPassword(ctx context.Context, userID, newPassword string, requireChange bool) error {
+	if err := validatePassword(newPassword); err != nil {
+		return err
+	}
+
+	dbUser, err := a.db.GetUserByID(ctx, userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	newHash, err := a.hashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	dbUser.PasswordHash = newHash
+	dbUser.MustChangePassword = requireChange
+	dbUser.UpdatedAt = time.Now()
+	return a.db.WithTx(ctx, func(ctx context.Context) error {
+		if err := a.db.UpdateUser(ctx, dbUser); err != nil {
+			return err
+		}
+		if err := a.db.DeletePasswordResetTokens(ctx, userID); err != nil {
+			return err
+		}
+		return a.db.RevokeAuthSessions(ctx, userID, "")
+	})
+}
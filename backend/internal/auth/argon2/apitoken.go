@@ -0,0 +1,67 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package argon2
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/auth"
+)
+
+// lastUsedResolution limits how often last-used tracking writes to the
+// database for a busy token
+const lastUsedResolution = time.Minute
+
+// validateAPIToken resolves a personal access token to its owner
+func (a *Argon2AuthProvider) validateAPIToken(ctx context.Context, token string) (*auth.User, error) {
+	apiToken, err := a.db.GetAPITokenByHash(ctx, auth.HashAPIToken(token))
+	if err != nil {
+		return nil, errors.New("invalid token")
+	}
+
+	now := time.Now()
+	if apiToken.ExpiresAt != nil && !now.Before(*apiToken.ExpiresAt) {
+		return nil, errors.New("token expired")
+	}
+
+	dbUser, err := a.db.GetUserByID(ctx, apiToken.UserID)
+	if err != nil || !dbUser.Active {
+		return nil, errors.New("user not found")
+	}
+
+	if apiToken.LastUsedAt == nil || now.Sub(*apiToken.LastUsedAt) >= lastUsedResolution {
+		// Tracking is best effort and must not fail the request
+		_ = a.db.TouchAPIToken(ctx, apiToken.ID, now)
+	}
+
+	return &auth.User{
+		ID:                 dbUser.ID,
+		Email:              dbUser.Email,
+		FirstName:          dbUser.FirstName,
+		LastName:           dbUser.LastName,
+		EmailVerified:      dbUser.EmailVerified,
+		Active:             dbUser.Active,
+		IsAdmin:            dbUser.IsAdmin,
+		CreatedAt:          dbUser.CreatedAt,
+		MustChangePassword: dbUser.MustChangePassword,
+		TokenScope:         apiToken.Scope,
+	}, nil
+}
@@ -37,8 +37,8 @@ import (
 
 var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
-	ErrUserAlreadyExists  = errors.New("user already exists")
-	ErrWeakPassword       = errors.New("password does not meet requirements")
+	ErrUserAlreadyExists  = auth.ErrUserAlreadyExists
+	ErrWeakPassword       = auth.ErrWeakPassword
 )
 
 // Argon2AuthProvider implements authentication using Argon2id
@@ -47,6 +47,12 @@ type Argon2AuthProvider struct {
 	jwtSecret     []byte
 	jwtExpiry     time.Duration
 	refreshExpiry time.Duration
+	sessionMaxAge time.Duration
+	resetExpiry   time.Duration
+	handoffExpiry time.Duration
+	totpIssuer    string
+	requireTOTP   bool
+	registration  string
 	argon2Memory  uint32
 	argon2Time    uint32
 	argon2Threads uint8
@@ -61,6 +67,12 @@ func NewArgon2AuthProvider(db database.Database, cfg *config.Config) *Argon2Auth
 		jwtSecret:     []byte(cfg.Auth.JWTSecret),
 		jwtExpiry:     time.Duration(cfg.Auth.JWTExpiry) * time.Minute,
 		refreshExpiry: time.Duration(cfg.Auth.RefreshExpiry) * 24 * time.Hour,
+		sessionMaxAge: time.Duration(cfg.Auth.SessionMaxAge) * 24 * time.Hour,
+		resetExpiry:   time.Duration(cfg.Auth.PasswordResetExpiry) * time.Minute,
+		handoffExpiry: time.Duration(cfg.Auth.HandoffExpiry) * time.Second,
+		totpIssuer:    cfg.Auth.TOTPIssuer,
+		requireTOTP:   cfg.Auth.RequireTwoFactor,
+		registration:  cfg.Auth.Registration,
 		argon2Memory:  cfg.Auth.Argon2Memory,
 		argon2Time:    cfg.Auth.Argon2Time,
 		argon2Threads: cfg.Auth.Argon2Threads,
@@ -69,8 +81,56 @@ func NewArgon2AuthProvider(db database.Database, cfg *config.Config) *Argon2Auth
 	}
 }
 
-// Register creates a new user account
+// Register creates a new user account. Until setup has created the first
+// administrator, which it does under its own lock, nobody may register.
 func (a *Argon2AuthProvider) Register(ctx context.Context, req auth.RegisterRequest) (*auth.User, error) {
+	userCount, err := a.db.CountUsers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count users: %w", err)
+	}
+	if userCount == 0 {
+		return nil, auth.ErrSetupRequired
+	}
+	invitation, err := a.checkRegistration(ctx, req.Email, req.InviteCode)
+	if err != nil {
+		return nil, err
+	}
+	if invitation == nil {
+		return a.createUser(ctx, req, false)
+	}
+
+	// The invitation is used up, and the account joins its household, in
+	// the transaction that creates the account, so each code admits one
+	var user *auth.User
+	err = a.db.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = a.createUser(ctx, req, false)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		accepted, err := a.db.AcceptHouseholdInvitation(ctx, invitation.ID, user.ID, now)
+		if err != nil {
+			return err
+		}
+		if !accepted {
+			return auth.ErrInviteRequired
+		}
+		return a.db.AddHouseholdMember(ctx, &database.HouseholdMember{
+			HouseholdID: invitation.HouseholdID,
+			UserID:      user.ID,
+			Role:        invitation.Role,
+			JoinedAt:    now,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// createUser validates the credentials and stores a new account
+func (a *Argon2AuthProvider) createUser(ctx context.Context, req auth.RegisterRequest, admin bool) (*auth.User, error) {
 	// Validate password strength
 	if err := validatePassword(req.Password); err != nil {
 		return nil, err
@@ -100,6 +160,7 @@ func (a *Argon2AuthProvider) Register(ctx context.Context, req auth.RegisterRequ
 		UpdatedAt:     now,
 		EmailVerified: false,
 		Active:        true,
+		IsAdmin:       admin,
 	}
 
 	if err := a.db.CreateUser(ctx, dbUser); err != nil {
@@ -113,6 +174,7 @@ func (a *Argon2AuthProvider) Register(ctx context.Context, req auth.RegisterRequ
 		LastName:      dbUser.LastName,
 		EmailVerified: dbUser.EmailVerified,
 		Active:        dbUser.Active,
+		IsAdmin:       dbUser.IsAdmin,
 		CreatedAt:     dbUser.CreatedAt,
 	}, nil
 }
@@ -135,6 +197,21 @@ func (a *Argon2AuthProvider) Login(ctx context.Context, req auth.LoginRequest) (
 		return nil, errors.New("account is inactive")
 	}
 
+	// Hold back tokens until the second factor is verified
+	if a.totpEnabled(ctx, dbUser.ID) {
+		mfaToken, err := a.generateMFAToken(dbUser)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate MFA token: %w", err)
+		}
+		return &auth.AuthResponse{MFARequired: true, MFAToken: mfaToken}, nil
+	}
+
+	return a.StartSession(ctx, dbUser, req.UserAgent, req.IPAddress)
+}
+
+// StartSession signs in a user whose identity has already been verified,
+// either by password or by an external identity provider
+func (a *Argon2AuthProvider) StartSession(ctx context.Context, dbUser *database.User, userAgent, ipAddress string) (*auth.AuthResponse, error) {
 	// Update last login time
 	now := time.Now()
 	dbUser.LastLoginAt = &now
@@ -142,15 +219,16 @@ func (a *Argon2AuthProvider) Login(ctx context.Context, req auth.LoginRequest) (
 		// Log error but don't fail login
 	}
 
-	// Generate tokens
-	accessToken, err := a.generateAccessToken(dbUser)
+	// Start a session for this device
+	session, refreshToken, err := a.createSession(ctx, dbUser.ID, userAgent, ipAddress)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate access token: %w", err)
+		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
 
-	refreshToken, err := a.generateRefreshToken(dbUser)
+	// Generate tokens
+	accessToken, err := a.generateAccessToken(dbUser, session.ID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
 
 	return &auth.AuthResponse{
@@ -158,40 +236,43 @@ func (a *Argon2AuthProvider) Login(ctx context.Context, req auth.LoginRequest) (
 		RefreshToken: refreshToken,
 		ExpiresIn:    int(a.jwtExpiry.Seconds()),
 		User: &auth.User{
-			ID:            dbUser.ID,
-			Email:         dbUser.Email,
-			FirstName:     dbUser.FirstName,
-			LastName:      dbUser.LastName,
-			EmailVerified: dbUser.EmailVerified,
-			Active:        dbUser.Active,
-			CreatedAt:     dbUser.CreatedAt,
+			ID:                 dbUser.ID,
+			Email:              dbUser.Email,
+			FirstName:          dbUser.FirstName,
+			LastName:           dbUser.LastName,
+			EmailVerified:      dbUser.EmailVerified,
+			Active:             dbUser.Active,
+			IsAdmin:            dbUser.IsAdmin,
+			CreatedAt:          dbUser.CreatedAt,
+			MustChangePassword: dbUser.MustChangePassword,
+			SessionID:          session.ID,
 		},
 	}, nil
 }
 
 // RefreshToken generates new access token from refresh token
 func (a *Argon2AuthProvider) RefreshToken(ctx context.Context, refreshToken string) (*auth.AuthResponse, error) {
-	// Validate refresh token
-	claims, err := a.validateJWT(refreshToken)
+	// Validate and rotate refresh token
+	session, newRefreshToken, err := a.rotateSession(ctx, refreshToken)
 	if err != nil {
-		return nil, errors.New("invalid refresh token")
+		return nil, err
 	}
 
 	// Get user
-	dbUser, err := a.db.GetUserByID(ctx, claims.UserID)
+	dbUser, err := a.db.GetUserByID(ctx, session.UserID)
 	if err != nil {
 		return nil, errors.New("user not found")
 	}
 
-	// Generate new tokens
-	accessToken, err := a.generateAccessToken(dbUser)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	if !dbUser.Active {
+		_ = a.revokeSession(ctx, session)
+		return nil, errors.New("account is inactive")
 	}
 
-	newRefreshToken, err := a.generateRefreshToken(dbUser)
+	// Generate new access token
+	accessToken, err := a.generateAccessToken(dbUser, session.ID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
 
 	return &auth.AuthResponse{
@@ -199,19 +280,26 @@ func (a *Argon2AuthProvider) RefreshToken(ctx context.Context, refreshToken stri
 		RefreshToken: newRefreshToken,
 		ExpiresIn:    int(a.jwtExpiry.Seconds()),
 		User: &auth.User{
-			ID:            dbUser.ID,
-			Email:         dbUser.Email,
-			FirstName:     dbUser.FirstName,
-			LastName:      dbUser.LastName,
-			EmailVerified: dbUser.EmailVerified,
-			Active:        dbUser.Active,
-			CreatedAt:     dbUser.CreatedAt,
+			ID:                 dbUser.ID,
+			Email:              dbUser.Email,
+			FirstName:          dbUser.FirstName,
+			LastName:           dbUser.LastName,
+			EmailVerified:      dbUser.EmailVerified,
+			Active:             dbUser.Active,
+			IsAdmin:            dbUser.IsAdmin,
+			CreatedAt:          dbUser.CreatedAt,
+			MustChangePassword: dbUser.MustChangePassword,
+			SessionID:          session.ID,
 		},
 	}, nil
 }
 
 // ValidateToken validates an access token and returns user info
 func (a *Argon2AuthProvider) ValidateToken(ctx context.Context, token string) (*auth.User, error) {
+	if auth.IsAPIToken(token) {
+		return a.validateAPIToken(ctx, token)
+	}
+
 	claims, err := a.validateJWT(token)
 	if err != nil {
 		return nil, err
@@ -221,25 +309,33 @@ func (a *Argon2AuthProvider) ValidateToken(ctx context.Context, token string) (*
 	if err != nil {
 		return nil, errors.New("user not found")
 	}
+	if !dbUser.Active {
+		return nil, errors.New("account is inactive")
+	}
+
+	// Tokens die with their session. Tokens without one predate sessions
+	// and would otherwise outlive revocation, so they are refused.
+	if claims.SessionID == "" {
+		return nil, errors.New("token has no session")
+	}
+	if err := a.checkSession(ctx, claims.SessionID, dbUser.ID); err != nil {
+		return nil, err
+	}
 
 	return &auth.User{
-		ID:            dbUser.ID,
-		Email:         dbUser.Email,
-		FirstName:     dbUser.FirstName,
-		LastName:      dbUser.LastName,
-		EmailVerified: dbUser.EmailVerified,
-		Active:        dbUser.Active,
-		CreatedAt:     dbUser.CreatedAt,
+		ID:                 dbUser.ID,
+		Email:              dbUser.Email,
+		FirstName:          dbUser.FirstName,
+		LastName:           dbUser.LastName,
+		EmailVerified:      dbUser.EmailVerified,
+		Active:             dbUser.Active,
+		IsAdmin:            dbUser.IsAdmin,
+		CreatedAt:          dbUser.CreatedAt,
+		MustChangePassword: dbUser.MustChangePassword,
+		SessionID:          claims.SessionID,
 	}, nil
 }
 
-// Logout invalidates user tokens
-func (a *Argon2AuthProvider) Logout(ctx context.Context, userID string) error {
-	// In a stateless JWT system, logout is handled client-side
-	// For a more secure implementation, maintain a token blacklist
-	return nil
-}
-
 // ChangePassword changes user password
 func (a *Argon2AuthProvider) ChangePassword(ctx context.Context, userID string, oldPassword, newPassword string) error {
 	// Validate new password
@@ -255,7 +351,12 @@ func (a *Argon2AuthProvider) ChangePassword(ctx context.Context, userID string,
 
 	// Verify old password
 	if err := a.verifyPassword(oldPassword, dbUser.PasswordHash); err != nil {
-		return errors.New("invalid old password")
+		return auth.ErrWrongPassword
+	}
+
+	// A password an administrator set has to be replaced, not kept
+	if dbUser.MustChangePassword && oldPassword == newPassword {
+		return auth.ErrPasswordReused
 	}
 
 	// Hash new password
@@ -264,10 +365,16 @@ func (a *Argon2AuthProvider) ChangePassword(ctx context.Context, userID string,
 		return fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	// Update user
+	// Update user; reset links sent before the change stop working
 	dbUser.PasswordHash = newHash
+	dbUser.MustChangePassword = false
 	dbUser.UpdatedAt = time.Now()
-	return a.db.UpdateUser(ctx, dbUser)
+	return a.db.WithTx(ctx, func(ctx context.Context) error {
+		if err := a.db.UpdateUser(ctx, dbUser); err != nil {
+			return err
+		}
+		return a.db.DeletePasswordResetTokens(ctx, userID)
+	})
 }
 
 // ResetPassword initiates password reset
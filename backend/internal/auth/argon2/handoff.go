@@ -0,0 +1,93 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package argon2
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/auth"
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Handoff tokens carry a signed-in user over to another device. Like reset
+// tokens they are random secrets stored only as a hash, but they expire
+// within minutes, as they are shown on screen, and redeeming one starts a
+// new session rather than continuing the one that issued it.
+
+// CreateHandoff issues a handoff token for a user
+func (a *Argon2AuthProvider) CreateHandoff(ctx context.Context, userID, target string) (string, time.Time, error) {
+	token, err := generateSessionSecret()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	now := time.Now()
+	handoff := &database.HandoffToken{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		TokenHash: hashSessionSecret(token),
+		Target:    target,
+		CreatedAt: now,
+		ExpiresAt: now.Add(a.handoffExpiry),
+	}
+	if err := a.db.CreateHandoffToken(ctx, handoff); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to store handoff token: %w", err)
+	}
+	return token, handoff.ExpiresAt, nil
+}
+
+// RedeemHandoff signs in with a handoff token and returns the target it
+// was issued for
+func (a *Argon2AuthProvider) RedeemHandoff(ctx context.Context, req auth.HandoffLoginRequest) (*auth.AuthResponse, string, error) {
+	stored, err := a.db.GetHandoffTokenByHash(ctx, hashSessionSecret(req.Token))
+	if apierror.IsNotFound(err) {
+		return nil, "", auth.ErrInvalidHandoffToken
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	now := time.Now()
+	if stored.UsedAt != nil || !now.Before(stored.ExpiresAt) {
+		return nil, "", auth.ErrInvalidHandoffToken
+	}
+
+	dbUser, err := a.db.GetUserByID(ctx, stored.UserID)
+	if err != nil || !dbUser.Active {
+		return nil, "", auth.ErrInvalidHandoffToken
+	}
+
+	// Claimed before signing in, so a code scanned by two devices at once
+	// only signs in one of them
+	used, err := a.db.UseHandoffToken(ctx, stored.ID, now)
+	if err != nil {
+		return nil, "", err
+	}
+	if !used {
+		return nil, "", auth.ErrInvalidHandoffToken
+	}
+
+	resp, err := a.StartSession(ctx, dbUser, req.UserAgent, req.IPAddress)
+	if err != nil {
+		return nil, "", err
+	}
+	return resp, stored.Target, nil
+}
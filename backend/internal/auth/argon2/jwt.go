@@ -29,17 +29,20 @@ import (
 
 // Custom JWT claims
 type jwtClaims struct {
-	UserID string `json:"user_id"`
-	Email  string `json:"email"`
+	UserID    string `json:"user_id"`
+	Email     string `json:"email"`
+	SessionID string `json:"sid,omitempty"`
+	Purpose   string `json:"purpose,omitempty"` // empty for access tokens
 	jwt.RegisteredClaims
 }
 
 // generateAccessToken generates a short-lived access token
-func (a *Argon2AuthProvider) generateAccessToken(user *database.User) (string, error) {
+func (a *Argon2AuthProvider) generateAccessToken(user *database.User, sessionID string) (string, error) {
 	now := time.Now()
 	claims := jwtClaims{
-		UserID: user.ID,
-		Email:  user.Email,
+		UserID:    user.ID,
+		Email:     user.Email,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(now.Add(a.jwtExpiry)),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -51,14 +54,16 @@ func (a *Argon2AuthProvider) generateAccessToken(user *database.User) (string, e
 	return token.SignedString(a.jwtSecret)
 }
 
-// generateRefreshToken generates a long-lived refresh token
-func (a *Argon2AuthProvider) generateRefreshToken(user *database.User) (string, error) {
+// generateMFAToken generates a short-lived token proving the password step
+// of a two-factor login succeeded
+func (a *Argon2AuthProvider) generateMFAToken(user *database.User) (string, error) {
 	now := time.Now()
 	claims := jwtClaims{
-		UserID: user.ID,
-		Email:  user.Email,
+		UserID:  user.ID,
+		Email:   user.Email,
+		Purpose: mfaTokenPurpose,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(now.Add(a.refreshExpiry)),
+			ExpiresAt: jwt.NewNumericDate(now.Add(mfaTokenExpiry)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			Subject:   user.ID,
 		},
@@ -70,6 +75,11 @@ func (a *Argon2AuthProvider) generateRefreshToken(user *database.User) (string,
 
 // validateJWT validates a JWT token and returns the claims
 func (a *Argon2AuthProvider) validateJWT(tokenString string) (*auth.TokenClaims, error) {
+	return a.parseJWT(tokenString, "")
+}
+
+// parseJWT validates a JWT token issued for the given purpose
+func (a *Argon2AuthProvider) parseJWT(tokenString, purpose string) (*auth.TokenClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &jwtClaims{}, func(token *jwt.Token) (interface{}, error) {
 		// Verify signing method
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -82,10 +92,11 @@ func (a *Argon2AuthProvider) validateJWT(tokenString string) (*auth.TokenClaims,
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*jwtClaims); ok && token.Valid {
+	if claims, ok := token.Claims.(*jwtClaims); ok && token.Valid && claims.Purpose == purpose {
 		return &auth.TokenClaims{
 			UserID:    claims.UserID,
 			Email:     claims.Email,
+			SessionID: claims.SessionID,
 			IssuedAt:  claims.IssuedAt.Time,
 			ExpiresAt: claims.ExpiresAt.Time,
 		}, nil
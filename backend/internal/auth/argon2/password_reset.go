@@ -0,0 +1,142 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package argon2
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/auth"
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Password reset tokens are random secrets emailed to the account's
+// address. Only a hash is stored. A token works once and until it expires,
+// and every outstanding token stops working when the password changes.
+
+// CreatePasswordReset issues a reset token for the active account with the
+// email. It returns no user and no error when there is no such account, or
+// the account signs in only through single sign-on.
+func (a *Argon2AuthProvider) CreatePasswordReset(ctx context.Context, email string) (*auth.User, string, error) {
+	dbUser, err := a.db.GetUserByEmail(ctx, email)
+	if apierror.IsNotFound(err) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if !dbUser.Active || dbUser.PasswordHash == "" {
+		return nil, "", nil
+	}
+
+	token, err := generateSessionSecret()
+	if err != nil {
+		return nil, "", err
+	}
+	now := time.Now()
+	if err := a.db.CreatePasswordResetToken(ctx, &database.PasswordResetToken{
+		ID:        uuid.New().String(),
+		UserID:    dbUser.ID,
+		TokenHash: hashSessionSecret(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(a.resetExpiry),
+	}); err != nil {
+		return nil, "", fmt.Errorf("failed to store password reset token: %w", err)
+	}
+
+	return &auth.User{
+		ID:            dbUser.ID,
+		Email:         dbUser.Email,
+		FirstName:     dbUser.FirstName,
+		LastName:      dbUser.LastName,
+		EmailVerified: dbUser.EmailVerified,
+		Active:        dbUser.Active,
+		IsAdmin:       dbUser.IsAdmin,
+		CreatedAt:     dbUser.CreatedAt,
+	}, token, nil
+}
+
+// CompletePasswordReset sets a new password with a reset token, signs the
+// user out everywhere and returns them
+func (a *Argon2AuthProvider) CompletePasswordReset(ctx context.Context, token, newPassword string) (*auth.User, error) {
+	stored, err := a.db.GetPasswordResetTokenByHash(ctx, hashSessionSecret(token))
+	if apierror.IsNotFound(err) {
+		return nil, auth.ErrInvalidResetToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if stored.UsedAt != nil || !now.Before(stored.ExpiresAt) {
+		return nil, auth.ErrInvalidResetToken
+	}
+
+	if err := validatePassword(newPassword); err != nil {
+		return nil, err
+	}
+
+	dbUser, err := a.db.GetUserByID(ctx, stored.UserID)
+	if err != nil || !dbUser.Active {
+		return nil, auth.ErrInvalidResetToken
+	}
+
+	newHash, err := a.hashPassword(newPassword)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+	dbUser.PasswordHash = newHash
+	dbUser.MustChangePassword = false
+	dbUser.UpdatedAt = now
+
+	err = a.db.WithTx(ctx, func(ctx context.Context) error {
+		// Claiming the token first means two requests racing with the same
+		// link can't both set a password
+		used, err := a.db.UsePasswordResetToken(ctx, stored.ID, now)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return auth.ErrInvalidResetToken
+		}
+		if err := a.db.UpdateUser(ctx, dbUser); err != nil {
+			return err
+		}
+		if err := a.db.DeletePasswordResetTokens(ctx, dbUser.ID); err != nil {
+			return err
+		}
+		return a.db.RevokeAuthSessions(ctx, dbUser.ID, "")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &auth.User{
+		ID:            dbUser.ID,
+		Email:         dbUser.Email,
+		FirstName:     dbUser.FirstName,
+		LastName:      dbUser.LastName,
+		EmailVerified: dbUser.EmailVerified,
+		Active:        dbUser.Active,
+		IsAdmin:       dbUser.IsAdmin,
+		CreatedAt:     dbUser.CreatedAt,
+	}, nil
+}
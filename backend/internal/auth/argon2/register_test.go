@@ -0,0 +1,145 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package argon2_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/auth"
+	"github.com/rghsoftware/space-food/internal/auth/argon2"
+	"github.com/rghsoftware/space-food/internal/config"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/database/dbtest"
+)
+
+const password = "correct-horse-battery"
+
+func newProvider(db database.Database, registration string) *argon2.Argon2AuthProvider {
+	cfg := &config.Config{}
+	cfg.Auth.JWTSecret = "test-secret"
+	cfg.Auth.JWTExpiry = 15
+	cfg.Auth.RefreshExpiry = 30
+	cfg.Auth.Registration = registration
+	cfg.Auth.Argon2Memory = 1024
+	cfg.Auth.Argon2Time = 1
+	cfg.Auth.Argon2Threads = 1
+	return argon2.NewArgon2AuthProvider(db, cfg)
+}
+
+func register(ctx context.Context, provider *argon2.Argon2AuthProvider, email, inviteCode string) (*auth.User, error) {
+	return provider.Register(ctx, auth.RegisterRequest{Email: email, Password: password, InviteCode: inviteCode})
+}
+
+func TestRegisterBeforeSetup(t *testing.T) {
+	dbtest.Run(t, func(ctx context.Context, db database.Database) {
+		provider := newProvider(db, auth.RegistrationOpen)
+
+		if _, err := register(ctx, provider, "first@example.com", ""); !errors.Is(err, auth.ErrSetupRequired) {
+			t.Fatalf("Register on an empty instance = %v, want %v", err, auth.ErrSetupRequired)
+		}
+		if count, err := db.CountUsers(ctx); err != nil || count != 0 {
+			t.Fatalf("CountUsers = %d, %v, want no accounts", count, err)
+		}
+
+		// Setup creates the administrator; registration opens after that
+		if _, err := provider.CreateUser(ctx, auth.RegisterRequest{Email: "admin@example.com", Password: password}, true); err != nil {
+			t.Fatalf("CreateUser: %v", err)
+		}
+		user, err := register(ctx, provider, "second@example.com", "")
+		if err != nil {
+			t.Fatalf("Register after setup: %v", err)
+		}
+		if user.IsAdmin {
+			t.Error("a registered account was made an administrator")
+		}
+	})
+}
+
+func TestRegisterWithInvitation(t *testing.T) {
+	dbtest.Run(t, func(ctx context.Context, db database.Database) {
+		provider := newProvider(db, auth.RegistrationInviteOnly)
+		owner := dbtest.User(t, ctx, db)
+		now := time.Now()
+		household := &database.Household{ID: uuid.New().String(), Name: "Home", OwnerID: owner.ID, CreatedAt: now, UpdatedAt: now}
+		if err := db.CreateHousehold(ctx, household); err != nil {
+			t.Fatalf("CreateHousehold: %v", err)
+		}
+		invite := func(code, email string) *database.HouseholdInvitation {
+			invitation := &database.HouseholdInvitation{
+				ID:          uuid.New().String(),
+				HouseholdID: household.ID,
+				InvitedBy:   owner.ID,
+				Email:       email,
+				CodeHash:    database.HashInvitationCode(code),
+				Role:        database.HouseholdRoleParticipant,
+				Status:      database.InvitationStatusPending,
+				ExpiresAt:   now.Add(time.Hour),
+				CreatedAt:   now,
+			}
+			if err := db.CreateHouseholdInvitation(ctx, invitation); err != nil {
+				t.Fatalf("CreateHouseholdInvitation: %v", err)
+			}
+			return invitation
+		}
+		open := invite("open-code", "")
+		invite("addressed-code", "guest@example.com")
+
+		tests := []struct {
+			name  string
+			email string
+			code  string
+			want  error
+		}{
+			{"no code", "a@example.com", "", auth.ErrInviteRequired},
+			{"unknown code", "a@example.com", "no-such-code", auth.ErrInviteRequired},
+			{"addressed to another email", "a@example.com", "addressed-code", auth.ErrInviteRequired},
+			{"open code", "a@example.com", "open-code", nil},
+			{"used code", "b@example.com", "open-code", auth.ErrInviteRequired},
+			{"addressed to this email", "Guest@example.com", "addressed-code", nil},
+		}
+		for _, tt := range tests {
+			user, err := register(ctx, provider, tt.email, tt.code)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("%s: Register = %v, want %v", tt.name, err, tt.want)
+			}
+			if err != nil {
+				if _, err := db.GetUserByEmail(ctx, tt.email); err == nil {
+					t.Errorf("%s: the account was created", tt.name)
+				}
+				continue
+			}
+			member, err := db.GetHouseholdMember(ctx, household.ID, user.ID)
+			if err != nil || member.Role != database.HouseholdRoleParticipant {
+				t.Errorf("%s: membership = %+v, %v, want the invitation's role", tt.name, member, err)
+			}
+		}
+
+		used, err := db.GetHouseholdInvitationByID(ctx, open.ID)
+		if err != nil {
+			t.Fatalf("GetHouseholdInvitationByID: %v", err)
+		}
+		if used.Status != database.InvitationStatusAccepted || used.RespondedBy == nil {
+			t.Errorf("invitation = %s by %v, want accepted by the new account", used.Status, used.RespondedBy)
+		}
+	})
+}
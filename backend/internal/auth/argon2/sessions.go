@@ -0,0 +1,220 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package argon2
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/auth"
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Refresh tokens are opaque strings of the form "<session id>.<secret>".
+// Only a hash of the secret is stored, and the secret is replaced on every
+// refresh, so a stolen token stops working as soon as the real device
+// refreshes. Presenting an already rotated secret revokes the session.
+
+// ListSessions lists the user's active sessions
+func (a *Argon2AuthProvider) ListSessions(ctx context.Context, userID string) ([]*auth.Session, error) {
+	dbSessions, err := a.db.ListActiveAuthSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]*auth.Session, 0, len(dbSessions))
+	for _, s := range dbSessions {
+		sessions = append(sessions, &auth.Session{
+			ID:         s.ID,
+			UserAgent:  s.UserAgent,
+			IPAddress:  s.IPAddress,
+			CreatedAt:  s.CreatedAt,
+			LastUsedAt: s.LastUsedAt,
+			ExpiresAt:  s.ExpiresAt,
+		})
+	}
+	return sessions, nil
+}
+
+// RevokeSession revokes a single session owned by the user
+func (a *Argon2AuthProvider) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	session, err := a.db.GetAuthSessionByID(ctx, sessionID)
+	if err != nil || session.UserID != userID || session.RevokedAt != nil {
+		return auth.ErrSessionNotFound
+	}
+	return a.revokeSession(ctx, session)
+}
+
+// RevokeAllSessions revokes every session of the user except exceptSessionID
+func (a *Argon2AuthProvider) RevokeAllSessions(ctx context.Context, userID, exceptSessionID string) error {
+	return a.db.RevokeAuthSessions(ctx, userID, exceptSessionID)
+}
+
+// Logout revokes the session the refresh token belongs to
+func (a *Argon2AuthProvider) Logout(ctx context.Context, refreshToken string) error {
+	session, _, err := a.lookupSession(ctx, refreshToken)
+	if err != nil {
+		return err
+	}
+	if session.RevokedAt != nil {
+		return nil
+	}
+	return a.revokeSession(ctx, session)
+}
+
+// createSession starts a new session for the user and returns its refresh token
+func (a *Argon2AuthProvider) createSession(ctx context.Context, userID, userAgent, ipAddress string) (*database.AuthSession, string, error) {
+	secret, err := generateSessionSecret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	session := &database.AuthSession{
+		ID:               uuid.New().String(),
+		UserID:           userID,
+		RefreshTokenHash: hashSessionSecret(secret),
+		UserAgent:        userAgent,
+		IPAddress:        ipAddress,
+		CreatedAt:        now,
+		LastUsedAt:       now,
+	}
+	session.ExpiresAt = a.sessionExpiry(session, now)
+
+	if err := a.db.CreateAuthSession(ctx, session); err != nil {
+		return nil, "", err
+	}
+
+	return session, session.ID + "." + secret, nil
+}
+
+// rotateSession validates a refresh token and replaces its secret
+func (a *Argon2AuthProvider) rotateSession(ctx context.Context, refreshToken string) (*database.AuthSession, string, error) {
+	session, secret, err := a.lookupSession(ctx, refreshToken)
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	if session.RevokedAt != nil || !now.Before(session.ExpiresAt) {
+		return nil, "", auth.ErrInvalidRefreshToken
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashSessionSecret(secret)), []byte(session.RefreshTokenHash)) != 1 {
+		// The secret was valid once but has since been rotated, so two
+		// parties hold this session. Revoke it rather than guess which one
+		// is legitimate.
+		_ = a.revokeSession(ctx, session)
+		return nil, "", auth.ErrInvalidRefreshToken
+	}
+
+	newSecret, err := generateSessionSecret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	oldHash := session.RefreshTokenHash
+	session.RefreshTokenHash = hashSessionSecret(newSecret)
+	session.LastUsedAt = now
+	session.ExpiresAt = a.sessionExpiry(session, now)
+
+	// Of several refreshes presenting the same secret only the first
+	// rotates it; the others are reuse, handled as above
+	rotated, err := a.db.RotateAuthSession(ctx, session, oldHash)
+	if err != nil {
+		return nil, "", err
+	}
+	if !rotated {
+		_ = a.revokeSession(ctx, session)
+		return nil, "", auth.ErrInvalidRefreshToken
+	}
+
+	return session, session.ID + "." + newSecret, nil
+}
+
+// lookupSession splits a refresh token and loads its session
+func (a *Argon2AuthProvider) lookupSession(ctx context.Context, refreshToken string) (*database.AuthSession, string, error) {
+	sessionID, secret, ok := strings.Cut(refreshToken, ".")
+	if !ok || secret == "" {
+		return nil, "", auth.ErrInvalidRefreshToken
+	}
+	if _, err := uuid.Parse(sessionID); err != nil {
+		return nil, "", auth.ErrInvalidRefreshToken
+	}
+
+	session, err := a.db.GetAuthSessionByID(ctx, sessionID)
+	if err != nil {
+		return nil, "", auth.ErrInvalidRefreshToken
+	}
+	return session, secret, nil
+}
+
+// checkSession verifies the session an access token was issued for is still active
+func (a *Argon2AuthProvider) checkSession(ctx context.Context, sessionID, userID string) error {
+	session, err := a.db.GetAuthSessionByID(ctx, sessionID)
+	if err != nil || session.UserID != userID {
+		return errors.New("session not found")
+	}
+	if session.RevokedAt != nil {
+		return errors.New("session revoked")
+	}
+	return nil
+}
+
+// revokeSession marks a session as revoked
+func (a *Argon2AuthProvider) revokeSession(ctx context.Context, session *database.AuthSession) error {
+	now := time.Now()
+	session.RevokedAt = &now
+	return a.db.UpdateAuthSession(ctx, session)
+}
+
+// sessionExpiry extends a session by the refresh window, capped at its maximum age
+func (a *Argon2AuthProvider) sessionExpiry(session *database.AuthSession, now time.Time) time.Time {
+	expiresAt := now.Add(a.refreshExpiry)
+	if a.sessionMaxAge > 0 {
+		if limit := session.CreatedAt.Add(a.sessionMaxAge); expiresAt.After(limit) {
+			expiresAt = limit
+		}
+	}
+	return expiresAt
+}
+
+// generateSessionSecret generates the random half of a refresh token
+func generateSessionSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate session secret: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashSessionSecret returns the stored form of a refresh token secret
+func hashSessionSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
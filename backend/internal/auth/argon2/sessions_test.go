@@ -0,0 +1,103 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package argon2_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rghsoftware/space-food/internal/auth"
+	"github.com/rghsoftware/space-food/internal/auth/argon2"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/database/dbtest"
+)
+
+// login creates an account and signs it in
+func login(t *testing.T, ctx context.Context, provider *argon2.Argon2AuthProvider) *auth.AuthResponse {
+	t.Helper()
+	req := auth.RegisterRequest{Email: "user@example.com", Password: password}
+	if _, err := provider.CreateUser(ctx, req, false); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	resp, err := provider.Login(ctx, auth.LoginRequest{Email: req.Email, Password: password})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	return resp
+}
+
+func TestRevokeAllSessions(t *testing.T) {
+	dbtest.Run(t, func(ctx context.Context, db database.Database) {
+		provider := newProvider(db, auth.RegistrationOpen)
+		resp := login(t, ctx, provider)
+		user := resp.User
+
+		// An access token signed with the server secret but issued for no
+		// session, like the refresh tokens of earlier versions
+		now := time.Now()
+		sessionless, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"user_id": user.ID,
+			"email":   user.Email,
+			"sub":     user.ID,
+			"iat":     now.Unix(),
+			"exp":     now.Add(30 * 24 * time.Hour).Unix(),
+		}).SignedString([]byte("test-secret"))
+		if err != nil {
+			t.Fatalf("signing: %v", err)
+		}
+
+		if _, err := provider.ValidateToken(ctx, resp.AccessToken); err != nil {
+			t.Fatalf("ValidateToken before revoking: %v", err)
+		}
+		if err := provider.RevokeAllSessions(ctx, user.ID, ""); err != nil {
+			t.Fatalf("RevokeAllSessions: %v", err)
+		}
+
+		for name, token := range map[string]string{"session": resp.AccessToken, "sessionless": sessionless} {
+			if _, err := provider.ValidateToken(ctx, token); err == nil {
+				t.Errorf("%s access token accepted after revoking all sessions", name)
+			}
+		}
+		if _, err := provider.RefreshToken(ctx, resp.RefreshToken); err == nil {
+			t.Error("refresh token accepted after revoking all sessions")
+		}
+	})
+}
+
+func TestRefreshTokenReuse(t *testing.T) {
+	dbtest.Run(t, func(ctx context.Context, db database.Database) {
+		provider := newProvider(db, auth.RegistrationOpen)
+		resp := login(t, ctx, provider)
+
+		rotated, err := provider.RefreshToken(ctx, resp.RefreshToken)
+		if err != nil {
+			t.Fatalf("RefreshToken: %v", err)
+		}
+		if _, err := provider.RefreshToken(ctx, resp.RefreshToken); err != auth.ErrInvalidRefreshToken {
+			t.Fatalf("reusing a rotated refresh token = %v, want %v", err, auth.ErrInvalidRefreshToken)
+		}
+
+		// Reuse ends the session for whoever holds it
+		if _, err := provider.RefreshToken(ctx, rotated.RefreshToken); err == nil {
+			t.Error("the session survived its refresh token being reused")
+		}
+	})
+}
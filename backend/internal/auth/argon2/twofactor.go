@@ -0,0 +1,249 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package argon2
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/auth"
+	"github.com/rghsoftware/space-food/internal/auth/totp"
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+const (
+	mfaTokenPurpose   = "mfa"
+	mfaTokenExpiry    = 5 * time.Minute
+	recoveryCodeCount = 10
+
+	// Second-factor attempts allowed before they are locked, however many
+	// addresses and login attempts they come from, and for how long
+	maxTwoFactorAttempts = 5
+	twoFactorLockout     = 15 * time.Minute
+
+	// Recovery codes avoid characters that are easy to misread
+	recoveryCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
+// TwoFactorStatus reports the user's enrollment state
+func (a *Argon2AuthProvider) TwoFactorStatus(ctx context.Context, userID string) (*auth.TwoFactorStatus, error) {
+	status := &auth.TwoFactorStatus{Required: a.requireTOTP}
+	if !a.totpEnabled(ctx, userID) {
+		return status, nil
+	}
+
+	remaining, err := a.db.CountRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	status.Enabled = true
+	status.RecoveryCodesRemaining = remaining
+	return status, nil
+}
+
+// BeginTOTPEnrollment generates a new secret awaiting confirmation
+func (a *Argon2AuthProvider) BeginTOTPEnrollment(ctx context.Context, userID string) (*auth.TOTPEnrollment, error) {
+	dbUser, err := a.db.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if a.totpEnabled(ctx, userID) {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	enrollment := &database.UserTOTP{
+		UserID:    userID,
+		Secret:    secret,
+		Enabled:   false,
+		CreatedAt: time.Now(),
+	}
+	if err := a.db.UpsertUserTOTP(ctx, enrollment); err != nil {
+		return nil, err
+	}
+
+	return &auth.TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(a.totpIssuer, dbUser.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTPEnrollment enables TOTP once the user proves their app works
+func (a *Argon2AuthProvider) ConfirmTOTPEnrollment(ctx context.Context, userID, code string) ([]string, error) {
+	enrollment, err := a.db.GetUserTOTP(ctx, userID)
+	if err != nil {
+		return nil, errors.New("no enrollment in progress")
+	}
+	if enrollment.Enabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	step, ok := totp.Validate(enrollment.Secret, code, time.Now())
+	if !ok {
+		return nil, auth.ErrInvalidTwoFactorCode
+	}
+
+	now := time.Now()
+	enrollment.Enabled = true
+	enrollment.LastUsedStep = step
+	enrollment.ConfirmedAt = &now
+	if err := a.db.UpsertUserTOTP(ctx, enrollment); err != nil {
+		return nil, err
+	}
+
+	return a.issueRecoveryCodes(ctx, userID)
+}
+
+// DisableTOTP removes the enrollment after checking a current code
+func (a *Argon2AuthProvider) DisableTOTP(ctx context.Context, userID, code string) error {
+	if a.requireTOTP {
+		return auth.ErrTwoFactorEnforced
+	}
+	if err := a.verifySecondFactor(ctx, userID, code); err != nil {
+		return err
+	}
+	return a.db.DeleteUserTOTP(ctx, userID)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes after checking a current code
+func (a *Argon2AuthProvider) RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error) {
+	if err := a.verifySecondFactor(ctx, userID, code); err != nil {
+		return nil, err
+	}
+	return a.issueRecoveryCodes(ctx, userID)
+}
+
+// VerifyTwoFactor completes a login that returned MFARequired
+func (a *Argon2AuthProvider) VerifyTwoFactor(ctx context.Context, req auth.TwoFactorLoginRequest) (*auth.AuthResponse, error) {
+	claims, err := a.parseJWT(req.MFAToken, mfaTokenPurpose)
+	if err != nil {
+		return nil, errors.New("login attempt expired, please sign in again")
+	}
+
+	if err := a.verifySecondFactor(ctx, claims.UserID, req.Code); err != nil {
+		return nil, err
+	}
+
+	dbUser, err := a.db.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if !dbUser.Active {
+		return nil, errors.New("account is inactive")
+	}
+
+	return a.StartSession(ctx, dbUser, req.UserAgent, req.IPAddress)
+}
+
+// verifySecondFactor accepts either a current authenticator code, which may
+// only be used once, or an unused recovery code. Every attempt counts
+// against the user's limit until one succeeds; after maxTwoFactorAttempts
+// attempts are locked for twoFactorLockout, and each one made after that
+// locks them again until a code is accepted.
+func (a *Argon2AuthProvider) verifySecondFactor(ctx context.Context, userID, code string) error {
+	enrollment, err := a.db.GetUserTOTP(ctx, userID)
+	if err != nil || !enrollment.Enabled {
+		return errors.New("two-factor authentication is not enabled")
+	}
+
+	now := time.Now()
+	allowed, err := a.db.ClaimTOTPAttempt(ctx, userID, now, maxTwoFactorAttempts, now.Add(twoFactorLockout))
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return auth.ErrTwoFactorLocked
+	}
+
+	if step, ok := totp.Validate(enrollment.Secret, code, now); ok {
+		fresh, err := a.db.AdvanceTOTPStep(ctx, userID, step)
+		if err != nil {
+			return err
+		}
+		if !fresh {
+			return auth.ErrInvalidTwoFactorCode
+		}
+		return a.db.ResetTOTPAttempts(ctx, userID)
+	}
+
+	used, err := a.db.ConsumeRecoveryCode(ctx, userID, hashRecoveryCode(code))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return auth.ErrInvalidTwoFactorCode
+	}
+	return a.db.ResetTOTPAttempts(ctx, userID)
+}
+
+// totpEnabled reports whether the user has a confirmed TOTP enrollment
+func (a *Argon2AuthProvider) totpEnabled(ctx context.Context, userID string) bool {
+	enrollment, err := a.db.GetUserTOTP(ctx, userID)
+	return err == nil && enrollment.Enabled
+}
+
+// issueRecoveryCodes generates and stores a new set of recovery codes,
+// returning them in plain text for the user to write down
+func (a *Argon2AuthProvider) issueRecoveryCodes(ctx context.Context, userID string) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		hashes[i] = hashRecoveryCode(code)
+	}
+
+	if err := a.db.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// generateRecoveryCode returns a random code formatted as XXXXX-XXXXX
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate recovery code: %w", err)
+	}
+	for i := range b {
+		b[i] = recoveryCodeAlphabet[int(b[i])%len(recoveryCodeAlphabet)]
+	}
+	return string(b[:5]) + "-" + string(b[5:]), nil
+}
+
+// hashRecoveryCode returns the stored form of a recovery code, ignoring
+// case and separators
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,93 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package argon2_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/auth"
+	"github.com/rghsoftware/space-food/internal/auth/argon2"
+	"github.com/rghsoftware/space-food/internal/auth/totp"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/database/dbtest"
+)
+
+// enrollTOTP turns on two-factor authentication for the user and returns
+// their recovery codes
+func enrollTOTP(t *testing.T, ctx context.Context, provider *argon2.Argon2AuthProvider, userID string) []string {
+	t.Helper()
+	enrollment, err := provider.BeginTOTPEnrollment(ctx, userID)
+	if err != nil {
+		t.Fatalf("BeginTOTPEnrollment: %v", err)
+	}
+	code, err := totp.Code(enrollment.Secret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatalf("totp.Code: %v", err)
+	}
+	recoveryCodes, err := provider.ConfirmTOTPEnrollment(ctx, userID, code)
+	if err != nil {
+		t.Fatalf("ConfirmTOTPEnrollment: %v", err)
+	}
+	return recoveryCodes
+}
+
+func TestTwoFactorAttemptLimit(t *testing.T) {
+	dbtest.Run(t, func(ctx context.Context, db database.Database) {
+		provider := newProvider(db, auth.RegistrationOpen)
+		user := login(t, ctx, provider).User
+		recoveryCodes := enrollTOTP(t, ctx, provider, user.ID)
+
+		// Each attempt comes with a fresh login, as it would from another address
+		verify := func(code string) error {
+			t.Helper()
+			resp, err := provider.Login(ctx, auth.LoginRequest{Email: user.Email, Password: password})
+			if err != nil || !resp.MFARequired {
+				t.Fatalf("Login = %+v, %v, want a two-factor challenge", resp, err)
+			}
+			_, err = provider.VerifyTwoFactor(ctx, auth.TwoFactorLoginRequest{MFAToken: resp.MFAToken, Code: code})
+			return err
+		}
+		guess := func(n int) {
+			t.Helper()
+			for i := 0; i < n; i++ {
+				if err := verify("WRONG-GUESS"); !errors.Is(err, auth.ErrInvalidTwoFactorCode) {
+					t.Fatalf("wrong code %d = %v, want %v", i+1, err, auth.ErrInvalidTwoFactorCode)
+				}
+			}
+		}
+
+		// An accepted code starts the count again
+		guess(4)
+		if err := verify(recoveryCodes[0]); err != nil {
+			t.Fatalf("recovery code after 4 wrong codes: %v", err)
+		}
+		guess(5)
+
+		// Locked now, even for a right code
+		if err := verify(recoveryCodes[1]); !errors.Is(err, auth.ErrTwoFactorLocked) {
+			t.Fatalf("recovery code after 5 wrong codes = %v, want %v", err, auth.ErrTwoFactorLocked)
+		}
+		if remaining, err := db.CountRecoveryCodes(ctx, user.ID); err != nil || remaining != len(recoveryCodes)-1 {
+			t.Errorf("CountRecoveryCodes = %d, %v, want the refused code left unused", remaining, err)
+		}
+	})
+}
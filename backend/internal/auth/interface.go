@@ -20,9 +20,38 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
+var (
+	ErrInvalidRefreshToken   = errors.New("invalid refresh token")
+	ErrSessionNotFound       = errors.New("session not found")
+	ErrPasswordLoginDisabled = errors.New("password login is disabled; use single sign-on")
+	ErrInvalidTwoFactorCode  = errors.New("invalid two-factor code")
+	ErrTwoFactorEnforced     = errors.New("two-factor authentication is required on this instance")
+	ErrTwoFactorLocked       = errors.New("too many wrong two-factor codes; try again later")
+	ErrRegistrationClosed    = errors.New("registration is closed on this instance")
+	ErrInviteRequired        = errors.New("a valid invitation code is required to register")
+	ErrSetupRequired         = errors.New("this instance has not been set up yet; complete setup first")
+	ErrInvalidResetToken     = errors.New("this password reset link is invalid, used or expired")
+	ErrInvalidHandoffToken   = errors.New("this handoff code is invalid, used or expired")
+	ErrUserAlreadyExists     = errors.New("user already exists")
+	ErrWeakPassword          = errors.New("password does not meet requirements")
+	ErrWrongPassword         = errors.New("invalid old password")
+	ErrPasswordReused        = errors.New("choose a new password rather than the one you were given")
+)
+
+// Registration modes, configured by administrators
+const (
+	RegistrationOpen       = "open"
+	RegistrationInviteOnly = "invite_only"
+	RegistrationClosed     = "closed"
+
+	// RegistrationModeSetting is the instance setting overriding auth.registration
+	RegistrationModeSetting = "registration_mode"
+)
+
 // AuthProvider defines the contract for authentication implementations
 type AuthProvider interface {
 	// Register creates a new user account
@@ -37,8 +66,17 @@ type AuthProvider interface {
 	// ValidateToken validates an access token and returns user info
 	ValidateToken(ctx context.Context, token string) (*User, error)
 
-	// Logout invalidates user tokens
-	Logout(ctx context.Context, userID string) error
+	// Logout revokes the session the refresh token belongs to
+	Logout(ctx context.Context, refreshToken string) error
+
+	// ListSessions lists the user's active sessions
+	ListSessions(ctx context.Context, userID string) ([]*Session, error)
+
+	// RevokeSession revokes a single session owned by the user
+	RevokeSession(ctx context.Context, userID, sessionID string) error
+
+	// RevokeAllSessions revokes every session of the user except exceptSessionID
+	RevokeAllSessions(ctx context.Context, userID, exceptSessionID string) error
 
 	// ChangePassword changes user password
 	ChangePassword(ctx context.Context, userID string, oldPassword, newPassword string) error
@@ -50,6 +88,78 @@ type AuthProvider interface {
 	VerifyEmail(ctx context.Context, token string) error
 }
 
+// SSOProvider is implemented by auth providers that can sign users in
+// through an external identity provider using the authorization code flow
+type SSOProvider interface {
+	// AuthCodeURL returns the identity provider URL to send the browser to
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+
+	// CompleteSSOLogin exchanges the authorization code and signs the user in
+	CompleteSSOLogin(ctx context.Context, req SSOLoginRequest) (*AuthResponse, error)
+}
+
+// TwoFactorProvider is implemented by auth providers supporting TOTP
+// two-factor authentication. When a user has it enabled, Login returns an
+// AuthResponse with MFARequired set and VerifyTwoFactor completes the login.
+type TwoFactorProvider interface {
+	// TwoFactorStatus reports the user's enrollment state
+	TwoFactorStatus(ctx context.Context, userID string) (*TwoFactorStatus, error)
+
+	// BeginTOTPEnrollment generates a new secret awaiting confirmation
+	BeginTOTPEnrollment(ctx context.Context, userID string) (*TOTPEnrollment, error)
+
+	// ConfirmTOTPEnrollment enables TOTP once the user proves their app works
+	// and returns a fresh set of recovery codes
+	ConfirmTOTPEnrollment(ctx context.Context, userID, code string) ([]string, error)
+
+	// DisableTOTP removes the enrollment after checking a current code
+	DisableTOTP(ctx context.Context, userID, code string) error
+
+	// RegenerateRecoveryCodes replaces the recovery codes after checking a current code
+	RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error)
+
+	// VerifyTwoFactor completes a login that returned MFARequired
+	VerifyTwoFactor(ctx context.Context, req TwoFactorLoginRequest) (*AuthResponse, error)
+}
+
+// AdminProvider is implemented by auth providers that let administrators
+// manage other users' credentials
+type AdminProvider interface {
+	// SetPassword replaces a user's password and signs them out everywhere.
+	// With requireChange the user must choose a new password after signing in.
+	SetPassword(ctx context.Context, userID, newPassword string, requireChange bool) error
+
+	// CreateUser creates an account regardless of the registration mode
+	CreateUser(ctx context.Context, req RegisterRequest, admin bool) (*User, error)
+}
+
+// PasswordResetProvider is implemented by auth providers that let users
+// who forgot their password choose a new one with a single-use token,
+// sent to them by email
+type PasswordResetProvider interface {
+	// CreatePasswordReset issues a reset token for the active account with
+	// the email. It returns no user and no error when there is no such
+	// account, so callers answer the same either way.
+	CreatePasswordReset(ctx context.Context, email string) (*User, string, error)
+
+	// CompletePasswordReset sets a new password with a reset token, signs
+	// the user out everywhere and returns them
+	CompletePasswordReset(ctx context.Context, token, newPassword string) (*User, error)
+}
+
+// HandoffProvider is implemented by auth providers that can sign a user in
+// on another device, such as a kitchen tablet, with a short-lived
+// single-use token shown there as a QR code
+type HandoffProvider interface {
+	// CreateHandoff issues a handoff token for a user. target says what the
+	// other device opens once signed in.
+	CreateHandoff(ctx context.Context, userID, target string) (string, time.Time, error)
+
+	// RedeemHandoff signs in with a handoff token and returns the target it
+	// was issued for
+	RedeemHandoff(ctx context.Context, req HandoffLoginRequest) (*AuthResponse, string, error)
+}
+
 // User represents an authenticated user
 type User struct {
 	ID            string
@@ -58,35 +168,97 @@ type User struct {
 	LastName      string
 	EmailVerified bool
 	Active        bool
+	IsAdmin       bool
 	CreatedAt     time.Time
+	SessionID     string `json:"-"` // session the presented token was issued for
+	TokenScope    string `json:"-"` // API token scope; empty for session tokens
+
+	// MustChangePassword is set after an administrator resets the password;
+	// until the user changes it only the password and session routes work
+	MustChangePassword bool
 }
 
 // RegisterRequest contains user registration data
 type RegisterRequest struct {
-	Email     string
-	Password  string
-	FirstName string
-	LastName  string
+	Email      string
+	Password   string
+	FirstName  string
+	LastName   string
+	InviteCode string // household invitation code, required when registration is invite-only
 }
 
 // LoginRequest contains user login credentials
 type LoginRequest struct {
-	Email    string
-	Password string
+	Email     string
+	Password  string
+	UserAgent string `json:"-"`
+	IPAddress string `json:"-"`
+}
+
+// SSOLoginRequest contains the result of an identity provider redirect
+type SSOLoginRequest struct {
+	Code         string
+	CodeVerifier string
+	Nonce        string
+	UserAgent    string
+	IPAddress    string
+}
+
+// TwoFactorLoginRequest contains the second step of a two-factor login
+type TwoFactorLoginRequest struct {
+	MFAToken  string
+	Code      string // authenticator code or recovery code
+	UserAgent string
+	IPAddress string
 }
 
-// AuthResponse contains authentication tokens and user info
+// HandoffLoginRequest contains a scanned handoff token
+type HandoffLoginRequest struct {
+	Token     string
+	UserAgent string
+	IPAddress string
+}
+
+// AuthResponse contains authentication tokens and user info. When
+// MFARequired is set only MFAToken is filled in.
 type AuthResponse struct {
 	AccessToken  string
 	RefreshToken string
 	ExpiresIn    int // seconds
 	User         *User
+	MFARequired  bool   `json:",omitempty"`
+	MFAToken     string `json:",omitempty"`
 }
 
 // TokenClaims represents JWT token claims
 type TokenClaims struct {
 	UserID    string
 	Email     string
+	SessionID string
 	IssuedAt  time.Time
 	ExpiresAt time.Time
 }
+
+// Session represents a signed-in device
+type Session struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+// TwoFactorStatus describes a user's two-factor enrollment
+type TwoFactorStatus struct {
+	Enabled                bool `json:"enabled"`
+	Required               bool `json:"required"` // enforced by instance policy
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+// TOTPEnrollment contains what an authenticator app needs to enroll
+type TOTPEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"` // otpauth:// URI to render as a QR code
+}
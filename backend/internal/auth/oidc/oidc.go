@@ -0,0 +1,376 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/auth"
+	"github.com/rghsoftware/space-food/internal/auth/argon2"
+	"github.com/rghsoftware/space-food/internal/config"
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+var (
+	ErrNoAccount    = errors.New("no account is linked to this identity and automatic provisioning is disabled")
+	ErrEmailInUse   = errors.New("an account with this email already exists but the identity provider has not verified the address")
+	ErrMissingEmail = errors.New("identity provider did not supply an email address")
+)
+
+// OIDCAuthProvider signs users in through an OpenID Connect identity
+// provider. Sessions and tokens are issued by the wrapped Argon2 provider,
+// which also keeps serving password logins unless they are disabled.
+type OIDCAuthProvider struct {
+	*argon2.Argon2AuthProvider
+
+	db              database.Database
+	cfg             config.OIDCConfig
+	disablePassword bool
+	httpClient      *http.Client
+
+	mu            sync.Mutex
+	doc           *discoveryDocument
+	docFetchedAt  time.Time
+	keys          map[string]any
+	keysFetchedAt time.Time
+}
+
+// NewOIDCAuthProvider creates a new OIDC authentication provider
+func NewOIDCAuthProvider(db database.Database, cfg *config.Config, base *argon2.Argon2AuthProvider) *OIDCAuthProvider {
+	return &OIDCAuthProvider{
+		Argon2AuthProvider: base,
+		db:                 db,
+		cfg:                cfg.Auth.OIDC,
+		disablePassword:    cfg.Auth.DisablePasswordLogin,
+		httpClient:         &http.Client{Timeout: 10 * time.Second},
+		keys:               map[string]any{},
+	}
+}
+
+// Register creates a new password account unless password login is disabled
+func (p *OIDCAuthProvider) Register(ctx context.Context, req auth.RegisterRequest) (*auth.User, error) {
+	if p.disablePassword {
+		return nil, auth.ErrPasswordLoginDisabled
+	}
+	return p.Argon2AuthProvider.Register(ctx, req)
+}
+
+// Login authenticates with a password unless password login is disabled
+func (p *OIDCAuthProvider) Login(ctx context.Context, req auth.LoginRequest) (*auth.AuthResponse, error) {
+	if p.disablePassword {
+		return nil, auth.ErrPasswordLoginDisabled
+	}
+	return p.Argon2AuthProvider.Login(ctx, req)
+}
+
+// ChangePassword changes user password unless password login is disabled
+func (p *OIDCAuthProvider) ChangePassword(ctx context.Context, userID string, oldPassword, newPassword string) error {
+	if p.disablePassword {
+		return auth.ErrPasswordLoginDisabled
+	}
+	return p.Argon2AuthProvider.ChangePassword(ctx, userID, oldPassword, newPassword)
+}
+
+// CreatePasswordReset issues a reset token unless password login is disabled
+func (p *OIDCAuthProvider) CreatePasswordReset(ctx context.Context, email string) (*auth.User, string, error) {
+	if p.disablePassword {
+		return nil, "", auth.ErrPasswordLoginDisabled
+	}
+	return p.Argon2AuthProvider.CreatePasswordReset(ctx, email)
+}
+
+// CompletePasswordReset sets a new password with a reset token unless
+// password login is disabled
+func (p *OIDCAuthProvider) CompletePasswordReset(ctx context.Context, token, newPassword string) (*auth.User, error) {
+	if p.disablePassword {
+		return nil, auth.ErrPasswordLoginDisabled
+	}
+	return p.Argon2AuthProvider.CompletePasswordReset(ctx, token, newPassword)
+}
+
+// AuthCodeURL returns the identity provider URL to send the browser to
+func (p *OIDCAuthProvider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	doc, err := p.discovery(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.cfg.RedirectURL},
+		"scope":                 {strings.Join(p.scopes(), " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+
+	sep := "?"
+	if strings.Contains(doc.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return doc.AuthorizationEndpoint + sep + params.Encode(), nil
+}
+
+// CompleteSSOLogin exchanges the authorization code and signs the user in
+func (p *OIDCAuthProvider) CompleteSSOLogin(ctx context.Context, req auth.SSOLoginRequest) (*auth.AuthResponse, error) {
+	doc, err := p.discovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := p.exchangeCode(ctx, doc, req.Code, req.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := p.verifyIDToken(ctx, doc, token.IDToken, req.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	// Some providers only put profile and group claims in userinfo
+	if doc.UserinfoEndpoint != "" && token.AccessToken != "" && (stringClaim(claims, "email") == "" || claims[p.groupsClaim()] == nil) {
+		p.mergeUserinfo(ctx, doc, token.AccessToken, claims)
+	}
+
+	dbUser, err := p.resolveUser(ctx, doc.Issuer, claims)
+	if err != nil {
+		return nil, err
+	}
+	if !dbUser.Active {
+		return nil, errors.New("account is inactive")
+	}
+
+	if err := p.syncHouseholds(ctx, dbUser.ID, stringsClaim(claims, p.groupsClaim())); err != nil {
+		return nil, fmt.Errorf("failed to apply household mapping: %w", err)
+	}
+
+	return p.StartSession(ctx, dbUser, req.UserAgent, req.IPAddress)
+}
+
+// resolveUser finds the local account for an identity, linking or creating
+// one on first login
+func (p *OIDCAuthProvider) resolveUser(ctx context.Context, issuer string, claims jwt.MapClaims) (*database.User, error) {
+	subject := stringClaim(claims, "sub")
+
+	if identity, err := p.db.GetUserIdentity(ctx, issuer, subject); err == nil {
+		return p.db.GetUserByID(ctx, identity.UserID)
+	}
+
+	email := stringClaim(claims, "email")
+	if email == "" {
+		return nil, ErrMissingEmail
+	}
+	emailVerified := boolClaim(claims, "email_verified")
+
+	// Link an existing password account only when the provider vouches for
+	// the address, otherwise anyone could claim it by typing it in
+	dbUser, err := p.db.GetUserByEmail(ctx, email)
+	provisioned := err != nil
+	if err == nil {
+		if !emailVerified {
+			return nil, ErrEmailInUse
+		}
+	} else {
+		if !p.cfg.AutoProvision {
+			return nil, ErrNoAccount
+		}
+
+		// Invitation codes cannot travel through the identity provider
+		// redirect, so only open registration provisions accounts. The
+		// first account is the administrator setup creates.
+		userCount, err := p.db.CountUsers(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count users: %w", err)
+		}
+		if userCount == 0 {
+			return nil, auth.ErrSetupRequired
+		}
+		if p.RegistrationMode(ctx) != auth.RegistrationOpen {
+			return nil, auth.ErrRegistrationClosed
+		}
+
+		firstName, lastName := nameClaims(claims)
+		now := time.Now()
+		dbUser = &database.User{
+			ID:            uuid.New().String(),
+			Email:         email,
+			PasswordHash:  "", // SSO-only accounts cannot log in with a password
+			FirstName:     firstName,
+			LastName:      lastName,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+			EmailVerified: emailVerified,
+			Active:        true,
+		}
+	}
+
+	identity := &database.UserIdentity{
+		Provider:  issuer,
+		Subject:   subject,
+		UserID:    dbUser.ID,
+		CreatedAt: time.Now(),
+	}
+
+	// A provisioned account without its identity could never sign in again
+	err = p.db.WithTx(ctx, func(ctx context.Context) error {
+		if provisioned {
+			if err := p.db.CreateUser(ctx, dbUser); err != nil {
+				return fmt.Errorf("failed to create user: %w", err)
+			}
+		}
+		if err := p.db.CreateUserIdentity(ctx, identity); err != nil {
+			return fmt.Errorf("failed to link identity: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dbUser, nil
+}
+
+// syncHouseholds adds the user to every household mapped to one of their
+// identity provider groups. Memberships are never removed here, so people
+// invited to a household directly keep their access.
+func (p *OIDCAuthProvider) syncHouseholds(ctx context.Context, userID string, groups []string) error {
+	if len(p.cfg.HouseholdGroups) == 0 {
+		return nil
+	}
+
+	for _, group := range groups {
+		householdID, ok := p.cfg.HouseholdGroups[strings.ToLower(group)]
+		if !ok {
+			continue
+		}
+		if _, err := p.db.GetHouseholdMember(ctx, householdID, userID); err == nil {
+			continue
+		}
+		if _, err := p.db.GetHouseholdByID(ctx, householdID); err != nil {
+			// A stale mapping should not lock people out
+			continue
+		}
+
+		member := &database.HouseholdMember{
+			HouseholdID: householdID,
+			UserID:      userID,
+			Role:        database.HouseholdRoleMember,
+			JoinedAt:    time.Now(),
+		}
+		if err := p.db.AddHouseholdMember(ctx, member); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeUserinfo fills claims missing from the ID token from the userinfo endpoint
+func (p *OIDCAuthProvider) mergeUserinfo(ctx context.Context, doc *discoveryDocument, accessToken string, claims jwt.MapClaims) {
+	info := map[string]any{}
+	if err := p.getJSON(ctx, doc.UserinfoEndpoint, accessToken, &info); err != nil {
+		return
+	}
+	// Userinfo must describe the same subject as the ID token
+	if sub, _ := info["sub"].(string); sub != stringClaim(claims, "sub") {
+		return
+	}
+	for k, v := range info {
+		if _, ok := claims[k]; !ok {
+			claims[k] = v
+		}
+	}
+}
+
+func (p *OIDCAuthProvider) scopes() []string {
+	scopes := p.cfg.Scopes
+	for _, s := range scopes {
+		if s == "openid" {
+			return scopes
+		}
+	}
+	return append([]string{"openid"}, scopes...)
+}
+
+func (p *OIDCAuthProvider) groupsClaim() string {
+	if p.cfg.GroupsClaim == "" {
+		return "groups"
+	}
+	return p.cfg.GroupsClaim
+}
+
+// stringClaim returns a string claim or ""
+func stringClaim(claims jwt.MapClaims, name string) string {
+	v, _ := claims[name].(string)
+	return v
+}
+
+// boolClaim returns a boolean claim, accepting "true" as some providers send strings
+func boolClaim(claims jwt.MapClaims, name string) bool {
+	switch v := claims[name].(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	}
+	return false
+}
+
+// stringsClaim returns a list claim, accepting a single string as a one-element list
+func stringsClaim(claims jwt.MapClaims, name string) []string {
+	switch v := claims[name].(type) {
+	case string:
+		return []string{v}
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// nameClaims returns the user's first and last name, splitting "name" when
+// the provider does not send given_name/family_name
+func nameClaims(claims jwt.MapClaims) (string, string) {
+	first, last := stringClaim(claims, "given_name"), stringClaim(claims, "family_name")
+	if first != "" || last != "" {
+		return first, last
+	}
+	name := stringClaim(claims, "name")
+	if name == "" {
+		name = stringClaim(claims, "preferred_username")
+	}
+	first, last, _ = strings.Cut(strings.TrimSpace(name), " ")
+	return first, strings.TrimSpace(last)
+}
@@ -0,0 +1,150 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package authz decides what users may do, from the roles they hold in
+// their households. Handlers ask it rather than comparing roles
+// themselves, so each rule lives in one place.
+package authz
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/middleware"
+)
+
+// Action is something a household role may or may not allow
+type Action string
+
+// Actions
+const (
+	ViewHousehold      Action = "view_household"       // see the household, its members and shared views
+	ViewMealPlans      Action = "view_meal_plans"      // see meal plans, including today's household plan
+	CheckShoppingItems Action = "check_shopping_items" // tick items off shopping lists
+	EditMealPlans      Action = "edit_meal_plans"
+	EditRecipes        Action = "edit_recipes"
+	EditSettings       Action = "edit_settings"  // household settings such as store layouts and equipment
+	ManageMembers      Action = "manage_members" // add and remove members, and invite new ones
+)
+
+// policy lists the actions each household role allows. Participants, such
+// as children, follow the household's plan and help with the shopping but
+// change nothing else.
+var policy = map[string][]Action{
+	database.HouseholdRoleOwner:       {ViewHousehold, ViewMealPlans, CheckShoppingItems, EditMealPlans, EditRecipes, EditSettings, ManageMembers},
+	database.HouseholdRoleAdmin:       {ViewHousehold, ViewMealPlans, CheckShoppingItems, EditMealPlans, EditRecipes, EditSettings, ManageMembers},
+	database.HouseholdRoleMember:      {ViewHousehold, ViewMealPlans, CheckShoppingItems, EditMealPlans, EditRecipes, EditSettings},
+	database.HouseholdRoleParticipant: {ViewHousehold, ViewMealPlans, CheckShoppingItems},
+}
+
+// rank orders roles from most to least capable
+var rank = []string{
+	database.HouseholdRoleOwner,
+	database.HouseholdRoleAdmin,
+	database.HouseholdRoleMember,
+	database.HouseholdRoleParticipant,
+}
+
+// Allows reports whether a household role allows an action. Unknown roles
+// allow nothing.
+func Allows(role string, action Action) bool {
+	for _, allowed := range policy[role] {
+		if allowed == action {
+			return true
+		}
+	}
+	return false
+}
+
+// errForbidden is returned when a role does not allow an action
+var errForbidden = apierror.New(http.StatusForbidden, apierror.CodeForbidden, "your household role does not allow this")
+
+// Household checks that a user belongs to a household and that their role
+// there allows an action, and returns their membership. Users outside the
+// household get a not-found error, so its existence is not revealed;
+// members whose role falls short get a forbidden one.
+func Household(ctx context.Context, db database.Database, householdID, userID string, action Action) (*database.HouseholdMember, error) {
+	member, err := db.GetHouseholdMember(ctx, householdID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !Allows(member.Role, action) {
+		return nil, errForbidden
+	}
+	return member, nil
+}
+
+// AccountRole is the most capable role a user holds in any household. It
+// governs what they may do with their own data: someone who only ever
+// joined households as a participant uses the app as one. Users in no
+// household are members.
+func AccountRole(ctx context.Context, db database.Database, userID string) (string, error) {
+	households, err := db.ListHouseholdsByUser(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if len(households) == 0 {
+		return database.HouseholdRoleMember, nil
+	}
+	best := len(rank)
+	for _, household := range households {
+		member, err := db.GetHouseholdMember(ctx, household.ID, userID)
+		if err != nil {
+			return "", err
+		}
+		for i, role := range rank {
+			if role == member.Role && i < best {
+				best = i
+			}
+		}
+	}
+	if best == len(rank) {
+		return database.HouseholdRoleMember, nil
+	}
+	return rank[best], nil
+}
+
+// Require rejects changes the user's account role does not allow, so
+// participants can read a group of routes but not write to it. Reads pass
+// through untouched. It must run after AuthMiddleware.
+func Require(db database.Database, action Action) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+		user, ok := middleware.GetUserFromContext(c)
+		if !ok {
+			apierror.Unauthorized(c, "unauthorized")
+			return
+		}
+		role, err := AccountRole(c.Request.Context(), db, user.ID)
+		if err != nil {
+			apierror.Respond(c, err)
+			return
+		}
+		if !Allows(role, action) {
+			apierror.Abort(c, errForbidden)
+			return
+		}
+		c.Next()
+	}
+}
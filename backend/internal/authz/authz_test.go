@@ -0,0 +1,290 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package authz_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/authz"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/database/dbtest"
+)
+
+// roles are the household roles a user can hold, plus "" for a user
+// outside the household
+var roles = []string{
+	database.HouseholdRoleOwner,
+	database.HouseholdRoleAdmin,
+	database.HouseholdRoleMember,
+	database.HouseholdRoleParticipant,
+	"",
+}
+
+// household is a household with one user in each role and one outside it
+type household struct {
+	*database.Household
+	users map[string]*database.User // by role; "" is the outsider
+}
+
+func newHousehold(t *testing.T, ctx context.Context, db database.Database) *household {
+	t.Helper()
+	h := &household{users: map[string]*database.User{}}
+	for _, role := range roles {
+		h.users[role] = dbtest.User(t, ctx, db)
+	}
+
+	now := time.Now().UTC()
+	h.Household = &database.Household{
+		ID:        uuid.New().String(),
+		Name:      "Test household",
+		OwnerID:   h.users[database.HouseholdRoleOwner].ID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := db.CreateHousehold(ctx, h.Household); err != nil {
+		t.Fatalf("CreateHousehold: %v", err)
+	}
+	for _, role := range []string{database.HouseholdRoleAdmin, database.HouseholdRoleMember, database.HouseholdRoleParticipant} {
+		member := &database.HouseholdMember{HouseholdID: h.ID, UserID: h.users[role].ID, Role: role, JoinedAt: now}
+		if err := db.AddHouseholdMember(ctx, member); err != nil {
+			t.Fatalf("AddHouseholdMember(%s): %v", role, err)
+		}
+	}
+	return h
+}
+
+// status is the HTTP status an authorization error maps to, or 200 for none
+func status(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	if apierror.IsNotFound(err) {
+		return http.StatusNotFound
+	}
+	var apiErr *apierror.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Status
+	}
+	return http.StatusInternalServerError
+}
+
+func name(role string) string {
+	if role == "" {
+		return "non-member"
+	}
+	return role
+}
+
+func TestAllows(t *testing.T) {
+	tests := []struct {
+		action authz.Action
+		want   map[string]bool // by role; roles not listed are denied
+	}{
+		{authz.ViewHousehold, map[string]bool{"owner": true, "admin": true, "member": true, "participant": true}},
+		{authz.ViewMealPlans, map[string]bool{"owner": true, "admin": true, "member": true, "participant": true}},
+		{authz.CheckShoppingItems, map[string]bool{"owner": true, "admin": true, "member": true, "participant": true}},
+		{authz.EditMealPlans, map[string]bool{"owner": true, "admin": true, "member": true}},
+		{authz.EditRecipes, map[string]bool{"owner": true, "admin": true, "member": true}},
+		{authz.EditSettings, map[string]bool{"owner": true, "admin": true, "member": true}},
+		{authz.ManageMembers, map[string]bool{"owner": true, "admin": true}},
+	}
+	for _, tt := range tests {
+		for _, role := range append(roles, "guest") {
+			if got := authz.Allows(role, tt.action); got != tt.want[role] {
+				t.Errorf("Allows(%s, %s) = %v, want %v", name(role), tt.action, got, tt.want[role])
+			}
+		}
+	}
+}
+
+func TestOwns(t *testing.T) {
+	tests := []struct {
+		name            string
+		userID, ownerID string
+		want            bool
+	}{
+		{"owner", "u1", "u1", true},
+		{"someone else", "u2", "u1", false},
+		{"signed out", "", "u1", false},
+		{"signed out, unowned", "", "", false},
+	}
+	for _, tt := range tests {
+		if got := authz.Owns(tt.userID, tt.ownerID); got != tt.want {
+			t.Errorf("%s: Owns(%q, %q) = %v, want %v", tt.name, tt.userID, tt.ownerID, got, tt.want)
+		}
+	}
+}
+
+func TestHousehold(t *testing.T) {
+	dbtest.Run(t, func(ctx context.Context, db database.Database) {
+		h := newHousehold(t, ctx, db)
+
+		tests := []struct {
+			action authz.Action
+			want   map[string]int // status by role
+		}{
+			{authz.ViewHousehold, map[string]int{"owner": 200, "admin": 200, "member": 200, "participant": 200, "": 404}},
+			{authz.EditMealPlans, map[string]int{"owner": 200, "admin": 200, "member": 200, "participant": 403, "": 404}},
+			{authz.EditSettings, map[string]int{"owner": 200, "admin": 200, "member": 200, "participant": 403, "": 404}},
+			{authz.ManageMembers, map[string]int{"owner": 200, "admin": 200, "member": 403, "participant": 403, "": 404}},
+		}
+		for _, tt := range tests {
+			for _, role := range roles {
+				member, err := authz.Household(ctx, db, h.ID, h.users[role].ID, tt.action)
+				if got := status(err); got != tt.want[role] {
+					t.Errorf("Household(%s, %s) = %d (%v), want %d", name(role), tt.action, got, err, tt.want[role])
+					continue
+				}
+				if err == nil && member.Role != role {
+					t.Errorf("Household(%s, %s) returned role %q", name(role), tt.action, member.Role)
+				}
+			}
+		}
+	})
+}
+
+func TestAccountRole(t *testing.T) {
+	dbtest.Run(t, func(ctx context.Context, db database.Database) {
+		h := newHousehold(t, ctx, db)
+
+		// A participant here who owns a household of their own acts as its owner
+		participant := h.users[database.HouseholdRoleParticipant]
+		now := time.Now().UTC()
+		own := &database.Household{ID: uuid.New().String(), Name: "Own", OwnerID: participant.ID, CreatedAt: now, UpdatedAt: now}
+		if err := db.CreateHousehold(ctx, own); err != nil {
+			t.Fatalf("CreateHousehold: %v", err)
+		}
+
+		want := map[string]string{
+			database.HouseholdRoleOwner:       database.HouseholdRoleOwner,
+			database.HouseholdRoleAdmin:       database.HouseholdRoleAdmin,
+			database.HouseholdRoleMember:      database.HouseholdRoleMember,
+			database.HouseholdRoleParticipant: database.HouseholdRoleOwner,
+			"":                                database.HouseholdRoleMember,
+		}
+		for _, role := range roles {
+			got, err := authz.AccountRole(ctx, db, h.users[role].ID)
+			if err != nil {
+				t.Fatalf("AccountRole(%s): %v", name(role), err)
+			}
+			if got != want[role] {
+				t.Errorf("AccountRole(%s) = %q, want %q", name(role), got, want[role])
+			}
+		}
+
+		// Someone who only ever joined as a participant stays one
+		only := dbtest.User(t, ctx, db)
+		if err := db.AddHouseholdMember(ctx, &database.HouseholdMember{HouseholdID: h.ID, UserID: only.ID, Role: database.HouseholdRoleParticipant, JoinedAt: now}); err != nil {
+			t.Fatalf("AddHouseholdMember: %v", err)
+		}
+		if got, err := authz.AccountRole(ctx, db, only.ID); err != nil || got != database.HouseholdRoleParticipant {
+			t.Errorf("AccountRole(participant only) = %q, %v, want %q", got, err, database.HouseholdRoleParticipant)
+		}
+	})
+}
+
+func TestPersonalRecords(t *testing.T) {
+	dbtest.Run(t, func(ctx context.Context, db database.Database) {
+		h := newHousehold(t, ctx, db)
+
+		// Each record belongs to the household's member; only they may
+		// change it, whatever role anyone else holds
+		author := h.users[database.HouseholdRoleMember]
+		recipe := &database.Recipe{UserID: author.ID}
+		plan := &database.MealPlan{UserID: author.ID}
+		item := &database.ShoppingListItem{UserID: author.ID}
+
+		for _, role := range roles {
+			userID := h.users[role].ID
+			isAuthor := role == database.HouseholdRoleMember
+			inHousehold := role != ""
+
+			if got := authz.CanViewRecipe(userID, recipe); !got {
+				t.Errorf("CanViewRecipe(%s) = false, want true", name(role))
+			}
+			if got := authz.CanEditRecipe(userID, recipe); got != isAuthor {
+				t.Errorf("CanEditRecipe(%s) = %v, want %v", name(role), got, isAuthor)
+			}
+			if got := authz.CanEditMealPlan(userID, plan); got != isAuthor {
+				t.Errorf("CanEditMealPlan(%s) = %v, want %v", name(role), got, isAuthor)
+			}
+			if got := authz.CanEditShoppingItem(userID, item); got != isAuthor {
+				t.Errorf("CanEditShoppingItem(%s) = %v, want %v", name(role), got, isAuthor)
+			}
+
+			got, err := authz.CanViewMealPlan(ctx, db, userID, plan)
+			if err != nil || got != inHousehold {
+				t.Errorf("CanViewMealPlan(%s) = %v, %v, want %v", name(role), got, err, inHousehold)
+			}
+			got, err = authz.CanViewShoppingItem(ctx, db, userID, item)
+			if err != nil || got != inHousehold {
+				t.Errorf("CanViewShoppingItem(%s) = %v, %v, want %v", name(role), got, err, inHousehold)
+			}
+			got, err = authz.SharesHousehold(ctx, db, userID, author.ID)
+			if err != nil || got != inHousehold {
+				t.Errorf("SharesHousehold(%s) = %v, %v, want %v", name(role), got, err, inHousehold)
+			}
+		}
+
+		if authz.CanViewRecipe("", recipe) {
+			t.Error("CanViewRecipe(signed out) = true, want false")
+		}
+	})
+}
+
+func TestHouseholdHelpers(t *testing.T) {
+	dbtest.Run(t, func(ctx context.Context, db database.Database) {
+		h := newHousehold(t, ctx, db)
+		onList := &database.ShoppingListItem{UserID: h.users[database.HouseholdRoleMember].ID}
+		offList := &database.ShoppingListItem{UserID: h.users[""].ID}
+
+		tests := []struct {
+			role                      string
+			check, checkOther, manage int
+			delete                    bool
+		}{
+			{database.HouseholdRoleOwner, 200, 404, 200, true},
+			{database.HouseholdRoleAdmin, 200, 404, 200, false},
+			{database.HouseholdRoleMember, 200, 404, 403, false},
+			{database.HouseholdRoleParticipant, 200, 404, 403, false},
+			{"", 404, 404, 404, false},
+		}
+		for _, tt := range tests {
+			userID := h.users[tt.role].ID
+			if got := status(authz.CanCheckShoppingItem(ctx, db, h.ID, userID, onList)); got != tt.check {
+				t.Errorf("CanCheckShoppingItem(%s) = %d, want %d", name(tt.role), got, tt.check)
+			}
+			if got := status(authz.CanCheckShoppingItem(ctx, db, h.ID, userID, offList)); got != tt.checkOther {
+				t.Errorf("CanCheckShoppingItem(%s, item off the list) = %d, want %d", name(tt.role), got, tt.checkOther)
+			}
+			if got := status(authz.CanManageHousehold(ctx, db, h.ID, userID)); got != tt.manage {
+				t.Errorf("CanManageHousehold(%s) = %d, want %d", name(tt.role), got, tt.manage)
+			}
+			if got := authz.CanDeleteHousehold(userID, h.Household); got != tt.delete {
+				t.Errorf("CanDeleteHousehold(%s) = %v, want %v", name(tt.role), got, tt.delete)
+			}
+		}
+	})
+}
@@ -0,0 +1,117 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package authz
+
+import (
+	"context"
+
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Owns reports whether a personal record, such as a pantry item, leftover
+// or webhook, belongs to the user. Only its owner may see or change it.
+func Owns(userID, ownerID string) bool {
+	return userID != "" && userID == ownerID
+}
+
+// SharesHousehold reports whether two users belong to a household
+// together. Everyone shares one with themselves.
+func SharesHousehold(ctx context.Context, db database.Database, userID, otherID string) (bool, error) {
+	if Owns(userID, otherID) {
+		return true, nil
+	}
+	households, err := db.ListHouseholdsByUser(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, household := range households {
+		_, err := db.GetHouseholdMember(ctx, household.ID, otherID)
+		if err == nil {
+			return true, nil
+		}
+		if !apierror.IsNotFound(err) {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// CanViewRecipe reports whether the user may read a recipe. Recipes are a
+// library everyone on the instance reads from, so any signed-in user may.
+func CanViewRecipe(userID string, recipe *database.Recipe) bool {
+	return userID != ""
+}
+
+// CanEditRecipe reports whether the user may change or delete a recipe,
+// its image, history and share links: only its author may
+func CanEditRecipe(userID string, recipe *database.Recipe) bool {
+	return Owns(userID, recipe.UserID)
+}
+
+// CanViewMealPlan reports whether the user may read a meal plan: its
+// owner and the people they share a household with may
+func CanViewMealPlan(ctx context.Context, db database.Database, userID string, plan *database.MealPlan) (bool, error) {
+	return SharesHousehold(ctx, db, userID, plan.UserID)
+}
+
+// CanEditMealPlan reports whether the user may change or delete a meal
+// plan: only its owner may
+func CanEditMealPlan(userID string, plan *database.MealPlan) bool {
+	return Owns(userID, plan.UserID)
+}
+
+// CanViewShoppingItem reports whether the user may read an item on a
+// shopping list: its owner and the people they share a household with,
+// who see it on the household list, may
+func CanViewShoppingItem(ctx context.Context, db database.Database, userID string, item *database.ShoppingListItem) (bool, error) {
+	return SharesHousehold(ctx, db, userID, item.UserID)
+}
+
+// CanEditShoppingItem reports whether the user may change or delete an
+// item on a shopping list: only its owner may. Household members check
+// items off through the household list instead.
+func CanEditShoppingItem(userID string, item *database.ShoppingListItem) bool {
+	return Owns(userID, item.UserID)
+}
+
+// CanCheckShoppingItem checks the user may tick an item off, or reopen it,
+// on the shopping list of a household: their role there must allow it,
+// and the item must be on a member's list. Items on other lists are not
+// found.
+func CanCheckShoppingItem(ctx context.Context, db database.Database, householdID, userID string, item *database.ShoppingListItem) error {
+	if _, err := Household(ctx, db, householdID, userID, CheckShoppingItems); err != nil {
+		return err
+	}
+	_, err := db.GetHouseholdMember(ctx, householdID, item.UserID)
+	return err
+}
+
+// CanManageHousehold checks the user may manage a household's members and
+// invitations, with the errors Household returns
+func CanManageHousehold(ctx context.Context, db database.Database, householdID, userID string) error {
+	_, err := Household(ctx, db, householdID, userID, ManageMembers)
+	return err
+}
+
+// CanDeleteHousehold reports whether the user may delete a household:
+// only its owner may
+func CanDeleteHousehold(userID string, household *database.Household) bool {
+	return Owns(userID, household.OwnerID)
+}
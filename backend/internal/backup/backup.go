@@ -0,0 +1,377 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package backup writes and restores full-instance archives: a zip holding
+// a manifest, one JSON Lines file per database table and the uploaded files.
+package backup
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/config"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// FormatVersion is bumped when the archive layout changes
+const FormatVersion = 1
+
+// Archive layout
+const (
+	ManifestName = "manifest.json"
+	tablesDir    = "tables/"
+	uploadsDir   = "uploads/"
+)
+
+var (
+	// ErrInvalidArchive means the file is not a backup this server can read
+	ErrInvalidArchive = errors.New("invalid backup archive")
+
+	// ErrIncompatible means the backup cannot be restored into this instance
+	ErrIncompatible = errors.New("backup cannot be restored here")
+)
+
+// Manifest describes a backup archive
+type Manifest struct {
+	Format        int            `json:"format"`
+	CreatedAt     time.Time      `json:"created_at"`
+	Database      string         `json:"database"`       // engine the backup was taken from
+	SchemaVersion int            `json:"schema_version"` // newest migration applied
+	Tables        map[string]int `json:"tables"`         // rows per table
+	Uploads       int            `json:"uploads"`        // uploaded files
+}
+
+// Write dumps the database and the uploaded files into a zip archive
+// written to w. The schema must be fully migrated so the archive matches a
+// known version.
+func Write(ctx context.Context, cfg *config.Config, db database.Database, w io.Writer) (*Manifest, error) {
+	if err := db.Migrations().Check(ctx); err != nil {
+		return nil, err
+	}
+	version, err := db.Migrations().Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{
+		Format:        FormatVersion,
+		CreatedAt:     time.Now().UTC(),
+		Database:      cfg.Database.Engine(),
+		SchemaVersion: version,
+		Tables:        map[string]int{},
+	}
+
+	zw := zip.NewWriter(w)
+	dump := &archiveDump{zw: zw, manifest: manifest}
+	if err := db.DumpTables(ctx, dump); err != nil {
+		return nil, fmt.Errorf("failed to dump tables: %w", err)
+	}
+	if dir := uploadDir(cfg); dir != "" {
+		if manifest.Uploads, err = writeUploads(zw, dir); err != nil {
+			return nil, fmt.Errorf("failed to archive uploads: %w", err)
+		}
+	}
+
+	// The manifest goes last so it can carry the counts
+	mw, err := zw.Create(ManifestName)
+	if err != nil {
+		return nil, err
+	}
+	enc := json.NewEncoder(mw)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(manifest); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// Restore replaces every table, and the uploaded files, with the contents
+// of an archive written by Write. The schema is moved to the archive's
+// version to load the rows and migrated to this build's latest afterwards,
+// so backups from older releases restore too. The rows load in one
+// transaction; the schema changes around it do not.
+func Restore(ctx context.Context, cfg *config.Config, db database.Database, r io.ReaderAt, size int64) (*Manifest, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	manifest, err := readManifest(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	migrator := db.Migrations()
+	switch {
+	case manifest.Database != cfg.Database.Engine():
+		return nil, fmt.Errorf("%w: it was taken from %s and this instance uses %s", ErrIncompatible, manifest.Database, cfg.Database.Engine())
+	case manifest.SchemaVersion > migrator.Latest():
+		return nil, fmt.Errorf("%w: its schema version %d is newer than this server's %d", ErrIncompatible, manifest.SchemaVersion, migrator.Latest())
+	}
+
+	log := logger.Ctx(ctx)
+	log.Info().Int("schema_version", manifest.SchemaVersion).Time("created_at", manifest.CreatedAt).Msg("Restoring backup")
+
+	if err := migrator.To(ctx, manifest.SchemaVersion); err != nil {
+		return nil, fmt.Errorf("failed to match the backup's schema version: %w", err)
+	}
+	if err := db.LoadTables(ctx, &archiveSource{zr: zr, manifest: manifest}); err != nil {
+		return nil, fmt.Errorf("failed to load tables: %w", err)
+	}
+	if err := migrator.Up(ctx); err != nil {
+		return nil, fmt.Errorf("failed to migrate restored data: %w", err)
+	}
+
+	if dir := uploadDir(cfg); dir != "" {
+		if err := restoreUploads(zr, dir); err != nil {
+			return nil, fmt.Errorf("failed to restore uploads: %w", err)
+		}
+	}
+
+	log.Info().Int("tables", len(manifest.Tables)).Int("uploads", manifest.Uploads).Msg("Backup restored")
+	return manifest, nil
+}
+
+// uploadDir returns the directory of uploaded files, or "" when they are
+// not stored locally
+func uploadDir(cfg *config.Config) string {
+	if cfg.Storage.Type != "local" {
+		return ""
+	}
+	return cfg.Storage.LocalPath
+}
+
+func tablePath(table string) string {
+	return tablesDir + table + ".jsonl"
+}
+
+func readManifest(zr *zip.Reader) (*Manifest, error) {
+	f, err := zr.Open(ManifestName)
+	if err != nil {
+		return nil, fmt.Errorf("%w: no %s", ErrInvalidArchive, ManifestName)
+	}
+	defer f.Close()
+
+	var manifest Manifest
+	if err := json.NewDecoder(f).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidArchive, ManifestName, err)
+	}
+	if manifest.Format < 1 || manifest.Format > FormatVersion {
+		return nil, fmt.Errorf("%w: unsupported format %d", ErrInvalidArchive, manifest.Format)
+	}
+	for table := range manifest.Tables {
+		if _, err := fs.Stat(zr, tablePath(table)); err != nil {
+			return nil, fmt.Errorf("%w: %s is missing", ErrInvalidArchive, tablePath(table))
+		}
+	}
+	// Reject entries that would escape the upload directory before anything
+	// is replaced
+	for _, f := range zr.File {
+		if rel, ok := strings.CutPrefix(f.Name, uploadsDir); ok && !f.FileInfo().IsDir() {
+			if !filepath.IsLocal(filepath.FromSlash(rel)) || path.Clean(rel) != rel {
+				return nil, fmt.Errorf("%w: unsafe upload path %s", ErrInvalidArchive, f.Name)
+			}
+		}
+	}
+	return &manifest, nil
+}
+
+// archiveDump writes each table to its own archive entry
+type archiveDump struct {
+	zw       *zip.Writer
+	manifest *Manifest
+	table    string
+	entry    io.Writer
+}
+
+func (d *archiveDump) BeginTable(name string) error {
+	entry, err := d.zw.Create(tablePath(name))
+	if err != nil {
+		return err
+	}
+	d.table, d.entry = name, entry
+	d.manifest.Tables[name] = 0
+	return nil
+}
+
+func (d *archiveDump) Row(row json.RawMessage) error {
+	if _, err := d.entry.Write(row); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(d.entry, "\n"); err != nil {
+		return err
+	}
+	d.manifest.Tables[d.table]++
+	return nil
+}
+
+// archiveSource reads table rows back, checking them against the manifest
+type archiveSource struct {
+	zr       *zip.Reader
+	manifest *Manifest
+}
+
+func (s *archiveSource) Tables() []string {
+	tables := make([]string, 0, len(s.manifest.Tables))
+	for table := range s.manifest.Tables {
+		tables = append(tables, table)
+	}
+	return tables
+}
+
+func (s *archiveSource) Rows(table string, fn func(row json.RawMessage) error) error {
+	want, ok := s.manifest.Tables[table]
+	if !ok {
+		return nil
+	}
+	f, err := s.zr.Open(tablePath(table))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	count := 0
+	br := bufio.NewReader(f)
+	for {
+		line, err := br.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			count++
+			if err := fn(json.RawMessage(line)); err != nil {
+				return err
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if count != want {
+		return fmt.Errorf("%w: %s has %d rows, the manifest lists %d", ErrInvalidArchive, tablePath(table), count, want)
+	}
+	return nil
+}
+
+// writeUploads adds the regular files under dir to the archive
+func writeUploads(zw *zip.Writer, dir string) (int, error) {
+	count := 0
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && p == dir {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+
+		src, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		dst, err := zw.Create(uploadsDir + filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(dst, src); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
+
+// restoreUploads extracts the archived uploads next to dir and then swaps
+// them in, so a failed extraction leaves the current files alone
+func restoreUploads(zr *zip.Reader, dir string) error {
+	staging := dir + ".restoring"
+	if err := os.RemoveAll(staging); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(staging, 0o750); err != nil {
+		return err
+	}
+
+	for _, f := range zr.File {
+		// Paths were checked by readManifest
+		rel, ok := strings.CutPrefix(f.Name, uploadsDir)
+		if !ok || f.FileInfo().IsDir() {
+			continue
+		}
+		if err := extract(f, filepath.Join(staging, filepath.FromSlash(rel))); err != nil {
+			os.RemoveAll(staging)
+			return err
+		}
+	}
+
+	previous := dir + ".previous"
+	if err := os.RemoveAll(previous); err != nil {
+		return err
+	}
+	if err := os.Rename(dir, previous); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := os.Rename(staging, dir); err != nil {
+		return err
+	}
+	return os.RemoveAll(previous)
+}
+
+func extract(f *zip.File, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o750); err != nil {
+		return err
+	}
+	src, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o640)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
@@ -20,6 +20,8 @@ package config
 
 import (
 	"fmt"
+	"reflect"
+	"slices"
 	"strings"
 
 	"github.com/spf13/viper"
@@ -27,12 +29,26 @@ import (
 
 // Config represents the application configuration
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Auth     AuthConfig
-	AI       AIConfig
-	Storage  StorageConfig
-	Logging  LoggingConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	Auth      AuthConfig
+	RateLimit RateLimitConfig
+	AI        AIConfig
+	TTS       TTSConfig
+	Cooking   CookingConfig
+	Storage   StorageConfig
+	Fetch     FetchConfig
+	Foods     FoodsConfig
+	Webhooks  WebhooksConfig
+	MQTT      MQTTConfig
+	Mail      MailConfig
+	Calendar  CalendarConfig
+	Health    HealthConfig
+	Telemetry TelemetryConfig
+	Jobs      JobsConfig
+	Sync      SyncConfig
+	Audit     AuditConfig
+	Logging   LoggingConfig
 }
 
 // ServerConfig contains server-related configuration
@@ -41,11 +57,16 @@ type ServerConfig struct {
 	Port         int
 	Environment  string
 	TrustedProxy []string
+	PublicURL    string // externally reachable base URL, used in generated links
+
+	ShutdownDelay     int // seconds readiness fails before the server stops accepting connections
+	ShutdownTimeout   int // seconds to drain requests and background work on shutdown
+	IdempotencyWindow int // hours the response to a request with an Idempotency-Key is replayed
 }
 
 // DatabaseConfig contains database configuration
 type DatabaseConfig struct {
-	Type         string // postgres, sqlite, supabase
+	Type         string // postgres, sqlite, memory, supabase
 	Host         string
 	Port         int
 	Name         string
@@ -55,28 +76,94 @@ type DatabaseConfig struct {
 	MaxConns     int
 	MinConns     int
 	SQLitePath   string
+	AutoMigrate  bool // apply pending migrations on start
 	CustomConfig map[string]string
 }
 
+// Engine is the database engine behind Type. The in-memory database is
+// SQLite, so backups move between the two.
+func (d DatabaseConfig) Engine() string {
+	if d.Type == "memory" {
+		return "sqlite"
+	}
+	return d.Type
+}
+
 // AuthConfig contains authentication configuration
 type AuthConfig struct {
-	Type           string // argon2, oauth, supabase
-	JWTSecret      string
-	JWTExpiry      int // minutes
-	RefreshExpiry  int // days
-	Argon2Memory   uint32
-	Argon2Time     uint32
-	Argon2Threads  uint8
-	CustomConfig   map[string]string
+	Type                 string // argon2, oauth, supabase
+	JWTSecret            string
+	JWTExpiry            int // minutes
+	RefreshExpiry        int // days, extended on every refresh
+	SessionMaxAge        int // days, absolute limit regardless of activity
+	Argon2Memory         uint32
+	Argon2Time           uint32
+	Argon2Threads        uint8
+	CustomConfig         map[string]string
+	OIDC                 OIDCConfig
+	DisablePasswordLogin bool // only honoured while OIDC is enabled
+	RequireTwoFactor     bool // every account must enroll TOTP before using the API
+	TOTPIssuer           string
+	Registration         string // open, invite_only, closed; admins can override at runtime
+	DeletionGraceDays    int    // days a deleted account can still be restored by cancelling
+	PasswordResetExpiry  int    // minutes a password reset link works
+	PasswordResetURL     string // page reset emails link to, with ?token= appended
+	HandoffExpiry        int    // seconds a device handoff QR code works
+}
+
+// OIDCConfig for OpenID Connect single sign-on (Authelia, Authentik, Keycloak, ...)
+type OIDCConfig struct {
+	Enabled         bool
+	Name            string // shown on the login button
+	IssuerURL       string
+	ClientID        string
+	ClientSecret    string
+	RedirectURL     string // defaults to server.publicurl + /api/v1/auth/oidc/callback
+	Scopes          []string
+	GroupsClaim     string
+	AutoProvision   bool              // create accounts on first login
+	HouseholdGroups map[string]string // IdP group (lowercase) -> household ID
+	PostLoginURL    string            // browser logins are sent here with tokens in the URL fragment
+}
+
+// RateLimitConfig contains request rate limits; 0 disables a limit
+type RateLimitConfig struct {
+	Enabled           bool
+	UserPerMinute     int // requests per authenticated user
+	InstancePerMinute int // requests across all users
+	AuthPerMinute     int // login, registration and 2FA attempts per client IP
+	ImportPerHour     int // recipe imports from URLs per user
+	ResetPerHour      int // password reset emails per address
 }
 
 // AIConfig contains AI provider configuration
 type AIConfig struct {
-	DefaultProvider string // ollama, openai, gemini, claude
-	Ollama          OllamaConfig
-	OpenAI          OpenAIConfig
-	Gemini          GeminiConfig
-	Claude          ClaudeConfig
+	DefaultProvider     string   // ollama, openai, gemini, claude
+	Fallback            []string // providers to try in turn when the default fails
+	Ollama              OllamaConfig
+	OpenAI              OpenAIConfig
+	Gemini              GeminiConfig
+	Claude              ClaudeConfig
+	Budget              AIBudgetConfig
+	CacheTTL            int    // hours AI outputs are reused; 0 disables the cache
+	Timeout             int    // seconds to wait for a provider to answer
+	Deadline            int    // seconds one request may take across all the providers it tries
+	Cooldown            int    // seconds a provider that failed is tried only after the others
+	PromptsDir          string // directory of .tmpl files overriding the built-in prompts
+	InvocationRetention int    // days of AI call records to keep; 0 keeps them forever
+}
+
+// AIBudgetConfig caps monthly AI spend; 0 disables a cap
+type AIBudgetConfig struct {
+	UserMonthlyTokens   int64   // input plus output tokens per user
+	UserMonthlyCost     float64 // USD per user
+	InstanceMonthlyCost float64 // USD across all users
+}
+
+// AIPricing is the provider price in USD per million tokens, used to track cost
+type AIPricing struct {
+	InputPerMTok  float64
+	OutputPerMTok float64
 }
 
 // OllamaConfig for Ollama AI provider
@@ -90,7 +177,9 @@ type OllamaConfig struct {
 type OpenAIConfig struct {
 	Enabled bool
 	APIKey  string
+	BaseURL string // for servers that speak the OpenAI API
 	Model   string
+	Pricing AIPricing
 }
 
 // GeminiConfig for Google Gemini provider
@@ -98,6 +187,7 @@ type GeminiConfig struct {
 	Enabled bool
 	APIKey  string
 	Model   string
+	Pricing AIPricing
 }
 
 // ClaudeConfig for Anthropic Claude provider
@@ -105,16 +195,247 @@ type ClaudeConfig struct {
 	Enabled bool
 	APIKey  string
 	Model   string
+	Pricing AIPricing
+}
+
+// EnabledProviders returns the AI providers that are enabled and have the
+// settings they need to be usable (a host for Ollama, an API key otherwise)
+func (c AIConfig) EnabledProviders() []string {
+	providers := []string{}
+	if c.Ollama.Enabled && c.Ollama.Host != "" {
+		providers = append(providers, "ollama")
+	}
+	if c.OpenAI.Enabled && c.OpenAI.APIKey != "" {
+		providers = append(providers, "openai")
+	}
+	if c.Gemini.Enabled && c.Gemini.APIKey != "" {
+		providers = append(providers, "gemini")
+	}
+	if c.Claude.Enabled && c.Claude.APIKey != "" {
+		providers = append(providers, "claude")
+	}
+	return providers
+}
+
+// ActiveProvider returns the default provider when it is usable, otherwise
+// the first usable provider, or "" when none is
+func (c AIConfig) ActiveProvider() string {
+	providers := c.EnabledProviders()
+	for _, p := range providers {
+		if p == c.DefaultProvider {
+			return p
+		}
+	}
+	if len(providers) == 0 {
+		return ""
+	}
+	return providers[0]
+}
+
+// ProviderChain returns the providers to try, in order: the active one,
+// then the usable ones of ai.fallback
+func (c AIConfig) ProviderChain() []string {
+	active := c.ActiveProvider()
+	if active == "" {
+		return nil
+	}
+	chain := []string{active}
+	enabled := c.EnabledProviders()
+	for _, p := range c.Fallback {
+		if slices.Contains(enabled, p) && !slices.Contains(chain, p) {
+			chain = append(chain, p)
+		}
+	}
+	return chain
+}
+
+// Pricing returns the configured pricing for a provider; self-hosted Ollama is free
+func (c AIConfig) Pricing(provider string) AIPricing {
+	switch provider {
+	case "openai":
+		return c.OpenAI.Pricing
+	case "gemini":
+		return c.Gemini.Pricing
+	case "claude":
+		return c.Claude.Pricing
+	}
+	return AIPricing{}
+}
+
+// Enabled reports whether at least one AI provider is usable
+func (c AIConfig) Enabled() bool {
+	return len(c.EnabledProviders()) > 0
+}
+
+// TTSConfig for reading cooking steps aloud. Without a provider, steps
+// are still available as SSML for clients with their own speech engine.
+type TTSConfig struct {
+	Provider string // piper, openai; empty disables audio
+	Timeout  int    // seconds to wait for speech to be synthesized
+	Piper    PiperConfig
+	OpenAI   OpenAITTSConfig
+}
+
+// PiperConfig for a self-hosted Piper HTTP server
+type PiperConfig struct {
+	URL   string // e.g. http://piper:5000
+	Voice string // the server's default voice when empty
+}
+
+// OpenAITTSConfig for OpenAI's speech API
+type OpenAITTSConfig struct {
+	APIKey  string // defaults to ai.openai.apikey
+	BaseURL string
+	Model   string
+	Voice   string
+}
+
+// Enabled reports whether a speech provider is configured with the
+// settings it needs
+func (c TTSConfig) Enabled() bool {
+	switch c.Provider {
+	case "piper":
+		return c.Piper.URL != ""
+	case "openai":
+		return c.OpenAI.APIKey != ""
+	}
+	return false
+}
+
+// CookingConfig contains cooking assistant configuration
+type CookingConfig struct {
+	AutoPause int  // minutes without progress before an active session is paused; 0 disables
+	Nudge     bool // publish an event inviting the cook back when a session is auto-paused
 }
 
 // StorageConfig contains file storage configuration
 type StorageConfig struct {
-	Type      string // local, s3
-	LocalPath string
-	S3Bucket  string
-	S3Region  string
-	S3Key     string
-	S3Secret  string
+	Type          string // local, s3
+	LocalPath     string
+	S3Bucket      string
+	S3Region      string
+	S3Key         string
+	S3Secret      string
+	S3Endpoint    string // MinIO or another S3-compatible service; empty for AWS
+	S3PathStyle   bool   // address the bucket in the path rather than the host name
+	URLExpiry     int    // minutes signed image URLs stay valid
+	MaxUploadSize int    // megabytes
+	ImportImages  bool   // copy images of imported recipes instead of linking to the source
+}
+
+// FetchConfig limits what the server fetches from addresses users give it,
+// such as recipe pages to import
+type FetchConfig struct {
+	AllowDomains         []string // only these domains and their subdomains; empty allows any
+	DenyDomains          []string // never these domains or their subdomains
+	AllowPrivateNetworks bool     // let fetches reach loopback, LAN and link-local addresses
+	MaxPageSize          int      // megabytes
+	Timeout              int      // seconds
+}
+
+// FoodsConfig contains packaged food lookup configuration
+type FoodsConfig struct {
+	OpenFoodFacts OpenFoodFactsConfig
+}
+
+// OpenFoodFactsConfig for barcode lookups against Open Food Facts
+type OpenFoodFactsConfig struct {
+	Enabled   bool
+	BaseURL   string
+	UserAgent string // identifies this instance, as Open Food Facts asks
+	CacheDays int    // how long looked-up products are reused
+}
+
+// WebhooksConfig contains outgoing webhook configuration
+type WebhooksConfig struct {
+	Enabled              bool
+	Timeout              int  // seconds to wait for a receiver to respond
+	MaxAttempts          int  // attempts before a delivery is given up
+	Retention            int  // days of delivery history to keep
+	AllowPrivateNetworks bool // let deliveries reach localhost and LAN addresses
+}
+
+// MQTTConfig for publishing events to an MQTT broker
+type MQTTConfig struct {
+	Enabled     bool
+	Broker      string // tcp://host:1883, or ssl://host:8883 for TLS
+	ClientID    string
+	Username    string
+	Password    string
+	TopicPrefix string
+	KeepAlive   int // seconds
+}
+
+// MailConfig for sending email, such as household invitations, password
+// resets and weekly digests
+type MailConfig struct {
+	Enabled  bool
+	Mode     string // smtp, or log to write messages to the log instead of sending them
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string // sender, e.g. "Space Food <food@example.com>"
+	TLS      string // starttls, tls or none
+	Timeout  int    // seconds to wait for the mail server
+}
+
+// CalendarConfig contains calendar integration configuration
+type CalendarConfig struct {
+	Import CalendarImportConfig
+}
+
+// CalendarImportConfig for syncing external calendars that block meal slots
+type CalendarImportConfig struct {
+	Enabled      bool
+	SyncInterval int // minutes between fetches of each calendar
+	Timeout      int // seconds to wait for a calendar server
+}
+
+// HealthConfig contains readiness check configuration
+type HealthConfig struct {
+	Timeout int  // seconds each readiness check may take
+	CheckAI bool // also require the active AI provider to be reachable
+}
+
+// TelemetryConfig contains metrics and tracing configuration
+type TelemetryConfig struct {
+	Metrics MetricsConfig
+	Tracing TracingConfig
+}
+
+// MetricsConfig for the Prometheus scrape endpoint
+type MetricsConfig struct {
+	Enabled bool
+	Path    string
+	Token   string // bearer token scrapers must send; empty leaves the endpoint open
+}
+
+// TracingConfig for exporting OpenTelemetry traces over OTLP/HTTP
+type TracingConfig struct {
+	Enabled     bool
+	Endpoint    string // collector base URL, e.g. http://localhost:4318
+	ServiceName string
+	SampleRatio float64           // share of new traces recorded, 0-1
+	Headers     map[string]string // sent with every export, e.g. for collector auth
+}
+
+// JobsConfig contains background job scheduler configuration
+type JobsConfig struct {
+	Enabled      bool
+	PollInterval int // seconds between checks for due jobs
+	RunRetention int // days of job run history to keep
+}
+
+// SyncConfig contains offline sync configuration
+type SyncConfig struct {
+	TombstoneDays int // days deleted records are reported to clients that haven't synced
+}
+
+// AuditConfig contains audit log configuration
+type AuditConfig struct {
+	Enabled   bool
+	Retention int // days of audit events to keep; 0 keeps them forever
 }
 
 // LoggingConfig contains logging configuration
@@ -132,7 +453,7 @@ func Load() (*Config, error) {
 	viper.AddConfigPath("/etc/space-food")
 
 	// Set defaults
-	setDefaults()
+	setDefaults(viper.GetViper())
 
 	// Read config file (optional)
 	if err := viper.ReadInConfig(); err != nil {
@@ -145,57 +466,185 @@ func Load() (*Config, error) {
 	viper.SetEnvPrefix("SPACE_FOOD")
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	viper.AutomaticEnv()
+	// Unmarshal only sees variables for keys viper knows of, and settings
+	// without a default, such as API keys, would otherwise be missed
+	for _, setting := range settings() {
+		if setting.kind != reflect.Map {
+			viper.BindEnv(setting.key)
+		}
+	}
 
+	return decode()
+}
+
+// decode builds the configuration from what viper has read and validates
+// it
+func decode() (*Config, error) {
 	var cfg Config
 	if err := viper.Unmarshal(&cfg); err != nil {
 		return nil, fmt.Errorf("error unmarshaling config: %w", err)
 	}
 
+	if cfg.Auth.OIDC.RedirectURL == "" && cfg.Server.PublicURL != "" {
+		cfg.Auth.OIDC.RedirectURL = strings.TrimRight(cfg.Server.PublicURL, "/") + "/api/v1/auth/oidc/callback"
+	}
+
+	if cfg.TTS.OpenAI.APIKey == "" {
+		cfg.TTS.OpenAI.APIKey = cfg.AI.OpenAI.APIKey
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
 	return &cfg, nil
 }
 
-func setDefaults() {
+// setDefaults gives every setting that has one its default value
+func setDefaults(v *viper.Viper) {
 	// Server defaults
-	viper.SetDefault("server.host", "0.0.0.0")
-	viper.SetDefault("server.port", 8080)
-	viper.SetDefault("server.environment", "development")
+	v.SetDefault("server.host", "0.0.0.0")
+	v.SetDefault("server.port", 8080)
+	v.SetDefault("server.environment", "development")
+	v.SetDefault("server.shutdowndelay", 5)
+	v.SetDefault("server.shutdowntimeout", 30)
+	v.SetDefault("server.idempotencywindow", 24)
 
 	// Database defaults
-	viper.SetDefault("database.type", "postgres")
-	viper.SetDefault("database.host", "localhost")
-	viper.SetDefault("database.port", 5432)
-	viper.SetDefault("database.name", "space_food")
-	viper.SetDefault("database.user", "postgres")
-	viper.SetDefault("database.sslmode", "disable")
-	viper.SetDefault("database.maxconns", 25)
-	viper.SetDefault("database.minconns", 5)
-	viper.SetDefault("database.sqlitepath", "./data/space_food.db")
+	v.SetDefault("database.type", "postgres")
+	v.SetDefault("database.host", "localhost")
+	v.SetDefault("database.port", 5432)
+	v.SetDefault("database.name", "space_food")
+	v.SetDefault("database.user", "postgres")
+	v.SetDefault("database.sslmode", "disable")
+	v.SetDefault("database.maxconns", 25)
+	v.SetDefault("database.minconns", 5)
+	v.SetDefault("database.sqlitepath", "./data/space_food.db")
+	v.SetDefault("database.automigrate", true)
 
 	// Auth defaults
-	viper.SetDefault("auth.type", "argon2")
-	viper.SetDefault("auth.jwtexpiry", 15)
-	viper.SetDefault("auth.refreshexpiry", 7)
-	viper.SetDefault("auth.argon2memory", 65536)
-	viper.SetDefault("auth.argon2time", 3)
-	viper.SetDefault("auth.argon2threads", 4)
+	v.SetDefault("auth.type", "argon2")
+	v.SetDefault("auth.jwtexpiry", 15)
+	v.SetDefault("auth.refreshexpiry", 7)
+	v.SetDefault("auth.sessionmaxage", 90)
+	v.SetDefault("auth.totpissuer", "Space Food")
+	v.SetDefault("auth.registration", "open")
+	v.SetDefault("auth.deletiongracedays", 30)
+	v.SetDefault("auth.passwordresetexpiry", 60)
+	v.SetDefault("auth.handoffexpiry", 120)
+	v.SetDefault("auth.oidc.name", "Single sign-on")
+	v.SetDefault("auth.oidc.scopes", []string{"openid", "profile", "email"})
+	v.SetDefault("auth.oidc.groupsclaim", "groups")
+	v.SetDefault("auth.oidc.autoprovision", true)
+	v.SetDefault("auth.argon2memory", 65536)
+	v.SetDefault("auth.argon2time", 3)
+	v.SetDefault("auth.argon2threads", 4)
+
+	// Rate limit defaults
+	v.SetDefault("ratelimit.enabled", true)
+	v.SetDefault("ratelimit.userperminute", 120)
+	v.SetDefault("ratelimit.instanceperminute", 0)
+	v.SetDefault("ratelimit.authperminute", 10)
+	v.SetDefault("ratelimit.importperhour", 30)
+	v.SetDefault("ratelimit.resetperhour", 3)
 
 	// AI defaults
-	viper.SetDefault("ai.defaultprovider", "ollama")
-	viper.SetDefault("ai.ollama.enabled", true)
-	viper.SetDefault("ai.ollama.host", "http://localhost:11434")
-	viper.SetDefault("ai.ollama.model", "llama2")
-	viper.SetDefault("ai.openai.enabled", false)
-	viper.SetDefault("ai.openai.model", "gpt-3.5-turbo")
-	viper.SetDefault("ai.gemini.enabled", false)
-	viper.SetDefault("ai.gemini.model", "gemini-pro")
-	viper.SetDefault("ai.claude.enabled", false)
-	viper.SetDefault("ai.claude.model", "claude-3-sonnet-20240229")
+	v.SetDefault("ai.defaultprovider", "ollama")
+	v.SetDefault("ai.ollama.enabled", true)
+	v.SetDefault("ai.ollama.host", "http://localhost:11434")
+	v.SetDefault("ai.ollama.model", "llama2")
+	v.SetDefault("ai.openai.enabled", false)
+	v.SetDefault("ai.openai.baseurl", "https://api.openai.com/v1")
+	v.SetDefault("ai.openai.model", "gpt-3.5-turbo")
+	v.SetDefault("ai.gemini.enabled", false)
+	v.SetDefault("ai.gemini.model", "gemini-pro")
+	v.SetDefault("ai.claude.enabled", false)
+	v.SetDefault("ai.claude.model", "claude-3-sonnet-20240229")
+	v.SetDefault("ai.cachettl", 720)
+	v.SetDefault("ai.timeout", 60)
+	v.SetDefault("ai.deadline", 90)
+	v.SetDefault("ai.cooldown", 60)
+	v.SetDefault("ai.invocationretention", 90)
+
+	// Text-to-speech defaults
+	v.SetDefault("tts.timeout", 30)
+	v.SetDefault("tts.openai.baseurl", "https://api.openai.com/v1")
+	v.SetDefault("tts.openai.model", "tts-1")
+	v.SetDefault("tts.openai.voice", "alloy")
+
+	// Cooking assistant defaults
+	v.SetDefault("cooking.autopause", 30)
+	v.SetDefault("cooking.nudge", true)
 
 	// Storage defaults
-	viper.SetDefault("storage.type", "local")
-	viper.SetDefault("storage.localpath", "./uploads")
+	v.SetDefault("storage.type", "local")
+	v.SetDefault("storage.localpath", "./uploads")
+	v.SetDefault("storage.urlexpiry", 60)
+	v.SetDefault("storage.maxuploadsize", 10)
+	v.SetDefault("storage.importimages", true)
+
+	// Fetch policy defaults
+	v.SetDefault("fetch.allowprivatenetworks", false)
+	v.SetDefault("fetch.maxpagesize", 5)
+	v.SetDefault("fetch.timeout", 15)
+
+	// Food lookup defaults
+	v.SetDefault("foods.openfoodfacts.enabled", true)
+	v.SetDefault("foods.openfoodfacts.baseurl", "https://world.openfoodfacts.org")
+	v.SetDefault("foods.openfoodfacts.useragent", "SpaceFood/1.0 (self-hosted)")
+	v.SetDefault("foods.openfoodfacts.cachedays", 30)
+
+	// Webhook defaults
+	v.SetDefault("webhooks.enabled", true)
+	v.SetDefault("webhooks.timeout", 10)
+	v.SetDefault("webhooks.maxattempts", 6)
+	v.SetDefault("webhooks.retention", 30)
+	v.SetDefault("webhooks.allowprivatenetworks", false)
+
+	// MQTT defaults
+	v.SetDefault("mqtt.enabled", false)
+	v.SetDefault("mqtt.broker", "tcp://localhost:1883")
+	v.SetDefault("mqtt.clientid", "space-food")
+	v.SetDefault("mqtt.topicprefix", "spacefood")
+	v.SetDefault("mqtt.keepalive", 60)
+
+	// Mail defaults
+	v.SetDefault("mail.enabled", false)
+	v.SetDefault("mail.mode", "smtp")
+	v.SetDefault("mail.port", 587)
+	v.SetDefault("mail.from", "Space Food <noreply@localhost>")
+	v.SetDefault("mail.tls", "starttls")
+	v.SetDefault("mail.timeout", 15)
+
+	// Calendar defaults
+	v.SetDefault("calendar.import.enabled", true)
+	v.SetDefault("calendar.import.syncinterval", 60)
+	v.SetDefault("calendar.import.timeout", 20)
+
+	// Health check defaults
+	v.SetDefault("health.timeout", 2)
+	v.SetDefault("health.checkai", false)
+
+	// Telemetry defaults
+	v.SetDefault("telemetry.metrics.enabled", false)
+	v.SetDefault("telemetry.metrics.path", "/metrics")
+	v.SetDefault("telemetry.tracing.enabled", false)
+	v.SetDefault("telemetry.tracing.endpoint", "http://localhost:4318")
+	v.SetDefault("telemetry.tracing.servicename", "space-food")
+	v.SetDefault("telemetry.tracing.sampleratio", 1.0)
+
+	// Job scheduler defaults
+	v.SetDefault("jobs.enabled", true)
+	v.SetDefault("jobs.pollinterval", 30)
+	v.SetDefault("jobs.runretention", 30)
+
+	// Offline sync defaults
+	v.SetDefault("sync.tombstonedays", 90)
+
+	// Audit log defaults
+	v.SetDefault("audit.enabled", true)
+	v.SetDefault("audit.retention", 365)
 
 	// Logging defaults
-	viper.SetDefault("logging.level", "info")
-	viper.SetDefault("logging.format", "json")
+	v.SetDefault("logging.level", "info")
+	v.SetDefault("logging.format", "json")
 }
@@ -0,0 +1,343 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// setting is one key of the config file
+type setting struct {
+	key  string // e.g. ai.openai.apikey
+	kind reflect.Kind
+}
+
+// settings lists every key of the config file, in the order of Config
+func settings() []setting {
+	return appendSettings(nil, reflect.TypeOf(Config{}), "")
+}
+
+func appendSettings(list []setting, t reflect.Type, prefix string) []setting {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := prefix + strings.ToLower(field.Name)
+		if field.Type.Kind() == reflect.Struct {
+			list = appendSettings(list, field.Type, key+".")
+			continue
+		}
+		list = append(list, setting{key: key, kind: field.Type.Kind()})
+	}
+	return list
+}
+
+// EnvName returns the environment variable that overrides a setting
+func EnvName(key string) string {
+	return "SPACE_FOOD_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// descriptions explain the settings and sections the example config file
+// shows. Settings without one are left out of it.
+var descriptions = map[string]string{
+	"server":                   "HTTP server",
+	"server.host":              "address to listen on",
+	"server.port":              "port to listen on",
+	"server.environment":       "development, staging or production",
+	"server.publicurl":         "externally reachable base URL, used in invite links and other generated URLs",
+	"server.shutdowndelay":     "seconds /readyz fails before the server stops accepting connections, so load balancers can move traffic away",
+	"server.shutdowntimeout":   "seconds to finish in-flight requests and background work when stopping",
+	"server.idempotencywindow": "hours a retried request with the same Idempotency-Key gets the first response back",
+
+	"database":             "Database",
+	"database.type":        "postgres, sqlite, or memory for a demo that keeps nothing after it stops",
+	"database.host":        "PostgreSQL host",
+	"database.port":        "PostgreSQL port",
+	"database.name":        "PostgreSQL database name",
+	"database.user":        "PostgreSQL user",
+	"database.password":    "PostgreSQL password",
+	"database.sslmode":     "PostgreSQL sslmode: disable, require, verify-ca or verify-full",
+	"database.maxconns":    "most PostgreSQL connections to keep open",
+	"database.minconns":    "fewest PostgreSQL connections to keep open",
+	"database.sqlitepath":  "SQLite database file, created with its directory on first start",
+	"database.automigrate": "apply pending migrations on start; otherwise run `spacefood migrate up`",
+
+	"auth":                      "Sign-in and sessions",
+	"auth.type":                 "password hashing: argon2",
+	"auth.jwtsecret":            "secret signing access tokens; a long random string",
+	"auth.jwtexpiry":            "minutes an access token is valid",
+	"auth.refreshexpiry":        "days of inactivity before a session expires",
+	"auth.sessionmaxage":        "days before a session must sign in again",
+	"auth.argon2memory":         "KiB of memory per password hash",
+	"auth.argon2time":           "passes per password hash",
+	"auth.argon2threads":        "threads per password hash",
+	"auth.oidc":                 "Single sign-on through Authelia, Authentik, Keycloak or another OpenID Connect provider",
+	"auth.oidc.enabled":         "offer single sign-on",
+	"auth.oidc.name":            "shown on the login button",
+	"auth.oidc.issuerurl":       "the provider's issuer URL",
+	"auth.oidc.clientid":        "client ID registered with the provider",
+	"auth.oidc.clientsecret":    "client secret registered with the provider",
+	"auth.oidc.redirecturl":     "defaults to server.publicurl + /api/v1/auth/oidc/callback",
+	"auth.oidc.scopes":          "scopes to request",
+	"auth.oidc.groupsclaim":     "claim listing the user's groups",
+	"auth.oidc.autoprovision":   "create accounts on first login",
+	"auth.oidc.householdgroups": "identity provider group (lowercase) -> household ID; members are added on login",
+	"auth.oidc.postloginurl":    "browser logins are sent here with tokens in the URL fragment",
+	"auth.disablepasswordlogin": "sign in only through OIDC; requires auth.oidc.enabled",
+	"auth.requiretwofactor":     "every account must enroll an authenticator app",
+	"auth.totpissuer":           "name shown in authenticator apps",
+	"auth.registration":         "open, invite_only or closed; admins can change it at runtime",
+	"auth.deletiongracedays":    "days before a deleted account is purged; it can be cancelled until then",
+	"auth.passwordresetexpiry":  "minutes a password reset link works",
+	"auth.passwordreseturl":     "page reset emails link to, with ?token= appended; without it they give the token",
+	"auth.handoffexpiry":        "seconds a QR code for carrying a cooking session to another device works",
+
+	"ratelimit":                   "Request rate limits, 0 = unlimited",
+	"ratelimit.enabled":           "apply the limits below",
+	"ratelimit.userperminute":     "requests per minute per signed-in user",
+	"ratelimit.instanceperminute": "requests per minute across all users",
+	"ratelimit.authperminute":     "requests per minute per client IP on /auth endpoints",
+	"ratelimit.importperhour":     "recipe imports from URLs per user, per hour",
+	"ratelimit.resetperhour":      "password reset emails per address, per hour",
+
+	"ai":                              "AI providers; everything but cachettl is reloaded without a restart",
+	"ai.defaultprovider":              "ollama, openai, gemini or claude",
+	"ai.fallback":                     "enabled providers to try in turn when the default fails, e.g. [ollama]",
+	"ai.ollama.enabled":               "use a self-hosted Ollama server",
+	"ai.ollama.host":                  "Ollama server URL",
+	"ai.ollama.model":                 "Ollama model",
+	"ai.openai.enabled":               "use OpenAI or another server that speaks its API",
+	"ai.openai.apikey":                "OpenAI API key",
+	"ai.openai.baseurl":               "OpenAI API URL",
+	"ai.openai.model":                 "OpenAI model",
+	"ai.openai.pricing.inputpermtok":  "USD per million input tokens, for usage budgets",
+	"ai.openai.pricing.outputpermtok": "USD per million output tokens, for usage budgets",
+	"ai.gemini.enabled":               "use Google Gemini",
+	"ai.gemini.apikey":                "Gemini API key",
+	"ai.gemini.model":                 "Gemini model",
+	"ai.gemini.pricing.inputpermtok":  "USD per million input tokens, for usage budgets",
+	"ai.gemini.pricing.outputpermtok": "USD per million output tokens, for usage budgets",
+	"ai.claude.enabled":               "use Anthropic Claude",
+	"ai.claude.apikey":                "Claude API key",
+	"ai.claude.model":                 "Claude model",
+	"ai.claude.pricing.inputpermtok":  "USD per million input tokens, for usage budgets",
+	"ai.claude.pricing.outputpermtok": "USD per million output tokens, for usage budgets",
+	"ai.budget.usermonthlytokens":     "tokens per user per month, 0 = unlimited; reset on the 1st (UTC)",
+	"ai.budget.usermonthlycost":       "USD per user per month, 0 = unlimited",
+	"ai.budget.instancemonthlycost":   "USD across all users per month, 0 = unlimited",
+	"ai.cachettl":                     "hours AI outputs are reused, 0 = no caching",
+	"ai.timeout":                      "seconds to wait for a provider to answer",
+	"ai.deadline":                     "seconds one request may take across all the providers it tries",
+	"ai.cooldown":                     "seconds a provider that failed is tried only after the others",
+	"ai.promptsdir":                   "directory of .tmpl files overriding the built-in prompts, named after them",
+	"ai.invocationretention":          "days of AI call records to keep; 0 keeps them forever",
+
+	"tts":                "Reading cooking steps aloud; steps are always available as SSML",
+	"tts.provider":       "piper or openai; empty disables audio",
+	"tts.timeout":        "seconds to wait for speech",
+	"tts.piper.url":      "Piper HTTP server URL",
+	"tts.piper.voice":    "the server's default voice when empty",
+	"tts.openai.apikey":  "defaults to ai.openai.apikey",
+	"tts.openai.baseurl": "OpenAI API URL",
+	"tts.openai.model":   "speech model",
+	"tts.openai.voice":   "voice",
+
+	"cooking":           "Cooking assistant",
+	"cooking.autopause": "minutes without step or timer activity before a cooking session is paused, 0 = never",
+	"cooking.nudge":     "send a cooking_session_paused event (webhooks, MQTT) when that happens",
+
+	"storage":               "Uploaded images",
+	"storage.type":          "local or s3",
+	"storage.localpath":     "directory for local storage",
+	"storage.s3bucket":      "S3 bucket",
+	"storage.s3region":      "S3 region",
+	"storage.s3key":         "S3 access key",
+	"storage.s3secret":      "S3 secret key",
+	"storage.s3endpoint":    "MinIO or another S3-compatible service; empty for AWS",
+	"storage.s3pathstyle":   "address the bucket in the path rather than the host name",
+	"storage.urlexpiry":     "minutes an S3 image link stays valid",
+	"storage.maxuploadsize": "megabytes per uploaded image",
+	"storage.importimages":  "store images of imported recipes instead of linking to the source site",
+
+	"fetch":                      "Pages, images and calendars fetched from addresses users give",
+	"fetch.allowdomains":         "when set, only these sites and their subdomains",
+	"fetch.denydomains":          "never these sites or their subdomains",
+	"fetch.allowprivatenetworks": "let imports reach localhost and LAN addresses",
+	"fetch.maxpagesize":          "megabytes",
+	"fetch.timeout":              "seconds",
+
+	"foods":                         "Packaged food lookups",
+	"foods.openfoodfacts.enabled":   "look barcodes up on Open Food Facts",
+	"foods.openfoodfacts.baseurl":   "Open Food Facts URL",
+	"foods.openfoodfacts.useragent": "Open Food Facts asks apps to identify themselves",
+	"foods.openfoodfacts.cachedays": "days a looked-up product is reused before asking again",
+
+	"webhooks":                      "Outgoing webhooks",
+	"webhooks.enabled":              "let users register webhooks",
+	"webhooks.timeout":              "seconds to wait for a receiver to respond",
+	"webhooks.maxattempts":          "attempts before a delivery is given up",
+	"webhooks.retention":            "days of delivery history to keep",
+	"webhooks.allowprivatenetworks": "let deliveries reach localhost and LAN addresses, such as Home Assistant",
+
+	"mqtt":             "Publishing events to an MQTT broker",
+	"mqtt.enabled":     "publish events",
+	"mqtt.broker":      "tcp://host:1883, or ssl:// or mqtts:// for TLS",
+	"mqtt.clientid":    "client ID",
+	"mqtt.username":    "username",
+	"mqtt.password":    "password",
+	"mqtt.topicprefix": "topics are <topicprefix>/<user_id>/<event>",
+	"mqtt.keepalive":   "seconds",
+
+	"mail":          "Sending email: household invitations, password resets and weekly digests",
+	"mail.enabled":  "send email",
+	"mail.mode":     "smtp, or log to write messages to the log instead of sending them (for development)",
+	"mail.host":     "SMTP server",
+	"mail.port":     "587 for STARTTLS, 465 for TLS",
+	"mail.username": "username; leave empty for servers that don't need one",
+	"mail.password": "password",
+	"mail.from":     "sender, e.g. \"Space Food <food@example.com>\"",
+	"mail.tls":      "starttls, tls or none",
+	"mail.timeout":  "seconds to wait for the mail server",
+
+	"calendar":                     "Calendar integration",
+	"calendar.import.enabled":      "let users link calendars that block meal slots",
+	"calendar.import.syncinterval": "minutes between fetches of each linked calendar",
+	"calendar.import.timeout":      "seconds to wait for a calendar server",
+
+	"health":         "Readiness checks",
+	"health.timeout": "seconds each readiness check may take",
+	"health.checkai": "also require the active AI provider to be reachable for /readyz",
+
+	"telemetry":                     "Metrics and tracing",
+	"telemetry.metrics.enabled":     "serve Prometheus metrics",
+	"telemetry.metrics.path":        "metrics path",
+	"telemetry.metrics.token":       "when set, scrapers must send \"Authorization: Bearer <token>\"",
+	"telemetry.tracing.enabled":     "export OpenTelemetry traces",
+	"telemetry.tracing.endpoint":    "OTLP/HTTP collector; spans are posted to <endpoint>/v1/traces",
+	"telemetry.tracing.servicename": "service name",
+	"telemetry.tracing.sampleratio": "share of traces recorded, 0-1",
+	"telemetry.tracing.headers":     "extra headers sent to the collector, e.g. an API key",
+
+	"jobs":              "Background jobs",
+	"jobs.enabled":      "run scheduled jobs",
+	"jobs.pollinterval": "seconds between checks for due jobs",
+	"jobs.runretention": "days of job run history to keep",
+
+	"sync":               "Offline sync",
+	"sync.tombstonedays": "days deleted records are reported to clients that haven't synced; older clients download everything again",
+
+	"audit":           "Audit log",
+	"audit.enabled":   "record sign-ins, membership changes, deletions, exports and admin actions",
+	"audit.retention": "days of audit events to keep; 0 keeps them forever",
+
+	"logging":        "Logging",
+	"logging.level":  "debug, info, warn or error; reloaded without a restart",
+	"logging.format": "json or console",
+}
+
+// WriteExample writes a config file setting every described option to its
+// default, or to the value given in values, with a comment explaining it
+// and naming the environment variable that overrides it
+func WriteExample(w io.Writer, values map[string]any) error {
+	defaults := viper.New()
+	setDefaults(defaults)
+
+	var b strings.Builder
+	b.WriteString("# Space Food configuration\n")
+	b.WriteString("# Every setting can also be set with the environment variable named\n")
+	b.WriteString("# next to it, which takes precedence over this file.\n")
+
+	open := []string{} // sections the previous key was in
+	for _, setting := range settings() {
+		description, ok := descriptions[setting.key]
+		if !ok {
+			continue
+		}
+		path := strings.Split(setting.key, ".")
+		sections := path[:len(path)-1]
+
+		// Open the sections this key is in that the previous one wasn't
+		common := 0
+		for common < len(open) && common < len(sections) && open[common] == sections[common] {
+			common++
+		}
+		for depth := common; depth < len(sections); depth++ {
+			indent := strings.Repeat("  ", depth)
+			if depth == 0 {
+				b.WriteString("\n")
+			}
+			if about, ok := descriptions[strings.Join(sections[:depth+1], ".")]; ok {
+				fmt.Fprintf(&b, "%s# %s\n", indent, about)
+			}
+			fmt.Fprintf(&b, "%s%s:\n", indent, sections[depth])
+		}
+		open = sections
+
+		indent := strings.Repeat("  ", len(sections))
+		value, ok := values[setting.key]
+		if !ok {
+			value = defaults.Get(setting.key)
+		}
+		fmt.Fprintf(&b, "%s# %s\n", indent, description)
+		switch setting.kind {
+		case reflect.Map:
+		case reflect.Slice:
+			fmt.Fprintf(&b, "%s# env: %s, comma-separated\n", indent, EnvName(setting.key))
+		default:
+			fmt.Fprintf(&b, "%s# env: %s\n", indent, EnvName(setting.key))
+		}
+		fmt.Fprintf(&b, "%s%s: %s\n", indent, path[len(path)-1], yamlValue(value, setting.kind))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// yamlValue formats a setting's value, or the zero value of its kind when
+// it has none
+func yamlValue(value any, kind reflect.Kind) string {
+	switch v := value.(type) {
+	case nil:
+	case string:
+		return strconv.Quote(v)
+	case []string:
+		quoted := make([]string, len(v))
+		for i, s := range v {
+			quoted[i] = strconv.Quote(s)
+		}
+		return "[" + strings.Join(quoted, ", ") + "]"
+	default:
+		return fmt.Sprint(v)
+	}
+	switch kind {
+	case reflect.String:
+		return `""`
+	case reflect.Slice:
+		return "[]"
+	case reflect.Map:
+		return "{}"
+	case reflect.Bool:
+		return "false"
+	}
+	return "0"
+}
@@ -0,0 +1,327 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rghsoftware/space-food/pkg/logger"
+	"github.com/spf13/viper"
+)
+
+// reloadDelay lets an editor finish writing the config file before it is
+// read back
+const reloadDelay = 500 * time.Millisecond
+
+// reloadable lists the settings that take effect without a restart, with
+// how to copy each from a freshly read configuration
+var reloadable = []struct {
+	key   string
+	apply func(dst, src *Config)
+}{
+	{"logging.level", func(dst, src *Config) { dst.Logging.Level = src.Logging.Level }},
+	{"ai.defaultprovider", func(dst, src *Config) { dst.AI.DefaultProvider = src.AI.DefaultProvider }},
+	{"ai.fallback", func(dst, src *Config) { dst.AI.Fallback = src.AI.Fallback }},
+	{"ai.ollama", func(dst, src *Config) { dst.AI.Ollama = src.AI.Ollama }},
+	{"ai.openai", func(dst, src *Config) { dst.AI.OpenAI = src.AI.OpenAI }},
+	{"ai.gemini", func(dst, src *Config) { dst.AI.Gemini = src.AI.Gemini }},
+	{"ai.claude", func(dst, src *Config) { dst.AI.Claude = src.AI.Claude }},
+	{"ai.budget", func(dst, src *Config) { dst.AI.Budget = src.AI.Budget }},
+	{"ai.timeout", func(dst, src *Config) { dst.AI.Timeout = src.AI.Timeout }},
+	{"ai.deadline", func(dst, src *Config) { dst.AI.Deadline = src.AI.Deadline }},
+	{"ai.cooldown", func(dst, src *Config) { dst.AI.Cooldown = src.AI.Cooldown }},
+	{"ai.promptsdir", func(dst, src *Config) { dst.AI.PromptsDir = src.AI.PromptsDir }},
+	{"ai.invocationretention", func(dst, src *Config) { dst.AI.InvocationRetention = src.AI.InvocationRetention }},
+}
+
+// Reloadable returns the settings that take effect without a restart
+func Reloadable() []string {
+	keys := make([]string, len(reloadable))
+	for i, setting := range reloadable {
+		keys[i] = setting.key
+	}
+	return keys
+}
+
+// Live holds the configuration the server runs with and reloads it, when
+// the config file changes or the process receives SIGHUP. Only reloadable
+// settings change; other changes wait for a restart and are reported as
+// pending. Features that use reloadable settings register with OnReload.
+// Settings kept outside the file, such as the AI provider chosen in the
+// setup wizard, are laid over it with SetOverlay.
+type Live struct {
+	current atomic.Pointer[Config]
+
+	mu         sync.Mutex // serializes reloads; viper is not safe for concurrent use
+	base       *Config    // the configuration as last read, before the overlay
+	overlay    func(*Config)
+	listeners  []func(*Config)
+	pending    []string
+	reloadedAt *time.Time
+}
+
+// LiveStatus describes where the configuration came from and what the last
+// reload left undone
+type LiveStatus struct {
+	File       string     `json:"file,omitempty"` // empty when only defaults and the environment are used
+	ReloadedAt *time.Time `json:"reloaded_at"`
+	Pending    []string   `json:"pending_restart"` // settings changed in the file that need a restart
+}
+
+// NewLive holds a configuration returned by Load
+func NewLive(cfg *Config) *Live {
+	l := &Live{base: cfg, pending: []string{}}
+	l.current.Store(cfg)
+	return l
+}
+
+// Get returns the current configuration. It must not be modified.
+func (l *Live) Get() *Config {
+	return l.current.Load()
+}
+
+// OnReload registers fn to be called with the new configuration after a
+// reload changes it
+func (l *Live) OnReload(fn func(*Config)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.listeners = append(l.listeners, fn)
+}
+
+// Status reports where the configuration came from and what the last
+// reload left undone
+func (l *Live) Status() LiveStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return LiveStatus{
+		File:       viper.ConfigFileUsed(),
+		ReloadedAt: l.reloadedAt,
+		Pending:    append([]string{}, l.pending...),
+	}
+}
+
+// Reload reads the config file and environment again and applies the
+// reloadable settings that changed, which it returns. A configuration that
+// fails to load leaves the current one in place.
+func (l *Live) Reload() ([]string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := viper.ReadInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+			return nil, fmt.Errorf("error reading config file: %w", err)
+		}
+	}
+	next, err := decode()
+	if err != nil {
+		return nil, err
+	}
+	l.base = next
+	return l.apply(), nil
+}
+
+// SetOverlay sets fn to adjust every configuration read, and applies it to
+// the current one. Only the reloadable settings it changes take effect.
+func (l *Live) SetOverlay(fn func(*Config)) []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.overlay = fn
+	return l.apply()
+}
+
+// apply lays the overlay over the configuration as read, applies the
+// reloadable settings that changed and returns them
+func (l *Live) apply() []string {
+	next := l.base
+	if l.overlay != nil {
+		copied := *next
+		l.overlay(&copied)
+		next = &copied
+	}
+
+	current := l.current.Load()
+	merged := *current
+	changed := []string{}
+	for _, setting := range reloadable {
+		probe := *current
+		setting.apply(&probe, next)
+		if !reflect.DeepEqual(probe, *current) {
+			setting.apply(&merged, next)
+			changed = append(changed, setting.key)
+		}
+	}
+	l.pending = differences(reflect.ValueOf(merged), reflect.ValueOf(*next), "")
+
+	if len(changed) == 0 {
+		return changed
+	}
+	now := time.Now().UTC()
+	l.reloadedAt = &now
+	l.current.Store(&merged)
+	for _, fn := range l.listeners {
+		fn(&merged)
+	}
+	return changed
+}
+
+// Watch reloads the configuration when the config file changes or the
+// process receives SIGHUP, until ctx is done
+func (l *Live) Watch(ctx context.Context) {
+	log := logger.Get()
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	var watcher *fsnotify.Watcher
+	var fileEvents <-chan fsnotify.Event
+	var watchErrors <-chan error
+	file, err := filepath.Abs(viper.ConfigFileUsed())
+	if viper.ConfigFileUsed() != "" && err == nil {
+		// Watch the directory, as editors often replace the file rather
+		// than write to it
+		watcher, err = fsnotify.NewWatcher()
+		if err == nil {
+			err = watcher.Add(filepath.Dir(file))
+		}
+		if err != nil {
+			log.Warn().Err(err).Str("file", file).Msg("Cannot watch the config file; send SIGHUP to reload it")
+		} else {
+			fileEvents, watchErrors = watcher.Events, watcher.Errors
+		}
+	}
+
+	go func() {
+		defer signal.Stop(hup)
+		if watcher != nil {
+			defer watcher.Close()
+		}
+		var due <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				l.reloadAndLog("signal")
+			case event := <-fileEvents:
+				if filepath.Clean(event.Name) == file && (event.Has(fsnotify.Write) || event.Has(fsnotify.Create)) {
+					due = time.After(reloadDelay)
+				}
+			case <-due:
+				due = nil
+				l.reloadAndLog("file change")
+			case err := <-watchErrors:
+				log.Warn().Err(err).Msg("Config file watch failed")
+			}
+		}
+	}()
+}
+
+// reloadAndLog reloads the configuration and logs the outcome
+func (l *Live) reloadAndLog(trigger string) {
+	log := logger.Get()
+	changed, err := l.Reload()
+	if err != nil {
+		log.Error().Err(err).Str("trigger", trigger).Msg("Failed to reload configuration; keeping the current one")
+		return
+	}
+	log.Info().Str("trigger", trigger).Strs("changed", changed).Msg("Configuration reloaded")
+	if pending := l.Status().Pending; len(pending) > 0 {
+		log.Warn().Strs("settings", pending).Msg("Some configuration changes need a restart")
+	}
+}
+
+// differences lists the settings, named as in the config file, whose
+// values differ between two configurations. Sections are compared a level
+// down, so a change is reported as e.g. ai.cachettl rather than ai.
+func differences(a, b reflect.Value, prefix string) []string {
+	keys := []string{}
+	for i := 0; i < a.NumField(); i++ {
+		key := prefix + strings.ToLower(a.Type().Field(i).Name)
+		fa, fb := a.Field(i), b.Field(i)
+		if reflect.DeepEqual(fa.Interface(), fb.Interface()) {
+			continue
+		}
+		if fa.Kind() == reflect.Struct && prefix == "" {
+			keys = append(keys, differences(fa, fb, key+".")...)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// redactedValue stands in for a secret that is set
+const redactedValue = "[redacted]"
+
+// secretFields are settings whose values Redacted hides. Free-form maps,
+// such as database options and tracing headers, may hold credentials too.
+var secretFields = map[string]bool{
+	"password":     true,
+	"jwtsecret":    true,
+	"clientsecret": true,
+	"apikey":       true,
+	"s3key":        true,
+	"s3secret":     true,
+	"token":        true,
+	"headers":      true,
+	"customconfig": true,
+}
+
+// Redacted returns the configuration keyed as in the config file, with
+// secrets that are set replaced by [redacted]
+func (c *Config) Redacted() map[string]any {
+	return redact(reflect.ValueOf(*c)).(map[string]any)
+}
+
+func redact(v reflect.Value) any {
+	if v.Kind() != reflect.Struct {
+		return v.Interface()
+	}
+	out := map[string]any{}
+	for i := 0; i < v.NumField(); i++ {
+		key := strings.ToLower(v.Type().Field(i).Name)
+		field := v.Field(i)
+		switch {
+		case !secretFields[key]:
+			out[key] = redact(field)
+		case field.Kind() == reflect.Map:
+			values := map[string]any{}
+			for _, name := range field.MapKeys() {
+				values[fmt.Sprint(name.Interface())] = redactedValue
+			}
+			out[key] = values
+		case field.IsZero():
+			out[key] = field.Interface()
+		default:
+			out[key] = redactedValue
+		}
+	}
+	return out
+}
@@ -0,0 +1,281 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// ValidationError lists every problem found in a configuration, so they
+// can all be fixed before the next start
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// problems collects what is wrong with a configuration. Each names the
+// setting and the environment variable that overrides it.
+type problems []string
+
+func (p *problems) add(key, format string, args ...any) {
+	*p = append(*p, fmt.Sprintf("%s (%s) %s", key, EnvName(key), fmt.Sprintf(format, args...)))
+}
+
+func (p *problems) required(key, value string) {
+	if value == "" {
+		p.add(key, "is required")
+	}
+}
+
+func (p *problems) oneOf(key, value string, allowed ...string) {
+	if !slices.Contains(allowed, value) {
+		p.add(key, "is %q; use one of %s", value, strings.Join(allowed, ", "))
+	}
+}
+
+func (p *problems) url(key, value string, schemes ...string) {
+	if value == "" {
+		return
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" || !slices.Contains(schemes, u.Scheme) {
+		p.add(key, "is %q; use a %s URL", value, strings.Join(schemes, " or "))
+	}
+}
+
+func atLeast[T int | int64 | uint8 | uint32 | float64](p *problems, key string, value, min T) {
+	if value < min {
+		p.add(key, "is %v; it must be at least %v", value, min)
+	}
+}
+
+func between[T int | float64](p *problems, key string, value, min, max T) {
+	if value < min || value > max {
+		p.add(key, "is %v; it must be between %v and %v", value, min, max)
+	}
+}
+
+// Validate checks required settings, ranges and settings that only make
+// sense together, and reports every problem at once
+func (c *Config) Validate() error {
+	p := &problems{}
+
+	p.required("server.host", c.Server.Host)
+	between(p, "server.port", c.Server.Port, 1, 65535)
+	p.oneOf("server.environment", c.Server.Environment, "development", "staging", "production")
+	p.url("server.publicurl", c.Server.PublicURL, "http", "https")
+	atLeast(p, "server.shutdowndelay", c.Server.ShutdownDelay, 0)
+	atLeast(p, "server.shutdowntimeout", c.Server.ShutdownTimeout, 0)
+	atLeast(p, "server.idempotencywindow", c.Server.IdempotencyWindow, 1)
+
+	p.oneOf("database.type", c.Database.Type, "postgres", "sqlite", "memory")
+	switch c.Database.Type {
+	case "postgres":
+		p.required("database.host", c.Database.Host)
+		between(p, "database.port", c.Database.Port, 1, 65535)
+		p.required("database.name", c.Database.Name)
+		p.required("database.user", c.Database.User)
+		atLeast(p, "database.maxconns", c.Database.MaxConns, 1)
+		between(p, "database.minconns", c.Database.MinConns, 0, max(c.Database.MaxConns, 0))
+	case "sqlite":
+		p.required("database.sqlitepath", c.Database.SQLitePath)
+	}
+
+	p.oneOf("auth.type", c.Auth.Type, "argon2")
+	p.required("auth.jwtsecret", c.Auth.JWTSecret)
+	if c.Server.Environment == "production" && c.Auth.JWTSecret != "" && len(c.Auth.JWTSecret) < 32 {
+		p.add("auth.jwtsecret", "must be at least 32 characters in production")
+	}
+	atLeast(p, "auth.jwtexpiry", c.Auth.JWTExpiry, 1)
+	atLeast(p, "auth.refreshexpiry", c.Auth.RefreshExpiry, 1)
+	atLeast(p, "auth.sessionmaxage", c.Auth.SessionMaxAge, c.Auth.RefreshExpiry)
+	atLeast(p, "auth.argon2memory", c.Auth.Argon2Memory, 8*uint32(max(c.Auth.Argon2Threads, 1)))
+	atLeast(p, "auth.argon2time", c.Auth.Argon2Time, 1)
+	atLeast(p, "auth.argon2threads", c.Auth.Argon2Threads, 1)
+	p.oneOf("auth.registration", c.Auth.Registration, "open", "invite_only", "closed")
+	atLeast(p, "auth.deletiongracedays", c.Auth.DeletionGraceDays, 0)
+	atLeast(p, "auth.passwordresetexpiry", c.Auth.PasswordResetExpiry, 1)
+	p.url("auth.passwordreseturl", c.Auth.PasswordResetURL, "http", "https")
+	atLeast(p, "auth.handoffexpiry", c.Auth.HandoffExpiry, 10)
+	if c.Auth.OIDC.Enabled {
+		p.required("auth.oidc.issuerurl", c.Auth.OIDC.IssuerURL)
+		p.url("auth.oidc.issuerurl", c.Auth.OIDC.IssuerURL, "http", "https")
+		p.required("auth.oidc.clientid", c.Auth.OIDC.ClientID)
+		if c.Auth.OIDC.RedirectURL == "" {
+			p.add("auth.oidc.redirecturl", "is required unless server.publicurl is set")
+		}
+		p.url("auth.oidc.redirecturl", c.Auth.OIDC.RedirectURL, "http", "https")
+	} else if c.Auth.DisablePasswordLogin {
+		p.add("auth.disablepasswordlogin", "needs auth.oidc.enabled, or nobody could sign in")
+	}
+
+	atLeast(p, "ratelimit.userperminute", c.RateLimit.UserPerMinute, 0)
+	atLeast(p, "ratelimit.instanceperminute", c.RateLimit.InstancePerMinute, 0)
+	atLeast(p, "ratelimit.authperminute", c.RateLimit.AuthPerMinute, 0)
+	atLeast(p, "ratelimit.importperhour", c.RateLimit.ImportPerHour, 0)
+	atLeast(p, "ratelimit.resetperhour", c.RateLimit.ResetPerHour, 0)
+
+	c.AI.validate(p)
+
+	p.oneOf("tts.provider", c.TTS.Provider, "", "piper", "openai")
+	switch c.TTS.Provider {
+	case "piper":
+		p.required("tts.piper.url", c.TTS.Piper.URL)
+		p.url("tts.piper.url", c.TTS.Piper.URL, "http", "https")
+	case "openai":
+		if c.TTS.OpenAI.APIKey == "" {
+			p.add("tts.openai.apikey", "is required, or ai.openai.apikey to share it")
+		}
+	}
+	atLeast(p, "tts.timeout", c.TTS.Timeout, 1)
+
+	atLeast(p, "cooking.autopause", c.Cooking.AutoPause, 0)
+
+	p.oneOf("storage.type", c.Storage.Type, "local", "s3")
+	switch c.Storage.Type {
+	case "local":
+		p.required("storage.localpath", c.Storage.LocalPath)
+	case "s3":
+		p.required("storage.s3bucket", c.Storage.S3Bucket)
+		if (c.Storage.S3Key == "") != (c.Storage.S3Secret == "") {
+			p.add("storage.s3key", "and storage.s3secret must be set together")
+		}
+		p.url("storage.s3endpoint", c.Storage.S3Endpoint, "http", "https")
+	}
+	atLeast(p, "storage.urlexpiry", c.Storage.URLExpiry, 1)
+	atLeast(p, "storage.maxuploadsize", c.Storage.MaxUploadSize, 1)
+
+	atLeast(p, "fetch.maxpagesize", c.Fetch.MaxPageSize, 1)
+	atLeast(p, "fetch.timeout", c.Fetch.Timeout, 1)
+	for _, domain := range c.Fetch.AllowDomains {
+		if slices.Contains(c.Fetch.DenyDomains, domain) {
+			p.add("fetch.allowdomains", "and fetch.denydomains both list %q", domain)
+		}
+	}
+
+	if c.Foods.OpenFoodFacts.Enabled {
+		p.required("foods.openfoodfacts.baseurl", c.Foods.OpenFoodFacts.BaseURL)
+		p.url("foods.openfoodfacts.baseurl", c.Foods.OpenFoodFacts.BaseURL, "http", "https")
+		atLeast(p, "foods.openfoodfacts.cachedays", c.Foods.OpenFoodFacts.CacheDays, 0)
+	}
+
+	if c.Webhooks.Enabled {
+		atLeast(p, "webhooks.timeout", c.Webhooks.Timeout, 1)
+		atLeast(p, "webhooks.maxattempts", c.Webhooks.MaxAttempts, 1)
+		atLeast(p, "webhooks.retention", c.Webhooks.Retention, 0)
+	}
+
+	if c.MQTT.Enabled {
+		p.required("mqtt.broker", c.MQTT.Broker)
+		p.url("mqtt.broker", c.MQTT.Broker, "tcp", "mqtt", "ssl", "tls", "mqtts")
+		p.required("mqtt.topicprefix", c.MQTT.TopicPrefix)
+		atLeast(p, "mqtt.keepalive", c.MQTT.KeepAlive, 0)
+	}
+
+	if c.Mail.Enabled {
+		p.oneOf("mail.mode", c.Mail.Mode, "smtp", "log")
+		p.required("mail.from", c.Mail.From)
+		if _, err := mail.ParseAddress(c.Mail.From); c.Mail.From != "" && err != nil {
+			p.add("mail.from", "is %q; it must be an email address, optionally with a name", c.Mail.From)
+		}
+		if c.Mail.Mode == "smtp" {
+			p.required("mail.host", c.Mail.Host)
+			between(p, "mail.port", c.Mail.Port, 1, 65535)
+			p.oneOf("mail.tls", c.Mail.TLS, "starttls", "tls", "none")
+			atLeast(p, "mail.timeout", c.Mail.Timeout, 1)
+		}
+	}
+
+	if c.Calendar.Import.Enabled {
+		atLeast(p, "calendar.import.syncinterval", c.Calendar.Import.SyncInterval, 1)
+		atLeast(p, "calendar.import.timeout", c.Calendar.Import.Timeout, 1)
+	}
+
+	atLeast(p, "health.timeout", c.Health.Timeout, 1)
+
+	if c.Telemetry.Metrics.Enabled && !strings.HasPrefix(c.Telemetry.Metrics.Path, "/") {
+		p.add("telemetry.metrics.path", "is %q; it must start with /", c.Telemetry.Metrics.Path)
+	}
+	if c.Telemetry.Tracing.Enabled {
+		p.required("telemetry.tracing.endpoint", c.Telemetry.Tracing.Endpoint)
+		p.url("telemetry.tracing.endpoint", c.Telemetry.Tracing.Endpoint, "http", "https")
+		between(p, "telemetry.tracing.sampleratio", c.Telemetry.Tracing.SampleRatio, 0, 1)
+	}
+
+	if c.Jobs.Enabled {
+		atLeast(p, "jobs.pollinterval", c.Jobs.PollInterval, 1)
+	}
+	atLeast(p, "jobs.runretention", c.Jobs.RunRetention, 0)
+	atLeast(p, "sync.tombstonedays", c.Sync.TombstoneDays, 1)
+	atLeast(p, "audit.retention", c.Audit.Retention, 0)
+
+	p.oneOf("logging.level", c.Logging.Level, "debug", "info", "warn", "error")
+	p.oneOf("logging.format", c.Logging.Format, "json", "console")
+
+	if len(*p) > 0 {
+		return &ValidationError{Problems: *p}
+	}
+	return nil
+}
+
+// validate checks the AI settings. An enabled provider without the
+// settings it needs would otherwise be skipped without a word.
+func (c AIConfig) validate(p *problems) {
+	p.oneOf("ai.defaultprovider", c.DefaultProvider, "ollama", "openai", "gemini", "claude")
+	if c.Ollama.Enabled {
+		p.required("ai.ollama.host", c.Ollama.Host)
+		p.url("ai.ollama.host", c.Ollama.Host, "http", "https")
+	}
+	if c.OpenAI.Enabled {
+		p.required("ai.openai.apikey", c.OpenAI.APIKey)
+		p.url("ai.openai.baseurl", c.OpenAI.BaseURL, "http", "https")
+	}
+	if c.Gemini.Enabled {
+		p.required("ai.gemini.apikey", c.Gemini.APIKey)
+	}
+	if c.Claude.Enabled {
+		p.required("ai.claude.apikey", c.Claude.APIKey)
+	}
+	enabled := c.EnabledProviders()
+	for _, name := range c.Fallback {
+		if !slices.Contains(enabled, name) {
+			p.add("ai.fallback", "lists %q, which is not an enabled provider", name)
+		}
+	}
+	for _, name := range []string{"openai", "gemini", "claude"} {
+		pricing := c.Pricing(name)
+		atLeast(p, "ai."+name+".pricing.inputpermtok", pricing.InputPerMTok, 0)
+		atLeast(p, "ai."+name+".pricing.outputpermtok", pricing.OutputPerMTok, 0)
+	}
+	atLeast(p, "ai.budget.usermonthlytokens", c.Budget.UserMonthlyTokens, 0)
+	atLeast(p, "ai.budget.usermonthlycost", c.Budget.UserMonthlyCost, 0)
+	atLeast(p, "ai.budget.instancemonthlycost", c.Budget.InstanceMonthlyCost, 0)
+	atLeast(p, "ai.cachettl", c.CacheTTL, 0)
+	atLeast(p, "ai.timeout", c.Timeout, 1)
+	atLeast(p, "ai.deadline", c.Deadline, c.Timeout)
+	atLeast(p, "ai.cooldown", c.Cooldown, 0)
+	atLeast(p, "ai.invocationretention", c.InvocationRetention, 0)
+}
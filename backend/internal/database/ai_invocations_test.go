@@ -0,0 +1,118 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package database_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/database/dbtest"
+)
+
+func TestAIInvocations(t *testing.T) {
+	dbtest.Run(t, func(ctx context.Context, db database.Database) {
+		user := dbtest.User(t, ctx, db)
+		start := time.Now().UTC().Truncate(time.Second).Add(-time.Hour)
+
+		record := func(minute int, userID *string, feature, provider, outcome string, in, out, latency int64, cost float64) *database.AIInvocation {
+			invocation := &database.AIInvocation{
+				ID:           uuid.New().String(),
+				UserID:       userID,
+				Feature:      feature,
+				Provider:     provider,
+				Model:        provider + "/model",
+				InputTokens:  in,
+				OutputTokens: out,
+				LatencyMS:    latency,
+				Cost:         cost,
+				Outcome:      outcome,
+				CreatedAt:    start.Add(time.Duration(minute) * time.Minute),
+			}
+			if outcome == database.AIInvocationFailed {
+				invocation.Error = "timeout"
+			}
+			if err := db.RecordAIInvocation(ctx, invocation); err != nil {
+				t.Fatalf("RecordAIInvocation: %v", err)
+			}
+			return invocation
+		}
+		first := record(0, &user.ID, "translation", "ollama", database.AIInvocationFailed, 0, 0, 3000, 0)
+		record(1, &user.ID, "translation", "openai", database.AIInvocationSucceeded, 100, 20, 400, 0.5)
+		record(2, &user.ID, "translation", "openai", database.AIInvocationSucceeded, 300, 40, 800, 1.5)
+		last := record(3, nil, "substitutions", "openai", database.AIInvocationSucceeded, 50, 10, 200, 0.25)
+
+		all, err := db.ListAIInvocations(ctx, database.AIInvocationFilter{})
+		if err != nil {
+			t.Fatalf("ListAIInvocations: %v", err)
+		}
+		if len(all) != 4 || all[0].ID != last.ID || all[3].ID != first.ID {
+			t.Fatalf("ListAIInvocations returned %d, want 4 newest first", len(all))
+		}
+		if all[3].UserID == nil || *all[3].UserID != user.ID || all[3].Error != "timeout" || all[0].UserID != nil {
+			t.Errorf("ListAIInvocations read back %+v and %+v", all[3], all[0])
+		}
+
+		mine, err := db.ListAIInvocations(ctx, database.AIInvocationFilter{UserID: user.ID, Outcome: database.AIInvocationSucceeded})
+		if err != nil {
+			t.Fatalf("ListAIInvocations for a user: %v", err)
+		}
+		if len(mine) != 2 {
+			t.Errorf("user's successful calls = %d, want 2", len(mine))
+		}
+
+		page, err := db.ListAIInvocations(ctx, database.AIInvocationFilter{
+			After: &database.Cursor{Time: all[1].CreatedAt, ID: all[1].ID},
+			Limit: 1,
+		})
+		if err != nil {
+			t.Fatalf("ListAIInvocations after a cursor: %v", err)
+		}
+		if len(page) != 1 || page[0].ID != all[2].ID {
+			t.Errorf("page after the second call = %v, want the third", page)
+		}
+
+		usage, err := db.SumAIInvocations(ctx, database.AIInvocationFilter{Feature: "translation"})
+		if err != nil {
+			t.Fatalf("SumAIInvocations: %v", err)
+		}
+		if len(usage) != 2 {
+			t.Fatalf("SumAIInvocations returned %d groups, want 2", len(usage))
+		}
+		want := []database.AIFeatureUsage{
+			{Feature: "translation", Provider: "ollama", Requests: 1, Failures: 1, AvgLatencyMS: 3000},
+			{Feature: "translation", Provider: "openai", Requests: 2, InputTokens: 400, OutputTokens: 60, Cost: 2, AvgLatencyMS: 600},
+		}
+		for i := range want {
+			if *usage[i] != want[i] {
+				t.Errorf("SumAIInvocations[%d] = %+v, want %+v", i, *usage[i], want[i])
+			}
+		}
+
+		purged, err := db.PurgeAIInvocations(ctx, start.Add(90*time.Second))
+		if err != nil {
+			t.Fatalf("PurgeAIInvocations: %v", err)
+		}
+		if purged != 2 {
+			t.Errorf("PurgeAIInvocations removed %d, want 2", purged)
+		}
+	})
+}
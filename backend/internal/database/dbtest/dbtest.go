@@ -0,0 +1,118 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package dbtest runs repository tests against a real, migrated database.
+// Tests use an in-memory SQLite database, or the PostgreSQL database named
+// by SPACE_FOOD_TEST_POSTGRES_DSN, and each runs in a transaction that is
+// rolled back when it ends, so tests never see each other's rows.
+//
+// SQLite needs the sqlite_fts5 build tag: go test -tags sqlite_fts5 ./...
+package dbtest
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/database/postgres"
+	"github.com/rghsoftware/space-food/internal/database/sqlite"
+)
+
+// PostgresEnv names a PostgreSQL connection string to test against
+// instead of SQLite. Its schema is migrated to this build's latest.
+const PostgresEnv = "SPACE_FOOD_TEST_POSTGRES_DSN"
+
+// errRollback ends every test transaction
+var errRollback = errors.New("dbtest: rolled back")
+
+var (
+	once    sync.Once
+	shared  database.Database
+	openErr error
+)
+
+// open connects to and migrates the test database, once per test binary
+func open() (database.Database, error) {
+	once.Do(func() {
+		ctx := context.Background()
+		var db database.Database
+		if dsn := os.Getenv(PostgresEnv); dsn != "" {
+			db, openErr = postgres.NewPostgresDB(dsn, 4, 1)
+		} else {
+			db, openErr = sqlite.NewMemoryDB()
+		}
+		if openErr != nil {
+			return
+		}
+		if openErr = db.Connect(ctx); openErr != nil {
+			return
+		}
+		if openErr = db.Migrate(ctx); openErr != nil {
+			db.Close()
+			return
+		}
+		shared = db
+	})
+	return shared, openErr
+}
+
+// Run calls fn with the test database and a context holding a transaction,
+// which is rolled back when fn returns or the test fails. Repository calls
+// must be made with that context.
+func Run(t testing.TB, fn func(ctx context.Context, db database.Database)) {
+	t.Helper()
+	db, err := open()
+	if err != nil {
+		t.Fatalf("failed to open the test database: %v", err)
+	}
+
+	err = db.WithTx(context.Background(), func(ctx context.Context) error {
+		fn(ctx, db)
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("test transaction failed: %v", err)
+	}
+}
+
+// User creates an active user with a unique email, for rows that need an
+// owner
+func User(t testing.TB, ctx context.Context, db database.Database) *database.User {
+	t.Helper()
+	now := time.Now().UTC()
+	id := uuid.New().String()
+	user := &database.User{
+		ID:        id,
+		Email:     id + "@example.com",
+		FirstName: "Test",
+		LastName:  "User",
+		CreatedAt: now,
+		UpdatedAt: now,
+		Active:    true,
+	}
+	if err := db.CreateUser(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	return user
+}
@@ -0,0 +1,42 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package dbtest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/database/dbtest"
+)
+
+func TestRunRollsBack(t *testing.T) {
+	var id string
+	dbtest.Run(t, func(ctx context.Context, db database.Database) {
+		id = dbtest.User(t, ctx, db).ID
+		if _, err := db.GetUserByID(ctx, id); err != nil {
+			t.Fatalf("GetUserByID inside the test: %v", err)
+		}
+	})
+	dbtest.Run(t, func(ctx context.Context, db database.Database) {
+		if user, err := db.GetUserByID(ctx, id); err == nil {
+			t.Errorf("user %s outlived its test", user.ID)
+		}
+	})
+}
@@ -20,7 +20,13 @@ package database
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
 	"time"
+
+	"github.com/rghsoftware/space-food/internal/database/migrate"
 )
 
 // Database defines the contract that all database implementations must fulfill
@@ -29,10 +35,19 @@ type Database interface {
 	Connect(ctx context.Context) error
 	Close() error
 	Health(ctx context.Context) error
+	PoolStats() PoolStats
 	Migrate(ctx context.Context) error
+	Migrations() *migrate.Migrator
+
+	// Transaction management. Database calls made with the context passed
+	// to fn run in one transaction, committed when fn returns nil.
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
 
-	// Transaction management
-	BeginTx(ctx context.Context) (Transaction, error)
+	// Backup. DumpTables reads every application table from one consistent
+	// snapshot; LoadTables replaces the contents of every application table
+	// in one transaction.
+	DumpTables(ctx context.Context, dump TableDump) error
+	LoadTables(ctx context.Context, source TableSource) error
 
 	// User operations
 	CreateUser(ctx context.Context, user *User) error
@@ -46,14 +61,64 @@ type Database interface {
 	GetRecipeByID(ctx context.Context, id string) (*Recipe, error)
 	ListRecipes(ctx context.Context, filter RecipeFilter) ([]*Recipe, error)
 	UpdateRecipe(ctx context.Context, recipe *Recipe) error
+	UpdateRecipeIfUnchanged(ctx context.Context, recipe *Recipe, savedAt time.Time) (bool, error)
 	DeleteRecipe(ctx context.Context, id string) error
 	SearchRecipes(ctx context.Context, query string) ([]*Recipe, error)
 
+	// Recipe history. CreateRecipeRevision numbers the revision after the
+	// recipe's latest; ListRecipeRevisions returns the newest first, all of
+	// them when limit is 0.
+	CreateRecipeRevision(ctx context.Context, revision *RecipeRevision) error
+	ListRecipeRevisions(ctx context.Context, recipeID string, limit int) ([]*RecipeRevision, error)
+	GetRecipeRevision(ctx context.Context, recipeID string, revision int) (*RecipeRevision, error)
+
+	// Recipe note operations; each user keeps their own notes per recipe
+	GetRecipeNote(ctx context.Context, userID, recipeID string) (*RecipeNote, error)
+	UpsertRecipeNote(ctx context.Context, note *RecipeNote) error
+	DeleteRecipeNote(ctx context.Context, userID, recipeID string) error
+
+	// Recipe translation operations, one translation per recipe and
+	// language. ListRecipeTranslationsIn returns the translations of the
+	// given recipes into one language, by recipe ID.
+	GetRecipeTranslation(ctx context.Context, recipeID, language string) (*RecipeTranslation, error)
+	ListRecipeTranslations(ctx context.Context, recipeID string) ([]*RecipeTranslation, error)
+	ListRecipeTranslationsIn(ctx context.Context, recipeIDs []string, language string) (map[string]*RecipeTranslation, error)
+	UpsertRecipeTranslation(ctx context.Context, translation *RecipeTranslation) error
+	DeleteRecipeTranslation(ctx context.Context, recipeID, language string) error
+
+	// Recipe dietary tag operations: the allergens and diets found in a
+	// recipe's ingredients, with the author's overrides, and when it was
+	// last analyzed. The In variants return those of several recipes, by
+	// recipe ID.
+	GetRecipeDietaryAnalysis(ctx context.Context, recipeID string) (*RecipeDietaryAnalysis, error)
+	ListRecipeDietaryAnalysesIn(ctx context.Context, recipeIDs []string) (map[string]*RecipeDietaryAnalysis, error)
+	UpsertRecipeDietaryAnalysis(ctx context.Context, analysis *RecipeDietaryAnalysis) error
+	ListRecipeDietaryTags(ctx context.Context, recipeID string) ([]*RecipeDietaryTag, error)
+	ListRecipeDietaryTagsIn(ctx context.Context, recipeIDs []string) (map[string][]*RecipeDietaryTag, error)
+	UpsertRecipeDietaryTag(ctx context.Context, tag *RecipeDietaryTag) error
+	DeleteRecipeDietaryTag(ctx context.Context, recipeID, tag string) error
+
+	// Recipe rating operations. ListRecipeRatings returns the ratings the
+	// given users gave the given recipes; ListRecipesMade counts the cooking
+	// sessions of them they completed, leaving out recipes none of them made.
+	UpsertRecipeRating(ctx context.Context, rating *RecipeRating) error
+	DeleteRecipeRating(ctx context.Context, userID, recipeID string) error
+	ListRecipeRatings(ctx context.Context, recipeIDs, userIDs []string) ([]*RecipeRating, error)
+	ListRecipesMade(ctx context.Context, recipeIDs, userIDs []string) (map[string]RecipeMade, error)
+
+	// Recipe share link operations
+	CreateRecipeShare(ctx context.Context, share *RecipeShare) error
+	GetRecipeShareByHash(ctx context.Context, tokenHash string) (*RecipeShare, error)
+	ListRecipeShares(ctx context.Context, recipeID string) ([]*RecipeShare, error)
+	TouchRecipeShare(ctx context.Context, id string, usedAt time.Time) error
+	DeleteRecipeShare(ctx context.Context, id string) error
+
 	// Meal plan operations
 	CreateMealPlan(ctx context.Context, plan *MealPlan) error
 	GetMealPlanByID(ctx context.Context, id string) (*MealPlan, error)
 	ListMealPlans(ctx context.Context, filter MealPlanFilter) ([]*MealPlan, error)
 	UpdateMealPlan(ctx context.Context, plan *MealPlan) error
+	UpdateMealPlanIfUnchanged(ctx context.Context, plan *MealPlan, savedAt time.Time) (bool, error)
 	DeleteMealPlan(ctx context.Context, id string) error
 
 	// Pantry operations
@@ -77,27 +142,793 @@ type Database interface {
 
 	// Full-text search
 	SearchFullText(ctx context.Context, query string, entityType string) ([]interface{}, error)
-}
 
-// Transaction represents a database transaction
-type Transaction interface {
-	Commit() error
-	Rollback() error
-	Database
+	// Household operations
+	CreateHousehold(ctx context.Context, household *Household) error
+	GetHouseholdByID(ctx context.Context, id string) (*Household, error)
+	ListHouseholdsByUser(ctx context.Context, userID string) ([]*Household, error)
+	DeleteHousehold(ctx context.Context, id string) error
+	AddHouseholdMember(ctx context.Context, member *HouseholdMember) error
+	GetHouseholdMember(ctx context.Context, householdID, userID string) (*HouseholdMember, error)
+	ListHouseholdMembers(ctx context.Context, householdID string) ([]*HouseholdMember, error)
+	RemoveHouseholdMember(ctx context.Context, householdID, userID string) error
+
+	// Household invitation operations
+	CreateHouseholdInvitation(ctx context.Context, invitation *HouseholdInvitation) error
+	GetHouseholdInvitationByID(ctx context.Context, id string) (*HouseholdInvitation, error)
+	GetHouseholdInvitationByCodeHash(ctx context.Context, codeHash string) (*HouseholdInvitation, error)
+	ListHouseholdInvitations(ctx context.Context, filter HouseholdInvitationFilter) ([]*HouseholdInvitation, error)
+	UpdateHouseholdInvitation(ctx context.Context, invitation *HouseholdInvitation) error
+	AcceptHouseholdInvitation(ctx context.Context, id, userID string, now time.Time) (bool, error)
+
+	// Meal time preference operations
+	GetMealTimePreferences(ctx context.Context, userID string) (*MealTimePreferences, error)
+	UpsertMealTimePreferences(ctx context.Context, prefs *MealTimePreferences) error
+
+	// Dietary restriction operations
+	CreateDietaryRestriction(ctx context.Context, restriction *DietaryRestriction) error
+	GetDietaryRestrictionByID(ctx context.Context, id string) (*DietaryRestriction, error)
+	ListDietaryRestrictions(ctx context.Context, userIDs []string) ([]*DietaryRestriction, error)
+	DeleteDietaryRestriction(ctx context.Context, id string) error
+
+	// Auth session operations
+	CreateAuthSession(ctx context.Context, session *AuthSession) error
+	GetAuthSessionByID(ctx context.Context, id string) (*AuthSession, error)
+	ListActiveAuthSessions(ctx context.Context, userID string) ([]*AuthSession, error)
+	UpdateAuthSession(ctx context.Context, session *AuthSession) error
+	RotateAuthSession(ctx context.Context, session *AuthSession, oldHash string) (bool, error)
+	RevokeAuthSessions(ctx context.Context, userID string, exceptSessionID string) error
+
+	// External identity operations
+	CreateUserIdentity(ctx context.Context, identity *UserIdentity) error
+	GetUserIdentity(ctx context.Context, provider, subject string) (*UserIdentity, error)
+
+	// Two-factor authentication operations
+	GetUserTOTP(ctx context.Context, userID string) (*UserTOTP, error)
+	UpsertUserTOTP(ctx context.Context, totp *UserTOTP) error
+	DeleteUserTOTP(ctx context.Context, userID string) error
+	AdvanceTOTPStep(ctx context.Context, userID string, step int64) (bool, error)
+	ClaimTOTPAttempt(ctx context.Context, userID string, now time.Time, maxAttempts int, lockUntil time.Time) (bool, error)
+	ResetTOTPAttempts(ctx context.Context, userID string) error
+	ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error
+	ConsumeRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID string) (int, error)
+
+	// API token operations
+	CreateAPIToken(ctx context.Context, token *APIToken) error
+	GetAPITokenByHash(ctx context.Context, tokenHash string) (*APIToken, error)
+	ListAPITokens(ctx context.Context, userID string) ([]*APIToken, error)
+	TouchAPIToken(ctx context.Context, id string, usedAt time.Time) error
+	DeleteAPIToken(ctx context.Context, id string) error
+
+	// Password reset token operations
+	CreatePasswordResetToken(ctx context.Context, token *PasswordResetToken) error
+	GetPasswordResetTokenByHash(ctx context.Context, tokenHash string) (*PasswordResetToken, error)
+	UsePasswordResetToken(ctx context.Context, id string, usedAt time.Time) (bool, error)
+	DeletePasswordResetTokens(ctx context.Context, userID string) error
+	PurgePasswordResetTokens(ctx context.Context, before time.Time) (int64, error)
+
+	// Device handoff token operations
+	CreateHandoffToken(ctx context.Context, token *HandoffToken) error
+	GetHandoffTokenByHash(ctx context.Context, tokenHash string) (*HandoffToken, error)
+	UseHandoffToken(ctx context.Context, id string, usedAt time.Time) (bool, error)
+	PurgeHandoffTokens(ctx context.Context, before time.Time) (int64, error)
+
+	// Offline sync operations
+	ListSyncChanges(ctx context.Context, userID string, after int64, limit int) ([]*SyncChange, error)
+	GetSyncChange(ctx context.Context, userID, entity, entityID string) (*SyncChange, error)
+	GetSyncCursor(ctx context.Context, userID string) (*SyncCursor, error)
+	PurgeSyncTombstones(ctx context.Context, before time.Time) (int64, error)
+
+	// Instance administration operations
+	ListUsers(ctx context.Context, filter UserFilter) ([]*User, error)
+	CountUsers(ctx context.Context) (int, error)
+	GetInstanceStats(ctx context.Context) (*InstanceStats, error)
+	GetInstanceSetting(ctx context.Context, key string) (string, error)
+	SetInstanceSetting(ctx context.Context, key, value string) error
+
+	// AI usage operations
+	RecordAIUsage(ctx context.Context, usage *AIUsage) error
+	ListAIUsage(ctx context.Context, userID, period string) ([]*AIUsage, error)
+	GetInstanceAIUsage(ctx context.Context, period string) ([]*AIUsage, error)
+	RecordAIInvocation(ctx context.Context, invocation *AIInvocation) error
+	ListAIInvocations(ctx context.Context, filter AIInvocationFilter) ([]*AIInvocation, error)
+	SumAIInvocations(ctx context.Context, filter AIInvocationFilter) ([]*AIFeatureUsage, error)
+	PurgeAIInvocations(ctx context.Context, before time.Time) (int64, error)
+
+	// Instance metric operations. The figures behind the admin dashboard
+	// are rolled up on a schedule from the counts below; ReplaceInstanceMetrics
+	// swaps out a period's rows for the named metrics.
+	RecordRecipeImport(ctx context.Context, attempt *RecipeImportAttempt) error
+	CountRecipeImports(ctx context.Context, start, end time.Time) ([]*RecipeImportCount, error)
+	PurgeRecipeImports(ctx context.Context, before time.Time) (int64, error)
+	CountActiveUsers(ctx context.Context, start, end time.Time) (int, error)
+	CountCookingSessions(ctx context.Context, start, end time.Time) (started, completed int, err error)
+	ReplaceInstanceMetrics(ctx context.Context, period string, names []string, metrics []*InstanceMetric) error
+	ListInstanceMetrics(ctx context.Context, periods []string) ([]*InstanceMetric, error)
+
+	// AI output cache operations
+	GetAICacheEntry(ctx context.Context, key string) (*AICacheEntry, error)
+	PutAICacheEntry(ctx context.Context, entry *AICacheEntry) error
+	RecordAICacheHit(ctx context.Context, key string) error
+	DeleteAICacheEntry(ctx context.Context, key string) error
+	DeleteAICacheForRecipe(ctx context.Context, recipeID string) (int64, error)
+	PurgeExpiredAICache(ctx context.Context, now time.Time) (int64, error)
+	ClearAICache(ctx context.Context) (int64, error)
+	GetAICacheStats(ctx context.Context, now time.Time) ([]*AICacheStats, error)
+
+	// Scheduled job operations
+	SyncScheduledJob(ctx context.Context, job *ScheduledJob) error
+	ListScheduledJobs(ctx context.Context) ([]*ScheduledJob, error)
+	ClaimScheduledJob(ctx context.Context, name string, now, nextRunAt time.Time) (bool, error)
+	UpdateScheduledJobStatus(ctx context.Context, name string, lastRunAt time.Time, lastStatus string) error
+	CreateJobRun(ctx context.Context, run *JobRun) error
+	FinishJobRun(ctx context.Context, run *JobRun) error
+	ListJobRuns(ctx context.Context, filter JobRunFilter) ([]*JobRun, error)
+	CountJobRuns(ctx context.Context, filter JobRunFilter) (int, error)
+	InterruptJobRuns(ctx context.Context, finishedAt time.Time, reason string) (int64, error)
+	PurgeJobRuns(ctx context.Context, before time.Time) (int64, error)
+
+	// Audit log operations
+	CreateAuditEvent(ctx context.Context, event *AuditEvent) error
+	ListAuditEvents(ctx context.Context, filter AuditEventFilter) ([]*AuditEvent, error)
+	CountAuditEvents(ctx context.Context, filter AuditEventFilter) (int, error)
+	PurgeAuditEvents(ctx context.Context, before time.Time) (int64, error)
+
+	// Idempotency key operations
+	CreateIdempotencyKey(ctx context.Context, key *IdempotencyKey) error
+	GetIdempotencyKey(ctx context.Context, userID, key string) (*IdempotencyKey, error)
+	CompleteIdempotencyKey(ctx context.Context, key *IdempotencyKey) error
+	DeleteIdempotencyKey(ctx context.Context, userID, key string) error
+	PurgeIdempotencyKeys(ctx context.Context, before time.Time) (int64, error)
+
+	// Energy check-in operations
+	CreateEnergyCheckIn(ctx context.Context, checkIn *EnergyCheckIn) error
+	GetEnergyCheckInByID(ctx context.Context, id string) (*EnergyCheckIn, error)
+	GetLatestEnergyCheckIn(ctx context.Context, userID string) (*EnergyCheckIn, error)
+	ListEnergyCheckIns(ctx context.Context, filter EnergyCheckInFilter) ([]*EnergyCheckIn, error)
+	DeleteEnergyCheckIn(ctx context.Context, id string) error
+
+	// Leftover operations
+	CreateLeftover(ctx context.Context, leftover *Leftover) error
+	GetLeftoverByID(ctx context.Context, id string) (*Leftover, error)
+	ListLeftovers(ctx context.Context, filter LeftoverFilter) ([]*Leftover, error)
+	UpdateLeftover(ctx context.Context, leftover *Leftover) error
+	DeleteLeftover(ctx context.Context, id string) error
+	ListThawDueLeftovers(ctx context.Context, now time.Time) ([]*Leftover, error)
+
+	// Grocery price and budget operations
+	CreateGroceryPrice(ctx context.Context, price *GroceryPrice) error
+	GetGroceryPriceByID(ctx context.Context, id string) (*GroceryPrice, error)
+	ListGroceryPrices(ctx context.Context, filter GroceryPriceFilter) ([]*GroceryPrice, error)
+	DeleteGroceryPrice(ctx context.Context, id string) error
+	DeleteShoppingItemPurchase(ctx context.Context, shoppingItemID string) error
+	GetGroceryBudget(ctx context.Context, userID string) (*GroceryBudget, error)
+	UpsertGroceryBudget(ctx context.Context, budget *GroceryBudget) error
+	DeleteGroceryBudget(ctx context.Context, userID string) error
+
+	// Store layout operations
+	CreateStoreLayout(ctx context.Context, layout *StoreLayout) error
+	GetStoreLayoutByID(ctx context.Context, id string) (*StoreLayout, error)
+	ListStoreLayouts(ctx context.Context, householdIDs []string) ([]*StoreLayout, error)
+	UpdateStoreLayout(ctx context.Context, layout *StoreLayout) error
+	SetDefaultStoreLayout(ctx context.Context, householdID, id string) error
+	DeleteStoreLayout(ctx context.Context, id string) error
+
+	// Equipment operations. ListRecipeEquipment returns the equipment each
+	// recipe has been tagged with, leaving out untagged recipes;
+	// SetRecipeEquipment replaces a recipe's tags.
+	CreateHouseholdEquipment(ctx context.Context, equipment *HouseholdEquipment) error
+	GetHouseholdEquipmentByID(ctx context.Context, id string) (*HouseholdEquipment, error)
+	ListHouseholdEquipment(ctx context.Context, householdIDs []string) ([]*HouseholdEquipment, error)
+	DeleteHouseholdEquipment(ctx context.Context, id string) error
+	ListRecipeEquipment(ctx context.Context, recipeIDs []string) (map[string][]string, error)
+	SetRecipeEquipment(ctx context.Context, recipeID string, names []string) error
+
+	// Recipe sensory tag operations. ListRecipeSensoryTags leaves out
+	// recipes their authors haven't tagged.
+	ListRecipeSensoryTags(ctx context.Context, recipeIDs []string) (map[string]*RecipeSensoryTags, error)
+	UpsertRecipeSensoryTags(ctx context.Context, tags *RecipeSensoryTags) error
+	DeleteRecipeSensoryTags(ctx context.Context, recipeID string) error
+
+	// Meal request operations. ListMealRequests puts the most upvoted
+	// requests first; ListMealRequestVotes returns the votes on each
+	// request, leaving out requests nobody has voted on.
+	CreateMealRequest(ctx context.Context, request *MealRequest) error
+	GetMealRequestByID(ctx context.Context, id string) (*MealRequest, error)
+	ListMealRequests(ctx context.Context, filter MealRequestFilter) ([]*MealRequest, error)
+	UpdateMealRequest(ctx context.Context, request *MealRequest) error
+	DeleteMealRequest(ctx context.Context, id string) error
+	UpsertMealRequestVote(ctx context.Context, vote *MealRequestVote) error
+	DeleteMealRequestVote(ctx context.Context, requestID, userID string) error
+	ListMealRequestVotes(ctx context.Context, requestIDs []string) (map[string][]*MealRequestVote, error)
+
+	// Cooking session operations
+	CreateCookingSession(ctx context.Context, session *CookingSession) error
+	GetCookingSessionByID(ctx context.Context, id string) (*CookingSession, error)
+	ListCookingSessions(ctx context.Context, filter CookingSessionFilter) ([]*CookingSession, error)
+	UpdateCookingSession(ctx context.Context, session *CookingSession) error
+	ListIdleCookingSessions(ctx context.Context, idleSince time.Time) ([]*CookingSession, error)
+	RefreshRecipeRating(ctx context.Context, recipeID string) error
+	ListRecipeDifficulties(ctx context.Context, recipeIDs []string) (map[string]RecipeDifficulty, error)
+
+	// Cooking timer operations
+	CreateCookingTimer(ctx context.Context, timer *CookingTimer) error
+	GetCookingTimerByID(ctx context.Context, id string) (*CookingTimer, error)
+	ListCookingTimers(ctx context.Context, filter CookingTimerFilter) ([]*CookingTimer, error)
+	UpdateCookingTimer(ctx context.Context, timer *CookingTimer) error
+
+	// Packaged food product cache operations
+	GetFoodProduct(ctx context.Context, barcode string) (*FoodProduct, error)
+	UpsertFoodProduct(ctx context.Context, product *FoodProduct) error
+
+	// Safe food operations
+	CreateSafeFood(ctx context.Context, food *SafeFood) error
+	GetSafeFoodByID(ctx context.Context, id string) (*SafeFood, error)
+	ListSafeFoods(ctx context.Context, userID string) ([]*SafeFood, error)
+	UpdateSafeFood(ctx context.Context, food *SafeFood) error
+	DeleteSafeFood(ctx context.Context, id string) error
+
+	// Sensory profile operations
+	GetSensoryProfile(ctx context.Context, userID string) (*SensoryProfile, error)
+	UpsertSensoryProfile(ctx context.Context, profile *SensoryProfile) error
+	DeleteSensoryProfile(ctx context.Context, userID string) error
+
+	// Email preference operations
+	GetEmailPreferences(ctx context.Context, userID string) (*EmailPreferences, error)
+	UpsertEmailPreferences(ctx context.Context, prefs *EmailPreferences) error
+
+	// Locale operations
+	GetUserLocale(ctx context.Context, userID string) (*UserLocale, error)
+	UpsertUserLocale(ctx context.Context, locale *UserLocale) error
+	DeleteUserLocale(ctx context.Context, userID string) error
+
+	// Webhook operations
+	CreateWebhook(ctx context.Context, webhook *Webhook) error
+	GetWebhookByID(ctx context.Context, id string) (*Webhook, error)
+	ListWebhooks(ctx context.Context, userID string) ([]*Webhook, error)
+	UpdateWebhook(ctx context.Context, webhook *Webhook) error
+	DeleteWebhook(ctx context.Context, id string) error
+	CreateWebhookDelivery(ctx context.Context, delivery *WebhookDelivery) error
+	UpdateWebhookDelivery(ctx context.Context, delivery *WebhookDelivery) error
+	ListWebhookDeliveries(ctx context.Context, filter WebhookDeliveryFilter) ([]*WebhookDelivery, error)
+	CountWebhookDeliveries(ctx context.Context, filter WebhookDeliveryFilter) (int, error)
+	ListDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]*WebhookDelivery, error)
+	PurgeWebhookDeliveries(ctx context.Context, before time.Time) (int64, error)
+
+	// Calendar feed operations
+	CreateCalendarFeed(ctx context.Context, feed *CalendarFeed) error
+	GetCalendarFeedByHash(ctx context.Context, tokenHash string) (*CalendarFeed, error)
+	ListCalendarFeeds(ctx context.Context, userID string) ([]*CalendarFeed, error)
+	TouchCalendarFeed(ctx context.Context, id string, usedAt time.Time) error
+	DeleteCalendarFeed(ctx context.Context, id string) error
+
+	// External calendar operations
+	CreateExternalCalendar(ctx context.Context, cal *ExternalCalendar) error
+	GetExternalCalendarByID(ctx context.Context, id string) (*ExternalCalendar, error)
+	ListExternalCalendars(ctx context.Context, userID string) ([]*ExternalCalendar, error)
+	ListExternalCalendarsToSync(ctx context.Context, syncedBefore time.Time, limit int) ([]*ExternalCalendar, error)
+	UpdateExternalCalendar(ctx context.Context, cal *ExternalCalendar) error
+	DeleteExternalCalendar(ctx context.Context, id string) error
+	ReplaceExternalCalendarEvents(ctx context.Context, calendarID string, events []*ExternalCalendarEvent) error
+	ListExternalCalendarEvents(ctx context.Context, userID string, from, to time.Time) ([]*ExternalCalendarEvent, error)
+
+	// Account data operations. DumpUserData reads the rows in
+	// UserDataTables belonging to a user from one snapshot.
+	DumpUserData(ctx context.Context, userID string, dump TableDump) error
+	ScheduleAccountDeletion(ctx context.Context, deletion *AccountDeletion) error
+	GetAccountDeletion(ctx context.Context, userID string) (*AccountDeletion, error)
+	CancelAccountDeletion(ctx context.Context, userID string) error
+	ListDueAccountDeletions(ctx context.Context, before time.Time, limit int) ([]*AccountDeletion, error)
+	TransferHouseholdOwnership(ctx context.Context, householdID, newOwnerID string) error
 }
 
 // User represents a user in the system
 type User struct {
-	ID             string
-	Email          string
-	PasswordHash   string
-	FirstName      string
-	LastName       string
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
-	LastLoginAt    *time.Time
-	EmailVerified  bool
-	Active         bool
+	ID                 string
+	Email              string
+	PasswordHash       string
+	FirstName          string
+	LastName           string
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+	LastLoginAt        *time.Time
+	EmailVerified      bool
+	Active             bool
+	IsAdmin            bool
+	MustChangePassword bool // set by an administrator's reset, cleared by the next password change
+}
+
+// AuthSession represents a signed-in device holding a refresh token
+type AuthSession struct {
+	ID               string
+	UserID           string
+	RefreshTokenHash string
+	UserAgent        string
+	IPAddress        string
+	CreatedAt        time.Time
+	LastUsedAt       time.Time
+	ExpiresAt        time.Time
+	RevokedAt        *time.Time
+}
+
+// UserIdentity links a user to an account at an external identity provider
+type UserIdentity struct {
+	Provider  string
+	Subject   string
+	UserID    string
+	CreatedAt time.Time
+}
+
+// UserTOTP holds a user's authenticator app enrollment. The enrollment only
+// counts once confirmed with a first valid code.
+type UserTOTP struct {
+	UserID       string
+	Secret       string
+	Enabled      bool
+	LastUsedStep int64 // last accepted time step, to reject replayed codes
+	CreatedAt    time.Time
+	ConfirmedAt  *time.Time
+}
+
+// APIToken is a personal access token for scripts and integrations
+type APIToken struct {
+	ID         string
+	UserID     string
+	Name       string
+	TokenHash  string `json:"-"`
+	Prefix     string // first characters of the token, to tell tokens apart
+	Scope      string // read, meal-log-write, full
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+	ExpiresAt  *time.Time
+}
+
+// PasswordResetToken lets the holder of a reset link set a new password
+// once, until it expires
+type PasswordResetToken struct {
+	ID        string
+	UserID    string
+	TokenHash string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+// HandoffToken signs its holder in on another device once, shortly after
+// it is issued, and opens Target there
+type HandoffToken struct {
+	ID        string
+	UserID    string
+	TokenHash string
+	Target    string // what to open after signing in, such as "cooking_session:<id>"
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+// SyncChange is the latest change to one of a user's records, recorded by
+// the database itself whichever code made it
+type SyncChange struct {
+	Seq       int64 // the user's change counter when it happened
+	UserID    string
+	Entity    string // recipe, meal_plan, shopping_list_item or cooking_session
+	EntityID  string
+	Deleted   bool
+	ChangedAt time.Time
+}
+
+// SyncCursor is how far a user's change counter has got, and up to where
+// tombstones of deleted records have been purged
+type SyncCursor struct {
+	UserID    string
+	Seq       int64
+	PurgedSeq int64
+}
+
+// InstanceStats summarises instance usage for administrators
+type InstanceStats struct {
+	Users         int `json:"users"`
+	ActiveUsers   int `json:"active_users"`
+	Admins        int `json:"admins"`
+	Recipes       int `json:"recipes"`
+	MealPlans     int `json:"meal_plans"`
+	Households    int `json:"households"`
+	NutritionLogs int `json:"nutrition_logs"`
+}
+
+// AIUsage accumulates AI requests for one user, provider and month.
+// Instance-wide totals leave UserID empty.
+type AIUsage struct {
+	UserID       string    `json:"-"`
+	Provider     string    `json:"provider"`
+	Period       string    `json:"period"` // YYYY-MM
+	Requests     int64     `json:"requests"`
+	InputTokens  int64     `json:"input_tokens"`
+	OutputTokens int64     `json:"output_tokens"`
+	Cost         float64   `json:"cost"` // USD
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// AI invocation outcomes
+const (
+	AIInvocationSucceeded = "success"
+	AIInvocationFailed    = "error"
+)
+
+// AIInvocation is one call to an AI provider. Calls a failover or a repair
+// retry makes are recorded one by one.
+type AIInvocation struct {
+	ID           string    `json:"id"`
+	UserID       *string   `json:"user_id,omitempty"` // nil for calls no user asked for
+	Feature      string    `json:"feature"`           // e.g. translation, substitutions
+	Provider     string    `json:"provider"`
+	Model        string    `json:"model"`
+	InputTokens  int64     `json:"input_tokens"`
+	OutputTokens int64     `json:"output_tokens"`
+	LatencyMS    int64     `json:"latency_ms"`
+	Cost         float64   `json:"cost"` // USD, estimated at the prices configured at the time
+	Outcome      string    `json:"outcome"`
+	Error        string    `json:"error,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// AIFeatureUsage totals AI invocations for one feature and provider
+type AIFeatureUsage struct {
+	Feature      string  `json:"feature"`
+	Provider     string  `json:"provider"`
+	Requests     int64   `json:"requests"`
+	Failures     int64   `json:"failures"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	Cost         float64 `json:"cost"`
+	AvgLatencyMS int64   `json:"avg_latency_ms"`
+}
+
+// InstanceMetric is one rolled-up figure for the admin dashboard, such as
+// a week's active users or one provider's AI tokens for a month
+type InstanceMetric struct {
+	Period    string    `json:"period"` // YYYY-Www for weekly figures, YYYY-MM for monthly ones
+	Metric    string    `json:"metric"`
+	Dimension string    `json:"dimension,omitempty"` // e.g. the AI provider or the import site
+	Value     float64   `json:"value"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RecipeImportAttempt records that a recipe page was fetched from a site
+// and whether a recipe came of it. Neither the user nor the page is kept.
+type RecipeImportAttempt struct {
+	ID        string
+	Domain    string
+	Succeeded bool
+	CreatedAt time.Time
+}
+
+// RecipeImportCount tallies the import attempts from one site
+type RecipeImportCount struct {
+	Domain   string
+	Attempts int
+	Failures int
+}
+
+// AICacheEntry is a stored AI output, keyed by a hash of the prompt inputs
+type AICacheEntry struct {
+	Key       string          `json:"key"`
+	Kind      string          `json:"kind"` // e.g. breakdown, chain_suggestion
+	RecipeID  *string         `json:"recipe_id,omitempty"`
+	Payload   json.RawMessage `json:"payload"`
+	Hits      int64           `json:"hits"`
+	CreatedAt time.Time       `json:"created_at"`
+	ExpiresAt time.Time       `json:"expires_at"`
+	Stale     bool            `json:"stale"` // recipe updated since the entry was stored
+}
+
+// AICacheStats summarises live cache entries of one kind
+type AICacheStats struct {
+	Kind    string `json:"kind"`
+	Entries int64  `json:"entries"`
+	Hits    int64  `json:"hits"`
+}
+
+// ScheduledJob is the persisted schedule state of a background job
+type ScheduledJob struct {
+	Name       string     `json:"name"`
+	Schedule   string     `json:"schedule"`
+	NextRunAt  time.Time  `json:"next_run_at"`
+	LastRunAt  *time.Time `json:"last_run_at"`
+	LastStatus string     `json:"last_status,omitempty"`
+}
+
+// JobRun records one execution of a background job
+type JobRun struct {
+	ID         string     `json:"id"`
+	JobName    string     `json:"job_name"`
+	Trigger    string     `json:"trigger"` // schedule, manual
+	Status     string     `json:"status"`  // running, succeeded, failed
+	Result     string     `json:"result,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
+
+// AuditEvent records a sensitive action: who did it, when, from where and
+// to what
+type AuditEvent struct {
+	ID         string         `json:"id"`
+	UserID     *string        `json:"user_id,omitempty"`     // who acted; nil for unknown accounts and deleted users
+	Action     string         `json:"action"`                // e.g. auth.login, household.member_removed
+	TargetType string         `json:"target_type,omitempty"` // e.g. user, household, recipe
+	TargetID   string         `json:"target_id,omitempty"`
+	Details    map[string]any `json:"details,omitempty"`
+	IPAddress  string         `json:"ip_address,omitempty"`
+	UserAgent  string         `json:"user_agent,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+// IdempotencyKey is a request a client sent with an Idempotency-Key and
+// the response it got, replayed if the client sends the request again
+type IdempotencyKey struct {
+	UserID      string
+	Key         string
+	RequestHash string // method, path and body, so a reused key can be told apart
+	StatusCode  int    // 0 while the first request is still running
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+}
+
+// EnergyCheckIn is a self-reported energy level at a point in time
+type EnergyCheckIn struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"-"`
+	Level      int       `json:"level"` // 1 (running on empty) to 5 (full tank)
+	Note       string    `json:"note,omitempty"`
+	RecordedAt time.Time `json:"recorded_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Leftover storage locations
+const (
+	LeftoverStorageFridge  = "fridge"
+	LeftoverStorageFreezer = "freezer"
+)
+
+// Leftover is a batch of cooked food kept for later
+type Leftover struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"-"`
+	RecipeID   *string    `json:"recipe_id,omitempty"`
+	Name       string     `json:"name"`
+	FoodType   string     `json:"food_type"` // e.g. poultry, soup, rice
+	Portions   float64    `json:"portions"`
+	Container  string     `json:"container,omitempty"`
+	Storage    string     `json:"storage"` // fridge, freezer
+	StoredAt   time.Time  `json:"stored_at"`
+	EatBy      time.Time  `json:"eat_by"`
+	Notes      string     `json:"notes,omitempty"`
+	Reheat     string     `json:"reheat,omitempty"`  // how to thaw and reheat, printed on freezer labels
+	ThawAt     *time.Time `json:"thaw_at,omitempty"` // when to move it from the freezer to the fridge
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	// ThawRemindedAt is when the thaw reminder went out; changing ThawAt
+	// clears it
+	ThawRemindedAt *time.Time `json:"thaw_reminded_at,omitempty"`
+}
+
+// Cooking session statuses
+const (
+	CookingSessionActive    = "active"
+	CookingSessionPaused    = "paused"
+	CookingSessionCompleted = "completed"
+	CookingSessionAbandoned = "abandoned"
+)
+
+// CookingSession is a recipe being cooked one step at a time. The steps
+// are copied from the recipe when cooking starts, so editing the recipe
+// mid-cook does not move the cook's place.
+type CookingSession struct {
+	ID          string             `json:"id"`
+	UserID      string             `json:"-"`
+	RecipeID    *string            `json:"recipe_id,omitempty"`
+	RecipeTitle string             `json:"recipe_title"`
+	Status      string             `json:"status"`       // active, paused, completed, abandoned
+	CurrentStep int                `json:"current_step"` // index into Steps
+	Steps       []CookingStep      `json:"steps"`
+	StartedAt   time.Time          `json:"started_at"`
+	PausedAt    *time.Time         `json:"paused_at,omitempty"`
+	FinishedAt  *time.Time         `json:"finished_at,omitempty"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+	Notes       string             `json:"notes"`      // the cook's own notes while cooking
+	Reflection  *CookingReflection `json:"reflection"` // nil until the cook reflects on a completed session
+}
+
+// CookingReflection is how a completed cooking session went
+type CookingReflection struct {
+	Tasted         string    `json:"tasted"`                     // how it tasted, in the cook's words
+	WouldMakeAgain *int      `json:"would_make_again,omitempty"` // 1-5
+	Difficulty     *int      `json:"difficulty,omitempty"`       // how hard it felt, 1 (easy) to 5 (hard)
+	PhotoURL       string    `json:"photo_url,omitempty"`
+	ActualMinutes  *int      `json:"actual_minutes,omitempty"` // the total time it really took
+	ReflectedAt    time.Time `json:"reflected_at"`
+}
+
+// CookingStep is one step of a cooking session with the timers it calls for
+type CookingStep struct {
+	Text   string             `json:"text"`
+	Timers []CookingStepTimer `json:"timers"`
+	Cues   []string           `json:"cues,omitempty"` // what the step is hard on the senses for
+}
+
+// CookingStepTimer is a timer a step calls for, e.g. "simmer for 20 minutes"
+type CookingStepTimer struct {
+	Label   string `json:"label"`
+	Seconds int    `json:"seconds"`
+}
+
+// Cooking timer statuses. A running timer whose end has passed is done;
+// that is worked out when it is read rather than stored.
+const (
+	CookingTimerRunning   = "running"
+	CookingTimerPaused    = "paused"
+	CookingTimerCancelled = "cancelled"
+)
+
+// CookingTimer counts down during a cooking session, or on its own for
+// anything else in the kitchen. A running timer ends at EndsAt; a paused
+// one keeps the seconds it had left.
+type CookingTimer struct {
+	ID               string     `json:"id"`
+	UserID           string     `json:"-"`
+	SessionID        *string    `json:"session_id,omitempty"`
+	StepIndex        *int       `json:"step_index,omitempty"`
+	Label            string     `json:"label"`
+	DurationSeconds  int        `json:"duration_seconds"`
+	Status           string     `json:"status"`
+	EndsAt           *time.Time `json:"ends_at,omitempty"`
+	RemainingSeconds int        `json:"remaining_seconds"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// SafeFood is a food a user can always rely on eating. Protected safe foods
+// are never the target of suggestions to try something else.
+type SafeFood struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"-"`
+	Name      string    `json:"name"` // stored lowercase, e.g. chicken nuggets
+	RecipeID  *string   `json:"recipe_id,omitempty"`
+	Protected bool      `json:"protected"`
+	Notes     string    `json:"notes,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SensoryProfile holds a user's sensory food preferences, used to condition
+// suggestions
+type SensoryProfile struct {
+	UserID                string    `json:"-"`
+	AvoidTextures         []string  `json:"avoid_textures"`         // e.g. slimy, mushy
+	PreferredTemperatures []string  `json:"preferred_temperatures"` // hot, warm, room_temperature, cold
+	SmellAversions        []string  `json:"smell_aversions"`        // e.g. fish, vinegar
+	MaxSpiceLevel         *int      `json:"max_spice_level"`        // 0 (not spicy) to 3 (hot); nil for no limit
+	AvoidCues             []string  `json:"avoid_cues"`             // cooking steps to be warned about: strong_smell, loud, splatter
+	EatingStyle           string    `json:"eating_style"`           // cutlery, finger_food, no_preference
+	Notes                 string    `json:"notes,omitempty"`
+	UpdatedAt             time.Time `json:"updated_at"`
+}
+
+// EmailPreferences are the kinds of email a user has chosen to receive.
+// Password resets and other account security email are always sent.
+type EmailPreferences struct {
+	UserID           string    `json:"-"`
+	HouseholdInvites bool      `json:"household_invites"`
+	WeeklyDigest     bool      `json:"weekly_digest"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// UserLocale is the language a user reads the app in
+type UserLocale struct {
+	UserID    string    `json:"-"`
+	Locale    string    `json:"locale"` // BCP 47 tag, e.g. es or pt-BR
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// Webhook is an outgoing integration that receives a user's events
+type Webhook struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"-"`
+	URL         string    `json:"url"`
+	Secret      string    `json:"-"`      // signs deliveries; shown once on creation
+	Events      []string  `json:"events"` // empty for every event type
+	Description string    `json:"description,omitempty"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// WebhookDelivery is one event sent, or still to be sent, to a webhook
+type WebhookDelivery struct {
+	ID             string          `json:"id"`
+	WebhookID      string          `json:"webhook_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"` // pending, succeeded, failed
+	Attempts       int             `json:"attempts"`
+	ResponseStatus int             `json:"response_status,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"` // nil once settled
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
+
+// CalendarFeed is a secret iCalendar subscription URL for a user's meal plans
+type CalendarFeed struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"-"`
+	Name       string     `json:"name"`
+	TokenHash  string     `json:"-"`
+	Prefix     string     `json:"prefix"` // first characters of the token, to tell feeds apart
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// ExternalCalendar is an iCalendar URL a user links so the meal planner
+// knows when they are out
+type ExternalCalendar struct {
+	ID           string     `json:"id"`
+	UserID       string     `json:"-"`
+	Name         string     `json:"name"`
+	URL          string     `json:"url"`
+	MealTypes    []string   `json:"meal_types"` // meal slots its events block
+	LastSyncedAt *time.Time `json:"last_synced_at,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// ExternalCalendarEvent is a busy period imported from an external calendar.
+// Recurring events are stored once per occurrence.
+type ExternalCalendarEvent struct {
+	CalendarID string    `json:"calendar_id"`
+	UserID     string    `json:"-"`
+	UID        string    `json:"uid"`
+	Summary    string    `json:"summary"`
+	StartsAt   time.Time `json:"starts_at"`
+	EndsAt     time.Time `json:"ends_at"`
+}
+
+// AccountDeletion is a user's request to delete their account, carried out
+// once the grace period ends
+type AccountDeletion struct {
+	UserID      string    `json:"-"`
+	RequestedAt time.Time `json:"requested_at"`
+	DeleteAfter time.Time `json:"delete_after"`
+}
+
+// FoodProduct is a packaged food looked up by barcode. Products the source
+// does not know are cached too, with Found false, to avoid repeat lookups.
+type FoodProduct struct {
+	Barcode             string         `json:"barcode"`
+	Found               bool           `json:"found"`
+	Source              string         `json:"source"` // e.g. openfoodfacts
+	Name                string         `json:"name"`
+	Brand               string         `json:"brand,omitempty"`
+	Quantity            string         `json:"quantity,omitempty"`     // package size, e.g. "400 g"
+	ServingSize         string         `json:"serving_size,omitempty"` // e.g. "1/2 pizza (200 g)"
+	ImageURL            string         `json:"image_url,omitempty"`
+	Categories          []string       `json:"categories"`
+	NutritionPer100g    *NutritionInfo `json:"nutrition_per_100g"`
+	NutritionPerServing *NutritionInfo `json:"nutrition_per_serving"`
+	FetchedAt           time.Time      `json:"fetched_at"`
 }
 
 // Recipe represents a recipe
@@ -121,6 +952,63 @@ type Recipe struct {
 	Rating          float64
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
+	CookNotes       *RecipeNote         // the viewer's own notes; only set by the recipe view
+	Effort          *RecipeEffort       // computed; only set by recipe listings and the recipe view
+	Equipment       []string            // what it needs, as tagged or else found in the instructions; only set by recipe listings and the recipe view
+	Ratings         *RecipeRatings      // how the viewer's households rate and cook it; only set by recipe listings and the recipe view
+	Translation     *RecipeTranslation  // in the viewer's language, when there is one; only set by recipe listings and the recipe view
+	DietaryTags     []*RecipeDietaryTag // allergens and diets found in the ingredients or set by the author; only set by recipe listings and the recipe view
+	Sensory         *RecipeSensory      // spice level and the steps that are loud, smelly or spit oil; only set by recipe listings and the recipe view
+}
+
+// RecipeEffort scores how much effort a recipe takes, from 1 (barely any)
+// to 10
+type RecipeEffort struct {
+	Score         int               `json:"score"`
+	Steps         int               `json:"steps"`
+	ActiveMinutes int               `json:"active_minutes"` // hands-on time, without unattended waits such as simmering
+	Equipment     []string          `json:"equipment"`
+	Difficulty    *RecipeDifficulty `json:"difficulty,omitempty"` // as rated by cooks on this instance
+}
+
+// RecipeSensory is what cooking and eating a recipe is like for the
+// senses, as found in the recipe or tagged by its author
+type RecipeSensory struct {
+	SpiceLevel  int           `json:"spice_level"` // 0 (not spicy) to 3 (hot)
+	SpiceTagged bool          `json:"spice_tagged"`
+	Cues        []string      `json:"cues"`  // every cue of any step
+	Steps       []StepSensory `json:"steps"` // the steps with cues or tagged by the author
+}
+
+// StepSensory is what a step of the method is hard on the senses for
+type StepSensory struct {
+	Step   int      `json:"step"` // numbered from 1, as in cook mode
+	Cues   []string `json:"cues"` // strong_smell, loud, splatter
+	Tagged bool     `json:"tagged"`
+}
+
+// RecipeSensoryTags are what a recipe's author has said about its spice
+// level and steps, in place of what is found in the recipe
+type RecipeSensoryTags struct {
+	RecipeID   string           `json:"-"`
+	SpiceLevel *int             `json:"spice_level"` // nil to go by the ingredients
+	Steps      []RecipeStepCues `json:"steps"`
+	UpdatedAt  time.Time        `json:"updated_at"`
+}
+
+// RecipeStepCues tags a step with its cues. The step is kept by its text,
+// so the tag follows it when steps are added before it and lapses when it
+// is reworded.
+type RecipeStepCues struct {
+	Text string   `json:"text"`
+	Cues []string `json:"cues"`
+}
+
+// RecipeDifficulty is how hard cooks found a recipe, averaged from their
+// reflections
+type RecipeDifficulty struct {
+	Average float64 `json:"average"` // 1 (easy) to 5 (hard)
+	Ratings int     `json:"ratings"`
 }
 
 // Ingredient represents a recipe ingredient
@@ -146,6 +1034,118 @@ type NutritionInfo struct {
 	Sodium        float64
 }
 
+// RecipeRevision is a recipe's content as saved by one edit
+type RecipeRevision struct {
+	ID           string          `json:"id"`
+	RecipeID     string          `json:"recipe_id"`
+	Revision     int             `json:"revision"`
+	UserID       string          `json:"user_id"`                 // who saved it
+	RestoredFrom *int            `json:"restored_from,omitempty"` // revision a rollback brought back
+	Content      json.RawMessage `json:"content"`                 // the versioned recipe fields
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// RecipeNote is what one user keeps in mind when cooking a recipe: free
+// notes and standing changes such as "halve the sugar"
+type RecipeNote struct {
+	UserID        string               `json:"-"`
+	RecipeID      string               `json:"recipe_id"`
+	Notes         string               `json:"notes"`
+	Modifications []RecipeModification `json:"modifications"`
+	UpdatedAt     time.Time            `json:"updated_at"`
+}
+
+// RecipeTranslation is a recipe's text in another language, shared by
+// everyone who can see the recipe
+type RecipeTranslation struct {
+	RecipeID          string                 `json:"recipe_id"`
+	Language          string                 `json:"language"` // BCP 47 tag, e.g. es or pt-BR
+	Title             string                 `json:"title"`
+	Description       string                 `json:"description"`
+	Instructions      string                 `json:"instructions"`
+	Ingredients       []TranslatedIngredient `json:"ingredients"`         // in the order of the recipe's ingredients
+	MachineTranslated bool                   `json:"machine_translated"`  // written by the AI provider and not corrected since
+	Model             string                 `json:"model,omitempty"`     // the provider and model that translated it
+	PromptID          string                 `json:"prompt_id,omitempty"` // the prompt it was translated with
+	SourceUpdatedAt   time.Time              `json:"source_updated_at"`   // the recipe's updated time when it was translated
+	Outdated          bool                   `json:"outdated"`            // computed: the recipe changed since
+	CreatedAt         time.Time              `json:"created_at"`
+	UpdatedAt         time.Time              `json:"updated_at"`
+}
+
+// TranslatedIngredient is an ingredient's name and notes in another
+// language
+type TranslatedIngredient struct {
+	Name  string `json:"name"`
+	Notes string `json:"notes,omitempty"`
+}
+
+// RecipeDietaryAnalysis records when a recipe's dietary tags were last
+// worked out from its ingredients
+type RecipeDietaryAnalysis struct {
+	RecipeID        string    `json:"-"`
+	Version         int       `json:"version"`           // of the analyzer, so improvements reach older recipes
+	SourceUpdatedAt time.Time `json:"source_updated_at"` // the recipe's updated time when it was analyzed
+	AnalyzedAt      time.Time `json:"analyzed_at"`
+}
+
+// RecipeDietaryTag is an allergen a recipe likely contains, or a diet it
+// likely suits
+type RecipeDietaryTag struct {
+	RecipeID    string    `json:"-"`
+	Tag         string    `json:"tag"`         // e.g. gluten or vegan
+	Kind        string    `json:"kind"`        // allergen or diet
+	Detected    bool      `json:"detected"`    // found by the analyzer
+	Confidence  float64   `json:"confidence"`  // of the analyzer, 0 to 1
+	Ingredients []string  `json:"ingredients"` // what it went by
+	Override    *bool     `json:"override"`    // the author's say, which wins over detection; null when they haven't
+	Applies     bool      `json:"applies"`     // computed: the override, or else detected
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// RecipeRating is the stars one user gave a recipe
+type RecipeRating struct {
+	UserID    string    `json:"-"`
+	RecipeID  string    `json:"recipe_id"`
+	Stars     int       `json:"stars"` // 1 to 5
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RecipeMade is how often a group of people cooked a recipe to the end
+type RecipeMade struct {
+	Count  int
+	LastAt time.Time
+}
+
+// RecipeRatings is how a group of people, the viewer and everyone they
+// share a household with, rate and cook a recipe
+type RecipeRatings struct {
+	Stars      *float64   `json:"stars"` // average of the stars given; null until someone rates it
+	Ratings    int        `json:"ratings"`
+	MyStars    *int       `json:"my_stars"`
+	MadeCount  int        `json:"made_count"` // completed cooking sessions
+	LastMadeAt *time.Time `json:"last_made_at"`
+}
+
+// RecipeModification is a standing substitution or adjustment, optionally
+// tied to one ingredient
+type RecipeModification struct {
+	Ingredient string `json:"ingredient,omitempty"` // e.g. sugar
+	Change     string `json:"change"`               // e.g. use half
+}
+
+// RecipeShare is a secret link that publishes a recipe for other
+// instances to import. Only the token's hash is stored.
+type RecipeShare struct {
+	ID         string     `json:"id"`
+	RecipeID   string     `json:"recipe_id"`
+	UserID     string     `json:"-"`
+	TokenHash  string     `json:"-"`
+	Prefix     string     `json:"prefix"` // first characters of the token, to tell links apart
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
 // MealPlan represents a meal plan
 type MealPlan struct {
 	ID          string
@@ -198,10 +1198,102 @@ type ShoppingListItem struct {
 	Notes     string
 	Completed bool
 	RecipeID  *string
+	Price     *float64 // what the item costs or cost, for the quantity
+	Store     string
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
 
+// GroceryPrice is a price a user paid or saw for an item at a store. The
+// latest price of each item at each store is their price memory; purchases
+// also count towards their spending.
+type GroceryPrice struct {
+	ID             string    `json:"id"`
+	UserID         string    `json:"-"`
+	Store          string    `json:"store,omitempty"`
+	Name           string    `json:"name"` // stored lowercase
+	Quantity       float64   `json:"quantity"`
+	Unit           string    `json:"unit,omitempty"`
+	Price          float64   `json:"price"` // for the quantity
+	Purchased      bool      `json:"purchased"`
+	ShoppingItemID *string   `json:"shopping_item_id,omitempty"` // the item whose purchase this records
+	RecordedAt     time.Time `json:"recorded_at"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// StoreLayout is the order a household walks a grocery store's aisles in,
+// as shopping list categories
+type StoreLayout struct {
+	ID          string    `json:"id"`
+	HouseholdID string    `json:"household_id"`
+	Name        string    `json:"name"`       // the store, matched against shopping list items' Store
+	Categories  []string  `json:"categories"` // lowercase, in aisle order
+	IsDefault   bool      `json:"is_default"` // used for items from stores without a layout
+	CreatedBy   *string   `json:"created_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// HouseholdEquipment is a piece of kitchen equipment a household owns
+type HouseholdEquipment struct {
+	ID          string    `json:"id"`
+	HouseholdID string    `json:"household_id"`
+	Name        string    `json:"name"` // lowercase, e.g. "air fryer"
+	Notes       string    `json:"notes,omitempty"`
+	CreatedBy   *string   `json:"created_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Meal request statuses
+const (
+	MealRequestOpen     = "open"
+	MealRequestAccepted = "accepted"
+	MealRequestDeclined = "declined"
+)
+
+// Meal request votes
+const (
+	MealRequestVoteUp      = "up"
+	MealRequestVoteCantEat = "cant_eat"
+)
+
+// MealRequest is a meal a household member would like to eat, proposed on
+// the household's request board
+type MealRequest struct {
+	ID          string     `json:"id"`
+	HouseholdID string     `json:"household_id"`
+	RequestedBy *string    `json:"requested_by,omitempty"` // cleared when the member's account is deleted
+	RecipeID    *string    `json:"recipe_id,omitempty"`
+	Title       string     `json:"title"` // the recipe's title when one is linked
+	Notes       string     `json:"notes,omitempty"`
+	Status      string     `json:"status"` // open, accepted, declined
+	DecidedBy   *string    `json:"decided_by,omitempty"`
+	DecidedAt   *time.Time `json:"decided_at,omitempty"`
+	MealPlanID  *string    `json:"meal_plan_id,omitempty"` // the plan an accepted request was added to
+	PlannedDate *time.Time `json:"planned_date,omitempty"`
+	MealType    string     `json:"meal_type,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// MealRequestVote is a member's vote on a meal request: an upvote, or a
+// note that they can't eat it
+type MealRequestVote struct {
+	RequestID string    `json:"-"`
+	UserID    string    `json:"user_id"`
+	Vote      string    `json:"vote"` // up, cant_eat
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// GroceryBudget is how much a user means to spend on groceries a month
+type GroceryBudget struct {
+	UserID       string    `json:"-"`
+	MonthlyLimit float64   `json:"monthly_limit"`
+	Currency     string    `json:"currency,omitempty"` // a label such as EUR, shown with amounts
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
 // NutritionLog represents a nutrition tracking entry
 type NutritionLog struct {
 	ID             string
@@ -216,13 +1308,143 @@ type NutritionLog struct {
 	CreatedAt      time.Time
 }
 
-// RecipeFilter for querying recipes
+// Household roles
+const (
+	HouseholdRoleOwner       = "owner"
+	HouseholdRoleAdmin       = "admin"
+	HouseholdRoleMember      = "member"
+	HouseholdRoleParticipant = "participant" // follows the plan and shopping list, edits nothing
+)
+
+// Household invitation statuses
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusDeclined = "declined"
+	InvitationStatusRevoked  = "revoked"
+)
+
+// Household represents a group of users sharing meal planning
+type Household struct {
+	ID        string
+	Name      string
+	OwnerID   string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// HouseholdMember represents a user's membership in a household
+type HouseholdMember struct {
+	HouseholdID string
+	UserID      string
+	Role        string // owner, admin, member, participant
+	JoinedAt    time.Time
+
+	// Populated from the users table when listing members
+	Email     string
+	FirstName string
+	LastName  string
+}
+
+// HouseholdInvitation represents an invitation to join a household
+type HouseholdInvitation struct {
+	ID          string
+	HouseholdID string
+	InvitedBy   string
+	Email       string // optional, restricts who can accept by ID
+	CodeHash    string `json:"-"`
+	Role        string
+	Status      string // pending, accepted, declined, revoked
+	ExpiresAt   time.Time
+	CreatedAt   time.Time
+	RespondedAt *time.Time
+	RespondedBy *string
+}
+
+// MealWindow is the time of day during which a meal type is eaten,
+// expressed in minutes after local midnight
+type MealWindow struct {
+	MealType    string // breakfast, lunch, dinner, snack
+	StartMinute int
+	EndMinute   int
+}
+
+// MealTimePreferences holds a user's meal windows for time-aware suggestions
+type MealTimePreferences struct {
+	UserID          string
+	Timezone        string // IANA zone name, e.g. Europe/Berlin
+	Windows         []MealWindow
+	BreakfastAllDay bool
+	UpdatedAt       time.Time
+}
+
+// Dietary restriction kinds
+const (
+	DietaryKindAllergy     = "allergy"
+	DietaryKindIntolerance = "intolerance"
+	DietaryKindPreference  = "preference"
+)
+
+// DietaryRestriction is an allergy, intolerance or dietary preference recorded by a user
+type DietaryRestriction struct {
+	ID        string
+	UserID    string
+	Kind      string // allergy, intolerance, preference
+	Name      string // e.g. peanuts, lactose, vegetarian
+	Severity  string // optional: mild, moderate, severe
+	Notes     string
+	CreatedAt time.Time
+}
+
+// PoolStats describes the database connection pool
+type PoolStats struct {
+	MaxConns     int
+	OpenConns    int
+	InUseConns   int
+	IdleConns    int
+	WaitCount    int64         // acquisitions that had to wait for a connection
+	WaitDuration time.Duration // total time spent waiting
+}
+
+// DerivedTables are filled in by triggers as the other tables change, so
+// backups leave them out and restores rebuild them
+var DerivedTables = map[string]bool{
+	"sync_changes": true,
+	"sync_cursors": true,
+}
+
+// TableDump receives the contents of the database table by table
+type TableDump interface {
+	// BeginTable starts a table; the rows that follow belong to it
+	BeginTable(name string) error
+	// Row receives one row as a JSON object keyed by column name
+	Row(row json.RawMessage) error
+}
+
+// TableSource provides the rows LoadTables writes
+type TableSource interface {
+	// Tables lists the tables the source has rows for
+	Tables() []string
+	// Rows calls fn with each row of a table, as passed to TableDump.Row;
+	// fn may keep the row
+	Rows(table string, fn func(row json.RawMessage) error) error
+}
+
+// Cursor is a keyset position in a list sorted newest first. The list
+// continues with the rows older than Time, ties broken by descending ID.
+type Cursor struct {
+	Time time.Time
+	ID   string
+}
+
+// RecipeFilter for querying recipes, newest first
 type RecipeFilter struct {
 	UserID      string
 	Categories  []string
 	Tags        []string
 	MinRating   *float64
 	MaxPrepTime *int
+	After       *Cursor // created_at and ID to continue after; replaces Offset
 	Limit       int
 	Offset      int
 }
@@ -262,3 +1484,121 @@ type NutritionFilter struct {
 	Limit     int
 	Offset    int
 }
+
+// HouseholdInvitationFilter for querying household invitations
+type HouseholdInvitationFilter struct {
+	HouseholdID string
+	Email       string
+	PendingOnly bool // pending and not yet expired
+	Limit       int
+	Offset      int
+}
+
+// MealRequestFilter for querying a household's meal requests
+type MealRequestFilter struct {
+	HouseholdID string
+	Status      string // all statuses when empty
+	Limit       int
+	Offset      int
+}
+
+// UserFilter for listing users
+type UserFilter struct {
+	Query  string // matches email or name
+	Limit  int
+	Offset int
+}
+
+// JobRunFilter for listing job runs, newest first
+type JobRunFilter struct {
+	JobName string
+	Status  string
+	After   *Cursor // started_at and ID to continue after; replaces Offset
+	Limit   int
+	Offset  int
+}
+
+// AuditEventFilter for listing audit events, newest first; zero times are
+// unbounded
+type AuditEventFilter struct {
+	UserID     string
+	Action     string // an action, or a prefix ending in a dot such as "auth."
+	TargetType string
+	TargetID   string
+	Since      time.Time
+	Until      time.Time
+	After      *Cursor // created_at and ID to continue after; replaces Offset
+	Limit      int
+	Offset     int
+}
+
+// AIInvocationFilter for listing AI invocations, newest first, or totalling
+// them; zero times are unbounded
+type AIInvocationFilter struct {
+	UserID   string
+	Feature  string
+	Provider string
+	Outcome  string
+	Since    time.Time
+	Until    time.Time
+	After    *Cursor // created_at and ID to continue after; replaces Offset
+	Limit    int
+	Offset   int
+}
+
+// WebhookDeliveryFilter for listing webhook deliveries, newest first
+type WebhookDeliveryFilter struct {
+	WebhookID string
+	Status    string
+	After     *Cursor // created_at and ID to continue after; replaces Offset
+	Limit     int
+	Offset    int
+}
+
+// EnergyCheckInFilter for listing energy check-ins; zero times are unbounded
+type EnergyCheckInFilter struct {
+	UserID string
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+}
+
+// LeftoverFilter for listing leftovers
+type LeftoverFilter struct {
+	UserID          string
+	IncludeFinished bool
+	EatByBefore     *time.Time
+	Storage         string // fridge or freezer; empty for both
+}
+
+// GroceryPriceFilter for listing grocery prices
+type GroceryPriceFilter struct {
+	UserID        string
+	Store         string
+	PurchasedOnly bool
+	Since         time.Time // zero for no lower bound
+	Until         time.Time // zero for no upper bound
+	Limit         int
+}
+
+// CookingSessionFilter for listing cooking sessions
+type CookingSessionFilter struct {
+	UserID          string
+	RecipeID        string
+	IncludeFinished bool
+}
+
+// CookingTimerFilter for listing cooking timers
+type CookingTimerFilter struct {
+	UserID           string
+	SessionID        string // every session, and standalone timers, when empty
+	IncludeCancelled bool
+	EndsAfter        *time.Time // leaves out running timers that ended by then
+}
+
+// HashInvitationCode returns the stored form of a household invitation code;
+// codes are case-insensitive
+func HashInvitationCode(code string) string {
+	sum := sha256.Sum256([]byte(strings.ToUpper(strings.TrimSpace(code))))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,334 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package migrate applies versioned schema migrations with golang-migrate.
+// Each database engine embeds its own migration files, NNN_name.up.sql with
+// NNN_name.down.sql to revert it, and provides a Driver that hands the
+// library its connection.
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+var (
+	// ErrSchemaAhead means the database has migrations this build does not
+	// know, i.e. it was migrated by a newer version of the server
+	ErrSchemaAhead = errors.New("database schema is newer than this server")
+
+	// ErrPending means migrations have not been applied yet
+	ErrPending = errors.New("database schema has pending migrations")
+
+	// ErrUntracked means the database has tables but no migration history
+	ErrUntracked = errors.New("database has tables but no migration history")
+
+	// ErrDirty means a migration failed part way, leaving the schema
+	// between versions
+	ErrDirty = errors.New("database schema is dirty")
+)
+
+// Migration is one numbered schema change
+type Migration struct {
+	Version int
+	Name    string // e.g. leftovers for 014_leftovers.up.sql
+}
+
+// Status is the state of one migration
+type Status struct {
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+	Applied bool   `json:"applied"`
+	Unknown bool   `json:"unknown,omitempty"` // recorded in the database but not part of this build
+}
+
+// Driver gives golang-migrate access to one database engine
+type Driver interface {
+	// HasTables reports whether the application's tables exist
+	HasTables(ctx context.Context) (bool, error)
+	// Open returns the library's driver for the application's database and
+	// a function that releases it without closing the application's
+	// connections
+	Open(ctx context.Context) (database.Driver, func() error, error)
+}
+
+// Source is an engine's migration files
+type Source struct {
+	fsys       fs.FS
+	dir        string
+	migrations []Migration
+}
+
+// MustLoad reads the migrations in dir of fsys, usually an embedded
+// directory, and panics when they can't be read as that is a build mistake
+func MustLoad(fsys fs.FS, dir string) *Source {
+	src := &Source{fsys: fsys, dir: dir}
+	files, err := src.open()
+	if err != nil {
+		panic(fmt.Sprintf("migrate: %v", err))
+	}
+	defer files.Close()
+
+	version, err := files.First()
+	for err == nil {
+		up, name, readErr := files.ReadUp(version)
+		if readErr != nil {
+			panic(fmt.Sprintf("migrate: version %d has no up migration: %v", version, readErr))
+		}
+		up.Close()
+		src.migrations = append(src.migrations, Migration{Version: int(version), Name: name})
+		version, err = files.Next(version)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		panic(fmt.Sprintf("migrate: %v", err))
+	}
+	return src
+}
+
+func (s *Source) open() (source.Driver, error) {
+	return iofs.New(s.fsys, s.dir)
+}
+
+// Migrator moves a database between schema versions
+type Migrator struct {
+	driver Driver
+	source *Source
+}
+
+// New creates a migrator for the migrations shipped with this build
+func New(driver Driver, source *Source) *Migrator {
+	return &Migrator{driver: driver, source: source}
+}
+
+// Latest returns the newest version this build knows
+func (m *Migrator) Latest() int {
+	if len(m.source.migrations) == 0 {
+		return 0
+	}
+	return m.source.migrations[len(m.source.migrations)-1].Version
+}
+
+// Version returns the newest applied version, or 0 when none is
+func (m *Migrator) Version(ctx context.Context) (int, error) {
+	var version int
+	err := m.run(ctx, func(lib *migrate.Migrate) error {
+		var err error
+		version, err = current(lib)
+		return err
+	})
+	return version, err
+}
+
+// Status lists every known migration, plus the applied version when this
+// build does not know it, in version order
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	version, err := m.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.source.migrations)+1)
+	for _, mig := range m.source.migrations {
+		statuses = append(statuses, Status{Version: mig.Version, Name: mig.Name, Applied: mig.Version <= version})
+	}
+	if version != 0 && !m.known(version) {
+		statuses = append(statuses, Status{Version: version, Applied: true, Unknown: true})
+	}
+	return statuses, nil
+}
+
+// Check reports ErrSchemaAhead, ErrDirty or ErrPending when the database
+// does not match this build
+func (m *Migrator) Check(ctx context.Context) error {
+	version, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	if err := m.checkKnown(version); err != nil {
+		return err
+	}
+	pending := 0
+	for _, mig := range m.source.migrations {
+		if mig.Version > version {
+			pending++
+		}
+	}
+	if pending > 0 {
+		return fmt.Errorf("%w: %d to apply", ErrPending, pending)
+	}
+	return nil
+}
+
+// Up applies every pending migration
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.To(ctx, m.Latest())
+}
+
+// Down reverts the n most recently applied migrations
+func (m *Migrator) Down(ctx context.Context, n int) error {
+	if n <= 0 {
+		return fmt.Errorf("invalid number of migrations to revert: %d", n)
+	}
+	version, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	if err := m.checkKnown(version); err != nil {
+		return err
+	}
+
+	target := 0
+	for i := len(m.source.migrations) - 1; i >= 0; i-- {
+		if v := m.source.migrations[i].Version; v <= version {
+			if n == 0 {
+				target = v
+				break
+			}
+			n--
+		}
+	}
+	return m.To(ctx, target)
+}
+
+// To applies or reverts migrations until version is the newest applied; 0
+// reverts everything
+func (m *Migrator) To(ctx context.Context, version int) error {
+	if version != 0 && !m.known(version) {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+	return m.run(ctx, func(lib *migrate.Migrate) error {
+		applied, err := current(lib)
+		if err != nil {
+			return err
+		}
+		if err := m.checkKnown(applied); err != nil {
+			return err
+		}
+		if applied == 0 {
+			// Tables without a history were created by hand; applying the
+			// first migration over them would fail half way
+			tables, err := m.driver.HasTables(ctx)
+			if err != nil {
+				return err
+			}
+			if tables {
+				return fmt.Errorf("%w; record the migrations already applied with `migrate force <version>`", ErrUntracked)
+			}
+		}
+
+		if version == 0 {
+			err = lib.Down()
+		} else {
+			err = lib.Migrate(uint(version))
+		}
+		if errors.Is(err, migrate.ErrNoChange) {
+			return nil
+		}
+		return err
+	})
+}
+
+// Force records version as the newest applied without running anything,
+// for databases whose schema was created by hand or repaired after a
+// failed migration
+func (m *Migrator) Force(ctx context.Context, version int) error {
+	if version != 0 && !m.known(version) {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+	return m.run(ctx, func(lib *migrate.Migrate) error {
+		if version == 0 {
+			return lib.Force(database.NilVersion)
+		}
+		return lib.Force(version)
+	})
+}
+
+// run opens the library on the database for one operation
+func (m *Migrator) run(ctx context.Context, fn func(lib *migrate.Migrate) error) error {
+	files, err := m.source.open()
+	if err != nil {
+		return err
+	}
+	driver, release, err := m.driver.Open(ctx)
+	if err != nil {
+		files.Close()
+		return err
+	}
+	// Closing the library would close the application's database too, so
+	// the source and driver are released separately
+	defer release()
+	defer files.Close()
+
+	lib, err := migrate.NewWithInstance("iofs", files, "database", driver)
+	if err != nil {
+		return err
+	}
+	lib.Log = migrationLog{}
+	return fn(lib)
+}
+
+// current returns the applied version, 0 when none is, or ErrDirty
+func current(lib *migrate.Migrate) (int, error) {
+	version, dirty, err := lib.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if dirty {
+		return 0, fmt.Errorf("%w: migration %d failed part way; repair the schema and record the version it matches with `migrate force <version>`", ErrDirty, version)
+	}
+	return int(version), nil
+}
+
+// checkKnown reports ErrSchemaAhead when the applied version is not part
+// of this build
+func (m *Migrator) checkKnown(version int) error {
+	if version != 0 && !m.known(version) {
+		return fmt.Errorf("%w: version %d is not part of this build", ErrSchemaAhead, version)
+	}
+	return nil
+}
+
+func (m *Migrator) known(version int) bool {
+	for _, mig := range m.source.migrations {
+		if mig.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// migrationLog writes the library's progress to the application log
+type migrationLog struct{}
+
+func (migrationLog) Printf(format string, v ...any) {
+	logger.Get().Info().Str("migration", strings.TrimSpace(fmt.Sprintf(format, v...))).Msg("Ran migration")
+}
+
+func (migrationLog) Verbose() bool { return false }
@@ -0,0 +1,91 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Account deletion operations
+
+// ScheduleAccountDeletion records a pending deletion, replacing any earlier
+// request from the same user
+func (db *PostgresDB) ScheduleAccountDeletion(ctx context.Context, deletion *database.AccountDeletion) error {
+	query := `
+		INSERT INTO account_deletions (user_id, requested_at, delete_after)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET requested_at = EXCLUDED.requested_at, delete_after = EXCLUDED.delete_after
+	`
+	_, err := db.conn(ctx).Exec(ctx, query, deletion.UserID, deletion.RequestedAt, deletion.DeleteAfter)
+	return err
+}
+
+// GetAccountDeletion retrieves a user's pending deletion
+func (db *PostgresDB) GetAccountDeletion(ctx context.Context, userID string) (*database.AccountDeletion, error) {
+	query := `
+		SELECT user_id, requested_at, delete_after
+		FROM account_deletions WHERE user_id = $1
+	`
+	var deletion database.AccountDeletion
+	err := db.conn(ctx).QueryRow(ctx, query, userID).Scan(
+		&deletion.UserID, &deletion.RequestedAt, &deletion.DeleteAfter,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+// CancelAccountDeletion removes a user's pending deletion
+func (db *PostgresDB) CancelAccountDeletion(ctx context.Context, userID string) error {
+	query := `DELETE FROM account_deletions WHERE user_id = $1`
+	_, err := db.conn(ctx).Exec(ctx, query, userID)
+	return err
+}
+
+// ListDueAccountDeletions lists deletions whose grace period ended before
+// the cutoff, oldest first
+func (db *PostgresDB) ListDueAccountDeletions(ctx context.Context, before time.Time, limit int) ([]*database.AccountDeletion, error) {
+	query := `
+		SELECT user_id, requested_at, delete_after
+		FROM account_deletions
+		WHERE delete_after < $1
+		ORDER BY delete_after
+		LIMIT $2
+	`
+	rows, err := db.conn(ctx).Query(ctx, query, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deletions := []*database.AccountDeletion{}
+	for rows.Next() {
+		var deletion database.AccountDeletion
+		if err := rows.Scan(&deletion.UserID, &deletion.RequestedAt, &deletion.DeleteAfter); err != nil {
+			return nil, err
+		}
+		deletions = append(deletions, &deletion)
+	}
+	return deletions, rows.Err()
+}
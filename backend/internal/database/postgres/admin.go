@@ -0,0 +1,125 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Instance administration operations
+
+// ListUsers lists users, optionally matching a search query
+func (db *PostgresDB) ListUsers(ctx context.Context, filter database.UserFilter) ([]*database.User, error) {
+	query := `
+		SELECT id, email, password_hash, COALESCE(first_name, ''), COALESCE(last_name, ''),
+		       created_at, updated_at, last_login_at, email_verified, active, is_admin, must_change_password
+		FROM users
+	`
+	args := []interface{}{}
+	argPos := 1
+
+	if filter.Query != "" {
+		query += fmt.Sprintf(" WHERE email ILIKE $%d OR first_name ILIKE $%d OR last_name ILIKE $%d", argPos, argPos, argPos)
+		args = append(args, "%"+filter.Query+"%")
+		argPos++
+	}
+
+	query += " ORDER BY created_at"
+
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argPos)
+		args = append(args, filter.Limit)
+		argPos++
+	}
+	if filter.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET $%d", argPos)
+		args = append(args, filter.Offset)
+	}
+
+	rows, err := db.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*database.User{}
+	for rows.Next() {
+		var user database.User
+		if err := rows.Scan(
+			&user.ID, &user.Email, &user.PasswordHash, &user.FirstName, &user.LastName,
+			&user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt, &user.EmailVerified, &user.Active, &user.IsAdmin, &user.MustChangePassword,
+		); err != nil {
+			return nil, err
+		}
+		users = append(users, &user)
+	}
+	return users, rows.Err()
+}
+
+// CountUsers counts all users
+func (db *PostgresDB) CountUsers(ctx context.Context) (int, error) {
+	var count int
+	err := db.conn(ctx).QueryRow(ctx, `SELECT COUNT(*) FROM users`).Scan(&count)
+	return count, err
+}
+
+// GetInstanceStats counts the main records on the instance
+func (db *PostgresDB) GetInstanceStats(ctx context.Context) (*database.InstanceStats, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM users),
+			(SELECT COUNT(*) FROM users WHERE active),
+			(SELECT COUNT(*) FROM users WHERE is_admin),
+			(SELECT COUNT(*) FROM recipes),
+			(SELECT COUNT(*) FROM meal_plans),
+			(SELECT COUNT(*) FROM households),
+			(SELECT COUNT(*) FROM nutrition_logs)
+	`
+	var stats database.InstanceStats
+	err := db.conn(ctx).QueryRow(ctx, query).Scan(
+		&stats.Users, &stats.ActiveUsers, &stats.Admins, &stats.Recipes,
+		&stats.MealPlans, &stats.Households, &stats.NutritionLogs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// GetInstanceSetting retrieves an instance setting
+func (db *PostgresDB) GetInstanceSetting(ctx context.Context, key string) (string, error) {
+	var value string
+	err := db.conn(ctx).QueryRow(ctx, `SELECT value FROM instance_settings WHERE key = $1`, key).Scan(&value)
+	return value, err
+}
+
+// SetInstanceSetting creates or updates an instance setting
+func (db *PostgresDB) SetInstanceSetting(ctx context.Context, key, value string) error {
+	query := `
+		INSERT INTO instance_settings (key, value, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, updated_at = EXCLUDED.updated_at
+	`
+	_, err := db.conn(ctx).Exec(ctx, query, key, value, time.Now())
+	return err
+}
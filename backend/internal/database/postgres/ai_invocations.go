@@ -0,0 +1,165 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// AI invocation operations
+
+// RecordAIInvocation records one call to an AI provider
+func (db *PostgresDB) RecordAIInvocation(ctx context.Context, invocation *database.AIInvocation) error {
+	query := `
+		INSERT INTO ai_invocations (id, user_id, feature, provider, model, input_tokens, output_tokens,
+		                            latency_ms, cost, outcome, error, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+	_, err := db.conn(ctx).Exec(ctx, query,
+		invocation.ID, invocation.UserID, invocation.Feature, invocation.Provider, invocation.Model,
+		invocation.InputTokens, invocation.OutputTokens, invocation.LatencyMS, invocation.Cost,
+		invocation.Outcome, invocation.Error, invocation.CreatedAt,
+	)
+	return err
+}
+
+// ListAIInvocations lists AI invocations, newest first
+func (db *PostgresDB) ListAIInvocations(ctx context.Context, filter database.AIInvocationFilter) ([]*database.AIInvocation, error) {
+	where, args := aiInvocationConditions(filter, 1)
+	query := `
+		SELECT id, user_id, feature, provider, model, input_tokens, output_tokens, latency_ms, cost,
+		       outcome, error, created_at
+		FROM ai_invocations
+		WHERE ` + where
+	argPos := len(args) + 1
+
+	if filter.After != nil {
+		query += fmt.Sprintf(" AND (created_at, id) < ($%d, $%d)", argPos, argPos+1)
+		args = append(args, filter.After.Time, filter.After.ID)
+		argPos += 2
+	}
+
+	query += " ORDER BY created_at DESC, id DESC"
+
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argPos)
+		args = append(args, filter.Limit)
+		argPos++
+	}
+	if filter.Offset > 0 && filter.After == nil {
+		query += fmt.Sprintf(" OFFSET $%d", argPos)
+		args = append(args, filter.Offset)
+	}
+
+	rows, err := db.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invocations := []*database.AIInvocation{}
+	for rows.Next() {
+		var invocation database.AIInvocation
+		if err := rows.Scan(
+			&invocation.ID, &invocation.UserID, &invocation.Feature, &invocation.Provider, &invocation.Model,
+			&invocation.InputTokens, &invocation.OutputTokens, &invocation.LatencyMS, &invocation.Cost,
+			&invocation.Outcome, &invocation.Error, &invocation.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		invocations = append(invocations, &invocation)
+	}
+	return invocations, rows.Err()
+}
+
+// SumAIInvocations totals the AI invocations matching a filter by feature
+// and provider, ignoring its paging
+func (db *PostgresDB) SumAIInvocations(ctx context.Context, filter database.AIInvocationFilter) ([]*database.AIFeatureUsage, error) {
+	where, args := aiInvocationConditions(filter, 2)
+	query := `
+		SELECT feature, provider, COUNT(*), COUNT(*) FILTER (WHERE outcome <> $1),
+		       COALESCE(SUM(input_tokens), 0), COALESCE(SUM(output_tokens), 0), COALESCE(SUM(cost), 0),
+		       COALESCE(AVG(latency_ms), 0)::BIGINT
+		FROM ai_invocations
+		WHERE ` + where + `
+		GROUP BY feature, provider
+		ORDER BY feature, provider
+	`
+	rows, err := db.conn(ctx).Query(ctx, query, append([]interface{}{database.AIInvocationSucceeded}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usage := []*database.AIFeatureUsage{}
+	for rows.Next() {
+		var u database.AIFeatureUsage
+		if err := rows.Scan(
+			&u.Feature, &u.Provider, &u.Requests, &u.Failures,
+			&u.InputTokens, &u.OutputTokens, &u.Cost, &u.AvgLatencyMS,
+		); err != nil {
+			return nil, err
+		}
+		usage = append(usage, &u)
+	}
+	return usage, rows.Err()
+}
+
+// aiInvocationConditions builds the WHERE clause shared by listing and
+// totalling, numbering its parameters from first
+func aiInvocationConditions(filter database.AIInvocationFilter, first int) (string, []interface{}) {
+	where := "1=1"
+	args := []interface{}{}
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		where += fmt.Sprintf(" AND "+condition, first+len(args)-1)
+	}
+	if filter.UserID != "" {
+		add("user_id = $%d", filter.UserID)
+	}
+	if filter.Feature != "" {
+		add("feature = $%d", filter.Feature)
+	}
+	if filter.Provider != "" {
+		add("provider = $%d", filter.Provider)
+	}
+	if filter.Outcome != "" {
+		add("outcome = $%d", filter.Outcome)
+	}
+	if !filter.Since.IsZero() {
+		add("created_at >= $%d", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		add("created_at < $%d", filter.Until)
+	}
+	return where, args
+}
+
+// PurgeAIInvocations removes AI invocations recorded before a cutoff
+func (db *PostgresDB) PurgeAIInvocations(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := db.conn(ctx).Exec(ctx, `DELETE FROM ai_invocations WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
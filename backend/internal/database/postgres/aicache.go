@@ -0,0 +1,130 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// AI output cache operations
+
+// GetAICacheEntry retrieves a cache entry by key, whether or not it has
+// expired, marking it stale when its recipe was updated since it was stored
+func (db *PostgresDB) GetAICacheEntry(ctx context.Context, key string) (*database.AICacheEntry, error) {
+	query := `
+		SELECT c.cache_key, c.kind, c.recipe_id, c.payload, c.hits, c.created_at, c.expires_at,
+		       c.recipe_id IS NOT NULL AND r.updated_at IS DISTINCT FROM c.recipe_updated_at
+		FROM ai_cache c
+		LEFT JOIN recipes r ON r.id = c.recipe_id
+		WHERE c.cache_key = $1
+	`
+	var entry database.AICacheEntry
+	err := db.conn(ctx).QueryRow(ctx, query, key).Scan(
+		&entry.Key, &entry.Kind, &entry.RecipeID, &entry.Payload,
+		&entry.Hits, &entry.CreatedAt, &entry.ExpiresAt, &entry.Stale,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// PutAICacheEntry stores a cache entry, replacing any previous entry and its
+// hit count. The recipe's current updated_at is recorded with it.
+func (db *PostgresDB) PutAICacheEntry(ctx context.Context, entry *database.AICacheEntry) error {
+	query := `
+		INSERT INTO ai_cache (cache_key, kind, recipe_id, recipe_updated_at, payload, hits, created_at, expires_at)
+		VALUES ($1, $2, $3, (SELECT updated_at FROM recipes WHERE id = $3), $4, 0, $5, $6)
+		ON CONFLICT (cache_key) DO UPDATE
+		SET kind = EXCLUDED.kind, recipe_id = EXCLUDED.recipe_id, recipe_updated_at = EXCLUDED.recipe_updated_at,
+		    payload = EXCLUDED.payload, hits = 0, created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at
+	`
+	_, err := db.conn(ctx).Exec(ctx, query,
+		entry.Key, entry.Kind, entry.RecipeID, []byte(entry.Payload), entry.CreatedAt, entry.ExpiresAt,
+	)
+	return err
+}
+
+// RecordAICacheHit increments an entry's hit count
+func (db *PostgresDB) RecordAICacheHit(ctx context.Context, key string) error {
+	_, err := db.conn(ctx).Exec(ctx, `UPDATE ai_cache SET hits = hits + 1 WHERE cache_key = $1`, key)
+	return err
+}
+
+// DeleteAICacheEntry removes one cache entry
+func (db *PostgresDB) DeleteAICacheEntry(ctx context.Context, key string) error {
+	_, err := db.conn(ctx).Exec(ctx, `DELETE FROM ai_cache WHERE cache_key = $1`, key)
+	return err
+}
+
+// DeleteAICacheForRecipe removes every cached output derived from a recipe
+func (db *PostgresDB) DeleteAICacheForRecipe(ctx context.Context, recipeID string) (int64, error) {
+	tag, err := db.conn(ctx).Exec(ctx, `DELETE FROM ai_cache WHERE recipe_id = $1`, recipeID)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// PurgeExpiredAICache removes entries that expired before now
+func (db *PostgresDB) PurgeExpiredAICache(ctx context.Context, now time.Time) (int64, error) {
+	tag, err := db.conn(ctx).Exec(ctx, `DELETE FROM ai_cache WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ClearAICache removes every cache entry
+func (db *PostgresDB) ClearAICache(ctx context.Context) (int64, error) {
+	tag, err := db.conn(ctx).Exec(ctx, `DELETE FROM ai_cache`)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// GetAICacheStats counts live entries and their hits per kind
+func (db *PostgresDB) GetAICacheStats(ctx context.Context, now time.Time) ([]*database.AICacheStats, error) {
+	query := `
+		SELECT kind, COUNT(*), COALESCE(SUM(hits), 0)::bigint
+		FROM ai_cache
+		WHERE expires_at > $1
+		GROUP BY kind
+		ORDER BY kind
+	`
+	rows, err := db.conn(ctx).Query(ctx, query, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []*database.AICacheStats{}
+	for rows.Next() {
+		var s database.AICacheStats
+		if err := rows.Scan(&s.Kind, &s.Entries, &s.Hits); err != nil {
+			return nil, err
+		}
+		stats = append(stats, &s)
+	}
+	return stats, rows.Err()
+}
@@ -0,0 +1,105 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// AI usage operations
+
+// RecordAIUsage adds a usage delta to the user's monthly totals
+func (db *PostgresDB) RecordAIUsage(ctx context.Context, usage *database.AIUsage) error {
+	query := `
+		INSERT INTO ai_usage (user_id, provider, period, requests, input_tokens, output_tokens, cost, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id, provider, period) DO UPDATE SET
+			requests = ai_usage.requests + EXCLUDED.requests,
+			input_tokens = ai_usage.input_tokens + EXCLUDED.input_tokens,
+			output_tokens = ai_usage.output_tokens + EXCLUDED.output_tokens,
+			cost = ai_usage.cost + EXCLUDED.cost,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err := db.conn(ctx).Exec(ctx, query,
+		usage.UserID, usage.Provider, usage.Period, usage.Requests,
+		usage.InputTokens, usage.OutputTokens, usage.Cost, usage.UpdatedAt,
+	)
+	return err
+}
+
+// ListAIUsage lists a user's usage per provider for a month
+func (db *PostgresDB) ListAIUsage(ctx context.Context, userID, period string) ([]*database.AIUsage, error) {
+	query := `
+		SELECT user_id, provider, period, requests, input_tokens, output_tokens, cost::float8, updated_at
+		FROM ai_usage
+		WHERE user_id = $1 AND period = $2
+		ORDER BY provider
+	`
+	rows, err := db.conn(ctx).Query(ctx, query, userID, period)
+	if err != nil {
+		return nil, err
+	}
+	return scanAIUsageRows(rows)
+}
+
+// GetInstanceAIUsage totals usage per provider across all users for a month
+func (db *PostgresDB) GetInstanceAIUsage(ctx context.Context, period string) ([]*database.AIUsage, error) {
+	query := `
+		SELECT provider, SUM(requests)::bigint, SUM(input_tokens)::bigint, SUM(output_tokens)::bigint, SUM(cost)::float8
+		FROM ai_usage
+		WHERE period = $1
+		GROUP BY provider
+		ORDER BY provider
+	`
+	rows, err := db.conn(ctx).Query(ctx, query, period)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usage := []*database.AIUsage{}
+	for rows.Next() {
+		u := database.AIUsage{Period: period}
+		if err := rows.Scan(&u.Provider, &u.Requests, &u.InputTokens, &u.OutputTokens, &u.Cost); err != nil {
+			return nil, err
+		}
+		usage = append(usage, &u)
+	}
+	return usage, rows.Err()
+}
+
+func scanAIUsageRows(rows pgx.Rows) ([]*database.AIUsage, error) {
+	defer rows.Close()
+
+	usage := []*database.AIUsage{}
+	for rows.Next() {
+		var u database.AIUsage
+		if err := rows.Scan(
+			&u.UserID, &u.Provider, &u.Period, &u.Requests,
+			&u.InputTokens, &u.OutputTokens, &u.Cost, &u.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		usage = append(usage, &u)
+	}
+	return usage, rows.Err()
+}
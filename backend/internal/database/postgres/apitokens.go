@@ -0,0 +1,93 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// API token operations
+
+const apiTokenColumns = `id, user_id, name, token_hash, prefix, scope, created_at, last_used_at, expires_at`
+
+func scanAPIToken(row interface{ Scan(dest ...any) error }) (*database.APIToken, error) {
+	var token database.APIToken
+	err := row.Scan(
+		&token.ID, &token.UserID, &token.Name, &token.TokenHash, &token.Prefix, &token.Scope,
+		&token.CreatedAt, &token.LastUsedAt, &token.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// CreateAPIToken creates a new API token
+func (db *PostgresDB) CreateAPIToken(ctx context.Context, token *database.APIToken) error {
+	query := `
+		INSERT INTO api_tokens (id, user_id, name, token_hash, prefix, scope, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := db.conn(ctx).Exec(ctx, query,
+		token.ID, token.UserID, token.Name, token.TokenHash, token.Prefix, token.Scope,
+		token.CreatedAt, token.ExpiresAt,
+	)
+	return err
+}
+
+// GetAPITokenByHash retrieves an API token by the hash of its secret
+func (db *PostgresDB) GetAPITokenByHash(ctx context.Context, tokenHash string) (*database.APIToken, error) {
+	query := `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE token_hash = $1`
+	return scanAPIToken(db.conn(ctx).QueryRow(ctx, query, tokenHash))
+}
+
+// ListAPITokens lists a user's API tokens
+func (db *PostgresDB) ListAPITokens(ctx context.Context, userID string) ([]*database.APIToken, error) {
+	query := `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE user_id = $1 ORDER BY created_at DESC`
+	rows, err := db.conn(ctx).Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []*database.APIToken{}
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
+
+// TouchAPIToken records when an API token was last used
+func (db *PostgresDB) TouchAPIToken(ctx context.Context, id string, usedAt time.Time) error {
+	_, err := db.conn(ctx).Exec(ctx, `UPDATE api_tokens SET last_used_at = $2 WHERE id = $1`, id, usedAt)
+	return err
+}
+
+// DeleteAPIToken deletes an API token
+func (db *PostgresDB) DeleteAPIToken(ctx context.Context, id string) error {
+	_, err := db.conn(ctx).Exec(ctx, `DELETE FROM api_tokens WHERE id = $1`, id)
+	return err
+}
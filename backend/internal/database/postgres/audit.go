@@ -0,0 +1,159 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Audit log operations
+
+// CreateAuditEvent records an audit event
+func (db *PostgresDB) CreateAuditEvent(ctx context.Context, event *database.AuditEvent) error {
+	var details []byte
+	if len(event.Details) > 0 {
+		var err error
+		details, err = json.Marshal(event.Details)
+		if err != nil {
+			return err
+		}
+	}
+	query := `
+		INSERT INTO audit_events (id, user_id, action, target_type, target_id, details, ip_address, user_agent, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err := db.conn(ctx).Exec(ctx, query,
+		event.ID, event.UserID, event.Action, event.TargetType, event.TargetID, details,
+		event.IPAddress, event.UserAgent, event.CreatedAt,
+	)
+	return err
+}
+
+// ListAuditEvents lists audit events, newest first
+func (db *PostgresDB) ListAuditEvents(ctx context.Context, filter database.AuditEventFilter) ([]*database.AuditEvent, error) {
+	where, args := auditEventConditions(filter)
+	query := `
+		SELECT id, user_id, action, COALESCE(target_type, ''), COALESCE(target_id, ''), details,
+		       COALESCE(ip_address, ''), COALESCE(user_agent, ''), created_at
+		FROM audit_events
+		WHERE ` + where
+	argPos := len(args) + 1
+
+	if filter.After != nil {
+		query += fmt.Sprintf(" AND (created_at, id) < ($%d, $%d)", argPos, argPos+1)
+		args = append(args, filter.After.Time, filter.After.ID)
+		argPos += 2
+	}
+
+	query += " ORDER BY created_at DESC, id DESC"
+
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argPos)
+		args = append(args, filter.Limit)
+		argPos++
+	}
+	if filter.Offset > 0 && filter.After == nil {
+		query += fmt.Sprintf(" OFFSET $%d", argPos)
+		args = append(args, filter.Offset)
+	}
+
+	rows, err := db.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []*database.AuditEvent{}
+	for rows.Next() {
+		var event database.AuditEvent
+		var details []byte
+		if err := rows.Scan(
+			&event.ID, &event.UserID, &event.Action, &event.TargetType, &event.TargetID, &details,
+			&event.IPAddress, &event.UserAgent, &event.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if len(details) > 0 {
+			if err := json.Unmarshal(details, &event.Details); err != nil {
+				return nil, fmt.Errorf("failed to decode audit event details: %w", err)
+			}
+		}
+		events = append(events, &event)
+	}
+	return events, rows.Err()
+}
+
+// CountAuditEvents counts the audit events matching a filter, ignoring its
+// paging
+func (db *PostgresDB) CountAuditEvents(ctx context.Context, filter database.AuditEventFilter) (int, error) {
+	where, args := auditEventConditions(filter)
+	var count int
+	err := db.conn(ctx).QueryRow(ctx, `SELECT COUNT(*) FROM audit_events WHERE `+where, args...).Scan(&count)
+	return count, err
+}
+
+// auditEventConditions builds the WHERE clause shared by listing and
+// counting
+func auditEventConditions(filter database.AuditEventFilter) (string, []interface{}) {
+	where := "1=1"
+	args := []interface{}{}
+	if filter.UserID != "" {
+		args = append(args, filter.UserID)
+		where += fmt.Sprintf(" AND user_id = $%d", len(args))
+	}
+	if strings.HasSuffix(filter.Action, ".") {
+		args = append(args, filter.Action+"%")
+		where += fmt.Sprintf(" AND action LIKE $%d", len(args))
+	} else if filter.Action != "" {
+		args = append(args, filter.Action)
+		where += fmt.Sprintf(" AND action = $%d", len(args))
+	}
+	if filter.TargetType != "" {
+		args = append(args, filter.TargetType)
+		where += fmt.Sprintf(" AND target_type = $%d", len(args))
+	}
+	if filter.TargetID != "" {
+		args = append(args, filter.TargetID)
+		where += fmt.Sprintf(" AND target_id = $%d", len(args))
+	}
+	if !filter.Since.IsZero() {
+		args = append(args, filter.Since)
+		where += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+	if !filter.Until.IsZero() {
+		args = append(args, filter.Until)
+		where += fmt.Sprintf(" AND created_at < $%d", len(args))
+	}
+	return where, args
+}
+
+// PurgeAuditEvents removes audit events recorded before a cutoff
+func (db *PostgresDB) PurgeAuditEvents(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := db.conn(ctx).Exec(ctx, `DELETE FROM audit_events WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,256 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// loadBatchSize is how many rows LoadTables inserts per statement
+const loadBatchSize = 500
+
+// DumpTables streams every table except the migration history and derived
+// tables as JSON rows
+func (db *PostgresDB) DumpTables(ctx context.Context, dump database.TableDump) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		// One snapshot for every table, without blocking writers
+		if _, err := db.conn(ctx).Exec(ctx, `SET TRANSACTION ISOLATION LEVEL REPEATABLE READ, READ ONLY`); err != nil {
+			return err
+		}
+
+		tables, err := db.listTables(ctx)
+		if err != nil {
+			return err
+		}
+		for _, table := range tables {
+			if database.DerivedTables[table] {
+				continue
+			}
+			if err := dump.BeginTable(table); err != nil {
+				return err
+			}
+			if err := db.dumpRows(ctx, dump, `SELECT row_to_json(t)::text FROM `+pgx.Identifier{table}.Sanitize()+` t`); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DumpUserData streams a user's rows from each of database.UserDataTables,
+// without their credential columns
+func (db *PostgresDB) DumpUserData(ctx context.Context, userID string, dump database.TableDump) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if _, err := db.conn(ctx).Exec(ctx, `SET TRANSACTION ISOLATION LEVEL REPEATABLE READ, READ ONLY`); err != nil {
+			return err
+		}
+
+		for _, t := range database.UserDataTables {
+			if err := dump.BeginTable(t.Table); err != nil {
+				return err
+			}
+			query := `SELECT (to_jsonb(t) - $2::text[])::text FROM ` + pgx.Identifier{t.Table}.Sanitize() + ` t
+				WHERE ` + strings.ReplaceAll(t.Where, ":user", "$1")
+			// A nil slice would be sent as NULL, and subtracting NULL empties the row
+			omit := append([]string{}, t.Omit...)
+			if err := db.dumpRows(ctx, dump, query, userID, omit); err != nil {
+				return fmt.Errorf("%s: %w", t.Table, err)
+			}
+		}
+		return nil
+	})
+}
+
+// LoadTables empties every table except the migration history and inserts
+// the source's rows, parents before the tables referencing them
+func (db *PostgresDB) LoadTables(ctx context.Context, source database.TableSource) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		tables, err := db.listTables(ctx)
+		if err != nil {
+			return err
+		}
+		if err := checkSourceTables(tables, source); err != nil {
+			return err
+		}
+		order, err := db.loadOrder(ctx, tables)
+		if err != nil {
+			return err
+		}
+
+		// Truncating every table at once is allowed despite the foreign keys
+		// between them
+		idents := make([]string, len(tables))
+		for i, table := range tables {
+			idents[i] = pgx.Identifier{table}.Sanitize()
+		}
+		if _, err := db.conn(ctx).Exec(ctx, `TRUNCATE `+strings.Join(idents, ", ")); err != nil {
+			return err
+		}
+
+		for _, table := range order {
+			if database.DerivedTables[table] {
+				continue
+			}
+			if err := db.loadTable(ctx, table, source); err != nil {
+				return fmt.Errorf("%s: %w", table, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (db *PostgresDB) listTables(ctx context.Context) ([]string, error) {
+	rows, err := db.conn(ctx).Query(ctx, `
+		SELECT tablename FROM pg_tables
+		WHERE schemaname = current_schema() AND tablename <> 'schema_migrations'
+		ORDER BY tablename
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// dumpRows passes each row of a query selecting one JSON text column to dump
+func (db *PostgresDB) dumpRows(ctx context.Context, dump database.TableDump, query string, args ...any) error {
+	rows, err := db.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row string
+		if err := rows.Scan(&row); err != nil {
+			return err
+		}
+		if err := dump.Row(json.RawMessage(row)); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// loadOrder sorts tables so each comes after the tables it references
+func (db *PostgresDB) loadOrder(ctx context.Context, tables []string) ([]string, error) {
+	rows, err := db.conn(ctx).Query(ctx, `
+		SELECT child.relname, parent.relname
+		FROM pg_constraint c
+		JOIN pg_class child ON child.oid = c.conrelid
+		JOIN pg_class parent ON parent.oid = c.confrelid
+		JOIN pg_namespace n ON n.oid = child.relnamespace
+		WHERE c.contype = 'f' AND n.nspname = current_schema() AND c.conrelid <> c.confrelid
+	`)
+	if err != nil {
+		return nil, err
+	}
+	references := make(map[string][]string)
+	for rows.Next() {
+		var child, parent string
+		if err := rows.Scan(&child, &parent); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		references[child] = append(references[child], parent)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	order := make([]string, 0, len(tables))
+	state := make(map[string]int) // 1 visiting, 2 done
+	var visit func(table string) error
+	visit = func(table string) error {
+		switch state[table] {
+		case 1:
+			return fmt.Errorf("foreign keys form a cycle through %s", table)
+		case 2:
+			return nil
+		}
+		state[table] = 1
+		for _, parent := range references[table] {
+			if err := visit(parent); err != nil {
+				return err
+			}
+		}
+		state[table] = 2
+		order = append(order, table)
+		return nil
+	}
+	for _, table := range tables {
+		if err := visit(table); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// loadTable inserts a table's rows in batches, letting PostgreSQL convert
+// each JSON object to the table's row type
+func (db *PostgresDB) loadTable(ctx context.Context, table string, source database.TableSource) error {
+	ident := pgx.Identifier{table}.Sanitize()
+	insert := `INSERT INTO ` + ident + ` SELECT * FROM json_populate_recordset(NULL::` + ident + `, $1::json)`
+
+	batch := make([]json.RawMessage, 0, loadBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		data, err := json.Marshal(batch)
+		if err != nil {
+			return err
+		}
+		batch = batch[:0]
+		_, err = db.conn(ctx).Exec(ctx, insert, string(data))
+		return err
+	}
+
+	err := source.Rows(table, func(row json.RawMessage) error {
+		batch = append(batch, row)
+		if len(batch) == loadBatchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return flush()
+}
+
+// checkSourceTables rejects rows for tables the schema does not have
+func checkSourceTables(tables []string, source database.TableSource) error {
+	known := make(map[string]bool, len(tables))
+	for _, table := range tables {
+		known[table] = true
+	}
+	for _, table := range source.Tables() {
+		if !known[table] {
+			return fmt.Errorf("table %s does not exist in this schema", table)
+		}
+	}
+	return nil
+}
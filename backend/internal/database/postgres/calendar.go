@@ -0,0 +1,92 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Calendar feed operations
+
+const calendarFeedColumns = `id, user_id, name, token_hash, prefix, created_at, last_used_at`
+
+func scanCalendarFeed(row interface{ Scan(dest ...any) error }) (*database.CalendarFeed, error) {
+	var feed database.CalendarFeed
+	err := row.Scan(
+		&feed.ID, &feed.UserID, &feed.Name, &feed.TokenHash, &feed.Prefix,
+		&feed.CreatedAt, &feed.LastUsedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+// CreateCalendarFeed creates a new calendar feed
+func (db *PostgresDB) CreateCalendarFeed(ctx context.Context, feed *database.CalendarFeed) error {
+	query := `
+		INSERT INTO calendar_feeds (id, user_id, name, token_hash, prefix, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := db.conn(ctx).Exec(ctx, query,
+		feed.ID, feed.UserID, feed.Name, feed.TokenHash, feed.Prefix, feed.CreatedAt,
+	)
+	return err
+}
+
+// GetCalendarFeedByHash retrieves a calendar feed by the hash of its token
+func (db *PostgresDB) GetCalendarFeedByHash(ctx context.Context, tokenHash string) (*database.CalendarFeed, error) {
+	query := `SELECT ` + calendarFeedColumns + ` FROM calendar_feeds WHERE token_hash = $1`
+	return scanCalendarFeed(db.conn(ctx).QueryRow(ctx, query, tokenHash))
+}
+
+// ListCalendarFeeds lists a user's calendar feeds
+func (db *PostgresDB) ListCalendarFeeds(ctx context.Context, userID string) ([]*database.CalendarFeed, error) {
+	query := `SELECT ` + calendarFeedColumns + ` FROM calendar_feeds WHERE user_id = $1 ORDER BY created_at DESC`
+	rows, err := db.conn(ctx).Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	feeds := []*database.CalendarFeed{}
+	for rows.Next() {
+		feed, err := scanCalendarFeed(rows)
+		if err != nil {
+			return nil, err
+		}
+		feeds = append(feeds, feed)
+	}
+	return feeds, rows.Err()
+}
+
+// TouchCalendarFeed records when a calendar feed was last fetched
+func (db *PostgresDB) TouchCalendarFeed(ctx context.Context, id string, usedAt time.Time) error {
+	_, err := db.conn(ctx).Exec(ctx, `UPDATE calendar_feeds SET last_used_at = $2 WHERE id = $1`, id, usedAt)
+	return err
+}
+
+// DeleteCalendarFeed deletes a calendar feed
+func (db *PostgresDB) DeleteCalendarFeed(ctx context.Context, id string) error {
+	_, err := db.conn(ctx).Exec(ctx, `DELETE FROM calendar_feeds WHERE id = $1`, id)
+	return err
+}
@@ -0,0 +1,318 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Cooking session operations
+
+const cookingSessionColumns = `id, user_id, recipe_id, recipe_title, status, current_step, steps,
+	started_at, paused_at, finished_at, updated_at, tasted, would_make_again, difficulty, photo_url,
+	actual_minutes, reflected_at, notes`
+
+// CreateCookingSession starts a cooking session
+func (db *PostgresDB) CreateCookingSession(ctx context.Context, session *database.CookingSession) error {
+	steps, err := json.Marshal(session.Steps)
+	if err != nil {
+		return fmt.Errorf("failed to encode cooking steps: %w", err)
+	}
+
+	query := `
+		INSERT INTO cooking_sessions (id, user_id, recipe_id, recipe_title, status, current_step, steps,
+		                              started_at, paused_at, finished_at, updated_at, notes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+	_, err = db.conn(ctx).Exec(ctx, query,
+		session.ID, session.UserID, session.RecipeID, session.RecipeTitle, session.Status, session.CurrentStep,
+		steps, session.StartedAt, session.PausedAt, session.FinishedAt, session.UpdatedAt, session.Notes,
+	)
+	return err
+}
+
+// GetCookingSessionByID retrieves a cooking session by ID
+func (db *PostgresDB) GetCookingSessionByID(ctx context.Context, id string) (*database.CookingSession, error) {
+	query := `SELECT ` + cookingSessionColumns + ` FROM cooking_sessions WHERE id = $1`
+	return scanCookingSession(db.conn(ctx).QueryRow(ctx, query, id))
+}
+
+// ListCookingSessions lists a user's cooking sessions, most recent first
+func (db *PostgresDB) ListCookingSessions(ctx context.Context, filter database.CookingSessionFilter) ([]*database.CookingSession, error) {
+	query := `SELECT ` + cookingSessionColumns + ` FROM cooking_sessions WHERE user_id = $1`
+	args := []interface{}{filter.UserID}
+
+	argPos := 2
+
+	if filter.RecipeID != "" {
+		query += fmt.Sprintf(" AND recipe_id = $%d", argPos)
+		args = append(args, filter.RecipeID)
+		argPos++
+	}
+	if !filter.IncludeFinished {
+		query += " AND finished_at IS NULL"
+	}
+
+	query += " ORDER BY updated_at DESC"
+
+	rows, err := db.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []*database.CookingSession{}
+	for rows.Next() {
+		session, err := scanCookingSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// UpdateCookingSession updates a cooking session's progress
+func (db *PostgresDB) UpdateCookingSession(ctx context.Context, session *database.CookingSession) error {
+	query := `
+		UPDATE cooking_sessions
+		SET status = $2, current_step = $3, paused_at = $4, finished_at = $5, updated_at = $6,
+		    tasted = $7, would_make_again = $8, difficulty = $9, photo_url = $10, actual_minutes = $11,
+		    reflected_at = $12, notes = $13
+		WHERE id = $1
+	`
+	args := append([]any{
+		session.ID, session.Status, session.CurrentStep, session.PausedAt, session.FinishedAt, session.UpdatedAt,
+	}, reflectionValues(session.Reflection)...)
+	_, err := db.conn(ctx).Exec(ctx, query, append(args, session.Notes)...)
+	return err
+}
+
+// reflectionValues are the reflection columns of a session, all NULL
+// until the cook reflects
+func reflectionValues(r *database.CookingReflection) []any {
+	if r == nil {
+		return []any{nil, nil, nil, nil, nil, nil}
+	}
+	return []any{r.Tasted, r.WouldMakeAgain, r.Difficulty, r.PhotoURL, r.ActualMinutes, r.ReflectedAt}
+}
+
+// RefreshRecipeRating sets a recipe's rating to the average would make
+// again score of every reflection on it, across all users. Recipes nobody
+// has scored keep their rating.
+func (db *PostgresDB) RefreshRecipeRating(ctx context.Context, recipeID string) error {
+	query := `
+		UPDATE recipes
+		SET rating = COALESCE((
+		    SELECT AVG(would_make_again) FROM cooking_sessions
+		    WHERE recipe_id = $1 AND would_make_again IS NOT NULL
+		), rating)
+		WHERE id = $1
+	`
+	_, err := db.conn(ctx).Exec(ctx, query, recipeID)
+	return err
+}
+
+// ListIdleCookingSessions lists active sessions across all users with no
+// progress since idleSince: the session has not moved on and none of its
+// timers has been touched or was still counting down since then
+func (db *PostgresDB) ListIdleCookingSessions(ctx context.Context, idleSince time.Time) ([]*database.CookingSession, error) {
+	query := `
+		SELECT ` + cookingSessionColumns + `
+		FROM cooking_sessions s
+		WHERE s.status = 'active' AND s.updated_at < $1
+		  AND NOT EXISTS (
+		      SELECT 1 FROM cooking_timers t
+		      WHERE t.session_id = s.id
+		        AND (t.updated_at >= $1 OR (t.status = 'running' AND t.ends_at > $1))
+		  )
+		ORDER BY s.updated_at
+	`
+	rows, err := db.conn(ctx).Query(ctx, query, idleSince)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []*database.CookingSession{}
+	for rows.Next() {
+		session, err := scanCookingSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// ListRecipeDifficulties averages how hard cooks across the instance found
+// each of the given recipes. Recipes nobody has rated are left out.
+func (db *PostgresDB) ListRecipeDifficulties(ctx context.Context, recipeIDs []string) (map[string]database.RecipeDifficulty, error) {
+	query := `
+		SELECT recipe_id, AVG(difficulty)::float8, COUNT(*)
+		FROM cooking_sessions
+		WHERE recipe_id = ANY($1) AND difficulty IS NOT NULL
+		GROUP BY recipe_id
+	`
+	rows, err := db.conn(ctx).Query(ctx, query, recipeIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	difficulties := map[string]database.RecipeDifficulty{}
+	for rows.Next() {
+		var recipeID string
+		var difficulty database.RecipeDifficulty
+		if err := rows.Scan(&recipeID, &difficulty.Average, &difficulty.Ratings); err != nil {
+			return nil, err
+		}
+		difficulties[recipeID] = difficulty
+	}
+	return difficulties, rows.Err()
+}
+
+func scanCookingSession(row interface{ Scan(dest ...any) error }) (*database.CookingSession, error) {
+	var session database.CookingSession
+	var steps []byte
+	var tasted, photoURL *string
+	var wouldMakeAgain, difficulty, actualMinutes *int
+	var reflectedAt *time.Time
+	err := row.Scan(
+		&session.ID, &session.UserID, &session.RecipeID, &session.RecipeTitle, &session.Status,
+		&session.CurrentStep, &steps, &session.StartedAt, &session.PausedAt, &session.FinishedAt,
+		&session.UpdatedAt, &tasted, &wouldMakeAgain, &difficulty, &photoURL, &actualMinutes,
+		&reflectedAt, &session.Notes,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if reflectedAt != nil {
+		session.Reflection = &database.CookingReflection{
+			WouldMakeAgain: wouldMakeAgain,
+			Difficulty:     difficulty,
+			ActualMinutes:  actualMinutes,
+			ReflectedAt:    *reflectedAt,
+		}
+		if tasted != nil {
+			session.Reflection.Tasted = *tasted
+		}
+		if photoURL != nil {
+			session.Reflection.PhotoURL = *photoURL
+		}
+	}
+	if err := json.Unmarshal(steps, &session.Steps); err != nil {
+		return nil, fmt.Errorf("failed to decode cooking steps: %w", err)
+	}
+	return &session, nil
+}
+
+// Cooking timer operations
+
+const cookingTimerColumns = `id, user_id, session_id, step_index, label, duration_seconds, status,
+	ends_at, remaining_seconds, created_at, updated_at`
+
+// CreateCookingTimer starts a cooking timer
+func (db *PostgresDB) CreateCookingTimer(ctx context.Context, timer *database.CookingTimer) error {
+	query := `
+		INSERT INTO cooking_timers (id, user_id, session_id, step_index, label, duration_seconds, status,
+		                            ends_at, remaining_seconds, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	_, err := db.conn(ctx).Exec(ctx, query,
+		timer.ID, timer.UserID, timer.SessionID, timer.StepIndex, timer.Label, timer.DurationSeconds,
+		timer.Status, timer.EndsAt, timer.RemainingSeconds, timer.CreatedAt, timer.UpdatedAt,
+	)
+	return err
+}
+
+// GetCookingTimerByID retrieves a cooking timer by ID
+func (db *PostgresDB) GetCookingTimerByID(ctx context.Context, id string) (*database.CookingTimer, error) {
+	query := `SELECT ` + cookingTimerColumns + ` FROM cooking_timers WHERE id = $1`
+	return scanCookingTimer(db.conn(ctx).QueryRow(ctx, query, id))
+}
+
+// ListCookingTimers lists a user's cooking timers in the order they were
+// started
+func (db *PostgresDB) ListCookingTimers(ctx context.Context, filter database.CookingTimerFilter) ([]*database.CookingTimer, error) {
+	query := `SELECT ` + cookingTimerColumns + ` FROM cooking_timers WHERE user_id = $1`
+	args := []interface{}{filter.UserID}
+	argPos := 2
+
+	if filter.SessionID != "" {
+		query += fmt.Sprintf(" AND session_id = $%d", argPos)
+		args = append(args, filter.SessionID)
+		argPos++
+	}
+	if !filter.IncludeCancelled {
+		query += " AND status <> 'cancelled'"
+	}
+	if filter.EndsAfter != nil {
+		query += fmt.Sprintf(" AND (ends_at IS NULL OR ends_at > $%d)", argPos)
+		args = append(args, *filter.EndsAfter)
+	}
+
+	query += " ORDER BY created_at"
+
+	rows, err := db.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	timers := []*database.CookingTimer{}
+	for rows.Next() {
+		timer, err := scanCookingTimer(rows)
+		if err != nil {
+			return nil, err
+		}
+		timers = append(timers, timer)
+	}
+	return timers, rows.Err()
+}
+
+// UpdateCookingTimer updates a cooking timer's state
+func (db *PostgresDB) UpdateCookingTimer(ctx context.Context, timer *database.CookingTimer) error {
+	query := `
+		UPDATE cooking_timers
+		SET label = $2, status = $3, ends_at = $4, remaining_seconds = $5, updated_at = $6
+		WHERE id = $1
+	`
+	_, err := db.conn(ctx).Exec(ctx, query,
+		timer.ID, timer.Label, timer.Status, timer.EndsAt, timer.RemainingSeconds, timer.UpdatedAt,
+	)
+	return err
+}
+
+func scanCookingTimer(row interface{ Scan(dest ...any) error }) (*database.CookingTimer, error) {
+	var timer database.CookingTimer
+	err := row.Scan(
+		&timer.ID, &timer.UserID, &timer.SessionID, &timer.StepIndex, &timer.Label, &timer.DurationSeconds,
+		&timer.Status, &timer.EndsAt, &timer.RemainingSeconds, &timer.CreatedAt, &timer.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &timer, nil
+}
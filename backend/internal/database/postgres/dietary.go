@@ -0,0 +1,92 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Dietary restriction operations
+
+// CreateDietaryRestriction records a dietary restriction for a user
+func (db *PostgresDB) CreateDietaryRestriction(ctx context.Context, restriction *database.DietaryRestriction) error {
+	query := `
+		INSERT INTO dietary_restrictions (id, user_id, kind, name, severity, notes, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := db.conn(ctx).Exec(ctx, query,
+		restriction.ID, restriction.UserID, restriction.Kind, restriction.Name,
+		restriction.Severity, restriction.Notes, restriction.CreatedAt,
+	)
+	return err
+}
+
+// GetDietaryRestrictionByID retrieves a dietary restriction by ID
+func (db *PostgresDB) GetDietaryRestrictionByID(ctx context.Context, id string) (*database.DietaryRestriction, error) {
+	query := `
+		SELECT id, user_id, kind, name, COALESCE(severity, ''), COALESCE(notes, ''), created_at
+		FROM dietary_restrictions WHERE id = $1
+	`
+	var restriction database.DietaryRestriction
+	err := db.conn(ctx).QueryRow(ctx, query, id).Scan(
+		&restriction.ID, &restriction.UserID, &restriction.Kind, &restriction.Name,
+		&restriction.Severity, &restriction.Notes, &restriction.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &restriction, nil
+}
+
+// ListDietaryRestrictions lists the dietary restrictions of the given users
+func (db *PostgresDB) ListDietaryRestrictions(ctx context.Context, userIDs []string) ([]*database.DietaryRestriction, error) {
+	query := `
+		SELECT id, user_id, kind, name, COALESCE(severity, ''), COALESCE(notes, ''), created_at
+		FROM dietary_restrictions
+		WHERE user_id = ANY($1)
+		ORDER BY user_id, kind, name
+	`
+	rows, err := db.conn(ctx).Query(ctx, query, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	restrictions := []*database.DietaryRestriction{}
+	for rows.Next() {
+		var restriction database.DietaryRestriction
+		if err := rows.Scan(
+			&restriction.ID, &restriction.UserID, &restriction.Kind, &restriction.Name,
+			&restriction.Severity, &restriction.Notes, &restriction.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		restrictions = append(restrictions, &restriction)
+	}
+	return restrictions, rows.Err()
+}
+
+// DeleteDietaryRestriction deletes a dietary restriction
+func (db *PostgresDB) DeleteDietaryRestriction(ctx context.Context, id string) error {
+	query := `DELETE FROM dietary_restrictions WHERE id = $1`
+	_, err := db.conn(ctx).Exec(ctx, query, id)
+	return err
+}
@@ -0,0 +1,56 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Email preference operations
+
+// GetEmailPreferences retrieves the email a user has chosen to receive
+func (db *PostgresDB) GetEmailPreferences(ctx context.Context, userID string) (*database.EmailPreferences, error) {
+	query := `
+		SELECT user_id, household_invites, weekly_digest, updated_at
+		FROM email_preferences WHERE user_id = $1
+	`
+	var prefs database.EmailPreferences
+	err := db.conn(ctx).QueryRow(ctx, query, userID).Scan(
+		&prefs.UserID, &prefs.HouseholdInvites, &prefs.WeeklyDigest, &prefs.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &prefs, nil
+}
+
+// UpsertEmailPreferences creates or replaces a user's email preferences
+func (db *PostgresDB) UpsertEmailPreferences(ctx context.Context, prefs *database.EmailPreferences) error {
+	query := `
+		INSERT INTO email_preferences (user_id, household_invites, weekly_digest, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE
+		SET household_invites = EXCLUDED.household_invites, weekly_digest = EXCLUDED.weekly_digest,
+		    updated_at = EXCLUDED.updated_at
+	`
+	_, err := db.conn(ctx).Exec(ctx, query, prefs.UserID, prefs.HouseholdInvites, prefs.WeeklyDigest, prefs.UpdatedAt)
+	return err
+}
@@ -0,0 +1,123 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Energy check-in operations
+
+const energyCheckInColumns = `id, user_id, level, COALESCE(note, ''), recorded_at, created_at`
+
+// CreateEnergyCheckIn records an energy level
+func (db *PostgresDB) CreateEnergyCheckIn(ctx context.Context, checkIn *database.EnergyCheckIn) error {
+	query := `
+		INSERT INTO energy_checkins (id, user_id, level, note, recorded_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := db.conn(ctx).Exec(ctx, query,
+		checkIn.ID, checkIn.UserID, checkIn.Level, checkIn.Note, checkIn.RecordedAt, checkIn.CreatedAt,
+	)
+	return err
+}
+
+// GetEnergyCheckInByID retrieves an energy check-in by ID
+func (db *PostgresDB) GetEnergyCheckInByID(ctx context.Context, id string) (*database.EnergyCheckIn, error) {
+	query := `SELECT ` + energyCheckInColumns + ` FROM energy_checkins WHERE id = $1`
+	var checkIn database.EnergyCheckIn
+	err := db.conn(ctx).QueryRow(ctx, query, id).Scan(
+		&checkIn.ID, &checkIn.UserID, &checkIn.Level, &checkIn.Note, &checkIn.RecordedAt, &checkIn.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &checkIn, nil
+}
+
+// GetLatestEnergyCheckIn retrieves a user's most recent energy check-in
+func (db *PostgresDB) GetLatestEnergyCheckIn(ctx context.Context, userID string) (*database.EnergyCheckIn, error) {
+	query := `
+		SELECT ` + energyCheckInColumns + `
+		FROM energy_checkins
+		WHERE user_id = $1
+		ORDER BY recorded_at DESC
+		LIMIT 1
+	`
+	var checkIn database.EnergyCheckIn
+	err := db.conn(ctx).QueryRow(ctx, query, userID).Scan(
+		&checkIn.ID, &checkIn.UserID, &checkIn.Level, &checkIn.Note, &checkIn.RecordedAt, &checkIn.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &checkIn, nil
+}
+
+// ListEnergyCheckIns lists a user's energy check-ins, newest first
+func (db *PostgresDB) ListEnergyCheckIns(ctx context.Context, filter database.EnergyCheckInFilter) ([]*database.EnergyCheckIn, error) {
+	query := `SELECT ` + energyCheckInColumns + ` FROM energy_checkins WHERE user_id = $1`
+	args := []interface{}{filter.UserID}
+	argPos := 2
+
+	if !filter.Since.IsZero() {
+		query += fmt.Sprintf(" AND recorded_at >= $%d", argPos)
+		args = append(args, filter.Since)
+		argPos++
+	}
+	if !filter.Until.IsZero() {
+		query += fmt.Sprintf(" AND recorded_at < $%d", argPos)
+		args = append(args, filter.Until)
+		argPos++
+	}
+
+	query += " ORDER BY recorded_at DESC"
+
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argPos)
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := db.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	checkIns := []*database.EnergyCheckIn{}
+	for rows.Next() {
+		var checkIn database.EnergyCheckIn
+		if err := rows.Scan(
+			&checkIn.ID, &checkIn.UserID, &checkIn.Level, &checkIn.Note, &checkIn.RecordedAt, &checkIn.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		checkIns = append(checkIns, &checkIn)
+	}
+	return checkIns, rows.Err()
+}
+
+// DeleteEnergyCheckIn deletes an energy check-in
+func (db *PostgresDB) DeleteEnergyCheckIn(ctx context.Context, id string) error {
+	_, err := db.conn(ctx).Exec(ctx, `DELETE FROM energy_checkins WHERE id = $1`, id)
+	return err
+}
@@ -0,0 +1,135 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Equipment operations
+
+const householdEquipmentColumns = `id, household_id, name, COALESCE(notes, ''), created_by, created_at`
+
+// CreateHouseholdEquipment adds a piece of equipment to a household
+func (db *PostgresDB) CreateHouseholdEquipment(ctx context.Context, equipment *database.HouseholdEquipment) error {
+	query := `
+		INSERT INTO household_equipment (id, household_id, name, notes, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := db.conn(ctx).Exec(ctx, query,
+		equipment.ID, equipment.HouseholdID, equipment.Name, equipment.Notes, equipment.CreatedBy, equipment.CreatedAt,
+	)
+	return err
+}
+
+// GetHouseholdEquipmentByID retrieves a piece of household equipment by ID
+func (db *PostgresDB) GetHouseholdEquipmentByID(ctx context.Context, id string) (*database.HouseholdEquipment, error) {
+	query := `SELECT ` + householdEquipmentColumns + ` FROM household_equipment WHERE id = $1`
+	return scanHouseholdEquipment(db.conn(ctx).QueryRow(ctx, query, id))
+}
+
+// ListHouseholdEquipment lists the equipment of the given households, by name
+func (db *PostgresDB) ListHouseholdEquipment(ctx context.Context, householdIDs []string) ([]*database.HouseholdEquipment, error) {
+	if len(householdIDs) == 0 {
+		return []*database.HouseholdEquipment{}, nil
+	}
+
+	query := `SELECT ` + householdEquipmentColumns + ` FROM household_equipment
+		WHERE household_id = ANY($1)
+		ORDER BY name, household_id`
+	rows, err := db.conn(ctx).Query(ctx, query, householdIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []*database.HouseholdEquipment{}
+	for rows.Next() {
+		equipment, err := scanHouseholdEquipment(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, equipment)
+	}
+	return list, rows.Err()
+}
+
+// DeleteHouseholdEquipment removes a piece of equipment from its household
+func (db *PostgresDB) DeleteHouseholdEquipment(ctx context.Context, id string) error {
+	_, err := db.conn(ctx).Exec(ctx, `DELETE FROM household_equipment WHERE id = $1`, id)
+	return err
+}
+
+// ListRecipeEquipment returns the equipment the given recipes are tagged
+// with, by recipe ID
+func (db *PostgresDB) ListRecipeEquipment(ctx context.Context, recipeIDs []string) (map[string][]string, error) {
+	tagged := map[string][]string{}
+	if len(recipeIDs) == 0 {
+		return tagged, nil
+	}
+
+	query := `SELECT recipe_id, name FROM recipe_equipment
+		WHERE recipe_id = ANY($1)
+		ORDER BY recipe_id, name`
+	rows, err := db.conn(ctx).Query(ctx, query, recipeIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var recipeID, name string
+		if err := rows.Scan(&recipeID, &name); err != nil {
+			return nil, err
+		}
+		tagged[recipeID] = append(tagged[recipeID], name)
+	}
+	return tagged, rows.Err()
+}
+
+// SetRecipeEquipment replaces the equipment a recipe is tagged with
+func (db *PostgresDB) SetRecipeEquipment(ctx context.Context, recipeID string, names []string) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if _, err := db.conn(ctx).Exec(ctx, `DELETE FROM recipe_equipment WHERE recipe_id = $1`, recipeID); err != nil {
+			return err
+		}
+		for _, name := range names {
+			_, err := db.conn(ctx).Exec(ctx,
+				`INSERT INTO recipe_equipment (recipe_id, name) VALUES ($1, $2)`, recipeID, name)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func scanHouseholdEquipment(row interface{ Scan(dest ...any) error }) (*database.HouseholdEquipment, error) {
+	var equipment database.HouseholdEquipment
+	err := row.Scan(
+		&equipment.ID, &equipment.HouseholdID, &equipment.Name, &equipment.Notes, &equipment.CreatedBy,
+		&equipment.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &equipment, nil
+}
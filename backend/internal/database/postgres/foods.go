@@ -0,0 +1,112 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Packaged food product cache operations
+
+// GetFoodProduct retrieves a cached product by barcode
+func (db *PostgresDB) GetFoodProduct(ctx context.Context, barcode string) (*database.FoodProduct, error) {
+	query := `
+		SELECT barcode, found, source, COALESCE(name, ''), COALESCE(brand, ''), COALESCE(quantity, ''),
+		       COALESCE(serving_size, ''), COALESCE(image_url, ''), categories, nutrition_100g, nutrition_serving, fetched_at
+		FROM food_products WHERE barcode = $1
+	`
+	var product database.FoodProduct
+	var categories, per100g, perServing []byte
+	err := db.conn(ctx).QueryRow(ctx, query, barcode).Scan(
+		&product.Barcode, &product.Found, &product.Source, &product.Name, &product.Brand, &product.Quantity,
+		&product.ServingSize, &product.ImageURL, &categories, &per100g, &perServing, &product.FetchedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := decodeFoodProduct(&product, categories, per100g, perServing); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// UpsertFoodProduct caches a product, replacing any earlier lookup
+func (db *PostgresDB) UpsertFoodProduct(ctx context.Context, product *database.FoodProduct) error {
+	categories, per100g, perServing, err := encodeFoodProduct(product)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO food_products (barcode, found, source, name, brand, quantity, serving_size, image_url,
+		                           categories, nutrition_100g, nutrition_serving, fetched_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (barcode) DO UPDATE
+		SET found = EXCLUDED.found, source = EXCLUDED.source, name = EXCLUDED.name, brand = EXCLUDED.brand,
+		    quantity = EXCLUDED.quantity, serving_size = EXCLUDED.serving_size, image_url = EXCLUDED.image_url,
+		    categories = EXCLUDED.categories, nutrition_100g = EXCLUDED.nutrition_100g,
+		    nutrition_serving = EXCLUDED.nutrition_serving, fetched_at = EXCLUDED.fetched_at
+	`
+	_, err = db.conn(ctx).Exec(ctx, query,
+		product.Barcode, product.Found, product.Source, product.Name, product.Brand, product.Quantity,
+		product.ServingSize, product.ImageURL, categories, per100g, perServing, product.FetchedAt,
+	)
+	return err
+}
+
+// encodeFoodProduct encodes the JSON columns of a product; missing
+// nutrition is stored as NULL
+func encodeFoodProduct(product *database.FoodProduct) (categories, per100g, perServing []byte, err error) {
+	if categories, err = json.Marshal(product.Categories); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to encode categories: %w", err)
+	}
+	if product.NutritionPer100g != nil {
+		if per100g, err = json.Marshal(product.NutritionPer100g); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to encode nutrition: %w", err)
+		}
+	}
+	if product.NutritionPerServing != nil {
+		if perServing, err = json.Marshal(product.NutritionPerServing); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to encode nutrition: %w", err)
+		}
+	}
+	return categories, per100g, perServing, nil
+}
+
+// decodeFoodProduct decodes the JSON columns of a product
+func decodeFoodProduct(product *database.FoodProduct, categories, per100g, perServing []byte) error {
+	if err := json.Unmarshal(categories, &product.Categories); err != nil {
+		return fmt.Errorf("failed to decode categories: %w", err)
+	}
+	if len(per100g) > 0 {
+		if err := json.Unmarshal(per100g, &product.NutritionPer100g); err != nil {
+			return fmt.Errorf("failed to decode nutrition: %w", err)
+		}
+	}
+	if len(perServing) > 0 {
+		if err := json.Unmarshal(perServing, &product.NutritionPerServing); err != nil {
+			return fmt.Errorf("failed to decode nutrition: %w", err)
+		}
+	}
+	return nil
+}
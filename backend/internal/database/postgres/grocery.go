@@ -0,0 +1,152 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Grocery price and budget operations
+
+const groceryPriceColumns = `id, user_id, store, name, quantity::float8, unit, price::float8, purchased, shopping_item_id,
+	recorded_at, created_at`
+
+// CreateGroceryPrice records a price paid or seen
+func (db *PostgresDB) CreateGroceryPrice(ctx context.Context, price *database.GroceryPrice) error {
+	query := `
+		INSERT INTO grocery_prices (id, user_id, store, name, quantity, unit, price, purchased,
+		                            shopping_item_id, recorded_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	_, err := db.conn(ctx).Exec(ctx, query,
+		price.ID, price.UserID, price.Store, price.Name, price.Quantity, price.Unit, price.Price,
+		price.Purchased, price.ShoppingItemID, price.RecordedAt, price.CreatedAt,
+	)
+	return err
+}
+
+// GetGroceryPriceByID retrieves a recorded price by ID
+func (db *PostgresDB) GetGroceryPriceByID(ctx context.Context, id string) (*database.GroceryPrice, error) {
+	query := `SELECT ` + groceryPriceColumns + ` FROM grocery_prices WHERE id = $1`
+	return scanGroceryPrice(db.conn(ctx).QueryRow(ctx, query, id))
+}
+
+// ListGroceryPrices lists a user's recorded prices, most recent first
+func (db *PostgresDB) ListGroceryPrices(ctx context.Context, filter database.GroceryPriceFilter) ([]*database.GroceryPrice, error) {
+	query := `SELECT ` + groceryPriceColumns + ` FROM grocery_prices WHERE user_id = $1`
+	args := []interface{}{filter.UserID}
+
+	if filter.Store != "" {
+		args = append(args, filter.Store)
+		query += fmt.Sprintf(" AND store = $%d", len(args))
+	}
+	if filter.PurchasedOnly {
+		query += " AND purchased"
+	}
+	if !filter.Since.IsZero() {
+		args = append(args, filter.Since)
+		query += fmt.Sprintf(" AND recorded_at >= $%d", len(args))
+	}
+	if !filter.Until.IsZero() {
+		args = append(args, filter.Until)
+		query += fmt.Sprintf(" AND recorded_at < $%d", len(args))
+	}
+
+	query += " ORDER BY recorded_at DESC, created_at DESC"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := db.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prices := []*database.GroceryPrice{}
+	for rows.Next() {
+		price, err := scanGroceryPrice(rows)
+		if err != nil {
+			return nil, err
+		}
+		prices = append(prices, price)
+	}
+	return prices, rows.Err()
+}
+
+// DeleteGroceryPrice deletes a recorded price
+func (db *PostgresDB) DeleteGroceryPrice(ctx context.Context, id string) error {
+	_, err := db.conn(ctx).Exec(ctx, `DELETE FROM grocery_prices WHERE id = $1`, id)
+	return err
+}
+
+// DeleteShoppingItemPurchase deletes the purchase recorded when a shopping
+// list item was ticked off
+func (db *PostgresDB) DeleteShoppingItemPurchase(ctx context.Context, shoppingItemID string) error {
+	_, err := db.conn(ctx).Exec(ctx,
+		`DELETE FROM grocery_prices WHERE shopping_item_id = $1 AND purchased`, shoppingItemID)
+	return err
+}
+
+// GetGroceryBudget retrieves a user's monthly grocery budget
+func (db *PostgresDB) GetGroceryBudget(ctx context.Context, userID string) (*database.GroceryBudget, error) {
+	query := `SELECT user_id, monthly_limit::float8, currency, updated_at FROM grocery_budgets WHERE user_id = $1`
+	var budget database.GroceryBudget
+	err := db.conn(ctx).QueryRow(ctx, query, userID).Scan(
+		&budget.UserID, &budget.MonthlyLimit, &budget.Currency, &budget.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &budget, nil
+}
+
+// UpsertGroceryBudget creates or replaces a user's monthly grocery budget
+func (db *PostgresDB) UpsertGroceryBudget(ctx context.Context, budget *database.GroceryBudget) error {
+	query := `
+		INSERT INTO grocery_budgets (user_id, monthly_limit, currency, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE
+		SET monthly_limit = excluded.monthly_limit, currency = excluded.currency, updated_at = excluded.updated_at
+	`
+	_, err := db.conn(ctx).Exec(ctx, query, budget.UserID, budget.MonthlyLimit, budget.Currency, budget.UpdatedAt)
+	return err
+}
+
+// DeleteGroceryBudget deletes a user's monthly grocery budget
+func (db *PostgresDB) DeleteGroceryBudget(ctx context.Context, userID string) error {
+	_, err := db.conn(ctx).Exec(ctx, `DELETE FROM grocery_budgets WHERE user_id = $1`, userID)
+	return err
+}
+
+func scanGroceryPrice(row interface{ Scan(dest ...any) error }) (*database.GroceryPrice, error) {
+	var price database.GroceryPrice
+	err := row.Scan(
+		&price.ID, &price.UserID, &price.Store, &price.Name, &price.Quantity, &price.Unit, &price.Price,
+		&price.Purchased, &price.ShoppingItemID, &price.RecordedAt, &price.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &price, nil
+}
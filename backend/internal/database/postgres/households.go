@@ -0,0 +1,328 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Household operations
+
+// CreateHousehold creates a new household and registers its owner as a member
+func (db *PostgresDB) CreateHousehold(ctx context.Context, household *database.Household) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		tx := db.conn(ctx)
+
+		query := `
+			INSERT INTO households (id, name, owner_id, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5)
+		`
+		if _, err := tx.Exec(ctx, query,
+			household.ID, household.Name, household.OwnerID, household.CreatedAt, household.UpdatedAt,
+		); err != nil {
+			return err
+		}
+
+		memberQuery := `
+			INSERT INTO household_members (household_id, user_id, role, joined_at)
+			VALUES ($1, $2, $3, $4)
+		`
+		if _, err := tx.Exec(ctx, memberQuery,
+			household.ID, household.OwnerID, database.HouseholdRoleOwner, household.CreatedAt,
+		); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// GetHouseholdByID retrieves a household by ID
+func (db *PostgresDB) GetHouseholdByID(ctx context.Context, id string) (*database.Household, error) {
+	query := `
+		SELECT id, name, owner_id, created_at, updated_at
+		FROM households WHERE id = $1
+	`
+	var household database.Household
+	err := db.conn(ctx).QueryRow(ctx, query, id).Scan(
+		&household.ID, &household.Name, &household.OwnerID, &household.CreatedAt, &household.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &household, nil
+}
+
+// ListHouseholdsByUser lists the households a user is a member of
+func (db *PostgresDB) ListHouseholdsByUser(ctx context.Context, userID string) ([]*database.Household, error) {
+	query := `
+		SELECT h.id, h.name, h.owner_id, h.created_at, h.updated_at
+		FROM households h
+		JOIN household_members m ON m.household_id = h.id
+		WHERE m.user_id = $1
+		ORDER BY h.created_at
+	`
+	rows, err := db.conn(ctx).Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	households := []*database.Household{}
+	for rows.Next() {
+		var household database.Household
+		if err := rows.Scan(
+			&household.ID, &household.Name, &household.OwnerID, &household.CreatedAt, &household.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		households = append(households, &household)
+	}
+	return households, rows.Err()
+}
+
+// DeleteHousehold deletes a household along with its memberships and invitations
+func (db *PostgresDB) DeleteHousehold(ctx context.Context, id string) error {
+	query := `DELETE FROM households WHERE id = $1`
+	_, err := db.conn(ctx).Exec(ctx, query, id)
+	return err
+}
+
+// AddHouseholdMember adds a user to a household
+func (db *PostgresDB) AddHouseholdMember(ctx context.Context, member *database.HouseholdMember) error {
+	query := `
+		INSERT INTO household_members (household_id, user_id, role, joined_at)
+		VALUES ($1, $2, $3, $4)
+	`
+	_, err := db.conn(ctx).Exec(ctx, query, member.HouseholdID, member.UserID, member.Role, member.JoinedAt)
+	return err
+}
+
+// GetHouseholdMember retrieves a single household membership
+func (db *PostgresDB) GetHouseholdMember(ctx context.Context, householdID, userID string) (*database.HouseholdMember, error) {
+	query := `
+		SELECT m.household_id, m.user_id, m.role, m.joined_at, u.email, COALESCE(u.first_name, ''), COALESCE(u.last_name, '')
+		FROM household_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.household_id = $1 AND m.user_id = $2
+	`
+	var member database.HouseholdMember
+	err := db.conn(ctx).QueryRow(ctx, query, householdID, userID).Scan(
+		&member.HouseholdID, &member.UserID, &member.Role, &member.JoinedAt,
+		&member.Email, &member.FirstName, &member.LastName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// ListHouseholdMembers lists the members of a household
+func (db *PostgresDB) ListHouseholdMembers(ctx context.Context, householdID string) ([]*database.HouseholdMember, error) {
+	query := `
+		SELECT m.household_id, m.user_id, m.role, m.joined_at, u.email, COALESCE(u.first_name, ''), COALESCE(u.last_name, '')
+		FROM household_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.household_id = $1
+		ORDER BY m.joined_at
+	`
+	rows, err := db.conn(ctx).Query(ctx, query, householdID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []*database.HouseholdMember{}
+	for rows.Next() {
+		var member database.HouseholdMember
+		if err := rows.Scan(
+			&member.HouseholdID, &member.UserID, &member.Role, &member.JoinedAt,
+			&member.Email, &member.FirstName, &member.LastName,
+		); err != nil {
+			return nil, err
+		}
+		members = append(members, &member)
+	}
+	return members, rows.Err()
+}
+
+// RemoveHouseholdMember removes a user from a household
+func (db *PostgresDB) RemoveHouseholdMember(ctx context.Context, householdID, userID string) error {
+	query := `DELETE FROM household_members WHERE household_id = $1 AND user_id = $2`
+	_, err := db.conn(ctx).Exec(ctx, query, householdID, userID)
+	return err
+}
+
+// TransferHouseholdOwnership makes an existing member the owner of a
+// household; the previous owner stays on as an admin
+func (db *PostgresDB) TransferHouseholdOwnership(ctx context.Context, householdID, newOwnerID string) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		tx := db.conn(ctx)
+
+		demote := `
+			UPDATE household_members SET role = $1
+			WHERE household_id = $2 AND role = $3
+		`
+		if _, err := tx.Exec(ctx, demote, database.HouseholdRoleAdmin, householdID, database.HouseholdRoleOwner); err != nil {
+			return err
+		}
+
+		promote := `
+			UPDATE household_members SET role = $1
+			WHERE household_id = $2 AND user_id = $3
+		`
+		tag, err := tx.Exec(ctx, promote, database.HouseholdRoleOwner, householdID, newOwnerID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return fmt.Errorf("user %s is not a member of household %s", newOwnerID, householdID)
+		}
+
+		query := `UPDATE households SET owner_id = $1, updated_at = $2 WHERE id = $3`
+		_, err = tx.Exec(ctx, query, newOwnerID, time.Now(), householdID)
+		return err
+	})
+}
+
+// Household invitation operations
+
+const householdInvitationColumns = `
+	id, household_id, invited_by, COALESCE(email, ''), code_hash, role, status,
+	expires_at, created_at, responded_at, responded_by
+`
+
+func scanHouseholdInvitation(row interface{ Scan(dest ...any) error }) (*database.HouseholdInvitation, error) {
+	var invitation database.HouseholdInvitation
+	err := row.Scan(
+		&invitation.ID, &invitation.HouseholdID, &invitation.InvitedBy, &invitation.Email,
+		&invitation.CodeHash, &invitation.Role, &invitation.Status, &invitation.ExpiresAt,
+		&invitation.CreatedAt, &invitation.RespondedAt, &invitation.RespondedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// CreateHouseholdInvitation creates a new household invitation
+func (db *PostgresDB) CreateHouseholdInvitation(ctx context.Context, invitation *database.HouseholdInvitation) error {
+	query := `
+		INSERT INTO household_invitations (id, household_id, invited_by, email, code_hash, role, status, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err := db.conn(ctx).Exec(ctx, query,
+		invitation.ID, invitation.HouseholdID, invitation.InvitedBy, invitation.Email, invitation.CodeHash,
+		invitation.Role, invitation.Status, invitation.ExpiresAt, invitation.CreatedAt,
+	)
+	return err
+}
+
+// GetHouseholdInvitationByID retrieves a household invitation by ID
+func (db *PostgresDB) GetHouseholdInvitationByID(ctx context.Context, id string) (*database.HouseholdInvitation, error) {
+	query := `SELECT ` + householdInvitationColumns + ` FROM household_invitations WHERE id = $1`
+	return scanHouseholdInvitation(db.conn(ctx).QueryRow(ctx, query, id))
+}
+
+// GetHouseholdInvitationByCodeHash retrieves a household invitation by the hash of its code
+func (db *PostgresDB) GetHouseholdInvitationByCodeHash(ctx context.Context, codeHash string) (*database.HouseholdInvitation, error) {
+	query := `SELECT ` + householdInvitationColumns + ` FROM household_invitations WHERE code_hash = $1`
+	return scanHouseholdInvitation(db.conn(ctx).QueryRow(ctx, query, codeHash))
+}
+
+// ListHouseholdInvitations lists household invitations with filters
+func (db *PostgresDB) ListHouseholdInvitations(ctx context.Context, filter database.HouseholdInvitationFilter) ([]*database.HouseholdInvitation, error) {
+	conditions := []string{}
+	args := []interface{}{}
+
+	if filter.HouseholdID != "" {
+		args = append(args, filter.HouseholdID)
+		conditions = append(conditions, fmt.Sprintf("household_id = $%d", len(args)))
+	}
+	if filter.Email != "" {
+		args = append(args, filter.Email)
+		conditions = append(conditions, fmt.Sprintf("LOWER(email) = LOWER($%d)", len(args)))
+	}
+	if filter.PendingOnly {
+		args = append(args, database.InvitationStatusPending)
+		conditions = append(conditions, fmt.Sprintf("status = $%d AND expires_at > CURRENT_TIMESTAMP", len(args)))
+	}
+
+	query := `SELECT ` + householdInvitationColumns + ` FROM household_invitations`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY created_at DESC"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit, filter.Offset)
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	}
+
+	rows, err := db.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := []*database.HouseholdInvitation{}
+	for rows.Next() {
+		invitation, err := scanHouseholdInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, invitation)
+	}
+	return invitations, rows.Err()
+}
+
+// UpdateHouseholdInvitation updates the status of a household invitation
+func (db *PostgresDB) UpdateHouseholdInvitation(ctx context.Context, invitation *database.HouseholdInvitation) error {
+	query := `
+		UPDATE household_invitations
+		SET status = $2, responded_at = $3, responded_by = $4
+		WHERE id = $1
+	`
+	_, err := db.conn(ctx).Exec(ctx, query,
+		invitation.ID, invitation.Status, invitation.RespondedAt, invitation.RespondedBy,
+	)
+	return err
+}
+
+// AcceptHouseholdInvitation marks a pending, unexpired invitation as
+// accepted by the user, reporting whether it was still open. Concurrent
+// callers with the same invitation cannot both succeed.
+func (db *PostgresDB) AcceptHouseholdInvitation(ctx context.Context, id, userID string, now time.Time) (bool, error) {
+	query := `
+		UPDATE household_invitations
+		SET status = $2, responded_at = $3, responded_by = $4
+		WHERE id = $1 AND status = $5 AND expires_at > $3
+	`
+	tag, err := db.conn(ctx).Exec(ctx, query,
+		id, database.InvitationStatusAccepted, now, userID, database.InvitationStatusPending,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
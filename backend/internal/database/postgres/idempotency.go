@@ -0,0 +1,83 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Idempotency key operations
+
+// CreateIdempotencyKey claims a key for a request that has not finished
+// yet. It fails with a uniqueness violation when the user already holds
+// the key.
+func (db *PostgresDB) CreateIdempotencyKey(ctx context.Context, key *database.IdempotencyKey) error {
+	query := `
+		INSERT INTO idempotency_keys (user_id, idempotency_key, request_hash, status_code, created_at)
+		VALUES ($1, $2, $3, 0, $4)
+	`
+	_, err := db.conn(ctx).Exec(ctx, query, key.UserID, key.Key, key.RequestHash, key.CreatedAt)
+	return err
+}
+
+// GetIdempotencyKey retrieves a user's key with its stored response
+func (db *PostgresDB) GetIdempotencyKey(ctx context.Context, userID, key string) (*database.IdempotencyKey, error) {
+	query := `
+		SELECT user_id, idempotency_key, request_hash, status_code, COALESCE(content_type, ''), response_body, created_at
+		FROM idempotency_keys
+		WHERE user_id = $1 AND idempotency_key = $2
+	`
+	var k database.IdempotencyKey
+	err := db.conn(ctx).QueryRow(ctx, query, userID, key).Scan(
+		&k.UserID, &k.Key, &k.RequestHash, &k.StatusCode, &k.ContentType, &k.Body, &k.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// CompleteIdempotencyKey stores the response to a key's request
+func (db *PostgresDB) CompleteIdempotencyKey(ctx context.Context, key *database.IdempotencyKey) error {
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = $1, content_type = $2, response_body = $3
+		WHERE user_id = $4 AND idempotency_key = $5
+	`
+	_, err := db.conn(ctx).Exec(ctx, query, key.StatusCode, key.ContentType, key.Body, key.UserID, key.Key)
+	return err
+}
+
+// DeleteIdempotencyKey releases a key so its request can be tried again
+func (db *PostgresDB) DeleteIdempotencyKey(ctx context.Context, userID, key string) error {
+	_, err := db.conn(ctx).Exec(ctx, `DELETE FROM idempotency_keys WHERE user_id = $1 AND idempotency_key = $2`, userID, key)
+	return err
+}
+
+// PurgeIdempotencyKeys removes keys claimed before a time
+func (db *PostgresDB) PurgeIdempotencyKeys(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := db.conn(ctx).Exec(ctx, `DELETE FROM idempotency_keys WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,54 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// External identity operations
+
+// CreateUserIdentity links a user to an external identity
+func (db *PostgresDB) CreateUserIdentity(ctx context.Context, identity *database.UserIdentity) error {
+	query := `
+		INSERT INTO user_identities (provider, subject, user_id, created_at)
+		VALUES ($1, $2, $3, $4)
+	`
+	_, err := db.conn(ctx).Exec(ctx, query, identity.Provider, identity.Subject, identity.UserID, identity.CreatedAt)
+	return err
+}
+
+// GetUserIdentity retrieves the link for an external identity
+func (db *PostgresDB) GetUserIdentity(ctx context.Context, provider, subject string) (*database.UserIdentity, error) {
+	query := `
+		SELECT provider, subject, user_id, created_at
+		FROM user_identities
+		WHERE provider = $1 AND subject = $2
+	`
+	var identity database.UserIdentity
+	err := db.conn(ctx).QueryRow(ctx, query, provider, subject).Scan(
+		&identity.Provider, &identity.Subject, &identity.UserID, &identity.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
@@ -0,0 +1,194 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Scheduled job operations
+
+// SyncScheduledJob records a registered job, keeping its next run time
+// unless the schedule changed
+func (db *PostgresDB) SyncScheduledJob(ctx context.Context, job *database.ScheduledJob) error {
+	query := `
+		INSERT INTO scheduled_jobs (name, schedule, next_run_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (name) DO UPDATE
+		SET next_run_at = CASE WHEN scheduled_jobs.schedule = EXCLUDED.schedule
+		                       THEN scheduled_jobs.next_run_at ELSE EXCLUDED.next_run_at END,
+		    schedule = EXCLUDED.schedule
+	`
+	_, err := db.conn(ctx).Exec(ctx, query, job.Name, job.Schedule, job.NextRunAt)
+	return err
+}
+
+// ListScheduledJobs lists every persisted job schedule
+func (db *PostgresDB) ListScheduledJobs(ctx context.Context) ([]*database.ScheduledJob, error) {
+	query := `
+		SELECT name, schedule, next_run_at, last_run_at, COALESCE(last_status, '')
+		FROM scheduled_jobs
+		ORDER BY name
+	`
+	rows, err := db.conn(ctx).Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []*database.ScheduledJob{}
+	for rows.Next() {
+		var job database.ScheduledJob
+		if err := rows.Scan(&job.Name, &job.Schedule, &job.NextRunAt, &job.LastRunAt, &job.LastStatus); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, &job)
+	}
+	return jobs, rows.Err()
+}
+
+// ClaimScheduledJob moves a due job to its next run time, reporting whether
+// this caller claimed the run
+func (db *PostgresDB) ClaimScheduledJob(ctx context.Context, name string, now, nextRunAt time.Time) (bool, error) {
+	query := `UPDATE scheduled_jobs SET next_run_at = $3 WHERE name = $1 AND next_run_at <= $2`
+	tag, err := db.conn(ctx).Exec(ctx, query, name, now, nextRunAt)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// UpdateScheduledJobStatus records the outcome of a job's latest run
+func (db *PostgresDB) UpdateScheduledJobStatus(ctx context.Context, name string, lastRunAt time.Time, lastStatus string) error {
+	query := `UPDATE scheduled_jobs SET last_run_at = $2, last_status = $3 WHERE name = $1`
+	_, err := db.conn(ctx).Exec(ctx, query, name, lastRunAt, lastStatus)
+	return err
+}
+
+// CreateJobRun records the start of a job run
+func (db *PostgresDB) CreateJobRun(ctx context.Context, run *database.JobRun) error {
+	query := `
+		INSERT INTO job_runs (id, job_name, triggered_by, status, result, error, started_at, finished_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := db.conn(ctx).Exec(ctx, query,
+		run.ID, run.JobName, run.Trigger, run.Status, run.Result, run.Error, run.StartedAt, run.FinishedAt,
+	)
+	return err
+}
+
+// FinishJobRun records the outcome of a job run
+func (db *PostgresDB) FinishJobRun(ctx context.Context, run *database.JobRun) error {
+	query := `UPDATE job_runs SET status = $2, result = $3, error = $4, finished_at = $5 WHERE id = $1`
+	_, err := db.conn(ctx).Exec(ctx, query, run.ID, run.Status, run.Result, run.Error, run.FinishedAt)
+	return err
+}
+
+// ListJobRuns lists job runs, newest first
+func (db *PostgresDB) ListJobRuns(ctx context.Context, filter database.JobRunFilter) ([]*database.JobRun, error) {
+	where, args := jobRunConditions(filter)
+	query := `
+		SELECT id, job_name, triggered_by, status, COALESCE(result, ''), COALESCE(error, ''), started_at, finished_at
+		FROM job_runs
+		WHERE ` + where
+	argPos := len(args) + 1
+
+	if filter.After != nil {
+		query += fmt.Sprintf(" AND (started_at, id) < ($%d, $%d)", argPos, argPos+1)
+		args = append(args, filter.After.Time, filter.After.ID)
+		argPos += 2
+	}
+
+	query += " ORDER BY started_at DESC, id DESC"
+
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argPos)
+		args = append(args, filter.Limit)
+		argPos++
+	}
+	if filter.Offset > 0 && filter.After == nil {
+		query += fmt.Sprintf(" OFFSET $%d", argPos)
+		args = append(args, filter.Offset)
+	}
+
+	rows, err := db.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []*database.JobRun{}
+	for rows.Next() {
+		var run database.JobRun
+		if err := rows.Scan(
+			&run.ID, &run.JobName, &run.Trigger, &run.Status,
+			&run.Result, &run.Error, &run.StartedAt, &run.FinishedAt,
+		); err != nil {
+			return nil, err
+		}
+		runs = append(runs, &run)
+	}
+	return runs, rows.Err()
+}
+
+// CountJobRuns counts the job runs matching a filter, ignoring its paging
+func (db *PostgresDB) CountJobRuns(ctx context.Context, filter database.JobRunFilter) (int, error) {
+	where, args := jobRunConditions(filter)
+	var count int
+	err := db.conn(ctx).QueryRow(ctx, `SELECT COUNT(*) FROM job_runs WHERE `+where, args...).Scan(&count)
+	return count, err
+}
+
+// jobRunConditions builds the WHERE clause shared by listing and counting
+func jobRunConditions(filter database.JobRunFilter) (string, []interface{}) {
+	where := "1=1"
+	args := []interface{}{}
+	if filter.JobName != "" {
+		args = append(args, filter.JobName)
+		where += fmt.Sprintf(" AND job_name = $%d", len(args))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		where += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	return where, args
+}
+
+// InterruptJobRuns marks runs left running by a previous process as failed
+func (db *PostgresDB) InterruptJobRuns(ctx context.Context, finishedAt time.Time, reason string) (int64, error) {
+	query := `UPDATE job_runs SET status = 'failed', error = $2, finished_at = $1 WHERE status = 'running'`
+	tag, err := db.conn(ctx).Exec(ctx, query, finishedAt, reason)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// PurgeJobRuns removes job runs that started before a cutoff
+func (db *PostgresDB) PurgeJobRuns(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := db.conn(ctx).Exec(ctx, `DELETE FROM job_runs WHERE started_at < $1 AND status <> 'running'`, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
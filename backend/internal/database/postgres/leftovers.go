@@ -0,0 +1,158 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Leftover operations
+
+const leftoverColumns = `id, user_id, recipe_id, name, food_type, portions::float8, COALESCE(container, ''), storage,
+	stored_at, eat_by, COALESCE(notes, ''), COALESCE(reheat, ''), thaw_at, thaw_reminded_at, finished_at,
+	created_at, updated_at`
+
+// CreateLeftover records a new batch of leftovers
+func (db *PostgresDB) CreateLeftover(ctx context.Context, leftover *database.Leftover) error {
+	query := `
+		INSERT INTO leftovers (id, user_id, recipe_id, name, food_type, portions, container, storage,
+		                       stored_at, eat_by, notes, reheat, thaw_at, thaw_reminded_at, finished_at,
+		                       created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+	`
+	_, err := db.conn(ctx).Exec(ctx, query,
+		leftover.ID, leftover.UserID, leftover.RecipeID, leftover.Name, leftover.FoodType, leftover.Portions,
+		leftover.Container, leftover.Storage, leftover.StoredAt, leftover.EatBy, leftover.Notes,
+		leftover.Reheat, leftover.ThawAt, leftover.ThawRemindedAt, leftover.FinishedAt, leftover.CreatedAt,
+		leftover.UpdatedAt,
+	)
+	return err
+}
+
+// GetLeftoverByID retrieves leftovers by ID
+func (db *PostgresDB) GetLeftoverByID(ctx context.Context, id string) (*database.Leftover, error) {
+	query := `SELECT ` + leftoverColumns + ` FROM leftovers WHERE id = $1`
+	return scanLeftover(db.conn(ctx).QueryRow(ctx, query, id))
+}
+
+// ListLeftovers lists a user's leftovers, soonest eat-by first
+func (db *PostgresDB) ListLeftovers(ctx context.Context, filter database.LeftoverFilter) ([]*database.Leftover, error) {
+	query := `SELECT ` + leftoverColumns + ` FROM leftovers WHERE user_id = $1`
+	args := []interface{}{filter.UserID}
+	argPos := 2
+
+	if !filter.IncludeFinished {
+		query += " AND finished_at IS NULL"
+	}
+	if filter.EatByBefore != nil {
+		query += fmt.Sprintf(" AND eat_by < $%d", argPos)
+		args = append(args, *filter.EatByBefore)
+		argPos++
+	}
+	if filter.Storage != "" {
+		query += fmt.Sprintf(" AND storage = $%d", argPos)
+		args = append(args, filter.Storage)
+	}
+
+	query += " ORDER BY eat_by"
+
+	rows, err := db.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	leftovers := []*database.Leftover{}
+	for rows.Next() {
+		leftover, err := scanLeftover(rows)
+		if err != nil {
+			return nil, err
+		}
+		leftovers = append(leftovers, leftover)
+	}
+	return leftovers, rows.Err()
+}
+
+// UpdateLeftover updates leftovers
+func (db *PostgresDB) UpdateLeftover(ctx context.Context, leftover *database.Leftover) error {
+	query := `
+		UPDATE leftovers
+		SET name = $2, food_type = $3, portions = $4, container = $5, storage = $6,
+		    eat_by = $7, notes = $8, reheat = $9, thaw_at = $10, thaw_reminded_at = $11,
+		    finished_at = $12, updated_at = $13
+		WHERE id = $1
+	`
+	_, err := db.conn(ctx).Exec(ctx, query,
+		leftover.ID, leftover.Name, leftover.FoodType, leftover.Portions, leftover.Container,
+		leftover.Storage, leftover.EatBy, leftover.Notes, leftover.Reheat, leftover.ThawAt,
+		leftover.ThawRemindedAt, leftover.FinishedAt, leftover.UpdatedAt,
+	)
+	return err
+}
+
+// DeleteLeftover deletes leftovers
+func (db *PostgresDB) DeleteLeftover(ctx context.Context, id string) error {
+	_, err := db.conn(ctx).Exec(ctx, `DELETE FROM leftovers WHERE id = $1`, id)
+	return err
+}
+
+// ListThawDueLeftovers lists unfinished frozen leftovers across all users
+// whose thaw time has come and that haven't been reminded about yet
+func (db *PostgresDB) ListThawDueLeftovers(ctx context.Context, now time.Time) ([]*database.Leftover, error) {
+	query := `
+		SELECT ` + leftoverColumns + `
+		FROM leftovers
+		WHERE storage = $1 AND finished_at IS NULL AND thaw_reminded_at IS NULL AND thaw_at <= $2
+		ORDER BY thaw_at
+	`
+	rows, err := db.conn(ctx).Query(ctx, query, database.LeftoverStorageFreezer, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	leftovers := []*database.Leftover{}
+	for rows.Next() {
+		leftover, err := scanLeftover(rows)
+		if err != nil {
+			return nil, err
+		}
+		leftovers = append(leftovers, leftover)
+	}
+	return leftovers, rows.Err()
+}
+
+func scanLeftover(row pgx.Row) (*database.Leftover, error) {
+	var leftover database.Leftover
+	err := row.Scan(
+		&leftover.ID, &leftover.UserID, &leftover.RecipeID, &leftover.Name, &leftover.FoodType,
+		&leftover.Portions, &leftover.Container, &leftover.Storage, &leftover.StoredAt, &leftover.EatBy,
+		&leftover.Notes, &leftover.Reheat, &leftover.ThawAt, &leftover.ThawRemindedAt, &leftover.FinishedAt,
+		&leftover.CreatedAt, &leftover.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &leftover, nil
+}
@@ -0,0 +1,168 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Meal request operations
+
+const mealRequestColumns = `id, household_id, requested_by, recipe_id, title, COALESCE(notes, ''), status,
+	decided_by, decided_at, meal_plan_id, planned_date, COALESCE(meal_type, ''), created_at, updated_at`
+
+// CreateMealRequest adds a request to a household's board
+func (db *PostgresDB) CreateMealRequest(ctx context.Context, request *database.MealRequest) error {
+	query := `
+		INSERT INTO meal_requests (id, household_id, requested_by, recipe_id, title, notes, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err := db.conn(ctx).Exec(ctx, query,
+		request.ID, request.HouseholdID, request.RequestedBy, request.RecipeID, request.Title, request.Notes,
+		request.Status, request.CreatedAt, request.UpdatedAt,
+	)
+	return err
+}
+
+// GetMealRequestByID retrieves a meal request by ID
+func (db *PostgresDB) GetMealRequestByID(ctx context.Context, id string) (*database.MealRequest, error) {
+	query := `SELECT ` + mealRequestColumns + ` FROM meal_requests WHERE id = $1`
+	return scanMealRequest(db.conn(ctx).QueryRow(ctx, query, id))
+}
+
+// ListMealRequests lists a household's meal requests, most upvoted first
+// and then oldest first
+func (db *PostgresDB) ListMealRequests(ctx context.Context, filter database.MealRequestFilter) ([]*database.MealRequest, error) {
+	args := []interface{}{database.MealRequestVoteUp, filter.HouseholdID}
+	conditions := []string{"household_id = $2"}
+
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+
+	query := `SELECT ` + mealRequestColumns + ` FROM meal_requests
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY (SELECT COUNT(*) FROM meal_request_votes v WHERE v.request_id = meal_requests.id AND v.vote = $1) DESC,
+			created_at, id`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit, filter.Offset)
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	}
+
+	rows, err := db.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := []*database.MealRequest{}
+	for rows.Next() {
+		request, err := scanMealRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, request)
+	}
+	return requests, rows.Err()
+}
+
+// UpdateMealRequest saves a meal request's details and decision
+func (db *PostgresDB) UpdateMealRequest(ctx context.Context, request *database.MealRequest) error {
+	query := `
+		UPDATE meal_requests
+		SET recipe_id = $1, title = $2, notes = $3, status = $4, decided_by = $5, decided_at = $6,
+			meal_plan_id = $7, planned_date = $8, meal_type = $9, updated_at = $10
+		WHERE id = $11
+	`
+	_, err := db.conn(ctx).Exec(ctx, query,
+		request.RecipeID, request.Title, request.Notes, request.Status, request.DecidedBy, request.DecidedAt,
+		request.MealPlanID, request.PlannedDate, request.MealType, request.UpdatedAt, request.ID,
+	)
+	return err
+}
+
+// DeleteMealRequest removes a meal request and its votes
+func (db *PostgresDB) DeleteMealRequest(ctx context.Context, id string) error {
+	_, err := db.conn(ctx).Exec(ctx, `DELETE FROM meal_requests WHERE id = $1`, id)
+	return err
+}
+
+// UpsertMealRequestVote records a member's vote, replacing any earlier one
+func (db *PostgresDB) UpsertMealRequestVote(ctx context.Context, vote *database.MealRequestVote) error {
+	query := `
+		INSERT INTO meal_request_votes (request_id, user_id, vote, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (request_id, user_id) DO UPDATE
+		SET vote = EXCLUDED.vote, reason = EXCLUDED.reason, created_at = EXCLUDED.created_at
+	`
+	_, err := db.conn(ctx).Exec(ctx, query, vote.RequestID, vote.UserID, vote.Vote, vote.Reason, vote.CreatedAt)
+	return err
+}
+
+// DeleteMealRequestVote withdraws a member's vote
+func (db *PostgresDB) DeleteMealRequestVote(ctx context.Context, requestID, userID string) error {
+	_, err := db.conn(ctx).Exec(ctx,
+		`DELETE FROM meal_request_votes WHERE request_id = $1 AND user_id = $2`, requestID, userID)
+	return err
+}
+
+// ListMealRequestVotes returns the votes on the given requests, oldest
+// first, by request ID
+func (db *PostgresDB) ListMealRequestVotes(ctx context.Context, requestIDs []string) (map[string][]*database.MealRequestVote, error) {
+	votes := map[string][]*database.MealRequestVote{}
+	if len(requestIDs) == 0 {
+		return votes, nil
+	}
+
+	query := `SELECT request_id, user_id, vote, COALESCE(reason, ''), created_at FROM meal_request_votes
+		WHERE request_id = ANY($1)
+		ORDER BY request_id, created_at, user_id`
+	rows, err := db.conn(ctx).Query(ctx, query, requestIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var vote database.MealRequestVote
+		if err := rows.Scan(&vote.RequestID, &vote.UserID, &vote.Vote, &vote.Reason, &vote.CreatedAt); err != nil {
+			return nil, err
+		}
+		votes[vote.RequestID] = append(votes[vote.RequestID], &vote)
+	}
+	return votes, rows.Err()
+}
+
+func scanMealRequest(row interface{ Scan(dest ...any) error }) (*database.MealRequest, error) {
+	var request database.MealRequest
+	err := row.Scan(
+		&request.ID, &request.HouseholdID, &request.RequestedBy, &request.RecipeID, &request.Title, &request.Notes,
+		&request.Status, &request.DecidedBy, &request.DecidedAt, &request.MealPlanID, &request.PlannedDate,
+		&request.MealType, &request.CreatedAt, &request.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
@@ -0,0 +1,151 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Instance metric operations
+
+// RecordRecipeImport records the outcome of fetching a recipe page
+func (db *PostgresDB) RecordRecipeImport(ctx context.Context, attempt *database.RecipeImportAttempt) error {
+	query := `
+		INSERT INTO recipe_import_attempts (id, domain, succeeded, created_at)
+		VALUES ($1, $2, $3, $4)
+	`
+	_, err := db.conn(ctx).Exec(ctx, query, attempt.ID, attempt.Domain, attempt.Succeeded, attempt.CreatedAt)
+	return err
+}
+
+// CountRecipeImports tallies import attempts between start and end by
+// site, most failures first
+func (db *PostgresDB) CountRecipeImports(ctx context.Context, start, end time.Time) ([]*database.RecipeImportCount, error) {
+	query := `
+		SELECT domain, COUNT(*), SUM(CASE WHEN succeeded THEN 0 ELSE 1 END) AS failures
+		FROM recipe_import_attempts
+		WHERE created_at >= $1 AND created_at < $2
+		GROUP BY domain
+		ORDER BY failures DESC, domain
+	`
+	rows, err := db.conn(ctx).Query(ctx, query, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []*database.RecipeImportCount{}
+	for rows.Next() {
+		var count database.RecipeImportCount
+		if err := rows.Scan(&count.Domain, &count.Attempts, &count.Failures); err != nil {
+			return nil, err
+		}
+		counts = append(counts, &count)
+	}
+	return counts, rows.Err()
+}
+
+// PurgeRecipeImports removes import attempts made before the given time
+func (db *PostgresDB) PurgeRecipeImports(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := db.conn(ctx).Exec(ctx, `DELETE FROM recipe_import_attempts WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// CountActiveUsers counts the users who signed in, refreshed a session or
+// used an API token between start and end. Only the latest use of each is
+// kept, so a period is counted accurately only until it ends.
+func (db *PostgresDB) CountActiveUsers(ctx context.Context, start, end time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*) FROM users u
+		WHERE (u.last_login_at >= $1 AND u.last_login_at < $2)
+			OR EXISTS (SELECT 1 FROM auth_sessions s WHERE s.user_id = u.id AND s.last_used_at >= $1 AND s.last_used_at < $2)
+			OR EXISTS (SELECT 1 FROM api_tokens t WHERE t.user_id = u.id AND t.last_used_at >= $1 AND t.last_used_at < $2)
+	`
+	var n int
+	err := db.conn(ctx).QueryRow(ctx, query, start, end).Scan(&n)
+	return n, err
+}
+
+// CountCookingSessions counts the cooking sessions started between start
+// and end, and those completed in that time
+func (db *PostgresDB) CountCookingSessions(ctx context.Context, start, end time.Time) (int, int, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM cooking_sessions WHERE started_at >= $1 AND started_at < $2),
+			(SELECT COUNT(*) FROM cooking_sessions WHERE status = $3 AND finished_at >= $1 AND finished_at < $2)
+	`
+	var started, completed int
+	err := db.conn(ctx).QueryRow(ctx, query, start, end, database.CookingSessionCompleted).Scan(&started, &completed)
+	return started, completed, err
+}
+
+// ReplaceInstanceMetrics replaces a period's rows for the named metrics
+func (db *PostgresDB) ReplaceInstanceMetrics(ctx context.Context, period string, names []string, metrics []*database.InstanceMetric) error {
+	if len(names) == 0 {
+		return nil
+	}
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		_, err := db.conn(ctx).Exec(ctx,
+			`DELETE FROM instance_metrics WHERE period = $1 AND metric = ANY($2)`, period, names)
+		if err != nil {
+			return err
+		}
+		for _, metric := range metrics {
+			_, err := db.conn(ctx).Exec(ctx,
+				`INSERT INTO instance_metrics (period, metric, dimension, value, updated_at) VALUES ($1, $2, $3, $4, $5)`,
+				metric.Period, metric.Metric, metric.Dimension, metric.Value, metric.UpdatedAt)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListInstanceMetrics returns the rolled-up figures for the given periods
+func (db *PostgresDB) ListInstanceMetrics(ctx context.Context, periods []string) ([]*database.InstanceMetric, error) {
+	metrics := []*database.InstanceMetric{}
+	if len(periods) == 0 {
+		return metrics, nil
+	}
+
+	query := `SELECT period, metric, dimension, value, updated_at FROM instance_metrics
+		WHERE period = ANY($1)
+		ORDER BY period, metric, dimension`
+	rows, err := db.conn(ctx).Query(ctx, query, periods)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var metric database.InstanceMetric
+		if err := rows.Scan(&metric.Period, &metric.Metric, &metric.Dimension, &metric.Value, &metric.UpdatedAt); err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, &metric)
+	}
+	return metrics, rows.Err()
+}
@@ -0,0 +1,69 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+	"embed"
+
+	migratedb "github.com/golang-migrate/migrate/v4/database"
+	migratepgx "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/rghsoftware/space-food/internal/database/migrate"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var migrations = migrate.MustLoad(migrationFiles, "migrations")
+
+// Migrations returns the migrator for this database
+func (db *PostgresDB) Migrations() *migrate.Migrator {
+	return migrate.New(migrationDriver{db}, migrations)
+}
+
+// Migrate applies pending migrations, refusing when the schema is newer
+// than this build
+func (db *PostgresDB) Migrate(ctx context.Context) error {
+	return db.Migrations().Up(ctx)
+}
+
+// migrationDriver lets golang-migrate record applied migrations in
+// schema_migrations
+type migrationDriver struct {
+	db *PostgresDB
+}
+
+func (d migrationDriver) HasTables(ctx context.Context) (bool, error) {
+	var exists bool
+	err := d.db.conn(ctx).QueryRow(ctx, `SELECT to_regclass('users') IS NOT NULL`).Scan(&exists)
+	return exists, err
+}
+
+// Open borrows a connection from the pool for the library, which holds it
+// for PostgreSQL's advisory lock; closing the driver returns it
+func (d migrationDriver) Open(ctx context.Context) (migratedb.Driver, func() error, error) {
+	sqlDB := stdlib.OpenDBFromPool(d.db.pool)
+	driver, err := migratepgx.WithInstance(sqlDB, &migratepgx.Config{})
+	if err != nil {
+		sqlDB.Close()
+		return nil, nil, err
+	}
+	return driver, driver.Close, nil
+}
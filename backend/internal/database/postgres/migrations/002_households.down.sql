@@ -0,0 +1,5 @@
+-- Reverts: Households, membership and invitations
+
+DROP TABLE IF EXISTS household_invitations;
+DROP TABLE IF EXISTS household_members;
+DROP TABLE IF EXISTS households;
@@ -0,0 +1,45 @@
+-- Households, membership and invitations
+
+-- Households table
+CREATE TABLE households (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_households_owner_id ON households(owner_id);
+
+-- Household members junction table
+CREATE TABLE household_members (
+    household_id UUID NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(50) NOT NULL DEFAULT 'member', -- owner, admin, member
+    joined_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (household_id, user_id)
+);
+
+CREATE INDEX idx_household_members_user_id ON household_members(user_id);
+
+-- Household invitations table
+CREATE TABLE household_invitations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    household_id UUID NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    invited_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email VARCHAR(255),
+    code_hash VARCHAR(64) UNIQUE NOT NULL,
+    role VARCHAR(50) NOT NULL DEFAULT 'member',
+    status VARCHAR(50) NOT NULL DEFAULT 'pending', -- pending, accepted, declined, revoked
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    responded_at TIMESTAMP WITH TIME ZONE,
+    responded_by UUID REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_household_invitations_household_id ON household_invitations(household_id);
+CREATE INDEX idx_household_invitations_email ON household_invitations(email);
+CREATE INDEX idx_household_invitations_status ON household_invitations(status);
+
+CREATE TRIGGER update_households_updated_at BEFORE UPDATE ON households
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
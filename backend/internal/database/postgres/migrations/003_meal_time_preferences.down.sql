@@ -0,0 +1,3 @@
+-- Reverts: Per-user meal windows for time-of-day aware suggestions
+
+DROP TABLE IF EXISTS meal_time_preferences;
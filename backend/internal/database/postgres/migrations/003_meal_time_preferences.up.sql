@@ -0,0 +1,9 @@
+-- Per-user meal windows for time-of-day aware suggestions
+
+CREATE TABLE meal_time_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    timezone VARCHAR(100) NOT NULL DEFAULT 'UTC',
+    windows JSONB NOT NULL DEFAULT '[]',
+    breakfast_all_day BOOLEAN DEFAULT FALSE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
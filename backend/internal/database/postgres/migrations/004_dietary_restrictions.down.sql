@@ -0,0 +1,3 @@
+-- Reverts: Per-user allergies, intolerances and dietary preferences
+
+DROP TABLE IF EXISTS dietary_restrictions;
@@ -0,0 +1,14 @@
+-- Per-user allergies, intolerances and dietary preferences
+
+CREATE TABLE dietary_restrictions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(50) NOT NULL, -- allergy, intolerance, preference
+    name VARCHAR(100) NOT NULL,
+    severity VARCHAR(50),
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, kind, name)
+);
+
+CREATE INDEX idx_dietary_restrictions_user_id ON dietary_restrictions(user_id);
@@ -0,0 +1,11 @@
+-- Accounts at external identity providers (OIDC) linked to local users
+
+CREATE TABLE user_identities (
+    provider VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (provider, subject)
+);
+
+CREATE INDEX idx_user_identities_user_id ON user_identities(user_id);
@@ -0,0 +1,4 @@
+-- Reverts: Instance administration
+
+DROP TABLE IF EXISTS instance_settings;
+ALTER TABLE users DROP COLUMN is_admin;
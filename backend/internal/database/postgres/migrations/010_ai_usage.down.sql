@@ -0,0 +1,3 @@
+-- Reverts: Monthly AI usage per user and provider, for budgets and reporting
+
+DROP TABLE IF EXISTS ai_usage;
@@ -0,0 +1,15 @@
+-- Monthly AI usage per user and provider, for budgets and reporting
+
+CREATE TABLE ai_usage (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider VARCHAR(50) NOT NULL,
+    period CHAR(7) NOT NULL, -- YYYY-MM
+    requests BIGINT DEFAULT 0,
+    input_tokens BIGINT DEFAULT 0,
+    output_tokens BIGINT DEFAULT 0,
+    cost DECIMAL(12, 6) DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, provider, period)
+);
+
+CREATE INDEX idx_ai_usage_period ON ai_usage(period);
@@ -0,0 +1,14 @@
+-- Cached AI outputs keyed by a hash of their prompt inputs
+
+CREATE TABLE ai_cache (
+    cache_key VARCHAR(128) PRIMARY KEY,
+    kind VARCHAR(50) NOT NULL,
+    recipe_id UUID REFERENCES recipes(id) ON DELETE CASCADE,
+    payload JSONB NOT NULL,
+    hits BIGINT DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_ai_cache_recipe_id ON ai_cache(recipe_id);
+CREATE INDEX idx_ai_cache_expires_at ON ai_cache(expires_at);
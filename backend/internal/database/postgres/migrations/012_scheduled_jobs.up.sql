@@ -0,0 +1,24 @@
+-- Background job schedules and run history
+
+CREATE TABLE scheduled_jobs (
+    name VARCHAR(100) PRIMARY KEY,
+    schedule VARCHAR(100) NOT NULL,
+    next_run_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_run_at TIMESTAMP WITH TIME ZONE,
+    last_status VARCHAR(20)
+);
+
+CREATE TABLE job_runs (
+    id UUID PRIMARY KEY,
+    job_name VARCHAR(100) NOT NULL,
+    triggered_by VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    result TEXT,
+    error TEXT,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_job_runs_job_name ON job_runs(job_name, started_at);
+CREATE INDEX idx_job_runs_status ON job_runs(status);
+CREATE INDEX idx_job_runs_started_at ON job_runs(started_at);
@@ -0,0 +1,3 @@
+-- Reverts: Self-reported energy levels over time
+
+DROP TABLE IF EXISTS energy_checkins;
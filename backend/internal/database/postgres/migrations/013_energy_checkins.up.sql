@@ -0,0 +1,12 @@
+-- Self-reported energy levels over time
+
+CREATE TABLE energy_checkins (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    level SMALLINT NOT NULL CHECK (level BETWEEN 1 AND 5),
+    note TEXT,
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_energy_checkins_user_recorded ON energy_checkins(user_id, recorded_at);
@@ -0,0 +1,3 @@
+-- Reverts: Leftovers with estimated eat-by dates
+
+DROP TABLE IF EXISTS leftovers;
@@ -0,0 +1,21 @@
+-- Leftovers with estimated eat-by dates
+
+CREATE TABLE leftovers (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    recipe_id UUID REFERENCES recipes(id) ON DELETE SET NULL,
+    name VARCHAR(255) NOT NULL,
+    food_type VARCHAR(50) NOT NULL,
+    portions DECIMAL(6, 2) NOT NULL,
+    container VARCHAR(100),
+    storage VARCHAR(20) NOT NULL,
+    stored_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    eat_by TIMESTAMP WITH TIME ZONE NOT NULL,
+    notes TEXT,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_leftovers_user_id ON leftovers(user_id, finished_at);
+CREATE INDEX idx_leftovers_eat_by ON leftovers(eat_by);
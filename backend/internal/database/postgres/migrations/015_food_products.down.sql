@@ -0,0 +1,3 @@
+-- Reverts: Cache of packaged food products looked up by barcode
+
+DROP TABLE IF EXISTS food_products;
@@ -0,0 +1,16 @@
+-- Cache of packaged food products looked up by barcode
+
+CREATE TABLE food_products (
+    barcode VARCHAR(14) PRIMARY KEY,
+    found BOOLEAN NOT NULL,
+    source VARCHAR(50) NOT NULL,
+    name VARCHAR(255),
+    brand VARCHAR(255),
+    quantity VARCHAR(100),
+    serving_size VARCHAR(100),
+    image_url TEXT,
+    categories JSONB NOT NULL DEFAULT '[]',
+    nutrition_100g JSONB,
+    nutrition_serving JSONB,
+    fetched_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
@@ -0,0 +1,3 @@
+-- Reverts: Per-user sensory food preferences
+
+DROP TABLE IF EXISTS sensory_profiles;
@@ -0,0 +1,11 @@
+-- Per-user sensory food preferences
+
+CREATE TABLE sensory_profiles (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    avoid_textures JSONB NOT NULL DEFAULT '[]',
+    preferred_temperatures JSONB NOT NULL DEFAULT '[]',
+    smell_aversions JSONB NOT NULL DEFAULT '[]',
+    eating_style VARCHAR(20) NOT NULL DEFAULT 'no_preference',
+    notes TEXT,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,4 @@
+-- Reverts: Outgoing webhooks and their delivery log
+
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
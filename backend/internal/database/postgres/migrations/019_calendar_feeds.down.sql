@@ -0,0 +1,3 @@
+-- Reverts: Secret iCalendar subscription feeds
+
+DROP TABLE IF EXISTS calendar_feeds;
@@ -0,0 +1,4 @@
+-- Reverts: External calendars whose events block meal plan slots
+
+DROP TABLE IF EXISTS external_calendar_events;
+DROP TABLE IF EXISTS external_calendars;
@@ -0,0 +1,28 @@
+-- External calendars whose events block meal plan slots
+
+CREATE TABLE external_calendars (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    url TEXT NOT NULL,
+    meal_types JSONB NOT NULL DEFAULT '["dinner"]',
+    last_synced_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_external_calendars_user_id ON external_calendars(user_id);
+CREATE INDEX idx_external_calendars_synced ON external_calendars(last_synced_at);
+
+CREATE TABLE external_calendar_events (
+    calendar_id UUID NOT NULL REFERENCES external_calendars(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    uid TEXT NOT NULL,
+    summary TEXT,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_external_calendar_events_calendar ON external_calendar_events(calendar_id);
+CREATE INDEX idx_external_calendar_events_user_time ON external_calendar_events(user_id, starts_at, ends_at);
@@ -0,0 +1,6 @@
+-- Reverts: Indexes matching the newest-first keyset order of paginated lists
+
+CREATE INDEX idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, created_at);
+DROP INDEX IF EXISTS idx_webhook_deliveries_webhook_created;
+DROP INDEX IF EXISTS idx_job_runs_started_id;
+DROP INDEX IF EXISTS idx_recipes_user_created;
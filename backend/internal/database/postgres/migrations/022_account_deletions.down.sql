@@ -0,0 +1,3 @@
+-- Reverts: Accounts scheduled for deletion after a grace period
+
+DROP TABLE IF EXISTS account_deletions;
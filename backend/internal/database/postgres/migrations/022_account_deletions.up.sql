@@ -0,0 +1,9 @@
+-- Accounts scheduled for deletion after a grace period
+
+CREATE TABLE account_deletions (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    requested_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    delete_after TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_account_deletions_delete_after ON account_deletions(delete_after);
@@ -0,0 +1,3 @@
+-- Reverts: Saved versions of recipes, one per edit
+
+DROP TABLE IF EXISTS recipe_revisions;
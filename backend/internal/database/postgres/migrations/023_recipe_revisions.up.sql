@@ -0,0 +1,12 @@
+-- Saved versions of recipes, one per edit
+
+CREATE TABLE recipe_revisions (
+    id UUID PRIMARY KEY,
+    recipe_id UUID NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    restored_from INTEGER,
+    content JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    UNIQUE (recipe_id, revision)
+);
@@ -0,0 +1,3 @@
+-- Reverts: The version of the recipe each cached AI output was generated from
+
+ALTER TABLE ai_cache DROP COLUMN IF EXISTS recipe_updated_at;
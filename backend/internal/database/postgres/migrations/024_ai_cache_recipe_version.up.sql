@@ -0,0 +1,3 @@
+-- The version of the recipe each cached AI output was generated from
+
+ALTER TABLE ai_cache ADD COLUMN recipe_updated_at TIMESTAMP WITH TIME ZONE;
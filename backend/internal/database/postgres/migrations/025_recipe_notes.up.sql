@@ -0,0 +1,12 @@
+-- Per-user cook notes and standing modifications for recipes
+
+CREATE TABLE recipe_notes (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    recipe_id UUID NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    notes TEXT,
+    modifications JSONB NOT NULL DEFAULT '[]',
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, recipe_id)
+);
+
+CREATE INDEX idx_recipe_notes_recipe_id ON recipe_notes(recipe_id);
@@ -0,0 +1,3 @@
+-- Reverts: Secret links that publish a recipe for other Space Food instances to import
+
+DROP TABLE IF EXISTS recipe_shares;
@@ -0,0 +1,34 @@
+-- Cooking sessions that step through a recipe, with their timers
+
+CREATE TABLE cooking_sessions (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    recipe_id UUID REFERENCES recipes(id) ON DELETE SET NULL,
+    recipe_title VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    current_step INTEGER NOT NULL DEFAULT 0,
+    steps JSONB NOT NULL DEFAULT '[]',
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    paused_at TIMESTAMP WITH TIME ZONE,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_cooking_sessions_user_id ON cooking_sessions(user_id, status);
+
+CREATE TABLE cooking_timers (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    session_id UUID REFERENCES cooking_sessions(id) ON DELETE CASCADE,
+    step_index INTEGER,
+    label VARCHAR(255) NOT NULL,
+    duration_seconds INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE,
+    remaining_seconds INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_cooking_timers_user_id ON cooking_timers(user_id, status);
+CREATE INDEX idx_cooking_timers_session_id ON cooking_timers(session_id);
@@ -0,0 +1,9 @@
+-- How a finished cooking session went: taste, would make again, photo and time taken
+
+ALTER TABLE cooking_sessions ADD COLUMN tasted TEXT;
+ALTER TABLE cooking_sessions ADD COLUMN would_make_again INTEGER;
+ALTER TABLE cooking_sessions ADD COLUMN photo_url TEXT;
+ALTER TABLE cooking_sessions ADD COLUMN actual_minutes INTEGER;
+ALTER TABLE cooking_sessions ADD COLUMN reflected_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_cooking_sessions_recipe_id ON cooking_sessions(recipe_id);
@@ -0,0 +1,3 @@
+-- How hard each cook found a recipe, for effort scores
+
+ALTER TABLE cooking_sessions ADD COLUMN difficulty INTEGER;
@@ -0,0 +1,7 @@
+-- Reverts: Grocery prices paid or seen per store, shopping list prices and monthly budgets
+
+DROP TABLE IF EXISTS grocery_budgets;
+DROP TABLE IF EXISTS grocery_prices;
+
+ALTER TABLE shopping_list_items DROP COLUMN IF EXISTS store;
+ALTER TABLE shopping_list_items DROP COLUMN IF EXISTS price;
@@ -0,0 +1,28 @@
+-- Grocery prices paid or seen per store, shopping list prices and monthly budgets
+
+ALTER TABLE shopping_list_items ADD COLUMN price DECIMAL(10, 2);
+ALTER TABLE shopping_list_items ADD COLUMN store TEXT;
+
+CREATE TABLE grocery_prices (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    store TEXT NOT NULL DEFAULT '',
+    name TEXT NOT NULL,
+    quantity DECIMAL(10, 2) NOT NULL DEFAULT 1,
+    unit TEXT NOT NULL DEFAULT '',
+    price DECIMAL(10, 2) NOT NULL,
+    purchased BOOLEAN NOT NULL DEFAULT FALSE,
+    shopping_item_id UUID REFERENCES shopping_list_items(id) ON DELETE SET NULL,
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_grocery_prices_user_id ON grocery_prices(user_id, recorded_at);
+CREATE INDEX idx_grocery_prices_shopping_item_id ON grocery_prices(shopping_item_id);
+
+CREATE TABLE grocery_budgets (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    monthly_limit DECIMAL(10, 2) NOT NULL,
+    currency TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,18 @@
+-- Kitchen equipment each household owns, and the equipment recipes need
+
+CREATE TABLE household_equipment (
+    id UUID PRIMARY KEY,
+    household_id UUID NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    notes TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_household_equipment_name ON household_equipment(household_id, name);
+
+CREATE TABLE recipe_equipment (
+    recipe_id UUID NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    PRIMARY KEY (recipe_id, name)
+);
@@ -0,0 +1,6 @@
+-- Reverts: Frozen batch-cooked portions: reheat instructions and thaw reminders
+
+DROP INDEX IF EXISTS idx_leftovers_thaw_at;
+ALTER TABLE leftovers DROP COLUMN IF EXISTS thaw_reminded_at;
+ALTER TABLE leftovers DROP COLUMN IF EXISTS thaw_at;
+ALTER TABLE leftovers DROP COLUMN IF EXISTS reheat;
@@ -0,0 +1,7 @@
+-- Frozen batch-cooked portions: reheat instructions and thaw reminders
+
+ALTER TABLE leftovers ADD COLUMN reheat TEXT;
+ALTER TABLE leftovers ADD COLUMN thaw_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE leftovers ADD COLUMN thaw_reminded_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_leftovers_thaw_at ON leftovers(thaw_at);
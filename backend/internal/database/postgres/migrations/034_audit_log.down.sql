@@ -0,0 +1,3 @@
+-- Reverts: Audit trail of sensitive actions: sign-ins, membership changes, deletions, exports and administration
+
+DROP TABLE IF EXISTS audit_events;
@@ -0,0 +1,17 @@
+-- Audit trail of sensitive actions: sign-ins, membership changes, deletions, exports and administration
+
+CREATE TABLE audit_events (
+    id UUID PRIMARY KEY,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(100) NOT NULL,
+    target_type VARCHAR(50),
+    target_id TEXT,
+    details JSONB,
+    ip_address VARCHAR(64),
+    user_agent TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_audit_events_created_at ON audit_events(created_at);
+CREATE INDEX idx_audit_events_user_id ON audit_events(user_id, created_at);
+CREATE INDEX idx_audit_events_action ON audit_events(action, created_at);
@@ -0,0 +1,3 @@
+-- Reverts: Responses to requests sent with an Idempotency-Key, replayed when a client retries
+
+DROP TABLE IF EXISTS idempotency_keys;
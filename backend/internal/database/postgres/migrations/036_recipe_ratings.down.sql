@@ -0,0 +1,3 @@
+-- Reverts: Star ratings users give recipes, shared with their households
+
+DROP TABLE IF EXISTS recipe_ratings;
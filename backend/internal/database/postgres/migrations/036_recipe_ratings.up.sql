@@ -0,0 +1,11 @@
+-- Star ratings users give recipes, shared with their households
+
+CREATE TABLE recipe_ratings (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    recipe_id UUID NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    stars SMALLINT NOT NULL CHECK (stars BETWEEN 1 AND 5),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (user_id, recipe_id)
+);
+
+CREATE INDEX idx_recipe_ratings_recipe_id ON recipe_ratings(recipe_id);
@@ -0,0 +1,3 @@
+-- Reverts: Per-user choice of the email they receive
+
+DROP TABLE IF EXISTS email_preferences;
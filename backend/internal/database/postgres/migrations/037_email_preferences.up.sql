@@ -0,0 +1,8 @@
+-- Per-user choice of the email they receive
+
+CREATE TABLE email_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    household_invites BOOLEAN NOT NULL DEFAULT TRUE,
+    weekly_digest BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,3 @@
+-- Reverts: Device handoff tokens
+
+DROP TABLE IF EXISTS handoff_tokens;
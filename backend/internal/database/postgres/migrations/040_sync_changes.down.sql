@@ -0,0 +1,9 @@
+-- Reverts: Change log for offline sync
+
+DROP TRIGGER IF EXISTS sync_recipes ON recipes;
+DROP TRIGGER IF EXISTS sync_meal_plans ON meal_plans;
+DROP TRIGGER IF EXISTS sync_shopping_list_items ON shopping_list_items;
+DROP TRIGGER IF EXISTS sync_cooking_sessions ON cooking_sessions;
+DROP FUNCTION IF EXISTS record_sync_change();
+DROP TABLE IF EXISTS sync_changes;
+DROP TABLE IF EXISTS sync_cursors;
@@ -0,0 +1,82 @@
+-- Change log for offline sync
+--
+-- Triggers keep one row per changed record: its owner, the record and
+-- whether it was deleted. Each user's changes are numbered by seq, which
+-- moves forward every time the record changes again, so reading the rows
+-- after a client's last seq gives everything it hasn't seen.
+
+CREATE TABLE sync_cursors (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    seq BIGINT NOT NULL DEFAULT 0,
+    purged_seq BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE sync_changes (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    seq BIGINT NOT NULL,
+    entity VARCHAR(32) NOT NULL,
+    entity_id UUID NOT NULL,
+    deleted BOOLEAN NOT NULL DEFAULT FALSE,
+    changed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, seq)
+);
+
+CREATE UNIQUE INDEX idx_sync_changes_entity ON sync_changes(user_id, entity, entity_id);
+CREATE INDEX idx_sync_changes_tombstones ON sync_changes(changed_at) WHERE deleted;
+
+-- Records changed before sync existed
+INSERT INTO sync_changes (user_id, seq, entity, entity_id)
+SELECT user_id, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY updated_at, entity, id), entity, id
+FROM (
+    SELECT user_id, 'recipe' AS entity, id, updated_at FROM recipes
+    UNION ALL SELECT user_id, 'meal_plan', id, updated_at FROM meal_plans
+    UNION ALL SELECT user_id, 'shopping_list_item', id, updated_at FROM shopping_list_items
+    UNION ALL SELECT user_id, 'cooking_session', id, updated_at FROM cooking_sessions
+) AS existing;
+
+INSERT INTO sync_cursors (user_id, seq)
+SELECT user_id, MAX(seq) FROM sync_changes GROUP BY user_id;
+
+-- Records a change to the row in the table named by the trigger's
+-- argument. Numbering a change updates the owner's cursor row, which stays
+-- locked until the transaction commits, so each user's changes are
+-- numbered in the order they become visible. Deletes leave a tombstone,
+-- except for records removed along with their owner's account.
+CREATE OR REPLACE FUNCTION record_sync_change()
+RETURNS TRIGGER AS $$
+DECLARE
+    changed RECORD;
+    next_seq BIGINT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        changed := OLD;
+    ELSE
+        changed := NEW;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM users WHERE id = changed.user_id) THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO sync_cursors (user_id, seq) VALUES (changed.user_id, 1)
+    ON CONFLICT (user_id) DO UPDATE SET seq = sync_cursors.seq + 1
+    RETURNING sync_cursors.seq INTO next_seq;
+
+    INSERT INTO sync_changes (user_id, seq, entity, entity_id, deleted, changed_at)
+    VALUES (changed.user_id, next_seq, TG_ARGV[0], changed.id, TG_OP = 'DELETE', CURRENT_TIMESTAMP)
+    ON CONFLICT (user_id, entity, entity_id) DO UPDATE
+    SET seq = EXCLUDED.seq, deleted = EXCLUDED.deleted, changed_at = EXCLUDED.changed_at;
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER sync_recipes AFTER INSERT OR UPDATE OR DELETE ON recipes
+    FOR EACH ROW EXECUTE FUNCTION record_sync_change('recipe');
+
+CREATE TRIGGER sync_meal_plans AFTER INSERT OR UPDATE OR DELETE ON meal_plans
+    FOR EACH ROW EXECUTE FUNCTION record_sync_change('meal_plan');
+
+CREATE TRIGGER sync_shopping_list_items AFTER INSERT OR UPDATE OR DELETE ON shopping_list_items
+    FOR EACH ROW EXECUTE FUNCTION record_sync_change('shopping_list_item');
+
+CREATE TRIGGER sync_cooking_sessions AFTER INSERT OR UPDATE OR DELETE ON cooking_sessions
+    FOR EACH ROW EXECUTE FUNCTION record_sync_change('cooking_session');
@@ -0,0 +1,3 @@
+-- Reverts: The cook's own notes on a cooking session in progress
+
+ALTER TABLE cooking_sessions DROP COLUMN IF EXISTS notes;
@@ -0,0 +1,4 @@
+-- Reverts: Recipes translated into other languages, and each user's preferred language
+
+DROP TABLE IF EXISTS user_locales;
+DROP TABLE IF EXISTS recipe_translations;
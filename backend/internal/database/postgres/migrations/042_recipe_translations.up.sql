@@ -0,0 +1,22 @@
+-- Recipes translated into other languages, and each user's preferred language
+
+CREATE TABLE recipe_translations (
+    recipe_id UUID NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    language VARCHAR(35) NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    instructions TEXT NOT NULL DEFAULT '',
+    ingredients JSONB NOT NULL DEFAULT '[]',
+    machine_translated BOOLEAN NOT NULL DEFAULT FALSE,
+    model TEXT NOT NULL DEFAULT '',
+    source_updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (recipe_id, language)
+);
+
+CREATE TABLE user_locales (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    locale VARCHAR(35) NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,4 @@
+-- Reverts: Allergens and diets detected from recipe ingredients, with the author's overrides
+
+DROP TABLE IF EXISTS recipe_dietary_tags;
+DROP TABLE IF EXISTS recipe_dietary_analyses;
@@ -0,0 +1,22 @@
+-- Allergens and diets detected from recipe ingredients, with the author's overrides
+
+CREATE TABLE recipe_dietary_analyses (
+    recipe_id UUID PRIMARY KEY REFERENCES recipes(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    source_updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    analyzed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE recipe_dietary_tags (
+    recipe_id UUID NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    tag VARCHAR(50) NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    detected BOOLEAN NOT NULL DEFAULT FALSE,
+    confidence REAL NOT NULL DEFAULT 0,
+    ingredients JSONB NOT NULL DEFAULT '[]',
+    override BOOLEAN,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (recipe_id, tag)
+);
+
+CREATE INDEX idx_recipe_dietary_tags_tag ON recipe_dietary_tags(tag);
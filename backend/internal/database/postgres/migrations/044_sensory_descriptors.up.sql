@@ -0,0 +1,11 @@
+-- Spice level and sensory cues of recipe steps, and what a user avoids
+
+CREATE TABLE recipe_sensory_tags (
+    recipe_id UUID PRIMARY KEY REFERENCES recipes(id) ON DELETE CASCADE,
+    spice_level INTEGER,
+    steps JSONB NOT NULL DEFAULT '[]',
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE sensory_profiles ADD COLUMN max_spice_level INTEGER;
+ALTER TABLE sensory_profiles ADD COLUMN avoid_cues JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,4 @@
+-- Reverts: Meals household members propose, and their votes on them
+
+DROP TABLE IF EXISTS meal_request_votes;
+DROP TABLE IF EXISTS meal_requests;
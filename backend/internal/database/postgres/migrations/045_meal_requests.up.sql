@@ -0,0 +1,29 @@
+-- Meals household members propose, and their votes on them
+
+CREATE TABLE meal_requests (
+    id UUID PRIMARY KEY,
+    household_id UUID NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    requested_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    recipe_id UUID REFERENCES recipes(id) ON DELETE SET NULL,
+    title TEXT NOT NULL,
+    notes TEXT,
+    status TEXT NOT NULL DEFAULT 'open',
+    decided_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    decided_at TIMESTAMP WITH TIME ZONE,
+    meal_plan_id UUID REFERENCES meal_plans(id) ON DELETE SET NULL,
+    planned_date DATE,
+    meal_type TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_meal_requests_household ON meal_requests(household_id, status);
+
+CREATE TABLE meal_request_votes (
+    request_id UUID NOT NULL REFERENCES meal_requests(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    vote TEXT NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (request_id, user_id)
+);
@@ -0,0 +1,4 @@
+-- Reverts: Recipe import outcomes by site, and figures rolled up for the admin dashboard
+
+DROP TABLE IF EXISTS instance_metrics;
+DROP TABLE IF EXISTS recipe_import_attempts;
@@ -0,0 +1,3 @@
+-- Reverts: Record the AI prompt each machine translation was made with
+
+ALTER TABLE recipe_translations DROP COLUMN IF EXISTS prompt_id;
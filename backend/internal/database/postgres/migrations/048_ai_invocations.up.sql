@@ -0,0 +1,19 @@
+-- Every call to an AI provider, with its tokens, latency and estimated cost
+
+CREATE TABLE ai_invocations (
+    id UUID PRIMARY KEY,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    feature VARCHAR(50) NOT NULL DEFAULT '',
+    provider VARCHAR(50) NOT NULL,
+    model TEXT NOT NULL DEFAULT '',
+    input_tokens BIGINT NOT NULL DEFAULT 0,
+    output_tokens BIGINT NOT NULL DEFAULT 0,
+    latency_ms BIGINT NOT NULL DEFAULT 0,
+    cost DOUBLE PRECISION NOT NULL DEFAULT 0,
+    outcome VARCHAR(20) NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_ai_invocations_user_id ON ai_invocations(user_id, created_at);
+CREATE INDEX idx_ai_invocations_created_at ON ai_invocations(created_at);
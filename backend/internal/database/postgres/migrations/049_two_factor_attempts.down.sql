@@ -0,0 +1,4 @@
+-- Reverts: Count second-factor attempts so codes cannot be guessed without limit
+
+ALTER TABLE user_totp DROP COLUMN IF EXISTS locked_until;
+ALTER TABLE user_totp DROP COLUMN IF EXISTS failed_attempts;
@@ -0,0 +1,83 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Password reset token operations
+
+// CreatePasswordResetToken stores a new password reset token
+func (db *PostgresDB) CreatePasswordResetToken(ctx context.Context, token *database.PasswordResetToken) error {
+	query := `
+		INSERT INTO password_reset_tokens (id, user_id, token_hash, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err := db.conn(ctx).Exec(ctx, query,
+		token.ID, token.UserID, token.TokenHash, token.CreatedAt, token.ExpiresAt,
+	)
+	return err
+}
+
+// GetPasswordResetTokenByHash retrieves a password reset token by the hash
+// of its secret
+func (db *PostgresDB) GetPasswordResetTokenByHash(ctx context.Context, tokenHash string) (*database.PasswordResetToken, error) {
+	query := `
+		SELECT id, user_id, token_hash, created_at, expires_at, used_at
+		FROM password_reset_tokens WHERE token_hash = $1
+	`
+	var token database.PasswordResetToken
+	err := db.conn(ctx).QueryRow(ctx, query, tokenHash).Scan(
+		&token.ID, &token.UserID, &token.TokenHash, &token.CreatedAt, &token.ExpiresAt, &token.UsedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// UsePasswordResetToken marks an unused token as used, reporting whether
+// it was still unused
+func (db *PostgresDB) UsePasswordResetToken(ctx context.Context, id string, usedAt time.Time) (bool, error) {
+	query := `UPDATE password_reset_tokens SET used_at = $2 WHERE id = $1 AND used_at IS NULL`
+	tag, err := db.conn(ctx).Exec(ctx, query, id, usedAt)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// DeletePasswordResetTokens removes every password reset token of a user
+func (db *PostgresDB) DeletePasswordResetTokens(ctx context.Context, userID string) error {
+	_, err := db.conn(ctx).Exec(ctx, `DELETE FROM password_reset_tokens WHERE user_id = $1`, userID)
+	return err
+}
+
+// PurgePasswordResetTokens removes tokens that expired before a time
+func (db *PostgresDB) PurgePasswordResetTokens(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := db.conn(ctx).Exec(ctx, `DELETE FROM password_reset_tokens WHERE expires_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -23,7 +23,6 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rghsoftware/space-food/internal/database"
 )
@@ -44,6 +43,9 @@ func NewPostgresDB(connString string, maxConns, minConns int) (*PostgresDB, erro
 	config.MaxConns = int32(maxConns)
 	config.MinConns = int32(minConns)
 
+	// Time every query for traces and metrics
+	config.ConnConfig.Tracer = queryTracer{}
+
 	return &PostgresDB{config: config}, nil
 }
 
@@ -73,27 +75,31 @@ func (db *PostgresDB) Health(ctx context.Context) error {
 	return db.pool.Ping(ctx)
 }
 
-// Migrate runs database migrations
-func (db *PostgresDB) Migrate(ctx context.Context) error {
-	// Migration logic will be implemented
-	return nil
-}
-
-// BeginTx starts a new transaction
-func (db *PostgresDB) BeginTx(ctx context.Context) (database.Transaction, error) {
-	// Transaction logic will be implemented
-	return nil, fmt.Errorf("not implemented")
+// PoolStats reports the connection pool's state
+func (db *PostgresDB) PoolStats() database.PoolStats {
+	if db.pool == nil {
+		return database.PoolStats{}
+	}
+	stat := db.pool.Stat()
+	return database.PoolStats{
+		MaxConns:     int(stat.MaxConns()),
+		OpenConns:    int(stat.TotalConns()),
+		InUseConns:   int(stat.AcquiredConns()),
+		IdleConns:    int(stat.IdleConns()),
+		WaitCount:    stat.EmptyAcquireCount(),
+		WaitDuration: stat.AcquireDuration(),
+	}
 }
 
 // CreateUser creates a new user
 func (db *PostgresDB) CreateUser(ctx context.Context, user *database.User) error {
 	query := `
-		INSERT INTO users (id, email, password_hash, first_name, last_name, created_at, updated_at, email_verified, active)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO users (id, email, password_hash, first_name, last_name, created_at, updated_at, email_verified, active, is_admin, must_change_password)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
-	_, err := db.pool.Exec(ctx, query,
+	_, err := db.conn(ctx).Exec(ctx, query,
 		user.ID, user.Email, user.PasswordHash, user.FirstName, user.LastName,
-		user.CreatedAt, user.UpdatedAt, user.EmailVerified, user.Active,
+		user.CreatedAt, user.UpdatedAt, user.EmailVerified, user.Active, user.IsAdmin, user.MustChangePassword,
 	)
 	return err
 }
@@ -101,13 +107,13 @@ func (db *PostgresDB) CreateUser(ctx context.Context, user *database.User) error
 // GetUserByID retrieves a user by ID
 func (db *PostgresDB) GetUserByID(ctx context.Context, id string) (*database.User, error) {
 	query := `
-		SELECT id, email, password_hash, first_name, last_name, created_at, updated_at, last_login_at, email_verified, active
+		SELECT id, email, password_hash, first_name, last_name, created_at, updated_at, last_login_at, email_verified, active, is_admin, must_change_password
 		FROM users WHERE id = $1
 	`
 	var user database.User
-	err := db.pool.QueryRow(ctx, query, id).Scan(
+	err := db.conn(ctx).QueryRow(ctx, query, id).Scan(
 		&user.ID, &user.Email, &user.PasswordHash, &user.FirstName, &user.LastName,
-		&user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt, &user.EmailVerified, &user.Active,
+		&user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt, &user.EmailVerified, &user.Active, &user.IsAdmin, &user.MustChangePassword,
 	)
 	if err != nil {
 		return nil, err
@@ -118,13 +124,13 @@ func (db *PostgresDB) GetUserByID(ctx context.Context, id string) (*database.Use
 // GetUserByEmail retrieves a user by email
 func (db *PostgresDB) GetUserByEmail(ctx context.Context, email string) (*database.User, error) {
 	query := `
-		SELECT id, email, password_hash, first_name, last_name, created_at, updated_at, last_login_at, email_verified, active
+		SELECT id, email, password_hash, first_name, last_name, created_at, updated_at, last_login_at, email_verified, active, is_admin, must_change_password
 		FROM users WHERE email = $1
 	`
 	var user database.User
-	err := db.pool.QueryRow(ctx, query, email).Scan(
+	err := db.conn(ctx).QueryRow(ctx, query, email).Scan(
 		&user.ID, &user.Email, &user.PasswordHash, &user.FirstName, &user.LastName,
-		&user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt, &user.EmailVerified, &user.Active,
+		&user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt, &user.EmailVerified, &user.Active, &user.IsAdmin, &user.MustChangePassword,
 	)
 	if err != nil {
 		return nil, err
@@ -137,12 +143,13 @@ func (db *PostgresDB) UpdateUser(ctx context.Context, user *database.User) error
 	query := `
 		UPDATE users
 		SET email = $2, password_hash = $3, first_name = $4, last_name = $5,
-		    updated_at = $6, last_login_at = $7, email_verified = $8, active = $9
+		    updated_at = $6, last_login_at = $7, email_verified = $8, active = $9, is_admin = $10,
+		    must_change_password = $11
 		WHERE id = $1
 	`
-	_, err := db.pool.Exec(ctx, query,
+	_, err := db.conn(ctx).Exec(ctx, query,
 		user.ID, user.Email, user.PasswordHash, user.FirstName, user.LastName,
-		user.UpdatedAt, user.LastLoginAt, user.EmailVerified, user.Active,
+		user.UpdatedAt, user.LastLoginAt, user.EmailVerified, user.Active, user.IsAdmin, user.MustChangePassword,
 	)
 	return err
 }
@@ -150,7 +157,7 @@ func (db *PostgresDB) UpdateUser(ctx context.Context, user *database.User) error
 // DeleteUser deletes a user
 func (db *PostgresDB) DeleteUser(ctx context.Context, id string) error {
 	query := `DELETE FROM users WHERE id = $1`
-	_, err := db.pool.Exec(ctx, query, id)
+	_, err := db.conn(ctx).Exec(ctx, query, id)
 	return err
 }
 
@@ -177,6 +184,11 @@ func (db *PostgresDB) UpdateRecipe(ctx context.Context, recipe *database.Recipe)
 	return fmt.Errorf("not implemented")
 }
 
+// UpdateRecipeIfUnchanged updates a recipe unless it was saved after savedAt
+func (db *PostgresDB) UpdateRecipeIfUnchanged(ctx context.Context, recipe *database.Recipe, savedAt time.Time) (bool, error) {
+	return false, fmt.Errorf("not implemented")
+}
+
 // DeleteRecipe deletes a recipe
 func (db *PostgresDB) DeleteRecipe(ctx context.Context, id string) error {
 	return fmt.Errorf("not implemented")
@@ -209,6 +221,11 @@ func (db *PostgresDB) UpdateMealPlan(ctx context.Context, plan *database.MealPla
 	return fmt.Errorf("not implemented")
 }
 
+// UpdateMealPlanIfUnchanged updates a meal plan unless it was saved after savedAt
+func (db *PostgresDB) UpdateMealPlanIfUnchanged(ctx context.Context, plan *database.MealPlan, savedAt time.Time) (bool, error) {
+	return false, fmt.Errorf("not implemented")
+}
+
 // DeleteMealPlan deletes a meal plan
 func (db *PostgresDB) DeleteMealPlan(ctx context.Context, id string) error {
 	return fmt.Errorf("not implemented")
@@ -0,0 +1,172 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Recipe dietary tag operations
+
+const recipeDietaryTagColumns = `recipe_id, tag, kind, detected, confidence, ingredients, override, updated_at`
+
+// GetRecipeDietaryAnalysis retrieves when a recipe was last analyzed
+func (db *PostgresDB) GetRecipeDietaryAnalysis(ctx context.Context, recipeID string) (*database.RecipeDietaryAnalysis, error) {
+	query := `SELECT recipe_id, version, source_updated_at, analyzed_at FROM recipe_dietary_analyses WHERE recipe_id = $1`
+	var analysis database.RecipeDietaryAnalysis
+	err := db.conn(ctx).QueryRow(ctx, query, recipeID).Scan(
+		&analysis.RecipeID, &analysis.Version, &analysis.SourceUpdatedAt, &analysis.AnalyzedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &analysis, nil
+}
+
+// ListRecipeDietaryAnalysesIn returns when the given recipes were last
+// analyzed, by recipe ID, leaving out those never analyzed
+func (db *PostgresDB) ListRecipeDietaryAnalysesIn(ctx context.Context, recipeIDs []string) (map[string]*database.RecipeDietaryAnalysis, error) {
+	analyses := map[string]*database.RecipeDietaryAnalysis{}
+	if len(recipeIDs) == 0 {
+		return analyses, nil
+	}
+
+	query := `SELECT recipe_id, version, source_updated_at, analyzed_at FROM recipe_dietary_analyses
+		WHERE recipe_id = ANY($1)`
+	rows, err := db.conn(ctx).Query(ctx, query, recipeIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var analysis database.RecipeDietaryAnalysis
+		if err := rows.Scan(&analysis.RecipeID, &analysis.Version, &analysis.SourceUpdatedAt, &analysis.AnalyzedAt); err != nil {
+			return nil, err
+		}
+		analyses[analysis.RecipeID] = &analysis
+	}
+	return analyses, rows.Err()
+}
+
+// UpsertRecipeDietaryAnalysis records that a recipe was analyzed
+func (db *PostgresDB) UpsertRecipeDietaryAnalysis(ctx context.Context, analysis *database.RecipeDietaryAnalysis) error {
+	query := `
+		INSERT INTO recipe_dietary_analyses (recipe_id, version, source_updated_at, analyzed_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (recipe_id) DO UPDATE
+		SET version = EXCLUDED.version, source_updated_at = EXCLUDED.source_updated_at, analyzed_at = EXCLUDED.analyzed_at
+	`
+	_, err := db.conn(ctx).Exec(ctx, query,
+		analysis.RecipeID, analysis.Version, analysis.SourceUpdatedAt.UTC(), analysis.AnalyzedAt.UTC(),
+	)
+	return err
+}
+
+// ListRecipeDietaryTags lists a recipe's dietary tags, allergens first
+func (db *PostgresDB) ListRecipeDietaryTags(ctx context.Context, recipeID string) ([]*database.RecipeDietaryTag, error) {
+	query := `SELECT ` + recipeDietaryTagColumns + ` FROM recipe_dietary_tags WHERE recipe_id = $1 ORDER BY kind, tag`
+	rows, err := db.conn(ctx).Query(ctx, query, recipeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []*database.RecipeDietaryTag{}
+	for rows.Next() {
+		tag, err := scanRecipeDietaryTag(rows)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// ListRecipeDietaryTagsIn returns the dietary tags of the given recipes,
+// by recipe ID
+func (db *PostgresDB) ListRecipeDietaryTagsIn(ctx context.Context, recipeIDs []string) (map[string][]*database.RecipeDietaryTag, error) {
+	tags := map[string][]*database.RecipeDietaryTag{}
+	if len(recipeIDs) == 0 {
+		return tags, nil
+	}
+
+	query := `SELECT ` + recipeDietaryTagColumns + ` FROM recipe_dietary_tags
+		WHERE recipe_id = ANY($1) ORDER BY kind, tag`
+	rows, err := db.conn(ctx).Query(ctx, query, recipeIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		tag, err := scanRecipeDietaryTag(rows)
+		if err != nil {
+			return nil, err
+		}
+		tags[tag.RecipeID] = append(tags[tag.RecipeID], tag)
+	}
+	return tags, rows.Err()
+}
+
+// UpsertRecipeDietaryTag creates or replaces one of a recipe's dietary tags
+func (db *PostgresDB) UpsertRecipeDietaryTag(ctx context.Context, tag *database.RecipeDietaryTag) error {
+	ingredients, err := json.Marshal(tag.Ingredients)
+	if err != nil {
+		return fmt.Errorf("failed to encode dietary tag ingredients: %w", err)
+	}
+
+	query := `
+		INSERT INTO recipe_dietary_tags (recipe_id, tag, kind, detected, confidence, ingredients, override, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (recipe_id, tag) DO UPDATE
+		SET kind = EXCLUDED.kind, detected = EXCLUDED.detected, confidence = EXCLUDED.confidence,
+		    ingredients = EXCLUDED.ingredients, override = EXCLUDED.override, updated_at = EXCLUDED.updated_at
+	`
+	_, err = db.conn(ctx).Exec(ctx, query,
+		tag.RecipeID, tag.Tag, tag.Kind, tag.Detected, tag.Confidence, ingredients, tag.Override, tag.UpdatedAt.UTC(),
+	)
+	return err
+}
+
+// DeleteRecipeDietaryTag deletes one of a recipe's dietary tags
+func (db *PostgresDB) DeleteRecipeDietaryTag(ctx context.Context, recipeID, tag string) error {
+	query := `DELETE FROM recipe_dietary_tags WHERE recipe_id = $1 AND tag = $2`
+	_, err := db.conn(ctx).Exec(ctx, query, recipeID, tag)
+	return err
+}
+
+func scanRecipeDietaryTag(row interface{ Scan(dest ...any) error }) (*database.RecipeDietaryTag, error) {
+	var tag database.RecipeDietaryTag
+	var ingredients []byte
+	err := row.Scan(
+		&tag.RecipeID, &tag.Tag, &tag.Kind, &tag.Detected, &tag.Confidence, &ingredients, &tag.Override, &tag.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(ingredients, &tag.Ingredients); err != nil {
+		return nil, fmt.Errorf("failed to decode dietary tag ingredients: %w", err)
+	}
+	return &tag, nil
+}
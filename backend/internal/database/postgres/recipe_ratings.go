@@ -0,0 +1,99 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Recipe rating operations
+
+// UpsertRecipeRating creates or replaces a user's rating of a recipe
+func (db *PostgresDB) UpsertRecipeRating(ctx context.Context, rating *database.RecipeRating) error {
+	query := `
+		INSERT INTO recipe_ratings (user_id, recipe_id, stars, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, recipe_id) DO UPDATE
+		SET stars = EXCLUDED.stars, updated_at = EXCLUDED.updated_at
+	`
+	_, err := db.conn(ctx).Exec(ctx, query, rating.UserID, rating.RecipeID, rating.Stars, rating.UpdatedAt)
+	return err
+}
+
+// DeleteRecipeRating deletes a user's rating of a recipe
+func (db *PostgresDB) DeleteRecipeRating(ctx context.Context, userID, recipeID string) error {
+	query := `DELETE FROM recipe_ratings WHERE user_id = $1 AND recipe_id = $2`
+	_, err := db.conn(ctx).Exec(ctx, query, userID, recipeID)
+	return err
+}
+
+// ListRecipeRatings lists the ratings the given users gave the given
+// recipes
+func (db *PostgresDB) ListRecipeRatings(ctx context.Context, recipeIDs, userIDs []string) ([]*database.RecipeRating, error) {
+	query := `
+		SELECT user_id, recipe_id, stars, updated_at
+		FROM recipe_ratings
+		WHERE recipe_id = ANY($1) AND user_id = ANY($2)
+	`
+	rows, err := db.conn(ctx).Query(ctx, query, recipeIDs, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ratings := []*database.RecipeRating{}
+	for rows.Next() {
+		var rating database.RecipeRating
+		if err := rows.Scan(&rating.UserID, &rating.RecipeID, &rating.Stars, &rating.UpdatedAt); err != nil {
+			return nil, err
+		}
+		ratings = append(ratings, &rating)
+	}
+	return ratings, rows.Err()
+}
+
+// ListRecipesMade counts the cooking sessions of the given recipes the
+// given users completed. Recipes none of them made are left out.
+func (db *PostgresDB) ListRecipesMade(ctx context.Context, recipeIDs, userIDs []string) (map[string]database.RecipeMade, error) {
+	query := `
+		SELECT recipe_id, COUNT(*), MAX(finished_at)
+		FROM cooking_sessions
+		WHERE status = $1 AND finished_at IS NOT NULL
+		  AND recipe_id = ANY($2) AND user_id = ANY($3)
+		GROUP BY recipe_id
+	`
+	rows, err := db.conn(ctx).Query(ctx, query, database.CookingSessionCompleted, recipeIDs, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	made := map[string]database.RecipeMade{}
+	for rows.Next() {
+		var recipeID string
+		var m database.RecipeMade
+		if err := rows.Scan(&recipeID, &m.Count, &m.LastAt); err != nil {
+			return nil, err
+		}
+		made[recipeID] = m
+	}
+	return made, rows.Err()
+}
@@ -0,0 +1,82 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Recipe sensory tag operations
+
+// ListRecipeSensoryTags returns what the authors of the given recipes have
+// tagged them with, by recipe ID
+func (db *PostgresDB) ListRecipeSensoryTags(ctx context.Context, recipeIDs []string) (map[string]*database.RecipeSensoryTags, error) {
+	tagged := map[string]*database.RecipeSensoryTags{}
+	if len(recipeIDs) == 0 {
+		return tagged, nil
+	}
+
+	query := `SELECT recipe_id, spice_level, steps, updated_at FROM recipe_sensory_tags
+		WHERE recipe_id = ANY($1)`
+	rows, err := db.conn(ctx).Query(ctx, query, recipeIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tags database.RecipeSensoryTags
+		var steps []byte
+		if err := rows.Scan(&tags.RecipeID, &tags.SpiceLevel, &steps, &tags.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(steps, &tags.Steps); err != nil {
+			return nil, fmt.Errorf("failed to decode recipe sensory tags: %w", err)
+		}
+		tagged[tags.RecipeID] = &tags
+	}
+	return tagged, rows.Err()
+}
+
+// UpsertRecipeSensoryTags creates or replaces a recipe's sensory tags
+func (db *PostgresDB) UpsertRecipeSensoryTags(ctx context.Context, tags *database.RecipeSensoryTags) error {
+	steps, err := json.Marshal(tags.Steps)
+	if err != nil {
+		return fmt.Errorf("failed to encode recipe sensory tags: %w", err)
+	}
+
+	query := `
+		INSERT INTO recipe_sensory_tags (recipe_id, spice_level, steps, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (recipe_id) DO UPDATE
+		SET spice_level = EXCLUDED.spice_level, steps = EXCLUDED.steps, updated_at = EXCLUDED.updated_at
+	`
+	_, err = db.conn(ctx).Exec(ctx, query, tags.RecipeID, tags.SpiceLevel, steps, tags.UpdatedAt)
+	return err
+}
+
+// DeleteRecipeSensoryTags removes a recipe's sensory tags
+func (db *PostgresDB) DeleteRecipeSensoryTags(ctx context.Context, recipeID string) error {
+	_, err := db.conn(ctx).Exec(ctx, `DELETE FROM recipe_sensory_tags WHERE recipe_id = $1`, recipeID)
+	return err
+}
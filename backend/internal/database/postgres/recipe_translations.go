@@ -0,0 +1,133 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Recipe translation operations
+
+const recipeTranslationColumns = `recipe_id, language, title, description, instructions, ingredients,
+	machine_translated, model, prompt_id, source_updated_at, created_at, updated_at`
+
+// GetRecipeTranslation retrieves a recipe's translation into a language
+func (db *PostgresDB) GetRecipeTranslation(ctx context.Context, recipeID, language string) (*database.RecipeTranslation, error) {
+	query := `SELECT ` + recipeTranslationColumns + ` FROM recipe_translations WHERE recipe_id = $1 AND language = $2`
+	return scanRecipeTranslation(db.conn(ctx).QueryRow(ctx, query, recipeID, language))
+}
+
+// ListRecipeTranslations lists a recipe's translations by language
+func (db *PostgresDB) ListRecipeTranslations(ctx context.Context, recipeID string) ([]*database.RecipeTranslation, error) {
+	query := `SELECT ` + recipeTranslationColumns + ` FROM recipe_translations WHERE recipe_id = $1 ORDER BY language`
+	rows, err := db.conn(ctx).Query(ctx, query, recipeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	translations := []*database.RecipeTranslation{}
+	for rows.Next() {
+		translation, err := scanRecipeTranslation(rows)
+		if err != nil {
+			return nil, err
+		}
+		translations = append(translations, translation)
+	}
+	return translations, rows.Err()
+}
+
+// ListRecipeTranslationsIn returns the translations of the given recipes
+// into a language, by recipe ID
+func (db *PostgresDB) ListRecipeTranslationsIn(ctx context.Context, recipeIDs []string, language string) (map[string]*database.RecipeTranslation, error) {
+	translations := map[string]*database.RecipeTranslation{}
+	if len(recipeIDs) == 0 {
+		return translations, nil
+	}
+
+	query := `SELECT ` + recipeTranslationColumns + ` FROM recipe_translations
+		WHERE language = $1 AND recipe_id = ANY($2)`
+	rows, err := db.conn(ctx).Query(ctx, query, language, recipeIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		translation, err := scanRecipeTranslation(rows)
+		if err != nil {
+			return nil, err
+		}
+		translations[translation.RecipeID] = translation
+	}
+	return translations, rows.Err()
+}
+
+// UpsertRecipeTranslation creates or replaces a recipe's translation into
+// a language
+func (db *PostgresDB) UpsertRecipeTranslation(ctx context.Context, translation *database.RecipeTranslation) error {
+	ingredients, err := json.Marshal(translation.Ingredients)
+	if err != nil {
+		return fmt.Errorf("failed to encode translated ingredients: %w", err)
+	}
+
+	query := `
+		INSERT INTO recipe_translations (recipe_id, language, title, description, instructions, ingredients,
+		                                 machine_translated, model, prompt_id, source_updated_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (recipe_id, language) DO UPDATE
+		SET title = EXCLUDED.title, description = EXCLUDED.description, instructions = EXCLUDED.instructions,
+		    ingredients = EXCLUDED.ingredients, machine_translated = EXCLUDED.machine_translated,
+		    model = EXCLUDED.model, prompt_id = EXCLUDED.prompt_id, source_updated_at = EXCLUDED.source_updated_at, updated_at = EXCLUDED.updated_at
+	`
+	_, err = db.conn(ctx).Exec(ctx, query,
+		translation.RecipeID, translation.Language, translation.Title, translation.Description,
+		translation.Instructions, ingredients, translation.MachineTranslated, translation.Model,
+		translation.PromptID, translation.SourceUpdatedAt.UTC(), translation.CreatedAt.UTC(), translation.UpdatedAt.UTC(),
+	)
+	return err
+}
+
+// DeleteRecipeTranslation deletes a recipe's translation into a language
+func (db *PostgresDB) DeleteRecipeTranslation(ctx context.Context, recipeID, language string) error {
+	query := `DELETE FROM recipe_translations WHERE recipe_id = $1 AND language = $2`
+	_, err := db.conn(ctx).Exec(ctx, query, recipeID, language)
+	return err
+}
+
+func scanRecipeTranslation(row interface{ Scan(dest ...any) error }) (*database.RecipeTranslation, error) {
+	var translation database.RecipeTranslation
+	var ingredients []byte
+	err := row.Scan(
+		&translation.RecipeID, &translation.Language, &translation.Title, &translation.Description,
+		&translation.Instructions, &ingredients, &translation.MachineTranslated, &translation.Model,
+		&translation.PromptID, &translation.SourceUpdatedAt, &translation.CreatedAt, &translation.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(ingredients, &translation.Ingredients); err != nil {
+		return nil, fmt.Errorf("failed to decode translated ingredients: %w", err)
+	}
+	return &translation, nil
+}
@@ -0,0 +1,104 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Sensory profile operations
+
+// GetSensoryProfile retrieves a user's sensory profile
+func (db *PostgresDB) GetSensoryProfile(ctx context.Context, userID string) (*database.SensoryProfile, error) {
+	query := `
+		SELECT user_id, avoid_textures, preferred_temperatures, smell_aversions, max_spice_level,
+		       avoid_cues, eating_style, COALESCE(notes, ''), updated_at
+		FROM sensory_profiles WHERE user_id = $1
+	`
+	var profile database.SensoryProfile
+	var textures, temperatures, smells, cues []byte
+	err := db.conn(ctx).QueryRow(ctx, query, userID).Scan(
+		&profile.UserID, &textures, &temperatures, &smells, &profile.MaxSpiceLevel,
+		&cues, &profile.EatingStyle, &profile.Notes, &profile.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range []struct {
+		data []byte
+		dest *[]string
+	}{
+		{textures, &profile.AvoidTextures},
+		{temperatures, &profile.PreferredTemperatures},
+		{smells, &profile.SmellAversions},
+		{cues, &profile.AvoidCues},
+	} {
+		if err := json.Unmarshal(field.data, field.dest); err != nil {
+			return nil, fmt.Errorf("failed to decode sensory profile: %w", err)
+		}
+	}
+	return &profile, nil
+}
+
+// UpsertSensoryProfile creates or replaces a user's sensory profile
+func (db *PostgresDB) UpsertSensoryProfile(ctx context.Context, profile *database.SensoryProfile) error {
+	textures, err := json.Marshal(profile.AvoidTextures)
+	if err != nil {
+		return fmt.Errorf("failed to encode sensory profile: %w", err)
+	}
+	temperatures, err := json.Marshal(profile.PreferredTemperatures)
+	if err != nil {
+		return fmt.Errorf("failed to encode sensory profile: %w", err)
+	}
+	smells, err := json.Marshal(profile.SmellAversions)
+	if err != nil {
+		return fmt.Errorf("failed to encode sensory profile: %w", err)
+	}
+	cues, err := json.Marshal(profile.AvoidCues)
+	if err != nil {
+		return fmt.Errorf("failed to encode sensory profile: %w", err)
+	}
+
+	query := `
+		INSERT INTO sensory_profiles (user_id, avoid_textures, preferred_temperatures, smell_aversions,
+		                              max_spice_level, avoid_cues, eating_style, notes, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (user_id) DO UPDATE
+		SET avoid_textures = EXCLUDED.avoid_textures, preferred_temperatures = EXCLUDED.preferred_temperatures,
+		    smell_aversions = EXCLUDED.smell_aversions, max_spice_level = EXCLUDED.max_spice_level,
+		    avoid_cues = EXCLUDED.avoid_cues, eating_style = EXCLUDED.eating_style,
+		    notes = EXCLUDED.notes, updated_at = EXCLUDED.updated_at
+	`
+	_, err = db.conn(ctx).Exec(ctx, query,
+		profile.UserID, textures, temperatures, smells, profile.MaxSpiceLevel,
+		cues, profile.EatingStyle, profile.Notes, profile.UpdatedAt,
+	)
+	return err
+}
+
+// DeleteSensoryProfile deletes a user's sensory profile
+func (db *PostgresDB) DeleteSensoryProfile(ctx context.Context, userID string) error {
+	query := `DELETE FROM sensory_profiles WHERE user_id = $1`
+	_, err := db.conn(ctx).Exec(ctx, query, userID)
+	return err
+}
@@ -0,0 +1,130 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Auth session operations
+
+const authSessionColumns = `
+	id, user_id, refresh_token_hash, COALESCE(user_agent, ''), COALESCE(ip_address, ''),
+	created_at, last_used_at, expires_at, revoked_at
+`
+
+func scanAuthSession(row interface{ Scan(dest ...any) error }) (*database.AuthSession, error) {
+	var session database.AuthSession
+	err := row.Scan(
+		&session.ID, &session.UserID, &session.RefreshTokenHash, &session.UserAgent, &session.IPAddress,
+		&session.CreatedAt, &session.LastUsedAt, &session.ExpiresAt, &session.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// CreateAuthSession creates a new auth session
+func (db *PostgresDB) CreateAuthSession(ctx context.Context, session *database.AuthSession) error {
+	query := `
+		INSERT INTO auth_sessions (id, user_id, refresh_token_hash, user_agent, ip_address, created_at, last_used_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := db.conn(ctx).Exec(ctx, query,
+		session.ID, session.UserID, session.RefreshTokenHash, session.UserAgent, session.IPAddress,
+		session.CreatedAt, session.LastUsedAt, session.ExpiresAt,
+	)
+	return err
+}
+
+// GetAuthSessionByID retrieves an auth session by ID
+func (db *PostgresDB) GetAuthSessionByID(ctx context.Context, id string) (*database.AuthSession, error) {
+	query := `SELECT ` + authSessionColumns + ` FROM auth_sessions WHERE id = $1`
+	return scanAuthSession(db.conn(ctx).QueryRow(ctx, query, id))
+}
+
+// ListActiveAuthSessions lists a user's sessions that are neither revoked nor expired
+func (db *PostgresDB) ListActiveAuthSessions(ctx context.Context, userID string) ([]*database.AuthSession, error) {
+	query := `SELECT ` + authSessionColumns + `
+		FROM auth_sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2
+		ORDER BY last_used_at DESC
+	`
+	rows, err := db.conn(ctx).Query(ctx, query, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []*database.AuthSession{}
+	for rows.Next() {
+		session, err := scanAuthSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// UpdateAuthSession updates the rotating fields of an auth session
+func (db *PostgresDB) UpdateAuthSession(ctx context.Context, session *database.AuthSession) error {
+	query := `
+		UPDATE auth_sessions
+		SET refresh_token_hash = $2, last_used_at = $3, expires_at = $4, revoked_at = $5
+		WHERE id = $1
+	`
+	_, err := db.conn(ctx).Exec(ctx, query,
+		session.ID, session.RefreshTokenHash, session.LastUsedAt, session.ExpiresAt, session.RevokedAt,
+	)
+	return err
+}
+
+// RotateAuthSession replaces the refresh token hash of an active session
+// still holding oldHash, reporting whether it did. Concurrent callers
+// presenting the same refresh token cannot both succeed.
+func (db *PostgresDB) RotateAuthSession(ctx context.Context, session *database.AuthSession, oldHash string) (bool, error) {
+	query := `
+		UPDATE auth_sessions
+		SET refresh_token_hash = $2, last_used_at = $3, expires_at = $4
+		WHERE id = $1 AND refresh_token_hash = $5 AND revoked_at IS NULL
+	`
+	tag, err := db.conn(ctx).Exec(ctx, query,
+		session.ID, session.RefreshTokenHash, session.LastUsedAt, session.ExpiresAt, oldHash,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// RevokeAuthSessions revokes all of a user's active sessions, optionally keeping one
+func (db *PostgresDB) RevokeAuthSessions(ctx context.Context, userID string, exceptSessionID string) error {
+	query := `
+		UPDATE auth_sessions
+		SET revoked_at = $3
+		WHERE user_id = $1 AND revoked_at IS NULL AND id::text <> $2
+	`
+	_, err := db.conn(ctx).Exec(ctx, query, userID, exceptSessionID, time.Now())
+	return err
+}
@@ -0,0 +1,121 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Store layout operations
+
+const storeLayoutColumns = `id, household_id, name, categories, is_default, created_by, created_at, updated_at`
+
+// CreateStoreLayout creates a household's store layout
+func (db *PostgresDB) CreateStoreLayout(ctx context.Context, layout *database.StoreLayout) error {
+	categories, err := json.Marshal(layout.Categories)
+	if err != nil {
+		return fmt.Errorf("failed to encode store layout: %w", err)
+	}
+	query := `
+		INSERT INTO store_layouts (id, household_id, name, categories, is_default, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err = db.conn(ctx).Exec(ctx, query,
+		layout.ID, layout.HouseholdID, layout.Name, categories, layout.IsDefault, layout.CreatedBy,
+		layout.CreatedAt, layout.UpdatedAt,
+	)
+	return err
+}
+
+// GetStoreLayoutByID retrieves a store layout by ID
+func (db *PostgresDB) GetStoreLayoutByID(ctx context.Context, id string) (*database.StoreLayout, error) {
+	query := `SELECT ` + storeLayoutColumns + ` FROM store_layouts WHERE id = $1`
+	return scanStoreLayout(db.conn(ctx).QueryRow(ctx, query, id))
+}
+
+// ListStoreLayouts lists the store layouts of the given households, by name
+func (db *PostgresDB) ListStoreLayouts(ctx context.Context, householdIDs []string) ([]*database.StoreLayout, error) {
+	if len(householdIDs) == 0 {
+		return []*database.StoreLayout{}, nil
+	}
+
+	query := `SELECT ` + storeLayoutColumns + ` FROM store_layouts
+		WHERE household_id = ANY($1)
+		ORDER BY name, household_id`
+	rows, err := db.conn(ctx).Query(ctx, query, householdIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	layouts := []*database.StoreLayout{}
+	for rows.Next() {
+		layout, err := scanStoreLayout(rows)
+		if err != nil {
+			return nil, err
+		}
+		layouts = append(layouts, layout)
+	}
+	return layouts, rows.Err()
+}
+
+// UpdateStoreLayout updates a store layout's name and aisle order
+func (db *PostgresDB) UpdateStoreLayout(ctx context.Context, layout *database.StoreLayout) error {
+	categories, err := json.Marshal(layout.Categories)
+	if err != nil {
+		return fmt.Errorf("failed to encode store layout: %w", err)
+	}
+	query := `UPDATE store_layouts SET name = $1, categories = $2, updated_at = $3 WHERE id = $4`
+	_, err = db.conn(ctx).Exec(ctx, query, layout.Name, categories, layout.UpdatedAt, layout.ID)
+	return err
+}
+
+// SetDefaultStoreLayout makes a layout its household's default, and the
+// household's other layouts not
+func (db *PostgresDB) SetDefaultStoreLayout(ctx context.Context, householdID, id string) error {
+	_, err := db.conn(ctx).Exec(ctx,
+		`UPDATE store_layouts SET is_default = (id = $1) WHERE household_id = $2`, id, householdID)
+	return err
+}
+
+// DeleteStoreLayout deletes a store layout
+func (db *PostgresDB) DeleteStoreLayout(ctx context.Context, id string) error {
+	_, err := db.conn(ctx).Exec(ctx, `DELETE FROM store_layouts WHERE id = $1`, id)
+	return err
+}
+
+func scanStoreLayout(row interface{ Scan(dest ...any) error }) (*database.StoreLayout, error) {
+	var layout database.StoreLayout
+	var categories []byte
+	err := row.Scan(
+		&layout.ID, &layout.HouseholdID, &layout.Name, &categories, &layout.IsDefault, &layout.CreatedBy,
+		&layout.CreatedAt, &layout.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(categories, &layout.Categories); err != nil {
+		return nil, fmt.Errorf("failed to decode store layout: %w", err)
+	}
+	return &layout, nil
+}
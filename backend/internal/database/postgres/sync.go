@@ -0,0 +1,105 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Offline sync operations. The changes themselves are written by triggers.
+
+// ListSyncChanges lists a user's changes after a seq, oldest first
+func (db *PostgresDB) ListSyncChanges(ctx context.Context, userID string, after int64, limit int) ([]*database.SyncChange, error) {
+	query := `
+		SELECT user_id, seq, entity, entity_id, deleted, changed_at
+		FROM sync_changes WHERE user_id = $1 AND seq > $2
+		ORDER BY seq LIMIT $3
+	`
+	rows, err := db.conn(ctx).Query(ctx, query, userID, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []*database.SyncChange
+	for rows.Next() {
+		var change database.SyncChange
+		if err := rows.Scan(&change.UserID, &change.Seq, &change.Entity, &change.EntityID, &change.Deleted, &change.ChangedAt); err != nil {
+			return nil, err
+		}
+		changes = append(changes, &change)
+	}
+	return changes, rows.Err()
+}
+
+// GetSyncChange retrieves the latest change to one of a user's records
+func (db *PostgresDB) GetSyncChange(ctx context.Context, userID, entity, entityID string) (*database.SyncChange, error) {
+	query := `
+		SELECT user_id, seq, entity, entity_id, deleted, changed_at
+		FROM sync_changes WHERE user_id = $1 AND entity = $2 AND entity_id = $3
+	`
+	var change database.SyncChange
+	err := db.conn(ctx).QueryRow(ctx, query, userID, entity, entityID).Scan(
+		&change.UserID, &change.Seq, &change.Entity, &change.EntityID, &change.Deleted, &change.ChangedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// GetSyncCursor retrieves a user's change counter
+func (db *PostgresDB) GetSyncCursor(ctx context.Context, userID string) (*database.SyncCursor, error) {
+	query := `SELECT user_id, seq, purged_seq FROM sync_cursors WHERE user_id = $1`
+	var cursor database.SyncCursor
+	err := db.conn(ctx).QueryRow(ctx, query, userID).Scan(&cursor.UserID, &cursor.Seq, &cursor.PurgedSeq)
+	if err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+// PurgeSyncTombstones removes tombstones of records deleted before a time,
+// remembering the latest purged seq of each user
+func (db *PostgresDB) PurgeSyncTombstones(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	err := db.WithTx(ctx, func(ctx context.Context) error {
+		_, err := db.conn(ctx).Exec(ctx, `
+			UPDATE sync_cursors SET purged_seq = gone.seq
+			FROM (
+				SELECT user_id, MAX(seq) AS seq FROM sync_changes
+				WHERE deleted AND changed_at < $1 GROUP BY user_id
+			) AS gone
+			WHERE sync_cursors.user_id = gone.user_id
+		`, before)
+		if err != nil {
+			return err
+		}
+		tag, err := db.conn(ctx).Exec(ctx, `DELETE FROM sync_changes WHERE deleted AND changed_at < $1`, before)
+		if err != nil {
+			return err
+		}
+		purged = tag.RowsAffected()
+		return nil
+	})
+	return purged, err
+}
@@ -0,0 +1,72 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rghsoftware/space-food/internal/telemetry"
+)
+
+// maxStatementLength bounds the SQL recorded on query spans
+const maxStatementLength = 1000
+
+// queryTracer records a span and a latency sample for every query
+type queryTracer struct{}
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	operation string
+	start     time.Time
+	span      *telemetry.Span
+}
+
+// TraceQueryStart implements pgx.QueryTracer
+func (queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	operation := telemetry.SQLOperation(data.SQL)
+	ctx, span := telemetry.StartKind(ctx, "postgres "+operation, telemetry.KindClient)
+	if span != nil {
+		statement := strings.Join(strings.Fields(data.SQL), " ")
+		if len(statement) > maxStatementLength {
+			statement = statement[:maxStatementLength]
+		}
+		span.SetAttributes("db.system", "postgresql", "db.operation", operation, "db.statement", statement)
+	}
+	return context.WithValue(ctx, queryStartKey{}, &queryStart{operation: operation, start: time.Now(), span: span})
+}
+
+// TraceQueryEnd implements pgx.QueryTracer
+func (queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	qs, ok := ctx.Value(queryStartKey{}).(*queryStart)
+	if !ok {
+		return
+	}
+	err := data.Err
+	if errors.Is(err, pgx.ErrNoRows) {
+		// A lookup that finds nothing is an answer, not a failure
+		err = nil
+	}
+	telemetry.ObserveDBQuery(qs.operation, time.Since(qs.start), err)
+	qs.span.End(err)
+}
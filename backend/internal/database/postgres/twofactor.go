@@ -0,0 +1,156 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Two-factor authentication operations
+
+// GetUserTOTP retrieves a user's TOTP enrollment
+func (db *PostgresDB) GetUserTOTP(ctx context.Context, userID string) (*database.UserTOTP, error) {
+	query := `
+		SELECT user_id, secret, enabled, last_used_step, created_at, confirmed_at
+		FROM user_totp
+		WHERE user_id = $1
+	`
+	var totp database.UserTOTP
+	err := db.conn(ctx).QueryRow(ctx, query, userID).Scan(
+		&totp.UserID, &totp.Secret, &totp.Enabled, &totp.LastUsedStep, &totp.CreatedAt, &totp.ConfirmedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &totp, nil
+}
+
+// UpsertUserTOTP creates or replaces a user's TOTP enrollment
+func (db *PostgresDB) UpsertUserTOTP(ctx context.Context, totp *database.UserTOTP) error {
+	query := `
+		INSERT INTO user_totp (user_id, secret, enabled, last_used_step, created_at, confirmed_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id) DO UPDATE SET
+			secret = EXCLUDED.secret,
+			enabled = EXCLUDED.enabled,
+			last_used_step = EXCLUDED.last_used_step,
+			created_at = EXCLUDED.created_at,
+			confirmed_at = EXCLUDED.confirmed_at
+	`
+	_, err := db.conn(ctx).Exec(ctx, query,
+		totp.UserID, totp.Secret, totp.Enabled, totp.LastUsedStep, totp.CreatedAt, totp.ConfirmedAt,
+	)
+	return err
+}
+
+// DeleteUserTOTP removes a user's TOTP enrollment and recovery codes
+func (db *PostgresDB) DeleteUserTOTP(ctx context.Context, userID string) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		tx := db.conn(ctx)
+
+		if _, err := tx.Exec(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM user_totp WHERE user_id = $1`, userID); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// AdvanceTOTPStep records an accepted time step, reporting false when the
+// step (or a later one) was already used
+func (db *PostgresDB) AdvanceTOTPStep(ctx context.Context, userID string, step int64) (bool, error) {
+	query := `UPDATE user_totp SET last_used_step = $2 WHERE user_id = $1 AND last_used_step < $2`
+	tag, err := db.conn(ctx).Exec(ctx, query, userID, step)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ClaimTOTPAttempt counts an attempt at the user's second factor, reporting
+// false while attempts are locked. The attempt that reaches maxAttempts
+// without a reset locks further ones until lockUntil. Concurrent attempts
+// are counted one by one, so they cannot get past the limit together.
+func (db *PostgresDB) ClaimTOTPAttempt(ctx context.Context, userID string, now time.Time, maxAttempts int, lockUntil time.Time) (bool, error) {
+	query := `
+		UPDATE user_totp
+		SET failed_attempts = failed_attempts + 1,
+			locked_until = CASE WHEN failed_attempts + 1 >= $2 THEN $3::timestamptz ELSE NULL END
+		WHERE user_id = $1 AND (locked_until IS NULL OR locked_until <= $4)
+	`
+	tag, err := db.conn(ctx).Exec(ctx, query, userID, maxAttempts, lockUntil, now)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ResetTOTPAttempts clears the attempt count once a second factor was accepted
+func (db *PostgresDB) ResetTOTPAttempts(ctx context.Context, userID string) error {
+	query := `UPDATE user_totp SET failed_attempts = 0, locked_until = NULL WHERE user_id = $1`
+	_, err := db.conn(ctx).Exec(ctx, query, userID)
+	return err
+}
+
+// ReplaceRecoveryCodes replaces all of a user's recovery codes
+func (db *PostgresDB) ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		tx := db.conn(ctx)
+
+		if _, err := tx.Exec(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+			return err
+		}
+		for _, hash := range codeHashes {
+			if _, err := tx.Exec(ctx, `INSERT INTO user_recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, hash); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ConsumeRecoveryCode marks an unused recovery code as used, reporting
+// whether one matched
+func (db *PostgresDB) ConsumeRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error) {
+	query := `
+		UPDATE user_recovery_codes
+		SET used_at = $3
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+	tag, err := db.conn(ctx).Exec(ctx, query, userID, codeHash, time.Now())
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// CountRecoveryCodes counts a user's unused recovery codes
+func (db *PostgresDB) CountRecoveryCodes(ctx context.Context, userID string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM user_recovery_codes WHERE user_id = $1 AND used_at IS NULL`
+	err := db.conn(ctx).QueryRow(ctx, query, userID).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,247 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Webhook operations
+
+const webhookColumns = `id, user_id, url, secret, events, COALESCE(description, ''), active, created_at, updated_at`
+
+const webhookDeliveryColumns = `id, webhook_id, event_id, event_type, payload, status, attempts,
+	COALESCE(response_status, 0), COALESCE(last_error, ''), next_attempt_at, created_at, delivered_at`
+
+// CreateWebhook registers a webhook
+func (db *PostgresDB) CreateWebhook(ctx context.Context, webhook *database.Webhook) error {
+	events, err := json.Marshal(webhook.Events)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook events: %w", err)
+	}
+
+	query := `
+		INSERT INTO webhooks (id, user_id, url, secret, events, description, active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err = db.conn(ctx).Exec(ctx, query,
+		webhook.ID, webhook.UserID, webhook.URL, webhook.Secret, events, webhook.Description,
+		webhook.Active, webhook.CreatedAt, webhook.UpdatedAt,
+	)
+	return err
+}
+
+// GetWebhookByID retrieves a webhook by ID
+func (db *PostgresDB) GetWebhookByID(ctx context.Context, id string) (*database.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = $1`
+	return scanWebhook(db.conn(ctx).QueryRow(ctx, query, id))
+}
+
+// ListWebhooks lists a user's webhooks, oldest first
+func (db *PostgresDB) ListWebhooks(ctx context.Context, userID string) ([]*database.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE user_id = $1 ORDER BY created_at`
+	rows, err := db.conn(ctx).Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []*database.Webhook{}
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+// UpdateWebhook updates a webhook
+func (db *PostgresDB) UpdateWebhook(ctx context.Context, webhook *database.Webhook) error {
+	events, err := json.Marshal(webhook.Events)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook events: %w", err)
+	}
+
+	query := `
+		UPDATE webhooks
+		SET url = $2, secret = $3, events = $4, description = $5, active = $6, updated_at = $7
+		WHERE id = $1
+	`
+	_, err = db.conn(ctx).Exec(ctx, query,
+		webhook.ID, webhook.URL, webhook.Secret, events, webhook.Description, webhook.Active, webhook.UpdatedAt,
+	)
+	return err
+}
+
+// DeleteWebhook deletes a webhook and its delivery log
+func (db *PostgresDB) DeleteWebhook(ctx context.Context, id string) error {
+	_, err := db.conn(ctx).Exec(ctx, `DELETE FROM webhooks WHERE id = $1`, id)
+	return err
+}
+
+// CreateWebhookDelivery records a delivery before it is first attempted
+func (db *PostgresDB) CreateWebhookDelivery(ctx context.Context, delivery *database.WebhookDelivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (id, webhook_id, event_id, event_type, payload, status, attempts,
+		                                next_attempt_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err := db.conn(ctx).Exec(ctx, query,
+		delivery.ID, delivery.WebhookID, delivery.EventID, delivery.EventType, []byte(delivery.Payload),
+		delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.CreatedAt,
+	)
+	return err
+}
+
+// UpdateWebhookDelivery records the outcome of a delivery attempt
+func (db *PostgresDB) UpdateWebhookDelivery(ctx context.Context, delivery *database.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = $3, response_status = NULLIF($4, 0), last_error = $5,
+		    next_attempt_at = $6, delivered_at = $7
+		WHERE id = $1
+	`
+	_, err := db.conn(ctx).Exec(ctx, query,
+		delivery.ID, delivery.Status, delivery.Attempts, delivery.ResponseStatus, delivery.LastError,
+		delivery.NextAttemptAt, delivery.DeliveredAt,
+	)
+	return err
+}
+
+// ListWebhookDeliveries lists deliveries, newest first
+func (db *PostgresDB) ListWebhookDeliveries(ctx context.Context, filter database.WebhookDeliveryFilter) ([]*database.WebhookDelivery, error) {
+	where, args := webhookDeliveryConditions(filter)
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE ` + where
+	argPos := len(args) + 1
+
+	if filter.After != nil {
+		query += fmt.Sprintf(" AND (created_at, id) < ($%d, $%d)", argPos, argPos+1)
+		args = append(args, filter.After.Time, filter.After.ID)
+		argPos += 2
+	}
+
+	query += " ORDER BY created_at DESC, id DESC"
+
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argPos)
+		args = append(args, filter.Limit)
+		argPos++
+	}
+	if filter.Offset > 0 && filter.After == nil {
+		query += fmt.Sprintf(" OFFSET $%d", argPos)
+		args = append(args, filter.Offset)
+	}
+
+	return db.queryWebhookDeliveries(ctx, query, args...)
+}
+
+// CountWebhookDeliveries counts the deliveries matching a filter, ignoring its paging
+func (db *PostgresDB) CountWebhookDeliveries(ctx context.Context, filter database.WebhookDeliveryFilter) (int, error) {
+	where, args := webhookDeliveryConditions(filter)
+	var count int
+	err := db.conn(ctx).QueryRow(ctx, `SELECT COUNT(*) FROM webhook_deliveries WHERE `+where, args...).Scan(&count)
+	return count, err
+}
+
+// webhookDeliveryConditions builds the WHERE clause shared by listing and counting
+func webhookDeliveryConditions(filter database.WebhookDeliveryFilter) (string, []interface{}) {
+	where := "1=1"
+	args := []interface{}{}
+	if filter.WebhookID != "" {
+		args = append(args, filter.WebhookID)
+		where += fmt.Sprintf(" AND webhook_id = $%d", len(args))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		where += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	return where, args
+}
+
+// ListDueWebhookDeliveries lists pending deliveries whose next attempt is due
+func (db *PostgresDB) ListDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]*database.WebhookDelivery, error) {
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries
+		WHERE status = $1 AND next_attempt_at <= $2
+		ORDER BY next_attempt_at
+		LIMIT $3
+	`
+	return db.queryWebhookDeliveries(ctx, query, database.WebhookDeliveryPending, now, limit)
+}
+
+// PurgeWebhookDeliveries deletes settled deliveries created before the cutoff
+func (db *PostgresDB) PurgeWebhookDeliveries(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := db.conn(ctx).Exec(ctx,
+		`DELETE FROM webhook_deliveries WHERE created_at < $1 AND status <> $2`,
+		before, database.WebhookDeliveryPending,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (db *PostgresDB) queryWebhookDeliveries(ctx context.Context, query string, args ...any) ([]*database.WebhookDelivery, error) {
+	rows, err := db.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []*database.WebhookDelivery{}
+	for rows.Next() {
+		var delivery database.WebhookDelivery
+		var payload []byte
+		if err := rows.Scan(
+			&delivery.ID, &delivery.WebhookID, &delivery.EventID, &delivery.EventType, &payload,
+			&delivery.Status, &delivery.Attempts, &delivery.ResponseStatus, &delivery.LastError,
+			&delivery.NextAttemptAt, &delivery.CreatedAt, &delivery.DeliveredAt,
+		); err != nil {
+			return nil, err
+		}
+		delivery.Payload = payload
+		deliveries = append(deliveries, &delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+func scanWebhook(row pgx.Row) (*database.Webhook, error) {
+	var webhook database.Webhook
+	var events []byte
+	err := row.Scan(
+		&webhook.ID, &webhook.UserID, &webhook.URL, &webhook.Secret, &events,
+		&webhook.Description, &webhook.Active, &webhook.CreatedAt, &webhook.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(events, &webhook.Events); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook events: %w", err)
+	}
+	return &webhook, nil
+}
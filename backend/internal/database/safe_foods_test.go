@@ -0,0 +1,125 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package database_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/database/dbtest"
+)
+
+func newSafeFood(userID, name string) *database.SafeFood {
+	now := time.Now().UTC().Truncate(time.Second)
+	return &database.SafeFood{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func TestSafeFoods(t *testing.T) {
+	dbtest.Run(t, func(ctx context.Context, db database.Database) {
+		user := dbtest.User(t, ctx, db)
+		other := dbtest.User(t, ctx, db)
+
+		toast := newSafeFood(user.ID, "toast")
+		toast.Protected = true
+		toast.Notes = "plain, no butter"
+		for _, food := range []*database.SafeFood{newSafeFood(user.ID, "rice"), toast, newSafeFood(user.ID, "apples"), newSafeFood(other.ID, "yogurt")} {
+			if err := db.CreateSafeFood(ctx, food); err != nil {
+				t.Fatalf("CreateSafeFood(%s): %v", food.Name, err)
+			}
+		}
+
+		got, err := db.GetSafeFoodByID(ctx, toast.ID)
+		if err != nil {
+			t.Fatalf("GetSafeFoodByID: %v", err)
+		}
+		if got.UserID != user.ID || got.Name != "toast" || !got.Protected || got.Notes != toast.Notes || got.RecipeID != nil {
+			t.Errorf("GetSafeFoodByID = %+v, want %+v", got, toast)
+		}
+
+		foods, err := db.ListSafeFoods(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("ListSafeFoods: %v", err)
+		}
+		var names []string
+		for _, food := range foods {
+			names = append(names, food.Name)
+		}
+		if want := []string{"apples", "rice", "toast"}; !equal(names, want) {
+			t.Errorf("ListSafeFoods names = %v, want %v", names, want)
+		}
+
+		toast.Name, toast.Protected, toast.Notes = "toast with jam", false, ""
+		toast.UpdatedAt = toast.UpdatedAt.Add(time.Minute)
+		if err := db.UpdateSafeFood(ctx, toast); err != nil {
+			t.Fatalf("UpdateSafeFood: %v", err)
+		}
+		got, err = db.GetSafeFoodByID(ctx, toast.ID)
+		if err != nil {
+			t.Fatalf("GetSafeFoodByID after update: %v", err)
+		}
+		if got.Name != "toast with jam" || got.Protected || got.Notes != "" || !got.UpdatedAt.Equal(toast.UpdatedAt) {
+			t.Errorf("after update = %+v, want %+v", got, toast)
+		}
+
+		if err := db.DeleteSafeFood(ctx, toast.ID); err != nil {
+			t.Fatalf("DeleteSafeFood: %v", err)
+		}
+		if _, err := db.GetSafeFoodByID(ctx, toast.ID); !apierror.IsNotFound(err) {
+			t.Errorf("GetSafeFoodByID after delete: err = %v, want not found", err)
+		}
+	})
+}
+
+func TestSafeFoodsRemovedWithUser(t *testing.T) {
+	dbtest.Run(t, func(ctx context.Context, db database.Database) {
+		user := dbtest.User(t, ctx, db)
+		food := newSafeFood(user.ID, "crackers")
+		if err := db.CreateSafeFood(ctx, food); err != nil {
+			t.Fatalf("CreateSafeFood: %v", err)
+		}
+		if err := db.DeleteUser(ctx, user.ID); err != nil {
+			t.Fatalf("DeleteUser: %v", err)
+		}
+		if _, err := db.GetSafeFoodByID(ctx, food.ID); !apierror.IsNotFound(err) {
+			t.Errorf("GetSafeFoodByID after deleting its user: err = %v, want not found", err)
+		}
+	})
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package database_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/database/dbtest"
+)
+
+func TestRotateAuthSession(t *testing.T) {
+	dbtest.Run(t, func(ctx context.Context, db database.Database) {
+		user := dbtest.User(t, ctx, db)
+		now := time.Now().UTC().Truncate(time.Second)
+		session := &database.AuthSession{
+			ID:               uuid.New().String(),
+			UserID:           user.ID,
+			RefreshTokenHash: "first",
+			CreatedAt:        now,
+			LastUsedAt:       now,
+			ExpiresAt:        now.Add(time.Hour),
+		}
+		if err := db.CreateAuthSession(ctx, session); err != nil {
+			t.Fatalf("CreateAuthSession: %v", err)
+		}
+
+		// Two refreshes that both read the session before either rotated it
+		a, b := *session, *session
+		a.RefreshTokenHash, b.RefreshTokenHash = "second-a", "second-b"
+		if ok, err := db.RotateAuthSession(ctx, &a, "first"); err != nil || !ok {
+			t.Fatalf("first RotateAuthSession = %v, %v, want rotated", ok, err)
+		}
+		if ok, err := db.RotateAuthSession(ctx, &b, "first"); err != nil || ok {
+			t.Fatalf("second RotateAuthSession = %v, %v, want refused", ok, err)
+		}
+
+		got, err := db.GetAuthSessionByID(ctx, session.ID)
+		if err != nil {
+			t.Fatalf("GetAuthSessionByID: %v", err)
+		}
+		if got.RefreshTokenHash != "second-a" {
+			t.Errorf("refresh token hash = %q, want the first rotation's", got.RefreshTokenHash)
+		}
+
+		// A revoked session is never rotated
+		if err := db.RevokeAuthSessions(ctx, user.ID, ""); err != nil {
+			t.Fatalf("RevokeAuthSessions: %v", err)
+		}
+		a.RefreshTokenHash = "third"
+		if ok, err := db.RotateAuthSession(ctx, &a, "second-a"); err != nil || ok {
+			t.Errorf("RotateAuthSession on a revoked session = %v, %v, want refused", ok, err)
+		}
+	})
+}
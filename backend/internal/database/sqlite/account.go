@@ -0,0 +1,91 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Account deletion operations
+
+// ScheduleAccountDeletion records a pending deletion, replacing any earlier
+// request from the same user
+func (db *SQLiteDB) ScheduleAccountDeletion(ctx context.Context, deletion *database.AccountDeletion) error {
+	query := `
+		INSERT INTO account_deletions (user_id, requested_at, delete_after)
+		VALUES (?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE
+		SET requested_at = excluded.requested_at, delete_after = excluded.delete_after
+	`
+	_, err := db.conn(ctx).ExecContext(ctx, query, deletion.UserID, deletion.RequestedAt.UTC(), deletion.DeleteAfter.UTC())
+	return err
+}
+
+// GetAccountDeletion retrieves a user's pending deletion
+func (db *SQLiteDB) GetAccountDeletion(ctx context.Context, userID string) (*database.AccountDeletion, error) {
+	query := `
+		SELECT user_id, requested_at, delete_after
+		FROM account_deletions WHERE user_id = ?
+	`
+	var deletion database.AccountDeletion
+	err := db.conn(ctx).QueryRowContext(ctx, query, userID).Scan(
+		&deletion.UserID, &deletion.RequestedAt, &deletion.DeleteAfter,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+// CancelAccountDeletion removes a user's pending deletion
+func (db *SQLiteDB) CancelAccountDeletion(ctx context.Context, userID string) error {
+	query := `DELETE FROM account_deletions WHERE user_id = ?`
+	_, err := db.conn(ctx).ExecContext(ctx, query, userID)
+	return err
+}
+
+// ListDueAccountDeletions lists deletions whose grace period ended before
+// the cutoff, oldest first
+func (db *SQLiteDB) ListDueAccountDeletions(ctx context.Context, before time.Time, limit int) ([]*database.AccountDeletion, error) {
+	query := `
+		SELECT user_id, requested_at, delete_after
+		FROM account_deletions
+		WHERE delete_after < ?
+		ORDER BY delete_after
+		LIMIT ?
+	`
+	rows, err := db.conn(ctx).QueryContext(ctx, query, before.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deletions := []*database.AccountDeletion{}
+	for rows.Next() {
+		var deletion database.AccountDeletion
+		if err := rows.Scan(&deletion.UserID, &deletion.RequestedAt, &deletion.DeleteAfter); err != nil {
+			return nil, err
+		}
+		deletions = append(deletions, &deletion)
+	}
+	return deletions, rows.Err()
+}
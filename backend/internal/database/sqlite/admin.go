@@ -0,0 +1,123 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Instance administration operations
+
+// ListUsers lists users, optionally matching a search query
+func (db *SQLiteDB) ListUsers(ctx context.Context, filter database.UserFilter) ([]*database.User, error) {
+	query := `
+		SELECT id, email, password_hash, COALESCE(first_name, ''), COALESCE(last_name, ''),
+		       created_at, updated_at, last_login_at, email_verified, active, is_admin, must_change_password
+		FROM users
+	`
+	args := []interface{}{}
+
+	if filter.Query != "" {
+		// LIKE is case-insensitive for ASCII in SQLite
+		query += " WHERE email LIKE ? OR first_name LIKE ? OR last_name LIKE ?"
+		pattern := "%" + filter.Query + "%"
+		args = append(args, pattern, pattern, pattern)
+	}
+
+	query += " ORDER BY created_at"
+
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+		if filter.Offset > 0 {
+			query += " OFFSET ?"
+			args = append(args, filter.Offset)
+		}
+	}
+
+	rows, err := db.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*database.User{}
+	for rows.Next() {
+		var user database.User
+		if err := rows.Scan(
+			&user.ID, &user.Email, &user.PasswordHash, &user.FirstName, &user.LastName,
+			&user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt, &user.EmailVerified, &user.Active, &user.IsAdmin, &user.MustChangePassword,
+		); err != nil {
+			return nil, err
+		}
+		users = append(users, &user)
+	}
+	return users, rows.Err()
+}
+
+// CountUsers counts all users
+func (db *SQLiteDB) CountUsers(ctx context.Context) (int, error) {
+	var count int
+	err := db.conn(ctx).QueryRowContext(ctx, `SELECT COUNT(*) FROM users`).Scan(&count)
+	return count, err
+}
+
+// GetInstanceStats counts the main records on the instance
+func (db *SQLiteDB) GetInstanceStats(ctx context.Context) (*database.InstanceStats, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM users),
+			(SELECT COUNT(*) FROM users WHERE active = 1),
+			(SELECT COUNT(*) FROM users WHERE is_admin = 1),
+			(SELECT COUNT(*) FROM recipes),
+			(SELECT COUNT(*) FROM meal_plans),
+			(SELECT COUNT(*) FROM households),
+			(SELECT COUNT(*) FROM nutrition_logs)
+	`
+	var stats database.InstanceStats
+	err := db.conn(ctx).QueryRowContext(ctx, query).Scan(
+		&stats.Users, &stats.ActiveUsers, &stats.Admins, &stats.Recipes,
+		&stats.MealPlans, &stats.Households, &stats.NutritionLogs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// GetInstanceSetting retrieves an instance setting
+func (db *SQLiteDB) GetInstanceSetting(ctx context.Context, key string) (string, error) {
+	var value string
+	err := db.conn(ctx).QueryRowContext(ctx, `SELECT value FROM instance_settings WHERE key = ?`, key).Scan(&value)
+	return value, err
+}
+
+// SetInstanceSetting creates or updates an instance setting
+func (db *SQLiteDB) SetInstanceSetting(ctx context.Context, key, value string) error {
+	query := `
+		INSERT INTO instance_settings (key, value, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at
+	`
+	_, err := db.conn(ctx).ExecContext(ctx, query, key, value, time.Now())
+	return err
+}
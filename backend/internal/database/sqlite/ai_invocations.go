@@ -0,0 +1,162 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// AI invocation operations
+
+// RecordAIInvocation records one call to an AI provider
+func (db *SQLiteDB) RecordAIInvocation(ctx context.Context, invocation *database.AIInvocation) error {
+	query := `
+		INSERT INTO ai_invocations (id, user_id, feature, provider, model, input_tokens, output_tokens,
+		                            latency_ms, cost, outcome, error, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := db.conn(ctx).ExecContext(ctx, query,
+		invocation.ID, invocation.UserID, invocation.Feature, invocation.Provider, invocation.Model,
+		invocation.InputTokens, invocation.OutputTokens, invocation.LatencyMS, invocation.Cost,
+		invocation.Outcome, invocation.Error, invocation.CreatedAt.UTC(),
+	)
+	return err
+}
+
+// ListAIInvocations lists AI invocations, newest first
+func (db *SQLiteDB) ListAIInvocations(ctx context.Context, filter database.AIInvocationFilter) ([]*database.AIInvocation, error) {
+	where, args := aiInvocationConditions(filter)
+	query := `
+		SELECT id, user_id, feature, provider, model, input_tokens, output_tokens, latency_ms, cost,
+		       outcome, error, created_at
+		FROM ai_invocations
+		WHERE ` + where
+
+	if filter.After != nil {
+		query += " AND (created_at, id) < (?, ?)"
+		args = append(args, filter.After.Time.UTC(), filter.After.ID)
+	}
+
+	query += " ORDER BY created_at DESC, id DESC"
+
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+		if filter.Offset > 0 && filter.After == nil {
+			query += " OFFSET ?"
+			args = append(args, filter.Offset)
+		}
+	}
+
+	rows, err := db.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invocations := []*database.AIInvocation{}
+	for rows.Next() {
+		var invocation database.AIInvocation
+		if err := rows.Scan(
+			&invocation.ID, &invocation.UserID, &invocation.Feature, &invocation.Provider, &invocation.Model,
+			&invocation.InputTokens, &invocation.OutputTokens, &invocation.LatencyMS, &invocation.Cost,
+			&invocation.Outcome, &invocation.Error, &invocation.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		invocations = append(invocations, &invocation)
+	}
+	return invocations, rows.Err()
+}
+
+// SumAIInvocations totals the AI invocations matching a filter by feature
+// and provider, ignoring its paging
+func (db *SQLiteDB) SumAIInvocations(ctx context.Context, filter database.AIInvocationFilter) ([]*database.AIFeatureUsage, error) {
+	where, args := aiInvocationConditions(filter)
+	query := `
+		SELECT feature, provider, COUNT(*), SUM(CASE WHEN outcome = ? THEN 0 ELSE 1 END),
+		       SUM(input_tokens), SUM(output_tokens), SUM(cost), CAST(AVG(latency_ms) AS INTEGER)
+		FROM ai_invocations
+		WHERE ` + where + `
+		GROUP BY feature, provider
+		ORDER BY feature, provider
+	`
+	rows, err := db.conn(ctx).QueryContext(ctx, query, append([]interface{}{database.AIInvocationSucceeded}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usage := []*database.AIFeatureUsage{}
+	for rows.Next() {
+		var u database.AIFeatureUsage
+		if err := rows.Scan(
+			&u.Feature, &u.Provider, &u.Requests, &u.Failures,
+			&u.InputTokens, &u.OutputTokens, &u.Cost, &u.AvgLatencyMS,
+		); err != nil {
+			return nil, err
+		}
+		usage = append(usage, &u)
+	}
+	return usage, rows.Err()
+}
+
+// aiInvocationConditions builds the WHERE clause shared by listing and
+// totalling
+func aiInvocationConditions(filter database.AIInvocationFilter) (string, []interface{}) {
+	where := "1=1"
+	args := []interface{}{}
+	if filter.UserID != "" {
+		where += " AND user_id = ?"
+		args = append(args, filter.UserID)
+	}
+	if filter.Feature != "" {
+		where += " AND feature = ?"
+		args = append(args, filter.Feature)
+	}
+	if filter.Provider != "" {
+		where += " AND provider = ?"
+		args = append(args, filter.Provider)
+	}
+	if filter.Outcome != "" {
+		where += " AND outcome = ?"
+		args = append(args, filter.Outcome)
+	}
+	if !filter.Since.IsZero() {
+		where += " AND created_at >= ?"
+		args = append(args, filter.Since.UTC())
+	}
+	if !filter.Until.IsZero() {
+		where += " AND created_at < ?"
+		args = append(args, filter.Until.UTC())
+	}
+	return where, args
+}
+
+// PurgeAIInvocations removes AI invocations recorded before a cutoff
+func (db *SQLiteDB) PurgeAIInvocations(ctx context.Context, before time.Time) (int64, error) {
+	result, err := db.conn(ctx).ExecContext(ctx, `DELETE FROM ai_invocations WHERE created_at < ?`, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,158 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Audit log operations
+
+// CreateAuditEvent records an audit event
+func (db *SQLiteDB) CreateAuditEvent(ctx context.Context, event *database.AuditEvent) error {
+	var details *string
+	if len(event.Details) > 0 {
+		encoded, err := json.Marshal(event.Details)
+		if err != nil {
+			return err
+		}
+		value := string(encoded)
+		details = &value
+	}
+	query := `
+		INSERT INTO audit_events (id, user_id, action, target_type, target_id, details, ip_address, user_agent, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := db.conn(ctx).ExecContext(ctx, query,
+		event.ID, event.UserID, event.Action, event.TargetType, event.TargetID, details,
+		event.IPAddress, event.UserAgent, event.CreatedAt.UTC(),
+	)
+	return err
+}
+
+// ListAuditEvents lists audit events, newest first
+func (db *SQLiteDB) ListAuditEvents(ctx context.Context, filter database.AuditEventFilter) ([]*database.AuditEvent, error) {
+	where, args := auditEventConditions(filter)
+	query := `
+		SELECT id, user_id, action, COALESCE(target_type, ''), COALESCE(target_id, ''), details,
+		       COALESCE(ip_address, ''), COALESCE(user_agent, ''), created_at
+		FROM audit_events
+		WHERE ` + where
+
+	if filter.After != nil {
+		query += " AND (created_at, id) < (?, ?)"
+		args = append(args, filter.After.Time.UTC(), filter.After.ID)
+	}
+
+	query += " ORDER BY created_at DESC, id DESC"
+
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+		if filter.Offset > 0 && filter.After == nil {
+			query += " OFFSET ?"
+			args = append(args, filter.Offset)
+		}
+	}
+
+	rows, err := db.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []*database.AuditEvent{}
+	for rows.Next() {
+		var event database.AuditEvent
+		var details sql.NullString
+		if err := rows.Scan(
+			&event.ID, &event.UserID, &event.Action, &event.TargetType, &event.TargetID, &details,
+			&event.IPAddress, &event.UserAgent, &event.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if details.Valid && details.String != "" {
+			if err := json.Unmarshal([]byte(details.String), &event.Details); err != nil {
+				return nil, fmt.Errorf("failed to decode audit event details: %w", err)
+			}
+		}
+		events = append(events, &event)
+	}
+	return events, rows.Err()
+}
+
+// CountAuditEvents counts the audit events matching a filter, ignoring its
+// paging
+func (db *SQLiteDB) CountAuditEvents(ctx context.Context, filter database.AuditEventFilter) (int, error) {
+	where, args := auditEventConditions(filter)
+	var count int
+	err := db.conn(ctx).QueryRowContext(ctx, `SELECT COUNT(*) FROM audit_events WHERE `+where, args...).Scan(&count)
+	return count, err
+}
+
+// auditEventConditions builds the WHERE clause shared by listing and
+// counting
+func auditEventConditions(filter database.AuditEventFilter) (string, []interface{}) {
+	where := "1=1"
+	args := []interface{}{}
+	if filter.UserID != "" {
+		where += " AND user_id = ?"
+		args = append(args, filter.UserID)
+	}
+	if strings.HasSuffix(filter.Action, ".") {
+		where += " AND action LIKE ?"
+		args = append(args, filter.Action+"%")
+	} else if filter.Action != "" {
+		where += " AND action = ?"
+		args = append(args, filter.Action)
+	}
+	if filter.TargetType != "" {
+		where += " AND target_type = ?"
+		args = append(args, filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		where += " AND target_id = ?"
+		args = append(args, filter.TargetID)
+	}
+	if !filter.Since.IsZero() {
+		where += " AND created_at >= ?"
+		args = append(args, filter.Since.UTC())
+	}
+	if !filter.Until.IsZero() {
+		where += " AND created_at < ?"
+		args = append(args, filter.Until.UTC())
+	}
+	return where, args
+}
+
+// PurgeAuditEvents removes audit events recorded before a cutoff
+func (db *SQLiteDB) PurgeAuditEvents(ctx context.Context, before time.Time) (int64, error) {
+	result, err := db.conn(ctx).ExecContext(ctx, `DELETE FROM audit_events WHERE created_at < ?`, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
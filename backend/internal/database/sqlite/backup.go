@@ -0,0 +1,275 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// DumpTables streams every table except the migration history, full-text
+// indexes, which are rebuilt from their content tables, and derived tables
+// as JSON rows
+func (db *SQLiteDB) DumpTables(ctx context.Context, dump database.TableDump) error {
+	// A transaction reads from one snapshot of the write-ahead log
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		tables, err := db.listTables(ctx, "table")
+		if err != nil {
+			return err
+		}
+		for _, table := range tables {
+			if database.DerivedTables[table] {
+				continue
+			}
+			if err := dump.BeginTable(table); err != nil {
+				return err
+			}
+			if err := db.dumpRows(ctx, dump, nil, `SELECT * FROM `+quoteIdent(table)); err != nil {
+				return fmt.Errorf("%s: %w", table, err)
+			}
+		}
+		return nil
+	})
+}
+
+// DumpUserData streams a user's rows from each of database.UserDataTables,
+// without their credential columns
+func (db *SQLiteDB) DumpUserData(ctx context.Context, userID string, dump database.TableDump) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		for _, t := range database.UserDataTables {
+			if err := dump.BeginTable(t.Table); err != nil {
+				return err
+			}
+			query := `SELECT * FROM ` + quoteIdent(t.Table) + ` WHERE ` + t.Where
+			if err := db.dumpRows(ctx, dump, t.Omit, query, sql.Named("user", userID)); err != nil {
+				return fmt.Errorf("%s: %w", t.Table, err)
+			}
+		}
+		return nil
+	})
+}
+
+// LoadTables empties every table except the migration history, inserts the
+// source's rows and rebuilds the full-text indexes
+func (db *SQLiteDB) LoadTables(ctx context.Context, source database.TableSource) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		// Foreign keys are checked at commit, so tables load in any order
+		if _, err := db.conn(ctx).ExecContext(ctx, `PRAGMA defer_foreign_keys = ON`); err != nil {
+			return err
+		}
+
+		tables, err := db.listTables(ctx, "table")
+		if err != nil {
+			return err
+		}
+		known := make(map[string]bool, len(tables))
+		for _, table := range tables {
+			known[table] = true
+		}
+		for _, table := range source.Tables() {
+			if !known[table] {
+				return fmt.Errorf("table %s does not exist in this schema", table)
+			}
+		}
+
+		for _, table := range tables {
+			if _, err := db.conn(ctx).ExecContext(ctx, `DELETE FROM `+quoteIdent(table)); err != nil {
+				return err
+			}
+		}
+		for _, table := range tables {
+			if database.DerivedTables[table] {
+				continue
+			}
+			if err := db.loadTable(ctx, table, source); err != nil {
+				return fmt.Errorf("%s: %w", table, err)
+			}
+		}
+
+		indexes, err := db.listTables(ctx, "virtual")
+		if err != nil {
+			return err
+		}
+		for _, index := range indexes {
+			if _, err := db.conn(ctx).ExecContext(ctx, `INSERT INTO `+quoteIdent(index)+`(`+quoteIdent(index)+`) VALUES ('rebuild')`); err != nil {
+				return fmt.Errorf("failed to rebuild %s: %w", index, err)
+			}
+		}
+		return nil
+	})
+}
+
+// listTables lists the application tables of a kind: table, or virtual for
+// full-text indexes
+func (db *SQLiteDB) listTables(ctx context.Context, kind string) ([]string, error) {
+	rows, err := db.conn(ctx).QueryContext(ctx, `
+		SELECT name FROM pragma_table_list
+		WHERE schema = 'main' AND type = ?
+			AND name NOT LIKE 'sqlite_%' AND name <> 'schema_migrations'
+		ORDER BY name
+	`, kind)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tables = append(tables, name)
+	}
+	return tables, rows.Err()
+}
+
+// dumpRows passes each row of a query to dump as a JSON object, leaving out
+// the omitted columns
+func (db *SQLiteDB) dumpRows(ctx context.Context, dump database.TableDump, omit []string, query string, args ...any) error {
+	rows, err := db.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	values := make([]any, len(columns))
+	ptrs := make([]any, len(columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return err
+		}
+		row := make(map[string]any, len(columns))
+		for i, column := range columns {
+			if slices.Contains(omit, column) {
+				continue
+			}
+			// Text read without a declared type comes back as bytes
+			if b, ok := values[i].([]byte); ok {
+				row[column] = string(b)
+			} else {
+				row[column] = values[i]
+			}
+		}
+		data, err := json.Marshal(row)
+		if err != nil {
+			return err
+		}
+		if err := dump.Row(data); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// loadTable inserts a table's rows, turning JSON values back into the
+// types the driver stores: integers, floats and, for date and time
+// columns, time.Time so timestamps keep the driver's format
+func (db *SQLiteDB) loadTable(ctx context.Context, table string, source database.TableSource) error {
+	rows, err := db.conn(ctx).QueryContext(ctx, `SELECT name, type FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return err
+	}
+	timeColumns := make(map[string]bool)
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var name, declType string
+		if err := rows.Scan(&name, &declType); err != nil {
+			rows.Close()
+			return err
+		}
+		columns[name] = true
+		switch strings.ToLower(declType) {
+		case "date", "datetime", "timestamp":
+			timeColumns[name] = true
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	return source.Rows(table, func(raw json.RawMessage) error {
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		var row map[string]any
+		if err := dec.Decode(&row); err != nil {
+			return err
+		}
+
+		if len(row) == 0 {
+			return fmt.Errorf("empty row")
+		}
+
+		names := make([]string, 0, len(row))
+		args := make([]any, 0, len(row))
+		for name, value := range row {
+			if !columns[name] {
+				return fmt.Errorf("column %s does not exist", name)
+			}
+			switch v := value.(type) {
+			case json.Number:
+				if n, err := v.Int64(); err == nil {
+					value = n
+				} else if f, err := v.Float64(); err == nil {
+					value = f
+				}
+			case string:
+				if timeColumns[name] {
+					if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+						value = t.UTC()
+					}
+				}
+			case map[string]any, []any:
+				data, err := json.Marshal(v)
+				if err != nil {
+					return err
+				}
+				value = string(data)
+			}
+			names = append(names, quoteIdent(name))
+			args = append(args, value)
+		}
+
+		_, err := db.conn(ctx).ExecContext(ctx,
+			`INSERT INTO `+quoteIdent(table)+` (`+strings.Join(names, ", ")+`) VALUES (?`+strings.Repeat(", ?", len(names)-1)+`)`,
+			args...)
+		return err
+	})
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
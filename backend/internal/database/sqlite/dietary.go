@@ -0,0 +1,103 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"context"
+	"strings"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Dietary restriction operations
+
+// CreateDietaryRestriction records a dietary restriction for a user
+func (db *SQLiteDB) CreateDietaryRestriction(ctx context.Context, restriction *database.DietaryRestriction) error {
+	query := `
+		INSERT INTO dietary_restrictions (id, user_id, kind, name, severity, notes, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := db.conn(ctx).ExecContext(ctx, query,
+		restriction.ID, restriction.UserID, restriction.Kind, restriction.Name,
+		restriction.Severity, restriction.Notes, restriction.CreatedAt,
+	)
+	return err
+}
+
+// GetDietaryRestrictionByID retrieves a dietary restriction by ID
+func (db *SQLiteDB) GetDietaryRestrictionByID(ctx context.Context, id string) (*database.DietaryRestriction, error) {
+	query := `
+		SELECT id, user_id, kind, name, COALESCE(severity, ''), COALESCE(notes, ''), created_at
+		FROM dietary_restrictions WHERE id = ?
+	`
+	var restriction database.DietaryRestriction
+	err := db.conn(ctx).QueryRowContext(ctx, query, id).Scan(
+		&restriction.ID, &restriction.UserID, &restriction.Kind, &restriction.Name,
+		&restriction.Severity, &restriction.Notes, &restriction.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &restriction, nil
+}
+
+// ListDietaryRestrictions lists the dietary restrictions of the given users
+func (db *SQLiteDB) ListDietaryRestrictions(ctx context.Context, userIDs []string) ([]*database.DietaryRestriction, error) {
+	if len(userIDs) == 0 {
+		return []*database.DietaryRestriction{}, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(userIDs)), ", ")
+	args := make([]interface{}, len(userIDs))
+	for i, id := range userIDs {
+		args[i] = id
+	}
+
+	query := `
+		SELECT id, user_id, kind, name, COALESCE(severity, ''), COALESCE(notes, ''), created_at
+		FROM dietary_restrictions
+		WHERE user_id IN (` + placeholders + `)
+		ORDER BY user_id, kind, name
+	`
+	rows, err := db.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	restrictions := []*database.DietaryRestriction{}
+	for rows.Next() {
+		var restriction database.DietaryRestriction
+		if err := rows.Scan(
+			&restriction.ID, &restriction.UserID, &restriction.Kind, &restriction.Name,
+			&restriction.Severity, &restriction.Notes, &restriction.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		restrictions = append(restrictions, &restriction)
+	}
+	return restrictions, rows.Err()
+}
+
+// DeleteDietaryRestriction deletes a dietary restriction
+func (db *SQLiteDB) DeleteDietaryRestriction(ctx context.Context, id string) error {
+	query := `DELETE FROM dietary_restrictions WHERE id = ?`
+	_, err := db.conn(ctx).ExecContext(ctx, query, id)
+	return err
+}
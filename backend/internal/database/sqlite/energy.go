@@ -0,0 +1,119 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Energy check-in operations
+
+const energyCheckInColumns = `id, user_id, level, COALESCE(note, ''), recorded_at, created_at`
+
+// CreateEnergyCheckIn records an energy level
+func (db *SQLiteDB) CreateEnergyCheckIn(ctx context.Context, checkIn *database.EnergyCheckIn) error {
+	query := `
+		INSERT INTO energy_checkins (id, user_id, level, note, recorded_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	_, err := db.conn(ctx).ExecContext(ctx, query,
+		checkIn.ID, checkIn.UserID, checkIn.Level, checkIn.Note, checkIn.RecordedAt, checkIn.CreatedAt,
+	)
+	return err
+}
+
+// GetEnergyCheckInByID retrieves an energy check-in by ID
+func (db *SQLiteDB) GetEnergyCheckInByID(ctx context.Context, id string) (*database.EnergyCheckIn, error) {
+	query := `SELECT ` + energyCheckInColumns + ` FROM energy_checkins WHERE id = ?`
+	var checkIn database.EnergyCheckIn
+	err := db.conn(ctx).QueryRowContext(ctx, query, id).Scan(
+		&checkIn.ID, &checkIn.UserID, &checkIn.Level, &checkIn.Note, &checkIn.RecordedAt, &checkIn.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &checkIn, nil
+}
+
+// GetLatestEnergyCheckIn retrieves a user's most recent energy check-in
+func (db *SQLiteDB) GetLatestEnergyCheckIn(ctx context.Context, userID string) (*database.EnergyCheckIn, error) {
+	query := `
+		SELECT ` + energyCheckInColumns + `
+		FROM energy_checkins
+		WHERE user_id = ?
+		ORDER BY recorded_at DESC
+		LIMIT 1
+	`
+	var checkIn database.EnergyCheckIn
+	err := db.conn(ctx).QueryRowContext(ctx, query, userID).Scan(
+		&checkIn.ID, &checkIn.UserID, &checkIn.Level, &checkIn.Note, &checkIn.RecordedAt, &checkIn.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &checkIn, nil
+}
+
+// ListEnergyCheckIns lists a user's energy check-ins, newest first
+func (db *SQLiteDB) ListEnergyCheckIns(ctx context.Context, filter database.EnergyCheckInFilter) ([]*database.EnergyCheckIn, error) {
+	query := `SELECT ` + energyCheckInColumns + ` FROM energy_checkins WHERE user_id = ?`
+	args := []interface{}{filter.UserID}
+
+	if !filter.Since.IsZero() {
+		query += " AND recorded_at >= ?"
+		args = append(args, filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query += " AND recorded_at < ?"
+		args = append(args, filter.Until)
+	}
+
+	query += " ORDER BY recorded_at DESC"
+
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := db.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	checkIns := []*database.EnergyCheckIn{}
+	for rows.Next() {
+		var checkIn database.EnergyCheckIn
+		if err := rows.Scan(
+			&checkIn.ID, &checkIn.UserID, &checkIn.Level, &checkIn.Note, &checkIn.RecordedAt, &checkIn.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		checkIns = append(checkIns, &checkIn)
+	}
+	return checkIns, rows.Err()
+}
+
+// DeleteEnergyCheckIn deletes an energy check-in
+func (db *SQLiteDB) DeleteEnergyCheckIn(ctx context.Context, id string) error {
+	_, err := db.conn(ctx).ExecContext(ctx, `DELETE FROM energy_checkins WHERE id = ?`, id)
+	return err
+}
@@ -0,0 +1,198 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// External calendar operations
+
+const externalCalendarColumns = `id, user_id, name, url, meal_types, last_synced_at, COALESCE(last_error, ''),
+	created_at, updated_at`
+
+// CreateExternalCalendar links an external calendar
+func (db *SQLiteDB) CreateExternalCalendar(ctx context.Context, cal *database.ExternalCalendar) error {
+	mealTypes, err := json.Marshal(cal.MealTypes)
+	if err != nil {
+		return fmt.Errorf("failed to encode meal types: %w", err)
+	}
+
+	query := `
+		INSERT INTO external_calendars (id, user_id, name, url, meal_types, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = db.conn(ctx).ExecContext(ctx, query,
+		cal.ID, cal.UserID, cal.Name, cal.URL, string(mealTypes), cal.CreatedAt, cal.UpdatedAt,
+	)
+	return err
+}
+
+// GetExternalCalendarByID retrieves an external calendar by ID
+func (db *SQLiteDB) GetExternalCalendarByID(ctx context.Context, id string) (*database.ExternalCalendar, error) {
+	query := `SELECT ` + externalCalendarColumns + ` FROM external_calendars WHERE id = ?`
+	return scanExternalCalendar(db.conn(ctx).QueryRowContext(ctx, query, id))
+}
+
+// ListExternalCalendars lists a user's external calendars, oldest first
+func (db *SQLiteDB) ListExternalCalendars(ctx context.Context, userID string) ([]*database.ExternalCalendar, error) {
+	query := `SELECT ` + externalCalendarColumns + ` FROM external_calendars WHERE user_id = ? ORDER BY created_at`
+	return db.queryExternalCalendars(ctx, query, userID)
+}
+
+// ListExternalCalendarsToSync lists calendars never synced or last synced
+// before the cutoff, least recently synced first
+func (db *SQLiteDB) ListExternalCalendarsToSync(ctx context.Context, syncedBefore time.Time, limit int) ([]*database.ExternalCalendar, error) {
+	query := `
+		SELECT ` + externalCalendarColumns + `
+		FROM external_calendars
+		WHERE last_synced_at IS NULL OR last_synced_at < ?
+		ORDER BY last_synced_at
+		LIMIT ?
+	`
+	return db.queryExternalCalendars(ctx, query, syncedBefore.UTC(), limit)
+}
+
+// UpdateExternalCalendar updates an external calendar and its sync status
+func (db *SQLiteDB) UpdateExternalCalendar(ctx context.Context, cal *database.ExternalCalendar) error {
+	mealTypes, err := json.Marshal(cal.MealTypes)
+	if err != nil {
+		return fmt.Errorf("failed to encode meal types: %w", err)
+	}
+
+	query := `
+		UPDATE external_calendars
+		SET name = ?, url = ?, meal_types = ?, last_synced_at = ?, last_error = ?, updated_at = ?
+		WHERE id = ?
+	`
+	_, err = db.conn(ctx).ExecContext(ctx, query,
+		cal.Name, cal.URL, string(mealTypes), cal.LastSyncedAt, cal.LastError, cal.UpdatedAt,
+		cal.ID,
+	)
+	return err
+}
+
+// DeleteExternalCalendar unlinks an external calendar and its imported events.
+// The events are removed explicitly as foreign keys may not be enforced.
+func (db *SQLiteDB) DeleteExternalCalendar(ctx context.Context, id string) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		tx := db.conn(ctx)
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM external_calendar_events WHERE calendar_id = ?`, id); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM external_calendars WHERE id = ?`, id); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// ReplaceExternalCalendarEvents swaps the imported events of a calendar for
+// a freshly fetched set
+func (db *SQLiteDB) ReplaceExternalCalendarEvents(ctx context.Context, calendarID string, events []*database.ExternalCalendarEvent) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		tx := db.conn(ctx)
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM external_calendar_events WHERE calendar_id = ?`, calendarID); err != nil {
+			return err
+		}
+		for _, event := range events {
+			query := `
+				INSERT INTO external_calendar_events (calendar_id, user_id, uid, summary, starts_at, ends_at)
+				VALUES (?, ?, ?, ?, ?, ?)
+			`
+			if _, err := tx.ExecContext(ctx, query,
+				calendarID, event.UserID, event.UID, event.Summary, event.StartsAt.UTC(), event.EndsAt.UTC(),
+			); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ListExternalCalendarEvents lists a user's imported events overlapping a
+// time range, in start order
+func (db *SQLiteDB) ListExternalCalendarEvents(ctx context.Context, userID string, from, to time.Time) ([]*database.ExternalCalendarEvent, error) {
+	query := `
+		SELECT calendar_id, user_id, uid, COALESCE(summary, ''), starts_at, ends_at
+		FROM external_calendar_events
+		WHERE user_id = ? AND ends_at > ? AND starts_at < ?
+		ORDER BY starts_at
+	`
+	rows, err := db.conn(ctx).QueryContext(ctx, query, userID, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []*database.ExternalCalendarEvent{}
+	for rows.Next() {
+		var event database.ExternalCalendarEvent
+		if err := rows.Scan(
+			&event.CalendarID, &event.UserID, &event.UID, &event.Summary, &event.StartsAt, &event.EndsAt,
+		); err != nil {
+			return nil, err
+		}
+		events = append(events, &event)
+	}
+	return events, rows.Err()
+}
+
+func (db *SQLiteDB) queryExternalCalendars(ctx context.Context, query string, args ...any) ([]*database.ExternalCalendar, error) {
+	rows, err := db.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	calendars := []*database.ExternalCalendar{}
+	for rows.Next() {
+		cal, err := scanExternalCalendar(rows)
+		if err != nil {
+			return nil, err
+		}
+		calendars = append(calendars, cal)
+	}
+	return calendars, rows.Err()
+}
+
+func scanExternalCalendar(row interface{ Scan(dest ...any) error }) (*database.ExternalCalendar, error) {
+	var cal database.ExternalCalendar
+	var mealTypes string
+	err := row.Scan(
+		&cal.ID, &cal.UserID, &cal.Name, &cal.URL, &mealTypes, &cal.LastSyncedAt, &cal.LastError,
+		&cal.CreatedAt, &cal.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(mealTypes), &cal.MealTypes); err != nil {
+		return nil, fmt.Errorf("failed to decode meal types: %w", err)
+	}
+	return &cal, nil
+}
@@ -0,0 +1,151 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Grocery price and budget operations
+
+const groceryPriceColumns = `id, user_id, store, name, quantity, unit, price, purchased, shopping_item_id,
+	recorded_at, created_at`
+
+// CreateGroceryPrice records a price paid or seen
+func (db *SQLiteDB) CreateGroceryPrice(ctx context.Context, price *database.GroceryPrice) error {
+	query := `
+		INSERT INTO grocery_prices (id, user_id, store, name, quantity, unit, price, purchased,
+		                            shopping_item_id, recorded_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := db.conn(ctx).ExecContext(ctx, query,
+		price.ID, price.UserID, price.Store, price.Name, price.Quantity, price.Unit, price.Price,
+		price.Purchased, price.ShoppingItemID, price.RecordedAt, price.CreatedAt,
+	)
+	return err
+}
+
+// GetGroceryPriceByID retrieves a recorded price by ID
+func (db *SQLiteDB) GetGroceryPriceByID(ctx context.Context, id string) (*database.GroceryPrice, error) {
+	query := `SELECT ` + groceryPriceColumns + ` FROM grocery_prices WHERE id = ?`
+	return scanGroceryPrice(db.conn(ctx).QueryRowContext(ctx, query, id))
+}
+
+// ListGroceryPrices lists a user's recorded prices, most recent first
+func (db *SQLiteDB) ListGroceryPrices(ctx context.Context, filter database.GroceryPriceFilter) ([]*database.GroceryPrice, error) {
+	query := `SELECT ` + groceryPriceColumns + ` FROM grocery_prices WHERE user_id = ?`
+	args := []interface{}{filter.UserID}
+
+	if filter.Store != "" {
+		query += " AND store = ?"
+		args = append(args, filter.Store)
+	}
+	if filter.PurchasedOnly {
+		query += " AND purchased = 1"
+	}
+	if !filter.Since.IsZero() {
+		query += " AND recorded_at >= ?"
+		args = append(args, filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query += " AND recorded_at < ?"
+		args = append(args, filter.Until)
+	}
+
+	query += " ORDER BY recorded_at DESC, created_at DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := db.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prices := []*database.GroceryPrice{}
+	for rows.Next() {
+		price, err := scanGroceryPrice(rows)
+		if err != nil {
+			return nil, err
+		}
+		prices = append(prices, price)
+	}
+	return prices, rows.Err()
+}
+
+// DeleteGroceryPrice deletes a recorded price
+func (db *SQLiteDB) DeleteGroceryPrice(ctx context.Context, id string) error {
+	_, err := db.conn(ctx).ExecContext(ctx, `DELETE FROM grocery_prices WHERE id = ?`, id)
+	return err
+}
+
+// DeleteShoppingItemPurchase deletes the purchase recorded when a shopping
+// list item was ticked off
+func (db *SQLiteDB) DeleteShoppingItemPurchase(ctx context.Context, shoppingItemID string) error {
+	_, err := db.conn(ctx).ExecContext(ctx,
+		`DELETE FROM grocery_prices WHERE shopping_item_id = ? AND purchased = 1`, shoppingItemID)
+	return err
+}
+
+// GetGroceryBudget retrieves a user's monthly grocery budget
+func (db *SQLiteDB) GetGroceryBudget(ctx context.Context, userID string) (*database.GroceryBudget, error) {
+	query := `SELECT user_id, monthly_limit, currency, updated_at FROM grocery_budgets WHERE user_id = ?`
+	var budget database.GroceryBudget
+	err := db.conn(ctx).QueryRowContext(ctx, query, userID).Scan(
+		&budget.UserID, &budget.MonthlyLimit, &budget.Currency, &budget.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &budget, nil
+}
+
+// UpsertGroceryBudget creates or replaces a user's monthly grocery budget
+func (db *SQLiteDB) UpsertGroceryBudget(ctx context.Context, budget *database.GroceryBudget) error {
+	query := `
+		INSERT INTO grocery_budgets (user_id, monthly_limit, currency, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE
+		SET monthly_limit = excluded.monthly_limit, currency = excluded.currency, updated_at = excluded.updated_at
+	`
+	_, err := db.conn(ctx).ExecContext(ctx, query, budget.UserID, budget.MonthlyLimit, budget.Currency, budget.UpdatedAt)
+	return err
+}
+
+// DeleteGroceryBudget deletes a user's monthly grocery budget
+func (db *SQLiteDB) DeleteGroceryBudget(ctx context.Context, userID string) error {
+	_, err := db.conn(ctx).ExecContext(ctx, `DELETE FROM grocery_budgets WHERE user_id = ?`, userID)
+	return err
+}
+
+func scanGroceryPrice(row interface{ Scan(dest ...any) error }) (*database.GroceryPrice, error) {
+	var price database.GroceryPrice
+	err := row.Scan(
+		&price.ID, &price.UserID, &price.Store, &price.Name, &price.Quantity, &price.Unit, &price.Price,
+		&price.Purchased, &price.ShoppingItemID, &price.RecordedAt, &price.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &price, nil
+}
@@ -0,0 +1,77 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Device handoff token operations
+
+// CreateHandoffToken stores a new handoff token
+func (db *SQLiteDB) CreateHandoffToken(ctx context.Context, token *database.HandoffToken) error {
+	query := `
+		INSERT INTO handoff_tokens (id, user_id, token_hash, target, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	_, err := db.conn(ctx).ExecContext(ctx, query,
+		token.ID, token.UserID, token.TokenHash, token.Target, token.CreatedAt, token.ExpiresAt,
+	)
+	return err
+}
+
+// GetHandoffTokenByHash retrieves a handoff token by the hash of its secret
+func (db *SQLiteDB) GetHandoffTokenByHash(ctx context.Context, tokenHash string) (*database.HandoffToken, error) {
+	query := `
+		SELECT id, user_id, token_hash, target, created_at, expires_at, used_at
+		FROM handoff_tokens WHERE token_hash = ?
+	`
+	var token database.HandoffToken
+	err := db.conn(ctx).QueryRowContext(ctx, query, tokenHash).Scan(
+		&token.ID, &token.UserID, &token.TokenHash, &token.Target, &token.CreatedAt, &token.ExpiresAt, &token.UsedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// UseHandoffToken marks an unused token as used, reporting whether it was
+// still unused
+func (db *SQLiteDB) UseHandoffToken(ctx context.Context, id string, usedAt time.Time) (bool, error) {
+	query := `UPDATE handoff_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL`
+	result, err := db.conn(ctx).ExecContext(ctx, query, usedAt, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n == 1, err
+}
+
+// PurgeHandoffTokens removes tokens that expired before a time
+func (db *SQLiteDB) PurgeHandoffTokens(ctx context.Context, before time.Time) (int64, error) {
+	result, err := db.conn(ctx).ExecContext(ctx, `DELETE FROM handoff_tokens WHERE expires_at < ?`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
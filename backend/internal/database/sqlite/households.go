@@ -0,0 +1,338 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Household operations
+
+// CreateHousehold creates a new household and registers its owner as a member
+func (db *SQLiteDB) CreateHousehold(ctx context.Context, household *database.Household) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		tx := db.conn(ctx)
+
+		query := `
+			INSERT INTO households (id, name, owner_id, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?)
+		`
+		if _, err := tx.ExecContext(ctx, query,
+			household.ID, household.Name, household.OwnerID, household.CreatedAt, household.UpdatedAt,
+		); err != nil {
+			return err
+		}
+
+		memberQuery := `
+			INSERT INTO household_members (household_id, user_id, role, joined_at)
+			VALUES (?, ?, ?, ?)
+		`
+		if _, err := tx.ExecContext(ctx, memberQuery,
+			household.ID, household.OwnerID, database.HouseholdRoleOwner, household.CreatedAt,
+		); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// GetHouseholdByID retrieves a household by ID
+func (db *SQLiteDB) GetHouseholdByID(ctx context.Context, id string) (*database.Household, error) {
+	query := `
+		SELECT id, name, owner_id, created_at, updated_at
+		FROM households WHERE id = ?
+	`
+	var household database.Household
+	err := db.conn(ctx).QueryRowContext(ctx, query, id).Scan(
+		&household.ID, &household.Name, &household.OwnerID, &household.CreatedAt, &household.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &household, nil
+}
+
+// ListHouseholdsByUser lists the households a user is a member of
+func (db *SQLiteDB) ListHouseholdsByUser(ctx context.Context, userID string) ([]*database.Household, error) {
+	query := `
+		SELECT h.id, h.name, h.owner_id, h.created_at, h.updated_at
+		FROM households h
+		JOIN household_members m ON m.household_id = h.id
+		WHERE m.user_id = ?
+		ORDER BY h.created_at
+	`
+	rows, err := db.conn(ctx).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	households := []*database.Household{}
+	for rows.Next() {
+		var household database.Household
+		if err := rows.Scan(
+			&household.ID, &household.Name, &household.OwnerID, &household.CreatedAt, &household.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		households = append(households, &household)
+	}
+	return households, rows.Err()
+}
+
+// DeleteHousehold deletes a household along with its memberships and invitations
+func (db *SQLiteDB) DeleteHousehold(ctx context.Context, id string) error {
+	query := `DELETE FROM households WHERE id = ?`
+	_, err := db.conn(ctx).ExecContext(ctx, query, id)
+	return err
+}
+
+// AddHouseholdMember adds a user to a household
+func (db *SQLiteDB) AddHouseholdMember(ctx context.Context, member *database.HouseholdMember) error {
+	query := `
+		INSERT INTO household_members (household_id, user_id, role, joined_at)
+		VALUES (?, ?, ?, ?)
+	`
+	_, err := db.conn(ctx).ExecContext(ctx, query, member.HouseholdID, member.UserID, member.Role, member.JoinedAt)
+	return err
+}
+
+// GetHouseholdMember retrieves a single household membership
+func (db *SQLiteDB) GetHouseholdMember(ctx context.Context, householdID, userID string) (*database.HouseholdMember, error) {
+	query := `
+		SELECT m.household_id, m.user_id, m.role, m.joined_at, u.email, COALESCE(u.first_name, ''), COALESCE(u.last_name, '')
+		FROM household_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.household_id = ? AND m.user_id = ?
+	`
+	var member database.HouseholdMember
+	err := db.conn(ctx).QueryRowContext(ctx, query, householdID, userID).Scan(
+		&member.HouseholdID, &member.UserID, &member.Role, &member.JoinedAt,
+		&member.Email, &member.FirstName, &member.LastName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// ListHouseholdMembers lists the members of a household
+func (db *SQLiteDB) ListHouseholdMembers(ctx context.Context, householdID string) ([]*database.HouseholdMember, error) {
+	query := `
+		SELECT m.household_id, m.user_id, m.role, m.joined_at, u.email, COALESCE(u.first_name, ''), COALESCE(u.last_name, '')
+		FROM household_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.household_id = ?
+		ORDER BY m.joined_at
+	`
+	rows, err := db.conn(ctx).QueryContext(ctx, query, householdID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []*database.HouseholdMember{}
+	for rows.Next() {
+		var member database.HouseholdMember
+		if err := rows.Scan(
+			&member.HouseholdID, &member.UserID, &member.Role, &member.JoinedAt,
+			&member.Email, &member.FirstName, &member.LastName,
+		); err != nil {
+			return nil, err
+		}
+		members = append(members, &member)
+	}
+	return members, rows.Err()
+}
+
+// RemoveHouseholdMember removes a user from a household
+func (db *SQLiteDB) RemoveHouseholdMember(ctx context.Context, householdID, userID string) error {
+	query := `DELETE FROM household_members WHERE household_id = ? AND user_id = ?`
+	_, err := db.conn(ctx).ExecContext(ctx, query, householdID, userID)
+	return err
+}
+
+// TransferHouseholdOwnership makes an existing member the owner of a
+// household; the previous owner stays on as an admin
+func (db *SQLiteDB) TransferHouseholdOwnership(ctx context.Context, householdID, newOwnerID string) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		tx := db.conn(ctx)
+
+		demote := `
+			UPDATE household_members SET role = ?
+			WHERE household_id = ? AND role = ?
+		`
+		if _, err := tx.ExecContext(ctx, demote, database.HouseholdRoleAdmin, householdID, database.HouseholdRoleOwner); err != nil {
+			return err
+		}
+
+		promote := `
+			UPDATE household_members SET role = ?
+			WHERE household_id = ? AND user_id = ?
+		`
+		result, err := tx.ExecContext(ctx, promote, database.HouseholdRoleOwner, householdID, newOwnerID)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return fmt.Errorf("user %s is not a member of household %s", newOwnerID, householdID)
+		}
+
+		query := `UPDATE households SET owner_id = ?, updated_at = ? WHERE id = ?`
+		_, err = tx.ExecContext(ctx, query, newOwnerID, time.Now(), householdID)
+		return err
+	})
+}
+
+// Household invitation operations
+
+const householdInvitationColumns = `
+	id, household_id, invited_by, COALESCE(email, ''), code_hash, role, status,
+	expires_at, created_at, responded_at, responded_by
+`
+
+func scanHouseholdInvitation(row interface{ Scan(dest ...any) error }) (*database.HouseholdInvitation, error) {
+	var invitation database.HouseholdInvitation
+	var respondedBy sql.NullString
+	err := row.Scan(
+		&invitation.ID, &invitation.HouseholdID, &invitation.InvitedBy, &invitation.Email,
+		&invitation.CodeHash, &invitation.Role, &invitation.Status, &invitation.ExpiresAt,
+		&invitation.CreatedAt, &invitation.RespondedAt, &respondedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if respondedBy.Valid {
+		invitation.RespondedBy = &respondedBy.String
+	}
+	return &invitation, nil
+}
+
+// CreateHouseholdInvitation creates a new household invitation
+func (db *SQLiteDB) CreateHouseholdInvitation(ctx context.Context, invitation *database.HouseholdInvitation) error {
+	query := `
+		INSERT INTO household_invitations (id, household_id, invited_by, email, code_hash, role, status, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := db.conn(ctx).ExecContext(ctx, query,
+		invitation.ID, invitation.HouseholdID, invitation.InvitedBy, invitation.Email, invitation.CodeHash,
+		invitation.Role, invitation.Status, invitation.ExpiresAt, invitation.CreatedAt,
+	)
+	return err
+}
+
+// GetHouseholdInvitationByID retrieves a household invitation by ID
+func (db *SQLiteDB) GetHouseholdInvitationByID(ctx context.Context, id string) (*database.HouseholdInvitation, error) {
+	query := `SELECT ` + householdInvitationColumns + ` FROM household_invitations WHERE id = ?`
+	return scanHouseholdInvitation(db.conn(ctx).QueryRowContext(ctx, query, id))
+}
+
+// GetHouseholdInvitationByCodeHash retrieves a household invitation by the hash of its code
+func (db *SQLiteDB) GetHouseholdInvitationByCodeHash(ctx context.Context, codeHash string) (*database.HouseholdInvitation, error) {
+	query := `SELECT ` + householdInvitationColumns + ` FROM household_invitations WHERE code_hash = ?`
+	return scanHouseholdInvitation(db.conn(ctx).QueryRowContext(ctx, query, codeHash))
+}
+
+// ListHouseholdInvitations lists household invitations with filters
+func (db *SQLiteDB) ListHouseholdInvitations(ctx context.Context, filter database.HouseholdInvitationFilter) ([]*database.HouseholdInvitation, error) {
+	conditions := []string{}
+	args := []interface{}{}
+
+	if filter.HouseholdID != "" {
+		conditions = append(conditions, "household_id = ?")
+		args = append(args, filter.HouseholdID)
+	}
+	if filter.Email != "" {
+		conditions = append(conditions, "LOWER(email) = LOWER(?)")
+		args = append(args, filter.Email)
+	}
+	if filter.PendingOnly {
+		conditions = append(conditions, "status = ? AND expires_at > ?")
+		args = append(args, database.InvitationStatusPending, time.Now())
+	}
+
+	query := `SELECT ` + householdInvitationColumns + ` FROM household_invitations`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY created_at DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, filter.Limit, filter.Offset)
+	}
+
+	rows, err := db.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := []*database.HouseholdInvitation{}
+	for rows.Next() {
+		invitation, err := scanHouseholdInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, invitation)
+	}
+	return invitations, rows.Err()
+}
+
+// UpdateHouseholdInvitation updates the status of a household invitation
+func (db *SQLiteDB) UpdateHouseholdInvitation(ctx context.Context, invitation *database.HouseholdInvitation) error {
+	query := `
+		UPDATE household_invitations
+		SET status = ?, responded_at = ?, responded_by = ?
+		WHERE id = ?
+	`
+	_, err := db.conn(ctx).ExecContext(ctx, query,
+		invitation.Status, invitation.RespondedAt, invitation.RespondedBy, invitation.ID,
+	)
+	return err
+}
+
+// AcceptHouseholdInvitation marks a pending, unexpired invitation as
+// accepted by the user, reporting whether it was still open. Concurrent
+// callers with the same invitation cannot both succeed.
+func (db *SQLiteDB) AcceptHouseholdInvitation(ctx context.Context, id, userID string, now time.Time) (bool, error) {
+	query := `
+		UPDATE household_invitations
+		SET status = ?, responded_at = ?, responded_by = ?
+		WHERE id = ? AND status = ? AND expires_at > ?
+	`
+	result, err := db.conn(ctx).ExecContext(ctx, query,
+		database.InvitationStatusAccepted, now, userID, id, database.InvitationStatusPending, now,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n == 1, err
+}
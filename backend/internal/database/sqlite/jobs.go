@@ -0,0 +1,194 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Scheduled job operations
+
+// SyncScheduledJob records a registered job, keeping its next run time
+// unless the schedule changed
+func (db *SQLiteDB) SyncScheduledJob(ctx context.Context, job *database.ScheduledJob) error {
+	query := `
+		INSERT INTO scheduled_jobs (name, schedule, next_run_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (name) DO UPDATE
+		SET next_run_at = CASE WHEN scheduled_jobs.schedule = excluded.schedule
+		                       THEN scheduled_jobs.next_run_at ELSE excluded.next_run_at END,
+		    schedule = excluded.schedule
+	`
+	_, err := db.conn(ctx).ExecContext(ctx, query, job.Name, job.Schedule, job.NextRunAt)
+	return err
+}
+
+// ListScheduledJobs lists every persisted job schedule
+func (db *SQLiteDB) ListScheduledJobs(ctx context.Context) ([]*database.ScheduledJob, error) {
+	query := `
+		SELECT name, schedule, next_run_at, last_run_at, COALESCE(last_status, '')
+		FROM scheduled_jobs
+		ORDER BY name
+	`
+	rows, err := db.conn(ctx).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []*database.ScheduledJob{}
+	for rows.Next() {
+		var job database.ScheduledJob
+		if err := rows.Scan(&job.Name, &job.Schedule, &job.NextRunAt, &job.LastRunAt, &job.LastStatus); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, &job)
+	}
+	return jobs, rows.Err()
+}
+
+// ClaimScheduledJob moves a due job to its next run time, reporting whether
+// this caller claimed the run
+func (db *SQLiteDB) ClaimScheduledJob(ctx context.Context, name string, now, nextRunAt time.Time) (bool, error) {
+	query := `UPDATE scheduled_jobs SET next_run_at = ? WHERE name = ? AND next_run_at <= ?`
+	result, err := db.conn(ctx).ExecContext(ctx, query, nextRunAt, name, now)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// UpdateScheduledJobStatus records the outcome of a job's latest run
+func (db *SQLiteDB) UpdateScheduledJobStatus(ctx context.Context, name string, lastRunAt time.Time, lastStatus string) error {
+	query := `UPDATE scheduled_jobs SET last_run_at = ?, last_status = ? WHERE name = ?`
+	_, err := db.conn(ctx).ExecContext(ctx, query, lastRunAt, lastStatus, name)
+	return err
+}
+
+// CreateJobRun records the start of a job run
+func (db *SQLiteDB) CreateJobRun(ctx context.Context, run *database.JobRun) error {
+	query := `
+		INSERT INTO job_runs (id, job_name, triggered_by, status, result, error, started_at, finished_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := db.conn(ctx).ExecContext(ctx, query,
+		run.ID, run.JobName, run.Trigger, run.Status, run.Result, run.Error, run.StartedAt, run.FinishedAt,
+	)
+	return err
+}
+
+// FinishJobRun records the outcome of a job run
+func (db *SQLiteDB) FinishJobRun(ctx context.Context, run *database.JobRun) error {
+	query := `UPDATE job_runs SET status = ?, result = ?, error = ?, finished_at = ? WHERE id = ?`
+	_, err := db.conn(ctx).ExecContext(ctx, query, run.Status, run.Result, run.Error, run.FinishedAt, run.ID)
+	return err
+}
+
+// ListJobRuns lists job runs, newest first
+func (db *SQLiteDB) ListJobRuns(ctx context.Context, filter database.JobRunFilter) ([]*database.JobRun, error) {
+	where, args := jobRunConditions(filter)
+	query := `
+		SELECT id, job_name, triggered_by, status, COALESCE(result, ''), COALESCE(error, ''), started_at, finished_at
+		FROM job_runs
+		WHERE ` + where
+
+	if filter.After != nil {
+		query += " AND (started_at, id) < (?, ?)"
+		args = append(args, filter.After.Time.UTC(), filter.After.ID)
+	}
+
+	query += " ORDER BY started_at DESC, id DESC"
+
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+		if filter.Offset > 0 && filter.After == nil {
+			query += " OFFSET ?"
+			args = append(args, filter.Offset)
+		}
+	}
+
+	rows, err := db.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []*database.JobRun{}
+	for rows.Next() {
+		var run database.JobRun
+		if err := rows.Scan(
+			&run.ID, &run.JobName, &run.Trigger, &run.Status,
+			&run.Result, &run.Error, &run.StartedAt, &run.FinishedAt,
+		); err != nil {
+			return nil, err
+		}
+		runs = append(runs, &run)
+	}
+	return runs, rows.Err()
+}
+
+// CountJobRuns counts the job runs matching a filter, ignoring its paging
+func (db *SQLiteDB) CountJobRuns(ctx context.Context, filter database.JobRunFilter) (int, error) {
+	where, args := jobRunConditions(filter)
+	var count int
+	err := db.conn(ctx).QueryRowContext(ctx, `SELECT COUNT(*) FROM job_runs WHERE `+where, args...).Scan(&count)
+	return count, err
+}
+
+// jobRunConditions builds the WHERE clause shared by listing and counting
+func jobRunConditions(filter database.JobRunFilter) (string, []interface{}) {
+	where := "1=1"
+	args := []interface{}{}
+	if filter.JobName != "" {
+		where += " AND job_name = ?"
+		args = append(args, filter.JobName)
+	}
+	if filter.Status != "" {
+		where += " AND status = ?"
+		args = append(args, filter.Status)
+	}
+	return where, args
+}
+
+// InterruptJobRuns marks runs left running by a previous process as failed
+func (db *SQLiteDB) InterruptJobRuns(ctx context.Context, finishedAt time.Time, reason string) (int64, error) {
+	query := `UPDATE job_runs SET status = 'failed', error = ?, finished_at = ? WHERE status = 'running'`
+	result, err := db.conn(ctx).ExecContext(ctx, query, reason, finishedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// PurgeJobRuns removes job runs that started before a cutoff
+func (db *SQLiteDB) PurgeJobRuns(ctx context.Context, before time.Time) (int64, error) {
+	result, err := db.conn(ctx).ExecContext(ctx, `DELETE FROM job_runs WHERE started_at < ? AND status <> 'running'`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,154 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Leftover operations
+
+const leftoverColumns = `id, user_id, recipe_id, name, food_type, portions, COALESCE(container, ''), storage,
+	stored_at, eat_by, COALESCE(notes, ''), COALESCE(reheat, ''), thaw_at, thaw_reminded_at, finished_at,
+	created_at, updated_at`
+
+// CreateLeftover records a new batch of leftovers
+func (db *SQLiteDB) CreateLeftover(ctx context.Context, leftover *database.Leftover) error {
+	query := `
+		INSERT INTO leftovers (id, user_id, recipe_id, name, food_type, portions, container, storage,
+		                       stored_at, eat_by, notes, reheat, thaw_at, thaw_reminded_at, finished_at,
+		                       created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := db.conn(ctx).ExecContext(ctx, query,
+		leftover.ID, leftover.UserID, leftover.RecipeID, leftover.Name, leftover.FoodType, leftover.Portions,
+		leftover.Container, leftover.Storage, leftover.StoredAt, leftover.EatBy, leftover.Notes,
+		leftover.Reheat, leftover.ThawAt, leftover.ThawRemindedAt, leftover.FinishedAt, leftover.CreatedAt,
+		leftover.UpdatedAt,
+	)
+	return err
+}
+
+// GetLeftoverByID retrieves leftovers by ID
+func (db *SQLiteDB) GetLeftoverByID(ctx context.Context, id string) (*database.Leftover, error) {
+	query := `SELECT ` + leftoverColumns + ` FROM leftovers WHERE id = ?`
+	return scanLeftover(db.conn(ctx).QueryRowContext(ctx, query, id))
+}
+
+// ListLeftovers lists a user's leftovers, soonest eat-by first
+func (db *SQLiteDB) ListLeftovers(ctx context.Context, filter database.LeftoverFilter) ([]*database.Leftover, error) {
+	query := `SELECT ` + leftoverColumns + ` FROM leftovers WHERE user_id = ?`
+	args := []interface{}{filter.UserID}
+
+	if !filter.IncludeFinished {
+		query += " AND finished_at IS NULL"
+	}
+	if filter.EatByBefore != nil {
+		query += " AND eat_by < ?"
+		args = append(args, *filter.EatByBefore)
+	}
+	if filter.Storage != "" {
+		query += " AND storage = ?"
+		args = append(args, filter.Storage)
+	}
+
+	query += " ORDER BY eat_by"
+
+	rows, err := db.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	leftovers := []*database.Leftover{}
+	for rows.Next() {
+		leftover, err := scanLeftover(rows)
+		if err != nil {
+			return nil, err
+		}
+		leftovers = append(leftovers, leftover)
+	}
+	return leftovers, rows.Err()
+}
+
+// UpdateLeftover updates leftovers
+func (db *SQLiteDB) UpdateLeftover(ctx context.Context, leftover *database.Leftover) error {
+	query := `
+		UPDATE leftovers
+		SET name = ?, food_type = ?, portions = ?, container = ?, storage = ?,
+		    eat_by = ?, notes = ?, reheat = ?, thaw_at = ?, thaw_reminded_at = ?, finished_at = ?,
+		    updated_at = ?
+		WHERE id = ?
+	`
+	_, err := db.conn(ctx).ExecContext(ctx, query,
+		leftover.Name, leftover.FoodType, leftover.Portions, leftover.Container, leftover.Storage,
+		leftover.EatBy, leftover.Notes, leftover.Reheat, leftover.ThawAt, leftover.ThawRemindedAt,
+		leftover.FinishedAt, leftover.UpdatedAt, leftover.ID,
+	)
+	return err
+}
+
+// DeleteLeftover deletes leftovers
+func (db *SQLiteDB) DeleteLeftover(ctx context.Context, id string) error {
+	_, err := db.conn(ctx).ExecContext(ctx, `DELETE FROM leftovers WHERE id = ?`, id)
+	return err
+}
+
+// ListThawDueLeftovers lists unfinished frozen leftovers across all users
+// whose thaw time has come and that haven't been reminded about yet
+func (db *SQLiteDB) ListThawDueLeftovers(ctx context.Context, now time.Time) ([]*database.Leftover, error) {
+	query := `
+		SELECT ` + leftoverColumns + `
+		FROM leftovers
+		WHERE storage = ? AND finished_at IS NULL AND thaw_reminded_at IS NULL AND thaw_at <= ?
+		ORDER BY thaw_at
+	`
+	rows, err := db.conn(ctx).QueryContext(ctx, query, database.LeftoverStorageFreezer, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	leftovers := []*database.Leftover{}
+	for rows.Next() {
+		leftover, err := scanLeftover(rows)
+		if err != nil {
+			return nil, err
+		}
+		leftovers = append(leftovers, leftover)
+	}
+	return leftovers, rows.Err()
+}
+
+func scanLeftover(row interface{ Scan(dest ...any) error }) (*database.Leftover, error) {
+	var leftover database.Leftover
+	err := row.Scan(
+		&leftover.ID, &leftover.UserID, &leftover.RecipeID, &leftover.Name, &leftover.FoodType,
+		&leftover.Portions, &leftover.Container, &leftover.Storage, &leftover.StoredAt, &leftover.EatBy,
+		&leftover.Notes, &leftover.Reheat, &leftover.ThawAt, &leftover.ThawRemindedAt, &leftover.FinishedAt,
+		&leftover.CreatedAt, &leftover.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &leftover, nil
+}
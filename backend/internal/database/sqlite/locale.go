@@ -0,0 +1,57 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Locale operations
+
+// GetUserLocale retrieves the language a user chose
+func (db *SQLiteDB) GetUserLocale(ctx context.Context, userID string) (*database.UserLocale, error) {
+	query := `SELECT user_id, locale, updated_at FROM user_locales WHERE user_id = ?`
+	var locale database.UserLocale
+	err := db.conn(ctx).QueryRowContext(ctx, query, userID).Scan(&locale.UserID, &locale.Locale, &locale.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &locale, nil
+}
+
+// UpsertUserLocale sets the language a user chose
+func (db *SQLiteDB) UpsertUserLocale(ctx context.Context, locale *database.UserLocale) error {
+	query := `
+		INSERT INTO user_locales (user_id, locale, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE
+		SET locale = excluded.locale, updated_at = excluded.updated_at
+	`
+	_, err := db.conn(ctx).ExecContext(ctx, query, locale.UserID, locale.Locale, locale.UpdatedAt)
+	return err
+}
+
+// DeleteUserLocale forgets the language a user chose
+func (db *SQLiteDB) DeleteUserLocale(ctx context.Context, userID string) error {
+	query := `DELETE FROM user_locales WHERE user_id = ?`
+	_, err := db.conn(ctx).ExecContext(ctx, query, userID)
+	return err
+}
@@ -0,0 +1,168 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"context"
+	"strings"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Meal request operations
+
+const mealRequestColumns = `id, household_id, requested_by, recipe_id, title, COALESCE(notes, ''), status,
+	decided_by, decided_at, meal_plan_id, planned_date, COALESCE(meal_type, ''), created_at, updated_at`
+
+// CreateMealRequest adds a request to a household's board
+func (db *SQLiteDB) CreateMealRequest(ctx context.Context, request *database.MealRequest) error {
+	query := `
+		INSERT INTO meal_requests (id, household_id, requested_by, recipe_id, title, notes, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := db.conn(ctx).ExecContext(ctx, query,
+		request.ID, request.HouseholdID, request.RequestedBy, request.RecipeID, request.Title, request.Notes,
+		request.Status, request.CreatedAt, request.UpdatedAt,
+	)
+	return err
+}
+
+// GetMealRequestByID retrieves a meal request by ID
+func (db *SQLiteDB) GetMealRequestByID(ctx context.Context, id string) (*database.MealRequest, error) {
+	query := `SELECT ` + mealRequestColumns + ` FROM meal_requests WHERE id = ?`
+	return scanMealRequest(db.conn(ctx).QueryRowContext(ctx, query, id))
+}
+
+// ListMealRequests lists a household's meal requests, most upvoted first
+// and then oldest first
+func (db *SQLiteDB) ListMealRequests(ctx context.Context, filter database.MealRequestFilter) ([]*database.MealRequest, error) {
+	conditions := []string{"household_id = ?"}
+	args := []interface{}{filter.HouseholdID}
+
+	if filter.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, filter.Status)
+	}
+
+	query := `SELECT ` + mealRequestColumns + ` FROM meal_requests
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY (SELECT COUNT(*) FROM meal_request_votes v WHERE v.request_id = meal_requests.id AND v.vote = ?) DESC,
+			created_at, id`
+	args = append(args, database.MealRequestVoteUp)
+	if filter.Limit > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, filter.Limit, filter.Offset)
+	}
+
+	rows, err := db.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := []*database.MealRequest{}
+	for rows.Next() {
+		request, err := scanMealRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, request)
+	}
+	return requests, rows.Err()
+}
+
+// UpdateMealRequest saves a meal request's details and decision
+func (db *SQLiteDB) UpdateMealRequest(ctx context.Context, request *database.MealRequest) error {
+	query := `
+		UPDATE meal_requests
+		SET recipe_id = ?, title = ?, notes = ?, status = ?, decided_by = ?, decided_at = ?,
+			meal_plan_id = ?, planned_date = ?, meal_type = ?, updated_at = ?
+		WHERE id = ?
+	`
+	_, err := db.conn(ctx).ExecContext(ctx, query,
+		request.RecipeID, request.Title, request.Notes, request.Status, request.DecidedBy, request.DecidedAt,
+		request.MealPlanID, request.PlannedDate, request.MealType, request.UpdatedAt, request.ID,
+	)
+	return err
+}
+
+// DeleteMealRequest removes a meal request and its votes
+func (db *SQLiteDB) DeleteMealRequest(ctx context.Context, id string) error {
+	_, err := db.conn(ctx).ExecContext(ctx, `DELETE FROM meal_requests WHERE id = ?`, id)
+	return err
+}
+
+// UpsertMealRequestVote records a member's vote, replacing any earlier one
+func (db *SQLiteDB) UpsertMealRequestVote(ctx context.Context, vote *database.MealRequestVote) error {
+	query := `
+		INSERT INTO meal_request_votes (request_id, user_id, vote, reason, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (request_id, user_id) DO UPDATE
+		SET vote = excluded.vote, reason = excluded.reason, created_at = excluded.created_at
+	`
+	_, err := db.conn(ctx).ExecContext(ctx, query, vote.RequestID, vote.UserID, vote.Vote, vote.Reason, vote.CreatedAt)
+	return err
+}
+
+// DeleteMealRequestVote withdraws a member's vote
+func (db *SQLiteDB) DeleteMealRequestVote(ctx context.Context, requestID, userID string) error {
+	_, err := db.conn(ctx).ExecContext(ctx,
+		`DELETE FROM meal_request_votes WHERE request_id = ? AND user_id = ?`, requestID, userID)
+	return err
+}
+
+// ListMealRequestVotes returns the votes on the given requests, oldest
+// first, by request ID
+func (db *SQLiteDB) ListMealRequestVotes(ctx context.Context, requestIDs []string) (map[string][]*database.MealRequestVote, error) {
+	votes := map[string][]*database.MealRequestVote{}
+	if len(requestIDs) == 0 {
+		return votes, nil
+	}
+
+	query := `SELECT request_id, user_id, vote, COALESCE(reason, ''), created_at FROM meal_request_votes
+		WHERE request_id IN (` + placeholderList(len(requestIDs)) + `)
+		ORDER BY request_id, created_at, user_id`
+	rows, err := db.conn(ctx).QueryContext(ctx, query, stringArgs(requestIDs)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var vote database.MealRequestVote
+		if err := rows.Scan(&vote.RequestID, &vote.UserID, &vote.Vote, &vote.Reason, &vote.CreatedAt); err != nil {
+			return nil, err
+		}
+		votes[vote.RequestID] = append(votes[vote.RequestID], &vote)
+	}
+	return votes, rows.Err()
+}
+
+func scanMealRequest(row interface{ Scan(dest ...any) error }) (*database.MealRequest, error) {
+	var request database.MealRequest
+	err := row.Scan(
+		&request.ID, &request.HouseholdID, &request.RequestedBy, &request.RecipeID, &request.Title, &request.Notes,
+		&request.Status, &request.DecidedBy, &request.DecidedAt, &request.MealPlanID, &request.PlannedDate,
+		&request.MealType, &request.CreatedAt, &request.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
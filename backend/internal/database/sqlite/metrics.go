@@ -0,0 +1,155 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Instance metric operations
+
+// RecordRecipeImport records the outcome of fetching a recipe page
+func (db *SQLiteDB) RecordRecipeImport(ctx context.Context, attempt *database.RecipeImportAttempt) error {
+	query := `
+		INSERT INTO recipe_import_attempts (id, domain, succeeded, created_at)
+		VALUES (?, ?, ?, ?)
+	`
+	_, err := db.conn(ctx).ExecContext(ctx, query, attempt.ID, attempt.Domain, attempt.Succeeded, attempt.CreatedAt.UTC())
+	return err
+}
+
+// CountRecipeImports tallies import attempts between start and end by
+// site, most failures first
+func (db *SQLiteDB) CountRecipeImports(ctx context.Context, start, end time.Time) ([]*database.RecipeImportCount, error) {
+	query := `
+		SELECT domain, COUNT(*), SUM(CASE WHEN succeeded THEN 0 ELSE 1 END) AS failures
+		FROM recipe_import_attempts
+		WHERE created_at >= ? AND created_at < ?
+		GROUP BY domain
+		ORDER BY failures DESC, domain
+	`
+	rows, err := db.conn(ctx).QueryContext(ctx, query, start.UTC(), end.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []*database.RecipeImportCount{}
+	for rows.Next() {
+		var count database.RecipeImportCount
+		if err := rows.Scan(&count.Domain, &count.Attempts, &count.Failures); err != nil {
+			return nil, err
+		}
+		counts = append(counts, &count)
+	}
+	return counts, rows.Err()
+}
+
+// PurgeRecipeImports removes import attempts made before the given time
+func (db *SQLiteDB) PurgeRecipeImports(ctx context.Context, before time.Time) (int64, error) {
+	result, err := db.conn(ctx).ExecContext(ctx, `DELETE FROM recipe_import_attempts WHERE created_at < ?`, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// CountActiveUsers counts the users who signed in, refreshed a session or
+// used an API token between start and end. Only the latest use of each is
+// kept, so a period is counted accurately only until it ends.
+func (db *SQLiteDB) CountActiveUsers(ctx context.Context, start, end time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*) FROM users u
+		WHERE (u.last_login_at >= ? AND u.last_login_at < ?)
+			OR EXISTS (SELECT 1 FROM auth_sessions s WHERE s.user_id = u.id AND s.last_used_at >= ? AND s.last_used_at < ?)
+			OR EXISTS (SELECT 1 FROM api_tokens t WHERE t.user_id = u.id AND t.last_used_at >= ? AND t.last_used_at < ?)
+	`
+	start, end = start.UTC(), end.UTC()
+	var n int
+	err := db.conn(ctx).QueryRowContext(ctx, query, start, end, start, end, start, end).Scan(&n)
+	return n, err
+}
+
+// CountCookingSessions counts the cooking sessions started between start
+// and end, and those completed in that time
+func (db *SQLiteDB) CountCookingSessions(ctx context.Context, start, end time.Time) (int, int, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM cooking_sessions WHERE started_at >= ? AND started_at < ?),
+			(SELECT COUNT(*) FROM cooking_sessions WHERE status = ? AND finished_at >= ? AND finished_at < ?)
+	`
+	start, end = start.UTC(), end.UTC()
+	var started, completed int
+	err := db.conn(ctx).QueryRowContext(ctx, query, start, end, database.CookingSessionCompleted, start, end).
+		Scan(&started, &completed)
+	return started, completed, err
+}
+
+// ReplaceInstanceMetrics replaces a period's rows for the named metrics
+func (db *SQLiteDB) ReplaceInstanceMetrics(ctx context.Context, period string, names []string, metrics []*database.InstanceMetric) error {
+	if len(names) == 0 {
+		return nil
+	}
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		args := append([]any{period}, stringArgs(names)...)
+		_, err := db.conn(ctx).ExecContext(ctx,
+			`DELETE FROM instance_metrics WHERE period = ? AND metric IN (`+placeholderList(len(names))+`)`, args...)
+		if err != nil {
+			return err
+		}
+		for _, metric := range metrics {
+			_, err := db.conn(ctx).ExecContext(ctx,
+				`INSERT INTO instance_metrics (period, metric, dimension, value, updated_at) VALUES (?, ?, ?, ?, ?)`,
+				metric.Period, metric.Metric, metric.Dimension, metric.Value, metric.UpdatedAt.UTC())
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListInstanceMetrics returns the rolled-up figures for the given periods
+func (db *SQLiteDB) ListInstanceMetrics(ctx context.Context, periods []string) ([]*database.InstanceMetric, error) {
+	metrics := []*database.InstanceMetric{}
+	if len(periods) == 0 {
+		return metrics, nil
+	}
+
+	query := `SELECT period, metric, dimension, value, updated_at FROM instance_metrics
+		WHERE period IN (` + placeholderList(len(periods)) + `)
+		ORDER BY period, metric, dimension`
+	rows, err := db.conn(ctx).QueryContext(ctx, query, stringArgs(periods)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var metric database.InstanceMetric
+		if err := rows.Scan(&metric.Period, &metric.Metric, &metric.Dimension, &metric.Value, &metric.UpdatedAt); err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, &metric)
+	}
+	return metrics, rows.Err()
+}
@@ -0,0 +1,67 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"context"
+	"embed"
+
+	migratedb "github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
+	"github.com/rghsoftware/space-food/internal/database/migrate"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var migrations = migrate.MustLoad(migrationFiles, "migrations")
+
+// Migrations returns the migrator for this database
+func (db *SQLiteDB) Migrations() *migrate.Migrator {
+	return migrate.New(migrationDriver{db}, migrations)
+}
+
+// Migrate applies pending migrations, refusing when the schema is newer
+// than this build
+func (db *SQLiteDB) Migrate(ctx context.Context) error {
+	return db.Migrations().Up(ctx)
+}
+
+// migrationDriver lets golang-migrate record applied migrations in
+// schema_migrations
+type migrationDriver struct {
+	db *SQLiteDB
+}
+
+func (d migrationDriver) HasTables(ctx context.Context) (bool, error) {
+	var n int
+	err := d.db.conn(ctx).QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'users'`).Scan(&n)
+	return n > 0, err
+}
+
+// Open hands the library the application's pool, which it would close
+// along with its driver, so there is nothing to release. The pool's single
+// connection also keeps an in-memory database in one piece.
+func (d migrationDriver) Open(ctx context.Context) (migratedb.Driver, func() error, error) {
+	driver, err := sqlite3.WithInstance(d.db.db, &sqlite3.Config{})
+	if err != nil {
+		return nil, nil, err
+	}
+	return driver, func() error { return nil }, nil
+}
@@ -0,0 +1,15 @@
+-- Reverts: Initial database schema for Space Food application (SQLite)
+
+DROP TABLE IF EXISTS ingredients_fts;
+DROP TABLE IF EXISTS recipes_fts;
+DROP TABLE IF EXISTS nutrition_logs;
+DROP TABLE IF EXISTS shopping_list_items;
+DROP TABLE IF EXISTS pantry_items;
+DROP TABLE IF EXISTS planned_meals;
+DROP TABLE IF EXISTS meal_plans;
+DROP TABLE IF EXISTS recipe_nutrition;
+DROP TABLE IF EXISTS ingredients;
+DROP TABLE IF EXISTS recipe_tags;
+DROP TABLE IF EXISTS recipe_categories;
+DROP TABLE IF EXISTS recipes;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,42 @@
+-- Households, membership and invitations (SQLite)
+
+-- Households table
+CREATE TABLE households (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    owner_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_households_owner_id ON households(owner_id);
+
+-- Household members junction table
+CREATE TABLE household_members (
+    household_id TEXT NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL DEFAULT 'member',
+    joined_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (household_id, user_id)
+);
+
+CREATE INDEX idx_household_members_user_id ON household_members(user_id);
+
+-- Household invitations table
+CREATE TABLE household_invitations (
+    id TEXT PRIMARY KEY,
+    household_id TEXT NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    invited_by TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email TEXT,
+    code_hash TEXT UNIQUE NOT NULL,
+    role TEXT NOT NULL DEFAULT 'member',
+    status TEXT NOT NULL DEFAULT 'pending',
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    responded_at DATETIME,
+    responded_by TEXT REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_household_invitations_household_id ON household_invitations(household_id);
+CREATE INDEX idx_household_invitations_email ON household_invitations(email);
+CREATE INDEX idx_household_invitations_status ON household_invitations(status);
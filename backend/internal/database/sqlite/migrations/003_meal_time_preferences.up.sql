@@ -0,0 +1,9 @@
+-- Per-user meal windows for time-of-day aware suggestions (SQLite)
+
+CREATE TABLE meal_time_preferences (
+    user_id TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    timezone TEXT NOT NULL DEFAULT 'UTC',
+    windows TEXT NOT NULL DEFAULT '[]',
+    breakfast_all_day INTEGER DEFAULT 0,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
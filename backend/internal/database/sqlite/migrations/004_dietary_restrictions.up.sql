@@ -0,0 +1,14 @@
+-- Per-user allergies, intolerances and dietary preferences (SQLite)
+
+CREATE TABLE dietary_restrictions (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    name TEXT NOT NULL,
+    severity TEXT,
+    notes TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, kind, name)
+);
+
+CREATE INDEX idx_dietary_restrictions_user_id ON dietary_restrictions(user_id);
@@ -0,0 +1,3 @@
+-- Reverts: Refresh-token sessions, one per signed-in device (SQLite)
+
+DROP TABLE IF EXISTS auth_sessions;
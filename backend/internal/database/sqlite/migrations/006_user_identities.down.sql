@@ -0,0 +1,3 @@
+-- Reverts: Accounts at external identity providers (OIDC) linked to local users (SQLite)
+
+DROP TABLE IF EXISTS user_identities;
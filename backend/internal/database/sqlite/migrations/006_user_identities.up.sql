@@ -0,0 +1,11 @@
+-- Accounts at external identity providers (OIDC) linked to local users (SQLite)
+
+CREATE TABLE user_identities (
+    provider TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (provider, subject)
+);
+
+CREATE INDEX idx_user_identities_user_id ON user_identities(user_id);
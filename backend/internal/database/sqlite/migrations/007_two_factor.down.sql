@@ -0,0 +1,4 @@
+-- Reverts: TOTP two-factor authentication and recovery codes (SQLite)
+
+DROP TABLE IF EXISTS user_recovery_codes;
+DROP TABLE IF EXISTS user_totp;
@@ -0,0 +1,17 @@
+-- TOTP two-factor authentication and recovery codes (SQLite)
+
+CREATE TABLE user_totp (
+    user_id TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret TEXT NOT NULL,
+    enabled INTEGER DEFAULT 0,
+    last_used_step INTEGER DEFAULT 0,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    confirmed_at DATETIME
+);
+
+CREATE TABLE user_recovery_codes (
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    used_at DATETIME,
+    PRIMARY KEY (user_id, code_hash)
+);
@@ -0,0 +1,3 @@
+-- Reverts: Personal access tokens for automation (SQLite)
+
+DROP TABLE IF EXISTS api_tokens;
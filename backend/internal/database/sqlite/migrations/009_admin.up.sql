@@ -0,0 +1,9 @@
+-- Instance administration (SQLite)
+
+ALTER TABLE users ADD COLUMN is_admin INTEGER DEFAULT 0;
+
+CREATE TABLE instance_settings (
+    key TEXT PRIMARY KEY,
+    value TEXT NOT NULL,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,3 @@
+-- Reverts: Monthly AI usage per user and provider, for budgets and reporting (SQLite)
+
+DROP TABLE IF EXISTS ai_usage;
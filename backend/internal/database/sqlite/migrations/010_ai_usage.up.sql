@@ -0,0 +1,15 @@
+-- Monthly AI usage per user and provider, for budgets and reporting (SQLite)
+
+CREATE TABLE ai_usage (
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider TEXT NOT NULL,
+    period TEXT NOT NULL,
+    requests INTEGER DEFAULT 0,
+    input_tokens INTEGER DEFAULT 0,
+    output_tokens INTEGER DEFAULT 0,
+    cost REAL DEFAULT 0,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, provider, period)
+);
+
+CREATE INDEX idx_ai_usage_period ON ai_usage(period);
@@ -0,0 +1,3 @@
+-- Reverts: Cached AI outputs keyed by a hash of their prompt inputs (SQLite)
+
+DROP TABLE IF EXISTS ai_cache;
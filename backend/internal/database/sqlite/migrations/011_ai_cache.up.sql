@@ -0,0 +1,14 @@
+-- Cached AI outputs keyed by a hash of their prompt inputs (SQLite)
+
+CREATE TABLE ai_cache (
+    cache_key TEXT PRIMARY KEY,
+    kind TEXT NOT NULL,
+    recipe_id TEXT REFERENCES recipes(id) ON DELETE CASCADE,
+    payload TEXT NOT NULL,
+    hits INTEGER DEFAULT 0,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    expires_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_ai_cache_recipe_id ON ai_cache(recipe_id);
+CREATE INDEX idx_ai_cache_expires_at ON ai_cache(expires_at);
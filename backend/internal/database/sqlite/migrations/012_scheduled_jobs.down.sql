@@ -0,0 +1,4 @@
+-- Reverts: Background job schedules and run history (SQLite)
+
+DROP TABLE IF EXISTS job_runs;
+DROP TABLE IF EXISTS scheduled_jobs;
@@ -0,0 +1,24 @@
+-- Background job schedules and run history (SQLite)
+
+CREATE TABLE scheduled_jobs (
+    name TEXT PRIMARY KEY,
+    schedule TEXT NOT NULL,
+    next_run_at DATETIME NOT NULL,
+    last_run_at DATETIME,
+    last_status TEXT
+);
+
+CREATE TABLE job_runs (
+    id TEXT PRIMARY KEY,
+    job_name TEXT NOT NULL,
+    triggered_by TEXT NOT NULL,
+    status TEXT NOT NULL,
+    result TEXT,
+    error TEXT,
+    started_at DATETIME NOT NULL,
+    finished_at DATETIME
+);
+
+CREATE INDEX idx_job_runs_job_name ON job_runs(job_name, started_at);
+CREATE INDEX idx_job_runs_status ON job_runs(status);
+CREATE INDEX idx_job_runs_started_at ON job_runs(started_at);
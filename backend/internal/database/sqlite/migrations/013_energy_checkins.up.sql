@@ -0,0 +1,12 @@
+-- Self-reported energy levels over time (SQLite)
+
+CREATE TABLE energy_checkins (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    level INTEGER NOT NULL CHECK (level BETWEEN 1 AND 5),
+    note TEXT,
+    recorded_at DATETIME NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_energy_checkins_user_recorded ON energy_checkins(user_id, recorded_at);
@@ -0,0 +1,3 @@
+-- Reverts: Leftovers with estimated eat-by dates (SQLite)
+
+DROP TABLE IF EXISTS leftovers;
@@ -0,0 +1,21 @@
+-- Leftovers with estimated eat-by dates (SQLite)
+
+CREATE TABLE leftovers (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    recipe_id TEXT REFERENCES recipes(id) ON DELETE SET NULL,
+    name TEXT NOT NULL,
+    food_type TEXT NOT NULL,
+    portions REAL NOT NULL,
+    container TEXT,
+    storage TEXT NOT NULL,
+    stored_at DATETIME NOT NULL,
+    eat_by DATETIME NOT NULL,
+    notes TEXT,
+    finished_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_leftovers_user_id ON leftovers(user_id, finished_at);
+CREATE INDEX idx_leftovers_eat_by ON leftovers(eat_by);
@@ -0,0 +1,16 @@
+-- Cache of packaged food products looked up by barcode (SQLite)
+
+CREATE TABLE food_products (
+    barcode TEXT PRIMARY KEY,
+    found INTEGER NOT NULL,
+    source TEXT NOT NULL,
+    name TEXT,
+    brand TEXT,
+    quantity TEXT,
+    serving_size TEXT,
+    image_url TEXT,
+    categories TEXT NOT NULL DEFAULT '[]',
+    nutrition_100g TEXT,
+    nutrition_serving TEXT,
+    fetched_at DATETIME NOT NULL
+);
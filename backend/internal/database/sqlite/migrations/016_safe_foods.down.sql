@@ -0,0 +1,3 @@
+-- Reverts: Foods a user explicitly marks as safe (SQLite)
+
+DROP TABLE IF EXISTS safe_foods;
@@ -0,0 +1,13 @@
+-- Foods a user explicitly marks as safe (SQLite)
+
+CREATE TABLE safe_foods (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    recipe_id TEXT REFERENCES recipes(id) ON DELETE SET NULL,
+    protected INTEGER NOT NULL DEFAULT 1,
+    notes TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, name)
+);
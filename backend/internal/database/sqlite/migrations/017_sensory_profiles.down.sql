@@ -0,0 +1,3 @@
+-- Reverts: Per-user sensory food preferences (SQLite)
+
+DROP TABLE IF EXISTS sensory_profiles;
@@ -0,0 +1,11 @@
+-- Per-user sensory food preferences (SQLite)
+
+CREATE TABLE sensory_profiles (
+    user_id TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    avoid_textures TEXT NOT NULL DEFAULT '[]',
+    preferred_temperatures TEXT NOT NULL DEFAULT '[]',
+    smell_aversions TEXT NOT NULL DEFAULT '[]',
+    eating_style TEXT NOT NULL DEFAULT 'no_preference',
+    notes TEXT,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
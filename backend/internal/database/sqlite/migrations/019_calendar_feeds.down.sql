@@ -0,0 +1,3 @@
+-- Reverts: Secret iCalendar subscription feeds (SQLite)
+
+DROP TABLE IF EXISTS calendar_feeds;
@@ -0,0 +1,13 @@
+-- Secret iCalendar subscription feeds (SQLite)
+
+CREATE TABLE calendar_feeds (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    prefix TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    last_used_at DATETIME
+);
+
+CREATE INDEX idx_calendar_feeds_user_id ON calendar_feeds(user_id);
@@ -0,0 +1,28 @@
+-- External calendars whose events block meal plan slots (SQLite)
+
+CREATE TABLE external_calendars (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    url TEXT NOT NULL,
+    meal_types TEXT NOT NULL DEFAULT '["dinner"]',
+    last_synced_at DATETIME,
+    last_error TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_external_calendars_user_id ON external_calendars(user_id);
+CREATE INDEX idx_external_calendars_synced ON external_calendars(last_synced_at);
+
+CREATE TABLE external_calendar_events (
+    calendar_id TEXT NOT NULL REFERENCES external_calendars(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    uid TEXT NOT NULL,
+    summary TEXT,
+    starts_at DATETIME NOT NULL,
+    ends_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_external_calendar_events_calendar ON external_calendar_events(calendar_id);
+CREATE INDEX idx_external_calendar_events_user_time ON external_calendar_events(user_id, starts_at, ends_at);
@@ -0,0 +1,6 @@
+-- Indexes matching the newest-first keyset order of paginated lists (SQLite)
+
+CREATE INDEX idx_recipes_user_created ON recipes(user_id, created_at, id);
+CREATE INDEX idx_job_runs_started_id ON job_runs(started_at, id);
+CREATE INDEX idx_webhook_deliveries_webhook_created ON webhook_deliveries(webhook_id, created_at, id);
+DROP INDEX idx_webhook_deliveries_webhook;
@@ -0,0 +1,3 @@
+-- Reverts: Saved versions of recipes, one per edit (SQLite)
+
+DROP TABLE IF EXISTS recipe_revisions;
@@ -0,0 +1,12 @@
+-- Saved versions of recipes, one per edit (SQLite)
+
+CREATE TABLE recipe_revisions (
+    id TEXT PRIMARY KEY,
+    recipe_id TEXT NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    restored_from INTEGER,
+    content TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    UNIQUE (recipe_id, revision)
+);
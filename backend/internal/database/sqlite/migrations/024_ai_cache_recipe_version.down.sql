@@ -0,0 +1,3 @@
+-- Reverts: The version of the recipe each cached AI output was generated from (SQLite)
+
+ALTER TABLE ai_cache DROP COLUMN recipe_updated_at;
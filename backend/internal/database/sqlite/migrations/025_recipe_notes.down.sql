@@ -0,0 +1,3 @@
+-- Reverts: Per-user cook notes and standing modifications for recipes (SQLite)
+
+DROP TABLE IF EXISTS recipe_notes;
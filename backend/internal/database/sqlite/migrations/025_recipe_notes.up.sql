@@ -0,0 +1,12 @@
+-- Per-user cook notes and standing modifications for recipes (SQLite)
+
+CREATE TABLE recipe_notes (
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    recipe_id TEXT NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    notes TEXT,
+    modifications TEXT NOT NULL DEFAULT '[]',
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, recipe_id)
+);
+
+CREATE INDEX idx_recipe_notes_recipe_id ON recipe_notes(recipe_id);
@@ -0,0 +1,13 @@
+-- Secret links that publish a recipe for other Space Food instances to import (SQLite)
+
+CREATE TABLE recipe_shares (
+    id TEXT PRIMARY KEY,
+    recipe_id TEXT NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL UNIQUE,
+    prefix TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    last_used_at DATETIME
+);
+
+CREATE INDEX idx_recipe_shares_recipe_id ON recipe_shares(recipe_id);
@@ -0,0 +1,4 @@
+-- Reverts: Cooking sessions that step through a recipe, with their timers (SQLite)
+
+DROP TABLE IF EXISTS cooking_timers;
+DROP TABLE IF EXISTS cooking_sessions;
@@ -0,0 +1,34 @@
+-- Cooking sessions that step through a recipe, with their timers (SQLite)
+
+CREATE TABLE cooking_sessions (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    recipe_id TEXT REFERENCES recipes(id) ON DELETE SET NULL,
+    recipe_title TEXT NOT NULL,
+    status TEXT NOT NULL,
+    current_step INTEGER NOT NULL DEFAULT 0,
+    steps TEXT NOT NULL DEFAULT '[]',
+    started_at DATETIME NOT NULL,
+    paused_at DATETIME,
+    finished_at DATETIME,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_cooking_sessions_user_id ON cooking_sessions(user_id, status);
+
+CREATE TABLE cooking_timers (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    session_id TEXT REFERENCES cooking_sessions(id) ON DELETE CASCADE,
+    step_index INTEGER,
+    label TEXT NOT NULL,
+    duration_seconds INTEGER NOT NULL,
+    status TEXT NOT NULL,
+    ends_at DATETIME,
+    remaining_seconds INTEGER NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_cooking_timers_user_id ON cooking_timers(user_id, status);
+CREATE INDEX idx_cooking_timers_session_id ON cooking_timers(session_id);
@@ -0,0 +1,8 @@
+-- Reverts: How a finished cooking session went: taste, would make again, photo and time taken (SQLite)
+
+DROP INDEX IF EXISTS idx_cooking_sessions_recipe_id;
+ALTER TABLE cooking_sessions DROP COLUMN reflected_at;
+ALTER TABLE cooking_sessions DROP COLUMN actual_minutes;
+ALTER TABLE cooking_sessions DROP COLUMN photo_url;
+ALTER TABLE cooking_sessions DROP COLUMN would_make_again;
+ALTER TABLE cooking_sessions DROP COLUMN tasted;
@@ -0,0 +1,3 @@
+-- Reverts: How hard each cook found a recipe, for effort scores (SQLite)
+
+ALTER TABLE cooking_sessions DROP COLUMN difficulty;
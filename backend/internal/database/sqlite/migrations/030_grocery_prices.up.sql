@@ -0,0 +1,28 @@
+-- Grocery prices paid or seen per store, shopping list prices and monthly budgets (SQLite)
+
+ALTER TABLE shopping_list_items ADD COLUMN price REAL;
+ALTER TABLE shopping_list_items ADD COLUMN store TEXT;
+
+CREATE TABLE grocery_prices (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    store TEXT NOT NULL DEFAULT '',
+    name TEXT NOT NULL,
+    quantity REAL NOT NULL DEFAULT 1,
+    unit TEXT NOT NULL DEFAULT '',
+    price REAL NOT NULL,
+    purchased INTEGER NOT NULL DEFAULT 0,
+    shopping_item_id TEXT REFERENCES shopping_list_items(id) ON DELETE SET NULL,
+    recorded_at DATETIME NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_grocery_prices_user_id ON grocery_prices(user_id, recorded_at);
+CREATE INDEX idx_grocery_prices_shopping_item_id ON grocery_prices(shopping_item_id);
+
+CREATE TABLE grocery_budgets (
+    user_id TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    monthly_limit REAL NOT NULL,
+    currency TEXT NOT NULL DEFAULT '',
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
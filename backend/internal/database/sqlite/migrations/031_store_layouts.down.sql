@@ -0,0 +1,3 @@
+-- Reverts: Aisle order of each household's grocery stores, for sorting shopping lists (SQLite)
+
+DROP TABLE IF EXISTS store_layouts;
@@ -0,0 +1,4 @@
+-- Reverts: Kitchen equipment each household owns, and the equipment recipes need (SQLite)
+
+DROP TABLE IF EXISTS recipe_equipment;
+DROP TABLE IF EXISTS household_equipment;
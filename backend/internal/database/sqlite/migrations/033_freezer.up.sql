@@ -0,0 +1,7 @@
+-- Frozen batch-cooked portions: reheat instructions and thaw reminders (SQLite)
+
+ALTER TABLE leftovers ADD COLUMN reheat TEXT;
+ALTER TABLE leftovers ADD COLUMN thaw_at DATETIME;
+ALTER TABLE leftovers ADD COLUMN thaw_reminded_at DATETIME;
+
+CREATE INDEX idx_leftovers_thaw_at ON leftovers(thaw_at);
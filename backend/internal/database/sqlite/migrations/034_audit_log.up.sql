@@ -0,0 +1,17 @@
+-- Audit trail of sensitive actions: sign-ins, membership changes, deletions, exports and administration (SQLite)
+
+CREATE TABLE audit_events (
+    id TEXT PRIMARY KEY,
+    user_id TEXT REFERENCES users(id) ON DELETE SET NULL,
+    action TEXT NOT NULL,
+    target_type TEXT,
+    target_id TEXT,
+    details TEXT,
+    ip_address TEXT,
+    user_agent TEXT,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_audit_events_created_at ON audit_events(created_at);
+CREATE INDEX idx_audit_events_user_id ON audit_events(user_id, created_at);
+CREATE INDEX idx_audit_events_action ON audit_events(action, created_at);
@@ -0,0 +1,14 @@
+-- Responses to requests sent with an Idempotency-Key, replayed when a client retries (SQLite)
+
+CREATE TABLE idempotency_keys (
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    idempotency_key TEXT NOT NULL,
+    request_hash TEXT NOT NULL,
+    status_code INTEGER NOT NULL DEFAULT 0,
+    content_type TEXT,
+    response_body BLOB,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (user_id, idempotency_key)
+);
+
+CREATE INDEX idx_idempotency_keys_created_at ON idempotency_keys(created_at);
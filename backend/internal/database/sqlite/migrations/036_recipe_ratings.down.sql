@@ -0,0 +1,3 @@
+-- Reverts: Star ratings users give recipes, shared with their households (SQLite)
+
+DROP TABLE IF EXISTS recipe_ratings;
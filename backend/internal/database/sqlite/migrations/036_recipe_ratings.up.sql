@@ -0,0 +1,11 @@
+-- Star ratings users give recipes, shared with their households (SQLite)
+
+CREATE TABLE recipe_ratings (
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    recipe_id TEXT NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    stars INTEGER NOT NULL CHECK (stars BETWEEN 1 AND 5),
+    updated_at DATETIME NOT NULL,
+    PRIMARY KEY (user_id, recipe_id)
+);
+
+CREATE INDEX idx_recipe_ratings_recipe_id ON recipe_ratings(recipe_id);
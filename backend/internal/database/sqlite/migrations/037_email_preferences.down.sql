@@ -0,0 +1,3 @@
+-- Reverts: Per-user choice of the email they receive (SQLite)
+
+DROP TABLE IF EXISTS email_preferences;
@@ -0,0 +1,8 @@
+-- Per-user choice of the email they receive (SQLite)
+
+CREATE TABLE email_preferences (
+    user_id TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    household_invites BOOLEAN NOT NULL DEFAULT 1,
+    weekly_digest BOOLEAN NOT NULL DEFAULT 0,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
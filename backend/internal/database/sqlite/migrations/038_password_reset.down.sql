@@ -0,0 +1,4 @@
+-- Reverts: Password reset (SQLite)
+
+DROP TABLE IF EXISTS password_reset_tokens;
+ALTER TABLE users DROP COLUMN must_change_password;
@@ -0,0 +1,3 @@
+-- Reverts: Device handoff tokens (SQLite)
+
+DROP TABLE IF EXISTS handoff_tokens;
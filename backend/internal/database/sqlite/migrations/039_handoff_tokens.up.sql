@@ -0,0 +1,13 @@
+-- Device handoff tokens (SQLite)
+
+CREATE TABLE handoff_tokens (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash TEXT UNIQUE NOT NULL,
+    target TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    expires_at DATETIME NOT NULL,
+    used_at DATETIME
+);
+
+CREATE INDEX idx_handoff_tokens_user_id ON handoff_tokens(user_id);
@@ -0,0 +1,16 @@
+-- Reverts: Change log for offline sync (SQLite)
+
+DROP TRIGGER IF EXISTS sync_recipes_insert;
+DROP TRIGGER IF EXISTS sync_recipes_update;
+DROP TRIGGER IF EXISTS sync_recipes_delete;
+DROP TRIGGER IF EXISTS sync_meal_plans_insert;
+DROP TRIGGER IF EXISTS sync_meal_plans_update;
+DROP TRIGGER IF EXISTS sync_meal_plans_delete;
+DROP TRIGGER IF EXISTS sync_shopping_list_items_insert;
+DROP TRIGGER IF EXISTS sync_shopping_list_items_update;
+DROP TRIGGER IF EXISTS sync_shopping_list_items_delete;
+DROP TRIGGER IF EXISTS sync_cooking_sessions_insert;
+DROP TRIGGER IF EXISTS sync_cooking_sessions_update;
+DROP TRIGGER IF EXISTS sync_cooking_sessions_delete;
+DROP TABLE IF EXISTS sync_changes;
+DROP TABLE IF EXISTS sync_cursors;
@@ -0,0 +1,165 @@
+-- Change log for offline sync (SQLite)
+--
+-- Triggers keep one row per changed record: its owner, the record and
+-- whether it was deleted. Each user's changes are numbered by seq, which
+-- moves forward every time the record changes again, so reading the rows
+-- after a client's last seq gives everything it hasn't seen.
+
+CREATE TABLE sync_cursors (
+    user_id TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    seq INTEGER NOT NULL DEFAULT 0,
+    purged_seq INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE sync_changes (
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    seq INTEGER NOT NULL,
+    entity TEXT NOT NULL,
+    entity_id TEXT NOT NULL,
+    deleted INTEGER NOT NULL DEFAULT 0,
+    changed_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, seq)
+);
+
+CREATE UNIQUE INDEX idx_sync_changes_entity ON sync_changes(user_id, entity, entity_id);
+CREATE INDEX idx_sync_changes_tombstones ON sync_changes(deleted, changed_at);
+
+-- Records changed before sync existed
+INSERT INTO sync_changes (user_id, seq, entity, entity_id)
+SELECT user_id, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY updated_at, entity, id), entity, id
+FROM (
+    SELECT user_id, 'recipe' AS entity, id, updated_at FROM recipes
+    UNION ALL SELECT user_id, 'meal_plan', id, updated_at FROM meal_plans
+    UNION ALL SELECT user_id, 'shopping_list_item', id, updated_at FROM shopping_list_items
+    UNION ALL SELECT user_id, 'cooking_session', id, updated_at FROM cooking_sessions
+);
+
+INSERT INTO sync_cursors (user_id, seq)
+SELECT user_id, MAX(seq) FROM sync_changes GROUP BY user_id;
+
+-- Deletes leave a tombstone, except for records removed along with their
+-- owner's account
+
+CREATE TRIGGER sync_recipes_insert AFTER INSERT ON recipes
+BEGIN
+    INSERT INTO sync_cursors (user_id, seq) VALUES (NEW.user_id, 1)
+    ON CONFLICT (user_id) DO UPDATE SET seq = seq + 1;
+    INSERT INTO sync_changes (user_id, seq, entity, entity_id, deleted)
+    SELECT user_id, seq, 'recipe', NEW.id, 0 FROM sync_cursors WHERE user_id = NEW.user_id
+    ON CONFLICT (user_id, entity, entity_id) DO UPDATE
+    SET seq = excluded.seq, deleted = excluded.deleted, changed_at = excluded.changed_at;
+END;
+
+CREATE TRIGGER sync_recipes_update AFTER UPDATE ON recipes
+BEGIN
+    INSERT INTO sync_cursors (user_id, seq) VALUES (NEW.user_id, 1)
+    ON CONFLICT (user_id) DO UPDATE SET seq = seq + 1;
+    INSERT INTO sync_changes (user_id, seq, entity, entity_id, deleted)
+    SELECT user_id, seq, 'recipe', NEW.id, 0 FROM sync_cursors WHERE user_id = NEW.user_id
+    ON CONFLICT (user_id, entity, entity_id) DO UPDATE
+    SET seq = excluded.seq, deleted = excluded.deleted, changed_at = excluded.changed_at;
+END;
+
+CREATE TRIGGER sync_recipes_delete AFTER DELETE ON recipes
+WHEN EXISTS (SELECT 1 FROM users WHERE id = OLD.user_id)
+BEGIN
+    INSERT INTO sync_cursors (user_id, seq) VALUES (OLD.user_id, 1)
+    ON CONFLICT (user_id) DO UPDATE SET seq = seq + 1;
+    INSERT INTO sync_changes (user_id, seq, entity, entity_id, deleted)
+    SELECT user_id, seq, 'recipe', OLD.id, 1 FROM sync_cursors WHERE user_id = OLD.user_id
+    ON CONFLICT (user_id, entity, entity_id) DO UPDATE
+    SET seq = excluded.seq, deleted = excluded.deleted, changed_at = excluded.changed_at;
+END;
+
+CREATE TRIGGER sync_meal_plans_insert AFTER INSERT ON meal_plans
+BEGIN
+    INSERT INTO sync_cursors (user_id, seq) VALUES (NEW.user_id, 1)
+    ON CONFLICT (user_id) DO UPDATE SET seq = seq + 1;
+    INSERT INTO sync_changes (user_id, seq, entity, entity_id, deleted)
+    SELECT user_id, seq, 'meal_plan', NEW.id, 0 FROM sync_cursors WHERE user_id = NEW.user_id
+    ON CONFLICT (user_id, entity, entity_id) DO UPDATE
+    SET seq = excluded.seq, deleted = excluded.deleted, changed_at = excluded.changed_at;
+END;
+
+CREATE TRIGGER sync_meal_plans_update AFTER UPDATE ON meal_plans
+BEGIN
+    INSERT INTO sync_cursors (user_id, seq) VALUES (NEW.user_id, 1)
+    ON CONFLICT (user_id) DO UPDATE SET seq = seq + 1;
+    INSERT INTO sync_changes (user_id, seq, entity, entity_id, deleted)
+    SELECT user_id, seq, 'meal_plan', NEW.id, 0 FROM sync_cursors WHERE user_id = NEW.user_id
+    ON CONFLICT (user_id, entity, entity_id) DO UPDATE
+    SET seq = excluded.seq, deleted = excluded.deleted, changed_at = excluded.changed_at;
+END;
+
+CREATE TRIGGER sync_meal_plans_delete AFTER DELETE ON meal_plans
+WHEN EXISTS (SELECT 1 FROM users WHERE id = OLD.user_id)
+BEGIN
+    INSERT INTO sync_cursors (user_id, seq) VALUES (OLD.user_id, 1)
+    ON CONFLICT (user_id) DO UPDATE SET seq = seq + 1;
+    INSERT INTO sync_changes (user_id, seq, entity, entity_id, deleted)
+    SELECT user_id, seq, 'meal_plan', OLD.id, 1 FROM sync_cursors WHERE user_id = OLD.user_id
+    ON CONFLICT (user_id, entity, entity_id) DO UPDATE
+    SET seq = excluded.seq, deleted = excluded.deleted, changed_at = excluded.changed_at;
+END;
+
+CREATE TRIGGER sync_shopping_list_items_insert AFTER INSERT ON shopping_list_items
+BEGIN
+    INSERT INTO sync_cursors (user_id, seq) VALUES (NEW.user_id, 1)
+    ON CONFLICT (user_id) DO UPDATE SET seq = seq + 1;
+    INSERT INTO sync_changes (user_id, seq, entity, entity_id, deleted)
+    SELECT user_id, seq, 'shopping_list_item', NEW.id, 0 FROM sync_cursors WHERE user_id = NEW.user_id
+    ON CONFLICT (user_id, entity, entity_id) DO UPDATE
+    SET seq = excluded.seq, deleted = excluded.deleted, changed_at = excluded.changed_at;
+END;
+
+CREATE TRIGGER sync_shopping_list_items_update AFTER UPDATE ON shopping_list_items
+BEGIN
+    INSERT INTO sync_cursors (user_id, seq) VALUES (NEW.user_id, 1)
+    ON CONFLICT (user_id) DO UPDATE SET seq = seq + 1;
+    INSERT INTO sync_changes (user_id, seq, entity, entity_id, deleted)
+    SELECT user_id, seq, 'shopping_list_item', NEW.id, 0 FROM sync_cursors WHERE user_id = NEW.user_id
+    ON CONFLICT (user_id, entity, entity_id) DO UPDATE
+    SET seq = excluded.seq, deleted = excluded.deleted, changed_at = excluded.changed_at;
+END;
+
+CREATE TRIGGER sync_shopping_list_items_delete AFTER DELETE ON shopping_list_items
+WHEN EXISTS (SELECT 1 FROM users WHERE id = OLD.user_id)
+BEGIN
+    INSERT INTO sync_cursors (user_id, seq) VALUES (OLD.user_id, 1)
+    ON CONFLICT (user_id) DO UPDATE SET seq = seq + 1;
+    INSERT INTO sync_changes (user_id, seq, entity, entity_id, deleted)
+    SELECT user_id, seq, 'shopping_list_item', OLD.id, 1 FROM sync_cursors WHERE user_id = OLD.user_id
+    ON CONFLICT (user_id, entity, entity_id) DO UPDATE
+    SET seq = excluded.seq, deleted = excluded.deleted, changed_at = excluded.changed_at;
+END;
+
+CREATE TRIGGER sync_cooking_sessions_insert AFTER INSERT ON cooking_sessions
+BEGIN
+    INSERT INTO sync_cursors (user_id, seq) VALUES (NEW.user_id, 1)
+    ON CONFLICT (user_id) DO UPDATE SET seq = seq + 1;
+    INSERT INTO sync_changes (user_id, seq, entity, entity_id, deleted)
+    SELECT user_id, seq, 'cooking_session', NEW.id, 0 FROM sync_cursors WHERE user_id = NEW.user_id
+    ON CONFLICT (user_id, entity, entity_id) DO UPDATE
+    SET seq = excluded.seq, deleted = excluded.deleted, changed_at = excluded.changed_at;
+END;
+
+CREATE TRIGGER sync_cooking_sessions_update AFTER UPDATE ON cooking_sessions
+BEGIN
+    INSERT INTO sync_cursors (user_id, seq) VALUES (NEW.user_id, 1)
+    ON CONFLICT (user_id) DO UPDATE SET seq = seq + 1;
+    INSERT INTO sync_changes (user_id, seq, entity, entity_id, deleted)
+    SELECT user_id, seq, 'cooking_session', NEW.id, 0 FROM sync_cursors WHERE user_id = NEW.user_id
+    ON CONFLICT (user_id, entity, entity_id) DO UPDATE
+    SET seq = excluded.seq, deleted = excluded.deleted, changed_at = excluded.changed_at;
+END;
+
+CREATE TRIGGER sync_cooking_sessions_delete AFTER DELETE ON cooking_sessions
+WHEN EXISTS (SELECT 1 FROM users WHERE id = OLD.user_id)
+BEGIN
+    INSERT INTO sync_cursors (user_id, seq) VALUES (OLD.user_id, 1)
+    ON CONFLICT (user_id) DO UPDATE SET seq = seq + 1;
+    INSERT INTO sync_changes (user_id, seq, entity, entity_id, deleted)
+    SELECT user_id, seq, 'cooking_session', OLD.id, 1 FROM sync_cursors WHERE user_id = OLD.user_id
+    ON CONFLICT (user_id, entity, entity_id) DO UPDATE
+    SET seq = excluded.seq, deleted = excluded.deleted, changed_at = excluded.changed_at;
+END;
@@ -0,0 +1,3 @@
+-- The cook's own notes on a cooking session in progress (SQLite)
+
+ALTER TABLE cooking_sessions ADD COLUMN notes TEXT NOT NULL DEFAULT '';
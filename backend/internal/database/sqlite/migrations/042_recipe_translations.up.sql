@@ -0,0 +1,22 @@
+-- Recipes translated into other languages, and each user's preferred language (SQLite)
+
+CREATE TABLE recipe_translations (
+    recipe_id TEXT NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    language TEXT NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    instructions TEXT NOT NULL DEFAULT '',
+    ingredients TEXT NOT NULL DEFAULT '[]',
+    machine_translated BOOLEAN NOT NULL DEFAULT 0,
+    model TEXT NOT NULL DEFAULT '',
+    source_updated_at DATETIME NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (recipe_id, language)
+);
+
+CREATE TABLE user_locales (
+    user_id TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    locale TEXT NOT NULL,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,22 @@
+-- Allergens and diets detected from recipe ingredients, with the author's overrides (SQLite)
+
+CREATE TABLE recipe_dietary_analyses (
+    recipe_id TEXT PRIMARY KEY REFERENCES recipes(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    source_updated_at DATETIME NOT NULL,
+    analyzed_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE recipe_dietary_tags (
+    recipe_id TEXT NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    detected BOOLEAN NOT NULL DEFAULT 0,
+    confidence REAL NOT NULL DEFAULT 0,
+    ingredients TEXT NOT NULL DEFAULT '[]',
+    override BOOLEAN,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (recipe_id, tag)
+);
+
+CREATE INDEX idx_recipe_dietary_tags_tag ON recipe_dietary_tags(tag);
@@ -0,0 +1,5 @@
+-- Reverts: Spice level and sensory cues of recipe steps, and what a user avoids (SQLite)
+
+ALTER TABLE sensory_profiles DROP COLUMN avoid_cues;
+ALTER TABLE sensory_profiles DROP COLUMN max_spice_level;
+DROP TABLE IF EXISTS recipe_sensory_tags;
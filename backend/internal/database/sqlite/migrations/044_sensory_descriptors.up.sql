@@ -0,0 +1,11 @@
+-- Spice level and sensory cues of recipe steps, and what a user avoids (SQLite)
+
+CREATE TABLE recipe_sensory_tags (
+    recipe_id TEXT PRIMARY KEY REFERENCES recipes(id) ON DELETE CASCADE,
+    spice_level INTEGER,
+    steps TEXT NOT NULL DEFAULT '[]',
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE sensory_profiles ADD COLUMN max_spice_level INTEGER;
+ALTER TABLE sensory_profiles ADD COLUMN avoid_cues TEXT NOT NULL DEFAULT '[]';
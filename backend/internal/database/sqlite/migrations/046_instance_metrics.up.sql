@@ -0,0 +1,19 @@
+-- Recipe import outcomes by site, and figures rolled up for the admin dashboard (SQLite)
+
+CREATE TABLE recipe_import_attempts (
+    id TEXT PRIMARY KEY,
+    domain TEXT NOT NULL,
+    succeeded BOOLEAN NOT NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_recipe_import_attempts_created_at ON recipe_import_attempts(created_at);
+
+CREATE TABLE instance_metrics (
+    period TEXT NOT NULL,
+    metric TEXT NOT NULL,
+    dimension TEXT NOT NULL DEFAULT '',
+    value REAL NOT NULL,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (period, metric, dimension)
+);
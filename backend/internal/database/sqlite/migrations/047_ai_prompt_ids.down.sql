@@ -0,0 +1,3 @@
+-- Reverts: Record the AI prompt each machine translation was made with (SQLite)
+
+ALTER TABLE recipe_translations DROP COLUMN prompt_id;
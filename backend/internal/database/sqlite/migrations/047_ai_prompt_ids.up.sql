@@ -0,0 +1,3 @@
+-- Record the AI prompt each machine translation was made with (SQLite)
+
+ALTER TABLE recipe_translations ADD COLUMN prompt_id TEXT NOT NULL DEFAULT '';
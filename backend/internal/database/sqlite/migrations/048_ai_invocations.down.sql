@@ -0,0 +1,3 @@
+-- Reverts: Every call to an AI provider, with its tokens, latency and estimated cost (SQLite)
+
+DROP TABLE IF EXISTS ai_invocations;
@@ -0,0 +1,19 @@
+-- Every call to an AI provider, with its tokens, latency and estimated cost (SQLite)
+
+CREATE TABLE ai_invocations (
+    id TEXT PRIMARY KEY,
+    user_id TEXT REFERENCES users(id) ON DELETE CASCADE,
+    feature TEXT NOT NULL DEFAULT '',
+    provider TEXT NOT NULL,
+    model TEXT NOT NULL DEFAULT '',
+    input_tokens INTEGER NOT NULL DEFAULT 0,
+    output_tokens INTEGER NOT NULL DEFAULT 0,
+    latency_ms INTEGER NOT NULL DEFAULT 0,
+    cost REAL NOT NULL DEFAULT 0,
+    outcome TEXT NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_ai_invocations_user_id ON ai_invocations(user_id, created_at);
+CREATE INDEX idx_ai_invocations_created_at ON ai_invocations(created_at);
@@ -0,0 +1,4 @@
+-- Reverts: Count second-factor attempts so codes cannot be guessed without limit (SQLite)
+
+ALTER TABLE user_totp DROP COLUMN locked_until;
+ALTER TABLE user_totp DROP COLUMN failed_attempts;
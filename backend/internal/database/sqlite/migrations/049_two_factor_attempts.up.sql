@@ -0,0 +1,4 @@
+-- Count second-factor attempts so codes cannot be guessed without limit (SQLite)
+
+ALTER TABLE user_totp ADD COLUMN failed_attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE user_totp ADD COLUMN locked_until DATETIME;
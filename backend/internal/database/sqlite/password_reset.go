@@ -0,0 +1,84 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Password reset token operations
+
+// CreatePasswordResetToken stores a new password reset token
+func (db *SQLiteDB) CreatePasswordResetToken(ctx context.Context, token *database.PasswordResetToken) error {
+	query := `
+		INSERT INTO password_reset_tokens (id, user_id, token_hash, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	_, err := db.conn(ctx).ExecContext(ctx, query,
+		token.ID, token.UserID, token.TokenHash, token.CreatedAt, token.ExpiresAt,
+	)
+	return err
+}
+
+// GetPasswordResetTokenByHash retrieves a password reset token by the hash
+// of its secret
+func (db *SQLiteDB) GetPasswordResetTokenByHash(ctx context.Context, tokenHash string) (*database.PasswordResetToken, error) {
+	query := `
+		SELECT id, user_id, token_hash, created_at, expires_at, used_at
+		FROM password_reset_tokens WHERE token_hash = ?
+	`
+	var token database.PasswordResetToken
+	err := db.conn(ctx).QueryRowContext(ctx, query, tokenHash).Scan(
+		&token.ID, &token.UserID, &token.TokenHash, &token.CreatedAt, &token.ExpiresAt, &token.UsedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// UsePasswordResetToken marks an unused token as used, reporting whether
+// it was still unused
+func (db *SQLiteDB) UsePasswordResetToken(ctx context.Context, id string, usedAt time.Time) (bool, error) {
+	query := `UPDATE password_reset_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL`
+	result, err := db.conn(ctx).ExecContext(ctx, query, usedAt, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n == 1, err
+}
+
+// DeletePasswordResetTokens removes every password reset token of a user
+func (db *SQLiteDB) DeletePasswordResetTokens(ctx context.Context, userID string) error {
+	_, err := db.conn(ctx).ExecContext(ctx, `DELETE FROM password_reset_tokens WHERE user_id = ?`, userID)
+	return err
+}
+
+// PurgePasswordResetTokens removes tokens that expired before a time
+func (db *SQLiteDB) PurgePasswordResetTokens(ctx context.Context, before time.Time) (int64, error) {
+	result, err := db.conn(ctx).ExecContext(ctx, `DELETE FROM password_reset_tokens WHERE expires_at < ?`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,69 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Meal time preference operations
+
+// GetMealTimePreferences retrieves a user's meal time preferences
+func (db *SQLiteDB) GetMealTimePreferences(ctx context.Context, userID string) (*database.MealTimePreferences, error) {
+	query := `
+		SELECT user_id, timezone, windows, breakfast_all_day, updated_at
+		FROM meal_time_preferences WHERE user_id = ?
+	`
+	var prefs database.MealTimePreferences
+	var windows string
+	err := db.conn(ctx).QueryRowContext(ctx, query, userID).Scan(
+		&prefs.UserID, &prefs.Timezone, &windows, &prefs.BreakfastAllDay, &prefs.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(windows), &prefs.Windows); err != nil {
+		return nil, fmt.Errorf("failed to decode meal windows: %w", err)
+	}
+	return &prefs, nil
+}
+
+// UpsertMealTimePreferences creates or replaces a user's meal time preferences
+func (db *SQLiteDB) UpsertMealTimePreferences(ctx context.Context, prefs *database.MealTimePreferences) error {
+	windows, err := json.Marshal(prefs.Windows)
+	if err != nil {
+		return fmt.Errorf("failed to encode meal windows: %w", err)
+	}
+
+	query := `
+		INSERT INTO meal_time_preferences (user_id, timezone, windows, breakfast_all_day, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE
+		SET timezone = excluded.timezone, windows = excluded.windows,
+		    breakfast_all_day = excluded.breakfast_all_day, updated_at = excluded.updated_at
+	`
+	_, err = db.conn(ctx).ExecContext(ctx, query,
+		prefs.UserID, prefs.Timezone, string(windows), prefs.BreakfastAllDay, prefs.UpdatedAt,
+	)
+	return err
+}
@@ -0,0 +1,177 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Recipe dietary tag operations
+
+const recipeDietaryTagColumns = `recipe_id, tag, kind, detected, confidence, ingredients, override, updated_at`
+
+// GetRecipeDietaryAnalysis retrieves when a recipe was last analyzed
+func (db *SQLiteDB) GetRecipeDietaryAnalysis(ctx context.Context, recipeID string) (*database.RecipeDietaryAnalysis, error) {
+	query := `SELECT recipe_id, version, source_updated_at, analyzed_at FROM recipe_dietary_analyses WHERE recipe_id = ?`
+	var analysis database.RecipeDietaryAnalysis
+	err := db.conn(ctx).QueryRowContext(ctx, query, recipeID).Scan(
+		&analysis.RecipeID, &analysis.Version, &analysis.SourceUpdatedAt, &analysis.AnalyzedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &analysis, nil
+}
+
+// ListRecipeDietaryAnalysesIn returns when the given recipes were last
+// analyzed, by recipe ID, leaving out those never analyzed
+func (db *SQLiteDB) ListRecipeDietaryAnalysesIn(ctx context.Context, recipeIDs []string) (map[string]*database.RecipeDietaryAnalysis, error) {
+	analyses := map[string]*database.RecipeDietaryAnalysis{}
+	if len(recipeIDs) == 0 {
+		return analyses, nil
+	}
+
+	query := `SELECT recipe_id, version, source_updated_at, analyzed_at FROM recipe_dietary_analyses
+		WHERE recipe_id IN (` + placeholderList(len(recipeIDs)) + `)`
+	rows, err := db.conn(ctx).QueryContext(ctx, query, stringArgs(recipeIDs)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var analysis database.RecipeDietaryAnalysis
+		if err := rows.Scan(&analysis.RecipeID, &analysis.Version, &analysis.SourceUpdatedAt, &analysis.AnalyzedAt); err != nil {
+			return nil, err
+		}
+		analyses[analysis.RecipeID] = &analysis
+	}
+	return analyses, rows.Err()
+}
+
+// UpsertRecipeDietaryAnalysis records that a recipe was analyzed
+func (db *SQLiteDB) UpsertRecipeDietaryAnalysis(ctx context.Context, analysis *database.RecipeDietaryAnalysis) error {
+	query := `
+		INSERT INTO recipe_dietary_analyses (recipe_id, version, source_updated_at, analyzed_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (recipe_id) DO UPDATE
+		SET version = excluded.version, source_updated_at = excluded.source_updated_at, analyzed_at = excluded.analyzed_at
+	`
+	_, err := db.conn(ctx).ExecContext(ctx, query,
+		analysis.RecipeID, analysis.Version, analysis.SourceUpdatedAt.UTC(), analysis.AnalyzedAt.UTC(),
+	)
+	return err
+}
+
+// ListRecipeDietaryTags lists a recipe's dietary tags, allergens first
+func (db *SQLiteDB) ListRecipeDietaryTags(ctx context.Context, recipeID string) ([]*database.RecipeDietaryTag, error) {
+	query := `SELECT ` + recipeDietaryTagColumns + ` FROM recipe_dietary_tags WHERE recipe_id = ? ORDER BY kind, tag`
+	rows, err := db.conn(ctx).QueryContext(ctx, query, recipeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []*database.RecipeDietaryTag{}
+	for rows.Next() {
+		tag, err := scanRecipeDietaryTag(rows)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// ListRecipeDietaryTagsIn returns the dietary tags of the given recipes,
+// by recipe ID
+func (db *SQLiteDB) ListRecipeDietaryTagsIn(ctx context.Context, recipeIDs []string) (map[string][]*database.RecipeDietaryTag, error) {
+	tags := map[string][]*database.RecipeDietaryTag{}
+	if len(recipeIDs) == 0 {
+		return tags, nil
+	}
+
+	query := `SELECT ` + recipeDietaryTagColumns + ` FROM recipe_dietary_tags
+		WHERE recipe_id IN (` + placeholderList(len(recipeIDs)) + `) ORDER BY kind, tag`
+	rows, err := db.conn(ctx).QueryContext(ctx, query, stringArgs(recipeIDs)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		tag, err := scanRecipeDietaryTag(rows)
+		if err != nil {
+			return nil, err
+		}
+		tags[tag.RecipeID] = append(tags[tag.RecipeID], tag)
+	}
+	return tags, rows.Err()
+}
+
+// UpsertRecipeDietaryTag creates or replaces one of a recipe's dietary tags
+func (db *SQLiteDB) UpsertRecipeDietaryTag(ctx context.Context, tag *database.RecipeDietaryTag) error {
+	ingredients, err := json.Marshal(tag.Ingredients)
+	if err != nil {
+		return fmt.Errorf("failed to encode dietary tag ingredients: %w", err)
+	}
+
+	query := `
+		INSERT INTO recipe_dietary_tags (recipe_id, tag, kind, detected, confidence, ingredients, override, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (recipe_id, tag) DO UPDATE
+		SET kind = excluded.kind, detected = excluded.detected, confidence = excluded.confidence,
+		    ingredients = excluded.ingredients, override = excluded.override, updated_at = excluded.updated_at
+	`
+	_, err = db.conn(ctx).ExecContext(ctx, query,
+		tag.RecipeID, tag.Tag, tag.Kind, tag.Detected, tag.Confidence, string(ingredients), tag.Override, tag.UpdatedAt.UTC(),
+	)
+	return err
+}
+
+// DeleteRecipeDietaryTag deletes one of a recipe's dietary tags
+func (db *SQLiteDB) DeleteRecipeDietaryTag(ctx context.Context, recipeID, tag string) error {
+	query := `DELETE FROM recipe_dietary_tags WHERE recipe_id = ? AND tag = ?`
+	_, err := db.conn(ctx).ExecContext(ctx, query, recipeID, tag)
+	return err
+}
+
+func scanRecipeDietaryTag(row interface{ Scan(dest ...any) error }) (*database.RecipeDietaryTag, error) {
+	var tag database.RecipeDietaryTag
+	var ingredients string
+	var override sql.NullBool
+	err := row.Scan(
+		&tag.RecipeID, &tag.Tag, &tag.Kind, &tag.Detected, &tag.Confidence, &ingredients, &override, &tag.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if override.Valid {
+		tag.Override = &override.Bool
+	}
+	if err := json.Unmarshal([]byte(ingredients), &tag.Ingredients); err != nil {
+		return nil, fmt.Errorf("failed to decode dietary tag ingredients: %w", err)
+	}
+	return &tag, nil
+}
@@ -0,0 +1,73 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Recipe note operations
+
+// GetRecipeNote retrieves a user's notes on a recipe
+func (db *SQLiteDB) GetRecipeNote(ctx context.Context, userID, recipeID string) (*database.RecipeNote, error) {
+	query := `
+		SELECT user_id, recipe_id, COALESCE(notes, ''), modifications, updated_at
+		FROM recipe_notes WHERE user_id = ? AND recipe_id = ?
+	`
+	var note database.RecipeNote
+	var modifications string
+	err := db.conn(ctx).QueryRowContext(ctx, query, userID, recipeID).Scan(
+		&note.UserID, &note.RecipeID, &note.Notes, &modifications, &note.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(modifications), &note.Modifications); err != nil {
+		return nil, fmt.Errorf("failed to decode recipe note: %w", err)
+	}
+	return &note, nil
+}
+
+// UpsertRecipeNote creates or replaces a user's notes on a recipe
+func (db *SQLiteDB) UpsertRecipeNote(ctx context.Context, note *database.RecipeNote) error {
+	modifications, err := json.Marshal(note.Modifications)
+	if err != nil {
+		return fmt.Errorf("failed to encode recipe note: %w", err)
+	}
+
+	query := `
+		INSERT INTO recipe_notes (user_id, recipe_id, notes, modifications, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (user_id, recipe_id) DO UPDATE
+		SET notes = excluded.notes, modifications = excluded.modifications, updated_at = excluded.updated_at
+	`
+	_, err = db.conn(ctx).ExecContext(ctx, query, note.UserID, note.RecipeID, note.Notes, string(modifications), note.UpdatedAt.UTC())
+	return err
+}
+
+// DeleteRecipeNote deletes a user's notes on a recipe
+func (db *SQLiteDB) DeleteRecipeNote(ctx context.Context, userID, recipeID string) error {
+	query := `DELETE FROM recipe_notes WHERE user_id = ? AND recipe_id = ?`
+	_, err := db.conn(ctx).ExecContext(ctx, query, userID, recipeID)
+	return err
+}
@@ -0,0 +1,132 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Recipe rating operations
+
+// UpsertRecipeRating creates or replaces a user's rating of a recipe
+func (db *SQLiteDB) UpsertRecipeRating(ctx context.Context, rating *database.RecipeRating) error {
+	query := `
+		INSERT INTO recipe_ratings (user_id, recipe_id, stars, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (user_id, recipe_id) DO UPDATE
+		SET stars = excluded.stars, updated_at = excluded.updated_at
+	`
+	_, err := db.conn(ctx).ExecContext(ctx, query, rating.UserID, rating.RecipeID, rating.Stars, rating.UpdatedAt.UTC())
+	return err
+}
+
+// DeleteRecipeRating deletes a user's rating of a recipe
+func (db *SQLiteDB) DeleteRecipeRating(ctx context.Context, userID, recipeID string) error {
+	query := `DELETE FROM recipe_ratings WHERE user_id = ? AND recipe_id = ?`
+	_, err := db.conn(ctx).ExecContext(ctx, query, userID, recipeID)
+	return err
+}
+
+// ListRecipeRatings lists the ratings the given users gave the given
+// recipes
+func (db *SQLiteDB) ListRecipeRatings(ctx context.Context, recipeIDs, userIDs []string) ([]*database.RecipeRating, error) {
+	ratings := []*database.RecipeRating{}
+	if len(recipeIDs) == 0 || len(userIDs) == 0 {
+		return ratings, nil
+	}
+
+	query := `
+		SELECT user_id, recipe_id, stars, updated_at
+		FROM recipe_ratings
+		WHERE recipe_id IN (` + placeholderList(len(recipeIDs)) + `)
+		  AND user_id IN (` + placeholderList(len(userIDs)) + `)
+	`
+	rows, err := db.conn(ctx).QueryContext(ctx, query, append(stringArgs(recipeIDs), stringArgs(userIDs)...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rating database.RecipeRating
+		if err := rows.Scan(&rating.UserID, &rating.RecipeID, &rating.Stars, &rating.UpdatedAt); err != nil {
+			return nil, err
+		}
+		ratings = append(ratings, &rating)
+	}
+	return ratings, rows.Err()
+}
+
+// ListRecipesMade counts the cooking sessions of the given recipes the
+// given users completed. Recipes none of them made are left out.
+func (db *SQLiteDB) ListRecipesMade(ctx context.Context, recipeIDs, userIDs []string) (map[string]database.RecipeMade, error) {
+	made := map[string]database.RecipeMade{}
+	if len(recipeIDs) == 0 || len(userIDs) == 0 {
+		return made, nil
+	}
+
+	// SQLite returns MAX of a DATETIME column as text, so the latest is
+	// picked here instead
+	query := `
+		SELECT recipe_id, finished_at
+		FROM cooking_sessions
+		WHERE status = ? AND finished_at IS NOT NULL
+		  AND recipe_id IN (` + placeholderList(len(recipeIDs)) + `)
+		  AND user_id IN (` + placeholderList(len(userIDs)) + `)
+	`
+	args := append([]any{database.CookingSessionCompleted}, stringArgs(recipeIDs)...)
+	rows, err := db.conn(ctx).QueryContext(ctx, query, append(args, stringArgs(userIDs)...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var recipeID string
+		var finishedAt time.Time
+		if err := rows.Scan(&recipeID, &finishedAt); err != nil {
+			return nil, err
+		}
+		m := made[recipeID]
+		m.Count++
+		if finishedAt.After(m.LastAt) {
+			m.LastAt = finishedAt
+		}
+		made[recipeID] = m
+	}
+	return made, rows.Err()
+}
+
+// placeholderList is n comma-separated placeholders for an IN list
+func placeholderList(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// stringArgs converts IDs to query arguments
+func stringArgs(values []string) []any {
+	args := make([]any, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
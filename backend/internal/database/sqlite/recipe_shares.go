@@ -0,0 +1,92 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Recipe share operations
+
+const recipeShareColumns = `id, recipe_id, user_id, token_hash, prefix, created_at, last_used_at`
+
+func scanRecipeShare(row interface{ Scan(dest ...any) error }) (*database.RecipeShare, error) {
+	var share database.RecipeShare
+	err := row.Scan(
+		&share.ID, &share.RecipeID, &share.UserID, &share.TokenHash, &share.Prefix,
+		&share.CreatedAt, &share.LastUsedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+// CreateRecipeShare creates a new recipe share link
+func (db *SQLiteDB) CreateRecipeShare(ctx context.Context, share *database.RecipeShare) error {
+	query := `
+		INSERT INTO recipe_shares (id, recipe_id, user_id, token_hash, prefix, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	_, err := db.conn(ctx).ExecContext(ctx, query,
+		share.ID, share.RecipeID, share.UserID, share.TokenHash, share.Prefix, share.CreatedAt,
+	)
+	return err
+}
+
+// GetRecipeShareByHash retrieves a recipe share link by the hash of its token
+func (db *SQLiteDB) GetRecipeShareByHash(ctx context.Context, tokenHash string) (*database.RecipeShare, error) {
+	query := `SELECT ` + recipeShareColumns + ` FROM recipe_shares WHERE token_hash = ?`
+	return scanRecipeShare(db.conn(ctx).QueryRowContext(ctx, query, tokenHash))
+}
+
+// ListRecipeShares lists a recipe's share links
+func (db *SQLiteDB) ListRecipeShares(ctx context.Context, recipeID string) ([]*database.RecipeShare, error) {
+	query := `SELECT ` + recipeShareColumns + ` FROM recipe_shares WHERE recipe_id = ? ORDER BY created_at DESC`
+	rows, err := db.conn(ctx).QueryContext(ctx, query, recipeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shares := []*database.RecipeShare{}
+	for rows.Next() {
+		share, err := scanRecipeShare(rows)
+		if err != nil {
+			return nil, err
+		}
+		shares = append(shares, share)
+	}
+	return shares, rows.Err()
+}
+
+// TouchRecipeShare records when a recipe share link was last fetched
+func (db *SQLiteDB) TouchRecipeShare(ctx context.Context, id string, usedAt time.Time) error {
+	_, err := db.conn(ctx).ExecContext(ctx, `UPDATE recipe_shares SET last_used_at = ? WHERE id = ?`, usedAt, id)
+	return err
+}
+
+// DeleteRecipeShare deletes a recipe share link
+func (db *SQLiteDB) DeleteRecipeShare(ctx context.Context, id string) error {
+	_, err := db.conn(ctx).ExecContext(ctx, `DELETE FROM recipe_shares WHERE id = ?`, id)
+	return err
+}
@@ -0,0 +1,95 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Recipe history operations
+
+// CreateRecipeRevision stores a revision numbered after the recipe's latest
+func (db *SQLiteDB) CreateRecipeRevision(ctx context.Context, revision *database.RecipeRevision) error {
+	query := `
+		INSERT INTO recipe_revisions (id, recipe_id, revision, user_id, restored_from, content, created_at)
+		SELECT ?1, ?2, COALESCE(MAX(revision), 0) + 1, ?3, ?4, ?5, ?6
+		FROM recipe_revisions WHERE recipe_id = ?2
+		RETURNING revision
+	`
+	return db.conn(ctx).QueryRowContext(ctx, query,
+		revision.ID, revision.RecipeID, revision.UserID, revision.RestoredFrom,
+		string(revision.Content), revision.CreatedAt.UTC(),
+	).Scan(&revision.Revision)
+}
+
+// ListRecipeRevisions lists a recipe's revisions, newest first
+func (db *SQLiteDB) ListRecipeRevisions(ctx context.Context, recipeID string, limit int) ([]*database.RecipeRevision, error) {
+	query := `
+		SELECT id, recipe_id, revision, user_id, restored_from, content, created_at
+		FROM recipe_revisions
+		WHERE recipe_id = ?
+		ORDER BY revision DESC
+	`
+	args := []any{recipeID}
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+	rows, err := db.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []*database.RecipeRevision{}
+	for rows.Next() {
+		var revision database.RecipeRevision
+		var content string
+		if err := rows.Scan(
+			&revision.ID, &revision.RecipeID, &revision.Revision, &revision.UserID,
+			&revision.RestoredFrom, &content, &revision.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		revision.Content = []byte(content)
+		revisions = append(revisions, &revision)
+	}
+	return revisions, rows.Err()
+}
+
+// GetRecipeRevision retrieves one revision of a recipe
+func (db *SQLiteDB) GetRecipeRevision(ctx context.Context, recipeID string, revision int) (*database.RecipeRevision, error) {
+	query := `
+		SELECT id, recipe_id, revision, user_id, restored_from, content, created_at
+		FROM recipe_revisions
+		WHERE recipe_id = ? AND revision = ?
+	`
+	var r database.RecipeRevision
+	var content string
+	err := db.conn(ctx).QueryRowContext(ctx, query, recipeID, revision).Scan(
+		&r.ID, &r.RecipeID, &r.Revision, &r.UserID, &r.RestoredFrom, &content, &r.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	r.Content = []byte(content)
+	return &r, nil
+}
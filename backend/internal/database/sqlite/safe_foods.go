@@ -0,0 +1,105 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Safe food operations
+
+// CreateSafeFood records a safe food for a user
+func (db *SQLiteDB) CreateSafeFood(ctx context.Context, food *database.SafeFood) error {
+	query := `
+		INSERT INTO safe_foods (id, user_id, name, recipe_id, protected, notes, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := db.conn(ctx).ExecContext(ctx, query,
+		food.ID, food.UserID, food.Name, food.RecipeID, food.Protected,
+		food.Notes, food.CreatedAt, food.UpdatedAt,
+	)
+	return err
+}
+
+// GetSafeFoodByID retrieves a safe food by ID
+func (db *SQLiteDB) GetSafeFoodByID(ctx context.Context, id string) (*database.SafeFood, error) {
+	query := `
+		SELECT id, user_id, name, recipe_id, protected, COALESCE(notes, ''), created_at, updated_at
+		FROM safe_foods WHERE id = ?
+	`
+	var food database.SafeFood
+	err := db.conn(ctx).QueryRowContext(ctx, query, id).Scan(
+		&food.ID, &food.UserID, &food.Name, &food.RecipeID, &food.Protected,
+		&food.Notes, &food.CreatedAt, &food.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &food, nil
+}
+
+// ListSafeFoods lists a user's safe foods by name
+func (db *SQLiteDB) ListSafeFoods(ctx context.Context, userID string) ([]*database.SafeFood, error) {
+	query := `
+		SELECT id, user_id, name, recipe_id, protected, COALESCE(notes, ''), created_at, updated_at
+		FROM safe_foods
+		WHERE user_id = ?
+		ORDER BY name
+	`
+	rows, err := db.conn(ctx).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	foods := []*database.SafeFood{}
+	for rows.Next() {
+		var food database.SafeFood
+		if err := rows.Scan(
+			&food.ID, &food.UserID, &food.Name, &food.RecipeID, &food.Protected,
+			&food.Notes, &food.CreatedAt, &food.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		foods = append(foods, &food)
+	}
+	return foods, rows.Err()
+}
+
+// UpdateSafeFood updates a safe food
+func (db *SQLiteDB) UpdateSafeFood(ctx context.Context, food *database.SafeFood) error {
+	query := `
+		UPDATE safe_foods
+		SET name = ?, recipe_id = ?, protected = ?, notes = ?, updated_at = ?
+		WHERE id = ?
+	`
+	_, err := db.conn(ctx).ExecContext(ctx, query,
+		food.Name, food.RecipeID, food.Protected, food.Notes, food.UpdatedAt, food.ID,
+	)
+	return err
+}
+
+// DeleteSafeFood deletes a safe food
+func (db *SQLiteDB) DeleteSafeFood(ctx context.Context, id string) error {
+	query := `DELETE FROM safe_foods WHERE id = ?`
+	_, err := db.conn(ctx).ExecContext(ctx, query, id)
+	return err
+}
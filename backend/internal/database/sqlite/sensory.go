@@ -0,0 +1,104 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Sensory profile operations
+
+// GetSensoryProfile retrieves a user's sensory profile
+func (db *SQLiteDB) GetSensoryProfile(ctx context.Context, userID string) (*database.SensoryProfile, error) {
+	query := `
+		SELECT user_id, avoid_textures, preferred_temperatures, smell_aversions, max_spice_level,
+		       avoid_cues, eating_style, COALESCE(notes, ''), updated_at
+		FROM sensory_profiles WHERE user_id = ?
+	`
+	var profile database.SensoryProfile
+	var textures, temperatures, smells, cues string
+	err := db.conn(ctx).QueryRowContext(ctx, query, userID).Scan(
+		&profile.UserID, &textures, &temperatures, &smells, &profile.MaxSpiceLevel,
+		&cues, &profile.EatingStyle, &profile.Notes, &profile.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range []struct {
+		data string
+		dest *[]string
+	}{
+		{textures, &profile.AvoidTextures},
+		{temperatures, &profile.PreferredTemperatures},
+		{smells, &profile.SmellAversions},
+		{cues, &profile.AvoidCues},
+	} {
+		if err := json.Unmarshal([]byte(field.data), field.dest); err != nil {
+			return nil, fmt.Errorf("failed to decode sensory profile: %w", err)
+		}
+	}
+	return &profile, nil
+}
+
+// UpsertSensoryProfile creates or replaces a user's sensory profile
+func (db *SQLiteDB) UpsertSensoryProfile(ctx context.Context, profile *database.SensoryProfile) error {
+	textures, err := json.Marshal(profile.AvoidTextures)
+	if err != nil {
+		return fmt.Errorf("failed to encode sensory profile: %w", err)
+	}
+	temperatures, err := json.Marshal(profile.PreferredTemperatures)
+	if err != nil {
+		return fmt.Errorf("failed to encode sensory profile: %w", err)
+	}
+	smells, err := json.Marshal(profile.SmellAversions)
+	if err != nil {
+		return fmt.Errorf("failed to encode sensory profile: %w", err)
+	}
+	cues, err := json.Marshal(profile.AvoidCues)
+	if err != nil {
+		return fmt.Errorf("failed to encode sensory profile: %w", err)
+	}
+
+	query := `
+		INSERT INTO sensory_profiles (user_id, avoid_textures, preferred_temperatures, smell_aversions,
+		                              max_spice_level, avoid_cues, eating_style, notes, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE
+		SET avoid_textures = excluded.avoid_textures, preferred_temperatures = excluded.preferred_temperatures,
+		    smell_aversions = excluded.smell_aversions, max_spice_level = excluded.max_spice_level,
+		    avoid_cues = excluded.avoid_cues, eating_style = excluded.eating_style,
+		    notes = excluded.notes, updated_at = excluded.updated_at
+	`
+	_, err = db.conn(ctx).ExecContext(ctx, query,
+		profile.UserID, string(textures), string(temperatures), string(smells), profile.MaxSpiceLevel,
+		string(cues), profile.EatingStyle, profile.Notes, profile.UpdatedAt,
+	)
+	return err
+}
+
+// DeleteSensoryProfile deletes a user's sensory profile
+func (db *SQLiteDB) DeleteSensoryProfile(ctx context.Context, userID string) error {
+	query := `DELETE FROM sensory_profiles WHERE user_id = ?`
+	_, err := db.conn(ctx).ExecContext(ctx, query, userID)
+	return err
+}
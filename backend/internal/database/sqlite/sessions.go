@@ -0,0 +1,131 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Auth session operations
+
+const authSessionColumns = `
+	id, user_id, refresh_token_hash, COALESCE(user_agent, ''), COALESCE(ip_address, ''),
+	created_at, last_used_at, expires_at, revoked_at
+`
+
+func scanAuthSession(row interface{ Scan(dest ...any) error }) (*database.AuthSession, error) {
+	var session database.AuthSession
+	err := row.Scan(
+		&session.ID, &session.UserID, &session.RefreshTokenHash, &session.UserAgent, &session.IPAddress,
+		&session.CreatedAt, &session.LastUsedAt, &session.ExpiresAt, &session.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// CreateAuthSession creates a new auth session
+func (db *SQLiteDB) CreateAuthSession(ctx context.Context, session *database.AuthSession) error {
+	query := `
+		INSERT INTO auth_sessions (id, user_id, refresh_token_hash, user_agent, ip_address, created_at, last_used_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := db.conn(ctx).ExecContext(ctx, query,
+		session.ID, session.UserID, session.RefreshTokenHash, session.UserAgent, session.IPAddress,
+		session.CreatedAt, session.LastUsedAt, session.ExpiresAt,
+	)
+	return err
+}
+
+// GetAuthSessionByID retrieves an auth session by ID
+func (db *SQLiteDB) GetAuthSessionByID(ctx context.Context, id string) (*database.AuthSession, error) {
+	query := `SELECT ` + authSessionColumns + ` FROM auth_sessions WHERE id = ?`
+	return scanAuthSession(db.conn(ctx).QueryRowContext(ctx, query, id))
+}
+
+// ListActiveAuthSessions lists a user's sessions that are neither revoked nor expired
+func (db *SQLiteDB) ListActiveAuthSessions(ctx context.Context, userID string) ([]*database.AuthSession, error) {
+	query := `SELECT ` + authSessionColumns + `
+		FROM auth_sessions
+		WHERE user_id = ? AND revoked_at IS NULL AND expires_at > ?
+		ORDER BY last_used_at DESC
+	`
+	rows, err := db.conn(ctx).QueryContext(ctx, query, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []*database.AuthSession{}
+	for rows.Next() {
+		session, err := scanAuthSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// UpdateAuthSession updates the rotating fields of an auth session
+func (db *SQLiteDB) UpdateAuthSession(ctx context.Context, session *database.AuthSession) error {
+	query := `
+		UPDATE auth_sessions
+		SET refresh_token_hash = ?, last_used_at = ?, expires_at = ?, revoked_at = ?
+		WHERE id = ?
+	`
+	_, err := db.conn(ctx).ExecContext(ctx, query,
+		session.RefreshTokenHash, session.LastUsedAt, session.ExpiresAt, session.RevokedAt, session.ID,
+	)
+	return err
+}
+
+// RotateAuthSession replaces the refresh token hash of an active session
+// still holding oldHash, reporting whether it did. Concurrent callers
+// presenting the same refresh token cannot both succeed.
+func (db *SQLiteDB) RotateAuthSession(ctx context.Context, session *database.AuthSession, oldHash string) (bool, error) {
+	query := `
+		UPDATE auth_sessions
+		SET refresh_token_hash = ?, last_used_at = ?, expires_at = ?
+		WHERE id = ? AND refresh_token_hash = ? AND revoked_at IS NULL
+	`
+	result, err := db.conn(ctx).ExecContext(ctx, query,
+		session.RefreshTokenHash, session.LastUsedAt, session.ExpiresAt, session.ID, oldHash,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n == 1, err
+}
+
+// RevokeAuthSessions revokes all of a user's active sessions, optionally keeping one
+func (db *SQLiteDB) RevokeAuthSessions(ctx context.Context, userID string, exceptSessionID string) error {
+	query := `
+		UPDATE auth_sessions
+		SET revoked_at = ?
+		WHERE user_id = ? AND revoked_at IS NULL AND id <> ?
+	`
+	_, err := db.conn(ctx).ExecContext(ctx, query, time.Now(), userID, exceptSessionID)
+	return err
+}
@@ -22,6 +22,8 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"os"
+	"path/filepath"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -29,8 +31,9 @@ import (
 )
 
 type SQLiteDB struct {
-	db   *sql.DB
-	path string
+	db     *sql.DB
+	path   string
+	memory bool
 }
 
 // NewSQLiteDB creates a new SQLite database instance
@@ -40,9 +43,30 @@ func NewSQLiteDB(path string) (*SQLiteDB, error) {
 	}, nil
 }
 
-// Connect establishes connection to the database
+// NewMemoryDB creates a SQLite database held in memory, which starts empty
+// and is lost when the server stops. It suits demos and trying the app out.
+func NewMemoryDB() (*SQLiteDB, error) {
+	return &SQLiteDB{
+		memory: true,
+	}, nil
+}
+
+// Connect establishes connection to the database, creating the file and
+// its directory on first start
 func (db *SQLiteDB) Connect(ctx context.Context) error {
-	sqlDB, err := sql.Open("sqlite3", db.path)
+	// Enforce foreign keys like PostgreSQL does, use the write-ahead log so
+	// reads are not blocked by a write, and wait on locks instead of failing
+	dsn := "file:" + db.path + "?_foreign_keys=on&_journal_mode=WAL&_busy_timeout=5000"
+	if db.memory {
+		// The database lives as long as its connection, which the pool of
+		// one below keeps open
+		dsn = "file::memory:?_foreign_keys=on"
+	} else if dir := filepath.Dir(db.path); dir != "." {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return fmt.Errorf("failed to create database directory: %w", err)
+		}
+	}
+	sqlDB, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
@@ -51,7 +75,7 @@ func (db *SQLiteDB) Connect(ctx context.Context) error {
 	sqlDB.SetMaxOpenConns(1) // SQLite performs better with single connection
 	db.db = sqlDB
 
-	return nil
+	return sqlDB.PingContext(ctx)
 }
 
 // Close closes the database connection
@@ -70,16 +94,20 @@ func (db *SQLiteDB) Health(ctx context.Context) error {
 	return db.db.PingContext(ctx)
 }
 
-// Migrate runs database migrations
-func (db *SQLiteDB) Migrate(ctx context.Context) error {
-	// Migration logic will be implemented
-	return nil
-}
-
-// BeginTx starts a new transaction
-func (db *SQLiteDB) BeginTx(ctx context.Context) (database.Transaction, error) {
-	// Transaction logic will be implemented
-	return nil, fmt.Errorf("not implemented")
+// PoolStats reports the connection pool's state
+func (db *SQLiteDB) PoolStats() database.PoolStats {
+	if db.db == nil {
+		return database.PoolStats{}
+	}
+	stats := db.db.Stats()
+	return database.PoolStats{
+		MaxConns:     stats.MaxOpenConnections,
+		OpenConns:    stats.OpenConnections,
+		InUseConns:   stats.InUse,
+		IdleConns:    stats.Idle,
+		WaitCount:    stats.WaitCount,
+		WaitDuration: stats.WaitDuration,
+	}
 }
 
 // User operations
@@ -87,12 +115,12 @@ func (db *SQLiteDB) BeginTx(ctx context.Context) (database.Transaction, error) {
 // CreateUser creates a new user
 func (db *SQLiteDB) CreateUser(ctx context.Context, user *database.User) error {
 	query := `
-		INSERT INTO users (id, email, password_hash, first_name, last_name, created_at, updated_at, email_verified, active)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO users (id, email, password_hash, first_name, last_name, created_at, updated_at, email_verified, active, is_admin, must_change_password)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
-	_, err := db.db.ExecContext(ctx, query,
+	_, err := db.conn(ctx).ExecContext(ctx, query,
 		user.ID, user.Email, user.PasswordHash, user.FirstName, user.LastName,
-		user.CreatedAt, user.UpdatedAt, user.EmailVerified, user.Active,
+		user.CreatedAt, user.UpdatedAt, user.EmailVerified, user.Active, user.IsAdmin, user.MustChangePassword,
 	)
 	return err
 }
@@ -100,13 +128,13 @@ func (db *SQLiteDB) CreateUser(ctx context.Context, user *database.User) error {
 // GetUserByID retrieves a user by ID
 func (db *SQLiteDB) GetUserByID(ctx context.Context, id string) (*database.User, error) {
 	query := `
-		SELECT id, email, password_hash, first_name, last_name, created_at, updated_at, last_login_at, email_verified, active
+		SELECT id, email, password_hash, first_name, last_name, created_at, updated_at, last_login_at, email_verified, active, is_admin, must_change_password
 		FROM users WHERE id = ?
 	`
 	var user database.User
-	err := db.db.QueryRowContext(ctx, query, id).Scan(
+	err := db.conn(ctx).QueryRowContext(ctx, query, id).Scan(
 		&user.ID, &user.Email, &user.PasswordHash, &user.FirstName, &user.LastName,
-		&user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt, &user.EmailVerified, &user.Active,
+		&user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt, &user.EmailVerified, &user.Active, &user.IsAdmin, &user.MustChangePassword,
 	)
 	if err != nil {
 		return nil, err
@@ -117,13 +145,13 @@ func (db *SQLiteDB) GetUserByID(ctx context.Context, id string) (*database.User,
 // GetUserByEmail retrieves a user by email
 func (db *SQLiteDB) GetUserByEmail(ctx context.Context, email string) (*database.User, error) {
 	query := `
-		SELECT id, email, password_hash, first_name, last_name, created_at, updated_at, last_login_at, email_verified, active
+		SELECT id, email, password_hash, first_name, last_name, created_at, updated_at, last_login_at, email_verified, active, is_admin, must_change_password
 		FROM users WHERE email = ?
 	`
 	var user database.User
-	err := db.db.QueryRowContext(ctx, query, email).Scan(
+	err := db.conn(ctx).QueryRowContext(ctx, query, email).Scan(
 		&user.ID, &user.Email, &user.PasswordHash, &user.FirstName, &user.LastName,
-		&user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt, &user.EmailVerified, &user.Active,
+		&user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt, &user.EmailVerified, &user.Active, &user.IsAdmin, &user.MustChangePassword,
 	)
 	if err != nil {
 		return nil, err
@@ -136,12 +164,13 @@ func (db *SQLiteDB) UpdateUser(ctx context.Context, user *database.User) error {
 	query := `
 		UPDATE users
 		SET email = ?, password_hash = ?, first_name = ?, last_name = ?,
-		    updated_at = ?, last_login_at = ?, email_verified = ?, active = ?
+		    updated_at = ?, last_login_at = ?, email_verified = ?, active = ?, is_admin = ?,
+		    must_change_password = ?
 		WHERE id = ?
 	`
-	_, err := db.db.ExecContext(ctx, query,
+	_, err := db.conn(ctx).ExecContext(ctx, query,
 		user.Email, user.PasswordHash, user.FirstName, user.LastName,
-		user.UpdatedAt, user.LastLoginAt, user.EmailVerified, user.Active, user.ID,
+		user.UpdatedAt, user.LastLoginAt, user.EmailVerified, user.Active, user.IsAdmin, user.MustChangePassword, user.ID,
 	)
 	return err
 }
@@ -149,7 +178,7 @@ func (db *SQLiteDB) UpdateUser(ctx context.Context, user *database.User) error {
 // DeleteUser deletes a user
 func (db *SQLiteDB) DeleteUser(ctx context.Context, id string) error {
 	query := `DELETE FROM users WHERE id = ?`
-	_, err := db.db.ExecContext(ctx, query, id)
+	_, err := db.conn(ctx).ExecContext(ctx, query, id)
 	return err
 }
 
@@ -171,6 +200,10 @@ func (db *SQLiteDB) UpdateRecipe(ctx context.Context, recipe *database.Recipe) e
 	return fmt.Errorf("not implemented")
 }
 
+func (db *SQLiteDB) UpdateRecipeIfUnchanged(ctx context.Context, recipe *database.Recipe, savedAt time.Time) (bool, error) {
+	return false, fmt.Errorf("not implemented")
+}
+
 func (db *SQLiteDB) DeleteRecipe(ctx context.Context, id string) error {
 	return fmt.Errorf("not implemented")
 }
@@ -197,6 +230,10 @@ func (db *SQLiteDB) UpdateMealPlan(ctx context.Context, plan *database.MealPlan)
 	return fmt.Errorf("not implemented")
 }
 
+func (db *SQLiteDB) UpdateMealPlanIfUnchanged(ctx context.Context, plan *database.MealPlan, savedAt time.Time) (bool, error) {
+	return false, fmt.Errorf("not implemented")
+}
+
 func (db *SQLiteDB) DeleteMealPlan(ctx context.Context, id string) error {
 	return fmt.Errorf("not implemented")
 }
@@ -0,0 +1,104 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Offline sync operations. The changes themselves are written by triggers.
+
+// ListSyncChanges lists a user's changes after a seq, oldest first
+func (db *SQLiteDB) ListSyncChanges(ctx context.Context, userID string, after int64, limit int) ([]*database.SyncChange, error) {
+	query := `
+		SELECT user_id, seq, entity, entity_id, deleted, changed_at
+		FROM sync_changes WHERE user_id = ? AND seq > ?
+		ORDER BY seq LIMIT ?
+	`
+	rows, err := db.conn(ctx).QueryContext(ctx, query, userID, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []*database.SyncChange
+	for rows.Next() {
+		var change database.SyncChange
+		if err := rows.Scan(&change.UserID, &change.Seq, &change.Entity, &change.EntityID, &change.Deleted, &change.ChangedAt); err != nil {
+			return nil, err
+		}
+		changes = append(changes, &change)
+	}
+	return changes, rows.Err()
+}
+
+// GetSyncChange retrieves the latest change to one of a user's records
+func (db *SQLiteDB) GetSyncChange(ctx context.Context, userID, entity, entityID string) (*database.SyncChange, error) {
+	query := `
+		SELECT user_id, seq, entity, entity_id, deleted, changed_at
+		FROM sync_changes WHERE user_id = ? AND entity = ? AND entity_id = ?
+	`
+	var change database.SyncChange
+	err := db.conn(ctx).QueryRowContext(ctx, query, userID, entity, entityID).Scan(
+		&change.UserID, &change.Seq, &change.Entity, &change.EntityID, &change.Deleted, &change.ChangedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// GetSyncCursor retrieves a user's change counter
+func (db *SQLiteDB) GetSyncCursor(ctx context.Context, userID string) (*database.SyncCursor, error) {
+	query := `SELECT user_id, seq, purged_seq FROM sync_cursors WHERE user_id = ?`
+	var cursor database.SyncCursor
+	err := db.conn(ctx).QueryRowContext(ctx, query, userID).Scan(&cursor.UserID, &cursor.Seq, &cursor.PurgedSeq)
+	if err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+// PurgeSyncTombstones removes tombstones of records deleted before a time,
+// remembering the latest purged seq of each user
+func (db *SQLiteDB) PurgeSyncTombstones(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	err := db.WithTx(ctx, func(ctx context.Context) error {
+		_, err := db.conn(ctx).ExecContext(ctx, `
+			UPDATE sync_cursors SET purged_seq = (
+				SELECT MAX(seq) FROM sync_changes
+				WHERE sync_changes.user_id = sync_cursors.user_id AND deleted = 1 AND changed_at < ?
+			)
+			WHERE user_id IN (SELECT user_id FROM sync_changes WHERE deleted = 1 AND changed_at < ?)
+		`, before, before)
+		if err != nil {
+			return err
+		}
+		result, err := db.conn(ctx).ExecContext(ctx, `DELETE FROM sync_changes WHERE deleted = 1 AND changed_at < ?`, before)
+		if err != nil {
+			return err
+		}
+		purged, err = result.RowsAffected()
+		return err
+	})
+	return purged, err
+}
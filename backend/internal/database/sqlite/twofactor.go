@@ -0,0 +1,159 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Two-factor authentication operations
+
+// GetUserTOTP retrieves a user's TOTP enrollment
+func (db *SQLiteDB) GetUserTOTP(ctx context.Context, userID string) (*database.UserTOTP, error) {
+	query := `
+		SELECT user_id, secret, enabled, last_used_step, created_at, confirmed_at
+		FROM user_totp
+		WHERE user_id = ?
+	`
+	var totp database.UserTOTP
+	err := db.conn(ctx).QueryRowContext(ctx, query, userID).Scan(
+		&totp.UserID, &totp.Secret, &totp.Enabled, &totp.LastUsedStep, &totp.CreatedAt, &totp.ConfirmedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &totp, nil
+}
+
+// UpsertUserTOTP creates or replaces a user's TOTP enrollment
+func (db *SQLiteDB) UpsertUserTOTP(ctx context.Context, totp *database.UserTOTP) error {
+	query := `
+		INSERT INTO user_totp (user_id, secret, enabled, last_used_step, created_at, confirmed_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET
+			secret = excluded.secret,
+			enabled = excluded.enabled,
+			last_used_step = excluded.last_used_step,
+			created_at = excluded.created_at,
+			confirmed_at = excluded.confirmed_at
+	`
+	_, err := db.conn(ctx).ExecContext(ctx, query,
+		totp.UserID, totp.Secret, totp.Enabled, totp.LastUsedStep, totp.CreatedAt, totp.ConfirmedAt,
+	)
+	return err
+}
+
+// DeleteUserTOTP removes a user's TOTP enrollment and recovery codes
+func (db *SQLiteDB) DeleteUserTOTP(ctx context.Context, userID string) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		tx := db.conn(ctx)
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = ?`, userID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM user_totp WHERE user_id = ?`, userID); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// AdvanceTOTPStep records an accepted time step, reporting false when the
+// step (or a later one) was already used
+func (db *SQLiteDB) AdvanceTOTPStep(ctx context.Context, userID string, step int64) (bool, error) {
+	query := `UPDATE user_totp SET last_used_step = ? WHERE user_id = ? AND last_used_step < ?`
+	result, err := db.conn(ctx).ExecContext(ctx, query, step, userID, step)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n == 1, err
+}
+
+// ClaimTOTPAttempt counts an attempt at the user's second factor, reporting
+// false while attempts are locked. The attempt that reaches maxAttempts
+// without a reset locks further ones until lockUntil. Concurrent attempts
+// are counted one by one, so they cannot get past the limit together.
+func (db *SQLiteDB) ClaimTOTPAttempt(ctx context.Context, userID string, now time.Time, maxAttempts int, lockUntil time.Time) (bool, error) {
+	query := `
+		UPDATE user_totp
+		SET failed_attempts = failed_attempts + 1,
+			locked_until = CASE WHEN failed_attempts + 1 >= ? THEN ? ELSE NULL END
+		WHERE user_id = ? AND (locked_until IS NULL OR locked_until <= ?)
+	`
+	result, err := db.conn(ctx).ExecContext(ctx, query, maxAttempts, lockUntil, userID, now)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n == 1, err
+}
+
+// ResetTOTPAttempts clears the attempt count once a second factor was accepted
+func (db *SQLiteDB) ResetTOTPAttempts(ctx context.Context, userID string) error {
+	query := `UPDATE user_totp SET failed_attempts = 0, locked_until = NULL WHERE user_id = ?`
+	_, err := db.conn(ctx).ExecContext(ctx, query, userID)
+	return err
+}
+
+// ReplaceRecoveryCodes replaces all of a user's recovery codes
+func (db *SQLiteDB) ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		tx := db.conn(ctx)
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = ?`, userID); err != nil {
+			return err
+		}
+		for _, hash := range codeHashes {
+			if _, err := tx.ExecContext(ctx, `INSERT INTO user_recovery_codes (user_id, code_hash) VALUES (?, ?)`, userID, hash); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ConsumeRecoveryCode marks an unused recovery code as used, reporting
+// whether one matched
+func (db *SQLiteDB) ConsumeRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error) {
+	query := `
+		UPDATE user_recovery_codes
+		SET used_at = ?
+		WHERE user_id = ? AND code_hash = ? AND used_at IS NULL
+	`
+	result, err := db.conn(ctx).ExecContext(ctx, query, time.Now(), userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n == 1, err
+}
+
+// CountRecoveryCodes counts a user's unused recovery codes
+func (db *SQLiteDB) CountRecoveryCodes(ctx context.Context, userID string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM user_recovery_codes WHERE user_id = ? AND used_at IS NULL`
+	err := db.conn(ctx).QueryRowContext(ctx, query, userID).Scan(&count)
+	return count, err
+}
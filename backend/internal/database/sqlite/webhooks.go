@@ -0,0 +1,244 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Webhook operations
+
+const webhookColumns = `id, user_id, url, secret, events, COALESCE(description, ''), active, created_at, updated_at`
+
+const webhookDeliveryColumns = `id, webhook_id, event_id, event_type, payload, status, attempts,
+	COALESCE(response_status, 0), COALESCE(last_error, ''), next_attempt_at, created_at, delivered_at`
+
+// CreateWebhook registers a webhook
+func (db *SQLiteDB) CreateWebhook(ctx context.Context, webhook *database.Webhook) error {
+	events, err := json.Marshal(webhook.Events)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook events: %w", err)
+	}
+
+	query := `
+		INSERT INTO webhooks (id, user_id, url, secret, events, description, active, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = db.conn(ctx).ExecContext(ctx, query,
+		webhook.ID, webhook.UserID, webhook.URL, webhook.Secret, string(events), webhook.Description,
+		webhook.Active, webhook.CreatedAt, webhook.UpdatedAt,
+	)
+	return err
+}
+
+// GetWebhookByID retrieves a webhook by ID
+func (db *SQLiteDB) GetWebhookByID(ctx context.Context, id string) (*database.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = ?`
+	return scanWebhook(db.conn(ctx).QueryRowContext(ctx, query, id))
+}
+
+// ListWebhooks lists a user's webhooks, oldest first
+func (db *SQLiteDB) ListWebhooks(ctx context.Context, userID string) ([]*database.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE user_id = ? ORDER BY created_at`
+	rows, err := db.conn(ctx).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []*database.Webhook{}
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+// UpdateWebhook updates a webhook
+func (db *SQLiteDB) UpdateWebhook(ctx context.Context, webhook *database.Webhook) error {
+	events, err := json.Marshal(webhook.Events)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook events: %w", err)
+	}
+
+	query := `
+		UPDATE webhooks
+		SET url = ?, secret = ?, events = ?, description = ?, active = ?, updated_at = ?
+		WHERE id = ?
+	`
+	_, err = db.conn(ctx).ExecContext(ctx, query,
+		webhook.URL, webhook.Secret, string(events), webhook.Description, webhook.Active, webhook.UpdatedAt,
+		webhook.ID,
+	)
+	return err
+}
+
+// DeleteWebhook deletes a webhook and its delivery log
+func (db *SQLiteDB) DeleteWebhook(ctx context.Context, id string) error {
+	_, err := db.conn(ctx).ExecContext(ctx, `DELETE FROM webhooks WHERE id = ?`, id)
+	return err
+}
+
+// CreateWebhookDelivery records a delivery before it is first attempted
+func (db *SQLiteDB) CreateWebhookDelivery(ctx context.Context, delivery *database.WebhookDelivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (id, webhook_id, event_id, event_type, payload, status, attempts,
+		                                next_attempt_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := db.conn(ctx).ExecContext(ctx, query,
+		delivery.ID, delivery.WebhookID, delivery.EventID, delivery.EventType, string(delivery.Payload),
+		delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.CreatedAt,
+	)
+	return err
+}
+
+// UpdateWebhookDelivery records the outcome of a delivery attempt
+func (db *SQLiteDB) UpdateWebhookDelivery(ctx context.Context, delivery *database.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, response_status = NULLIF(?, 0), last_error = ?,
+		    next_attempt_at = ?, delivered_at = ?
+		WHERE id = ?
+	`
+	_, err := db.conn(ctx).ExecContext(ctx, query,
+		delivery.Status, delivery.Attempts, delivery.ResponseStatus, delivery.LastError,
+		delivery.NextAttemptAt, delivery.DeliveredAt, delivery.ID,
+	)
+	return err
+}
+
+// ListWebhookDeliveries lists deliveries, newest first
+func (db *SQLiteDB) ListWebhookDeliveries(ctx context.Context, filter database.WebhookDeliveryFilter) ([]*database.WebhookDelivery, error) {
+	where, args := webhookDeliveryConditions(filter)
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE ` + where
+
+	if filter.After != nil {
+		query += " AND (created_at, id) < (?, ?)"
+		args = append(args, filter.After.Time.UTC(), filter.After.ID)
+	}
+
+	query += " ORDER BY created_at DESC, id DESC"
+
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+		if filter.Offset > 0 && filter.After == nil {
+			query += " OFFSET ?"
+			args = append(args, filter.Offset)
+		}
+	}
+
+	return db.queryWebhookDeliveries(ctx, query, args...)
+}
+
+// CountWebhookDeliveries counts the deliveries matching a filter, ignoring its paging
+func (db *SQLiteDB) CountWebhookDeliveries(ctx context.Context, filter database.WebhookDeliveryFilter) (int, error) {
+	where, args := webhookDeliveryConditions(filter)
+	var count int
+	err := db.conn(ctx).QueryRowContext(ctx, `SELECT COUNT(*) FROM webhook_deliveries WHERE `+where, args...).Scan(&count)
+	return count, err
+}
+
+// webhookDeliveryConditions builds the WHERE clause shared by listing and counting
+func webhookDeliveryConditions(filter database.WebhookDeliveryFilter) (string, []interface{}) {
+	where := "1=1"
+	args := []interface{}{}
+	if filter.WebhookID != "" {
+		where += " AND webhook_id = ?"
+		args = append(args, filter.WebhookID)
+	}
+	if filter.Status != "" {
+		where += " AND status = ?"
+		args = append(args, filter.Status)
+	}
+	return where, args
+}
+
+// ListDueWebhookDeliveries lists pending deliveries whose next attempt is due
+func (db *SQLiteDB) ListDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]*database.WebhookDelivery, error) {
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries
+		WHERE status = ? AND next_attempt_at <= ?
+		ORDER BY next_attempt_at
+		LIMIT ?
+	`
+	return db.queryWebhookDeliveries(ctx, query, database.WebhookDeliveryPending, now.UTC(), limit)
+}
+
+// PurgeWebhookDeliveries deletes settled deliveries created before the cutoff
+func (db *SQLiteDB) PurgeWebhookDeliveries(ctx context.Context, before time.Time) (int64, error) {
+	result, err := db.conn(ctx).ExecContext(ctx,
+		`DELETE FROM webhook_deliveries WHERE created_at < ? AND status <> ?`,
+		before.UTC(), database.WebhookDeliveryPending,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (db *SQLiteDB) queryWebhookDeliveries(ctx context.Context, query string, args ...any) ([]*database.WebhookDelivery, error) {
+	rows, err := db.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []*database.WebhookDelivery{}
+	for rows.Next() {
+		var delivery database.WebhookDelivery
+		var payload string
+		if err := rows.Scan(
+			&delivery.ID, &delivery.WebhookID, &delivery.EventID, &delivery.EventType, &payload,
+			&delivery.Status, &delivery.Attempts, &delivery.ResponseStatus, &delivery.LastError,
+			&delivery.NextAttemptAt, &delivery.CreatedAt, &delivery.DeliveredAt,
+		); err != nil {
+			return nil, err
+		}
+		delivery.Payload = []byte(payload)
+		deliveries = append(deliveries, &delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+func scanWebhook(row interface{ Scan(dest ...any) error }) (*database.Webhook, error) {
+	var webhook database.Webhook
+	var events string
+	err := row.Scan(
+		&webhook.ID, &webhook.UserID, &webhook.URL, &webhook.Secret, &events,
+		&webhook.Description, &webhook.Active, &webhook.CreatedAt, &webhook.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(events), &webhook.Events); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook events: %w", err)
+	}
+	return &webhook, nil
+}
@@ -0,0 +1,89 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package database
+
+// UserDataTable selects the rows of one table that belong to a user
+type UserDataTable struct {
+	Table string
+	Where string   // condition on the table's columns; :user is the user's ID
+	Omit  []string // credential columns left out of exports
+}
+
+// UserDataTables lists where a user's data lives, for personal data
+// exports. Every table here references users with ON DELETE CASCADE,
+// directly or through its parent, so deleting the user removes the rows;
+// audit events alone are kept, with the user cleared. Tables added later
+// that hold personal data belong here too.
+var UserDataTables = []UserDataTable{
+	{Table: "users", Where: "id = :user", Omit: []string{"password_hash"}},
+	{Table: "user_identities", Where: "user_id = :user"},
+	{Table: "user_totp", Where: "user_id = :user", Omit: []string{"secret"}},
+	{Table: "auth_sessions", Where: "user_id = :user", Omit: []string{"refresh_token_hash"}},
+	{Table: "api_tokens", Where: "user_id = :user", Omit: []string{"token_hash"}},
+	{Table: "password_reset_tokens", Where: "user_id = :user", Omit: []string{"token_hash"}},
+	{Table: "handoff_tokens", Where: "user_id = :user", Omit: []string{"token_hash"}},
+	{Table: "recipes", Where: "user_id = :user"},
+	{Table: "recipe_categories", Where: "recipe_id IN (SELECT id FROM recipes WHERE user_id = :user)"},
+	{Table: "recipe_tags", Where: "recipe_id IN (SELECT id FROM recipes WHERE user_id = :user)"},
+	{Table: "ingredients", Where: "recipe_id IN (SELECT id FROM recipes WHERE user_id = :user)"},
+	{Table: "recipe_nutrition", Where: "recipe_id IN (SELECT id FROM recipes WHERE user_id = :user)"},
+	{Table: "recipe_revisions", Where: "recipe_id IN (SELECT id FROM recipes WHERE user_id = :user)"},
+	{Table: "recipe_equipment", Where: "recipe_id IN (SELECT id FROM recipes WHERE user_id = :user)"},
+	{Table: "recipe_translations", Where: "recipe_id IN (SELECT id FROM recipes WHERE user_id = :user)"},
+	{Table: "recipe_sensory_tags", Where: "recipe_id IN (SELECT id FROM recipes WHERE user_id = :user)"},
+	{Table: "recipe_dietary_analyses", Where: "recipe_id IN (SELECT id FROM recipes WHERE user_id = :user)"},
+	{Table: "recipe_dietary_tags", Where: "recipe_id IN (SELECT id FROM recipes WHERE user_id = :user)"},
+	{Table: "recipe_notes", Where: "user_id = :user"},
+	{Table: "recipe_ratings", Where: "user_id = :user"},
+	{Table: "recipe_shares", Where: "user_id = :user", Omit: []string{"token_hash"}},
+	{Table: "meal_plans", Where: "user_id = :user"},
+	{Table: "planned_meals", Where: "meal_plan_id IN (SELECT id FROM meal_plans WHERE user_id = :user)"},
+	{Table: "pantry_items", Where: "user_id = :user"},
+	{Table: "shopping_list_items", Where: "user_id = :user"},
+	{Table: "grocery_prices", Where: "user_id = :user"},
+	{Table: "grocery_budgets", Where: "user_id = :user"},
+	{Table: "nutrition_logs", Where: "user_id = :user"},
+	{Table: "leftovers", Where: "user_id = :user"},
+	{Table: "cooking_sessions", Where: "user_id = :user"},
+	{Table: "cooking_timers", Where: "user_id = :user"},
+	{Table: "safe_foods", Where: "user_id = :user"},
+	{Table: "sensory_profiles", Where: "user_id = :user"},
+	{Table: "email_preferences", Where: "user_id = :user"},
+	{Table: "user_locales", Where: "user_id = :user"},
+	{Table: "energy_checkins", Where: "user_id = :user"},
+	{Table: "meal_time_preferences", Where: "user_id = :user"},
+	{Table: "dietary_restrictions", Where: "user_id = :user"},
+	{Table: "households", Where: "id IN (SELECT household_id FROM household_members WHERE user_id = :user)"},
+	{Table: "household_members", Where: "user_id = :user"},
+	{Table: "store_layouts", Where: "household_id IN (SELECT household_id FROM household_members WHERE user_id = :user)"},
+	{Table: "household_equipment", Where: "household_id IN (SELECT household_id FROM household_members WHERE user_id = :user)"},
+	{Table: "meal_requests", Where: "household_id IN (SELECT household_id FROM household_members WHERE user_id = :user)"},
+	{Table: "meal_request_votes", Where: "user_id = :user"},
+	{Table: "household_invitations", Where: "invited_by = :user OR responded_by = :user", Omit: []string{"code_hash"}},
+	{Table: "ai_usage", Where: "user_id = :user"},
+	{Table: "ai_invocations", Where: "user_id = :user"},
+	{Table: "webhooks", Where: "user_id = :user", Omit: []string{"secret"}},
+	{Table: "webhook_deliveries", Where: "webhook_id IN (SELECT id FROM webhooks WHERE user_id = :user)"},
+	{Table: "calendar_feeds", Where: "user_id = :user", Omit: []string{"token_hash"}},
+	{Table: "external_calendars", Where: "user_id = :user"},
+	{Table: "external_calendar_events", Where: "user_id = :user"},
+	{Table: "account_deletions", Where: "user_id = :user"},
+	{Table: "audit_events", Where: "user_id = :user"},
+	{Table: "idempotency_keys", Where: "user_id = :user"},
+}
@@ -16,18 +16,21 @@
  * along with this program. If not, see <https://www.gnu.org/licenses/>.
  */
 
-package database
+// Package dbfactory opens the configured database engine. It lives outside
+// package database, which the engines import.
+package dbfactory
 
 import (
 	"fmt"
 
 	"github.com/rghsoftware/space-food/internal/config"
+	"github.com/rghsoftware/space-food/internal/database"
 	"github.com/rghsoftware/space-food/internal/database/postgres"
 	"github.com/rghsoftware/space-food/internal/database/sqlite"
 )
 
 // NewDatabase creates a new database instance based on configuration
-func NewDatabase(cfg *config.Config) (Database, error) {
+func NewDatabase(cfg *config.Config) (database.Database, error) {
 	switch cfg.Database.Type {
 	case "postgres":
 		connString := fmt.Sprintf(
@@ -44,6 +47,9 @@ func NewDatabase(cfg *config.Config) (Database, error) {
 	case "sqlite":
 		return sqlite.NewSQLiteDB(cfg.Database.SQLitePath)
 
+	case "memory":
+		return sqlite.NewMemoryDB()
+
 	default:
 		return nil, fmt.Errorf("unsupported database type: %s", cfg.Database.Type)
 	}
@@ -0,0 +1,136 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package events
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// Event types
+const (
+	MealLogged           = "meal_logged"
+	ShoppingListUpdated  = "shopping_list_updated"
+	CookingSessionPaused = "cooking_session_paused"
+	LeftoverThawDue      = "leftover_thaw_due"
+	WeeklyReviewReady    = "weekly_review_ready"
+	Ping                 = "ping" // sent to test an integration
+)
+
+// Types lists the event types integrations can subscribe to
+func Types() []string {
+	return []string{MealLogged, ShoppingListUpdated, CookingSessionPaused, LeftoverThawDue, WeeklyReviewReady}
+}
+
+// IsType reports whether t is an event type integrations can subscribe to
+func IsType(t string) bool {
+	for _, known := range Types() {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is something that happened to a user's data
+type Event struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	UserID     string    `json:"user_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+// New creates an event with a fresh ID
+func New(eventType, userID string, data any) Event {
+	return Event{
+		ID:         uuid.New().String(),
+		Type:       eventType,
+		UserID:     userID,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}
+}
+
+// Subscriber handles published events
+type Subscriber func(ctx context.Context, event Event)
+
+// Bus fans events out to subscribers in-process. Subscribers run in the
+// background so a slow integration never holds up the request that caused
+// the event.
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers []Subscriber
+	wg          sync.WaitGroup
+}
+
+// NewBus creates a new event bus
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe adds a subscriber. Features call it while the router is being
+// set up.
+func (b *Bus) Subscribe(subscriber Subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers = append(b.subscribers, subscriber)
+}
+
+// Publish hands an event to every subscriber. The request context is not
+// passed on, as subscribers usually outlive the request.
+func (b *Bus) Publish(ctx context.Context, event Event) {
+	b.mu.RLock()
+	subscribers := append([]Subscriber{}, b.subscribers...)
+	b.mu.RUnlock()
+
+	ctx = context.WithoutCancel(ctx)
+	for _, subscriber := range subscribers {
+		b.wg.Add(1)
+		go func(subscriber Subscriber) {
+			defer b.wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Ctx(ctx).Error().Str("event", event.Type).Str("panic", fmt.Sprint(r)).Msg("Event subscriber panicked")
+				}
+			}()
+			subscriber(ctx, event)
+		}(subscriber)
+	}
+}
+
+// Wait waits for subscribers still handling events until ctx expires
+func (b *Bus) Wait(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		logger.Get().Warn().Msg("Stopped waiting for event subscribers to finish")
+	}
+}
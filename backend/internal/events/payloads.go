@@ -0,0 +1,151 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package events
+
+import (
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Shopping list actions
+const (
+	ShoppingListItemAdded     = "added"
+	ShoppingListItemUpdated   = "updated"
+	ShoppingListItemCompleted = "completed"
+	ShoppingListItemReopened  = "reopened"
+	ShoppingListItemRemoved   = "removed"
+)
+
+// MealLoggedData is the payload of a meal_logged event
+type MealLoggedData struct {
+	LogID    string    `json:"log_id"`
+	FoodName string    `json:"food_name"`
+	MealType string    `json:"meal_type,omitempty"`
+	Servings float64   `json:"servings"`
+	RecipeID *string   `json:"recipe_id,omitempty"`
+	Calories float64   `json:"calories"`
+	Date     time.Time `json:"date"`
+}
+
+// NewMealLogged creates a meal_logged event for a nutrition log
+func NewMealLogged(log *database.NutritionLog) Event {
+	return New(MealLogged, log.UserID, MealLoggedData{
+		LogID:    log.ID,
+		FoodName: log.FoodName,
+		MealType: log.MealType,
+		Servings: log.Servings,
+		RecipeID: log.RecipeID,
+		Calories: log.NutritionInfo.Calories * log.Servings,
+		Date:     log.Date,
+	})
+}
+
+// ShoppingListUpdatedData is the payload of a shopping_list_updated event
+type ShoppingListUpdatedData struct {
+	Action    string  `json:"action"` // added, updated, completed, reopened, removed
+	ItemID    string  `json:"item_id"`
+	Name      string  `json:"name"`
+	Quantity  float64 `json:"quantity,omitempty"`
+	Unit      string  `json:"unit,omitempty"`
+	Category  string  `json:"category,omitempty"`
+	Completed bool    `json:"completed"`
+}
+
+// NewShoppingListUpdated creates a shopping_list_updated event for an item
+func NewShoppingListUpdated(action string, item *database.ShoppingListItem) Event {
+	return New(ShoppingListUpdated, item.UserID, ShoppingListUpdatedData{
+		Action:    action,
+		ItemID:    item.ID,
+		Name:      item.Name,
+		Quantity:  item.Quantity,
+		Unit:      item.Unit,
+		Category:  item.Category,
+		Completed: item.Completed,
+	})
+}
+
+// CookingSessionPausedData is the payload of a cooking_session_paused
+// event, sent when a session is paused for lack of activity
+type CookingSessionPausedData struct {
+	SessionID   string  `json:"session_id"`
+	RecipeID    *string `json:"recipe_id,omitempty"`
+	RecipeTitle string  `json:"recipe_title"`
+	Step        int     `json:"step"` // counted from 1
+	StepCount   int     `json:"step_count"`
+	Message     string  `json:"message"` // a gentle nudge to show the cook
+}
+
+// NewCookingSessionPaused creates a cooking_session_paused event for a
+// session that was paused for lack of activity
+func NewCookingSessionPaused(session *database.CookingSession) Event {
+	return New(CookingSessionPaused, session.UserID, CookingSessionPausedData{
+		SessionID:   session.ID,
+		RecipeID:    session.RecipeID,
+		RecipeTitle: session.RecipeTitle,
+		Step:        session.CurrentStep + 1,
+		StepCount:   len(session.Steps),
+		Message:     "Your " + session.RecipeTitle + " session is paused. Want to pick it back up, or let it go guilt-free?",
+	})
+}
+
+// LeftoverThawDueData is the payload of a leftover_thaw_due event, sent
+// when frozen leftovers should move to the fridge to thaw
+type LeftoverThawDueData struct {
+	LeftoverID string    `json:"leftover_id"`
+	Name       string    `json:"name"`
+	Portions   float64   `json:"portions"`
+	Container  string    `json:"container,omitempty"`
+	ThawAt     time.Time `json:"thaw_at"`
+	Reheat     string    `json:"reheat,omitempty"`
+	Message    string    `json:"message"`
+}
+
+// NewLeftoverThawDue creates a leftover_thaw_due event for frozen leftovers
+// whose thaw time has come
+func NewLeftoverThawDue(leftover *database.Leftover) Event {
+	data := LeftoverThawDueData{
+		LeftoverID: leftover.ID,
+		Name:       leftover.Name,
+		Portions:   leftover.Portions,
+		Container:  leftover.Container,
+		Reheat:     leftover.Reheat,
+		Message:    "Time to move " + leftover.Name + " from the freezer to the fridge to thaw.",
+	}
+	if leftover.ThawAt != nil {
+		data.ThawAt = *leftover.ThawAt
+	}
+	return New(LeftoverThawDue, leftover.UserID, data)
+}
+
+// WeeklyReviewReadyData is the payload of a weekly_review_ready event,
+// sent on Sunday evening with the review of the week
+type WeeklyReviewReadyData struct {
+	Message string `json:"message"` // the review's summary
+	Review  any    `json:"review"`  // as returned by GET /me/weekly-review
+}
+
+// NewWeeklyReviewReady creates a weekly_review_ready event for a user's
+// weekly review
+func NewWeeklyReviewReady(userID, summary string, review any) Event {
+	return New(WeeklyReviewReady, userID, WeeklyReviewReadyData{
+		Message: summary,
+		Review:  review,
+	})
+}
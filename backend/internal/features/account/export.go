@@ -0,0 +1,143 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package account
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/images"
+	"github.com/rghsoftware/space-food/internal/storage"
+)
+
+// ManifestName is the export entry describing its contents
+const ManifestName = "manifest.json"
+
+// imagesPrefix holds the user's uploaded images in an export
+const imagesPrefix = "images/"
+
+// Manifest describes a personal data export
+type Manifest struct {
+	ExportedAt time.Time      `json:"exported_at"`
+	UserID     string         `json:"user_id"`
+	Tables     map[string]int `json:"tables"` // rows per table file
+	Images     int            `json:"images"` // files under images/
+}
+
+// WriteExport writes a zip archive of the user's data: a JSON array per
+// table in database.UserDataTables, their uploaded images and a manifest
+func WriteExport(ctx context.Context, db database.Database, store storage.Provider, userID string, w io.Writer) error {
+	zw := zip.NewWriter(w)
+	manifest := &Manifest{
+		ExportedAt: time.Now().UTC(),
+		UserID:     userID,
+		Tables:     map[string]int{},
+	}
+
+	dump := &exportDump{zw: zw, manifest: manifest}
+	if err := db.DumpUserData(ctx, userID, dump); err != nil {
+		return err
+	}
+	if err := dump.endTable(); err != nil {
+		return err
+	}
+
+	err := images.ForEach(ctx, store, userID, func(name string, r io.Reader) error {
+		entry, err := zw.CreateHeader(&zip.FileHeader{Name: imagesPrefix + name, Method: zip.Store})
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(entry, r); err != nil {
+			return err
+		}
+		manifest.Images++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	entry, err := zw.Create(ManifestName)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(entry)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(manifest); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// exportDump writes each table as an indented JSON array
+type exportDump struct {
+	zw       *zip.Writer
+	manifest *Manifest
+	table    string
+	entry    io.Writer
+}
+
+func (d *exportDump) BeginTable(name string) error {
+	if err := d.endTable(); err != nil {
+		return err
+	}
+	entry, err := d.zw.Create(name + ".json")
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(entry, "["); err != nil {
+		return err
+	}
+	d.table, d.entry = name, entry
+	d.manifest.Tables[name] = 0
+	return nil
+}
+
+func (d *exportDump) Row(row json.RawMessage) error {
+	sep := ",\n  "
+	if d.manifest.Tables[d.table] == 0 {
+		sep = "\n  "
+	}
+	if _, err := io.WriteString(d.entry, sep); err != nil {
+		return err
+	}
+	if _, err := d.entry.Write(row); err != nil {
+		return err
+	}
+	d.manifest.Tables[d.table]++
+	return nil
+}
+
+// endTable closes the array of the table being written, if any
+func (d *exportDump) endTable() error {
+	if d.entry == nil {
+		return nil
+	}
+	end := "\n]\n"
+	if d.manifest.Tables[d.table] == 0 {
+		end = "]\n"
+	}
+	_, err := io.WriteString(d.entry, end)
+	d.entry = nil
+	return err
+}
@@ -0,0 +1,258 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package account
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/audit"
+	"github.com/rghsoftware/space-food/internal/config"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/images"
+	"github.com/rghsoftware/space-food/internal/middleware"
+	"github.com/rghsoftware/space-food/internal/storage"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// purgeBatch caps how many accounts one purge run deletes
+const purgeBatch = 100
+
+// Handler handles data export and account deletion HTTP requests
+type Handler struct {
+	cfg   *config.Config
+	db    database.Database
+	store storage.Provider
+}
+
+// NewHandler creates a new account handler
+func NewHandler(cfg *config.Config, db database.Database, store storage.Provider) *Handler {
+	return &Handler{
+		cfg:   cfg,
+		db:    db,
+		store: store,
+	}
+}
+
+// RegisterRoutes registers account routes on the current user group
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.POST("/export", h.ExportData)
+	router.DELETE("", h.DeleteAccount)
+	router.GET("/deletion", h.GetDeletion)
+	router.DELETE("/deletion", h.CancelDeletion)
+}
+
+// ExportData streams a zip of every record tied to the authenticated user,
+// one JSON file per table, and their uploaded images
+// @Summary Export my data
+// @Tags account
+// @Produce application/zip
+// @Router /me/export [post]
+func (h *Handler) ExportData(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	audit.Record(c, audit.Entry{Action: audit.DataExported, TargetType: "user", TargetID: user.ID})
+
+	name := "space-food-export-" + time.Now().UTC().Format("20060102-150405") + ".zip"
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="`+name+`"`)
+	c.Status(http.StatusOK)
+	if err := WriteExport(c.Request.Context(), h.db, h.store, user.ID, c.Writer); err != nil {
+		logger.Ctx(c.Request.Context()).Error().Err(err).Str("user_id", user.ID).Msg("Data export failed")
+		c.Abort()
+	}
+}
+
+// DeleteAccount schedules the authenticated user's account for deletion
+// after the grace period and signs out every other session
+// @Summary Delete my account
+// @Tags account
+// @Produce json
+// @Router /me [delete]
+func (h *Handler) DeleteAccount(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+	if user.TokenScope != "" {
+		apierror.Abort(c, apierror.New(http.StatusForbidden, apierror.CodeInsufficientScope, "API tokens cannot delete accounts"))
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	// Keep at least one administrator on the instance
+	if user.IsAdmin {
+		stats, err := h.db.GetInstanceStats(ctx)
+		if err != nil {
+			apierror.Respond(c, err)
+			return
+		}
+		if stats.Admins <= 1 {
+			apierror.Conflict(c, "you are the only administrator; promote another user before deleting your account")
+			return
+		}
+	}
+
+	now := time.Now()
+	deletion := &database.AccountDeletion{
+		UserID:      user.ID,
+		RequestedAt: now,
+		DeleteAfter: now.AddDate(0, 0, max(h.cfg.Auth.DeletionGraceDays, 0)),
+	}
+	err := h.db.WithTx(ctx, func(ctx context.Context) error {
+		if err := h.db.ScheduleAccountDeletion(ctx, deletion); err != nil {
+			return err
+		}
+		return h.db.RevokeAuthSessions(ctx, user.ID, user.SessionID)
+	})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	audit.Record(c, audit.Entry{
+		Action:     audit.AccountDeletionRequested,
+		TargetType: "user",
+		TargetID:   user.ID,
+		Details:    map[string]any{"delete_after": deletion.DeleteAfter},
+	})
+
+	c.JSON(http.StatusAccepted, deletion)
+}
+
+// GetDeletion returns the authenticated user's pending account deletion
+// @Summary Get pending account deletion
+// @Tags account
+// @Produce json
+// @Router /me/deletion [get]
+func (h *Handler) GetDeletion(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	deletion, err := h.db.GetAccountDeletion(c.Request.Context(), user.ID)
+	if err != nil {
+		apierror.Lookup(c, err, "no account deletion is pending")
+		return
+	}
+
+	c.JSON(http.StatusOK, deletion)
+}
+
+// CancelDeletion keeps the authenticated user's account
+// @Summary Cancel account deletion
+// @Tags account
+// @Router /me/deletion [delete]
+func (h *Handler) CancelDeletion(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	if err := h.db.CancelAccountDeletion(c.Request.Context(), user.ID); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	audit.Record(c, audit.Entry{Action: audit.AccountDeletionCancelled, TargetType: "user", TargetID: user.ID})
+
+	c.Status(http.StatusNoContent)
+}
+
+// PurgeDeletedAccounts deletes the accounts whose grace period has ended.
+// Households they own pass to another member first; the rest of their
+// data goes with the user row, and their images are removed afterwards.
+func (h *Handler) PurgeDeletedAccounts(ctx context.Context) (string, error) {
+	due, err := h.db.ListDueAccountDeletions(ctx, time.Now(), purgeBatch)
+	if err != nil {
+		return "", err
+	}
+
+	failed := 0
+	for _, deletion := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := h.purge(ctx, deletion.UserID); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("user_id", deletion.UserID).Msg("Account deletion failed")
+			failed++
+			continue
+		}
+		// The account is gone either way; a failure here only leaves files behind
+		if err := images.RemoveAll(ctx, h.store, deletion.UserID); err != nil {
+			logger.Ctx(ctx).Warn().Err(err).Str("user_id", deletion.UserID).Msg("Failed to remove images of deleted account")
+		}
+	}
+	return fmt.Sprintf("deleted %d accounts, %d failed", len(due)-failed, failed), nil
+}
+
+func (h *Handler) purge(ctx context.Context, userID string) error {
+	return h.db.WithTx(ctx, func(ctx context.Context) error {
+		households, err := h.db.ListHouseholdsByUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+		for _, household := range households {
+			if household.OwnerID != userID {
+				continue
+			}
+			members, err := h.db.ListHouseholdMembers(ctx, household.ID)
+			if err != nil {
+				return err
+			}
+			// A household with nobody else to own it is deleted with its owner
+			if successor := nextOwner(members, userID); successor != "" {
+				if err := h.db.TransferHouseholdOwnership(ctx, household.ID, successor); err != nil {
+					return err
+				}
+			}
+		}
+		return h.db.DeleteUser(ctx, userID)
+	})
+}
+
+// nextOwner picks the longest-standing admin, or failing that the
+// longest-standing member, other than the departing owner. Participants
+// never inherit a household. Members are listed in the order they joined.
+func nextOwner(members []*database.HouseholdMember, ownerID string) string {
+	successor := ""
+	for _, member := range members {
+		if member.UserID == ownerID || member.Role == database.HouseholdRoleParticipant {
+			continue
+		}
+		if member.Role == database.HouseholdRoleAdmin {
+			return member.UserID
+		}
+		if successor == "" {
+			successor = member.UserID
+		}
+	}
+	return successor
+}
@@ -0,0 +1,89 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package admin
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/api/params"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// ListAuditEvents lists the audit trail, newest first
+// @Summary List audit events
+// @Tags admin
+// @Produce json
+// @Param user_id query string false "Who acted"
+// @Param action query string false "An action such as auth.login, or an area such as auth."
+// @Param target_type query string false "e.g. user, household, recipe"
+// @Param target_id query string false "ID of the target"
+// @Param since query string false "First day, YYYY-MM-DD (UTC)"
+// @Param until query string false "Last day, YYYY-MM-DD (UTC)"
+// @Param limit query int false "Maximum events to return (1-200, default 50)"
+// @Param offset query int false "Events to skip"
+// @Param cursor query string false "Keyset paging: empty for the first page, then next_cursor"
+// @Param include_total query bool false "Count all matching events"
+// @Router /admin/audit [get]
+func (h *Handler) ListAuditEvents(c *gin.Context) {
+	query := params.Query(c)
+	filter := database.AuditEventFilter{
+		UserID:     query.String("user_id"),
+		Action:     query.String("action"),
+		TargetType: query.String("target_type"),
+		TargetID:   query.String("target_id"),
+	}
+	if since, ok := query.Date("since", time.UTC); ok {
+		filter.Since = since
+	}
+	if until, ok := query.Date("until", time.UTC); ok {
+		filter.Until = until.AddDate(0, 0, 1)
+	}
+	page := query.Page(50)
+	withTotal := query.Bool("include_total")
+	if !query.Valid() {
+		return
+	}
+	filter.After = page.After
+	filter.Limit = page.Fetch()
+	filter.Offset = page.Offset
+
+	ctx := c.Request.Context()
+	events, err := h.db.ListAuditEvents(ctx, filter)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	var total *int
+	if withTotal {
+		count, err := h.db.CountAuditEvents(ctx, filter)
+		if err != nil {
+			apierror.Respond(c, err)
+			return
+		}
+		total = &count
+	}
+
+	events, next := params.Trim(events, page, func(event *database.AuditEvent) database.Cursor {
+		return database.Cursor{Time: event.CreatedAt, ID: event.ID}
+	})
+	params.WriteList(c, events, page, next, total)
+}
@@ -0,0 +1,112 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package admin
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/audit"
+	"github.com/rghsoftware/space-food/internal/backup"
+	"github.com/rghsoftware/space-food/internal/database/migrate"
+	"github.com/rghsoftware/space-food/internal/middleware"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// DownloadBackup streams an archive of every table and uploaded file
+// @Summary Download a full backup
+// @Tags admin
+// @Produce application/zip
+// @Router /admin/backup [get]
+func (h *Handler) DownloadBackup(c *gin.Context) {
+	// Fail before the response starts; afterwards errors can only be logged
+	if err := h.db.Migrations().Check(c.Request.Context()); err != nil {
+		apierror.Conflict(c, err.Error())
+		return
+	}
+
+	// Archives can be large, so don't hold them back for an ETag
+	middleware.SkipConditionalGET(c)
+
+	audit.Record(c, audit.Entry{Action: audit.BackupDownloaded})
+
+	name := "space-food-backup-" + time.Now().UTC().Format("20060102-150405") + ".zip"
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="`+name+`"`)
+	c.Status(http.StatusOK)
+	if _, err := backup.Write(c.Request.Context(), h.cfg, h.db, c.Writer); err != nil {
+		logger.Ctx(c.Request.Context()).Error().Err(err).Msg("Backup download failed")
+		c.Abort()
+	}
+}
+
+// RestoreBackup replaces every table and uploaded file with the archive in
+// the request body. Sessions are restored too, so clients may need to sign
+// in again.
+// @Summary Restore a full backup
+// @Tags admin
+// @Accept application/zip
+// @Produce json
+// @Router /admin/restore [post]
+func (h *Handler) RestoreBackup(c *gin.Context) {
+	if !h.restoring.TryLock() {
+		apierror.Conflict(c, "a restore is already running")
+		return
+	}
+	defer h.restoring.Unlock()
+
+	// The archive is read out of order, so spool it to disk first
+	f, err := os.CreateTemp("", "space-food-restore-*.zip")
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	size, err := io.Copy(f, c.Request.Body)
+	if err != nil {
+		apierror.BadRequest(c, "failed to read the archive")
+		return
+	}
+	if size == 0 {
+		apierror.BadRequest(c, "request body must be a backup archive")
+		return
+	}
+
+	manifest, err := backup.Restore(c.Request.Context(), h.cfg, h.db, f, size)
+	switch {
+	case errors.Is(err, backup.ErrInvalidArchive):
+		apierror.BadRequest(c, err.Error())
+	case errors.Is(err, backup.ErrIncompatible), errors.Is(err, migrate.ErrSchemaAhead), errors.Is(err, migrate.ErrUntracked):
+		apierror.Conflict(c, err.Error())
+	case err != nil:
+		apierror.Internal(c, err)
+	default:
+		// Recorded into the restored database, so the trail shows the
+		// restore even though it replaced the events before it
+		audit.Record(c, audit.Entry{Action: audit.BackupRestored, Details: map[string]any{"created_at": manifest.CreatedAt}})
+		c.JSON(http.StatusOK, manifest)
+	}
+}
@@ -0,0 +1,72 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package admin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/audit"
+	"github.com/rghsoftware/space-food/internal/config"
+)
+
+// configResponse is the effective configuration with secrets hidden
+type configResponse struct {
+	config.LiveStatus
+	Reloadable []string       `json:"reloadable"`
+	Changed    []string       `json:"changed,omitempty"` // what a reload just applied
+	Config     map[string]any `json:"config"`
+}
+
+// GetConfig shows the configuration the server runs with, including
+// reloaded settings, with passwords, keys and tokens replaced by
+// [redacted]
+// @Summary Effective configuration
+// @Tags admin
+// @Produce json
+// @Router /admin/config [get]
+func (h *Handler) GetConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, h.configResponse(nil))
+}
+
+// ReloadConfig reads the config file again and applies the settings that
+// are safe to change without a restart, as SIGHUP does
+// @Summary Reload the configuration
+// @Tags admin
+// @Produce json
+// @Router /admin/config/reload [post]
+func (h *Handler) ReloadConfig(c *gin.Context) {
+	changed, err := h.live.Reload()
+	if err != nil {
+		apierror.BadRequest(c, "configuration not reloaded: "+err.Error())
+		return
+	}
+	audit.Record(c, audit.Entry{Action: audit.ConfigReloaded, Details: map[string]any{"changed": changed}})
+	c.JSON(http.StatusOK, h.configResponse(changed))
+}
+
+func (h *Handler) configResponse(changed []string) configResponse {
+	return configResponse{
+		LiveStatus: h.live.Status(),
+		Reloadable: config.Reloadable(),
+		Changed:    changed,
+		Config:     h.live.Get().Redacted(),
+	}
+}
@@ -0,0 +1,411 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package admin
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/api/params"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/audit"
+	"github.com/rghsoftware/space-food/internal/auth"
+	"github.com/rghsoftware/space-food/internal/config"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/aiusage"
+	"github.com/rghsoftware/space-food/internal/features/email"
+	"github.com/rghsoftware/space-food/internal/middleware"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// Handler handles instance administration HTTP requests
+type Handler struct {
+	cfg          *config.Config
+	live         *config.Live
+	db           database.Database
+	authProvider auth.AuthProvider
+	notifier     *email.Notifier
+	restoring    sync.Mutex
+}
+
+// NewHandler creates a new admin handler
+func NewHandler(cfg *config.Config, live *config.Live, db database.Database, authProvider auth.AuthProvider, notifier *email.Notifier) *Handler {
+	return &Handler{
+		cfg:          cfg,
+		live:         live,
+		db:           db,
+		authProvider: authProvider,
+		notifier:     notifier,
+	}
+}
+
+// RegisterRoutes registers admin routes. The group must be restricted to
+// administrators with middleware.RequireAdmin.
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/users", h.ListUsers)
+	router.GET("/users/:id", h.GetUser)
+	router.PATCH("/users/:id", h.UpdateUser)
+	router.POST("/users/:id/reset-password", h.ResetPassword)
+	router.GET("/stats", h.GetStats)
+	router.GET("/metrics", h.GetMetrics)
+	router.GET("/settings", h.GetSettings)
+	router.PUT("/settings", h.UpdateSettings)
+	router.GET("/backup", h.DownloadBackup)
+	router.POST("/restore", h.RestoreBackup)
+	router.GET("/audit", h.ListAuditEvents)
+	router.GET("/config", h.GetConfig)
+	router.POST("/config/reload", h.ReloadConfig)
+}
+
+// userResponse is the admin view of a user, without credentials
+type userResponse struct {
+	ID                 string     `json:"id"`
+	Email              string     `json:"email"`
+	FirstName          string     `json:"first_name"`
+	LastName           string     `json:"last_name"`
+	CreatedAt          time.Time  `json:"created_at"`
+	LastLoginAt        *time.Time `json:"last_login_at"`
+	EmailVerified      bool       `json:"email_verified"`
+	Active             bool       `json:"active"`
+	IsAdmin            bool       `json:"is_admin"`
+	PasswordLogin      bool       `json:"password_login"`       // false for single sign-on only accounts
+	MustChangePassword bool       `json:"must_change_password"` // until the user replaces a password an administrator set
+}
+
+func toUserResponse(u *database.User) userResponse {
+	return userResponse{
+		ID:                 u.ID,
+		Email:              u.Email,
+		FirstName:          u.FirstName,
+		LastName:           u.LastName,
+		CreatedAt:          u.CreatedAt,
+		LastLoginAt:        u.LastLoginAt,
+		EmailVerified:      u.EmailVerified,
+		Active:             u.Active,
+		IsAdmin:            u.IsAdmin,
+		PasswordLogin:      u.PasswordHash != "",
+		MustChangePassword: u.MustChangePassword,
+	}
+}
+
+// ListUsers lists users on the instance
+// @Summary List users
+// @Tags admin
+// @Produce json
+// @Param q query string false "Search email or name"
+// @Param limit query int false "Maximum users to return (1-200, default 50)"
+// @Param offset query int false "Users to skip"
+// @Router /admin/users [get]
+func (h *Handler) ListUsers(c *gin.Context) {
+	query := params.Query(c)
+	search := query.String("q")
+	page := query.Page(50)
+	if !query.Valid() {
+		return
+	}
+
+	users, err := h.db.ListUsers(c.Request.Context(), database.UserFilter{
+		Query:  search,
+		Limit:  page.Limit,
+		Offset: page.Offset,
+	})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	resp := make([]userResponse, 0, len(users))
+	for _, u := range users {
+		resp = append(resp, toUserResponse(u))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetUser retrieves a single user
+// @Summary Get user
+// @Tags admin
+// @Produce json
+// @Router /admin/users/{id} [get]
+func (h *Handler) GetUser(c *gin.Context) {
+	user, err := h.db.GetUserByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierror.Lookup(c, err, "user not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, toUserResponse(user))
+}
+
+// UpdateUser enables or disables a user and grants or revokes admin rights
+// @Summary Update user
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Router /admin/users/{id} [patch]
+func (h *Handler) UpdateUser(c *gin.Context) {
+	admin, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req struct {
+		Active  *bool `json:"active"`
+		IsAdmin *bool `json:"is_admin"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	id := c.Param("id")
+
+	// Administrators cannot lock themselves out
+	if id == admin.ID && ((req.Active != nil && !*req.Active) || (req.IsAdmin != nil && !*req.IsAdmin)) {
+		apierror.BadRequest(c, "you cannot disable or demote your own account")
+		return
+	}
+
+	user, err := h.db.GetUserByID(c.Request.Context(), id)
+	if err != nil {
+		apierror.Lookup(c, err, "user not found")
+		return
+	}
+
+	if req.Active != nil {
+		user.Active = *req.Active
+	}
+	if req.IsAdmin != nil {
+		user.IsAdmin = *req.IsAdmin
+	}
+	user.UpdatedAt = time.Now()
+
+	err = h.db.WithTx(c.Request.Context(), func(ctx context.Context) error {
+		if err := h.db.UpdateUser(ctx, user); err != nil {
+			return err
+		}
+		// A disabled user is signed out everywhere
+		if !user.Active {
+			return h.db.RevokeAuthSessions(ctx, user.ID, "")
+		}
+		return nil
+	})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	audit.Record(c, audit.Entry{
+		Action:     audit.UserUpdated,
+		TargetType: "user",
+		TargetID:   user.ID,
+		Details:    map[string]any{"active": req.Active, "is_admin": req.IsAdmin},
+	})
+
+	c.JSON(http.StatusOK, toUserResponse(user))
+}
+
+// ResetPassword sets a new password for a user and signs them out; they
+// must change it after signing in unless require_change is false. When no
+// password is given a temporary one is generated and returned once. With
+// send_email the user is emailed a reset link instead, and their password
+// stays as it is until they use it.
+// @Summary Reset user password
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Router /admin/users/{id}/reset-password [post]
+func (h *Handler) ResetPassword(c *gin.Context) {
+	provider, ok := h.authProvider.(auth.AdminProvider)
+	if !ok {
+		apierror.Abort(c, apierror.New(http.StatusNotImplemented, apierror.CodeNotImplemented, "the auth provider does not support password resets"))
+		return
+	}
+
+	var req struct {
+		Password      string `json:"password"`
+		RequireChange *bool  `json:"require_change"`
+		SendEmail     bool   `json:"send_email"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	user, err := h.db.GetUserByID(ctx, c.Param("id"))
+	if err != nil {
+		apierror.Lookup(c, err, "user not found")
+		return
+	}
+
+	if req.SendEmail {
+		h.emailPasswordReset(c, user)
+		return
+	}
+
+	generated := req.Password == ""
+	if generated {
+		password, err := auth.GenerateTemporaryPassword()
+		if err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+		req.Password = password
+	}
+	requireChange := req.RequireChange == nil || *req.RequireChange
+
+	if err := provider.SetPassword(ctx, user.ID, req.Password, requireChange); errors.Is(err, auth.ErrWeakPassword) {
+		apierror.BadRequest(c, auth.ErrWeakPassword.Error())
+		return
+	} else if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	audit.Record(c, audit.Entry{
+		Action:     audit.PasswordReset,
+		TargetType: "user",
+		TargetID:   user.ID,
+		Details:    map[string]any{"generated": generated, "require_change": requireChange},
+	})
+
+	if generated {
+		c.JSON(http.StatusOK, gin.H{"temporary_password": req.Password})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// emailPasswordReset emails a user a link to choose a new password
+func (h *Handler) emailPasswordReset(c *gin.Context, user *database.User) {
+	provider, ok := h.authProvider.(auth.PasswordResetProvider)
+	if !ok || !h.notifier.Enabled() {
+		apierror.Abort(c, apierror.New(http.StatusNotImplemented, apierror.CodeNotImplemented, "reset emails need email to be set up on this instance"))
+		return
+	}
+
+	ctx := c.Request.Context()
+	resetUser, token, err := provider.CreatePasswordReset(ctx, user.Email)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	if resetUser == nil {
+		apierror.Conflict(c, "the user is disabled or signs in only through single sign-on")
+		return
+	}
+	if err := h.notifier.SendPasswordReset(ctx, resetUser, token, true); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("user_id", user.ID).Msg("Failed to send password reset email")
+		apierror.Upstream(c, "failed to send the reset email")
+		return
+	}
+	audit.Record(c, audit.Entry{
+		Action:     audit.PasswordReset,
+		TargetType: "user",
+		TargetID:   user.ID,
+		Details:    map[string]any{"emailed": true},
+	})
+
+	c.JSON(http.StatusAccepted, gin.H{"emailed": true})
+}
+
+// GetStats returns instance usage statistics
+// @Summary Get instance statistics
+// @Tags admin
+// @Produce json
+// @Router /admin/stats [get]
+func (h *Handler) GetStats(c *gin.Context) {
+	stats, err := h.db.GetInstanceStats(c.Request.Context())
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	period := aiusage.Period(time.Now())
+	usage, err := h.db.GetInstanceAIUsage(c.Request.Context(), period)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"counts": stats,
+		"ai": gin.H{
+			"enabled":   h.live.Get().AI.Enabled(),
+			"providers": h.live.Get().AI.EnabledProviders(),
+			"period":    period,
+			"usage":     usage,
+		},
+	})
+}
+
+// GetSettings returns the runtime instance settings
+// @Summary Get instance settings
+// @Tags admin
+// @Produce json
+// @Router /admin/settings [get]
+func (h *Handler) GetSettings(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"registration_mode": h.registrationMode(c),
+	})
+}
+
+// UpdateSettings changes runtime instance settings
+// @Summary Update instance settings
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Router /admin/settings [put]
+func (h *Handler) UpdateSettings(c *gin.Context) {
+	var req struct {
+		RegistrationMode string `json:"registration_mode" binding:"required,oneof=open invite_only closed"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	if err := h.db.SetInstanceSetting(c.Request.Context(), auth.RegistrationModeSetting, req.RegistrationMode); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	audit.Record(c, audit.Entry{
+		Action:  audit.SettingsUpdated,
+		Details: map[string]any{"registration_mode": req.RegistrationMode},
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"registration_mode": req.RegistrationMode,
+	})
+}
+
+// registrationMode returns the stored registration mode, falling back to config
+func (h *Handler) registrationMode(c *gin.Context) string {
+	if mode, err := h.db.GetInstanceSetting(c.Request.Context(), auth.RegistrationModeSetting); err == nil && mode != "" {
+		return mode
+	}
+	if h.cfg.Auth.Registration == "" {
+		return auth.RegistrationOpen
+	}
+	return h.cfg.Auth.Registration
+}
@@ -0,0 +1,310 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/api/params"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/aiusage"
+)
+
+// Rolled-up metrics. Weekly ones run Monday to Sunday in UTC.
+const (
+	metricActiveUsers      = "active_users"               // weekly
+	metricCookingSessions  = "cooking_sessions"           // weekly, sessions started
+	metricCookingCompleted = "cooking_sessions_completed" // weekly
+	metricImports          = "recipe_imports"             // weekly, by site
+	metricImportFailures   = "recipe_import_failures"     // weekly, by site
+	metricAIRequests       = "ai_requests"                // monthly, by provider
+	metricAIInputTokens    = "ai_input_tokens"            // monthly, by provider
+	metricAIOutputTokens   = "ai_output_tokens"           // monthly, by provider
+)
+
+// topFailingDomains caps the sites listed as failing imports
+const topFailingDomains = 10
+
+// WeekMetrics are the figures for one week of the dashboard
+type WeekMetrics struct {
+	Week                     string `json:"week"`   // ISO week, e.g. 2026-W42
+	Starts                   string `json:"starts"` // its Monday, YYYY-MM-DD
+	ActiveUsers              int    `json:"active_users"`
+	CookingSessions          int    `json:"cooking_sessions"`
+	CookingSessionsCompleted int    `json:"cooking_sessions_completed"`
+	RecipeImports            int    `json:"recipe_imports"`
+	RecipeImportFailures     int    `json:"recipe_import_failures"`
+}
+
+// ProviderMetrics is one AI provider's usage for a month
+type ProviderMetrics struct {
+	Period       string `json:"period"` // YYYY-MM
+	Provider     string `json:"provider"`
+	Requests     int64  `json:"requests"`
+	InputTokens  int64  `json:"input_tokens"`
+	OutputTokens int64  `json:"output_tokens"`
+}
+
+// DomainMetrics is how recipe imports from one site went
+type DomainMetrics struct {
+	Domain   string `json:"domain"`
+	Attempts int    `json:"attempts"`
+	Failures int    `json:"failures"`
+}
+
+// GetMetrics returns the instance dashboard: active users, cooking
+// sessions and recipe imports by week, AI usage by provider and month, and
+// the sites imports fail on most. Everything comes from the rollups the
+// instance-metrics job keeps, so nothing is counted per request and no
+// figure identifies a user.
+// @Summary Instance metrics dashboard
+// @Tags admin
+// @Produce json
+// @Param weeks query int false "Weeks to return, newest last (1-52, default 12)"
+// @Param months query int false "Months of AI usage to return (1-24, default 3)"
+// @Router /admin/metrics [get]
+func (h *Handler) GetMetrics(c *gin.Context) {
+	query := params.Query(c)
+	weekCount := query.Int("weeks", 12, 1, 52)
+	monthCount := query.Int("months", 3, 1, 24)
+	if !query.Valid() {
+		return
+	}
+
+	now := time.Now().UTC()
+	thisWeek := weekStart(now)
+	weeks := make([]WeekMetrics, weekCount)
+	weekIndex := map[string]int{}
+	periods := []string{}
+	for i := range weeks {
+		start := thisWeek.AddDate(0, 0, -7*(weekCount-1-i))
+		weeks[i] = WeekMetrics{Week: weekPeriod(start), Starts: start.Format("2006-01-02")}
+		weekIndex[weeks[i].Week] = i
+		periods = append(periods, weeks[i].Week)
+	}
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for i := monthCount - 1; i >= 0; i-- {
+		periods = append(periods, aiusage.Period(thisMonth.AddDate(0, -i, 0)))
+	}
+
+	metrics, err := h.db.ListInstanceMetrics(c.Request.Context(), periods)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	providers := map[[2]string]*ProviderMetrics{}
+	domains := map[string]*DomainMetrics{}
+	var rolledUpAt *time.Time
+	for _, m := range metrics {
+		if rolledUpAt == nil || m.UpdatedAt.After(*rolledUpAt) {
+			updated := m.UpdatedAt
+			rolledUpAt = &updated
+		}
+		switch m.Metric {
+		case metricAIRequests, metricAIInputTokens, metricAIOutputTokens:
+			key := [2]string{m.Period, m.Dimension}
+			usage := providers[key]
+			if usage == nil {
+				usage = &ProviderMetrics{Period: m.Period, Provider: m.Dimension}
+				providers[key] = usage
+			}
+			switch m.Metric {
+			case metricAIRequests:
+				usage.Requests = int64(m.Value)
+			case metricAIInputTokens:
+				usage.InputTokens = int64(m.Value)
+			case metricAIOutputTokens:
+				usage.OutputTokens = int64(m.Value)
+			}
+			continue
+		}
+
+		i, ok := weekIndex[m.Period]
+		if !ok {
+			continue
+		}
+		week := &weeks[i]
+		switch m.Metric {
+		case metricActiveUsers:
+			week.ActiveUsers = int(m.Value)
+		case metricCookingSessions:
+			week.CookingSessions = int(m.Value)
+		case metricCookingCompleted:
+			week.CookingSessionsCompleted = int(m.Value)
+		case metricImports, metricImportFailures:
+			domain := domains[m.Dimension]
+			if domain == nil {
+				domain = &DomainMetrics{Domain: m.Dimension}
+				domains[m.Dimension] = domain
+			}
+			if m.Metric == metricImports {
+				week.RecipeImports += int(m.Value)
+				domain.Attempts += int(m.Value)
+			} else {
+				week.RecipeImportFailures += int(m.Value)
+				domain.Failures += int(m.Value)
+			}
+		}
+	}
+
+	usage := make([]ProviderMetrics, 0, len(providers))
+	for _, p := range providers {
+		usage = append(usage, *p)
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Period != usage[j].Period {
+			return usage[i].Period < usage[j].Period
+		}
+		return usage[i].Provider < usage[j].Provider
+	})
+
+	failing := []DomainMetrics{}
+	for _, d := range domains {
+		if d.Failures > 0 {
+			failing = append(failing, *d)
+		}
+	}
+	sort.Slice(failing, func(i, j int) bool {
+		if failing[i].Failures != failing[j].Failures {
+			return failing[i].Failures > failing[j].Failures
+		}
+		return failing[i].Domain < failing[j].Domain
+	})
+	if len(failing) > topFailingDomains {
+		failing = failing[:topFailingDomains]
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"weeks":                  weeks,
+		"ai_usage":               usage,
+		"failing_import_domains": failing,
+		"rolled_up_at":           rolledUpAt,
+	})
+}
+
+// RollupMetrics recounts the dashboard figures for this week and last,
+// and this month's and last month's AI usage, then drops the import
+// records no later rollup will read. Active users are only counted for the
+// current week: just each user's latest sign-in is kept, so counting a
+// finished week again would miss everyone who has been back since.
+func (h *Handler) RollupMetrics(ctx context.Context) (string, error) {
+	now := time.Now().UTC()
+	thisWeek := weekStart(now)
+	lastWeek := thisWeek.AddDate(0, 0, -7)
+
+	for _, start := range []time.Time{lastWeek, thisWeek} {
+		if err := h.rollupWeek(ctx, start, now); err != nil {
+			return "", err
+		}
+	}
+
+	active, err := h.db.CountActiveUsers(ctx, thisWeek, thisWeek.AddDate(0, 0, 7))
+	if err != nil {
+		return "", err
+	}
+	err = h.db.ReplaceInstanceMetrics(ctx, weekPeriod(thisWeek), []string{metricActiveUsers}, []*database.InstanceMetric{
+		{Period: weekPeriod(thisWeek), Metric: metricActiveUsers, Value: float64(active), UpdatedAt: now},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for _, month := range []time.Time{thisMonth.AddDate(0, -1, 0), thisMonth} {
+		if err := h.rollupAIUsage(ctx, aiusage.Period(month), now); err != nil {
+			return "", err
+		}
+	}
+
+	purged, err := h.db.PurgeRecipeImports(ctx, lastWeek)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("rolled up %s and %s, removed %d import records", weekPeriod(lastWeek), weekPeriod(thisWeek), purged), nil
+}
+
+// rollupWeek recounts the cooking sessions and recipe imports of the week
+// starting at start
+func (h *Handler) rollupWeek(ctx context.Context, start, now time.Time) error {
+	end := start.AddDate(0, 0, 7)
+	period := weekPeriod(start)
+
+	started, completed, err := h.db.CountCookingSessions(ctx, start, end)
+	if err != nil {
+		return err
+	}
+	imports, err := h.db.CountRecipeImports(ctx, start, end)
+	if err != nil {
+		return err
+	}
+
+	metrics := []*database.InstanceMetric{
+		{Period: period, Metric: metricCookingSessions, Value: float64(started), UpdatedAt: now},
+		{Period: period, Metric: metricCookingCompleted, Value: float64(completed), UpdatedAt: now},
+	}
+	for _, count := range imports {
+		metrics = append(metrics, &database.InstanceMetric{
+			Period: period, Metric: metricImports, Dimension: count.Domain, Value: float64(count.Attempts), UpdatedAt: now,
+		})
+		if count.Failures > 0 {
+			metrics = append(metrics, &database.InstanceMetric{
+				Period: period, Metric: metricImportFailures, Dimension: count.Domain, Value: float64(count.Failures), UpdatedAt: now,
+			})
+		}
+	}
+	return h.db.ReplaceInstanceMetrics(ctx, period,
+		[]string{metricCookingSessions, metricCookingCompleted, metricImports, metricImportFailures}, metrics)
+}
+
+// rollupAIUsage copies a month's AI usage totals by provider
+func (h *Handler) rollupAIUsage(ctx context.Context, period string, now time.Time) error {
+	usage, err := h.db.GetInstanceAIUsage(ctx, period)
+	if err != nil {
+		return err
+	}
+	metrics := []*database.InstanceMetric{}
+	for _, u := range usage {
+		metrics = append(metrics,
+			&database.InstanceMetric{Period: period, Metric: metricAIRequests, Dimension: u.Provider, Value: float64(u.Requests), UpdatedAt: now},
+			&database.InstanceMetric{Period: period, Metric: metricAIInputTokens, Dimension: u.Provider, Value: float64(u.InputTokens), UpdatedAt: now},
+			&database.InstanceMetric{Period: period, Metric: metricAIOutputTokens, Dimension: u.Provider, Value: float64(u.OutputTokens), UpdatedAt: now},
+		)
+	}
+	return h.db.ReplaceInstanceMetrics(ctx, period, []string{metricAIRequests, metricAIInputTokens, metricAIOutputTokens}, metrics)
+}
+
+// weekStart returns midnight UTC on the Monday of t's week
+func weekStart(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// weekPeriod names t's ISO week, e.g. 2026-W42
+func weekPeriod(t time.Time) string {
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
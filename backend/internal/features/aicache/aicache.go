@@ -0,0 +1,170 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package aicache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// Cache reuses AI outputs across requests. Entries are keyed on a hash of
+// the actual prompt inputs, so any change to a recipe, setting or prompt
+// template produces a new key instead of serving a stale answer. As a
+// backstop, entries linked to a recipe also remember the recipe's
+// updated_at and are not served once it moves on.
+type Cache struct {
+	db     database.Database
+	ttl    time.Duration
+	hits   atomic.Int64
+	misses atomic.Int64
+	stale  atomic.Int64
+}
+
+// Stats reports cache effectiveness
+type Stats struct {
+	Enabled bool                     `json:"enabled"`
+	Hits    int64                    `json:"hits"`   // since the server started
+	Misses  int64                    `json:"misses"` // since the server started
+	Stale   int64                    `json:"stale"`  // misses on outdated entries, also counted in Misses
+	Kinds   []*database.AICacheStats `json:"kinds"`
+}
+
+// NewCache creates a new AI output cache; ttlHours of 0 disables caching
+func NewCache(db database.Database, ttlHours int) *Cache {
+	return &Cache{
+		db:  db,
+		ttl: time.Duration(ttlHours) * time.Hour,
+	}
+}
+
+// Key derives a cache key from the kind of output and every input that
+// goes into its prompt. Inputs must be JSON-encodable; map keys are sorted
+// by encoding/json, so equal inputs always hash the same.
+func Key(kind string, inputs any) (string, error) {
+	data, err := json.Marshal(inputs)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cache inputs: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return kind + ":" + hex.EncodeToString(sum[:]), nil
+}
+
+// Get loads a cached output into dest, reporting whether there was a live entry
+func (c *Cache) Get(ctx context.Context, key string, dest any) (bool, error) {
+	if c.ttl <= 0 {
+		return false, nil
+	}
+
+	entry, err := c.db.GetAICacheEntry(ctx, key)
+	if err != nil || !entry.ExpiresAt.After(time.Now()) {
+		c.misses.Add(1)
+		return false, nil
+	}
+	if entry.Stale {
+		// The recipe changed without its change hook running
+		c.misses.Add(1)
+		c.stale.Add(1)
+		if err := c.db.DeleteAICacheEntry(ctx, key); err != nil {
+			logger.Ctx(ctx).Warn().Err(err).Str("key", key).Msg("failed to drop stale AI cache entry")
+		}
+		return false, nil
+	}
+	if err := json.Unmarshal(entry.Payload, dest); err != nil {
+		c.misses.Add(1)
+		return false, fmt.Errorf("failed to decode cached output: %w", err)
+	}
+
+	c.hits.Add(1)
+	if err := c.db.RecordAICacheHit(ctx, key); err != nil {
+		logger.Ctx(ctx).Warn().Err(err).Str("key", key).Msg("failed to record AI cache hit")
+	}
+	return true, nil
+}
+
+// Set stores an output. recipeID links the entry to a recipe so it is
+// dropped when the recipe changes; pass "" for outputs not tied to one.
+func (c *Cache) Set(ctx context.Context, key, kind, recipeID string, value any) error {
+	if c.ttl <= 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+
+	now := time.Now()
+	entry := &database.AICacheEntry{
+		Key:       key,
+		Kind:      kind,
+		Payload:   payload,
+		CreatedAt: now,
+		ExpiresAt: now.Add(c.ttl),
+	}
+	if recipeID != "" {
+		entry.RecipeID = &recipeID
+	}
+	return c.db.PutAICacheEntry(ctx, entry)
+}
+
+// InvalidateRecipe drops every output derived from a recipe. It is
+// registered as a recipe change hook.
+func (c *Cache) InvalidateRecipe(ctx context.Context, recipeID string) {
+	n, err := c.db.DeleteAICacheForRecipe(ctx, recipeID)
+	if err != nil {
+		logger.Ctx(ctx).Warn().Err(err).Str("recipe_id", recipeID).Msg("failed to invalidate AI cache")
+		return
+	}
+	if n > 0 {
+		logger.Ctx(ctx).Debug().Int64("entries", n).Str("recipe_id", recipeID).Msg("invalidated AI cache")
+	}
+}
+
+// PurgeExpired removes expired entries
+func (c *Cache) PurgeExpired(ctx context.Context) (int64, error) {
+	return c.db.PurgeExpiredAICache(ctx, time.Now())
+}
+
+// Clear removes every entry
+func (c *Cache) Clear(ctx context.Context) (int64, error) {
+	return c.db.ClearAICache(ctx)
+}
+
+// Stats returns hit metrics and live entry counts per kind
+func (c *Cache) Stats(ctx context.Context) (*Stats, error) {
+	kinds, err := c.db.GetAICacheStats(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &Stats{
+		Enabled: c.ttl > 0,
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Stale:   c.stale.Load(),
+		Kinds:   kinds,
+	}, nil
+}
@@ -0,0 +1,77 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package aicache
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/audit"
+)
+
+// Handler handles AI cache administration HTTP requests
+type Handler struct {
+	cache *Cache
+}
+
+// NewHandler creates a new AI cache handler
+func NewHandler(cache *Cache) *Handler {
+	return &Handler{
+		cache: cache,
+	}
+}
+
+// RegisterRoutes registers AI cache routes. The group must be restricted to
+// administrators with middleware.RequireAdmin.
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("", h.GetStats)
+	router.DELETE("", h.Clear)
+}
+
+// GetStats returns cache hit metrics and entry counts
+// @Summary Get AI cache statistics
+// @Tags admin
+// @Produce json
+// @Router /admin/ai-cache [get]
+func (h *Handler) GetStats(c *gin.Context) {
+	stats, err := h.cache.Stats(c.Request.Context())
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// Clear empties the cache, forcing every AI output to be regenerated
+// @Summary Clear AI cache
+// @Tags admin
+// @Produce json
+// @Router /admin/ai-cache [delete]
+func (h *Handler) Clear(c *gin.Context) {
+	removed, err := h.cache.Clear(c.Request.Context())
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	audit.Record(c, audit.Entry{Action: audit.AICacheCleared, Details: map[string]any{"removed": removed}})
+
+	c.JSON(http.StatusOK, gin.H{"removed": removed})
+}
@@ -0,0 +1,229 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package aiprompts holds the prompt templates features send to the AI
+// provider. Each is built in and can be overridden per instance by a file
+// of the same name in ai.promptsdir, so prompts can be tuned without a
+// rebuild. Every prompt has an ID that changes with its text, stored with
+// what it generated so a regression can be traced to a prompt change.
+package aiprompts
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"text/template"
+
+	"github.com/rghsoftware/space-food/internal/ai"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// Prompts. Each is a .tmpl file defining "system" and "prompt".
+const (
+	Translation   = "translation"
+	Substitutions = "substitutions"
+)
+
+// TranslationData fills in the translation prompt
+type TranslationData struct {
+	Language string // the target language by name, e.g. Spanish
+	Recipe   string // the recipe's text as JSON, in the shape of the answer
+}
+
+// SubstitutionsData fills in the substitutions prompt
+type SubstitutionsData struct {
+	Title       string
+	Ingredients []string // the recipe's ingredients
+	Needed      []string // those to suggest substitutes for
+	Avoid       []string // dietary restrictions to respect
+	Language    string   // for amounts and notes; empty for English
+}
+
+// Sources of a prompt
+const (
+	SourceBuiltIn  = "built-in"
+	SourceOverride = "override"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// funcs are available to every template
+var funcs = template.FuncMap{"join": strings.Join}
+
+// Prompt describes one loaded prompt template
+type Prompt struct {
+	Name   string `json:"name"`
+	ID     string `json:"id"`             // name@version, the version a hash of the template
+	Source string `json:"source"`         // built-in or override
+	Path   string `json:"path,omitempty"` // the override file
+	// Error is why an override file was not used
+	Error string `json:"error,omitempty"`
+	Text  string `json:"template"`
+
+	tmpl    *template.Template
+	builtIn *Prompt // for an override, the prompt it replaces
+}
+
+// Registry hands out the prompt templates in use. Overrides are read when
+// it is created and again on Reload.
+type Registry struct {
+	current atomic.Value // map[string]*Prompt
+}
+
+// NewRegistry loads the built-in prompts and the overrides in dir, if set
+func NewRegistry(dir string) *Registry {
+	r := &Registry{}
+	r.Reload(dir)
+	return r
+}
+
+// Reload reads the override files in dir again. An override that can't be
+// read or parsed is reported and the built-in prompt kept.
+func (r *Registry) Reload(dir string) {
+	prompts := map[string]*Prompt{}
+	entries, err := templateFS.ReadDir("templates")
+	if err != nil {
+		// The templates are embedded, so this is a build problem
+		panic(err)
+	}
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".tmpl")
+		text, err := templateFS.ReadFile("templates/" + entry.Name())
+		if err != nil {
+			panic(err)
+		}
+		builtIn, err := parse(name, string(text))
+		if err != nil {
+			panic(err)
+		}
+		builtIn.Source = SourceBuiltIn
+		prompts[name] = builtIn
+
+		if dir == "" {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		text, err = os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		var override *Prompt
+		if err == nil {
+			override, err = parse(name, string(text))
+		}
+		if err != nil {
+			logger.Get().Warn().Err(err).Str("path", path).Msg("Ignoring AI prompt override")
+			builtIn.Path, builtIn.Error = path, err.Error()
+			continue
+		}
+		override.Source, override.Path, override.builtIn = SourceOverride, path, builtIn
+		prompts[name] = override
+	}
+	if dir != "" {
+		warnUnknown(dir, prompts)
+	}
+	r.current.Store(prompts)
+}
+
+// List returns the prompts in use, by name
+func (r *Registry) List() []*Prompt {
+	prompts := r.current.Load().(map[string]*Prompt)
+	list := make([]*Prompt, 0, len(prompts))
+	for _, prompt := range prompts {
+		list = append(list, prompt)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Request fills in a prompt for the provider and returns it with the ID of
+// the prompt used. When an override fails to render, the built-in prompt
+// is used instead.
+func (r *Registry) Request(name string, data any) (ai.Request, string, error) {
+	prompt, ok := r.current.Load().(map[string]*Prompt)[name]
+	if !ok {
+		return ai.Request{}, "", fmt.Errorf("unknown AI prompt %s", name)
+	}
+	req, err := prompt.render(data)
+	if err != nil && prompt.builtIn != nil {
+		logger.Get().Warn().Err(err).Str("path", prompt.Path).Msg("AI prompt override failed, using the built-in prompt")
+		prompt = prompt.builtIn
+		req, err = prompt.render(data)
+	}
+	if err != nil {
+		return ai.Request{}, "", err
+	}
+	return req, prompt.ID, nil
+}
+
+// warnUnknown logs the files in dir that override no prompt, so a
+// misnamed override doesn't go unnoticed
+func warnUnknown(dir string, prompts map[string]*Prompt) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		logger.Get().Warn().Err(err).Str("dir", dir).Msg("Failed to read the AI prompts directory")
+		return
+	}
+	for _, file := range files {
+		name, ok := strings.CutSuffix(file.Name(), ".tmpl")
+		if ok && prompts[name] == nil {
+			logger.Get().Warn().Str("path", filepath.Join(dir, file.Name())).Msg("AI prompt override matches no prompt")
+		}
+	}
+}
+
+func (p *Prompt) render(data any) (ai.Request, error) {
+	var system, prompt bytes.Buffer
+	if err := p.tmpl.ExecuteTemplate(&system, "system", data); err != nil {
+		return ai.Request{}, fmt.Errorf("failed to render AI prompt %s: %w", p.Name, err)
+	}
+	if err := p.tmpl.ExecuteTemplate(&prompt, "prompt", data); err != nil {
+		return ai.Request{}, fmt.Errorf("failed to render AI prompt %s: %w", p.Name, err)
+	}
+	return ai.Request{System: strings.TrimSpace(system.String()), Prompt: prompt.String()}, nil
+}
+
+// parse reads a prompt template, which must define both "system" and
+// "prompt"
+func parse(name, text string) (*Prompt, error) {
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	for _, part := range []string{"system", "prompt"} {
+		if tmpl.Lookup(part) == nil {
+			return nil, fmt.Errorf("%s does not define %q", name, part)
+		}
+	}
+	sum := sha256.Sum256([]byte(text))
+	return &Prompt{
+		Name: name,
+		ID:   name + "@" + hex.EncodeToString(sum[:4]),
+		Text: text,
+		tmpl: tmpl,
+	}, nil
+}
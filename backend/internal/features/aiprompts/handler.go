@@ -0,0 +1,82 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package aiprompts
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/audit"
+	"github.com/rghsoftware/space-food/internal/config"
+)
+
+// Handler handles AI prompt administration HTTP requests
+type Handler struct {
+	registry *Registry
+	live     *config.Live
+}
+
+// NewHandler creates a new AI prompt handler
+func NewHandler(registry *Registry, live *config.Live) *Handler {
+	return &Handler{
+		registry: registry,
+		live:     live,
+	}
+}
+
+// RegisterRoutes registers AI prompt routes. The group must be restricted
+// to administrators with middleware.RequireAdmin.
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("", h.ListPrompts)
+	router.POST("/reload", h.ReloadPrompts)
+}
+
+// ListPrompts returns the prompts in use with their IDs and templates
+// @Summary List AI prompts
+// @Tags admin
+// @Produce json
+// @Router /admin/ai-prompts [get]
+func (h *Handler) ListPrompts(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"dir":     h.live.Get().AI.PromptsDir,
+		"prompts": h.registry.List(),
+	})
+}
+
+// ReloadPrompts reads the override files again, so edited prompts are
+// used without a restart
+// @Summary Reload AI prompt overrides
+// @Tags admin
+// @Produce json
+// @Router /admin/ai-prompts/reload [post]
+func (h *Handler) ReloadPrompts(c *gin.Context) {
+	h.registry.Reload(h.live.Get().AI.PromptsDir)
+	prompts := h.registry.List()
+
+	ids := map[string]string{}
+	for _, prompt := range prompts {
+		ids[prompt.Name] = prompt.ID
+	}
+	audit.Record(c, audit.Entry{Action: audit.AIPromptsReloaded, Details: map[string]any{"prompts": ids}})
+
+	c.JSON(http.StatusOK, gin.H{
+		"dir":     h.live.Get().AI.PromptsDir,
+		"prompts": prompts,
+	})
+}
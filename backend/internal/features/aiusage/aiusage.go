@@ -0,0 +1,230 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package aiusage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/ai"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/config"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/middleware"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// Features, as AI calls are attributed with ai.WithCaller
+const (
+	FeatureTranslation   = "translation"
+	FeatureSubstitutions = "substitutions"
+)
+
+// userBudgetMessage is shown when a user's own monthly AI budget is used up
+const userBudgetMessage = "You've used this month's AI allowance. It resets on the 1st; everything else keeps working in the meantime."
+
+// BudgetError explains which monthly AI budget has been used up
+type BudgetError struct {
+	Message string
+}
+
+func (e *BudgetError) Error() string {
+	return e.Message
+}
+
+// Tracker records AI usage and enforces the monthly budgets. It observes
+// every provider call through the AI source; AI features name the user and
+// feature with ai.WithCaller and guard their routes with RequireBudget.
+type Tracker struct {
+	db  database.Database
+	cfg atomic.Pointer[config.AIConfig]
+}
+
+// NewTracker creates a new AI usage tracker
+func NewTracker(db database.Database, cfg config.AIConfig) *Tracker {
+	t := &Tracker{db: db}
+	t.cfg.Store(&cfg)
+	return t
+}
+
+// SetConfig applies reloaded prices and budgets
+func (t *Tracker) SetConfig(cfg config.AIConfig) {
+	t.cfg.Store(&cfg)
+}
+
+// Period returns the budget period containing t, as YYYY-MM in UTC
+func Period(t time.Time) string {
+	return t.UTC().Format("2006-01")
+}
+
+// periodStart returns when the budget period containing t began
+func periodStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// periodEnd returns when the budget period containing t resets
+func periodEnd(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// Cost returns the USD cost of a request at the provider's configured prices
+func (t *Tracker) Cost(provider string, inputTokens, outputTokens int64) float64 {
+	pricing := t.cfg.Load().Pricing(provider)
+	return (float64(inputTokens)*pricing.InputPerMTok + float64(outputTokens)*pricing.OutputPerMTok) / 1_000_000
+}
+
+// Observe records one provider call in the invocation log and, when it
+// answered for a user, adds it to their monthly usage. Pass it to
+// ai.Source.Observe.
+func (t *Tracker) Observe(ctx context.Context, invocation ai.Invocation) {
+	userID, feature := ai.Caller(ctx)
+	now := time.Now()
+	cost := t.Cost(invocation.Provider, invocation.InputTokens, invocation.OutputTokens)
+
+	record := &database.AIInvocation{
+		ID:           uuid.New().String(),
+		Feature:      feature,
+		Provider:     invocation.Provider,
+		Model:        invocation.Model,
+		InputTokens:  invocation.InputTokens,
+		OutputTokens: invocation.OutputTokens,
+		LatencyMS:    invocation.Latency.Milliseconds(),
+		Cost:         cost,
+		Outcome:      database.AIInvocationSucceeded,
+		CreatedAt:    now,
+	}
+	if userID != "" {
+		record.UserID = &userID
+	}
+	if invocation.Err != nil {
+		record.Outcome, record.Error = database.AIInvocationFailed, invocation.Err.Error()
+	}
+
+	// The call is over either way, so it is recorded even if the request
+	// that made it has been cancelled
+	ctx = context.WithoutCancel(ctx)
+	log := logger.Ctx(ctx)
+	if err := t.db.RecordAIInvocation(ctx, record); err != nil {
+		log.Warn().Err(err).Msg("Failed to record AI invocation")
+	}
+	if userID == "" || invocation.Err != nil {
+		return
+	}
+	if err := t.db.RecordAIUsage(ctx, &database.AIUsage{
+		UserID:       userID,
+		Provider:     invocation.Provider,
+		Period:       Period(now),
+		Requests:     1,
+		InputTokens:  invocation.InputTokens,
+		OutputTokens: invocation.OutputTokens,
+		Cost:         cost,
+		UpdatedAt:    now,
+	}); err != nil {
+		log.Warn().Err(err).Msg("Failed to record AI usage")
+	}
+}
+
+// Purge removes AI invocations older than ai.invocationretention. Run by
+// the scheduler.
+func (t *Tracker) Purge(ctx context.Context) (string, error) {
+	retention := t.cfg.Load().InvocationRetention
+	if retention <= 0 {
+		return "retention is unlimited", nil
+	}
+	n, err := t.db.PurgeAIInvocations(ctx, time.Now().AddDate(0, 0, -retention))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("removed %d AI invocations", n), nil
+}
+
+// Check returns a *BudgetError when the user or the instance has used up
+// this month's AI budget
+func (t *Tracker) Check(ctx context.Context, userID string) error {
+	budget := t.cfg.Load().Budget
+	period := Period(time.Now())
+
+	if budget.UserMonthlyTokens > 0 || budget.UserMonthlyCost > 0 {
+		usage, err := t.db.ListAIUsage(ctx, userID, period)
+		if err != nil {
+			return err
+		}
+		totals := sum(usage)
+		if budget.UserMonthlyTokens > 0 && totals.InputTokens+totals.OutputTokens >= budget.UserMonthlyTokens ||
+			budget.UserMonthlyCost > 0 && totals.Cost >= budget.UserMonthlyCost {
+			return &BudgetError{Message: userBudgetMessage}
+		}
+	}
+
+	if budget.InstanceMonthlyCost > 0 {
+		usage, err := t.db.GetInstanceAIUsage(ctx, period)
+		if err != nil {
+			return err
+		}
+		if sum(usage).Cost >= budget.InstanceMonthlyCost {
+			return &BudgetError{Message: "This server has reached its AI budget for the month. AI features will be back on the 1st."}
+		}
+	}
+
+	return nil
+}
+
+// RequireBudget rejects requests with 429 once the caller's AI budget is
+// used up. It must run after AuthMiddleware.
+func (t *Tracker) RequireBudget() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := middleware.GetUserFromContext(c)
+		if !ok {
+			apierror.Unauthorized(c, "unauthorized")
+			return
+		}
+
+		err := t.Check(c.Request.Context(), user.ID)
+		if budgetErr, ok := err.(*BudgetError); ok {
+			apierror.Abort(c, apierror.New(http.StatusTooManyRequests, apierror.CodeAIBudgetExceeded, budgetErr.Message).
+				WithDetails(gin.H{"resets_at": periodEnd(time.Now())}))
+			return
+		}
+		if err != nil {
+			apierror.Respond(c, err)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// sum totals usage across providers
+func sum(usage []*database.AIUsage) database.AIUsage {
+	var total database.AIUsage
+	for _, u := range usage {
+		total.Requests += u.Requests
+		total.InputTokens += u.InputTokens
+		total.OutputTokens += u.OutputTokens
+		total.Cost += u.Cost
+	}
+	return total
+}
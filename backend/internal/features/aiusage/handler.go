@@ -0,0 +1,232 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package aiusage
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/api/params"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/middleware"
+)
+
+// Handler handles AI usage HTTP requests
+type Handler struct {
+	tracker *Tracker
+}
+
+// NewHandler creates a new AI usage handler
+func NewHandler(tracker *Tracker) *Handler {
+	return &Handler{
+		tracker: tracker,
+	}
+}
+
+// RegisterRoutes registers AI usage routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("", h.GetUsage)
+	router.GET("/calls", h.ListCalls)
+}
+
+// RegisterAdminRoutes registers the instance-wide AI invocation log
+func (h *Handler) RegisterAdminRoutes(router *gin.RouterGroup) {
+	router.GET("", h.ListInvocations)
+	router.GET("/summary", h.SummarizeInvocations)
+}
+
+// GetUsage returns the authenticated user's AI usage this month
+// @Summary Get AI usage
+// @Tags ai-usage
+// @Produce json
+// @Router /me/ai-usage [get]
+func (h *Handler) GetUsage(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	now := time.Now()
+	usage, err := h.tracker.db.ListAIUsage(c.Request.Context(), user.ID, Period(now))
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	features, err := h.tracker.db.SumAIInvocations(c.Request.Context(), database.AIInvocationFilter{
+		UserID: user.ID,
+		Since:  periodStart(now),
+	})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	totals := sum(usage)
+	budget := h.tracker.cfg.Load().Budget
+
+	// nil limits and remaining values mean unlimited
+	var tokenLimit, tokensRemaining *int64
+	if budget.UserMonthlyTokens > 0 {
+		remaining := max(budget.UserMonthlyTokens-totals.InputTokens-totals.OutputTokens, 0)
+		tokenLimit, tokensRemaining = &budget.UserMonthlyTokens, &remaining
+	}
+	var costLimit, costRemaining *float64
+	if budget.UserMonthlyCost > 0 {
+		remaining := max(budget.UserMonthlyCost-totals.Cost, 0)
+		costLimit, costRemaining = &budget.UserMonthlyCost, &remaining
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"period":    Period(now),
+		"resets_at": periodEnd(now),
+		"providers": usage,
+		"features":  features,
+		"totals": gin.H{
+			"requests":      totals.Requests,
+			"input_tokens":  totals.InputTokens,
+			"output_tokens": totals.OutputTokens,
+			"cost":          totals.Cost,
+		},
+		"limits": gin.H{
+			"monthly_tokens": tokenLimit,
+			"monthly_cost":   costLimit,
+		},
+		"remaining": gin.H{
+			"tokens": tokensRemaining,
+			"cost":   costRemaining,
+		},
+	})
+}
+
+// ListCalls lists the authenticated user's AI calls, newest first
+// @Summary List AI calls
+// @Tags ai-usage
+// @Produce json
+// @Param feature query string false "e.g. translation, substitutions"
+// @Param provider query string false "e.g. ollama, openai"
+// @Param outcome query string false "success or error"
+// @Param since query string false "First day, YYYY-MM-DD (UTC)"
+// @Param until query string false "Last day, YYYY-MM-DD (UTC)"
+// @Param limit query int false "Maximum calls to return (1-200, default 50)"
+// @Param offset query int false "Calls to skip"
+// @Param cursor query string false "Keyset paging: empty for the first page, then next_cursor"
+// @Router /me/ai-usage/calls [get]
+func (h *Handler) ListCalls(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	query := params.Query(c)
+	filter := invocationFilter(query)
+	filter.UserID = user.ID
+	h.listInvocations(c, query, filter)
+}
+
+// ListInvocations lists every AI call on the instance, newest first
+// @Summary List AI invocations
+// @Tags admin
+// @Produce json
+// @Param user_id query string false "Who the call was made for"
+// @Param feature query string false "e.g. translation, substitutions"
+// @Param provider query string false "e.g. ollama, openai"
+// @Param outcome query string false "success or error"
+// @Param since query string false "First day, YYYY-MM-DD (UTC)"
+// @Param until query string false "Last day, YYYY-MM-DD (UTC)"
+// @Param limit query int false "Maximum calls to return (1-200, default 50)"
+// @Param offset query int false "Calls to skip"
+// @Param cursor query string false "Keyset paging: empty for the first page, then next_cursor"
+// @Router /admin/ai-invocations [get]
+func (h *Handler) ListInvocations(c *gin.Context) {
+	query := params.Query(c)
+	filter := invocationFilter(query)
+	filter.UserID = query.String("user_id")
+	h.listInvocations(c, query, filter)
+}
+
+// SummarizeInvocations totals the instance's AI calls by feature and
+// provider
+// @Summary Summarize AI invocations
+// @Tags admin
+// @Produce json
+// @Param user_id query string false "Who the calls were made for"
+// @Param feature query string false "e.g. translation, substitutions"
+// @Param provider query string false "e.g. ollama, openai"
+// @Param outcome query string false "success or error"
+// @Param since query string false "First day, YYYY-MM-DD (UTC)"
+// @Param until query string false "Last day, YYYY-MM-DD (UTC)"
+// @Router /admin/ai-invocations/summary [get]
+func (h *Handler) SummarizeInvocations(c *gin.Context) {
+	query := params.Query(c)
+	filter := invocationFilter(query)
+	filter.UserID = query.String("user_id")
+	if !query.Valid() {
+		return
+	}
+
+	usage, err := h.tracker.db.SumAIInvocations(c.Request.Context(), filter)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, usage)
+}
+
+// listInvocations responds with a page of the calls matching filter
+func (h *Handler) listInvocations(c *gin.Context, query *params.Parser, filter database.AIInvocationFilter) {
+	page := query.Page(50)
+	if !query.Valid() {
+		return
+	}
+	filter.After = page.After
+	filter.Limit = page.Fetch()
+	filter.Offset = page.Offset
+
+	invocations, err := h.tracker.db.ListAIInvocations(c.Request.Context(), filter)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	invocations, next := params.Trim(invocations, page, func(invocation *database.AIInvocation) database.Cursor {
+		return database.Cursor{Time: invocation.CreatedAt, ID: invocation.ID}
+	})
+	params.WriteList(c, invocations, page, next, nil)
+}
+
+// invocationFilter reads the filters shared by the invocation lists
+func invocationFilter(query *params.Parser) database.AIInvocationFilter {
+	filter := database.AIInvocationFilter{
+		Feature:  query.String("feature"),
+		Provider: query.String("provider"),
+		Outcome:  query.String("outcome", database.AIInvocationSucceeded, database.AIInvocationFailed),
+	}
+	if since, ok := query.Date("since", time.UTC); ok {
+		filter.Since = since
+	}
+	if until, ok := query.Date("until", time.UTC); ok {
+		filter.Until = until.AddDate(0, 0, 1)
+	}
+	return filter
+}
@@ -0,0 +1,178 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package apitokens
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/audit"
+	"github.com/rghsoftware/space-food/internal/auth"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/middleware"
+)
+
+// Handler handles personal access token HTTP requests
+type Handler struct {
+	db database.Database
+}
+
+// NewHandler creates a new API token handler
+func NewHandler(db database.Database) *Handler {
+	return &Handler{
+		db: db,
+	}
+}
+
+// RegisterRoutes registers API token routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("", h.ListTokens)
+	router.POST("", h.CreateToken)
+	router.DELETE("/:id", h.DeleteToken)
+}
+
+// ListTokens lists the authenticated user's API tokens
+// @Summary List API tokens
+// @Tags api-tokens
+// @Produce json
+// @Router /me/api-tokens [get]
+func (h *Handler) ListTokens(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	tokens, err := h.db.ListAPITokens(c.Request.Context(), user.ID)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// CreateToken creates an API token. The token itself is only returned here.
+// @Summary Create API token
+// @Tags api-tokens
+// @Accept json
+// @Produce json
+// @Router /me/api-tokens [post]
+func (h *Handler) CreateToken(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req struct {
+		Name          string `json:"name" binding:"required,max=255"`
+		Scope         string `json:"scope" binding:"required"`
+		ExpiresInDays int    `json:"expires_in_days" binding:"min=0"` // 0 never expires
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	if !auth.IsValidScope(req.Scope) {
+		apierror.BadRequest(c, "scope must be one of read, meal-log-write, full")
+		return
+	}
+
+	secret, hash, prefix, err := auth.GenerateAPIToken()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	now := time.Now()
+	token := database.APIToken{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		Name:      req.Name,
+		TokenHash: hash,
+		Prefix:    prefix,
+		Scope:     req.Scope,
+		CreatedAt: now,
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := now.AddDate(0, 0, req.ExpiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+
+	if err := h.db.CreateAPIToken(c.Request.Context(), &token); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	audit.Record(c, audit.Entry{
+		Action:     audit.APITokenCreated,
+		TargetType: "api_token",
+		TargetID:   token.ID,
+		Details:    map[string]any{"name": token.Name, "scope": token.Scope},
+	})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"token":     secret,
+		"api_token": token,
+	})
+}
+
+// DeleteToken revokes an API token
+// @Summary Delete API token
+// @Tags api-tokens
+// @Router /me/api-tokens/{id} [delete]
+func (h *Handler) DeleteToken(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	id := c.Param("id")
+
+	// Verify ownership
+	tokens, err := h.db.ListAPITokens(c.Request.Context(), user.ID)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	owned := false
+	for _, t := range tokens {
+		if t.ID == id {
+			owned = true
+			break
+		}
+	}
+	if !owned {
+		apierror.NotFound(c, "token not found")
+		return
+	}
+
+	if err := h.db.DeleteAPIToken(c.Request.Context(), id); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	audit.Record(c, audit.Entry{Action: audit.APITokenRevoked, TargetType: "api_token", TargetID: id})
+
+	c.Status(http.StatusNoContent)
+}
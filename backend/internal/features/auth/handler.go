@@ -19,21 +19,29 @@
 package authfeature
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/api/params"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/audit"
 	"github.com/rghsoftware/space-food/internal/auth"
+	"github.com/rghsoftware/space-food/internal/middleware"
 )
 
 // Handler handles authentication HTTP requests
 type Handler struct {
 	authProvider auth.AuthProvider
+	postLoginURL string
 }
 
-// NewHandler creates a new authentication handler
-func NewHandler(authProvider auth.AuthProvider) *Handler {
+// NewHandler creates a new authentication handler. postLoginURL is where
+// browser single sign-on logins are sent; when empty the callback returns JSON.
+func NewHandler(authProvider auth.AuthProvider, postLoginURL string) *Handler {
 	return &Handler{
 		authProvider: authProvider,
+		postLoginURL: postLoginURL,
 	}
 }
 
@@ -43,6 +51,20 @@ func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
 	router.POST("/login", h.Login)
 	router.POST("/refresh", h.RefreshToken)
 	router.POST("/logout", h.Logout)
+	router.POST("/2fa/verify", h.VerifyTwoFactor)
+
+	if _, ok := h.authProvider.(auth.SSOProvider); ok {
+		router.GET("/oidc/login", h.OIDCLogin)
+		router.GET("/oidc/callback", h.OIDCCallback)
+	}
+}
+
+// RegisterSessionRoutes registers session management routes, which require
+// an authenticated user
+func (h *Handler) RegisterSessionRoutes(router *gin.RouterGroup) {
+	router.GET("", h.ListSessions)
+	router.DELETE("", h.RevokeAllSessions)
+	router.DELETE("/:id", h.RevokeSession)
 }
 
 // Register handles user registration
@@ -56,15 +78,24 @@ func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
 func (h *Handler) Register(c *gin.Context) {
 	var req auth.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Invalid(c, err)
 		return
 	}
 
 	user, err := h.authProvider.Register(c.Request.Context(), req)
+	if errors.Is(err, auth.ErrPasswordLoginDisabled) || errors.Is(err, auth.ErrRegistrationClosed) || errors.Is(err, auth.ErrInviteRequired) {
+		apierror.Forbidden(c, err.Error())
+		return
+	}
+	if errors.Is(err, auth.ErrSetupRequired) {
+		apierror.Conflict(c, err.Error())
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BadRequest(c, err.Error())
 		return
 	}
+	audit.Record(c, audit.Entry{Action: audit.Registered, UserID: user.ID, TargetType: "user", TargetID: user.ID})
 
 	c.JSON(http.StatusCreated, gin.H{
 		"user": user,
@@ -82,19 +113,41 @@ func (h *Handler) Register(c *gin.Context) {
 func (h *Handler) Login(c *gin.Context) {
 	var req auth.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Invalid(c, err)
 		return
 	}
 
+	req.UserAgent = c.Request.UserAgent()
+	req.IPAddress = c.ClientIP()
+
 	resp, err := h.authProvider.Login(c.Request.Context(), req)
+	if errors.Is(err, auth.ErrPasswordLoginDisabled) {
+		apierror.Forbidden(c, err.Error())
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		audit.Record(c, audit.Entry{Action: audit.LoginFailed, Details: map[string]any{"email": req.Email}})
+		apierror.Unauthorized(c, err.Error())
 		return
 	}
+	recordLogin(c, resp, "password")
 
 	c.JSON(http.StatusOK, resp)
 }
 
+// recordLogin records a completed login in the audit log. A login waiting
+// on a second factor is recorded once that is verified.
+func recordLogin(c *gin.Context, resp *auth.AuthResponse, method string) {
+	if resp.MFARequired || resp.User == nil {
+		return
+	}
+	audit.Record(c, audit.Entry{
+		Action:  audit.Login,
+		UserID:  resp.User.ID,
+		Details: map[string]any{"method": method},
+	})
+}
+
 // RefreshToken handles token refresh
 // @Summary Refresh access token
 // @Tags auth
@@ -109,13 +162,13 @@ func (h *Handler) RefreshToken(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Invalid(c, err)
 		return
 	}
 
 	resp, err := h.authProvider.RefreshToken(c.Request.Context(), req.RefreshToken)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		apierror.Unauthorized(c, err.Error())
 		return
 	}
 
@@ -125,10 +178,106 @@ func (h *Handler) RefreshToken(c *gin.Context) {
 // Logout handles user logout
 // @Summary Logout user
 // @Tags auth
+// @Accept json
+// @Param request body RefreshTokenRequest true "Refresh token of the session to end"
 // @Success 200
 // @Router /auth/logout [post]
 func (h *Handler) Logout(c *gin.Context) {
-	// In JWT stateless authentication, logout is client-side
-	// For enhanced security, implement token blacklist
+	var req struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	if err := h.authProvider.Logout(c.Request.Context(), req.RefreshToken); err != nil {
+		apierror.Unauthorized(c, err.Error())
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "logged out successfully"})
 }
+
+// ListSessions lists the authenticated user's active sessions
+// @Summary List sessions
+// @Tags auth
+// @Produce json
+// @Router /me/sessions [get]
+func (h *Handler) ListSessions(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	sessions, err := h.authProvider.ListSessions(c.Request.Context(), user.ID)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	for _, s := range sessions {
+		s.Current = s.ID == user.SessionID
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSession signs out a single session
+// @Summary Revoke session
+// @Tags auth
+// @Router /me/sessions/{id} [delete]
+func (h *Handler) RevokeSession(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	err := h.authProvider.RevokeSession(c.Request.Context(), user.ID, c.Param("id"))
+	if errors.Is(err, auth.ErrSessionNotFound) {
+		apierror.NotFound(c, "session not found")
+		return
+	}
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	audit.Record(c, audit.Entry{Action: audit.SessionRevoked, TargetType: "session", TargetID: c.Param("id")})
+
+	c.Status(http.StatusNoContent)
+}
+
+// RevokeAllSessions signs out every other session, or every session
+// including the current one with ?include_current=true
+// @Summary Revoke all sessions
+// @Tags auth
+// @Router /me/sessions [delete]
+func (h *Handler) RevokeAllSessions(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	query := params.Query(c)
+	includeCurrent := query.Bool("include_current")
+	if !query.Valid() {
+		return
+	}
+
+	keep := user.SessionID
+	if includeCurrent {
+		keep = ""
+	}
+
+	if err := h.authProvider.RevokeAllSessions(c.Request.Context(), user.ID, keep); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	audit.Record(c, audit.Entry{Action: audit.SessionsRevoked, Details: map[string]any{"include_current": includeCurrent}})
+
+	c.Status(http.StatusNoContent)
+}
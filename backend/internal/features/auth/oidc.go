@@ -0,0 +1,137 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package authfeature
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/auth"
+)
+
+const (
+	oidcCookieName   = "space_food_oidc"
+	oidcCookieMaxAge = 600 // seconds to complete the identity provider login
+)
+
+// OIDCLogin starts a single sign-on login by redirecting to the identity provider
+// @Summary Start single sign-on
+// @Tags auth
+// @Success 302
+// @Router /auth/oidc/login [get]
+func (h *Handler) OIDCLogin(c *gin.Context) {
+	sso := h.authProvider.(auth.SSOProvider)
+
+	state, nonce, verifier := randomToken(), randomToken(), randomToken()
+	challenge := sha256.Sum256([]byte(verifier))
+
+	target, err := sso.AuthCodeURL(c.Request.Context(), state, nonce, base64.RawURLEncoding.EncodeToString(challenge[:]))
+	if err != nil {
+		apierror.Upstream(c, err.Error())
+		return
+	}
+
+	// The login attempt is bound to this browser; the cookie must survive
+	// the top-level redirect back from the identity provider
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcCookieName, state+"."+nonce+"."+verifier, oidcCookieMaxAge, path.Dir(c.FullPath()), "", isSecure(c), true)
+
+	c.Redirect(http.StatusFound, target)
+}
+
+// OIDCCallback completes a single sign-on login
+// @Summary Complete single sign-on
+// @Tags auth
+// @Produce json
+// @Success 200 {object} AuthResponse
+// @Router /auth/oidc/callback [get]
+func (h *Handler) OIDCCallback(c *gin.Context) {
+	sso := h.authProvider.(auth.SSOProvider)
+
+	if errCode := c.Query("error"); errCode != "" {
+		apierror.Abort(c, apierror.New(http.StatusUnauthorized, apierror.CodeUnauthorized, "the identity provider rejected the login").WithDetails(gin.H{
+			"provider_error":       errCode,
+			"provider_description": c.Query("error_description"),
+		}))
+		return
+	}
+
+	cookie, err := c.Cookie(oidcCookieName)
+	if err != nil {
+		apierror.BadRequest(c, "login attempt expired, please try again")
+		return
+	}
+	c.SetCookie(oidcCookieName, "", -1, path.Dir(c.FullPath()), "", isSecure(c), true)
+
+	parts := strings.Split(cookie, ".")
+	if len(parts) != 3 || subtle.ConstantTimeCompare([]byte(parts[0]), []byte(c.Query("state"))) != 1 {
+		apierror.BadRequest(c, "invalid login state")
+		return
+	}
+
+	resp, err := sso.CompleteSSOLogin(c.Request.Context(), auth.SSOLoginRequest{
+		Code:         c.Query("code"),
+		CodeVerifier: parts[2],
+		Nonce:        parts[1],
+		UserAgent:    c.Request.UserAgent(),
+		IPAddress:    c.ClientIP(),
+	})
+	if err != nil {
+		apierror.Unauthorized(c, err.Error())
+		return
+	}
+	recordLogin(c, resp, "oidc")
+
+	if h.postLoginURL == "" {
+		c.JSON(http.StatusOK, resp)
+		return
+	}
+
+	// Tokens go in the fragment so they never reach server logs
+	fragment := url.Values{
+		"access_token":  {resp.AccessToken},
+		"refresh_token": {resp.RefreshToken},
+		"expires_in":    {strconv.Itoa(resp.ExpiresIn)},
+	}
+	c.Redirect(http.StatusFound, h.postLoginURL+"#"+fragment.Encode())
+}
+
+// randomToken returns a URL-safe random string
+func randomToken() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// isSecure reports whether the request reached us over HTTPS, directly or
+// through a proxy
+func isSecure(c *gin.Context) bool {
+	return c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+}
@@ -0,0 +1,217 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package authfeature
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/audit"
+	"github.com/rghsoftware/space-food/internal/auth"
+	"github.com/rghsoftware/space-food/internal/config"
+	"github.com/rghsoftware/space-food/internal/features/email"
+	"github.com/rghsoftware/space-food/internal/middleware"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// PasswordHandler handles forgotten and changed passwords
+type PasswordHandler struct {
+	authProvider auth.AuthProvider
+	notifier     *email.Notifier
+	resetLimiter *middleware.RateLimiter // reset emails per address; nil when unlimited
+}
+
+// NewPasswordHandler creates a new password handler
+func NewPasswordHandler(cfg *config.Config, authProvider auth.AuthProvider, notifier *email.Notifier) *PasswordHandler {
+	h := &PasswordHandler{
+		authProvider: authProvider,
+		notifier:     notifier,
+	}
+	if cfg.RateLimit.Enabled && cfg.RateLimit.ResetPerHour > 0 {
+		h.resetLimiter = middleware.NewRateLimiterPer(cfg.RateLimit.ResetPerHour, time.Hour)
+	}
+	return h
+}
+
+// RegisterRoutes registers the public password reset routes
+func (h *PasswordHandler) RegisterRoutes(router *gin.RouterGroup) {
+	if _, ok := h.authProvider.(auth.PasswordResetProvider); ok {
+		router.POST("/password/forgot", h.ForgotPassword)
+		router.POST("/password/reset", h.ResetPassword)
+	}
+}
+
+// RegisterChangeRoutes registers the password change route, which requires
+// an authenticated user
+func (h *PasswordHandler) RegisterChangeRoutes(router *gin.RouterGroup) {
+	router.PUT("", h.ChangePassword)
+}
+
+// ForgotPassword emails a single-use password reset token. It answers the
+// same whether or not an account uses the address.
+// @Summary Request a password reset email
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Router /auth/password/forgot [post]
+func (h *PasswordHandler) ForgotPassword(c *gin.Context) {
+	var req struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	if !h.notifier.Enabled() {
+		apierror.Abort(c, apierror.New(http.StatusNotImplemented, apierror.CodeNotImplemented,
+			"this instance can't send email; ask an administrator to reset your password"))
+		return
+	}
+	if h.resetLimiter != nil {
+		if ok, wait := h.resetLimiter.Allow("email:" + strings.ToLower(req.Email)); !ok {
+			middleware.TooManyRequests(c, wait, "A reset email was sent to this address recently. Please check your inbox, or wait before asking again.")
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	provider := h.authProvider.(auth.PasswordResetProvider)
+	user, token, err := provider.CreatePasswordReset(ctx, req.Email)
+	if errors.Is(err, auth.ErrPasswordLoginDisabled) {
+		apierror.Forbidden(c, err.Error())
+		return
+	}
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	entry := audit.Entry{Action: audit.PasswordResetRequested, Details: map[string]any{"email": req.Email}}
+	if user != nil {
+		entry.UserID, entry.TargetType, entry.TargetID = user.ID, "user", user.ID
+		if err := h.notifier.SendPasswordReset(ctx, user, token, false); err != nil {
+			// Answered the same as a sent email, so failures don't reveal
+			// which addresses have accounts
+			logger.Ctx(ctx).Error().Err(err).Str("user_id", user.ID).Msg("Failed to send password reset email")
+			entry.Details["error"] = "email not sent"
+		}
+	}
+	audit.Record(c, entry)
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "If an account uses this address, a password reset email is on its way.",
+	})
+}
+
+// ResetPassword sets a new password with a token from a reset email and
+// signs the user out everywhere
+// @Summary Reset a forgotten password
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Router /auth/password/reset [post]
+func (h *PasswordHandler) ResetPassword(c *gin.Context) {
+	var req struct {
+		Token    string `json:"token" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	provider := h.authProvider.(auth.PasswordResetProvider)
+	user, err := provider.CompletePasswordReset(c.Request.Context(), req.Token, req.Password)
+	switch {
+	case errors.Is(err, auth.ErrInvalidResetToken):
+		audit.Record(c, audit.Entry{Action: audit.PasswordResetFailed})
+		apierror.Abort(c, apierror.New(http.StatusGone, apierror.CodeGone, err.Error()))
+		return
+	case errors.Is(err, auth.ErrPasswordLoginDisabled):
+		apierror.Forbidden(c, err.Error())
+		return
+	case errors.Is(err, auth.ErrWeakPassword):
+		apierror.BadRequest(c, auth.ErrWeakPassword.Error())
+		return
+	case err != nil:
+		apierror.Respond(c, err)
+		return
+	}
+	audit.Record(c, audit.Entry{Action: audit.PasswordResetCompleted, UserID: user.ID, TargetType: "user", TargetID: user.ID})
+
+	c.JSON(http.StatusOK, gin.H{"message": "password changed; sign in with the new password"})
+}
+
+// ChangePassword changes the authenticated user's password and signs out
+// their other sessions
+// @Summary Change password
+// @Tags auth
+// @Accept json
+// @Router /me/password [put]
+func (h *PasswordHandler) ChangePassword(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req struct {
+		CurrentPassword string `json:"current_password" binding:"required"`
+		NewPassword     string `json:"new_password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	err := h.authProvider.ChangePassword(ctx, user.ID, req.CurrentPassword, req.NewPassword)
+	switch {
+	case errors.Is(err, auth.ErrPasswordLoginDisabled):
+		apierror.Forbidden(c, err.Error())
+		return
+	case errors.Is(err, auth.ErrWeakPassword):
+		apierror.BadRequest(c, auth.ErrWeakPassword.Error())
+		return
+	case errors.Is(err, auth.ErrWrongPassword):
+		apierror.BadRequest(c, auth.ErrWrongPassword.Error())
+		return
+	case errors.Is(err, auth.ErrPasswordReused):
+		apierror.BadRequest(c, auth.ErrPasswordReused.Error())
+		return
+	case err != nil:
+		apierror.Respond(c, err)
+		return
+	}
+	if err := h.authProvider.RevokeAllSessions(ctx, user.ID, user.SessionID); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	audit.Record(c, audit.Entry{
+		Action:  audit.PasswordChanged,
+		Details: map[string]any{"required": user.MustChangePassword},
+	})
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,232 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package authfeature
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/audit"
+	"github.com/rghsoftware/space-food/internal/auth"
+	"github.com/rghsoftware/space-food/internal/middleware"
+)
+
+// RegisterTwoFactorRoutes registers two-factor management routes, which
+// require an authenticated user
+func (h *Handler) RegisterTwoFactorRoutes(router *gin.RouterGroup) {
+	if _, ok := h.authProvider.(auth.TwoFactorProvider); !ok {
+		return
+	}
+
+	router.GET("", h.GetTwoFactorStatus)
+	router.POST("/totp", h.BeginTOTPEnrollment)
+	router.POST("/totp/confirm", h.ConfirmTOTPEnrollment)
+	router.DELETE("/totp", h.DisableTOTP)
+	router.POST("/recovery-codes", h.RegenerateRecoveryCodes)
+}
+
+// twoFactorCodeRequest carries an authenticator or recovery code
+type twoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// VerifyTwoFactor completes a login that returned MFARequired
+// @Summary Verify two-factor code
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} AuthResponse
+// @Router /auth/2fa/verify [post]
+func (h *Handler) VerifyTwoFactor(c *gin.Context) {
+	tf, ok := h.authProvider.(auth.TwoFactorProvider)
+	if !ok {
+		apierror.NotFound(c, "two-factor authentication is not supported")
+		return
+	}
+
+	var req struct {
+		MFAToken string `json:"mfa_token" binding:"required"`
+		Code     string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	resp, err := tf.VerifyTwoFactor(c.Request.Context(), auth.TwoFactorLoginRequest{
+		MFAToken:  req.MFAToken,
+		Code:      req.Code,
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	})
+	if errors.Is(err, auth.ErrTwoFactorLocked) {
+		apierror.Abort(c, twoFactorError(err))
+		return
+	}
+	if err != nil {
+		apierror.Unauthorized(c, err.Error())
+		return
+	}
+	recordLogin(c, resp, "two_factor")
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetTwoFactorStatus returns the authenticated user's two-factor state
+// @Summary Get two-factor status
+// @Tags auth
+// @Produce json
+// @Router /me/2fa [get]
+func (h *Handler) GetTwoFactorStatus(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	status, err := h.authProvider.(auth.TwoFactorProvider).TwoFactorStatus(c.Request.Context(), user.ID)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// BeginTOTPEnrollment starts authenticator app enrollment
+// @Summary Begin TOTP enrollment
+// @Tags auth
+// @Produce json
+// @Router /me/2fa/totp [post]
+func (h *Handler) BeginTOTPEnrollment(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	enrollment, err := h.authProvider.(auth.TwoFactorProvider).BeginTOTPEnrollment(c.Request.Context(), user.ID)
+	if err != nil {
+		apierror.BadRequest(c, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// ConfirmTOTPEnrollment enables TOTP and returns recovery codes
+// @Summary Confirm TOTP enrollment
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Router /me/2fa/totp/confirm [post]
+func (h *Handler) ConfirmTOTPEnrollment(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req twoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	codes, err := h.authProvider.(auth.TwoFactorProvider).ConfirmTOTPEnrollment(c.Request.Context(), user.ID, req.Code)
+	if err != nil {
+		apierror.Abort(c, twoFactorError(err))
+		return
+	}
+	audit.Record(c, audit.Entry{Action: audit.TwoFactorEnabled})
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// DisableTOTP turns two-factor authentication off
+// @Summary Disable TOTP
+// @Tags auth
+// @Accept json
+// @Router /me/2fa/totp [delete]
+func (h *Handler) DisableTOTP(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req twoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	if err := h.authProvider.(auth.TwoFactorProvider).DisableTOTP(c.Request.Context(), user.ID, req.Code); err != nil {
+		apierror.Abort(c, twoFactorError(err))
+		return
+	}
+	audit.Record(c, audit.Entry{Action: audit.TwoFactorDisabled})
+
+	c.Status(http.StatusNoContent)
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes
+// @Summary Regenerate recovery codes
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Router /me/2fa/recovery-codes [post]
+func (h *Handler) RegenerateRecoveryCodes(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req twoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	codes, err := h.authProvider.(auth.TwoFactorProvider).RegenerateRecoveryCodes(c.Request.Context(), user.ID, req.Code)
+	if err != nil {
+		apierror.Abort(c, twoFactorError(err))
+		return
+	}
+	audit.Record(c, audit.Entry{Action: audit.RecoveryCodesRegenerated})
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// twoFactorError maps provider errors to API errors
+func twoFactorError(err error) *apierror.Error {
+	switch {
+	case errors.Is(err, auth.ErrInvalidTwoFactorCode):
+		return apierror.New(http.StatusUnauthorized, apierror.CodeUnauthorized, err.Error())
+	case errors.Is(err, auth.ErrTwoFactorEnforced):
+		return apierror.New(http.StatusForbidden, apierror.CodeForbidden, err.Error())
+	case errors.Is(err, auth.ErrTwoFactorLocked):
+		return apierror.New(http.StatusTooManyRequests, apierror.CodeRateLimited, err.Error())
+	default:
+		return apierror.New(http.StatusBadRequest, apierror.CodeBadRequest, err.Error())
+	}
+}
@@ -0,0 +1,409 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package batchcook plans freezer batch-cooking sessions: several recipes
+// cooked in one go, with their shopping and prep combined, their steps
+// interleaved so one cooks while another bakes, and the results portioned
+// into the freezer.
+package batchcook
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/leftovers"
+	"github.com/rghsoftware/space-food/internal/features/recipes"
+)
+
+// Step kinds
+const (
+	KindHandsOn    = "hands_on"   // the cook is busy for the whole step
+	KindUnattended = "unattended" // the cook sets it going and is free until it's done
+)
+
+// Default timings, in minutes, for steps that don't say how long they take
+const (
+	defaultStepMinutes = 5  // a step without a duration
+	setUpMinutes       = 5  // getting an unattended step going
+	coolMinutes        = 30 // cooling before the freezer
+	packMinutes        = 10 // portioning and labelling
+)
+
+// minUnattendedMinutes is the shortest wait worth doing something else in
+const minUnattendedMinutes = 10
+
+// prepVerb matches a word for the knife and grater work that can be done
+// for every recipe at once
+var prepVerb = regexp.MustCompile(`^(chop|dice|slice|mince|grate|peel|trim|cube|shred|crush|zest)(?:s|d|ed|ped|ded)?$`)
+
+// unattendedVerb matches steps that mostly wait: in the oven, on a low
+// simmer, in the fridge or in an appliance
+var unattendedVerb = regexp.MustCompile(`(?i)\b(?:bake|roast|simmer|braise|stew|chill|refrigerate|marinate|rest|rise|prove|proof|cool|slow[- ]cook|pressure[- ]cook|cook on (?:low|high))`)
+
+// singleUse is the equipment that can only do one thing at a time. Ovens
+// and hobs hold several dishes, so steps using them can overlap.
+var singleUse = map[string]bool{
+	"slow cooker":     true,
+	"pressure cooker": true,
+	"air fryer":       true,
+	"blender":         true,
+	"food processor":  true,
+	"mixer":           true,
+}
+
+// freezesPoorly warns about ingredients that don't come back well from the
+// freezer, by words in their name
+var freezesPoorly = []struct {
+	words string
+	note  string
+}{
+	{"lettuce", "Salad leaves go limp; add them fresh when serving"},
+	{"cucumber", "Cucumber turns watery; add it fresh when serving"},
+	{"mayonnaise", "Mayonnaise splits when thawed"},
+	{"yogurt", "Yogurt and sour cream can split; stir well when reheating or add them after"},
+	{"sour cream", "Yogurt and sour cream can split; stir well when reheating or add them after"},
+	{"potato", "Potato can turn grainy; undercook it slightly or freeze it mashed"},
+	{"pasta", "Cook pasta just short of done so it doesn't go soft on reheating"},
+	{"noodle", "Cook noodles just short of done so they don't go soft on reheating"},
+	{"boiled egg", "Hard-boiled egg whites turn rubbery in the freezer"},
+}
+
+// Item is one recipe to batch-cook and how many portions to make of it
+type Item struct {
+	RecipeID string `json:"recipe_id" binding:"required"`
+	Portions int    `json:"portions" binding:"omitempty,gt=0,lte=100"` // defaults to the recipe's servings
+}
+
+// Plan is a batch-cooking session
+type Plan struct {
+	Recipes     []PlanRecipe `json:"recipes"`
+	Ingredients []Ingredient `json:"ingredients"` // everything needed, scaled and combined
+	Prep        []PrepTask   `json:"prep"`        // knife work, shared tasks first
+	Timeline    []Block      `json:"timeline"`
+	// TotalMinutes is how long the session takes with steps interleaved,
+	// SeparateMinutes how long cooking each recipe on its own would
+	TotalMinutes    int `json:"total_minutes"`
+	SeparateMinutes int `json:"separate_minutes"`
+	Portions        int `json:"portions"`
+}
+
+// PlanRecipe is a recipe in the session and how it will keep
+type PlanRecipe struct {
+	RecipeID       string   `json:"recipe_id"`
+	Title          string   `json:"title"`
+	Portions       int      `json:"portions"`
+	Scale          float64  `json:"scale"` // of the recipe's quantities
+	FoodType       string   `json:"food_type"`
+	FreezerDays    int      `json:"freezer_days"`
+	Reheat         string   `json:"reheat"`
+	FreezeWarnings []string `json:"freeze_warnings"`
+}
+
+// Ingredient is the total of an ingredient across the session's recipes
+type Ingredient struct {
+	Name     string   `json:"name"`
+	Quantity float64  `json:"quantity,omitempty"`
+	Unit     string   `json:"unit,omitempty"`
+	Recipes  []string `json:"recipes"`
+}
+
+// PrepTask is the prep of one ingredient, such as onions, for every recipe
+// that needs it
+type PrepTask struct {
+	Ingredient string   `json:"ingredient"`
+	Actions    []string `json:"actions"` // e.g. chop, dice
+	Recipes    []string `json:"recipes"`
+	Shared     bool     `json:"shared"` // more than one recipe needs it
+}
+
+// Block is a step placed on the session's timeline, in minutes from the
+// start. The cook is busy for the first HandsOnMinutes of it.
+type Block struct {
+	Start          int    `json:"start"`
+	End            int    `json:"end"`
+	HandsOnMinutes int    `json:"hands_on_minutes"`
+	RecipeID       string `json:"recipe_id"`
+	Recipe         string `json:"recipe"`
+	Step           int    `json:"step"` // counted from 1; 0 for cooling and packing
+	Text           string `json:"text"`
+	Kind           string `json:"kind"`
+	Equipment      string `json:"equipment,omitempty"` // single-use equipment it ties up
+}
+
+// step is a recipe step with its timing worked out
+type step struct {
+	number    int
+	text      string
+	handsOn   int
+	wait      int
+	equipment string
+}
+
+func (s step) minutes() int {
+	return s.handsOn + s.wait
+}
+
+// Scale works out the portions and quantity scale for an item. Recipes
+// without a serving count are taken to make the portions asked for.
+func Scale(recipe *database.Recipe, portions int) (int, float64) {
+	switch {
+	case portions <= 0 && recipe.Servings > 0:
+		return recipe.Servings, 1
+	case portions <= 0:
+		return 4, 1
+	case recipe.Servings <= 0:
+		return portions, 1
+	}
+	return portions, float64(portions) / float64(recipe.Servings)
+}
+
+// NewPlan plans a session cooking the recipes, with the portions of each
+// in portions, keyed by recipe ID
+func NewPlan(recipeList []*database.Recipe, portions map[string]int) *Plan {
+	plan := &Plan{
+		Recipes:     []PlanRecipe{},
+		Ingredients: []Ingredient{},
+		Prep:        []PrepTask{},
+		Timeline:    []Block{},
+	}
+	ingredientIndex := map[string]int{}
+	prepIndex := map[string]int{}
+	steps := make([][]step, len(recipeList))
+
+	for i, recipe := range recipeList {
+		n, scale := Scale(recipe, portions[recipe.ID])
+		foodType := leftovers.LookupFoodType(leftovers.GuessFoodType(recipe.Title, recipe))
+		plan.Recipes = append(plan.Recipes, PlanRecipe{
+			RecipeID:       recipe.ID,
+			Title:          recipe.Title,
+			Portions:       n,
+			Scale:          math.Round(scale*100) / 100,
+			FoodType:       foodType.Name,
+			FreezerDays:    foodType.FreezerDays,
+			Reheat:         foodType.Reheat,
+			FreezeWarnings: freezeWarnings(recipe),
+		})
+		plan.Portions += n
+
+		for _, ing := range recipe.Ingredients {
+			key := strings.Join(words(ing.Name), " ") + "|" + strings.ToLower(strings.TrimSpace(ing.Unit))
+			j, ok := ingredientIndex[key]
+			if !ok {
+				j = len(plan.Ingredients)
+				ingredientIndex[key] = j
+				plan.Ingredients = append(plan.Ingredients, Ingredient{Name: strings.TrimSpace(ing.Name), Unit: ing.Unit, Recipes: []string{}})
+			}
+			plan.Ingredients[j].Quantity = math.Round((plan.Ingredients[j].Quantity+ing.Quantity*scale)*100) / 100
+			plan.Ingredients[j].Recipes = appendUnique(plan.Ingredients[j].Recipes, recipe.Title)
+		}
+
+		steps[i] = recipeSteps(recipe, n)
+		for _, s := range steps[i] {
+			for _, task := range prepTasks(s.text, recipe) {
+				j, ok := prepIndex[task.Ingredient]
+				if !ok {
+					j = len(plan.Prep)
+					prepIndex[task.Ingredient] = j
+					plan.Prep = append(plan.Prep, PrepTask{Ingredient: task.Ingredient, Actions: []string{}, Recipes: []string{}})
+				}
+				plan.Prep[j].Actions = appendUnique(plan.Prep[j].Actions, task.Actions[0])
+				plan.Prep[j].Recipes = appendUnique(plan.Prep[j].Recipes, recipe.Title)
+				plan.Prep[j].Shared = len(plan.Prep[j].Recipes) > 1
+			}
+		}
+	}
+
+	sort.SliceStable(plan.Ingredients, func(i, j int) bool {
+		return strings.ToLower(plan.Ingredients[i].Name) < strings.ToLower(plan.Ingredients[j].Name)
+	})
+	sort.SliceStable(plan.Prep, func(i, j int) bool {
+		return plan.Prep[i].Shared && !plan.Prep[j].Shared
+	})
+	plan.schedule(recipeList, steps)
+	return plan
+}
+
+// recipeSteps times a recipe's steps and adds cooling and packing the
+// portions at the end
+func recipeSteps(recipe *database.Recipe, portions int) []step {
+	steps := []step{}
+	for _, cookStep := range recipes.CookSteps(recipe.Instructions) {
+		s := step{number: cookStep.Number, text: cookStep.Text}
+		timed := 0
+		for _, timer := range cookStep.Timers {
+			timed += (timer.Seconds + 59) / 60
+		}
+		switch {
+		case timed >= minUnattendedMinutes && unattendedVerb.MatchString(cookStep.Text):
+			s.handsOn, s.wait = setUpMinutes, max(timed-setUpMinutes, 0)
+		case timed > 0:
+			s.handsOn = timed
+		default:
+			s.handsOn = defaultStepMinutes
+		}
+		for _, kit := range recipes.DetectEquipment(cookStep.Text) {
+			if singleUse[kit] {
+				s.equipment = kit
+				break
+			}
+		}
+		steps = append(steps, s)
+	}
+	portionWord := "portions"
+	if portions == 1 {
+		portionWord = "portion"
+	}
+	return append(steps,
+		step{text: "Cool quickly in shallow containers", wait: coolMinutes},
+		step{text: "Split into " + strconv.Itoa(portions) + " " + portionWord + ", label and freeze", handsOn: packMinutes},
+	)
+}
+
+// schedule interleaves the recipes' steps. Each recipe's steps stay in
+// order; whenever the cook is free, the step that can start soonest goes
+// next, favouring the recipe with the most time left so long bakes start
+// early. Single-use equipment runs one step at a time.
+func (p *Plan) schedule(recipeList []*database.Recipe, steps [][]step) {
+	next := make([]int, len(recipeList))
+	ready := make([]int, len(recipeList))
+	equipmentFree := map[string]int{}
+	cookFree := 0
+
+	remaining := func(i int) int {
+		total := 0
+		for _, s := range steps[i][next[i]:] {
+			total += s.minutes()
+		}
+		return total
+	}
+
+	for {
+		best, bestStart := -1, 0
+		for i := range recipeList {
+			if next[i] >= len(steps[i]) {
+				continue
+			}
+			s := steps[i][next[i]]
+			start := max(cookFree, ready[i])
+			if s.equipment != "" {
+				start = max(start, equipmentFree[s.equipment])
+			}
+			if best < 0 || start < bestStart || start == bestStart && remaining(i) > remaining(best) {
+				best, bestStart = i, start
+			}
+		}
+		if best < 0 {
+			break
+		}
+
+		s := steps[best][next[best]]
+		next[best]++
+		block := Block{
+			Start:          bestStart,
+			End:            bestStart + s.minutes(),
+			HandsOnMinutes: s.handsOn,
+			RecipeID:       recipeList[best].ID,
+			Recipe:         recipeList[best].Title,
+			Step:           s.number,
+			Text:           s.text,
+			Kind:           KindHandsOn,
+			Equipment:      s.equipment,
+		}
+		if s.wait > 0 {
+			block.Kind = KindUnattended
+		}
+		p.Timeline = append(p.Timeline, block)
+
+		cookFree = bestStart + s.handsOn
+		ready[best] = block.End
+		if s.equipment != "" {
+			equipmentFree[s.equipment] = block.End
+		}
+		p.TotalMinutes = max(p.TotalMinutes, block.End)
+		p.SeparateMinutes += s.minutes()
+	}
+}
+
+// prepTasks finds the prep a step does to the recipe's ingredients, named
+// by their last word: "Chop the onions and mince the garlic" gives chop
+// onion and mince garlic. Each ingredient goes with the prep word before
+// it.
+func prepTasks(text string, recipe *database.Recipe) []PrepTask {
+	nouns := map[string]bool{}
+	for _, ing := range recipe.Ingredients {
+		if nameWords := words(ing.Name); len(nameWords) > 0 {
+			nouns[nameWords[len(nameWords)-1]] = true
+		}
+	}
+
+	tasks := []PrepTask{}
+	seen := map[string]bool{}
+	action := ""
+	for _, w := range words(text) {
+		if m := prepVerb.FindStringSubmatch(w); m != nil {
+			action = m[1]
+			continue
+		}
+		if action != "" && nouns[w] && !seen[w] {
+			seen[w] = true
+			tasks = append(tasks, PrepTask{Ingredient: w, Actions: []string{action}})
+		}
+	}
+	return tasks
+}
+
+// freezeWarnings lists what in a recipe won't freeze well
+func freezeWarnings(recipe *database.Recipe) []string {
+	warnings := []string{}
+	for _, ing := range recipe.Ingredients {
+		name := " " + strings.Join(words(ing.Name), " ") + " "
+		for _, poor := range freezesPoorly {
+			if strings.Contains(name, " "+poor.words+" ") {
+				warnings = appendUnique(warnings, poor.note)
+			}
+		}
+	}
+	return warnings
+}
+
+// words splits text into lowercase words with a trailing plural "s"
+// removed, so "onions" matches "onion"
+func words(s string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+	for i, f := range fields {
+		if len(f) > 3 && strings.HasSuffix(f, "s") && !strings.HasSuffix(f, "ss") {
+			fields[i] = strings.TrimSuffix(f, "s")
+		}
+	}
+	return fields
+}
+
+func appendUnique(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
@@ -0,0 +1,200 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package batchcook
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/authz"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/leftovers"
+	"github.com/rghsoftware/space-food/internal/features/mealtime"
+	"github.com/rghsoftware/space-food/internal/middleware"
+)
+
+// maxContainers bounds how many containers one freeze request fills
+const maxContainers = 100
+
+// Handler handles batch-cooking requests
+type Handler struct {
+	db database.Database
+}
+
+// NewHandler creates a new batch-cooking handler
+func NewHandler(db database.Database) *Handler {
+	return &Handler{db: db}
+}
+
+// RegisterRoutes registers batch-cooking routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.POST("/plan", h.PlanSession)
+	router.POST("/freeze", h.Freeze)
+}
+
+// PlanSession plans a batch-cooking session for several recipes
+// @Summary Plan a batch-cooking session
+// @Tags batch-cook
+// @Accept json
+// @Produce json
+// @Success 200 {object} Plan
+// @Router /batch-cook/plan [post]
+func (h *Handler) PlanSession(c *gin.Context) {
+	if _, ok := middleware.GetUserFromContext(c); !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req struct {
+		Recipes []Item `json:"recipes" binding:"required,min=1,max=10,dive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	recipeList := make([]*database.Recipe, 0, len(req.Recipes))
+	portions := map[string]int{}
+	for _, item := range req.Recipes {
+		if _, dup := portions[item.RecipeID]; dup {
+			apierror.BadRequest(c, "each recipe can only be listed once")
+			return
+		}
+		recipe, err := h.db.GetRecipeByID(c.Request.Context(), item.RecipeID)
+		if err != nil {
+			apierror.Lookup(c, err, "recipe not found")
+			return
+		}
+		recipeList = append(recipeList, recipe)
+		portions[recipe.ID] = item.Portions
+	}
+
+	c.JSON(http.StatusOK, NewPlan(recipeList, portions))
+}
+
+// freezeItem is a recipe's output going into the freezer
+type freezeItem struct {
+	RecipeID   string  `json:"recipe_id" binding:"required"`
+	Portions   float64 `json:"portions" binding:"required,gt=0"`
+	Containers int     `json:"containers" binding:"omitempty,gt=0"` // the portions are split evenly between them
+	Container  string  `json:"container"`                           // e.g. "1 L tub"
+	Reheat     string  `json:"reheat"`                              // defaults to the food type's instructions
+}
+
+// Freeze records the results of a session as frozen leftovers, one per
+// container so each can be thawed on its own, and returns their labels
+// @Summary Freeze batch-cooked portions
+// @Tags batch-cook
+// @Accept json
+// @Produce json
+// @Router /batch-cook/freeze [post]
+func (h *Handler) Freeze(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req struct {
+		Items    []freezeItem `json:"items" binding:"required,min=1,max=10,dive"`
+		StoredAt *time.Time   `json:"stored_at"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	now := time.Now()
+	storedAt := now
+	if req.StoredAt != nil {
+		storedAt = *req.StoredAt
+	}
+
+	containers := 0
+	recipeList := make([]*database.Recipe, len(req.Items))
+	for i, item := range req.Items {
+		recipe, err := h.db.GetRecipeByID(ctx, item.RecipeID)
+		if err != nil || !authz.Owns(user.ID, recipe.UserID) {
+			apierror.BadRequest(c, "recipe not found")
+			return
+		}
+		recipeList[i] = recipe
+		containers += max(item.Containers, 1)
+	}
+	if containers > maxContainers {
+		apierror.BadRequest(c, "too many containers in one request")
+		return
+	}
+
+	frozen := []*database.Leftover{}
+	sources := []*database.Recipe{}
+	for i, item := range req.Items {
+		recipe := recipeList[i]
+		n := max(item.Containers, 1)
+		for range n {
+			leftover := &database.Leftover{
+				ID:        uuid.New().String(),
+				UserID:    user.ID,
+				RecipeID:  &recipe.ID,
+				Name:      recipe.Title,
+				FoodType:  leftovers.GuessFoodType(recipe.Title, recipe),
+				Portions:  math.Round(item.Portions/float64(n)*100) / 100,
+				Container: strings.TrimSpace(item.Container),
+				StoredAt:  storedAt.UTC(),
+				Reheat:    strings.TrimSpace(item.Reheat),
+				CreatedAt: now,
+				UpdatedAt: now,
+			}
+			leftovers.Freeze(leftover, storedAt)
+			frozen = append(frozen, leftover)
+			sources = append(sources, recipe)
+		}
+	}
+
+	err := h.db.WithTx(ctx, func(ctx context.Context) error {
+		for _, leftover := range frozen {
+			if err := h.db.CreateLeftover(ctx, leftover); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	loc := mealtime.Location(mealtime.LoadPreferences(c, h.db, user.ID))
+	labels := make([]leftovers.Label, 0, len(frozen))
+	for i, leftover := range frozen {
+		labels = append(labels, leftovers.NewLabel(leftover, sources[i], loc))
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"leftovers": frozen,
+		"labels":    labels,
+	})
+}
@@ -0,0 +1,321 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package budget
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Reasons an ingredient has no cost estimate
+const (
+	UnpricedNoPrice      = "no_price"      // nothing like it in the price memory
+	UnpricedNoQuantity   = "no_quantity"   // e.g. "salt, to taste"
+	UnpricedUnitMismatch = "unit_mismatch" // priced by a unit it can't be converted from
+)
+
+// unit is a measuring unit as an amount of its dimension's base unit:
+// grams, millilitres or items
+type unit struct {
+	dimension string
+	factor    float64
+}
+
+// units are the measures prices and ingredients are converted between,
+// keyed by their singular lowercase spelling
+var units = map[string]unit{
+	"":            {"count", 1},
+	"piece":       {"count", 1},
+	"each":        {"count", 1},
+	"whole":       {"count", 1},
+	"g":           {"mass", 1},
+	"gram":        {"mass", 1},
+	"kg":          {"mass", 1000},
+	"kilogram":    {"mass", 1000},
+	"oz":          {"mass", 28.3495},
+	"ounce":       {"mass", 28.3495},
+	"lb":          {"mass", 453.592},
+	"pound":       {"mass", 453.592},
+	"ml":          {"volume", 1},
+	"milliliter":  {"volume", 1},
+	"millilitre":  {"volume", 1},
+	"l":           {"volume", 1000},
+	"liter":       {"volume", 1000},
+	"litre":       {"volume", 1000},
+	"tsp":         {"volume", 4.92892},
+	"teaspoon":    {"volume", 4.92892},
+	"tbsp":        {"volume", 14.7868},
+	"tablespoon":  {"volume", 14.7868},
+	"cup":         {"volume", 236.588},
+	"fl oz":       {"volume", 29.5735},
+	"fluid ounce": {"volume", 29.5735},
+}
+
+// singular drops a plural ending from a word: "tomatoes" to "tomato",
+// "berries" to "berry", "eggs" to "egg"
+func singular(word string) string {
+	switch {
+	case len(word) > 4 && strings.HasSuffix(word, "ies"):
+		return word[:len(word)-3] + "y"
+	case len(word) > 4 && strings.HasSuffix(word, "oes"):
+		return word[:len(word)-2]
+	case len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss"):
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// NormalizeName is how item names are stored and compared: lowercase,
+// single spaces and the last word singular
+func NormalizeName(name string) string {
+	words := strings.Fields(strings.ToLower(name))
+	if len(words) == 0 {
+		return ""
+	}
+	words[len(words)-1] = singular(words[len(words)-1])
+	return strings.Join(words, " ")
+}
+
+// normalizeUnit spells a unit the way units are keyed
+func normalizeUnit(u string) string {
+	u = strings.ToLower(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(u), ".")))
+	if u == "lbs" {
+		return "lb"
+	}
+	if len(u) > 2 {
+		u = singular(u)
+	}
+	return u
+}
+
+// convert turns an amount in one unit into another, reporting false when
+// they measure different things. Units outside the table convert only to
+// themselves, e.g. "can" to "can".
+func convert(amount float64, from, to string) (float64, bool) {
+	from, to = normalizeUnit(from), normalizeUnit(to)
+	if from == to {
+		return amount, true
+	}
+	f, ok := units[from]
+	if !ok {
+		return 0, false
+	}
+	t, ok := units[to]
+	if !ok || f.dimension != t.dimension {
+		return 0, false
+	}
+	return amount * f.factor / t.factor, true
+}
+
+// roundCents rounds an amount of money to two decimal places
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// Memory is a user's price memory: the latest price of each item at each
+// store
+type Memory []*database.GroceryPrice
+
+// LoadMemory reads a user's price memory
+func LoadMemory(ctx context.Context, db database.Database, userID string) (Memory, error) {
+	prices, err := db.ListGroceryPrices(ctx, database.GroceryPriceFilter{UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+	return latest(prices), nil
+}
+
+// latest keeps the first, most recent, price of each item at each store
+func latest(prices []*database.GroceryPrice) Memory {
+	seen := map[string]bool{}
+	memory := Memory{}
+	for _, price := range prices {
+		key := price.Store + "\x00" + price.Name
+		if !seen[key] {
+			seen[key] = true
+			memory = append(memory, price)
+		}
+	}
+	return memory
+}
+
+// Find returns the remembered price of an item, preferring store when one
+// is given and the most recent price otherwise. An item named exactly
+// matches first; failing that, the price whose name's words all appear in
+// the item's name with the most words, so "basil" prices "fresh basil
+// leaves".
+func (m Memory) Find(name, store string) *database.GroceryPrice {
+	name = NormalizeName(name)
+	if name == "" {
+		return nil
+	}
+	words := map[string]bool{}
+	for _, word := range strings.Fields(name) {
+		words[word] = true
+	}
+
+	var best *database.GroceryPrice
+	bestRank := 0
+	for _, price := range m {
+		rank := 0
+		if price.Name == name {
+			rank = 1000
+		} else {
+			priceWords := strings.Fields(price.Name)
+			for _, word := range priceWords {
+				if !words[word] && !words[singular(word)] {
+					priceWords = nil
+					break
+				}
+			}
+			rank = len(priceWords) * 10
+		}
+		if rank == 0 {
+			continue
+		}
+		if store != "" && strings.EqualFold(price.Store, store) {
+			rank += 5
+		}
+		// Ties keep the earlier, more recent, price
+		if rank > bestRank {
+			best, bestRank = price, rank
+		}
+	}
+	return best
+}
+
+// Estimate prices an amount of an item from the memory. It returns the
+// price used, or the reason there is no estimate.
+func (m Memory) Estimate(name string, quantity float64, unitName, store string) (float64, *database.GroceryPrice, string) {
+	price := m.Find(name, store)
+	if price == nil {
+		return 0, nil, UnpricedNoPrice
+	}
+	if quantity <= 0 {
+		return 0, price, UnpricedNoQuantity
+	}
+	amount, ok := convert(quantity, unitName, price.Unit)
+	if !ok {
+		return 0, price, UnpricedUnitMismatch
+	}
+	perUnit := price.Price
+	if price.Quantity > 0 {
+		perUnit = price.Price / price.Quantity
+	}
+	return roundCents(perUnit * amount), price, ""
+}
+
+// IngredientCost is the estimated cost of one ingredient of a recipe
+type IngredientCost struct {
+	Name     string   `json:"name"`
+	Cost     *float64 `json:"cost"` // nil when it can't be estimated
+	Store    string   `json:"store,omitempty"`
+	PriceID  string   `json:"price_id,omitempty"`
+	Unpriced string   `json:"unpriced,omitempty"` // why there is no cost
+}
+
+// RecipeCost estimates what a recipe costs to make from remembered prices
+type RecipeCost struct {
+	RecipeID    string           `json:"recipe_id"`
+	Servings    int              `json:"servings"`
+	Total       float64          `json:"total"` // of the ingredients with a cost
+	PerServing  *float64         `json:"per_serving"`
+	Currency    string           `json:"currency,omitempty"`
+	Priced      int              `json:"priced"`   // ingredients with a cost
+	Unpriced    int              `json:"unpriced"` // ingredients without, so the total is a lower bound
+	Ingredients []IngredientCost `json:"ingredients"`
+}
+
+// CostRecipe estimates the cost of a recipe scaled to servings; servings of
+// 0 keeps the recipe's own. Optional ingredients are left out.
+func (m Memory) CostRecipe(recipe *database.Recipe, servings int, store string) *RecipeCost {
+	scale := 1.0
+	if servings > 0 && recipe.Servings > 0 {
+		scale = float64(servings) / float64(recipe.Servings)
+	} else {
+		servings = recipe.Servings
+	}
+	cost := &RecipeCost{
+		RecipeID:    recipe.ID,
+		Servings:    servings,
+		Ingredients: []IngredientCost{},
+	}
+	for _, ingredient := range recipe.Ingredients {
+		if ingredient.Optional {
+			continue
+		}
+		line := IngredientCost{Name: ingredient.Name}
+		amount, price, unpriced := m.Estimate(ingredient.Name, ingredient.Quantity*scale, ingredient.Unit, store)
+		if price != nil {
+			line.Store, line.PriceID = price.Store, price.ID
+		}
+		if unpriced != "" {
+			line.Unpriced = unpriced
+			cost.Unpriced++
+		} else {
+			line.Cost = &amount
+			cost.Total += amount
+			cost.Priced++
+		}
+		cost.Ingredients = append(cost.Ingredients, line)
+	}
+	cost.Total = roundCents(cost.Total)
+	if servings > 0 && cost.Priced > 0 {
+		perServing := roundCents(cost.Total / float64(servings))
+		cost.PerServing = &perServing
+	}
+	return cost
+}
+
+// RecordPurchase adds a ticked-off shopping list item with a price to the
+// price memory and the month's spending
+func RecordPurchase(ctx context.Context, db database.Database, item *database.ShoppingListItem, now time.Time) error {
+	if item.Price == nil {
+		return nil
+	}
+	quantity := item.Quantity
+	if quantity <= 0 {
+		quantity = 1
+	}
+	itemID := item.ID
+	return db.CreateGroceryPrice(ctx, &database.GroceryPrice{
+		ID:             uuid.New().String(),
+		UserID:         item.UserID,
+		Store:          strings.TrimSpace(item.Store),
+		Name:           NormalizeName(item.Name),
+		Quantity:       quantity,
+		Unit:           strings.TrimSpace(item.Unit),
+		Price:          *item.Price,
+		Purchased:      true,
+		ShoppingItemID: &itemID,
+		RecordedAt:     now,
+		CreatedAt:      now,
+	})
+}
+
+// UndoPurchase removes the purchase recorded for a shopping list item that
+// was ticked off by mistake
+func UndoPurchase(ctx context.Context, db database.Database, item *database.ShoppingListItem) error {
+	return db.DeleteShoppingItemPurchase(ctx, item.ID)
+}
@@ -0,0 +1,637 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package budget
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/api/params"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/authz"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/mealtime"
+	"github.com/rghsoftware/space-food/internal/middleware"
+)
+
+// maxMonths is the most months a spending summary covers
+const maxMonths = 24
+
+// Handler handles grocery budget HTTP requests
+type Handler struct {
+	db database.Database
+}
+
+// NewHandler creates a new budget handler
+func NewHandler(db database.Database) *Handler {
+	return &Handler{
+		db: db,
+	}
+}
+
+// RegisterRoutes registers the price memory, monthly budget and spending
+// routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("", h.GetBudget)
+	router.PUT("", h.SetBudget)
+	router.DELETE("", h.DeleteBudget)
+	router.GET("/spending", h.GetSpending)
+	router.GET("/prices", h.ListPrices)
+	router.POST("/prices", h.RecordPrice)
+	router.DELETE("/prices/:id", h.DeletePrice)
+}
+
+// RegisterRecipeRoutes registers recipe cost estimates
+func (h *Handler) RegisterRecipeRoutes(router *gin.RouterGroup) {
+	router.GET("/:id/cost", h.GetRecipeCost)
+}
+
+// RegisterMealPlanRoutes registers meal plan cost estimates
+func (h *Handler) RegisterMealPlanRoutes(router *gin.RouterGroup) {
+	router.GET("/:id/cost", h.GetMealPlanCost)
+}
+
+// RegisterShoppingListRoutes registers the shopping list estimate
+func (h *Handler) RegisterShoppingListRoutes(router *gin.RouterGroup) {
+	router.GET("/estimate", h.GetShoppingListEstimate)
+}
+
+// currency returns the currency label of the user's budget, if any
+func (h *Handler) currency(c *gin.Context, userID string) string {
+	budget, err := h.db.GetGroceryBudget(c.Request.Context(), userID)
+	if err != nil {
+		return ""
+	}
+	return budget.Currency
+}
+
+// GetBudget returns the authenticated user's monthly grocery budget
+// @Summary Get grocery budget
+// @Tags budget
+// @Produce json
+// @Success 200 {object} database.GroceryBudget
+// @Router /budget [get]
+func (h *Handler) GetBudget(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	budget, err := h.db.GetGroceryBudget(c.Request.Context(), user.ID)
+	if err != nil {
+		apierror.Lookup(c, err, "no grocery budget set")
+		return
+	}
+	c.JSON(http.StatusOK, budget)
+}
+
+// SetBudget sets the authenticated user's monthly grocery budget
+// @Summary Set grocery budget
+// @Tags budget
+// @Accept json
+// @Produce json
+// @Success 200 {object} database.GroceryBudget
+// @Router /budget [put]
+func (h *Handler) SetBudget(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req struct {
+		MonthlyLimit float64 `json:"monthly_limit" binding:"required,gt=0,lte=1000000"`
+		Currency     string  `json:"currency" binding:"max=8"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	budget := &database.GroceryBudget{
+		UserID:       user.ID,
+		MonthlyLimit: roundCents(req.MonthlyLimit),
+		Currency:     strings.ToUpper(strings.TrimSpace(req.Currency)),
+		UpdatedAt:    time.Now(),
+	}
+	if err := h.db.UpsertGroceryBudget(c.Request.Context(), budget); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, budget)
+}
+
+// DeleteBudget removes the authenticated user's monthly grocery budget
+// @Summary Delete grocery budget
+// @Tags budget
+// @Success 204
+// @Router /budget [delete]
+func (h *Handler) DeleteBudget(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	if err := h.db.DeleteGroceryBudget(c.Request.Context(), user.ID); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// StoreSpending is what was spent at one store in a month
+type StoreSpending struct {
+	Store string  `json:"store"` // empty for purchases without a store
+	Total float64 `json:"total"`
+}
+
+// MonthSpending is what was spent on groceries in a month
+type MonthSpending struct {
+	Month     string          `json:"month"` // YYYY-MM
+	Total     float64         `json:"total"`
+	Purchases int             `json:"purchases"`
+	Stores    []StoreSpending `json:"stores"`              // largest first
+	Remaining *float64        `json:"remaining,omitempty"` // of the budget; negative when over
+}
+
+// spendingSummary is the spending of recent months, oldest first
+type spendingSummary struct {
+	Budget *database.GroceryBudget `json:"budget"`
+	Months []MonthSpending         `json:"months"`
+}
+
+// GetSpending summarises grocery spending by month, in the user's meal
+// window timezone, with what is left of the monthly budget
+// @Summary Monthly grocery spending
+// @Tags budget
+// @Produce json
+// @Param months query int false "Months to cover, including this one (default 6, max 24)"
+// @Success 200 {object} spendingSummary
+// @Router /budget/spending [get]
+func (h *Handler) GetSpending(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	query := params.Query(c)
+	months := query.Int("months", 6, 1, maxMonths)
+	if !query.Valid() {
+		return
+	}
+
+	ctx := c.Request.Context()
+	budget, err := h.db.GetGroceryBudget(ctx, user.ID)
+	if err != nil && !apierror.IsNotFound(err) {
+		apierror.Respond(c, err)
+		return
+	}
+
+	loc := mealtime.Location(mealtime.LoadPreferences(c, h.db, user.ID))
+	now := time.Now().In(loc)
+	since := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc).AddDate(0, -(months - 1), 0)
+	purchases, err := h.db.ListGroceryPrices(ctx, database.GroceryPriceFilter{
+		UserID:        user.ID,
+		PurchasedOnly: true,
+		Since:         since.UTC(),
+	})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	summary := spendingSummary{Budget: budget, Months: Summarize(purchases, since, months, loc, budget)}
+	c.JSON(http.StatusOK, summary)
+}
+
+// Summarize totals purchases by month and store for the months from since
+func Summarize(purchases []*database.GroceryPrice, since time.Time, months int, loc *time.Location, budget *database.GroceryBudget) []MonthSpending {
+	summary := make([]MonthSpending, months)
+	index := map[string]int{}
+	stores := make([]map[string]float64, months)
+	for i := range summary {
+		month := since.AddDate(0, i, 0).Format("2006-01")
+		summary[i] = MonthSpending{Month: month, Stores: []StoreSpending{}}
+		index[month] = i
+		stores[i] = map[string]float64{}
+	}
+	for _, purchase := range purchases {
+		i, ok := index[purchase.RecordedAt.In(loc).Format("2006-01")]
+		if !ok {
+			continue
+		}
+		summary[i].Total += purchase.Price
+		summary[i].Purchases++
+		stores[i][purchase.Store] += purchase.Price
+	}
+	for i := range summary {
+		summary[i].Total = roundCents(summary[i].Total)
+		for store, total := range stores[i] {
+			summary[i].Stores = append(summary[i].Stores, StoreSpending{Store: store, Total: roundCents(total)})
+		}
+		sort.Slice(summary[i].Stores, func(a, b int) bool {
+			if summary[i].Stores[a].Total != summary[i].Stores[b].Total {
+				return summary[i].Stores[a].Total > summary[i].Stores[b].Total
+			}
+			return summary[i].Stores[a].Store < summary[i].Stores[b].Store
+		})
+		if budget != nil {
+			remaining := roundCents(budget.MonthlyLimit - summary[i].Total)
+			summary[i].Remaining = &remaining
+		}
+	}
+	return summary
+}
+
+// ListPrices returns the authenticated user's price memory: the latest
+// price of each item at each store, or every recorded price with
+// history=true
+// @Summary List grocery prices
+// @Tags budget
+// @Produce json
+// @Param store query string false "Only prices at this store"
+// @Param q query string false "Only items whose name contains this"
+// @Param history query bool false "Every recorded price, not only the latest"
+// @Success 200 {array} database.GroceryPrice
+// @Router /budget/prices [get]
+func (h *Handler) ListPrices(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	query := params.Query(c)
+	store := query.String("store")
+	search := strings.ToLower(query.String("q"))
+	history := query.Bool("history")
+	if !query.Valid() {
+		return
+	}
+
+	prices, err := h.db.ListGroceryPrices(c.Request.Context(), database.GroceryPriceFilter{
+		UserID: user.ID,
+		Store:  store,
+	})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	if !history {
+		prices = latest(prices)
+	}
+	matching := []*database.GroceryPrice{}
+	for _, price := range prices {
+		if search == "" || strings.Contains(price.Name, search) {
+			matching = append(matching, price)
+		}
+	}
+	c.JSON(http.StatusOK, matching)
+}
+
+// RecordPrice remembers a price seen or paid for an item. Purchases count
+// towards the month's spending.
+// @Summary Record grocery price
+// @Tags budget
+// @Accept json
+// @Produce json
+// @Success 201 {object} database.GroceryPrice
+// @Router /budget/prices [post]
+func (h *Handler) RecordPrice(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req struct {
+		Name       string     `json:"name" binding:"required,max=200"`
+		Store      string     `json:"store" binding:"max=100"`
+		Quantity   float64    `json:"quantity" binding:"omitempty,gt=0,lte=100000"`
+		Unit       string     `json:"unit" binding:"max=30"`
+		Price      float64    `json:"price" binding:"gte=0,lte=100000"`
+		Purchased  bool       `json:"purchased"`
+		RecordedAt *time.Time `json:"recorded_at"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+	name := NormalizeName(req.Name)
+	if name == "" {
+		apierror.BadRequest(c, "name is required")
+		return
+	}
+
+	now := time.Now()
+	price := &database.GroceryPrice{
+		ID:         uuid.New().String(),
+		UserID:     user.ID,
+		Store:      strings.TrimSpace(req.Store),
+		Name:       name,
+		Quantity:   req.Quantity,
+		Unit:       strings.TrimSpace(req.Unit),
+		Price:      roundCents(req.Price),
+		Purchased:  req.Purchased,
+		RecordedAt: now,
+		CreatedAt:  now,
+	}
+	if price.Quantity == 0 {
+		price.Quantity = 1
+	}
+	if req.RecordedAt != nil {
+		if req.RecordedAt.After(now) {
+			apierror.BadRequest(c, "recorded_at can't be in the future")
+			return
+		}
+		price.RecordedAt = *req.RecordedAt
+	}
+	if err := h.db.CreateGroceryPrice(c.Request.Context(), price); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, price)
+}
+
+// DeletePrice forgets a recorded price
+// @Summary Delete grocery price
+// @Tags budget
+// @Param id path string true "Price ID"
+// @Success 204
+// @Router /budget/prices/{id} [delete]
+func (h *Handler) DeletePrice(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	ctx := c.Request.Context()
+	price, err := h.db.GetGroceryPriceByID(ctx, c.Param("id"))
+	if err != nil {
+		apierror.Lookup(c, err, "price not found")
+		return
+	}
+	if !authz.Owns(user.ID, price.UserID) {
+		apierror.Forbidden(c, "forbidden")
+		return
+	}
+	if err := h.db.DeleteGroceryPrice(ctx, price.ID); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetRecipeCost estimates what a recipe costs to make from the viewer's
+// remembered prices
+// @Summary Recipe cost estimate
+// @Tags budget
+// @Produce json
+// @Param id path string true "Recipe ID"
+// @Param servings query int false "Servings to scale to"
+// @Param store query string false "Prefer prices at this store"
+// @Success 200 {object} RecipeCost
+// @Router /recipes/{id}/cost [get]
+func (h *Handler) GetRecipeCost(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	query := params.Query(c)
+	servings := query.Int("servings", 0, 1, 100)
+	store := query.String("store")
+	if !query.Valid() {
+		return
+	}
+
+	ctx := c.Request.Context()
+	recipe, err := h.db.GetRecipeByID(ctx, c.Param("id"))
+	if err != nil {
+		apierror.Lookup(c, err, "recipe not found")
+		return
+	}
+	memory, err := LoadMemory(ctx, h.db, user.ID)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	cost := memory.CostRecipe(recipe, servings, store)
+	cost.Currency = h.currency(c, user.ID)
+	c.JSON(http.StatusOK, cost)
+}
+
+// WeekCost is the estimated cost of a week of a meal plan
+type WeekCost struct {
+	WeekStart     string  `json:"week_start"` // the Monday, YYYY-MM-DD
+	Total         float64 `json:"total"`
+	Meals         int     `json:"meals"`
+	UnpricedMeals int     `json:"unpriced_meals"` // with no recipe or ingredients missing a price
+}
+
+// mealPlanCost is the estimated cost of a meal plan by week
+type mealPlanCost struct {
+	MealPlanID string     `json:"meal_plan_id"`
+	Total      float64    `json:"total"`
+	Currency   string     `json:"currency,omitempty"`
+	Weeks      []WeekCost `json:"weeks"`
+}
+
+// GetMealPlanCost estimates what a meal plan costs, week by week, from the
+// planned recipes scaled to their servings
+// @Summary Meal plan cost estimate
+// @Tags budget
+// @Produce json
+// @Param id path string true "Meal plan ID"
+// @Param store query string false "Prefer prices at this store"
+// @Success 200 {object} mealPlanCost
+// @Router /meal-plans/{id}/cost [get]
+func (h *Handler) GetMealPlanCost(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	query := params.Query(c)
+	store := query.String("store")
+	if !query.Valid() {
+		return
+	}
+
+	ctx := c.Request.Context()
+	plan, err := h.db.GetMealPlanByID(ctx, c.Param("id"))
+	if err != nil {
+		apierror.Lookup(c, err, "meal plan not found")
+		return
+	}
+	visible, err := authz.CanViewMealPlan(ctx, h.db, user.ID, plan)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	if !visible {
+		apierror.NotFound(c, "meal plan not found")
+		return
+	}
+	memory, err := LoadMemory(ctx, h.db, user.ID)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	recipes := map[string]*database.Recipe{}
+	weeks := map[string]*WeekCost{}
+	for _, meal := range plan.Meals {
+		weekStart := meal.Date.AddDate(0, 0, -((int(meal.Date.Weekday()) + 6) % 7)).Format("2006-01-02")
+		week, ok := weeks[weekStart]
+		if !ok {
+			week = &WeekCost{WeekStart: weekStart}
+			weeks[weekStart] = week
+		}
+		week.Meals++
+		if meal.RecipeID == "" {
+			week.UnpricedMeals++
+			continue
+		}
+
+		recipe, ok := recipes[meal.RecipeID]
+		if !ok {
+			recipe, err = h.db.GetRecipeByID(ctx, meal.RecipeID)
+			if err != nil && !apierror.IsNotFound(err) {
+				apierror.Respond(c, err)
+				return
+			}
+			recipes[meal.RecipeID] = recipe
+		}
+		if recipe == nil {
+			week.UnpricedMeals++
+			continue
+		}
+		cost := memory.CostRecipe(recipe, meal.Servings, store)
+		week.Total += cost.Total
+		if cost.Unpriced > 0 || cost.Priced == 0 {
+			week.UnpricedMeals++
+		}
+	}
+
+	response := mealPlanCost{
+		MealPlanID: plan.ID,
+		Currency:   h.currency(c, user.ID),
+		Weeks:      []WeekCost{},
+	}
+	for _, week := range weeks {
+		week.Total = roundCents(week.Total)
+		response.Total += week.Total
+		response.Weeks = append(response.Weeks, *week)
+	}
+	response.Total = roundCents(response.Total)
+	sort.Slice(response.Weeks, func(i, j int) bool { return response.Weeks[i].WeekStart < response.Weeks[j].WeekStart })
+	c.JSON(http.StatusOK, response)
+}
+
+// ItemEstimate is the expected cost of a shopping list item
+type ItemEstimate struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Price     *float64 `json:"price"`     // nil when it can't be estimated
+	Estimated bool     `json:"estimated"` // from the price memory rather than the item
+	Store     string   `json:"store,omitempty"`
+}
+
+// shoppingEstimate is the expected cost of the items still to buy
+type shoppingEstimate struct {
+	Total    float64        `json:"total"`
+	Currency string         `json:"currency,omitempty"`
+	Unpriced int            `json:"unpriced"`
+	Items    []ItemEstimate `json:"items"`
+}
+
+// GetShoppingListEstimate estimates what the items still on the shopping
+// list will cost, using each item's own price and the price memory for
+// the rest
+// @Summary Shopping list cost estimate
+// @Tags budget
+// @Produce json
+// @Param store query string false "Prefer prices at this store"
+// @Success 200 {object} shoppingEstimate
+// @Router /shopping-list/estimate [get]
+func (h *Handler) GetShoppingListEstimate(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	query := params.Query(c)
+	store := query.String("store")
+	if !query.Valid() {
+		return
+	}
+
+	ctx := c.Request.Context()
+	completed := false
+	items, err := h.db.ListShoppingListItems(ctx, database.ShoppingListFilter{UserID: user.ID, Completed: &completed})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	memory, err := LoadMemory(ctx, h.db, user.ID)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	estimate := shoppingEstimate{Currency: h.currency(c, user.ID), Items: []ItemEstimate{}}
+	for _, item := range items {
+		line := ItemEstimate{ID: item.ID, Name: item.Name, Price: item.Price, Store: item.Store}
+		if line.Price == nil {
+			itemStore := item.Store
+			if itemStore == "" {
+				itemStore = store
+			}
+			// An item without a quantity is one of whatever the price is for
+			quantity, unit := item.Quantity, item.Unit
+			if price := memory.Find(item.Name, itemStore); price != nil && quantity <= 0 {
+				quantity, unit = price.Quantity, price.Unit
+			}
+			if amount, price, unpriced := memory.Estimate(item.Name, quantity, unit, itemStore); unpriced == "" {
+				line.Price, line.Estimated, line.Store = &amount, true, price.Store
+			}
+		}
+		if line.Price == nil {
+			estimate.Unpriced++
+		} else {
+			estimate.Total += *line.Price
+		}
+		estimate.Items = append(estimate.Items, line)
+	}
+	estimate.Total = roundCents(estimate.Total)
+	c.JSON(http.StatusOK, estimate)
+}
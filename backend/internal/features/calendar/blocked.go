@@ -0,0 +1,115 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package calendar
+
+import (
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/mealtime"
+)
+
+// BlockedSlot is a planned meal the user will be out for, so it should be
+// skipped when planning and shopping
+type BlockedSlot struct {
+	Date       string `json:"date"` // YYYY-MM-DD in the user's time zone
+	MealType   string `json:"meal_type"`
+	CalendarID string `json:"calendar_id"`
+	Reason     string `json:"reason"` // summary of the blocking event
+}
+
+// BlockedSlots lists the meal slots between the from and to days
+// (inclusive) that imported events block. An event blocks a slot when it
+// covers at least half of the user's window for that meal type, so a short
+// afternoon appointment does not cancel dinner. Meal types without a window
+// are never blocked.
+func BlockedSlots(calendars []*database.ExternalCalendar, events []*database.ExternalCalendarEvent, prefs *database.MealTimePreferences, from, to time.Time) []BlockedSlot {
+	loc := mealtime.Location(prefs)
+	first := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	last := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, loc)
+
+	byCalendar := map[string][]*database.ExternalCalendarEvent{}
+	for _, ev := range events {
+		byCalendar[ev.CalendarID] = append(byCalendar[ev.CalendarID], ev)
+	}
+
+	slots := []BlockedSlot{}
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		for _, mealType := range mealtime.MealTypes {
+			window, ok := mealWindow(prefs, mealType)
+			if !ok {
+				continue
+			}
+			start := day.Add(time.Duration(window.StartMinute) * time.Minute)
+			end := day.Add(time.Duration(window.EndMinute) * time.Minute)
+			if window.EndMinute <= window.StartMinute {
+				end = end.AddDate(0, 0, 1)
+			}
+
+			if slot, ok := blockingEvent(calendars, byCalendar, mealType, start, end); ok {
+				slot.Date = date
+				slots = append(slots, slot)
+			}
+		}
+	}
+	return slots
+}
+
+// blockingEvent finds the first event covering half of a meal window in a
+// calendar that blocks the meal type
+func blockingEvent(calendars []*database.ExternalCalendar, byCalendar map[string][]*database.ExternalCalendarEvent, mealType string, start, end time.Time) (BlockedSlot, bool) {
+	needed := end.Sub(start) / 2
+	for _, cal := range calendars {
+		if !blocks(cal, mealType) {
+			continue
+		}
+		for _, ev := range byCalendar[cal.ID] {
+			overlap := minTime(ev.EndsAt, end).Sub(maxTime(ev.StartsAt, start))
+			if overlap > 0 && overlap >= needed {
+				return BlockedSlot{MealType: mealType, CalendarID: cal.ID, Reason: ev.Summary}, true
+			}
+		}
+	}
+	return BlockedSlot{}, false
+}
+
+// blocks reports whether a calendar's events block a meal type
+func blocks(cal *database.ExternalCalendar, mealType string) bool {
+	for _, t := range cal.MealTypes {
+		if t == mealType {
+			return true
+		}
+	}
+	return false
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
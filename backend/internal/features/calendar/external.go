@@ -0,0 +1,339 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package calendar
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/api/params"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/authz"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/mealtime"
+	"github.com/rghsoftware/space-food/internal/middleware"
+)
+
+// maxBlockedSlotDays bounds the range of a blocked slot query
+const maxBlockedSlotDays = 92
+
+// RegisterExternalRoutes registers external calendar routes
+func (h *Handler) RegisterExternalRoutes(router *gin.RouterGroup) {
+	router.GET("", h.ListExternalCalendars)
+	router.POST("", h.CreateExternalCalendar)
+	router.GET("/blocked-slots", h.GetBlockedSlots)
+	router.PUT("/:id", h.UpdateExternalCalendar)
+	router.DELETE("/:id", h.DeleteExternalCalendar)
+	router.POST("/:id/sync", h.SyncExternalCalendar)
+}
+
+// ListExternalCalendars lists the authenticated user's linked calendars
+// @Summary List external calendars
+// @Tags calendar
+// @Produce json
+// @Router /me/external-calendars [get]
+func (h *Handler) ListExternalCalendars(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	calendars, err := h.db.ListExternalCalendars(c.Request.Context(), user.ID)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	for _, cal := range calendars {
+		cal.URL = RedactURL(cal.URL)
+	}
+	c.JSON(http.StatusOK, calendars)
+}
+
+// CreateExternalCalendar links an external calendar and imports it right away
+// @Summary Link external calendar
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Router /me/external-calendars [post]
+func (h *Handler) CreateExternalCalendar(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req struct {
+		Name      string   `json:"name" binding:"max=255"`
+		URL       string   `json:"url" binding:"required"`
+		MealTypes []string `json:"meal_types"` // defaults to dinner
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	calendarURL, err := NormalizeURL(req.URL)
+	if err != nil {
+		apierror.BadRequest(c, err.Error())
+		return
+	}
+	if err := h.importer.Check(calendarURL); err != nil {
+		apierror.BadRequest(c, err.Error())
+		return
+	}
+	mealTypes, ok := normalizeMealTypes(req.MealTypes)
+	if !ok {
+		apierror.BadRequest(c, "meal_types must be breakfast, lunch, dinner or snack")
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = "Calendar"
+	}
+
+	now := time.Now()
+	cal := database.ExternalCalendar{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		Name:      name,
+		URL:       calendarURL,
+		MealTypes: mealTypes,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := h.db.CreateExternalCalendar(c.Request.Context(), &cal); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	// A failed first sync is reported in last_error and retried by the
+	// background job, so the calendar is still created
+	h.importer.Sync(c.Request.Context(), &cal)
+
+	cal.URL = RedactURL(cal.URL)
+	c.JSON(http.StatusCreated, cal)
+}
+
+// UpdateExternalCalendar renames a linked calendar, changes its URL or the
+// meal types it blocks
+// @Summary Update external calendar
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Router /me/external-calendars/{id} [put]
+func (h *Handler) UpdateExternalCalendar(c *gin.Context) {
+	cal, ok := h.ownedExternalCalendar(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Name      *string  `json:"name" binding:"omitempty,max=255"`
+		URL       *string  `json:"url"`
+		MealTypes []string `json:"meal_types"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	if req.Name != nil && strings.TrimSpace(*req.Name) != "" {
+		cal.Name = strings.TrimSpace(*req.Name)
+	}
+	urlChanged := false
+	if req.URL != nil {
+		calendarURL, err := NormalizeURL(*req.URL)
+		if err != nil {
+			apierror.BadRequest(c, err.Error())
+			return
+		}
+		if err := h.importer.Check(calendarURL); err != nil {
+			apierror.BadRequest(c, err.Error())
+			return
+		}
+		urlChanged = calendarURL != cal.URL
+		cal.URL = calendarURL
+	}
+	if req.MealTypes != nil {
+		mealTypes, ok := normalizeMealTypes(req.MealTypes)
+		if !ok {
+			apierror.BadRequest(c, "meal_types must be breakfast, lunch, dinner or snack")
+			return
+		}
+		cal.MealTypes = mealTypes
+	}
+	cal.UpdatedAt = time.Now()
+
+	if err := h.db.UpdateExternalCalendar(c.Request.Context(), cal); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	if urlChanged {
+		h.importer.Sync(c.Request.Context(), cal)
+	}
+
+	cal.URL = RedactURL(cal.URL)
+	c.JSON(http.StatusOK, cal)
+}
+
+// DeleteExternalCalendar unlinks a calendar and forgets its events
+// @Summary Delete external calendar
+// @Tags calendar
+// @Router /me/external-calendars/{id} [delete]
+func (h *Handler) DeleteExternalCalendar(c *gin.Context) {
+	cal, ok := h.ownedExternalCalendar(c)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteExternalCalendar(c.Request.Context(), cal.ID); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SyncExternalCalendar fetches a linked calendar now
+// @Summary Sync external calendar
+// @Tags calendar
+// @Produce json
+// @Router /me/external-calendars/{id}/sync [post]
+func (h *Handler) SyncExternalCalendar(c *gin.Context) {
+	cal, ok := h.ownedExternalCalendar(c)
+	if !ok {
+		return
+	}
+
+	if err := h.importer.Sync(c.Request.Context(), cal); err != nil {
+		apierror.Upstream(c, err.Error())
+		return
+	}
+
+	cal.URL = RedactURL(cal.URL)
+	c.JSON(http.StatusOK, cal)
+}
+
+// GetBlockedSlots lists the meal slots the user's linked calendars mark as
+// eating out, for the from and to dates (inclusive, default the next two weeks)
+// @Summary Blocked meal slots
+// @Tags calendar
+// @Produce json
+// @Param from query string false "First day (YYYY-MM-DD)"
+// @Param to query string false "Last day (YYYY-MM-DD)"
+// @Router /me/external-calendars/blocked-slots [get]
+func (h *Handler) GetBlockedSlots(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	ctx := c.Request.Context()
+	prefs := mealtime.LoadPreferences(c, h.db, user.ID)
+	loc := mealtime.Location(prefs)
+
+	today := time.Now().In(loc)
+	from := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, loc)
+	to := from.AddDate(0, 0, 13)
+	query := params.Query(c)
+	if t, ok := query.Date("from", loc); ok {
+		from, to = t, t.AddDate(0, 0, 13)
+	}
+	if t, ok := query.Date("to", loc); ok {
+		to = t
+	}
+	if to.Before(from) {
+		query.Fail("to", "gtefield", "from")
+	} else if to.Sub(from) > maxBlockedSlotDays*24*time.Hour {
+		query.Fail("to", "max_days", strconv.Itoa(maxBlockedSlotDays))
+	}
+	if !query.Valid() {
+		return
+	}
+
+	calendars, err := h.db.ListExternalCalendars(ctx, user.ID)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	// Windows can run past midnight into the day after to
+	events, err := h.db.ListExternalCalendarEvents(ctx, user.ID, from, to.AddDate(0, 0, 2))
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":  from.Format("2006-01-02"),
+		"to":    to.Format("2006-01-02"),
+		"slots": BlockedSlots(calendars, events, prefs, from, to),
+	})
+}
+
+// ownedExternalCalendar loads the calendar named in the path, writing an
+// error response unless it belongs to the authenticated user
+func (h *Handler) ownedExternalCalendar(c *gin.Context) (*database.ExternalCalendar, bool) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return nil, false
+	}
+
+	cal, err := h.db.GetExternalCalendarByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierror.Lookup(c, err, "calendar not found")
+		return nil, false
+	}
+
+	if !authz.Owns(user.ID, cal.UserID) {
+		apierror.Forbidden(c, "forbidden")
+		return nil, false
+	}
+
+	return cal, true
+}
+
+// normalizeMealTypes validates the meal types a calendar blocks, defaulting
+// to dinner, the meal most often eaten out
+func normalizeMealTypes(types []string) ([]string, bool) {
+	if len(types) == 0 {
+		return []string{mealtime.MealTypeDinner}, true
+	}
+	seen := map[string]bool{}
+	normalized := []string{}
+	for _, t := range types {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if !mealtime.IsMealType(t) {
+			return nil, false
+		}
+		if !seen[t] {
+			seen[t] = true
+			normalized = append(normalized, t)
+		}
+	}
+	return normalized, true
+}
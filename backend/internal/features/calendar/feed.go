@@ -0,0 +1,153 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package calendar
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/mealtime"
+)
+
+// TokenPrefix marks calendar feed tokens so they are recognisable in URLs
+const TokenPrefix = "sfcal_"
+
+// Feed window and polling interval
+const (
+	FeedPast    = 30 * 24 * time.Hour
+	FeedFuture  = 90 * 24 * time.Hour
+	FeedRefresh = time.Hour
+)
+
+const uidDomain = "@space-food"
+
+// GenerateToken returns a new feed token, the hash to store, and a short
+// display prefix
+func GenerateToken() (token, hash, prefix string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+	token = TokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return token, HashToken(token), token[:len(TokenPrefix)+6], nil
+}
+
+// HashToken returns the stored form of a feed token
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// MealEvents returns the calendar events for a planned meal: the meal
+// itself, placed in the user's meal window when they have one for its meal
+// type, and a "start cooking" block before it when the recipe has prep or
+// cook times. recipe may be nil.
+func MealEvents(plan *database.MealPlan, meal database.PlannedMeal, recipe *database.Recipe, prefs *database.MealTimePreferences) []Event {
+	label := mealLabel(meal.MealType)
+	summary := label
+	if recipe != nil && recipe.Title != "" {
+		summary = label + ": " + recipe.Title
+	}
+
+	details := []string{}
+	if recipe != nil && recipe.Description != "" {
+		details = append(details, recipe.Description)
+	}
+	if meal.Servings > 0 {
+		details = append(details, fmt.Sprintf("Servings: %d", meal.Servings))
+	}
+	if meal.Notes != "" {
+		details = append(details, meal.Notes)
+	}
+	if plan.Title != "" {
+		details = append(details, "Meal plan: "+plan.Title)
+	}
+
+	loc := mealtime.Location(prefs)
+	day := time.Date(meal.Date.Year(), meal.Date.Month(), meal.Date.Day(), 0, 0, 0, 0, loc)
+
+	window, timed := mealWindow(prefs, meal.MealType)
+	if !timed {
+		return []Event{{
+			UID:         meal.ID + uidDomain,
+			Summary:     summary,
+			Description: strings.Join(details, "\n\n"),
+			Start:       day,
+			End:         day.AddDate(0, 0, 1),
+			AllDay:      true,
+			Categories:  []string{label},
+		}}
+	}
+
+	start := day.Add(time.Duration(window.StartMinute) * time.Minute)
+	end := day.Add(time.Duration(window.EndMinute) * time.Minute)
+	if window.EndMinute <= window.StartMinute {
+		// The window wraps past midnight
+		end = end.AddDate(0, 0, 1)
+	}
+
+	events := []Event{{
+		UID:         meal.ID + uidDomain,
+		Summary:     summary,
+		Description: strings.Join(details, "\n\n"),
+		Start:       start,
+		End:         end,
+		Categories:  []string{label},
+	}}
+
+	if recipe != nil && recipe.PrepTime+recipe.CookTime > 0 {
+		total := time.Duration(recipe.PrepTime+recipe.CookTime) * time.Minute
+		events = append(events, Event{
+			UID:         meal.ID + "-cook" + uidDomain,
+			Summary:     "Start cooking: " + recipe.Title,
+			Description: fmt.Sprintf("Prep %d min, cook %d min, ready for %s.", recipe.PrepTime, recipe.CookTime, strings.ToLower(label)),
+			Start:       start.Add(-total),
+			End:         start,
+			Categories:  []string{"Cooking"},
+		})
+	}
+
+	return events
+}
+
+// mealWindow finds the user's window for a meal type
+func mealWindow(prefs *database.MealTimePreferences, mealType string) (database.MealWindow, bool) {
+	if prefs != nil {
+		for _, w := range prefs.Windows {
+			if w.MealType == mealType {
+				return w, true
+			}
+		}
+	}
+	return database.MealWindow{}, false
+}
+
+// mealLabel capitalises a meal type for display
+func mealLabel(mealType string) string {
+	if mealType == "" {
+		return "Meal"
+	}
+	return strings.ToUpper(mealType[:1]) + mealType[1:]
+}
@@ -0,0 +1,255 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package calendar
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/mealtime"
+	"github.com/rghsoftware/space-food/internal/middleware"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// Handler handles calendar feed and external calendar HTTP requests
+type Handler struct {
+	db        database.Database
+	publicURL string
+	importer  *Importer
+}
+
+// NewHandler creates a new calendar handler. importer may be nil when
+// external calendar import is disabled.
+func NewHandler(db database.Database, publicURL string, importer *Importer) *Handler {
+	return &Handler{
+		db:        db,
+		publicURL: strings.TrimRight(publicURL, "/"),
+		importer:  importer,
+	}
+}
+
+// RegisterRoutes registers calendar feed management routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("", h.ListFeeds)
+	router.POST("", h.CreateFeed)
+	router.DELETE("/:id", h.DeleteFeed)
+}
+
+// RegisterFeedRoutes registers the public, token-authenticated feed route
+func (h *Handler) RegisterFeedRoutes(router *gin.RouterGroup) {
+	router.GET("/:token", h.GetFeed)
+}
+
+// ListFeeds lists the authenticated user's calendar feeds
+// @Summary List calendar feeds
+// @Tags calendar
+// @Produce json
+// @Router /me/calendar-feeds [get]
+func (h *Handler) ListFeeds(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	feeds, err := h.db.ListCalendarFeeds(c.Request.Context(), user.ID)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, feeds)
+}
+
+// CreateFeed creates a calendar feed. The subscription URL is only returned here.
+// @Summary Create calendar feed
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Router /me/calendar-feeds [post]
+func (h *Handler) CreateFeed(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req struct {
+		Name string `json:"name" binding:"max=255"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = "Meal plan"
+	}
+
+	token, hash, prefix, err := GenerateToken()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	feed := database.CalendarFeed{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		Name:      name,
+		TokenHash: hash,
+		Prefix:    prefix,
+		CreatedAt: time.Now(),
+	}
+	if err := h.db.CreateCalendarFeed(c.Request.Context(), &feed); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"url":           h.feedURL(token),
+		"calendar_feed": feed,
+	})
+}
+
+// DeleteFeed revokes a calendar feed
+// @Summary Delete calendar feed
+// @Tags calendar
+// @Router /me/calendar-feeds/{id} [delete]
+func (h *Handler) DeleteFeed(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	id := c.Param("id")
+
+	// Verify ownership
+	feeds, err := h.db.ListCalendarFeeds(c.Request.Context(), user.ID)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	owned := false
+	for _, f := range feeds {
+		if f.ID == id {
+			owned = true
+			break
+		}
+	}
+	if !owned {
+		apierror.NotFound(c, "calendar feed not found")
+		return
+	}
+
+	if err := h.db.DeleteCalendarFeed(c.Request.Context(), id); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetFeed serves the iCalendar feed for a token. The token in the URL is
+// the only credential, so calendar apps can subscribe without signing in.
+// @Summary Calendar feed
+// @Tags calendar
+// @Produce text/calendar
+// @Router /calendar/{token} [get]
+func (h *Handler) GetFeed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+	if !strings.HasPrefix(token, TokenPrefix) {
+		apierror.NotFound(c, "calendar feed not found")
+		return
+	}
+
+	ctx := c.Request.Context()
+	feed, err := h.db.GetCalendarFeedByHash(ctx, HashToken(token))
+	if err != nil {
+		apierror.Lookup(c, err, "calendar feed not found")
+		return
+	}
+
+	now := time.Now()
+	if err := h.db.TouchCalendarFeed(ctx, feed.ID, now); err != nil {
+		logger.Ctx(ctx).Warn().Err(err).Str("feed_id", feed.ID).Msg("Failed to record calendar feed use")
+	}
+
+	prefs := mealtime.LoadPreferences(c, h.db, feed.UserID)
+	from, to := now.Add(-FeedPast), now.Add(FeedFuture)
+
+	plans, err := h.db.ListMealPlans(ctx, database.MealPlanFilter{
+		UserID:    feed.UserID,
+		StartDate: from,
+		EndDate:   to,
+		Limit:     100,
+	})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	cal := Calendar{Name: "Space Food: " + feed.Name, Refresh: FeedRefresh}
+	recipes := map[string]*database.Recipe{}
+	// Stamp events with the latest plan change rather than the time of the
+	// request, so an unchanged feed has the same ETag on every poll
+	stamp := feed.CreatedAt
+	for _, plan := range plans {
+		if plan.UpdatedAt.After(stamp) {
+			stamp = plan.UpdatedAt
+		}
+		for _, meal := range plan.Meals {
+			if meal.Date.Before(from.Truncate(24*time.Hour)) || meal.Date.After(to) {
+				continue
+			}
+			cal.Events = append(cal.Events, MealEvents(plan, meal, h.recipe(c, recipes, meal.RecipeID), prefs)...)
+		}
+	}
+
+	c.Header("Cache-Control", "private, max-age=900")
+	c.Header("Content-Disposition", `inline; filename="space-food.ics"`)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", cal.Encode(stamp))
+}
+
+// recipe fetches a planned meal's recipe once per feed. Meals whose recipe
+// is missing or was deleted are still listed, just without recipe details.
+func (h *Handler) recipe(c *gin.Context, cache map[string]*database.Recipe, id string) *database.Recipe {
+	if id == "" {
+		return nil
+	}
+	if recipe, ok := cache[id]; ok {
+		return recipe
+	}
+	recipe, err := h.db.GetRecipeByID(c.Request.Context(), id)
+	if err != nil {
+		recipe = nil
+	}
+	cache[id] = recipe
+	return recipe
+}
+
+// feedURL builds the subscription URL for a feed token
+func (h *Handler) feedURL(token string) string {
+	return h.publicURL + "/api/v1/calendar/" + token + ".ics"
+}
@@ -0,0 +1,169 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Event is one VEVENT in an iCalendar feed. All-day events only use the
+// date part of Start and End, with End being the day after the last day.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+	Categories  []string
+}
+
+// Calendar is an iCalendar (RFC 5545) document published to subscribers
+type Calendar struct {
+	Name    string
+	Refresh time.Duration // how often clients should poll, 0 to leave it to them
+	Events  []Event
+}
+
+const (
+	productID     = "-//RGH Software//Space Food//EN"
+	icalDateTime  = "20060102T150405Z"
+	icalDate      = "20060102"
+	maxLineOctets = 75
+)
+
+// Encode renders the calendar with CRLF line endings and folded lines.
+// stamp is written as the DTSTAMP of every event.
+func (cal *Calendar) Encode(stamp time.Time) []byte {
+	var b strings.Builder
+	w := func(name, value string) {
+		writeFolded(&b, name+":"+value)
+	}
+
+	w("BEGIN", "VCALENDAR")
+	w("VERSION", "2.0")
+	w("PRODID", productID)
+	w("CALSCALE", "GREGORIAN")
+	w("METHOD", "PUBLISH")
+	if cal.Name != "" {
+		w("X-WR-CALNAME", escapeText(cal.Name))
+	}
+	if cal.Refresh > 0 {
+		duration := formatDuration(cal.Refresh)
+		w("REFRESH-INTERVAL;VALUE=DURATION", duration)
+		w("X-PUBLISHED-TTL", duration)
+	}
+
+	for _, ev := range cal.Events {
+		w("BEGIN", "VEVENT")
+		w("UID", ev.UID)
+		w("DTSTAMP", stamp.UTC().Format(icalDateTime))
+		if ev.AllDay {
+			w("DTSTART;VALUE=DATE", ev.Start.Format(icalDate))
+			w("DTEND;VALUE=DATE", ev.End.Format(icalDate))
+			w("TRANSP", "TRANSPARENT")
+		} else {
+			w("DTSTART", ev.Start.UTC().Format(icalDateTime))
+			w("DTEND", ev.End.UTC().Format(icalDateTime))
+		}
+		w("SUMMARY", escapeText(ev.Summary))
+		if ev.Description != "" {
+			w("DESCRIPTION", escapeText(ev.Description))
+		}
+		if len(ev.Categories) > 0 {
+			escaped := make([]string, len(ev.Categories))
+			for i, c := range ev.Categories {
+				escaped[i] = escapeText(c)
+			}
+			w("CATEGORIES", strings.Join(escaped, ","))
+		}
+		w("END", "VEVENT")
+	}
+
+	w("END", "VCALENDAR")
+	return []byte(b.String())
+}
+
+// escapeText escapes a TEXT property value
+func escapeText(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(s)
+}
+
+// unescapeText reverses escapeText
+func unescapeText(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// writeFolded writes a content line, folding it at 75 octets without
+// splitting multi-byte characters
+func writeFolded(b *strings.Builder, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines lose one octet to the leading space
+		limit = maxLineOctets - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func isRuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
+
+// formatDuration renders whole minutes as an iCalendar DURATION
+func formatDuration(d time.Duration) string {
+	minutes := int(d.Minutes())
+	if minutes < 1 {
+		minutes = 1
+	}
+	if minutes%60 == 0 {
+		return fmt.Sprintf("PT%dH", minutes/60)
+	}
+	return fmt.Sprintf("PT%dM", minutes)
+}
@@ -0,0 +1,231 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package calendar
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/config"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/mealtime"
+	"github.com/rghsoftware/space-food/internal/fetch"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// Import window, batch size and download limit
+const (
+	importPast      = 24 * time.Hour
+	importFuture    = 90 * 24 * time.Hour
+	syncBatch       = 20
+	maxCalendarSize = 5 << 20
+)
+
+// calendarTypes are the content types calendar servers send ICS files as
+var calendarTypes = []string{"text/calendar", "text/plain", "application/octet-stream"}
+
+// ErrInvalidURL is returned for calendar URLs that cannot be fetched
+var ErrInvalidURL = errors.New("calendar url must be an http, https or webcal address")
+
+// Sync failures as users see them, in responses and last_error. The
+// underlying errors, which can say what is on the server's network, are
+// only logged.
+var (
+	ErrBlocked   = errors.New("calendar address is not allowed")
+	ErrTooLarge  = fmt.Errorf("calendar is larger than %d MB", maxCalendarSize>>20)
+	ErrFetch     = errors.New("calendar could not be fetched")
+	ErrParse     = errors.New("calendar could not be read")
+	ErrSync      = errors.New("calendar could not be synced")
+	publicErrors = []error{ErrBlocked, ErrTooLarge, ErrFetch, ErrParse}
+)
+
+// Importer fetches linked external calendars and stores their busy events
+type Importer struct {
+	db       database.Database
+	fetcher  *fetch.Client
+	interval time.Duration
+}
+
+// NewImporter creates an external calendar importer. Calendars are fetched
+// under the instance's fetch policy, with the calendar import timeout.
+func NewImporter(db database.Database, cfg *config.Config) *Importer {
+	policy := cfg.Fetch
+	policy.Timeout = cfg.Calendar.Import.Timeout
+	return &Importer{
+		db:       db,
+		fetcher:  fetch.New(policy, maxCalendarSize),
+		interval: time.Duration(max(cfg.Calendar.Import.SyncInterval, 1)) * time.Minute,
+	}
+}
+
+// Check applies the fetch policy's domain rules to a normalized calendar
+// URL, so calendars that would never sync are refused when linked
+func (im *Importer) Check(calendarURL string) error {
+	u, err := url.Parse(calendarURL)
+	if err != nil {
+		return ErrInvalidURL
+	}
+	u.User = nil
+	if _, err := im.fetcher.Check(u.String()); err != nil {
+		return ErrBlocked
+	}
+	return nil
+}
+
+// NormalizeURL validates a calendar URL, turning webcal:// subscription
+// links into https:// ones. Credentials in the URL are kept and sent as
+// basic auth, which is how most CalDAV servers protect their ICS exports.
+func NormalizeURL(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return "", ErrInvalidURL
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "webcal", "webcals":
+		u.Scheme = "https"
+	case "http", "https":
+		u.Scheme = strings.ToLower(u.Scheme)
+	default:
+		return "", ErrInvalidURL
+	}
+	return u.String(), nil
+}
+
+// RedactURL hides any password in a calendar URL for display
+func RedactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	return u.Redacted()
+}
+
+// Sync fetches a calendar and replaces its imported events. The outcome is
+// recorded on the calendar; events from the last successful sync are kept
+// when fetching fails.
+func (im *Importer) Sync(ctx context.Context, cal *database.ExternalCalendar) error {
+	now := time.Now().UTC()
+	syncErr := im.sync(ctx, cal, now)
+
+	cal.LastSyncedAt = &now
+	cal.LastError = ""
+	if syncErr != nil {
+		logger.Ctx(ctx).Warn().Err(syncErr).Str("calendar_id", cal.ID).Msg("Calendar sync failed")
+		syncErr = publicError(syncErr)
+		cal.LastError = syncErr.Error()
+	}
+	if err := im.db.UpdateExternalCalendar(ctx, cal); err != nil {
+		return err
+	}
+	return syncErr
+}
+
+func (im *Importer) sync(ctx context.Context, cal *database.ExternalCalendar, now time.Time) error {
+	body, err := im.fetch(ctx, cal.URL)
+	if err != nil {
+		switch {
+		case errors.Is(err, fetch.ErrBlocked), errors.Is(err, fetch.ErrInvalidURL):
+			return fmt.Errorf("%w: %w", ErrBlocked, err)
+		case errors.Is(err, fetch.ErrTooLarge):
+			return ErrTooLarge
+		}
+		return fmt.Errorf("%w: %w", ErrFetch, err)
+	}
+
+	prefs, err := im.db.GetMealTimePreferences(ctx, cal.UserID)
+	if err != nil {
+		prefs = mealtime.DefaultPreferences(cal.UserID)
+	}
+
+	parsed, err := ParseEvents(bytes.NewReader(body), mealtime.Location(prefs), now.Add(-importPast), now.Add(importFuture))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrParse, err)
+	}
+
+	events := make([]*database.ExternalCalendarEvent, 0, len(parsed))
+	for _, ev := range parsed {
+		events = append(events, &database.ExternalCalendarEvent{
+			CalendarID: cal.ID,
+			UserID:     cal.UserID,
+			UID:        ev.UID,
+			Summary:    ev.Summary,
+			StartsAt:   ev.Start,
+			EndsAt:     ev.End,
+		})
+	}
+	return im.db.ReplaceExternalCalendarEvents(ctx, cal.ID, events)
+}
+
+// fetch downloads an ICS document. The fetch policy refuses URLs with
+// credentials in them, so they are sent as basic auth instead.
+func (im *Importer) fetch(ctx context.Context, calendarURL string) ([]byte, error) {
+	u, err := url.Parse(calendarURL)
+	if err != nil {
+		return nil, fetch.ErrInvalidURL
+	}
+	header := http.Header{}
+	if u.User != nil {
+		password, _ := u.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(u.User.Username() + ":" + password))
+		header.Set("Authorization", "Basic "+credentials)
+		u.User = nil
+	}
+
+	resp, err := im.fetcher.GetWith(ctx, u.String(), header, calendarTypes...)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// publicError reduces a sync error to the one users are shown
+func publicError(err error) error {
+	for _, public := range publicErrors {
+		if errors.Is(err, public) {
+			return public
+		}
+	}
+	return ErrSync
+}
+
+// SyncDue syncs the calendars not fetched within the sync interval
+func (im *Importer) SyncDue(ctx context.Context) (string, error) {
+	due, err := im.db.ListExternalCalendarsToSync(ctx, time.Now().UTC().Add(-im.interval), syncBatch)
+	if err != nil {
+		return "", err
+	}
+
+	failed := 0
+	for _, cal := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := im.Sync(ctx, cal); err != nil {
+			failed++
+		}
+	}
+	return fmt.Sprintf("synced %d calendars, %d failed", len(due)-failed, failed), nil
+}
@@ -0,0 +1,474 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxOccurrences bounds how far a single recurring event is expanded
+const maxOccurrences = 1000
+
+// property is one unfolded content line
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// component collects the properties of a VEVENT. Properties that may repeat
+// keep every value.
+type component map[string][]property
+
+func (c component) first(name string) (property, bool) {
+	if props := c[name]; len(props) > 0 {
+		return props[0], true
+	}
+	return property{}, false
+}
+
+func (c component) text(name string) string {
+	p, _ := c.first(name)
+	return unescapeText(p.value)
+}
+
+// ParseEvents reads the busy events of an iCalendar document occurring
+// between from and to. Recurring events are expanded into one Event per
+// occurrence. Times without a zone, and zones Go does not know, are read in
+// loc.
+//
+// Timed events count as busy unless marked TRANSP:TRANSPARENT. All-day
+// events are usually birthdays or holidays, so they only count when
+// explicitly marked TRANSP:OPAQUE. Cancelled events never count.
+func ParseEvents(r io.Reader, loc *time.Location, from, to time.Time) ([]Event, error) {
+	components, err := readEvents(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Occurrences moved or cancelled by a RECURRENCE-ID override are skipped
+	// when expanding the master event
+	overridden := map[string]map[int64]bool{}
+	for _, c := range components {
+		p, ok := c.first("RECURRENCE-ID")
+		if !ok {
+			continue
+		}
+		at, _, err := parseTime(p, loc)
+		if err != nil {
+			continue
+		}
+		uid := c.text("UID")
+		if overridden[uid] == nil {
+			overridden[uid] = map[int64]bool{}
+		}
+		overridden[uid][at.Unix()] = true
+	}
+
+	events := []Event{}
+	for _, c := range components {
+		if strings.EqualFold(c.text("STATUS"), "CANCELLED") {
+			continue
+		}
+
+		startProp, ok := c.first("DTSTART")
+		if !ok {
+			continue
+		}
+		start, allDay, err := parseTime(startProp, loc)
+		if err != nil {
+			continue
+		}
+
+		transp := strings.ToUpper(c.text("TRANSP"))
+		if transp == "TRANSPARENT" || (allDay && transp != "OPAQUE") {
+			continue
+		}
+
+		length, err := eventLength(c, start, allDay, loc)
+		if err != nil {
+			continue
+		}
+
+		base := Event{
+			UID:     c.text("UID"),
+			Summary: c.text("SUMMARY"),
+			AllDay:  allDay,
+		}
+
+		occurrences := []time.Time{start}
+		if _, isOverride := c.first("RECURRENCE-ID"); !isOverride {
+			if rule, ok := c.first("RRULE"); ok {
+				occurrences = expand(rule.value, start, to)
+				skip := exceptionDates(c, loc)
+				for at := range overridden[base.UID] {
+					skip[at] = true
+				}
+				occurrences = without(occurrences, skip)
+			}
+		}
+
+		for _, at := range occurrences {
+			end := at.Add(length)
+			if allDay {
+				// Whole days, so the end stays at midnight across DST changes
+				end = at.AddDate(0, 0, int((length+12*time.Hour)/(24*time.Hour)))
+			}
+			if !end.After(from) || !at.Before(to) {
+				continue
+			}
+			ev := base
+			ev.Start, ev.End = at, end
+			events = append(events, ev)
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool { return events[i].Start.Before(events[j].Start) })
+	return events, nil
+}
+
+// readEvents unfolds content lines and collects the top-level properties of
+// each VEVENT, ignoring nested components such as VALARM
+func readEvents(r io.Reader) ([]component, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read calendar: %w", err)
+	}
+	if len(lines) == 0 || !strings.EqualFold(strings.TrimSpace(lines[0]), "BEGIN:VCALENDAR") {
+		return nil, fmt.Errorf("not an iCalendar document")
+	}
+
+	components := []component{}
+	var current component
+	depth := 0 // nesting inside the current VEVENT
+	for _, line := range lines {
+		p, ok := parseLine(line)
+		if !ok {
+			continue
+		}
+		switch {
+		case p.name == "BEGIN" && strings.EqualFold(p.value, "VEVENT") && current == nil:
+			current = component{}
+		case current == nil:
+			// Outside any event
+		case p.name == "BEGIN":
+			depth++
+		case p.name == "END" && depth > 0:
+			depth--
+		case p.name == "END" && strings.EqualFold(p.value, "VEVENT"):
+			components = append(components, current)
+			current = nil
+		case depth == 0:
+			current[p.name] = append(current[p.name], p)
+		}
+	}
+	return components, nil
+}
+
+// parseLine splits a content line into name, parameters and value
+func parseLine(line string) (property, bool) {
+	// The value starts at the first colon outside a quoted parameter value
+	colon := -1
+	quoted := false
+	for i, ch := range line {
+		if ch == '"' {
+			quoted = !quoted
+		} else if ch == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon <= 0 {
+		return property{}, false
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	p := property{
+		name:   strings.ToUpper(parts[0]),
+		params: map[string]string{},
+		value:  line[colon+1:],
+	}
+	for _, param := range parts[1:] {
+		if key, value, ok := strings.Cut(param, "="); ok {
+			p.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+		}
+	}
+	return p, true
+}
+
+// parseTime reads a DATE or DATE-TIME property value, reporting whether it
+// is a date
+func parseTime(p property, loc *time.Location) (time.Time, bool, error) {
+	value := strings.TrimSpace(p.value)
+	if i := strings.IndexByte(value, ','); i >= 0 {
+		value = value[:i]
+	}
+	return parseTimeValue(value, p.params, loc)
+}
+
+func parseTimeValue(value string, params map[string]string, loc *time.Location) (time.Time, bool, error) {
+	if strings.EqualFold(params["VALUE"], "DATE") || len(value) == len(icalDate) {
+		t, err := time.ParseInLocation(icalDate, value, loc)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(icalDateTime, value)
+		return t, false, err
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, zone(params["TZID"], loc))
+	return t, false, err
+}
+
+// zone resolves a TZID, falling back to loc for names Go does not know,
+// such as Windows zone names
+func zone(tzid string, loc *time.Location) *time.Location {
+	tzid = strings.TrimPrefix(tzid, "/")
+	if tzid == "" {
+		return loc
+	}
+	if z, err := time.LoadLocation(tzid); err == nil {
+		return z
+	}
+	return loc
+}
+
+// eventLength works out an event's duration from DTEND or DURATION
+func eventLength(c component, start time.Time, allDay bool, loc *time.Location) (time.Duration, error) {
+	if p, ok := c.first("DTEND"); ok {
+		end, _, err := parseTime(p, loc)
+		if err != nil {
+			return 0, err
+		}
+		if end.Before(start) {
+			return 0, fmt.Errorf("event ends before it starts")
+		}
+		return end.Sub(start), nil
+	}
+	if p, ok := c.first("DURATION"); ok {
+		return parseDuration(p.value)
+	}
+	if allDay {
+		return 24 * time.Hour, nil
+	}
+	return 0, nil
+}
+
+// parseDuration reads an iCalendar DURATION such as PT1H30M or P1D
+func parseDuration(value string) (time.Duration, error) {
+	value = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(value)), "+")
+	if strings.HasPrefix(value, "-") {
+		return 0, fmt.Errorf("negative duration %q", value)
+	}
+	if !strings.HasPrefix(value, "P") {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	var total time.Duration
+	number := ""
+	for _, ch := range value[1:] {
+		if ch >= '0' && ch <= '9' {
+			number += string(ch)
+			continue
+		}
+		if ch == 'T' {
+			continue
+		}
+		n, err := strconv.Atoi(number)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		number = ""
+		switch ch {
+		case 'W':
+			total += time.Duration(n) * 7 * 24 * time.Hour
+		case 'D':
+			total += time.Duration(n) * 24 * time.Hour
+		case 'H':
+			total += time.Duration(n) * time.Hour
+		case 'M':
+			total += time.Duration(n) * time.Minute
+		case 'S':
+			total += time.Duration(n) * time.Second
+		default:
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+	}
+	return total, nil
+}
+
+// exceptionDates collects the EXDATE values of an event
+func exceptionDates(c component, loc *time.Location) map[int64]bool {
+	skip := map[int64]bool{}
+	for _, p := range c["EXDATE"] {
+		for _, value := range strings.Split(p.value, ",") {
+			if at, _, err := parseTimeValue(strings.TrimSpace(value), p.params, loc); err == nil {
+				skip[at.Unix()] = true
+			}
+		}
+	}
+	return skip
+}
+
+func without(times []time.Time, skip map[int64]bool) []time.Time {
+	kept := times[:0]
+	for _, t := range times {
+		if !skip[t.Unix()] {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// expand lists the occurrences of a recurrence rule up to the given time.
+// It supports FREQ DAILY, WEEKLY (with BYDAY), MONTHLY and YEARLY with
+// INTERVAL, COUNT and UNTIL. Rules it does not understand yield only the
+// first occurrence.
+func expand(rule string, start time.Time, to time.Time) []time.Time {
+	parts := map[string]string{}
+	for _, part := range strings.Split(rule, ";") {
+		if key, value, ok := strings.Cut(part, "="); ok {
+			parts[strings.ToUpper(key)] = strings.ToUpper(value)
+		}
+	}
+
+	interval := 1
+	if n, err := strconv.Atoi(parts["INTERVAL"]); err == nil && n > 0 {
+		interval = n
+	}
+	count := 0
+	if n, err := strconv.Atoi(parts["COUNT"]); err == nil && n > 0 {
+		count = n
+	}
+	until := to
+	if value := parts["UNTIL"]; value != "" {
+		if t, _, err := parseTimeValue(value, map[string]string{}, start.Location()); err == nil && t.Before(until) {
+			until = t
+			if len(value) == len(icalDate) {
+				// A date UNTIL includes occurrences on that day
+				until = t.AddDate(0, 0, 1).Add(-time.Second)
+			}
+		}
+	}
+
+	var byDay []time.Weekday
+	if value := parts["BYDAY"]; value != "" {
+		for _, day := range strings.Split(value, ",") {
+			wd, ok := weekdays[day]
+			if !ok {
+				// Ordinal forms such as 2TU are not supported
+				return []time.Time{start}
+			}
+			byDay = append(byDay, wd)
+		}
+	}
+
+	var next func(i int) []time.Time
+	switch parts["FREQ"] {
+	case "DAILY":
+		next = func(i int) []time.Time { return []time.Time{start.AddDate(0, 0, i*interval)} }
+	case "WEEKLY":
+		if len(byDay) == 0 {
+			next = func(i int) []time.Time { return []time.Time{start.AddDate(0, 0, 7*i*interval)} }
+			break
+		}
+		// Weeks start on Monday
+		offset := (int(start.Weekday()) + 6) % 7
+		weekStart := start.AddDate(0, 0, -offset)
+		next = func(i int) []time.Time {
+			week := weekStart.AddDate(0, 0, 7*i*interval)
+			days := []time.Time{}
+			for _, wd := range byDay {
+				day := week.AddDate(0, 0, (int(wd)+6)%7)
+				if !day.Before(start) {
+					days = append(days, day)
+				}
+			}
+			sort.Slice(days, func(a, b int) bool { return days[a].Before(days[b]) })
+			return days
+		}
+	case "MONTHLY":
+		if len(byDay) > 0 {
+			return []time.Time{start}
+		}
+		next = func(i int) []time.Time {
+			t := start.AddDate(0, i*interval, 0)
+			if t.Day() != start.Day() {
+				// Skip months without this day, as RFC 5545 requires
+				return nil
+			}
+			return []time.Time{t}
+		}
+	case "YEARLY":
+		if len(byDay) > 0 {
+			return []time.Time{start}
+		}
+		next = func(i int) []time.Time {
+			t := start.AddDate(i*interval, 0, 0)
+			if t.Day() != start.Day() {
+				return nil
+			}
+			return []time.Time{t}
+		}
+	default:
+		return []time.Time{start}
+	}
+
+	occurrences := []time.Time{}
+	found := 0
+	// Periods can be empty (the 31st in short months), so bound the
+	// periods tried as well as the occurrences found
+	for i := 0; i < 4*maxOccurrences && len(occurrences) < maxOccurrences; i++ {
+		batch := next(i)
+		done := false
+		for _, t := range batch {
+			if t.After(until) || (count > 0 && found >= count) {
+				done = true
+				break
+			}
+			found++
+			occurrences = append(occurrences, t)
+		}
+		if done {
+			break
+		}
+	}
+	return occurrences
+}
@@ -0,0 +1,96 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package capabilities
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/config"
+)
+
+// How an AI feature runs on this instance
+const (
+	ModeAI          = "ai"          // with the configured AI provider
+	ModeFallback    = "fallback"    // without AI, by its fallback
+	ModeUnavailable = "unavailable" // not at all
+)
+
+// AIFeature describes a feature that uses the AI provider, or stands in
+// for one, and what it does when no provider is configured
+type AIFeature struct {
+	Name string
+	// UsesAI is set for features that call the provider when one is
+	// configured. Features that are always rule-based register so clients
+	// know they work without AI.
+	UsesAI bool
+	// Fallback names what the feature does without AI, such as
+	// "curated_table"; empty when it is unavailable
+	Fallback    string
+	Description string // what the fallback gives, for clients to show
+}
+
+var (
+	aiFeaturesMu sync.RWMutex
+	aiFeatures   = map[string]AIFeature{}
+)
+
+// RegisterAIFeature adds a feature to the capability document. A feature
+// registered again under the same name replaces the earlier one.
+func RegisterAIFeature(feature AIFeature) {
+	aiFeaturesMu.Lock()
+	defer aiFeaturesMu.Unlock()
+	aiFeatures[feature.Name] = feature
+}
+
+// describeAIFeatures reports how each registered AI feature runs
+func describeAIFeatures(cfg *config.Config) gin.H {
+	aiFeaturesMu.RLock()
+	defer aiFeaturesMu.RUnlock()
+
+	names := make([]string, 0, len(aiFeatures))
+	for name := range aiFeatures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	features := gin.H{}
+	for _, name := range names {
+		feature := aiFeatures[name]
+		mode := ModeUnavailable
+		switch {
+		case feature.UsesAI && cfg.AI.Enabled():
+			mode = ModeAI
+		case feature.Fallback != "":
+			mode = ModeFallback
+		}
+		entry := gin.H{
+			"available": mode != ModeUnavailable,
+			"ai":        mode == ModeAI,
+			"mode":      mode,
+		}
+		if feature.Fallback != "" {
+			entry["fallback"] = feature.Fallback
+			entry["fallback_description"] = feature.Description
+		}
+		features[name] = entry
+	}
+	return features
+}
@@ -0,0 +1,62 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package capabilities
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/config"
+)
+
+func TestDescribeAIFeatures(t *testing.T) {
+	RegisterAIFeature(AIFeature{Name: "test_ai_table", UsesAI: true, Fallback: "table"})
+	RegisterAIFeature(AIFeature{Name: "test_ai_only", UsesAI: true})
+	RegisterAIFeature(AIFeature{Name: "test_rules", Fallback: "rules"})
+
+	withAI := &config.Config{}
+	withAI.AI.Ollama.Enabled = true
+	withAI.AI.Ollama.Host = "http://localhost:11434"
+
+	tests := []struct {
+		name    string
+		cfg     *config.Config
+		feature string
+		mode    string
+	}{
+		{"ai on, with fallback", withAI, "test_ai_table", ModeAI},
+		{"ai on, without fallback", withAI, "test_ai_only", ModeAI},
+		{"ai on, rule based", withAI, "test_rules", ModeFallback},
+		{"ai off, with fallback", &config.Config{}, "test_ai_table", ModeFallback},
+		{"ai off, without fallback", &config.Config{}, "test_ai_only", ModeUnavailable},
+		{"ai off, rule based", &config.Config{}, "test_rules", ModeFallback},
+	}
+	for _, tt := range tests {
+		entry, ok := describeAIFeatures(tt.cfg)[tt.feature].(gin.H)
+		if !ok {
+			t.Fatalf("%s: %s is not described", tt.name, tt.feature)
+		}
+		if entry["mode"] != tt.mode {
+			t.Errorf("%s: mode = %v, want %s", tt.name, entry["mode"], tt.mode)
+		}
+		if entry["available"] != (tt.mode != ModeUnavailable) || entry["ai"] != (tt.mode == ModeAI) {
+			t.Errorf("%s: available = %v, ai = %v for mode %s", tt.name, entry["available"], entry["ai"], tt.mode)
+		}
+	}
+}
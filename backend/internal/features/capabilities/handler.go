@@ -0,0 +1,94 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package capabilities
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/config"
+)
+
+// Handler advertises which optional capabilities this instance supports so
+// clients can hide or adapt features instead of failing on use
+type Handler struct {
+	live *config.Live
+}
+
+// NewHandler creates a new capabilities handler
+func NewHandler(live *config.Live) *Handler {
+	return &Handler{
+		live: live,
+	}
+}
+
+// RegisterRoutes registers capability routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("", h.GetCapabilities)
+}
+
+// GetCapabilities returns the instance capabilities
+// @Summary Get instance capabilities
+// @Tags capabilities
+// @Produce json
+// @Router /capabilities [get]
+func (h *Handler) GetCapabilities(c *gin.Context) {
+	c.JSON(http.StatusOK, Describe(h.live.Get()))
+}
+
+// Describe builds the capability document for a configuration
+func Describe(cfg *config.Config) gin.H {
+	providers := cfg.AI.EnabledProviders()
+	aiEnabled := len(providers) > 0
+
+	ai := gin.H{
+		"enabled":   aiEnabled,
+		"providers": providers,
+		"features":  describeAIFeatures(cfg),
+	}
+	if aiEnabled {
+		ai["default_provider"] = cfg.AI.ActiveProvider()
+	} else {
+		ai["reason"] = "no AI provider is configured; features fall back to non-AI behaviour"
+	}
+
+	return gin.H{
+		"ai":             ai,
+		"auth":           describeAuth(cfg),
+		"barcode_lookup": cfg.Foods.OpenFoodFacts.Enabled,
+		"email":          cfg.Mail.Enabled,
+		"read_aloud":     cfg.TTS.Enabled(),
+		"webhooks":       cfg.Webhooks.Enabled,
+	}
+}
+
+// describeAuth lists the login methods clients should offer
+func describeAuth(cfg *config.Config) gin.H {
+	oidc := gin.H{"enabled": cfg.Auth.OIDC.Enabled}
+	if cfg.Auth.OIDC.Enabled {
+		oidc["name"] = cfg.Auth.OIDC.Name
+		oidc["login_url"] = "/api/v1/auth/oidc/login"
+	}
+
+	return gin.H{
+		"password":            !(cfg.Auth.OIDC.Enabled && cfg.Auth.DisablePasswordLogin),
+		"oidc":                oidc,
+		"two_factor_required": cfg.Auth.RequireTwoFactor,
+	}
+}
@@ -0,0 +1,56 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cooking
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/events"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// AutoPause pauses active sessions that have made no progress for
+// cooking.autopause minutes: no step taken, no timer touched and none
+// still counting down. With cooking.nudge each one is announced with a
+// cooking_session_paused event, so the cook can be invited back or let it
+// go. Run by the scheduler.
+func (h *Handler) AutoPause(ctx context.Context) (string, error) {
+	now := time.Now()
+	idleSince := now.Add(-time.Duration(h.cfg.AutoPause) * time.Minute)
+	sessions, err := h.db.ListIdleCookingSessions(ctx, idleSince)
+	if err != nil {
+		return "", err
+	}
+
+	failed := 0
+	for _, session := range sessions {
+		Pause(session, now)
+		if err := h.db.UpdateCookingSession(ctx, session); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("session_id", session.ID).Msg("Failed to auto-pause cooking session")
+			failed++
+			continue
+		}
+		if h.cfg.Nudge {
+			h.events.Publish(ctx, events.NewCookingSessionPaused(session))
+		}
+	}
+	return fmt.Sprintf("paused %d idle cooking sessions, %d failed", len(sessions)-failed, failed), nil
+}
@@ -0,0 +1,222 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cooking
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/recipes"
+	"github.com/rghsoftware/space-food/internal/i18n"
+)
+
+// TimerDone is reported for a running timer whose end has passed
+const TimerDone = "done"
+
+// maxTimerSeconds bounds how long a single timer may run
+const maxTimerSeconds = 24 * 60 * 60
+
+// StepsFrom copies a recipe's method into the steps of a session, with
+// each step's sensory cues when the recipe's Sensory is set
+func StepsFrom(recipe *database.Recipe) []database.CookingStep {
+	steps := []database.CookingStep{}
+	for _, step := range recipes.CookSteps(recipe.Instructions) {
+		timers := make([]database.CookingStepTimer, 0, len(step.Timers))
+		for _, t := range step.Timers {
+			timers = append(timers, database.CookingStepTimer{Label: t.Label, Seconds: t.Seconds})
+		}
+		var cues []string
+		if recipe.Sensory != nil {
+			cues = recipes.StepSensoryCues(recipe, step.Number)
+		}
+		steps = append(steps, database.CookingStep{Text: step.Text, Timers: timers, Cues: cues})
+	}
+	return steps
+}
+
+// SuggestedTimer is a timer a step calls for that has not been started
+type SuggestedTimer struct {
+	StepIndex int    `json:"step_index"`
+	Label     string `json:"label"`
+	Seconds   int    `json:"seconds"`
+}
+
+// Suggestions lists the timers called for by the steps reached so far that
+// have not been started. A step's timer counts as started, or dismissed,
+// once the session has a timer of the same length for that step, whatever
+// its label or status.
+func Suggestions(session *database.CookingSession, timers []*database.CookingTimer) []SuggestedTimer {
+	started := map[[2]int]int{} // step index and seconds -> timers
+	for _, timer := range timers {
+		if timer.StepIndex != nil {
+			started[[2]int{*timer.StepIndex, timer.DurationSeconds}]++
+		}
+	}
+
+	suggestions := []SuggestedTimer{}
+	for i := 0; i <= session.CurrentStep && i < len(session.Steps); i++ {
+		for _, t := range session.Steps[i].Timers {
+			key := [2]int{i, t.Seconds}
+			if started[key] > 0 {
+				started[key]--
+				continue
+			}
+			suggestions = append(suggestions, SuggestedTimer{StepIndex: i, Label: t.Label, Seconds: t.Seconds})
+		}
+	}
+	return suggestions
+}
+
+// IsFinished reports whether a session has been completed or abandoned
+func IsFinished(session *database.CookingSession) bool {
+	return session.Status == database.CookingSessionCompleted || session.Status == database.CookingSessionAbandoned
+}
+
+// Pause pauses an active session
+func Pause(session *database.CookingSession, now time.Time) {
+	if session.Status != database.CookingSessionActive {
+		return
+	}
+	session.Status = database.CookingSessionPaused
+	session.PausedAt = &now
+	session.UpdatedAt = now
+}
+
+// Resume picks a paused session back up
+func Resume(session *database.CookingSession, now time.Time) {
+	if session.Status != database.CookingSessionPaused {
+		return
+	}
+	session.Status = database.CookingSessionActive
+	session.PausedAt = nil
+	session.UpdatedAt = now
+}
+
+// Finish ends a session as completed or abandoned
+func Finish(session *database.CookingSession, status string, now time.Time) {
+	session.Status = status
+	session.PausedAt = nil
+	session.FinishedAt = &now
+	session.UpdatedAt = now
+}
+
+// NewTimer starts a timer, optionally for a session and one of its steps.
+// A timer without a label is named after its length in the printer's
+// language.
+func NewTimer(p *i18n.Printer, userID string, sessionID *string, stepIndex *int, label string, seconds int, now time.Time) *database.CookingTimer {
+	label = strings.TrimSpace(label)
+	if label == "" {
+		label = p.T("cooking.timer_label", durationSpeech(p, seconds))
+	}
+	endsAt := now.Add(time.Duration(seconds) * time.Second).UTC()
+	return &database.CookingTimer{
+		ID:               uuid.New().String(),
+		UserID:           userID,
+		SessionID:        sessionID,
+		StepIndex:        stepIndex,
+		Label:            label,
+		DurationSeconds:  seconds,
+		Status:           database.CookingTimerRunning,
+		EndsAt:           &endsAt,
+		RemainingSeconds: seconds,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+}
+
+// TimerStatus reports a timer's status at now
+func TimerStatus(timer *database.CookingTimer, now time.Time) string {
+	if timer.Status == database.CookingTimerRunning && timer.EndsAt != nil && !now.Before(*timer.EndsAt) {
+		return TimerDone
+	}
+	return timer.Status
+}
+
+// Remaining returns the whole seconds a timer has left at now
+func Remaining(timer *database.CookingTimer, now time.Time) int {
+	switch timer.Status {
+	case database.CookingTimerRunning:
+		if timer.EndsAt == nil {
+			return 0
+		}
+		return max(int(math.Ceil(timer.EndsAt.Sub(now).Seconds())), 0)
+	case database.CookingTimerPaused:
+		return timer.RemainingSeconds
+	}
+	return 0
+}
+
+// PauseTimer stops a running timer, keeping the time it had left. It
+// reports whether the timer changed; finished timers are left alone.
+func PauseTimer(timer *database.CookingTimer, now time.Time) bool {
+	if TimerStatus(timer, now) != database.CookingTimerRunning {
+		return false
+	}
+	timer.RemainingSeconds = Remaining(timer, now)
+	timer.Status = database.CookingTimerPaused
+	timer.EndsAt = nil
+	timer.UpdatedAt = now
+	return true
+}
+
+// ResumeTimer restarts a paused timer from the time it had left
+func ResumeTimer(timer *database.CookingTimer, now time.Time) bool {
+	if timer.Status != database.CookingTimerPaused {
+		return false
+	}
+	endsAt := now.Add(time.Duration(timer.RemainingSeconds) * time.Second).UTC()
+	timer.Status = database.CookingTimerRunning
+	timer.EndsAt = &endsAt
+	timer.UpdatedAt = now
+	return true
+}
+
+// CancelTimer stops a timer for good. It reports whether the timer
+// changed; finished timers are left alone.
+func CancelTimer(timer *database.CookingTimer, now time.Time) bool {
+	switch TimerStatus(timer, now) {
+	case database.CookingTimerRunning, database.CookingTimerPaused:
+		timer.RemainingSeconds = Remaining(timer, now)
+		timer.Status = database.CookingTimerCancelled
+		timer.EndsAt = nil
+		timer.UpdatedAt = now
+		return true
+	}
+	return false
+}
+
+// durationSpeech says a number of seconds the way a person would, e.g.
+// "1 hour 30 minutes"
+func durationSpeech(p *i18n.Printer, seconds int) string {
+	hours, minutes, secs := seconds/3600, seconds%3600/60, seconds%60
+	parts := []string{}
+	if hours > 0 {
+		parts = append(parts, p.Plural("duration.hours", hours))
+	}
+	if minutes > 0 {
+		parts = append(parts, p.Plural("duration.minutes", minutes))
+	}
+	if secs > 0 || len(parts) == 0 {
+		parts = append(parts, p.Plural("duration.seconds", secs))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,532 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cooking
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/api/params"
+	"github.com/rghsoftware/space-food/internal/api/precondition"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/auth"
+	"github.com/rghsoftware/space-food/internal/authz"
+	"github.com/rghsoftware/space-food/internal/config"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/events"
+	"github.com/rghsoftware/space-food/internal/features/locale"
+	"github.com/rghsoftware/space-food/internal/features/mealtime"
+	"github.com/rghsoftware/space-food/internal/features/recipes"
+	"github.com/rghsoftware/space-food/internal/middleware"
+	"github.com/rghsoftware/space-food/internal/storage"
+)
+
+// Handler handles cooking assistant HTTP requests
+type Handler struct {
+	db            database.Database
+	store         storage.Provider
+	events        *events.Bus
+	cfg           config.CookingConfig
+	speech        Synthesizer          // nil when no speech provider is configured
+	handoff       auth.HandoffProvider // nil when the auth provider can't hand off sessions
+	publicURL     string
+	maxUploadSize int64
+}
+
+// NewHandler creates a new cooking assistant handler
+func NewHandler(cfg *config.Config, db database.Database, store storage.Provider, bus *events.Bus, authProvider auth.AuthProvider) *Handler {
+	h := &Handler{
+		db:            db,
+		store:         store,
+		events:        bus,
+		cfg:           cfg.Cooking,
+		speech:        NewSynthesizer(cfg.TTS),
+		publicURL:     strings.TrimRight(cfg.Server.PublicURL, "/"),
+		maxUploadSize: int64(max(cfg.Storage.MaxUploadSize, 1)) << 20,
+	}
+	h.handoff, _ = authProvider.(auth.HandoffProvider)
+	return h
+}
+
+// RegisterRoutes registers cooking assistant routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/sessions", h.ListSessions)
+	router.POST("/sessions", h.StartSession)
+	router.GET("/sessions/:id", h.GetSession)
+	router.PATCH("/sessions/:id", h.PatchSession)
+	router.POST("/sessions/:id/intent", h.HandleIntent)
+	router.GET("/sessions/:id/speech", h.GetSpeech)
+	router.POST("/sessions/:id/complete", h.CompleteSession)
+	router.POST("/sessions/:id/abandon", h.AbandonSession)
+	router.PUT("/sessions/:id/reflection", h.UpdateReflection)
+	router.POST("/sessions/:id/timers", h.CreateTimer)
+	router.POST("/sessions/:id/timers/from-step/:step_index", h.CreateStepTimers)
+	router.DELETE("/sessions/:id/timers/:timer", h.CancelSessionTimer)
+	if h.handoff != nil {
+		router.POST("/sessions/:id/handoff", h.CreateHandoff)
+	}
+}
+
+// timerResponse adds the computed status and time left to a stored timer
+type timerResponse struct {
+	*database.CookingTimer
+	Status           string `json:"status"`
+	RemainingSeconds int    `json:"remaining_seconds"`
+	RecipeTitle      string `json:"recipe_title,omitempty"` // the session's recipe, in the overview of all timers
+}
+
+func toTimerResponse(timer *database.CookingTimer, now time.Time) timerResponse {
+	return timerResponse{
+		CookingTimer:     timer,
+		Status:           TimerStatus(timer, now),
+		RemainingSeconds: Remaining(timer, now),
+	}
+}
+
+// sessionResponse adds the current step, the session's timers and the
+// step timers still waiting to be started to a stored session
+type sessionResponse struct {
+	*database.CookingSession
+	Version         string                `json:"version"` // send as If-Match to patch only this version
+	StepCount       int                   `json:"step_count"`
+	Step            *database.CookingStep `json:"step,omitempty"` // the current step
+	Timers          []timerResponse       `json:"timers"`
+	SuggestedTimers []SuggestedTimer      `json:"suggested_timers"`
+}
+
+func toSessionResponse(session *database.CookingSession, timers []*database.CookingTimer, now time.Time) sessionResponse {
+	resp := sessionResponse{
+		CookingSession: session,
+		Version:        precondition.Version(session.UpdatedAt),
+		StepCount:      len(session.Steps),
+		Timers:         []timerResponse{},
+	}
+	if session.CurrentStep >= 0 && session.CurrentStep < len(session.Steps) {
+		resp.Step = &session.Steps[session.CurrentStep]
+	}
+	for _, timer := range timers {
+		if timer.Status != database.CookingTimerCancelled {
+			resp.Timers = append(resp.Timers, toTimerResponse(timer, now))
+		}
+	}
+	resp.SuggestedTimers = []SuggestedTimer{}
+	if !IsFinished(session) {
+		resp.SuggestedTimers = Suggestions(session, timers)
+	}
+	return resp
+}
+
+// sessionTimers loads the timers of a session, including cancelled ones so
+// dismissed step timers are not suggested again
+func (h *Handler) sessionTimers(c *gin.Context, session *database.CookingSession) ([]*database.CookingTimer, bool) {
+	timers, err := h.db.ListCookingTimers(c.Request.Context(), database.CookingTimerFilter{
+		UserID:           session.UserID,
+		SessionID:        session.ID,
+		IncludeCancelled: true,
+	})
+	if err != nil {
+		apierror.Respond(c, err)
+		return nil, false
+	}
+	return timers, true
+}
+
+// respondSession writes a session with its timers
+func (h *Handler) respondSession(c *gin.Context, status int, session *database.CookingSession) {
+	timers, ok := h.sessionTimers(c, session)
+	if !ok {
+		return
+	}
+	c.JSON(status, toSessionResponse(session, timers, time.Now()))
+}
+
+// ListSessions lists the authenticated user's cooking sessions, most
+// recent first
+// @Summary List cooking sessions
+// @Tags cooking-assistant
+// @Produce json
+// @Param include_finished query bool false "Include completed and abandoned sessions"
+// @Router /cooking-assistant/sessions [get]
+func (h *Handler) ListSessions(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	query := params.Query(c)
+	includeFinished := query.Bool("include_finished")
+	if !query.Valid() {
+		return
+	}
+
+	sessions, err := h.db.ListCookingSessions(c.Request.Context(), database.CookingSessionFilter{
+		UserID:          user.ID,
+		IncludeFinished: includeFinished,
+	})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sessions)
+}
+
+// StartSession starts cooking a recipe from its first step. Any recipe the
+// user can view may be cooked.
+// @Summary Start cooking session
+// @Tags cooking-assistant
+// @Accept json
+// @Produce json
+// @Router /cooking-assistant/sessions [post]
+func (h *Handler) StartSession(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req struct {
+		RecipeID string `json:"recipe_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	recipe, err := h.db.GetRecipeByID(ctx, req.RecipeID)
+	if err != nil {
+		apierror.Lookup(c, err, "recipe not found")
+		return
+	}
+	if _, err := recipes.AddSensory(ctx, h.db, []*database.Recipe{recipe}); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	steps := StepsFrom(recipe)
+	if len(steps) == 0 {
+		apierror.BadRequest(c, "this recipe has no steps to cook from")
+		return
+	}
+
+	now := time.Now()
+	session := database.CookingSession{
+		ID:          uuid.New().String(),
+		UserID:      user.ID,
+		RecipeID:    &recipe.ID,
+		RecipeTitle: recipe.Title,
+		Status:      database.CookingSessionActive,
+		Steps:       steps,
+		StartedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := h.db.CreateCookingSession(ctx, &session); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toSessionResponse(&session, nil, now))
+}
+
+// GetSession returns a cooking session with its current step and timers
+// @Summary Get cooking session
+// @Tags cooking-assistant
+// @Produce json
+// @Param id path string true "Session ID"
+// @Router /cooking-assistant/sessions/{id} [get]
+func (h *Handler) GetSession(c *gin.Context) {
+	session, ok := h.ownedSession(c)
+	if !ok {
+		return
+	}
+	h.respondSession(c, http.StatusOK, session)
+}
+
+// CompleteSession marks a session as cooked, cancelling its timers. An
+// optional reflection on how it went, with a photo of the meal, is saved
+// with it, and servings eaten are logged as a meal.
+// @Summary Complete cooking session
+// @Tags cooking-assistant
+// @Accept json,mpfd
+// @Produce json
+// @Param id path string true "Session ID"
+// @Router /cooking-assistant/sessions/{id}/complete [post]
+func (h *Handler) CompleteSession(c *gin.Context) {
+	session, ok := h.ownedSession(c)
+	if !ok {
+		return
+	}
+	if IsFinished(session) {
+		apierror.Conflict(c, "this cooking session has already finished")
+		return
+	}
+
+	var req completeRequest
+	photo, ok := h.bindReflection(c, &req)
+	if !ok {
+		return
+	}
+	if req.MealType != "" && !mealtime.IsMealType(req.MealType) {
+		apierror.BadRequest(c, "invalid meal type")
+		return
+	}
+	timers, ok := h.sessionTimers(c, session)
+	if !ok {
+		return
+	}
+	photoURL, ok := h.savePhoto(c, session.UserID, photo)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	now := time.Now()
+	reflection := req.reflection()
+	reflecting := photoURL != "" || !reflection.empty()
+	if reflecting {
+		applyReflection(session, reflection, photoURL, now)
+	}
+	var log *database.NutritionLog
+	if req.Servings > 0 {
+		log = h.mealLog(ctx, session, req.Servings, req.MealType, now)
+	}
+
+	// Finish and log the meal in one step, so a retry never logs it twice
+	err := h.db.WithTx(ctx, func(ctx context.Context) error {
+		if err := h.finish(ctx, session, timers, database.CookingSessionCompleted, now); err != nil {
+			return err
+		}
+		if log != nil {
+			return h.db.CreateNutritionLog(ctx, log)
+		}
+		return nil
+	})
+	if err != nil {
+		h.removePhoto(ctx, photoURL)
+		apierror.Respond(c, err)
+		return
+	}
+	if log != nil {
+		h.events.Publish(ctx, events.NewMealLogged(log))
+	}
+	if reflecting {
+		h.reflected(ctx, session, "")
+	}
+	c.JSON(http.StatusOK, toSessionResponse(session, timers, now))
+}
+
+// AbandonSession stops a session without finishing it, cancelling its
+// timers
+// @Summary Abandon cooking session
+// @Tags cooking-assistant
+// @Produce json
+// @Param id path string true "Session ID"
+// @Router /cooking-assistant/sessions/{id}/abandon [post]
+func (h *Handler) AbandonSession(c *gin.Context) {
+	h.finishSession(c, database.CookingSessionAbandoned)
+}
+
+func (h *Handler) finishSession(c *gin.Context, status string) {
+	session, ok := h.ownedSession(c)
+	if !ok {
+		return
+	}
+	if IsFinished(session) {
+		apierror.Conflict(c, "this cooking session has already finished")
+		return
+	}
+	timers, ok := h.sessionTimers(c, session)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	if err := h.finish(c.Request.Context(), session, timers, status, now); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toSessionResponse(session, timers, now))
+}
+
+// finish ends a session and cancels the timers still counting down for it
+func (h *Handler) finish(ctx context.Context, session *database.CookingSession, timers []*database.CookingTimer, status string, now time.Time) error {
+	Finish(session, status, now)
+	for _, timer := range timers {
+		if CancelTimer(timer, now) {
+			if err := h.db.UpdateCookingTimer(ctx, timer); err != nil {
+				return err
+			}
+		}
+	}
+	return h.db.UpdateCookingSession(ctx, session)
+}
+
+// CreateTimer starts a timer in a cooking session
+// @Summary Start cooking timer
+// @Tags cooking-assistant
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Router /cooking-assistant/sessions/{id}/timers [post]
+func (h *Handler) CreateTimer(c *gin.Context) {
+	session, ok := h.ownedSession(c)
+	if !ok {
+		return
+	}
+	if IsFinished(session) {
+		apierror.Conflict(c, "this cooking session has already finished")
+		return
+	}
+
+	var req struct {
+		Label     string `json:"label" binding:"max=255"`
+		Seconds   int    `json:"seconds" binding:"required,gt=0,lte=86400"`
+		StepIndex *int   `json:"step_index" binding:"omitempty,gte=0"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+	if req.StepIndex != nil && *req.StepIndex >= len(session.Steps) {
+		apierror.BadRequest(c, "step_index is past the last step")
+		return
+	}
+
+	now := time.Now()
+	timer := NewTimer(locale.Printer(c, h.db, session.UserID), session.UserID, &session.ID, req.StepIndex, req.Label, req.Seconds, now)
+	if err := h.db.CreateCookingTimer(c.Request.Context(), timer); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, toTimerResponse(timer, now))
+}
+
+// CreateStepTimers starts every timer a step calls for in one go. Timers
+// of the step that are already counting down are not started twice.
+// @Summary Start a step's timers
+// @Tags cooking-assistant
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param step_index path int true "Step index, counted from 0"
+// @Router /cooking-assistant/sessions/{id}/timers/from-step/{step_index} [post]
+func (h *Handler) CreateStepTimers(c *gin.Context) {
+	session, ok := h.ownedSession(c)
+	if !ok {
+		return
+	}
+	if IsFinished(session) {
+		apierror.Conflict(c, "this cooking session has already finished")
+		return
+	}
+	stepIndex, err := strconv.Atoi(c.Param("step_index"))
+	if err != nil || stepIndex < 0 || stepIndex >= len(session.Steps) {
+		apierror.NotFound(c, "step not found")
+		return
+	}
+	timers, ok := h.sessionTimers(c, session)
+	if !ok {
+		return
+	}
+
+	running := map[int]int{} // seconds -> timers of this step counting down
+	now := time.Now()
+	for _, timer := range timers {
+		status := TimerStatus(timer, now)
+		if timer.StepIndex != nil && *timer.StepIndex == stepIndex &&
+			(status == database.CookingTimerRunning || status == database.CookingTimerPaused) {
+			running[timer.DurationSeconds]++
+		}
+	}
+
+	created := []timerResponse{}
+	p := locale.Printer(c, h.db, session.UserID)
+	for _, t := range session.Steps[stepIndex].Timers {
+		if running[t.Seconds] > 0 {
+			running[t.Seconds]--
+			continue
+		}
+		timer := NewTimer(p, session.UserID, &session.ID, &stepIndex, t.Label, t.Seconds, now)
+		if err := h.db.CreateCookingTimer(c.Request.Context(), timer); err != nil {
+			apierror.Respond(c, err)
+			return
+		}
+		created = append(created, toTimerResponse(timer, now))
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+// CancelSessionTimer cancels a timer in a cooking session
+// @Summary Cancel cooking timer
+// @Tags cooking-assistant
+// @Param id path string true "Session ID"
+// @Param timer path string true "Timer ID"
+// @Success 204
+// @Router /cooking-assistant/sessions/{id}/timers/{timer} [delete]
+func (h *Handler) CancelSessionTimer(c *gin.Context) {
+	session, ok := h.ownedSession(c)
+	if !ok {
+		return
+	}
+	timers, ok := h.sessionTimers(c, session)
+	if !ok {
+		return
+	}
+
+	for _, timer := range timers {
+		if timer.ID == c.Param("timer") {
+			if CancelTimer(timer, time.Now()) {
+				if err := h.db.UpdateCookingTimer(c.Request.Context(), timer); err != nil {
+					apierror.Respond(c, err)
+					return
+				}
+			}
+			c.Status(http.StatusNoContent)
+			return
+		}
+	}
+	apierror.NotFound(c, "timer not found")
+}
+
+// ownedSession loads the session in the path, checking it belongs to the
+// authenticated user
+func (h *Handler) ownedSession(c *gin.Context) (*database.CookingSession, bool) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return nil, false
+	}
+
+	session, err := h.db.GetCookingSessionByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierror.Lookup(c, err, "cooking session not found")
+		return nil, false
+	}
+
+	if !authz.Owns(user.ID, session.UserID) {
+		apierror.Forbidden(c, "forbidden")
+		return nil, false
+	}
+
+	return session, true
+}
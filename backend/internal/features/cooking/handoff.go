@@ -0,0 +1,169 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cooking
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/audit"
+	"github.com/rghsoftware/space-food/internal/auth"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// handoffTarget is what a handoff token issued for a cooking session opens
+const handoffTarget = "cooking_session:"
+
+// RegisterHandoffRoutes registers the public route that redeems handoff
+// codes. It belongs on the rate-limited auth group.
+func (h *Handler) RegisterHandoffRoutes(router *gin.RouterGroup) {
+	if h.handoff != nil {
+		router.POST("/handoff", h.RedeemHandoff)
+	}
+}
+
+// CreateHandoff issues a short-lived code for carrying a cooking session to
+// another device, such as a kitchen tablet. Apps show qr_data as a QR code;
+// scanning it on the other device signs in there and opens the session.
+// @Summary Hand a cooking session off to another device
+// @Tags cooking-assistant
+// @Produce json
+// @Param id path string true "Session ID"
+// @Router /cooking-assistant/sessions/{id}/handoff [post]
+func (h *Handler) CreateHandoff(c *gin.Context) {
+	session, ok := h.ownedSession(c)
+	if !ok {
+		return
+	}
+	if IsFinished(session) {
+		apierror.Conflict(c, "session is already "+session.Status)
+		return
+	}
+
+	token, expiresAt, err := h.handoff.CreateHandoff(c.Request.Context(), session.UserID, handoffTarget+session.ID)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	audit.Record(c, audit.Entry{
+		Action:     audit.HandoffCreated,
+		TargetType: "cooking_session",
+		TargetID:   session.ID,
+	})
+
+	// With a public URL the code is a link, so a device that hasn't been
+	// pointed at this server yet knows where to redeem it
+	qrData := token
+	if h.publicURL != "" {
+		qrData = h.publicURL + "/handoff/" + token
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"token":        token,
+		"qr_data":      qrData,
+		"expires_at":   expiresAt,
+		"session_id":   session.ID,
+		"current_step": session.CurrentStep,
+	})
+}
+
+// handoffResponse is a login response with the cooking session the handoff
+// opens
+type handoffResponse struct {
+	*auth.AuthResponse
+	CookingSession *sessionResponse `json:"cooking_session"` // null when the session was deleted
+}
+
+// RedeemHandoff signs in with a scanned handoff code and returns the
+// cooking session it was issued for, at the step it has reached now
+// @Summary Sign in with a handoff code
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Router /auth/handoff [post]
+func (h *Handler) RedeemHandoff(c *gin.Context) {
+	var req struct {
+		Token string `json:"token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+	// Accept the whole link as well as the bare token, for apps that
+	// scan qr_data and post it as it is
+	token := req.Token[strings.LastIndex(req.Token, "/")+1:]
+
+	ctx := c.Request.Context()
+	resp, target, err := h.handoff.RedeemHandoff(ctx, auth.HandoffLoginRequest{
+		Token:     token,
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	})
+	if errors.Is(err, auth.ErrInvalidHandoffToken) {
+		audit.Record(c, audit.Entry{Action: audit.LoginFailed, Details: map[string]any{"method": "handoff"}})
+		apierror.Abort(c, apierror.New(http.StatusGone, apierror.CodeGone, err.Error()))
+		return
+	}
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	audit.Record(c, audit.Entry{
+		Action:  audit.Login,
+		UserID:  resp.User.ID,
+		Details: map[string]any{"method": "handoff"},
+	})
+
+	// The device is signed in whatever happens next, as the code is used
+	// up; a session deleted in the meantime is just left out
+	out := handoffResponse{AuthResponse: resp}
+	if sessionID, ok := strings.CutPrefix(target, handoffTarget); ok {
+		view, err := h.loadSessionView(ctx, sessionID)
+		if err != nil && !apierror.IsNotFound(err) {
+			logger.Ctx(ctx).Error().Err(err).Str("session_id", sessionID).Msg("Failed to load handed-off cooking session")
+		}
+		out.CookingSession = view
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+// loadSessionView loads a session with its timers, as the session routes
+// answer with it
+func (h *Handler) loadSessionView(ctx context.Context, sessionID string) (*sessionResponse, error) {
+	session, err := h.db.GetCookingSessionByID(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	timers, err := h.db.ListCookingTimers(ctx, database.CookingTimerFilter{
+		UserID:           session.UserID,
+		SessionID:        session.ID,
+		IncludeCancelled: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	view := toSessionResponse(session, timers, time.Now())
+	return &view, nil
+}
@@ -0,0 +1,233 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cooking
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/locale"
+	"github.com/rghsoftware/space-food/internal/features/recipes"
+	"github.com/rghsoftware/space-food/internal/features/sensory"
+	"github.com/rghsoftware/space-food/internal/i18n"
+)
+
+// Intents a voice assistant or speech recognizer can send
+const (
+	IntentNextStep     = "next_step"
+	IntentPreviousStep = "previous_step"
+	IntentRepeatStep   = "repeat_step"
+	IntentGoToStep     = "go_to_step"
+	IntentStartTimer   = "start_timer"
+	IntentPause        = "pause"
+	IntentResume       = "resume"
+	IntentFinish       = "finish"
+)
+
+// intentRequest is a structured command. Minutes and Label go with
+// start_timer, Step (counted from 1) with go_to_step.
+type intentRequest struct {
+	Intent  string  `json:"intent" binding:"required,oneof=next_step previous_step repeat_step go_to_step start_timer pause resume finish"`
+	Minutes float64 `json:"minutes" binding:"omitempty,gt=0"`
+	Label   string  `json:"label" binding:"max=255"`
+	Step    int     `json:"step" binding:"omitempty,gte=1"`
+}
+
+// intentResponse carries the updated session and a sentence for the
+// client to speak
+type intentResponse struct {
+	Intent  string          `json:"intent"`
+	Speech  string          `json:"speech"`
+	Session sessionResponse `json:"session"`
+}
+
+// stepSpeech reads out the current step, e.g. "Step 2 of 5. Chop the onions.",
+// after a warning when the step is loud, smelly or spits hot oil and the
+// cook's sensory profile avoids that
+func stepSpeech(p *i18n.Printer, session *database.CookingSession, profile *database.SensoryProfile) string {
+	if session.CurrentStep < 0 || session.CurrentStep >= len(session.Steps) {
+		return p.T("cooking.no_steps")
+	}
+	step := session.Steps[session.CurrentStep]
+	speech := p.T("cooking.step", session.CurrentStep+1, len(session.Steps), step.Text)
+	if warning := recipes.StepWarning(p, profile, step.Text, step.Cues); warning != "" {
+		speech = warning + " " + speech
+	}
+	return speech
+}
+
+// stepTimerLabel names a spoken timer after the current step's timer of the
+// same length, so "set a timer for 20 minutes" while simmering is labelled
+// "Simmer for 20 minutes"
+func stepTimerLabel(session *database.CookingSession, seconds int) string {
+	if session.CurrentStep < 0 || session.CurrentStep >= len(session.Steps) {
+		return ""
+	}
+	for _, t := range session.Steps[session.CurrentStep].Timers {
+		if t.Seconds == seconds {
+			return t.Label
+		}
+	}
+	return ""
+}
+
+// HandleIntent applies a hands-free command to a session: moving between
+// steps, starting a timer, pausing and resuming, or finishing. Pausing
+// also pauses the session's running timers. Any step or timer command
+// picks a paused session back up.
+// @Summary Send cooking session intent
+// @Tags cooking-assistant
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Router /cooking-assistant/sessions/{id}/intent [post]
+func (h *Handler) HandleIntent(c *gin.Context) {
+	session, ok := h.ownedSession(c)
+	if !ok {
+		return
+	}
+	if IsFinished(session) {
+		apierror.Conflict(c, "this cooking session has already finished")
+		return
+	}
+
+	var req intentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	timers, ok := h.sessionTimers(c, session)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	now := time.Now()
+	p := locale.Printer(c, h.db, session.UserID)
+	profile := sensory.LoadProfile(ctx, h.db, session.UserID)
+	var speech string
+	var updated []*database.CookingTimer
+
+	switch req.Intent {
+	case IntentNextStep:
+		Resume(session, now)
+		if session.CurrentStep >= len(session.Steps)-1 {
+			speech = p.T("cooking.last_step")
+			break
+		}
+		session.CurrentStep++
+		speech = stepSpeech(p, session, profile)
+
+	case IntentPreviousStep:
+		Resume(session, now)
+		if session.CurrentStep == 0 {
+			speech = p.T("cooking.first_step", stepSpeech(p, session, profile))
+			break
+		}
+		session.CurrentStep--
+		speech = stepSpeech(p, session, profile)
+
+	case IntentRepeatStep:
+		Resume(session, now)
+		speech = stepSpeech(p, session, profile)
+
+	case IntentGoToStep:
+		if req.Step == 0 || req.Step > len(session.Steps) {
+			apierror.BadRequest(c, fmt.Sprintf("step must be between 1 and %d", len(session.Steps)))
+			return
+		}
+		Resume(session, now)
+		session.CurrentStep = req.Step - 1
+		speech = stepSpeech(p, session, profile)
+
+	case IntentStartTimer:
+		seconds := int(math.Round(req.Minutes * 60))
+		if seconds <= 0 || seconds > maxTimerSeconds {
+			apierror.BadRequest(c, "minutes must be more than 0 and at most 1440")
+			return
+		}
+		Resume(session, now)
+		step := session.CurrentStep
+		label := req.Label
+		if label == "" {
+			label = stepTimerLabel(session, seconds)
+		}
+		timer := NewTimer(p, session.UserID, &session.ID, &step, label, seconds, now)
+		if err := h.db.CreateCookingTimer(ctx, timer); err != nil {
+			apierror.Respond(c, err)
+			return
+		}
+		timers = append(timers, timer)
+		speech = p.T("cooking.timer_set", durationSpeech(p, seconds))
+
+	case IntentPause:
+		Pause(session, now)
+		for _, timer := range timers {
+			if PauseTimer(timer, now) {
+				updated = append(updated, timer)
+			}
+		}
+		speech = p.T("cooking.paused")
+
+	case IntentResume:
+		Resume(session, now)
+		for _, timer := range timers {
+			if ResumeTimer(timer, now) {
+				updated = append(updated, timer)
+			}
+		}
+		speech = p.T("cooking.welcome_back", stepSpeech(p, session, profile))
+
+	case IntentFinish:
+		if err := h.finish(c.Request.Context(), session, timers, database.CookingSessionCompleted, now); err != nil {
+			apierror.Respond(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, intentResponse{
+			Intent:  req.Intent,
+			Speech:  p.T("cooking.finished", session.RecipeTitle),
+			Session: toSessionResponse(session, timers, now),
+		})
+		return
+	}
+
+	for _, timer := range updated {
+		if err := h.db.UpdateCookingTimer(ctx, timer); err != nil {
+			apierror.Respond(c, err)
+			return
+		}
+	}
+	session.UpdatedAt = now
+	if err := h.db.UpdateCookingSession(ctx, session); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, intentResponse{
+		Intent:  req.Intent,
+		Speech:  speech,
+		Session: toSessionResponse(session, timers, now),
+	})
+}
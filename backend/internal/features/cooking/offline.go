@@ -0,0 +1,76 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cooking
+
+import (
+	"context"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// ApplyState moves an unfinished session to a step and status recorded by
+// a client while it was offline, and saves it. Its timers follow the status
+// as they do for the matching commands: pausing pauses them, resuming
+// resumes them and finishing cancels the ones still counting down.
+func ApplyState(ctx context.Context, db database.Database, session *database.CookingSession, step int, status string, now time.Time) error {
+	timers, err := db.ListCookingTimers(ctx, database.CookingTimerFilter{
+		UserID:    session.UserID,
+		SessionID: session.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	session.CurrentStep = step
+	session.UpdatedAt = now
+	var updated []*database.CookingTimer
+	switch status {
+	case database.CookingSessionCompleted, database.CookingSessionAbandoned:
+		Finish(session, status, now)
+		for _, timer := range timers {
+			if CancelTimer(timer, now) {
+				updated = append(updated, timer)
+			}
+		}
+	case database.CookingSessionPaused:
+		Pause(session, now)
+		for _, timer := range timers {
+			if PauseTimer(timer, now) {
+				updated = append(updated, timer)
+			}
+		}
+	case database.CookingSessionActive:
+		if session.Status == database.CookingSessionPaused {
+			Resume(session, now)
+			for _, timer := range timers {
+				if ResumeTimer(timer, now) {
+					updated = append(updated, timer)
+				}
+			}
+		}
+	}
+
+	for _, timer := range updated {
+		if err := db.UpdateCookingTimer(ctx, timer); err != nil {
+			return err
+		}
+	}
+	return db.UpdateCookingSession(ctx, session)
+}
@@ -0,0 +1,164 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cooking
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/api/patch"
+	"github.com/rghsoftware/space-food/internal/api/precondition"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// MaxNotesLength is the most characters a session's notes may hold
+const MaxNotesLength = 2000
+
+// sessionState is the part of a session a PATCH changes. Timers are keyed
+// by ID and only their status changes: set it to paused, running or
+// cancelled to pause, resume or cancel the timer, or remove the timer to
+// cancel it. Cancelled timers are left out.
+type sessionState struct {
+	CurrentStep int                   `json:"current_step"`
+	Notes       string                `json:"notes"`
+	Timers      map[string]timerState `json:"timers"`
+}
+
+type timerState struct {
+	Status string `json:"status"` // running, paused or done
+}
+
+func stateOf(session *database.CookingSession, timers []*database.CookingTimer, now time.Time) sessionState {
+	state := sessionState{
+		CurrentStep: session.CurrentStep,
+		Notes:       session.Notes,
+		Timers:      map[string]timerState{},
+	}
+	for _, timer := range timers {
+		if timer.Status != database.CookingTimerCancelled {
+			state.Timers[timer.ID] = timerState{Status: TimerStatus(timer, now)}
+		}
+	}
+	return state
+}
+
+// PatchSession changes a session's step, notes and timers with a JSON
+// Patch or merge patch, so two open tabs each change only what they mean
+// to. Send If-Match with the session's version, or a JSON Patch test, to
+// refuse the patch when the session has changed since.
+// @Summary Patch cooking session
+// @Tags cooking-assistant
+// @Accept application/json-patch+json,application/merge-patch+json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Router /cooking-assistant/sessions/{id} [patch]
+func (h *Handler) PatchSession(c *gin.Context) {
+	session, ok := h.ownedSession(c)
+	if !ok {
+		return
+	}
+	if IsFinished(session) {
+		apierror.Conflict(c, "this cooking session has already finished")
+		return
+	}
+	timers, ok := h.sessionTimers(c, session)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	current := stateOf(session, timers, now)
+	if !precondition.Check(c, session.UpdatedAt, time.Time{}, func() any { return current }) {
+		return
+	}
+	var patched sessionState
+	if !patch.Bind(c, precondition.Version(session.UpdatedAt), current, &patched) {
+		return
+	}
+
+	if patched.CurrentStep < 0 || patched.CurrentStep >= len(session.Steps) {
+		apierror.BadRequest(c, fmt.Sprintf("current_step must be between 0 and %d", len(session.Steps)-1))
+		return
+	}
+	if utf8.RuneCountInString(patched.Notes) > MaxNotesLength {
+		apierror.BadRequest(c, fmt.Sprintf("notes can be at most %d characters", MaxNotesLength))
+		return
+	}
+	for id := range patched.Timers {
+		if _, ok := current.Timers[id]; !ok {
+			apierror.BadRequest(c, fmt.Sprintf("timer %s not found", id))
+			return
+		}
+	}
+
+	var updated []*database.CookingTimer
+	for _, timer := range timers {
+		was, ok := current.Timers[timer.ID]
+		if !ok {
+			continue
+		}
+		want, kept := patched.Timers[timer.ID]
+		if !kept {
+			want.Status = database.CookingTimerCancelled
+		}
+		if want.Status == was.Status {
+			continue
+		}
+		changed := false
+		switch want.Status {
+		case database.CookingTimerRunning:
+			changed = ResumeTimer(timer, now)
+		case database.CookingTimerPaused:
+			changed = PauseTimer(timer, now)
+		case database.CookingTimerCancelled:
+			changed = CancelTimer(timer, now)
+		}
+		if !changed {
+			apierror.BadRequest(c, fmt.Sprintf("timer %s can't go from %s to %s", timer.ID, was.Status, want.Status))
+			return
+		}
+		updated = append(updated, timer)
+	}
+
+	if patched.CurrentStep != session.CurrentStep || patched.Notes != session.Notes || len(updated) > 0 {
+		session.CurrentStep = patched.CurrentStep
+		session.Notes = patched.Notes
+		session.UpdatedAt = now
+
+		ctx := c.Request.Context()
+		err := h.db.WithTx(ctx, func(ctx context.Context) error {
+			for _, timer := range updated {
+				if err := h.db.UpdateCookingTimer(ctx, timer); err != nil {
+					return err
+				}
+			}
+			return h.db.UpdateCookingSession(ctx, session)
+		})
+		if err != nil {
+			apierror.Respond(c, err)
+			return
+		}
+	}
+	c.JSON(http.StatusOK, toSessionResponse(session, timers, now))
+}
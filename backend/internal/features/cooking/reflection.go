@@ -0,0 +1,325 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cooking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/images"
+	"github.com/rghsoftware/space-food/internal/middleware"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// reflectionRequest is how a completed session went. It is sent as JSON,
+// or as a multipart form when it comes with a photo of the meal in the
+// photo field.
+type reflectionRequest struct {
+	Tasted         string `json:"tasted" form:"tasted" binding:"max=2000"`
+	WouldMakeAgain *int   `json:"would_make_again" form:"would_make_again" binding:"omitempty,min=1,max=5"`
+	Difficulty     *int   `json:"difficulty" form:"difficulty" binding:"omitempty,min=1,max=5"`
+	ActualMinutes  *int   `json:"actual_minutes" form:"actual_minutes" binding:"omitempty,gt=0,lte=1440"`
+	RemovePhoto    bool   `json:"remove_photo" form:"remove_photo"`
+}
+
+// completeRequest is the optional body of CompleteSession: a reflection,
+// and the servings eaten to log as a meal
+type completeRequest struct {
+	Tasted         string  `json:"tasted" form:"tasted" binding:"max=2000"`
+	WouldMakeAgain *int    `json:"would_make_again" form:"would_make_again" binding:"omitempty,min=1,max=5"`
+	Difficulty     *int    `json:"difficulty" form:"difficulty" binding:"omitempty,min=1,max=5"`
+	ActualMinutes  *int    `json:"actual_minutes" form:"actual_minutes" binding:"omitempty,gt=0,lte=1440"`
+	Servings       float64 `json:"servings" form:"servings" binding:"omitempty,gt=0,lte=100"`
+	MealType       string  `json:"meal_type" form:"meal_type"`
+}
+
+// reflection is the part of a completion that says how it went
+func (r *completeRequest) reflection() *reflectionRequest {
+	return &reflectionRequest{
+		Tasted:         r.Tasted,
+		WouldMakeAgain: r.WouldMakeAgain,
+		Difficulty:     r.Difficulty,
+		ActualMinutes:  r.ActualMinutes,
+	}
+}
+
+// empty reports whether a request says nothing about how the session went
+func (r *reflectionRequest) empty() bool {
+	return strings.TrimSpace(r.Tasted) == "" && r.WouldMakeAgain == nil && r.Difficulty == nil && r.ActualMinutes == nil
+}
+
+// RegisterRecipeRoutes registers a recipe's cooking history
+func (h *Handler) RegisterRecipeRoutes(router *gin.RouterGroup) {
+	router.GET("/:id/cooks", h.ListRecipeCooks)
+}
+
+// recipeCooks is the viewer's cooking history of a recipe
+type recipeCooks struct {
+	RecipeID       string                     `json:"recipe_id"`
+	Rating         float64                    `json:"rating"` // the average would make again score of everyone who reflected
+	TimesCooked    int                        `json:"times_cooked"`
+	AverageMinutes *int                       `json:"average_minutes,omitempty"` // of the cooks that recorded their time
+	Cooks          []*database.CookingSession `json:"cooks"`                     // completed sessions, most recent first
+}
+
+// bindReflection reads a reflection from a JSON body or a multipart form,
+// returning the uploaded photo, if any
+func (h *Handler) bindReflection(c *gin.Context, req any) ([]byte, bool) {
+	// Leave room for the multipart framing around the photo
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadSize+64<<10)
+	if err := c.ShouldBind(req); err != nil && !errors.Is(err, io.EOF) {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.photoTooLarge(c)
+			return nil, false
+		}
+		apierror.Invalid(c, err)
+		return nil, false
+	}
+	if !strings.HasPrefix(c.ContentType(), "multipart/") {
+		return nil, true
+	}
+
+	header, err := c.FormFile("photo")
+	if errors.Is(err, http.ErrMissingFile) {
+		return nil, true
+	}
+	if err != nil {
+		apierror.BadRequest(c, "the photo could not be read")
+		return nil, false
+	}
+	if header.Size > h.maxUploadSize {
+		h.photoTooLarge(c)
+		return nil, false
+	}
+	file, err := header.Open()
+	if err != nil {
+		apierror.Internal(c, err)
+		return nil, false
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		apierror.Internal(c, err)
+		return nil, false
+	}
+	return data, true
+}
+
+// photoTooLarge responds that an uploaded photo is over the size limit
+func (h *Handler) photoTooLarge(c *gin.Context) {
+	apierror.Abort(c, apierror.New(http.StatusRequestEntityTooLarge, apierror.CodePayloadTooLarge,
+		fmt.Sprintf("photos can be at most %d MB", h.maxUploadSize>>20)))
+}
+
+// savePhoto stores a meal photo like a recipe image, in several sizes with
+// its metadata removed. It returns an empty URL when there is no photo.
+func (h *Handler) savePhoto(c *gin.Context, userID string, data []byte) (string, bool) {
+	if data == nil {
+		return "", true
+	}
+	photoURL, err := images.Save(c.Request.Context(), h.store, userID, data)
+	switch {
+	case errors.Is(err, images.ErrUnsupported):
+		apierror.Abort(c, apierror.New(http.StatusUnsupportedMediaType, apierror.CodeUnsupportedMedia, err.Error()))
+		return "", false
+	case errors.Is(err, images.ErrTooLarge):
+		apierror.Abort(c, apierror.New(http.StatusRequestEntityTooLarge, apierror.CodePayloadTooLarge, err.Error()))
+		return "", false
+	case err != nil:
+		apierror.Internal(c, err)
+		return "", false
+	}
+	return photoURL, true
+}
+
+// removePhoto deletes a stored meal photo, logging failures
+func (h *Handler) removePhoto(ctx context.Context, photoURL string) {
+	if photoURL == "" {
+		return
+	}
+	if err := images.Remove(ctx, h.store, photoURL); err != nil {
+		logger.Ctx(ctx).Warn().Err(err).Str("photo_url", photoURL).Msg("Failed to remove cooking photo")
+	}
+}
+
+// applyReflection replaces a session's reflection. The photo is kept
+// unless a new one was uploaded or it is removed. It returns the URL of a
+// photo that was replaced, to delete once the session is saved.
+func applyReflection(session *database.CookingSession, req *reflectionRequest, photoURL string, now time.Time) string {
+	reflection := &database.CookingReflection{
+		Tasted:         strings.TrimSpace(req.Tasted),
+		WouldMakeAgain: req.WouldMakeAgain,
+		Difficulty:     req.Difficulty,
+		ActualMinutes:  req.ActualMinutes,
+		ReflectedAt:    now,
+	}
+	var replaced string
+	if session.Reflection != nil {
+		reflection.PhotoURL = session.Reflection.PhotoURL
+	}
+	if photoURL != "" || req.RemovePhoto {
+		replaced = reflection.PhotoURL
+		reflection.PhotoURL = photoURL
+	}
+	session.Reflection = reflection
+	session.UpdatedAt = now
+	return replaced
+}
+
+// reflected tidies up after a reflection is saved: the photo it replaced
+// is deleted and the recipe's rating takes in the new score
+func (h *Handler) reflected(ctx context.Context, session *database.CookingSession, replacedPhoto string) {
+	h.removePhoto(ctx, replacedPhoto)
+	if session.RecipeID == nil {
+		return
+	}
+	if err := h.db.RefreshRecipeRating(ctx, *session.RecipeID); err != nil {
+		logger.Ctx(ctx).Warn().Err(err).Str("recipe_id", *session.RecipeID).Msg("Failed to refresh recipe rating")
+	}
+}
+
+// mealLog builds the nutrition log for servings eaten of a cooked session,
+// using the recipe's nutrition when it is still there
+func (h *Handler) mealLog(ctx context.Context, session *database.CookingSession, servings float64, mealType string, now time.Time) *database.NutritionLog {
+	log := &database.NutritionLog{
+		ID:        uuid.New().String(),
+		UserID:    session.UserID,
+		Date:      now,
+		MealType:  mealType,
+		RecipeID:  session.RecipeID,
+		FoodName:  session.RecipeTitle,
+		Servings:  servings,
+		CreatedAt: now,
+	}
+	if session.RecipeID != nil {
+		if recipe, err := h.db.GetRecipeByID(ctx, *session.RecipeID); err == nil && recipe.NutritionInfo != nil {
+			log.NutritionInfo = *recipe.NutritionInfo
+		}
+	}
+	return log
+}
+
+// UpdateReflection replaces the reflection on a completed session, for
+// cooks who finished by voice or want to add a photo once they have eaten
+// @Summary Update cooking reflection
+// @Tags cooking-assistant
+// @Accept json,mpfd
+// @Produce json
+// @Param id path string true "Session ID"
+// @Router /cooking-assistant/sessions/{id}/reflection [put]
+func (h *Handler) UpdateReflection(c *gin.Context) {
+	session, ok := h.ownedSession(c)
+	if !ok {
+		return
+	}
+	if session.Status != database.CookingSessionCompleted {
+		apierror.Conflict(c, "only a completed cooking session can be reflected on")
+		return
+	}
+
+	var req reflectionRequest
+	photo, ok := h.bindReflection(c, &req)
+	if !ok {
+		return
+	}
+	photoURL, ok := h.savePhoto(c, session.UserID, photo)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	replaced := applyReflection(session, &req, photoURL, time.Now())
+	if err := h.db.UpdateCookingSession(ctx, session); err != nil {
+		h.removePhoto(ctx, photoURL)
+		apierror.Respond(c, err)
+		return
+	}
+	h.reflected(ctx, session, replaced)
+	h.respondSession(c, http.StatusOK, session)
+}
+
+// ListRecipeCooks returns the viewer's completed cooking sessions of a
+// recipe with their reflections
+// @Summary Recipe cooking history
+// @Tags cooking-assistant
+// @Produce json
+// @Param id path string true "Recipe ID"
+// @Success 200 {object} recipeCooks
+// @Router /recipes/{id}/cooks [get]
+func (h *Handler) ListRecipeCooks(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	ctx := c.Request.Context()
+	recipe, err := h.db.GetRecipeByID(ctx, c.Param("id"))
+	if err != nil {
+		apierror.Lookup(c, err, "recipe not found")
+		return
+	}
+	sessions, err := h.db.ListCookingSessions(ctx, database.CookingSessionFilter{
+		UserID:          user.ID,
+		RecipeID:        recipe.ID,
+		IncludeFinished: true,
+	})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	history := recipeCooks{
+		RecipeID: recipe.ID,
+		Rating:   recipe.Rating,
+		Cooks:    []*database.CookingSession{},
+	}
+	minutes, timed := 0, 0
+	for _, session := range sessions {
+		if session.Status != database.CookingSessionCompleted {
+			continue
+		}
+		history.Cooks = append(history.Cooks, session)
+		if session.Reflection != nil && session.Reflection.ActualMinutes != nil {
+			minutes += *session.Reflection.ActualMinutes
+			timed++
+		}
+	}
+	// Reflecting later touches a session, so order by when it was cooked
+	sort.SliceStable(history.Cooks, func(i, j int) bool {
+		return history.Cooks[i].FinishedAt.After(*history.Cooks[j].FinishedAt)
+	})
+	history.TimesCooked = len(history.Cooks)
+	if timed > 0 {
+		average := (minutes + timed/2) / timed
+		history.AverageMinutes = &average
+	}
+	c.JSON(http.StatusOK, history)
+}
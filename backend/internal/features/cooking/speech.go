@@ -0,0 +1,237 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cooking
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/api/params"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/config"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/storage"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// maxSpeechSize bounds the audio read back from a speech provider
+const maxSpeechSize = 20 << 20
+
+// Synthesizer turns text into speech
+type Synthesizer interface {
+	// Synthesize returns audio of text being read aloud
+	Synthesize(ctx context.Context, text string) ([]byte, error)
+	// ContentType is the MIME type of the audio returned
+	ContentType() string
+	// Voice identifies the provider, model and voice, so cached audio is
+	// not reused after they change
+	Voice() string
+}
+
+// NewSynthesizer creates the configured speech provider, or nil when none
+// is usable
+func NewSynthesizer(cfg config.TTSConfig) Synthesizer {
+	if !cfg.Enabled() {
+		return nil
+	}
+	client := &http.Client{Timeout: time.Duration(cfg.Timeout) * time.Second}
+	switch cfg.Provider {
+	case "piper":
+		return &piperSynthesizer{url: cfg.Piper.URL, voice: cfg.Piper.Voice, client: client}
+	case "openai":
+		return &openAISynthesizer{
+			baseURL: strings.TrimRight(cfg.OpenAI.BaseURL, "/"),
+			apiKey:  cfg.OpenAI.APIKey,
+			model:   cfg.OpenAI.Model,
+			voice:   cfg.OpenAI.Voice,
+			client:  client,
+		}
+	}
+	return nil
+}
+
+// piperSynthesizer reads text with a self-hosted Piper HTTP server
+type piperSynthesizer struct {
+	url    string
+	voice  string
+	client *http.Client
+}
+
+func (p *piperSynthesizer) Synthesize(ctx context.Context, text string) ([]byte, error) {
+	body := map[string]string{"text": text}
+	if p.voice != "" {
+		body["voice"] = p.voice
+	}
+	return postSpeech(ctx, p.client, p.url, "", body)
+}
+
+func (p *piperSynthesizer) ContentType() string { return "audio/wav" }
+
+func (p *piperSynthesizer) Voice() string { return "piper/" + p.voice }
+
+// openAISynthesizer reads text with OpenAI's speech API
+type openAISynthesizer struct {
+	baseURL string
+	apiKey  string
+	model   string
+	voice   string
+	client  *http.Client
+}
+
+func (o *openAISynthesizer) Synthesize(ctx context.Context, text string) ([]byte, error) {
+	return postSpeech(ctx, o.client, o.baseURL+"/audio/speech", o.apiKey, map[string]string{
+		"model":           o.model,
+		"voice":           o.voice,
+		"input":           text,
+		"response_format": "mp3",
+	})
+}
+
+func (o *openAISynthesizer) ContentType() string { return "audio/mpeg" }
+
+func (o *openAISynthesizer) Voice() string { return "openai/" + o.model + "/" + o.voice }
+
+// postSpeech sends a JSON request to a speech provider and returns the
+// audio it answers with
+func postSpeech(ctx context.Context, client *http.Client, url, apiKey string, body any) ([]byte, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("speech provider returned status %d", resp.StatusCode)
+	}
+	audio, err := io.ReadAll(io.LimitReader(resp.Body, maxSpeechSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(audio) > maxSpeechSize {
+		return nil, errors.New("speech provider returned too much audio")
+	}
+	return audio, nil
+}
+
+// stepSSML marks up a step for speech engines that read SSML, pausing
+// after the step number
+func stepSSML(session *database.CookingSession, index int) string {
+	var text bytes.Buffer
+	_ = xml.EscapeText(&text, []byte(session.Steps[index].Text))
+	return fmt.Sprintf(`<speak><s>Step %d of %d.</s><break time="500ms"/><s>%s</s></speak>`,
+		index+1, len(session.Steps), text.String())
+}
+
+// speechKey is where audio of text is cached. Keys are derived from the
+// voice and text alone, so the same step is only synthesized once.
+func speechKey(synth Synthesizer, text string) string {
+	sum := sha256.Sum256([]byte(synth.Voice() + "\n" + text))
+	ext := "wav"
+	if synth.ContentType() == "audio/mpeg" {
+		ext = "mp3"
+	}
+	return "speech/" + hex.EncodeToString(sum[:]) + "." + ext
+}
+
+// GetSpeech reads a step of a session aloud: audio from the configured
+// speech provider, cached per step, or SSML for clients with their own
+// speech engine
+// @Summary Read cooking step aloud
+// @Tags cooking-assistant
+// @Produce audio/wav,audio/mpeg,application/ssml+xml
+// @Param id path string true "Session ID"
+// @Param step query int false "Step number, counted from 1; the current step by default"
+// @Param format query string false "audio (the default when a speech provider is configured) or ssml"
+// @Router /cooking-assistant/sessions/{id}/speech [get]
+func (h *Handler) GetSpeech(c *gin.Context) {
+	session, ok := h.ownedSession(c)
+	if !ok {
+		return
+	}
+
+	query := params.Query(c)
+	step := query.Int("step", session.CurrentStep+1, 1, len(session.Steps))
+	format := query.String("format", "audio", "ssml")
+	if !query.Valid() {
+		return
+	}
+	if format == "" {
+		format = "ssml"
+		if h.speech != nil {
+			format = "audio"
+		}
+	}
+
+	index := step - 1
+	if format == "ssml" {
+		c.Data(http.StatusOK, "application/ssml+xml; charset=utf-8", []byte(stepSSML(session, index)))
+		return
+	}
+	if h.speech == nil {
+		apierror.Abort(c, apierror.New(http.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "text-to-speech is not configured on this instance; use format=ssml"))
+		return
+	}
+
+	ctx := c.Request.Context()
+	text := fmt.Sprintf("Step %d of %d. %s", step, len(session.Steps), session.Steps[index].Text)
+	key := speechKey(h.speech, text)
+	headers := map[string]string{"Cache-Control": "private, max-age=86400"}
+
+	if file, err := h.store.Open(ctx, key); err == nil {
+		defer file.Close()
+		c.DataFromReader(http.StatusOK, -1, h.speech.ContentType(), file, headers)
+		return
+	} else if !errors.Is(err, storage.ErrNotFound) {
+		logger.Ctx(ctx).Warn().Err(err).Str("key", key).Msg("Failed to read cached speech")
+	}
+
+	audio, err := h.speech.Synthesize(ctx, text)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Msg("Speech synthesis failed")
+		apierror.Upstream(c, "speech synthesis failed")
+		return
+	}
+	if err := h.store.Put(ctx, key, bytes.NewReader(audio), int64(len(audio)), h.speech.ContentType()); err != nil {
+		logger.Ctx(ctx).Warn().Err(err).Str("key", key).Msg("Failed to cache speech")
+	}
+	c.DataFromReader(http.StatusOK, int64(len(audio)), h.speech.ContentType(), bytes.NewReader(audio), headers)
+}
@@ -0,0 +1,188 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cooking
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/authz"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/locale"
+	"github.com/rghsoftware/space-food/internal/middleware"
+)
+
+// RegisterTimerRoutes registers the routes for all of a user's timers,
+// in sessions or standalone
+func (h *Handler) RegisterTimerRoutes(router *gin.RouterGroup) {
+	router.GET("", h.ListTimers)
+	router.POST("", h.CreateStandaloneTimer)
+	router.POST("/:id/pause", h.PauseTimer)
+	router.POST("/:id/resume", h.ResumeTimer)
+	router.DELETE("/:id", h.CancelTimer)
+}
+
+// ListTimers lists every timer of the authenticated user that is running
+// or paused, across cooking sessions and standalone timers, soonest
+// started first. Remaining time is worked out by the server, so a
+// dashboard can show them all without trusting its own clock.
+// @Summary List my timers
+// @Tags cooking-assistant
+// @Produce json
+// @Router /me/timers [get]
+func (h *Handler) ListTimers(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	ctx := c.Request.Context()
+	now := time.Now()
+	endsAfter := now.UTC()
+	timers, err := h.db.ListCookingTimers(ctx, database.CookingTimerFilter{
+		UserID:    user.ID,
+		EndsAfter: &endsAfter,
+	})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	sessions, err := h.db.ListCookingSessions(ctx, database.CookingSessionFilter{UserID: user.ID})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	titles := map[string]string{}
+	for _, session := range sessions {
+		titles[session.ID] = session.RecipeTitle
+	}
+
+	resp := make([]timerResponse, 0, len(timers))
+	for _, timer := range timers {
+		t := toTimerResponse(timer, now)
+		if timer.SessionID != nil {
+			t.RecipeTitle = titles[*timer.SessionID]
+		}
+		resp = append(resp, t)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// CreateStandaloneTimer starts a timer that belongs to no cooking session,
+// for the tea, the laundry or checking the oven
+// @Summary Start standalone timer
+// @Tags cooking-assistant
+// @Accept json
+// @Produce json
+// @Router /me/timers [post]
+func (h *Handler) CreateStandaloneTimer(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req struct {
+		Label   string `json:"label" binding:"required,max=255"`
+		Seconds int    `json:"seconds" binding:"required,gt=0,lte=86400"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	now := time.Now()
+	timer := NewTimer(locale.Printer(c, h.db, user.ID), user.ID, nil, nil, req.Label, req.Seconds, now)
+	if err := h.db.CreateCookingTimer(c.Request.Context(), timer); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, toTimerResponse(timer, now))
+}
+
+// PauseTimer pauses one of the authenticated user's timers
+// @Summary Pause timer
+// @Tags cooking-assistant
+// @Produce json
+// @Param id path string true "Timer ID"
+// @Router /me/timers/{id}/pause [post]
+func (h *Handler) PauseTimer(c *gin.Context) {
+	if timer := h.changeTimer(c, PauseTimer, "only a running timer can be paused"); timer != nil {
+		c.JSON(http.StatusOK, toTimerResponse(timer, time.Now()))
+	}
+}
+
+// ResumeTimer restarts one of the authenticated user's paused timers
+// @Summary Resume timer
+// @Tags cooking-assistant
+// @Produce json
+// @Param id path string true "Timer ID"
+// @Router /me/timers/{id}/resume [post]
+func (h *Handler) ResumeTimer(c *gin.Context) {
+	if timer := h.changeTimer(c, ResumeTimer, "only a paused timer can be resumed"); timer != nil {
+		c.JSON(http.StatusOK, toTimerResponse(timer, time.Now()))
+	}
+}
+
+// CancelTimer cancels one of the authenticated user's timers
+// @Summary Cancel timer
+// @Tags cooking-assistant
+// @Param id path string true "Timer ID"
+// @Success 204
+// @Router /me/timers/{id} [delete]
+func (h *Handler) CancelTimer(c *gin.Context) {
+	if timer := h.changeTimer(c, CancelTimer, "this timer has already finished or been cancelled"); timer != nil {
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// changeTimer applies change to the timer in the path and saves it,
+// answering 409 with conflict when the change does not apply to the
+// timer's current status. It returns nil once it has responded.
+func (h *Handler) changeTimer(c *gin.Context, change func(*database.CookingTimer, time.Time) bool, conflict string) *database.CookingTimer {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return nil
+	}
+
+	ctx := c.Request.Context()
+	timer, err := h.db.GetCookingTimerByID(ctx, c.Param("id"))
+	if err != nil {
+		apierror.Lookup(c, err, "timer not found")
+		return nil
+	}
+	if !authz.Owns(user.ID, timer.UserID) {
+		apierror.Forbidden(c, "forbidden")
+		return nil
+	}
+
+	if !change(timer, time.Now()) {
+		apierror.Conflict(c, conflict)
+		return nil
+	}
+	if err := h.db.UpdateCookingTimer(ctx, timer); err != nil {
+		apierror.Respond(c, err)
+		return nil
+	}
+	return timer
+}
@@ -0,0 +1,342 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package dietary
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/capabilities"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+func init() {
+	capabilities.RegisterAIFeature(capabilities.AIFeature{
+		Name:        "dietary_tags",
+		Fallback:    "ingredient_rules",
+		Description: "allergens and diets are detected by matching ingredients against a catalog",
+	})
+}
+
+// AnalyzerVersion is bumped when detection changes, so recipes analyzed
+// by an older version are analyzed again when next viewed
+const AnalyzerVersion = 1
+
+// Dietary tag kinds
+const (
+	TagAllergen = "allergen"
+	TagDiet     = "diet"
+)
+
+// allergens are the catalog groups detected as allergens
+var allergens = []string{"dairy", "eggs", "fish", "gluten", "peanuts", "sesame", "shellfish", "soy", "tree nuts"}
+
+// diets are the diets a recipe is checked against, with the catalog groups
+// each excludes
+var diets = map[string][]string{
+	"vegetarian": {"meat", "fish", "shellfish"},
+	"vegan":      {"meat", "fish", "shellfish", "dairy", "eggs", "honey"},
+}
+
+// uncertain are terms that often mean the allergen or animal product but
+// have common free-from versions, such as gluten-free pasta or vegetable
+// stock. Matches on them lower the confidence.
+var uncertain = map[string][]string{
+	"gluten":     {"flour", "bread", "breadcrumb", "pasta", "spaghetti", "noodle", "cracker", "tortilla", "malt", "soy sauce"},
+	"vegetarian": {"stock", "broth", "bouillon", "worcestershire", "parmesan", "pecorino", "pesto", "caesar"},
+	"vegan":      {"stock", "broth", "bouillon", "worcestershire", "pesto", "caesar", "chocolate", "margarine", "wine"},
+}
+
+// Confidence levels of detection
+const (
+	confidenceClear     = 0.9 // a plain match, or nothing excluded
+	confidenceUncertain = 0.6 // only matches on uncertain terms
+	optionalFactor      = 0.7 // only found in optional ingredients
+)
+
+// IsTag reports the kind of a dietary tag name, and whether it is one
+func IsTag(name string) (string, bool) {
+	for _, allergen := range allergens {
+		if allergen == name {
+			return TagAllergen, true
+		}
+	}
+	if _, ok := diets[name]; ok {
+		return TagDiet, true
+	}
+	return "", false
+}
+
+// KnownTags lists the allergens and diets recipes are tagged with
+func KnownTags() map[string][]string {
+	names := make([]string, 0, len(diets))
+	for diet := range diets {
+		names = append(names, diet)
+	}
+	sort.Strings(names)
+	return map[string][]string{TagAllergen: allergens, TagDiet: names}
+}
+
+// Analyze works out from a recipe's ingredients the allergens it likely
+// contains and the diets it likely suits. Recipes without ingredients get
+// no tags.
+func Analyze(recipe *database.Recipe) []*database.RecipeDietaryTag {
+	tags := []*database.RecipeDietaryTag{}
+	if len(recipe.Ingredients) == 0 {
+		return tags
+	}
+
+	for _, allergen := range allergens {
+		var required, optional []string
+		sure := false
+		for _, ingredient := range recipe.Ingredients {
+			if !groupMatches(groups[allergen], ingredient.Name) {
+				continue
+			}
+			if ingredient.Optional {
+				optional = append(optional, ingredient.Name)
+				continue
+			}
+			required = append(required, ingredient.Name)
+			if !onlyUncertain(allergen, ingredient.Name) {
+				sure = true
+			}
+		}
+		if len(required)+len(optional) == 0 {
+			continue
+		}
+		confidence := confidenceClear
+		if !sure {
+			confidence = confidenceUncertain
+		}
+		evidence := required
+		if len(required) == 0 {
+			evidence = optional
+			confidence *= optionalFactor
+		}
+		tags = append(tags, detectedTag(recipe.ID, allergen, TagAllergen, confidence, evidence))
+	}
+
+	for _, diet := range KnownTags()[TagDiet] {
+		var excludedOptional, doubtful []string
+		suits := true
+		for _, ingredient := range recipe.Ingredients {
+			excluded := false
+			for _, group := range diets[diet] {
+				if groupMatches(groups[group], ingredient.Name) {
+					excluded = true
+					break
+				}
+			}
+			switch {
+			case excluded && !ingredient.Optional:
+				suits = false
+			case excluded:
+				excludedOptional = append(excludedOptional, ingredient.Name)
+			case groupMatches(foodGroup{Terms: uncertain[diet]}, ingredient.Name):
+				doubtful = append(doubtful, ingredient.Name)
+			}
+		}
+		if !suits {
+			continue
+		}
+		confidence := confidenceClear
+		if len(doubtful) > 0 {
+			confidence = confidenceUncertain
+		}
+		if len(excludedOptional) > 0 {
+			confidence *= optionalFactor
+		}
+		tags = append(tags, detectedTag(recipe.ID, diet, TagDiet, confidence, append(doubtful, excludedOptional...)))
+	}
+	return tags
+}
+
+// onlyUncertain reports whether an ingredient matches an allergen only
+// through its uncertain terms
+func onlyUncertain(allergen, ingredient string) bool {
+	terms := uncertain[allergen]
+	if len(terms) == 0 {
+		return false
+	}
+	certain := foodGroup{Exceptions: groups[allergen].Exceptions}
+	for _, term := range groups[allergen].Terms {
+		if !contains(terms, term) {
+			certain.Terms = append(certain.Terms, term)
+		}
+	}
+	return !groupMatches(certain, ingredient)
+}
+
+func detectedTag(recipeID, name, kind string, confidence float64, ingredients []string) *database.RecipeDietaryTag {
+	if ingredients == nil {
+		ingredients = []string{}
+	}
+	return &database.RecipeDietaryTag{
+		RecipeID:    recipeID,
+		Tag:         name,
+		Kind:        kind,
+		Detected:    true,
+		Confidence:  math.Round(confidence*100) / 100,
+		Ingredients: ingredients,
+	}
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// RefreshTags analyzes a recipe again and stores what it found, keeping
+// the author's overrides. A recipe that no longer exists is skipped. It is
+// registered as a recipe change hook.
+func RefreshTags(db database.Database) func(ctx context.Context, recipeID string) {
+	return func(ctx context.Context, recipeID string) {
+		recipe, err := db.GetRecipeByID(ctx, recipeID)
+		if err == nil {
+			_, err = saveAnalysis(ctx, db, recipe)
+		}
+		if err != nil && !apierror.IsNotFound(err) {
+			// The tags are analyzed again when the recipe is next viewed
+			logger.Ctx(ctx).Warn().Err(err).Str("recipe_id", recipeID).Msg("Failed to refresh recipe dietary tags")
+		}
+	}
+}
+
+// saveAnalysis analyzes a recipe and replaces its detected tags, keeping
+// the author's overrides. It returns the tags as stored.
+func saveAnalysis(ctx context.Context, db database.Database, recipe *database.Recipe) ([]*database.RecipeDietaryTag, error) {
+	now := time.Now()
+	tags := Analyze(recipe)
+	err := db.WithTx(ctx, func(ctx context.Context) error {
+		existing, err := db.ListRecipeDietaryTags(ctx, recipe.ID)
+		if err != nil {
+			return err
+		}
+		overrides := map[string]*bool{}
+		for _, tag := range existing {
+			overrides[tag.Tag] = tag.Override
+		}
+		for _, tag := range tags {
+			tag.Override = overrides[tag.Tag]
+			delete(overrides, tag.Tag)
+		}
+		// What is left was detected before but isn't now
+		for _, tag := range existing {
+			if _, gone := overrides[tag.Tag]; !gone {
+				continue
+			}
+			if tag.Override == nil {
+				if err := db.DeleteRecipeDietaryTag(ctx, recipe.ID, tag.Tag); err != nil {
+					return err
+				}
+				continue
+			}
+			tag.Detected, tag.Confidence, tag.Ingredients = false, 0, []string{}
+			tags = append(tags, tag)
+		}
+
+		for _, tag := range tags {
+			tag.UpdatedAt = now
+			if err := db.UpsertRecipeDietaryTag(ctx, tag); err != nil {
+				return err
+			}
+		}
+		return db.UpsertRecipeDietaryAnalysis(ctx, &database.RecipeDietaryAnalysis{
+			RecipeID:        recipe.ID,
+			Version:         AnalyzerVersion,
+			SourceUpdatedAt: recipe.UpdatedAt,
+			AnalyzedAt:      now,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// stale reports whether a recipe needs analyzing: never analyzed, changed
+// since, or analyzed by an older version
+func stale(analysis *database.RecipeDietaryAnalysis, recipe *database.Recipe) bool {
+	return analysis == nil || analysis.Version < AnalyzerVersion || !analysis.SourceUpdatedAt.Equal(recipe.UpdatedAt)
+}
+
+// AddTags sets each recipe's dietary tags, analyzing those not analyzed
+// since they last changed
+func AddTags(ctx context.Context, db database.Database, recipes []*database.Recipe) error {
+	if len(recipes) == 0 {
+		return nil
+	}
+	ids := make([]string, len(recipes))
+	for i, recipe := range recipes {
+		ids[i] = recipe.ID
+	}
+	analyses, err := db.ListRecipeDietaryAnalysesIn(ctx, ids)
+	if err != nil {
+		return err
+	}
+	tags, err := db.ListRecipeDietaryTagsIn(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, recipe := range recipes {
+		recipeTags := tags[recipe.ID]
+		if stale(analyses[recipe.ID], recipe) {
+			if recipeTags, err = saveAnalysis(ctx, db, recipe); err != nil {
+				return err
+			}
+		}
+		recipe.DietaryTags = withApplies(recipeTags)
+	}
+	return nil
+}
+
+// withApplies works out which tags apply, and never returns nil
+func withApplies(tags []*database.RecipeDietaryTag) []*database.RecipeDietaryTag {
+	if tags == nil {
+		return []*database.RecipeDietaryTag{}
+	}
+	for _, tag := range tags {
+		tag.Applies = tag.Detected
+		if tag.Override != nil {
+			tag.Applies = *tag.Override
+		}
+	}
+	sortTags(tags)
+	return tags
+}
+
+// sortTags orders allergens before diets, then by name
+func sortTags(tags []*database.RecipeDietaryTag) {
+	sort.Slice(tags, func(a, b int) bool {
+		if tags[a].Kind != tags[b].Kind {
+			return tags[a].Kind < tags[b].Kind
+		}
+		return tags[a].Tag < tags[b].Tag
+	})
+}
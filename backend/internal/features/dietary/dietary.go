@@ -0,0 +1,260 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package dietary
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// foodGroup describes the ingredient terms that belong to an allergen or food
+// group. Exceptions are phrases that look like a match but are not, such as
+// "peanut butter" for dairy.
+type foodGroup struct {
+	Terms      []string
+	Exceptions []string
+}
+
+// groups is the curated catalog of allergen and food groups
+var groups = map[string]foodGroup{
+	"peanuts": {
+		Terms: []string{"peanut", "groundnut"},
+	},
+	"tree nuts": {
+		Terms: []string{"almond", "cashew", "walnut", "pecan", "hazelnut", "pistachio", "macadamia",
+			"brazil nut", "pine nut", "praline", "marzipan"},
+	},
+	"dairy": {
+		Terms: []string{"milk", "cheese", "butter", "cream", "yogurt", "yoghurt", "whey", "casein", "ghee",
+			"buttermilk", "parmesan", "mozzarella", "cheddar", "ricotta", "feta", "mascarpone"},
+		Exceptions: []string{"peanut butter", "almond butter", "cashew butter", "cocoa butter",
+			"coconut milk", "coconut cream", "almond milk", "oat milk", "soy milk", "rice milk",
+			"cream of tartar", "dairy free"},
+	},
+	"gluten": {
+		Terms: []string{"wheat", "flour", "barley", "rye", "spelt", "bread", "breadcrumb", "pasta",
+			"spaghetti", "noodle", "couscous", "semolina", "bulgur", "farro", "seitan", "malt",
+			"soy sauce", "cracker", "tortilla"},
+		Exceptions: []string{"rice flour", "almond flour", "coconut flour", "corn tortilla",
+			"rice noodle", "gluten free flour", "gluten free"},
+	},
+	"eggs": {
+		Terms: []string{"egg", "mayonnaise", "meringue", "aioli"},
+	},
+	"fish": {
+		Terms: []string{"fish", "salmon", "tuna", "cod", "anchovy", "anchovies", "sardine", "trout", "halibut",
+			"tilapia", "mackerel", "haddock", "fish sauce"},
+	},
+	"shellfish": {
+		Terms: []string{"shrimp", "prawn", "crab", "lobster", "scallop", "mussel", "clam", "oyster",
+			"crayfish", "squid", "octopus"},
+	},
+	"soy": {
+		Terms: []string{"soy", "soya", "tofu", "edamame", "miso", "tempeh", "soy sauce"},
+	},
+	"sesame": {
+		Terms: []string{"sesame", "tahini"},
+	},
+	"pork": {
+		Terms: []string{"pork", "bacon", "ham", "lard", "prosciutto", "pancetta", "gelatin", "chorizo"},
+	},
+	"meat": {
+		Terms: []string{"beef", "pork", "chicken", "turkey", "lamb", "bacon", "ham", "sausage", "veal",
+			"duck", "goat", "venison", "prosciutto", "pancetta", "salami", "pepperoni", "chorizo",
+			"gelatin", "lard", "steak", "mince"},
+	},
+	"honey": {
+		Terms: []string{"honey"},
+	},
+}
+
+// aliases maps restriction names users commonly enter to catalog groups.
+// Dietary preferences expand to every group they exclude.
+var aliases = map[string][]string{
+	"peanut":       {"peanuts"},
+	"tree nut":     {"tree nuts"},
+	"nuts":         {"peanuts", "tree nuts"},
+	"nut free":     {"peanuts", "tree nuts"},
+	"milk":         {"dairy"},
+	"lactose":      {"dairy"},
+	"dairy free":   {"dairy"},
+	"wheat":        {"gluten"},
+	"celiac":       {"gluten"},
+	"coeliac":      {"gluten"},
+	"gluten free":  {"gluten"},
+	"egg":          {"eggs"},
+	"seafood":      {"fish", "shellfish"},
+	"soya":         {"soy"},
+	"vegetarian":   {"meat", "fish", "shellfish"},
+	"pescatarian":  {"meat"},
+	"vegan":        {"meat", "fish", "shellfish", "dairy", "eggs", "honey"},
+	"halal":        {"pork"},
+	"no pork":      {"pork"},
+	"pork free":    {"pork"},
+	"shell fish":   {"shellfish"},
+	"sesame seeds": {"sesame"},
+}
+
+// Conflict describes an ingredient that clashes with a recorded restriction
+type Conflict struct {
+	RestrictionID string `json:"restriction_id"`
+	UserID        string `json:"user_id"`
+	Kind          string `json:"kind"`
+	Restriction   string `json:"restriction"`
+	Severity      string `json:"severity,omitempty"`
+	Group         string `json:"group"`
+	Ingredient    string `json:"ingredient"`
+	Optional      bool   `json:"optional"`
+}
+
+// KnownGroups returns the names of the catalog groups in alphabetical order
+func KnownGroups() []string {
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ExpandRestriction resolves a restriction name to the catalog groups it
+// covers. Names that are not in the catalog are matched literally.
+func ExpandRestriction(name string) []string {
+	key := normalize(name)
+	if _, ok := groups[key]; ok {
+		return []string{key}
+	}
+	if expanded, ok := aliases[key]; ok {
+		return expanded
+	}
+	return nil
+}
+
+// MatchGroups returns the catalog groups an ingredient name belongs to
+func MatchGroups(ingredient string) []string {
+	matched := []string{}
+	for _, name := range KnownGroups() {
+		if groupMatches(groups[name], ingredient) {
+			matched = append(matched, name)
+		}
+	}
+	return matched
+}
+
+// Conflicts returns every ingredient in the recipe that clashes with one of
+// the given restrictions
+func Conflicts(recipe *database.Recipe, restrictions []*database.DietaryRestriction) []Conflict {
+	conflicts := []Conflict{}
+	for _, restriction := range restrictions {
+		expanded := ExpandRestriction(restriction.Name)
+		for _, ingredient := range recipe.Ingredients {
+			group, ok := matchRestriction(restriction.Name, expanded, ingredient.Name)
+			if !ok {
+				continue
+			}
+			conflicts = append(conflicts, Conflict{
+				RestrictionID: restriction.ID,
+				UserID:        restriction.UserID,
+				Kind:          restriction.Kind,
+				Restriction:   restriction.Name,
+				Severity:      restriction.Severity,
+				Group:         group,
+				Ingredient:    ingredient.Name,
+				Optional:      ingredient.Optional,
+			})
+		}
+	}
+	return conflicts
+}
+
+// HasConflicts reports whether the recipe clashes with any restriction
+func HasConflicts(recipe *database.Recipe, restrictions []*database.DietaryRestriction) bool {
+	return len(Conflicts(recipe, restrictions)) > 0
+}
+
+// matchRestriction checks one ingredient against a restriction, returning
+// the group that matched
+func matchRestriction(name string, expanded []string, ingredient string) (string, bool) {
+	if len(expanded) == 0 {
+		// Free-form restriction such as "coriander"
+		literal := normalize(name)
+		if literal != "" && containsTerm(normalize(ingredient), literal) {
+			return literal, true
+		}
+		return "", false
+	}
+	for _, group := range expanded {
+		if groupMatches(groups[group], ingredient) {
+			return group, true
+		}
+	}
+	return "", false
+}
+
+// groupMatches reports whether an ingredient belongs to a food group
+func groupMatches(group foodGroup, ingredient string) bool {
+	text := normalize(ingredient)
+	for _, exception := range group.Exceptions {
+		text = strings.ReplaceAll(" "+text+" ", " "+exception+" ", " ")
+		text = strings.TrimSpace(text)
+	}
+	for _, term := range group.Terms {
+		if containsTerm(text, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsTerm reports whether text contains term as whole words, allowing
+// simple plurals such as "eggs" and "peaches"
+func containsTerm(text, term string) bool {
+	padded := " " + text + " "
+	for _, form := range []string{term, term + "s", term + "es"} {
+		if strings.Contains(padded, " "+form+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize lowercases text and replaces punctuation with single spaces
+func normalize(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			space = false
+			continue
+		}
+		if r > 127 {
+			b.WriteRune(r)
+			space = false
+			continue
+		}
+		if !space {
+			b.WriteByte(' ')
+			space = true
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,270 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package dietary
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/authz"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/middleware"
+)
+
+// Handler handles dietary restriction HTTP requests
+type Handler struct {
+	db database.Database
+}
+
+// NewHandler creates a new dietary restriction handler
+func NewHandler(db database.Database) *Handler {
+	return &Handler{
+		db: db,
+	}
+}
+
+// RegisterRoutes registers the current user's dietary restriction routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("", h.ListMyRestrictions)
+	router.POST("", h.CreateRestriction)
+	router.DELETE("/:id", h.DeleteRestriction)
+	router.GET("/groups", h.ListGroups)
+}
+
+// RegisterHouseholdRoutes registers household-scoped dietary routes
+func (h *Handler) RegisterHouseholdRoutes(router *gin.RouterGroup) {
+	router.GET("/:id/dietary-restrictions", h.ListHouseholdRestrictions)
+}
+
+// RegisterRecipeRoutes registers recipe conflict report and dietary tag
+// routes
+func (h *Handler) RegisterRecipeRoutes(router *gin.RouterGroup) {
+	router.GET("/:id/conflicts", h.GetRecipeConflicts)
+	router.GET("/:id/dietary-tags", h.GetRecipeTags)
+	router.PUT("/:id/dietary-tags/:tag", h.OverrideRecipeTag)
+	router.DELETE("/:id/dietary-tags/:tag", h.ClearRecipeTagOverride)
+}
+
+// ListMyRestrictions lists the authenticated user's dietary restrictions
+// @Summary List my dietary restrictions
+// @Tags dietary
+// @Produce json
+// @Router /me/dietary-restrictions [get]
+func (h *Handler) ListMyRestrictions(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	restrictions, err := h.db.ListDietaryRestrictions(c.Request.Context(), []string{user.ID})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restrictions)
+}
+
+// CreateRestriction records a new allergy, intolerance or dietary preference
+// @Summary Create dietary restriction
+// @Tags dietary
+// @Accept json
+// @Produce json
+// @Router /me/dietary-restrictions [post]
+func (h *Handler) CreateRestriction(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req struct {
+		Kind     string `json:"kind" binding:"required,oneof=allergy intolerance preference"`
+		Name     string `json:"name" binding:"required"`
+		Severity string `json:"severity" binding:"omitempty,oneof=mild moderate severe"`
+		Notes    string `json:"notes"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	restriction := database.DietaryRestriction{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		Kind:      req.Kind,
+		Name:      strings.ToLower(strings.TrimSpace(req.Name)),
+		Severity:  req.Severity,
+		Notes:     req.Notes,
+		CreatedAt: time.Now(),
+	}
+
+	if err := h.db.CreateDietaryRestriction(c.Request.Context(), &restriction); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"restriction": restriction,
+		// Tell the client how the name will be matched so typos are noticed
+		"matches_groups": ExpandRestriction(restriction.Name),
+	})
+}
+
+// DeleteRestriction deletes one of the authenticated user's dietary restrictions
+// @Summary Delete dietary restriction
+// @Tags dietary
+// @Param id path string true "Restriction ID"
+// @Success 204
+// @Router /me/dietary-restrictions/{id} [delete]
+func (h *Handler) DeleteRestriction(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	id := c.Param("id")
+
+	// Verify ownership
+	existing, err := h.db.GetDietaryRestrictionByID(c.Request.Context(), id)
+	if err != nil {
+		apierror.Lookup(c, err, "dietary restriction not found")
+		return
+	}
+
+	if !authz.Owns(user.ID, existing.UserID) {
+		apierror.Forbidden(c, "forbidden")
+		return
+	}
+
+	if err := h.db.DeleteDietaryRestriction(c.Request.Context(), id); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListGroups lists the allergen and food groups the matcher understands,
+// and the allergens and diets recipes are tagged with
+// @Summary List known dietary groups
+// @Tags dietary
+// @Produce json
+// @Router /me/dietary-restrictions/groups [get]
+func (h *Handler) ListGroups(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"groups": KnownGroups(), "recipe_tags": KnownTags()})
+}
+
+// ListHouseholdRestrictions lists the dietary restrictions of every member of a household
+// @Summary List household dietary restrictions
+// @Tags dietary
+// @Produce json
+// @Param id path string true "Household ID"
+// @Router /households/{id}/dietary-restrictions [get]
+func (h *Handler) ListHouseholdRestrictions(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	memberIDs, ok := h.householdMemberIDs(c, c.Param("id"), user.ID)
+	if !ok {
+		return
+	}
+
+	restrictions, err := h.db.ListDietaryRestrictions(c.Request.Context(), memberIDs)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restrictions)
+}
+
+// GetRecipeConflicts reports recipe ingredients that clash with the user's
+// restrictions, or with every household member's when household_id is given
+// @Summary Recipe dietary conflict report
+// @Tags dietary
+// @Produce json
+// @Param id path string true "Recipe ID"
+// @Param household_id query string false "Check against all members of this household"
+// @Router /recipes/{id}/conflicts [get]
+func (h *Handler) GetRecipeConflicts(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	recipe, err := h.db.GetRecipeByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierror.Lookup(c, err, "recipe not found")
+		return
+	}
+
+	userIDs := []string{user.ID}
+	if householdID := c.Query("household_id"); householdID != "" {
+		userIDs, ok = h.householdMemberIDs(c, householdID, user.ID)
+		if !ok {
+			return
+		}
+	}
+
+	restrictions, err := h.db.ListDietaryRestrictions(c.Request.Context(), userIDs)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	conflicts := Conflicts(recipe, restrictions)
+	c.JSON(http.StatusOK, gin.H{
+		"recipe_id":     recipe.ID,
+		"has_conflicts": len(conflicts) > 0,
+		"conflicts":     conflicts,
+	})
+}
+
+// householdMemberIDs returns the member IDs of a household after checking
+// that the requesting user belongs to it. It writes the error response and
+// returns false when the check fails.
+func (h *Handler) householdMemberIDs(c *gin.Context, householdID, userID string) ([]string, bool) {
+	if _, err := authz.Household(c.Request.Context(), h.db, householdID, userID, authz.ViewHousehold); err != nil {
+		apierror.Lookup(c, err, "household not found")
+		return nil, false
+	}
+
+	members, err := h.db.ListHouseholdMembers(c.Request.Context(), householdID)
+	if err != nil {
+		apierror.Respond(c, err)
+		return nil, false
+	}
+
+	ids := make([]string, 0, len(members))
+	for _, member := range members {
+		ids = append(ids, member.UserID)
+	}
+	return ids, true
+}
@@ -0,0 +1,202 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package dietary
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/authz"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/middleware"
+)
+
+// GetRecipeTags lists the allergens a recipe likely contains and the diets
+// it likely suits, analyzing it first if it changed since it last was
+// @Summary Recipe dietary tags
+// @Tags dietary
+// @Produce json
+// @Param id path string true "Recipe ID"
+// @Router /recipes/{id}/dietary-tags [get]
+func (h *Handler) GetRecipeTags(c *gin.Context) {
+	recipe, ok := h.tagRecipe(c, false)
+	if !ok {
+		return
+	}
+	if err := AddTags(c.Request.Context(), h.db, []*database.Recipe{recipe}); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	h.respondTags(c, recipe)
+}
+
+// OverrideRecipeTag sets whether a tag applies to a recipe, whatever the
+// analyzer finds, e.g. that a recipe using gluten-free flour has no gluten
+// @Summary Override a recipe dietary tag
+// @Tags dietary
+// @Accept json
+// @Produce json
+// @Param id path string true "Recipe ID"
+// @Param tag path string true "Allergen or diet, e.g. gluten or vegan"
+// @Router /recipes/{id}/dietary-tags/{tag} [put]
+func (h *Handler) OverrideRecipeTag(c *gin.Context) {
+	name, kind, ok := tagParam(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Applies *bool `json:"applies" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+	recipe, ok := h.tagRecipe(c, true)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := AddTags(ctx, h.db, []*database.Recipe{recipe}); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	tag := findTag(recipe.DietaryTags, name)
+	if tag == nil {
+		tag = &database.RecipeDietaryTag{RecipeID: recipe.ID, Tag: name, Kind: kind, Ingredients: []string{}}
+		recipe.DietaryTags = append(recipe.DietaryTags, tag)
+	}
+	tag.Override = req.Applies
+	tag.UpdatedAt = time.Now()
+	if err := h.db.UpsertRecipeDietaryTag(ctx, tag); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	recipe.DietaryTags = withApplies(recipe.DietaryTags)
+	h.respondTags(c, recipe)
+}
+
+// ClearRecipeTagOverride goes back to what the analyzer finds for a tag
+// @Summary Clear a recipe dietary tag override
+// @Tags dietary
+// @Produce json
+// @Param id path string true "Recipe ID"
+// @Param tag path string true "Allergen or diet, e.g. gluten or vegan"
+// @Router /recipes/{id}/dietary-tags/{tag} [delete]
+func (h *Handler) ClearRecipeTagOverride(c *gin.Context) {
+	name, _, ok := tagParam(c)
+	if !ok {
+		return
+	}
+	recipe, ok := h.tagRecipe(c, true)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := AddTags(ctx, h.db, []*database.Recipe{recipe}); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	tag := findTag(recipe.DietaryTags, name)
+	if tag == nil || tag.Override == nil {
+		apierror.NotFound(c, "this tag isn't overridden")
+		return
+	}
+
+	var err error
+	if tag.Detected {
+		tag.Override = nil
+		tag.UpdatedAt = time.Now()
+		err = h.db.UpsertRecipeDietaryTag(ctx, tag)
+	} else {
+		err = h.db.DeleteRecipeDietaryTag(ctx, recipe.ID, tag.Tag)
+		recipe.DietaryTags = removeTag(recipe.DietaryTags, tag.Tag)
+	}
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	recipe.DietaryTags = withApplies(recipe.DietaryTags)
+	h.respondTags(c, recipe)
+}
+
+// tagRecipe loads the recipe in the path, checking the user may view it,
+// or edit it when edit is set
+func (h *Handler) tagRecipe(c *gin.Context, edit bool) (*database.Recipe, bool) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return nil, false
+	}
+	recipe, err := h.db.GetRecipeByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierror.Lookup(c, err, "recipe not found")
+		return nil, false
+	}
+	if !authz.CanViewRecipe(user.ID, recipe) {
+		apierror.NotFound(c, "recipe not found")
+		return nil, false
+	}
+	if edit && !authz.CanEditRecipe(user.ID, recipe) {
+		apierror.Forbidden(c, "only the recipe's author can change its dietary tags")
+		return nil, false
+	}
+	return recipe, true
+}
+
+// tagParam reads the tag in the path, accepting tree-nuts for tree nuts
+func tagParam(c *gin.Context) (string, string, bool) {
+	name := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(c.Param("tag")), "-", " "))
+	kind, ok := IsTag(name)
+	if !ok {
+		apierror.NotFound(c, "unknown dietary tag; see /me/dietary-restrictions/groups")
+		return "", "", false
+	}
+	return name, kind, true
+}
+
+func (h *Handler) respondTags(c *gin.Context, recipe *database.Recipe) {
+	c.JSON(http.StatusOK, gin.H{
+		"recipe_id": recipe.ID,
+		"tags":      recipe.DietaryTags,
+	})
+}
+
+func findTag(tags []*database.RecipeDietaryTag, name string) *database.RecipeDietaryTag {
+	for _, tag := range tags {
+		if tag.Tag == name {
+			return tag
+		}
+	}
+	return nil
+}
+
+func removeTag(tags []*database.RecipeDietaryTag, name string) []*database.RecipeDietaryTag {
+	kept := tags[:0]
+	for _, tag := range tags {
+		if tag.Tag != name {
+			kept = append(kept, tag)
+		}
+	}
+	return kept
+}
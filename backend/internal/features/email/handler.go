@@ -0,0 +1,118 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package email
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/middleware"
+)
+
+// Handler handles email preference HTTP requests
+type Handler struct {
+	db       database.Database
+	notifier *Notifier
+}
+
+// NewHandler creates a new email preference handler
+func NewHandler(db database.Database, notifier *Notifier) *Handler {
+	return &Handler{
+		db:       db,
+		notifier: notifier,
+	}
+}
+
+// RegisterRoutes registers email preference routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("", h.GetPreferences)
+	router.PUT("", h.UpdatePreferences)
+}
+
+// GetPreferences returns the kinds of email the authenticated user receives
+// @Summary Get email preferences
+// @Tags email-preferences
+// @Produce json
+// @Router /me/email-preferences [get]
+func (h *Handler) GetPreferences(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	h.respond(c, LoadPreferences(c.Request.Context(), h.db, user.ID))
+}
+
+// UpdatePreferences changes the kinds of email the authenticated user
+// receives. Fields left out keep their current value.
+// @Summary Update email preferences
+// @Tags email-preferences
+// @Accept json
+// @Produce json
+// @Router /me/email-preferences [put]
+func (h *Handler) UpdatePreferences(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req struct {
+		HouseholdInvites *bool `json:"household_invites"`
+		WeeklyDigest     *bool `json:"weekly_digest"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	prefs := LoadPreferences(ctx, h.db, user.ID)
+	if req.HouseholdInvites != nil {
+		prefs.HouseholdInvites = *req.HouseholdInvites
+	}
+	if req.WeeklyDigest != nil {
+		prefs.WeeklyDigest = *req.WeeklyDigest
+	}
+	prefs.UpdatedAt = time.Now()
+
+	if err := h.db.UpsertEmailPreferences(ctx, prefs); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	h.respond(c, prefs)
+}
+
+// respond writes preferences with whether the instance sends email at all
+func (h *Handler) respond(c *gin.Context, prefs *database.EmailPreferences) {
+	var updatedAt *time.Time // null until the user saves preferences
+	if !prefs.UpdatedAt.IsZero() {
+		updatedAt = &prefs.UpdatedAt
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"household_invites": prefs.HouseholdInvites,
+		"weekly_digest":     prefs.WeeklyDigest,
+		"updated_at":        updatedAt,
+		"email_enabled":     h.notifier.Enabled(), // false when the instance has no mail server
+	})
+}
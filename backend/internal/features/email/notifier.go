@@ -0,0 +1,191 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package email sends the app's email through the mail package and keeps
+// each user's choice of which email they receive.
+package email
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/auth"
+	"github.com/rghsoftware/space-food/internal/config"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/events"
+	"github.com/rghsoftware/space-food/internal/mail"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// Notifier renders and sends the app's email, respecting each recipient's
+// preferences. A nil Notifier, or one without a sender, sends nothing.
+type Notifier struct {
+	db         database.Database
+	sender     mail.Sender
+	resetURL   string
+	resetValid time.Duration
+}
+
+// NewNotifier creates a notifier. sender is nil when email is off.
+func NewNotifier(cfg *config.Config, db database.Database, sender mail.Sender) *Notifier {
+	return &Notifier{
+		db:         db,
+		sender:     sender,
+		resetURL:   cfg.Auth.PasswordResetURL,
+		resetValid: time.Duration(cfg.Auth.PasswordResetExpiry) * time.Minute,
+	}
+}
+
+// Enabled reports whether the instance sends email
+func (n *Notifier) Enabled() bool {
+	return n != nil && n.sender != nil
+}
+
+// DefaultPreferences are the preferences of users who haven't chosen:
+// invitations are sent, digests are not
+func DefaultPreferences(userID string) *database.EmailPreferences {
+	return &database.EmailPreferences{
+		UserID:           userID,
+		HouseholdInvites: true,
+	}
+}
+
+// LoadPreferences returns a user's email preferences, or the defaults
+// when they haven't saved any or they can't be read
+func LoadPreferences(ctx context.Context, db database.Database, userID string) *database.EmailPreferences {
+	prefs, err := db.GetEmailPreferences(ctx, userID)
+	if err != nil {
+		return DefaultPreferences(userID)
+	}
+	return prefs
+}
+
+// HouseholdInvite is what the invitation email says
+type HouseholdInvite struct {
+	HouseholdName string
+	InviterName   string
+	Code          string
+	Link          string
+	ExpiresAt     time.Time
+}
+
+// SendHouseholdInvite emails an invitation to the address it is for. It
+// reports whether it was sent: not when email is off, the invitation has
+// no address, or its recipient has an account and turned invitation email
+// off.
+func (n *Notifier) SendHouseholdInvite(ctx context.Context, to string, invite HouseholdInvite) (bool, error) {
+	if !n.Enabled() || to == "" {
+		return false, nil
+	}
+	user, err := n.db.GetUserByEmail(ctx, to)
+	switch {
+	case err == nil:
+		if !LoadPreferences(ctx, n.db, user.ID).HouseholdInvites {
+			return false, nil
+		}
+	case !apierror.IsNotFound(err):
+		return false, err
+	}
+
+	msg, err := mail.Render(mail.HouseholdInvite, to, invite)
+	if err != nil {
+		return false, err
+	}
+	if err := n.sender.Send(ctx, msg); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// passwordReset is what the password reset email says. Without a
+// link, the email gives the token to enter in the app instead.
+type passwordReset struct {
+	Name         string
+	Link         string
+	Token        string
+	ValidMinutes int
+	ByAdmin      bool // an administrator started the reset, not the user
+}
+
+// SendPasswordReset emails a password reset token, linked to the reset page
+// when auth.passwordreseturl is set. It is sent whatever the user's
+// preferences, as it is about their account's security.
+func (n *Notifier) SendPasswordReset(ctx context.Context, user *auth.User, token string, byAdmin bool) error {
+	if !n.Enabled() {
+		return nil
+	}
+	reset := passwordReset{
+		Name:         displayName(user.FirstName),
+		Token:        token,
+		ValidMinutes: int(n.resetValid.Minutes()),
+		ByAdmin:      byAdmin,
+	}
+	if link, err := url.Parse(n.resetURL); err == nil && n.resetURL != "" {
+		query := link.Query()
+		query.Set("token", token)
+		link.RawQuery = query.Encode()
+		reset.Link = link.String()
+	}
+
+	msg, err := mail.Render(mail.PasswordReset, user.Email, reset)
+	if err != nil {
+		return err
+	}
+	return n.sender.Send(ctx, msg)
+}
+
+// weeklyDigest is what the weekly digest email says
+type weeklyDigest struct {
+	Name   string
+	Review any // the weekly review, as in the weekly_review_ready event
+}
+
+// Handle is the event bus subscriber. It emails weekly reviews to the
+// users who turned the weekly digest on.
+func (n *Notifier) Handle(ctx context.Context, event events.Event) {
+	if event.Type != events.WeeklyReviewReady || !n.Enabled() {
+		return
+	}
+	data, ok := event.Data.(events.WeeklyReviewReadyData)
+	if !ok || !LoadPreferences(ctx, n.db, event.UserID).WeeklyDigest {
+		return
+	}
+	user, err := n.db.GetUserByID(ctx, event.UserID)
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("user_id", event.UserID).Msg("Failed to look up weekly digest recipient")
+		return
+	}
+
+	msg, err := mail.Render(mail.WeeklyDigest, user.Email, weeklyDigest{Name: displayName(user.FirstName), Review: data.Review})
+	if err == nil {
+		err = n.sender.Send(ctx, msg)
+	}
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("user_id", event.UserID).Msg("Failed to send weekly digest")
+	}
+}
+
+// displayName is how email greets a user with the given first name
+func displayName(firstName string) string {
+	if firstName != "" {
+		return firstName
+	}
+	return "there"
+}
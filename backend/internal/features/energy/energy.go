@@ -0,0 +1,188 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package energy
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Energy levels run from MinLevel (running on empty) to MaxLevel (full tank)
+const (
+	MinLevel = 1
+	MaxLevel = 5
+)
+
+// FreshFor is how long a check-in describes the user's current energy
+const FreshFor = 12 * time.Hour
+
+// Trend directions
+const (
+	DirectionRising  = "rising"
+	DirectionFalling = "falling"
+	DirectionSteady  = "steady"
+	DirectionUnknown = "unknown"
+)
+
+// CurrentLevel returns the user's energy level from a check-in made within
+// FreshFor. Features that adapt to energy (step granularity, suggestions)
+// use it as their default instead of asking on every request.
+func CurrentLevel(ctx context.Context, db database.Database, userID string) (int, bool) {
+	latest, err := db.GetLatestEnergyCheckIn(ctx, userID)
+	if err != nil || time.Since(latest.RecordedAt) > FreshFor {
+		return 0, false
+	}
+	return latest.Level, true
+}
+
+// DayTrend summarises one local day of check-ins
+type DayTrend struct {
+	Date     string  `json:"date"` // YYYY-MM-DD
+	Average  float64 `json:"average"`
+	Min      int     `json:"min"`
+	Max      int     `json:"max"`
+	CheckIns int     `json:"check_ins"`
+}
+
+// Trend summarises check-ins over a period
+type Trend struct {
+	Timezone  string              `json:"timezone"`
+	Days      []DayTrend          `json:"days"`
+	Average   *float64            `json:"average"`
+	Direction string              `json:"direction"`
+	TimeOfDay map[string]*float64 `json:"time_of_day"` // average by morning, afternoon, evening, night
+}
+
+// timeOfDay buckets a local hour
+func timeOfDay(hour int) string {
+	switch {
+	case hour >= 5 && hour < 12:
+		return "morning"
+	case hour >= 12 && hour < 17:
+		return "afternoon"
+	case hour >= 17 && hour < 22:
+		return "evening"
+	default:
+		return "night"
+	}
+}
+
+// BuildTrend groups check-ins into local days, oldest first. Direction
+// compares the average of the later half of the period with the earlier
+// half; a change under half a level counts as steady.
+func BuildTrend(checkIns []*database.EnergyCheckIn, loc *time.Location, since time.Time, days int) *Trend {
+	trend := &Trend{
+		Timezone:  loc.String(),
+		Days:      []DayTrend{},
+		Direction: DirectionUnknown,
+		TimeOfDay: map[string]*float64{"morning": nil, "afternoon": nil, "evening": nil, "night": nil},
+	}
+	if len(checkIns) == 0 {
+		return trend
+	}
+
+	byDay := map[string]*DayTrend{}
+	daySums := map[string]int{}
+	periodSums := map[string][2]int{} // sum, count
+	var earlySum, earlyCount, lateSum, lateCount, total int
+	midpoint := since.AddDate(0, 0, days/2)
+
+	for _, ci := range checkIns {
+		local := ci.RecordedAt.In(loc)
+		date := local.Format("2006-01-02")
+
+		day, ok := byDay[date]
+		if !ok {
+			day = &DayTrend{Date: date, Min: ci.Level, Max: ci.Level}
+			byDay[date] = day
+		}
+		day.CheckIns++
+		day.Min = min(day.Min, ci.Level)
+		day.Max = max(day.Max, ci.Level)
+		daySums[date] += ci.Level
+
+		period := timeOfDay(local.Hour())
+		p := periodSums[period]
+		periodSums[period] = [2]int{p[0] + ci.Level, p[1] + 1}
+
+		if ci.RecordedAt.Before(midpoint) {
+			earlySum += ci.Level
+			earlyCount++
+		} else {
+			lateSum += ci.Level
+			lateCount++
+		}
+		total += ci.Level
+	}
+
+	for d := 0; d < days; d++ {
+		date := since.In(loc).AddDate(0, 0, d).Format("2006-01-02")
+		if day, ok := byDay[date]; ok {
+			day.Average = round(float64(daySums[date]) / float64(day.CheckIns))
+			trend.Days = append(trend.Days, *day)
+		}
+	}
+
+	for period, p := range periodSums {
+		avg := round(float64(p[0]) / float64(p[1]))
+		trend.TimeOfDay[period] = &avg
+	}
+
+	avg := round(float64(total) / float64(len(checkIns)))
+	trend.Average = &avg
+
+	if earlyCount > 0 && lateCount > 0 {
+		change := float64(lateSum)/float64(lateCount) - float64(earlySum)/float64(earlyCount)
+		switch {
+		case change >= 0.5:
+			trend.Direction = DirectionRising
+		case change <= -0.5:
+			trend.Direction = DirectionFalling
+		default:
+			trend.Direction = DirectionSteady
+		}
+	}
+
+	return trend
+}
+
+// WeekdayForecast predicts energy on each weekday as the average of the
+// check-ins made on that weekday, in loc. Weekdays without check-ins are
+// left out.
+func WeekdayForecast(checkIns []*database.EnergyCheckIn, loc *time.Location) map[time.Weekday]float64 {
+	sums := map[time.Weekday][2]int{} // sum, count
+	for _, ci := range checkIns {
+		day := ci.RecordedAt.In(loc).Weekday()
+		s := sums[day]
+		sums[day] = [2]int{s[0] + ci.Level, s[1] + 1}
+	}
+	forecast := map[time.Weekday]float64{}
+	for day, s := range sums {
+		forecast[day] = round(float64(s[0]) / float64(s[1]))
+	}
+	return forecast
+}
+
+// round rounds to one decimal place
+func round(v float64) float64 {
+	return math.Round(v*10) / 10
+}
@@ -0,0 +1,237 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package energy
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/api/params"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/authz"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/mealtime"
+	"github.com/rghsoftware/space-food/internal/middleware"
+)
+
+// maxDays bounds the days query parameter
+const maxDays = 90
+
+// Handler handles energy check-in HTTP requests
+type Handler struct {
+	db database.Database
+}
+
+// NewHandler creates a new energy check-in handler
+func NewHandler(db database.Database) *Handler {
+	return &Handler{
+		db: db,
+	}
+}
+
+// RegisterRoutes registers energy check-in routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("", h.ListCheckIns)
+	router.POST("", h.CreateCheckIn)
+	router.GET("/latest", h.GetLatest)
+	router.GET("/trend", h.GetTrend)
+	router.DELETE("/:id", h.DeleteCheckIn)
+}
+
+// ListCheckIns lists the authenticated user's recent energy check-ins
+// @Summary List energy check-ins
+// @Tags energy
+// @Produce json
+// @Param days query int false "How many days back to include (default 7, max 90)"
+// @Router /me/energy [get]
+func (h *Handler) ListCheckIns(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	query := params.Query(c)
+	days := query.Int("days", 7, 1, maxDays)
+	if !query.Valid() {
+		return
+	}
+
+	checkIns, err := h.db.ListEnergyCheckIns(c.Request.Context(), database.EnergyCheckInFilter{
+		UserID: user.ID,
+		Since:  time.Now().UTC().AddDate(0, 0, -days),
+		Limit:  500,
+	})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, checkIns)
+}
+
+// CreateCheckIn records the authenticated user's energy level
+// @Summary Check in energy level
+// @Tags energy
+// @Accept json
+// @Produce json
+// @Router /me/energy [post]
+func (h *Handler) CreateCheckIn(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req struct {
+		Level      int        `json:"level" binding:"required,min=1,max=5"`
+		Note       string     `json:"note" binding:"max=500"`
+		RecordedAt *time.Time `json:"recorded_at"` // for catching up on a missed check-in
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	now := time.Now()
+	recordedAt := now
+	if req.RecordedAt != nil {
+		if req.RecordedAt.After(now.Add(time.Minute)) {
+			apierror.BadRequest(c, "recorded_at cannot be in the future")
+			return
+		}
+		recordedAt = *req.RecordedAt
+	}
+
+	checkIn := database.EnergyCheckIn{
+		ID:         uuid.New().String(),
+		UserID:     user.ID,
+		Level:      req.Level,
+		Note:       strings.TrimSpace(req.Note),
+		RecordedAt: recordedAt.UTC(),
+		CreatedAt:  now,
+	}
+
+	if err := h.db.CreateEnergyCheckIn(c.Request.Context(), &checkIn); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, checkIn)
+}
+
+// GetLatest returns the authenticated user's most recent check-in
+// @Summary Get latest energy check-in
+// @Tags energy
+// @Produce json
+// @Router /me/energy/latest [get]
+func (h *Handler) GetLatest(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	latest, err := h.db.GetLatestEnergyCheckIn(c.Request.Context(), user.ID)
+	if err != nil {
+		apierror.Lookup(c, err, "no energy check-ins yet")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"check_in": latest,
+		// Stale check-ins are not used as defaults by other features
+		"current": time.Since(latest.RecordedAt) <= FreshFor,
+	})
+}
+
+// GetTrend summarises the authenticated user's energy by day and time of day
+// @Summary Get energy trend
+// @Tags energy
+// @Produce json
+// @Param days query int false "Length of the period in days (default 14, max 90)"
+// @Router /me/energy/trend [get]
+func (h *Handler) GetTrend(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	query := params.Query(c)
+	days := query.Int("days", 14, 1, maxDays)
+	if !query.Valid() {
+		return
+	}
+
+	loc := mealtime.Location(mealtime.LoadPreferences(c, h.db, user.ID))
+
+	// Start at local midnight so the first day is complete
+	now := time.Now().In(loc)
+	since := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc).AddDate(0, 0, -(days - 1))
+
+	checkIns, err := h.db.ListEnergyCheckIns(c.Request.Context(), database.EnergyCheckInFilter{
+		UserID: user.ID,
+		Since:  since.UTC(),
+	})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BuildTrend(checkIns, loc, since, days))
+}
+
+// DeleteCheckIn deletes one of the authenticated user's check-ins
+// @Summary Delete energy check-in
+// @Tags energy
+// @Param id path string true "Check-in ID"
+// @Success 204
+// @Router /me/energy/{id} [delete]
+func (h *Handler) DeleteCheckIn(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	id := c.Param("id")
+
+	// Verify ownership
+	existing, err := h.db.GetEnergyCheckInByID(c.Request.Context(), id)
+	if err != nil {
+		apierror.Lookup(c, err, "energy check-in not found")
+		return
+	}
+
+	if !authz.Owns(user.ID, existing.UserID) {
+		apierror.Forbidden(c, "forbidden")
+		return
+	}
+
+	if err := h.db.DeleteEnergyCheckIn(c.Request.Context(), id); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,210 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package foods
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/config"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// notFoundTTL is how long an unknown barcode is remembered. It is short as
+// products are added to Open Food Facts all the time.
+const notFoundTTL = 24 * time.Hour
+
+// Handler handles packaged food HTTP requests
+type Handler struct {
+	db       database.Database
+	cfg      config.OpenFoodFactsConfig
+	client   *OpenFoodFactsClient
+	cacheTTL time.Duration
+}
+
+// NewHandler creates a new foods handler
+func NewHandler(db database.Database, cfg config.OpenFoodFactsConfig) *Handler {
+	return &Handler{
+		db:       db,
+		cfg:      cfg,
+		client:   NewOpenFoodFactsClient(cfg),
+		cacheTTL: time.Duration(cfg.CacheDays) * 24 * time.Hour,
+	}
+}
+
+// RegisterRoutes registers food routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/barcode/:ean", h.LookupBarcode)
+}
+
+// LookupBarcode looks up a packaged food by barcode and returns it with a
+// nutrition log and pantry item ready to be submitted
+// @Summary Look up a food by barcode
+// @Tags foods
+// @Produce json
+// @Param ean path string true "EAN-8, UPC-A, EAN-13 or GTIN-14 barcode"
+// @Router /foods/barcode/{ean} [get]
+func (h *Handler) LookupBarcode(c *gin.Context) {
+	if !h.cfg.Enabled {
+		apierror.Abort(c, apierror.New(http.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "barcode lookup is disabled on this instance"))
+		return
+	}
+
+	barcode := strings.TrimSpace(c.Param("ean"))
+	if !ValidBarcode(barcode) {
+		apierror.BadRequest(c, "invalid barcode")
+		return
+	}
+
+	product, cached, err := h.lookup(c.Request.Context(), barcode)
+	if err != nil {
+		apierror.Upstream(c, "the food database could not be reached. Please try again or enter the food by hand.")
+		return
+	}
+	if !product.Found {
+		apierror.Abort(c, apierror.New(http.StatusNotFound, apierror.CodeNotFound, "product not found").WithDetails(gin.H{"barcode": barcode}))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"product":       product,
+		"cached":        cached,
+		"nutrition_log": nutritionLogTemplate(product),
+		"pantry_item":   pantryItemTemplate(product),
+	})
+}
+
+// lookup returns the cached product while it is fresh and asks Open Food
+// Facts otherwise. A stale cached product is served when the lookup fails.
+func (h *Handler) lookup(ctx context.Context, barcode string) (*database.FoodProduct, bool, error) {
+	cachedProduct, err := h.db.GetFoodProduct(ctx, barcode)
+	if err == nil && time.Since(cachedProduct.FetchedAt) < h.ttl(cachedProduct) {
+		return cachedProduct, true, nil
+	}
+
+	product, err := h.client.Lookup(ctx, barcode)
+	if err != nil {
+		logger.Ctx(ctx).Warn().Err(err).Str("barcode", barcode).Msg("Open Food Facts lookup failed")
+		if cachedProduct != nil {
+			return cachedProduct, true, nil
+		}
+		return nil, false, err
+	}
+
+	if err := h.db.UpsertFoodProduct(ctx, product); err != nil {
+		logger.Ctx(ctx).Warn().Err(err).Str("barcode", barcode).Msg("Failed to cache food product")
+	}
+	return product, false, nil
+}
+
+// ttl returns how long a cached product stays fresh
+func (h *Handler) ttl(product *database.FoodProduct) time.Duration {
+	if !product.Found {
+		return notFoundTTL
+	}
+	return h.cacheTTL
+}
+
+// displayName combines brand and product name, e.g. "Dr. Oetker Ristorante Pizza"
+func displayName(product *database.FoodProduct) string {
+	name := product.Name
+	if name == "" {
+		name = product.Barcode
+	}
+	if product.Brand != "" && !strings.Contains(strings.ToLower(name), strings.ToLower(product.Brand)) {
+		name = product.Brand + " " + name
+	}
+	return name
+}
+
+// nutritionLogTemplate prefills a nutrition log for one serving, or for
+// 100 g when the product has no serving data. Field names match the
+// nutrition log request body.
+func nutritionLogTemplate(product *database.FoodProduct) gin.H {
+	template := gin.H{
+		"FoodName": displayName(product),
+		"Servings": 1,
+	}
+
+	switch {
+	case product.NutritionPerServing != nil:
+		template["NutritionInfo"] = product.NutritionPerServing
+		template["Notes"] = servingNote(product)
+	case product.NutritionPer100g != nil:
+		template["NutritionInfo"] = product.NutritionPer100g
+		template["Notes"] = "Nutrition per 100 g"
+	}
+	return template
+}
+
+func servingNote(product *database.FoodProduct) string {
+	if product.ServingSize == "" {
+		return "Nutrition per serving"
+	}
+	return "Nutrition per serving (" + product.ServingSize + ")"
+}
+
+// pantryItemTemplate prefills a pantry item for one package. Field names
+// match the pantry item request body.
+func pantryItemTemplate(product *database.FoodProduct) gin.H {
+	template := gin.H{
+		"Name":     displayName(product),
+		"Quantity": 1,
+		"Unit":     "package",
+		"Barcode":  product.Barcode,
+	}
+	if category := mainCategory(product.Categories); category != "" {
+		template["Category"] = category
+	}
+	if isFrozen(product.Categories) {
+		template["Location"] = "freezer"
+	}
+	if product.Quantity != "" {
+		template["Notes"] = product.Quantity
+	}
+	return template
+}
+
+// mainCategory returns the most specific category in readable form. Open
+// Food Facts tags look like "en:frozen-pizzas", broadest first.
+func mainCategory(tags []string) string {
+	for i := len(tags) - 1; i >= 0; i-- {
+		lang, name, ok := strings.Cut(tags[i], ":")
+		if !ok || lang != "en" || name == "" {
+			continue
+		}
+		name = strings.ReplaceAll(name, "-", " ")
+		return strings.ToUpper(name[:1]) + name[1:]
+	}
+	return ""
+}
+
+func isFrozen(tags []string) bool {
+	for _, tag := range tags {
+		if strings.Contains(tag, "frozen") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,211 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package foods
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/config"
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// SourceOpenFoodFacts identifies products looked up on Open Food Facts
+const SourceOpenFoodFacts = "openfoodfacts"
+
+// productFields limits the Open Food Facts response to what we store
+const productFields = "code,product_name,generic_name,brands,quantity,serving_size,image_front_url,categories_tags,nutriments"
+
+// ErrLookupFailed is returned when Open Food Facts cannot be reached or
+// answers with something other than a product or a not-found reply
+var ErrLookupFailed = errors.New("product lookup failed")
+
+// OpenFoodFactsClient looks up products on the Open Food Facts API
+type OpenFoodFactsClient struct {
+	baseURL    string
+	userAgent  string
+	httpClient *http.Client
+}
+
+// NewOpenFoodFactsClient creates a client from configuration
+func NewOpenFoodFactsClient(cfg config.OpenFoodFactsConfig) *OpenFoodFactsClient {
+	return &OpenFoodFactsClient{
+		baseURL:    strings.TrimRight(cfg.BaseURL, "/"),
+		userAgent:  cfg.UserAgent,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// offResponse is the subset of the product API reply we rely on
+type offResponse struct {
+	Status  int `json:"status"`
+	Product struct {
+		Name        string         `json:"product_name"`
+		GenericName string         `json:"generic_name"`
+		Brands      string         `json:"brands"`
+		Quantity    string         `json:"quantity"`
+		ServingSize string         `json:"serving_size"`
+		ImageURL    string         `json:"image_front_url"`
+		Categories  []string       `json:"categories_tags"`
+		Nutriments  map[string]any `json:"nutriments"`
+	} `json:"product"`
+}
+
+// Lookup fetches a product by barcode. Unknown barcodes return a product
+// with Found false rather than an error, so they can be cached too.
+func (c *OpenFoodFactsClient) Lookup(ctx context.Context, barcode string) (*database.FoodProduct, error) {
+	endpoint := fmt.Sprintf("%s/api/v2/product/%s.json?fields=%s", c.baseURL, url.PathEscape(barcode), productFields)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrLookupFailed, err)
+	}
+	defer resp.Body.Close()
+
+	product := &database.FoodProduct{
+		Barcode:    barcode,
+		Source:     SourceOpenFoodFacts,
+		Categories: []string{},
+		FetchedAt:  time.Now(),
+	}
+
+	// The v2 API answers unknown products with a 404 and a JSON body
+	if resp.StatusCode == http.StatusNotFound {
+		return product, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d", ErrLookupFailed, resp.StatusCode)
+	}
+
+	var body offResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrLookupFailed, err)
+	}
+	if body.Status != 1 {
+		return product, nil
+	}
+
+	p := body.Product
+	product.Found = true
+	product.Name = strings.TrimSpace(p.Name)
+	if product.Name == "" {
+		product.Name = strings.TrimSpace(p.GenericName)
+	}
+	product.Brand = firstBrand(p.Brands)
+	product.Quantity = strings.TrimSpace(p.Quantity)
+	product.ServingSize = strings.TrimSpace(p.ServingSize)
+	product.ImageURL = p.ImageURL
+	if p.Categories != nil {
+		product.Categories = p.Categories
+	}
+	product.NutritionPer100g = nutritionFrom(p.Nutriments, "_100g")
+	product.NutritionPerServing = nutritionFrom(p.Nutriments, "_serving")
+
+	return product, nil
+}
+
+// firstBrand returns the first of a comma separated brand list
+func firstBrand(brands string) string {
+	brand, _, _ := strings.Cut(brands, ",")
+	return strings.TrimSpace(brand)
+}
+
+// nutritionFrom reads the nutriments with the given suffix. Open Food Facts
+// reports sodium in grams; NutritionInfo keeps it in milligrams. Returns nil
+// when no energy value is present, as the rest is rarely useful without it.
+func nutritionFrom(nutriments map[string]any, suffix string) *database.NutritionInfo {
+	calories, ok := nutriment(nutriments, "energy-kcal"+suffix)
+	if !ok {
+		kj, ok := nutriment(nutriments, "energy"+suffix)
+		if !ok {
+			return nil
+		}
+		calories = kj / 4.184
+	}
+
+	info := &database.NutritionInfo{Calories: round1(calories)}
+	info.Protein, _ = nutriment(nutriments, "proteins"+suffix)
+	info.Carbohydrates, _ = nutriment(nutriments, "carbohydrates"+suffix)
+	info.Fat, _ = nutriment(nutriments, "fat"+suffix)
+	info.Fiber, _ = nutriment(nutriments, "fiber"+suffix)
+	info.Sugar, _ = nutriment(nutriments, "sugars"+suffix)
+	if sodium, ok := nutriment(nutriments, "sodium"+suffix); ok {
+		info.Sodium = round1(sodium * 1000)
+	}
+	return info
+}
+
+// nutriment reads a numeric nutriment, which the API may send as a string
+func nutriment(nutriments map[string]any, key string) (float64, bool) {
+	switch v := nutriments[key].(type) {
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func round1(v float64) float64 {
+	return float64(int(v*10+0.5)) / 10
+}
+
+// ValidBarcode reports whether code is an EAN-8, UPC-A, EAN-13 or GTIN-14
+// barcode with a correct check digit
+func ValidBarcode(code string) bool {
+	switch len(code) {
+	case 8, 12, 13, 14:
+	default:
+		return false
+	}
+
+	sum := 0
+	for i := len(code) - 2; i >= 0; i-- {
+		d := code[i]
+		if d < '0' || d > '9' {
+			return false
+		}
+		weight := 1
+		if (len(code)-2-i)%2 == 0 {
+			weight = 3
+		}
+		sum += int(d-'0') * weight
+	}
+
+	check := code[len(code)-1]
+	if check < '0' || check > '9' {
+		return false
+	}
+	return (10-sum%10)%10 == int(check-'0')
+}
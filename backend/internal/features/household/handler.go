@@ -0,0 +1,822 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package household
+
+import (
+	"context"
+	"crypto/rand"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/api/params"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/audit"
+	"github.com/rghsoftware/space-food/internal/auth"
+	"github.com/rghsoftware/space-food/internal/authz"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/email"
+	"github.com/rghsoftware/space-food/internal/middleware"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+const (
+	// defaultInvitationExpiry is used when the request does not specify an expiry
+	defaultInvitationExpiry = 7 * 24 * time.Hour
+	// maxInvitationExpiry caps how long an invitation code stays valid
+	maxInvitationExpiry = 30 * 24 * time.Hour
+	// invitationCodeLength is the number of characters in a generated code
+	invitationCodeLength = 10
+	// invitationCodeAlphabet omits characters that are easily confused when read aloud
+	invitationCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
+// errInvitationClosed is returned when an invitation was accepted, declined
+// or revoked while it was being accepted
+var errInvitationClosed = apierror.New(http.StatusConflict, apierror.CodeConflict, "invitation is no longer pending")
+
+// errUnverifiedEmail is returned when an invitation is answered by its ID
+// from an account whose email address has not been verified
+var errUnverifiedEmail = apierror.New(http.StatusForbidden, apierror.CodeForbidden, "your email address is not verified; join with the invitation code instead")
+
+// Handler handles household HTTP requests
+type Handler struct {
+	db        database.Database
+	publicURL string
+	email     *email.Notifier
+}
+
+// NewHandler creates a new household handler
+func NewHandler(db database.Database, publicURL string, notifier *email.Notifier) *Handler {
+	return &Handler{
+		db:        db,
+		publicURL: strings.TrimRight(publicURL, "/"),
+		email:     notifier,
+	}
+}
+
+// RegisterRoutes registers household routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("", h.ListHouseholds)
+	router.POST("", h.CreateHousehold)
+	router.GET("/:id", h.GetHousehold)
+	router.DELETE("/:id", h.DeleteHousehold)
+	router.POST("/:id/members", h.AddHouseholdMember)
+	router.DELETE("/:id/members/:user_id", h.RemoveHouseholdMember)
+	router.GET("/:id/invitations", h.ListHouseholdInvitations)
+	router.POST("/:id/invitations", h.CreateHouseholdInvitation)
+	router.DELETE("/:id/invitations/:invitation_id", h.RevokeHouseholdInvitation)
+
+	router.GET("/invitations", h.ListMyInvitations)
+	router.POST("/invitations/accept", h.AcceptInvitationByCode)
+	router.POST("/invitations/:invitation_id/accept", h.AcceptInvitation)
+	router.POST("/invitations/:invitation_id/decline", h.DeclineInvitation)
+}
+
+// ListHouseholds lists the households the authenticated user belongs to
+// @Summary List households
+// @Tags households
+// @Produce json
+// @Success 200 {array} Household
+// @Router /households [get]
+func (h *Handler) ListHouseholds(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	households, err := h.db.ListHouseholdsByUser(c.Request.Context(), user.ID)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, households)
+}
+
+// CreateHousehold creates a new household owned by the authenticated user
+// @Summary Create household
+// @Tags households
+// @Accept json
+// @Produce json
+// @Param household body CreateHouseholdRequest true "Household"
+// @Success 201 {object} Household
+// @Router /households [post]
+func (h *Handler) CreateHousehold(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req struct {
+		Name string `json:"name" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	now := time.Now()
+	household := database.Household{
+		ID:        uuid.New().String(),
+		Name:      req.Name,
+		OwnerID:   user.ID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := h.db.CreateHousehold(c.Request.Context(), &household); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, household)
+}
+
+// GetHousehold retrieves a household and its members
+// @Summary Get household
+// @Tags households
+// @Produce json
+// @Param id path string true "Household ID"
+// @Success 200 {object} Household
+// @Router /households/{id} [get]
+func (h *Handler) GetHousehold(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	id := c.Param("id")
+
+	// Only members can see a household
+	if _, err := authz.Household(c.Request.Context(), h.db, id, user.ID, authz.ViewHousehold); err != nil {
+		apierror.Lookup(c, err, "household not found")
+		return
+	}
+
+	household, err := h.db.GetHouseholdByID(c.Request.Context(), id)
+	if err != nil {
+		apierror.Lookup(c, err, "household not found")
+		return
+	}
+
+	members, err := h.db.ListHouseholdMembers(c.Request.Context(), id)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"household": household,
+		"members":   members,
+	})
+}
+
+// DeleteHousehold deletes a household
+// @Summary Delete household
+// @Tags households
+// @Param id path string true "Household ID"
+// @Success 204
+// @Router /households/{id} [delete]
+func (h *Handler) DeleteHousehold(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	id := c.Param("id")
+
+	household, err := h.db.GetHouseholdByID(c.Request.Context(), id)
+	if err != nil {
+		apierror.Lookup(c, err, "household not found")
+		return
+	}
+
+	if !authz.CanDeleteHousehold(user.ID, household) {
+		apierror.Forbidden(c, "forbidden")
+		return
+	}
+
+	if err := h.db.DeleteHousehold(c.Request.Context(), id); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	audit.Record(c, audit.Entry{
+		Action:     audit.HouseholdDeleted,
+		TargetType: "household",
+		TargetID:   id,
+		Details:    map[string]any{"name": household.Name},
+	})
+
+	c.Status(http.StatusNoContent)
+}
+
+// AddHouseholdMember adds an existing user to a household by user ID
+// @Summary Add household member
+// @Tags households
+// @Accept json
+// @Produce json
+// @Param id path string true "Household ID"
+// @Success 201 {object} HouseholdMember
+// @Router /households/{id}/members [post]
+func (h *Handler) AddHouseholdMember(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	id := c.Param("id")
+
+	if !h.canManage(c, id, user.ID) {
+		apierror.Forbidden(c, "forbidden")
+		return
+	}
+
+	var req struct {
+		UserID string `json:"user_id" binding:"required"`
+		Role   string `json:"role"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	role, ok := normalizeRole(req.Role)
+	if !ok {
+		apierror.BadRequest(c, "invalid role")
+		return
+	}
+
+	if _, err := h.db.GetUserByID(c.Request.Context(), req.UserID); err != nil {
+		apierror.Lookup(c, err, "user not found")
+		return
+	}
+
+	if _, err := h.db.GetHouseholdMember(c.Request.Context(), id, req.UserID); err == nil {
+		apierror.Conflict(c, "user is already a member")
+		return
+	}
+
+	member := database.HouseholdMember{
+		HouseholdID: id,
+		UserID:      req.UserID,
+		Role:        role,
+		JoinedAt:    time.Now(),
+	}
+
+	if err := h.db.AddHouseholdMember(c.Request.Context(), &member); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	audit.Record(c, audit.Entry{
+		Action:     audit.MemberAdded,
+		TargetType: "household",
+		TargetID:   id,
+		Details:    map[string]any{"user_id": req.UserID, "role": role},
+	})
+
+	c.JSON(http.StatusCreated, member)
+}
+
+// RemoveHouseholdMember removes a member from a household; members may remove themselves
+// @Summary Remove household member
+// @Tags households
+// @Param id path string true "Household ID"
+// @Param user_id path string true "User ID"
+// @Success 204
+// @Router /households/{id}/members/{user_id} [delete]
+func (h *Handler) RemoveHouseholdMember(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	id := c.Param("id")
+	targetID := c.Param("user_id")
+
+	household, err := h.db.GetHouseholdByID(c.Request.Context(), id)
+	if err != nil {
+		apierror.Lookup(c, err, "household not found")
+		return
+	}
+
+	if targetID != user.ID && !h.canManage(c, id, user.ID) {
+		apierror.Forbidden(c, "forbidden")
+		return
+	}
+
+	if targetID == household.OwnerID {
+		apierror.BadRequest(c, "the owner cannot leave the household; delete it instead")
+		return
+	}
+
+	if err := h.db.RemoveHouseholdMember(c.Request.Context(), id, targetID); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	action := audit.MemberRemoved
+	if targetID == user.ID {
+		action = audit.MemberLeft
+	}
+	audit.Record(c, audit.Entry{
+		Action:     action,
+		TargetType: "household",
+		TargetID:   id,
+		Details:    map[string]any{"user_id": targetID},
+	})
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListHouseholdInvitations lists pending invitations for a household
+// @Summary List household invitations
+// @Tags households
+// @Produce json
+// @Param id path string true "Household ID"
+// @Success 200 {array} HouseholdInvitation
+// @Router /households/{id}/invitations [get]
+func (h *Handler) ListHouseholdInvitations(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	id := c.Param("id")
+
+	if !h.canManage(c, id, user.ID) {
+		apierror.Forbidden(c, "forbidden")
+		return
+	}
+
+	query := params.Query(c)
+	all := query.Bool("all")
+	page := query.Page(100)
+	if !query.Valid() {
+		return
+	}
+
+	filter := database.HouseholdInvitationFilter{
+		HouseholdID: id,
+		PendingOnly: !all,
+		Limit:       page.Limit,
+		Offset:      page.Offset,
+	}
+
+	invitations, err := h.db.ListHouseholdInvitations(c.Request.Context(), filter)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invitations)
+}
+
+// CreateHouseholdInvitation creates an invitation code for a household
+// @Summary Create household invitation
+// @Tags households
+// @Accept json
+// @Produce json
+// @Param id path string true "Household ID"
+// @Success 201 {object} InvitationResponse
+// @Router /households/{id}/invitations [post]
+func (h *Handler) CreateHouseholdInvitation(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	id := c.Param("id")
+
+	if !h.canManage(c, id, user.ID) {
+		apierror.Forbidden(c, "forbidden")
+		return
+	}
+
+	var req struct {
+		Email          string `json:"email" binding:"omitempty,email"`
+		Role           string `json:"role"`
+		ExpiresInHours int    `json:"expires_in_hours" binding:"omitempty,min=1"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	role, ok := normalizeRole(req.Role)
+	if !ok {
+		apierror.BadRequest(c, "invalid role")
+		return
+	}
+
+	expiry := defaultInvitationExpiry
+	if req.ExpiresInHours > 0 {
+		expiry = time.Duration(req.ExpiresInHours) * time.Hour
+		if expiry > maxInvitationExpiry {
+			expiry = maxInvitationExpiry
+		}
+	}
+
+	code, err := generateInvitationCode()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	now := time.Now()
+	invitation := database.HouseholdInvitation{
+		ID:          uuid.New().String(),
+		HouseholdID: id,
+		InvitedBy:   user.ID,
+		Email:       strings.TrimSpace(req.Email),
+		CodeHash:    database.HashInvitationCode(code),
+		Role:        role,
+		Status:      database.InvitationStatusPending,
+		ExpiresAt:   now.Add(expiry),
+		CreatedAt:   now,
+	}
+
+	if err := h.db.CreateHouseholdInvitation(c.Request.Context(), &invitation); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	audit.Record(c, audit.Entry{
+		Action:     audit.InvitationCreated,
+		TargetType: "household",
+		TargetID:   id,
+		Details:    map[string]any{"invitation_id": invitation.ID, "email": invitation.Email, "role": role},
+	})
+
+	// Invitations for an address are emailed there too. The invitation
+	// stands if that fails, as the code can still be passed on by hand.
+	emailed := false
+	if invitation.Email != "" && h.email.Enabled() {
+		emailed, err = h.sendInvitation(c.Request.Context(), &invitation, user, code)
+		if err != nil {
+			logger.Ctx(c.Request.Context()).Warn().Err(err).Str("invitation_id", invitation.ID).Msg("Failed to email household invitation")
+		}
+	}
+
+	// The code is only ever returned here; the database keeps a hash of it
+	c.JSON(http.StatusCreated, gin.H{
+		"invitation": invitation,
+		"code":       code,
+		"link":       h.invitationLink(code),
+		"emailed":    emailed,
+	})
+}
+
+// RevokeHouseholdInvitation revokes a pending invitation
+// @Summary Revoke household invitation
+// @Tags households
+// @Param id path string true "Household ID"
+// @Param invitation_id path string true "Invitation ID"
+// @Success 204
+// @Router /households/{id}/invitations/{invitation_id} [delete]
+func (h *Handler) RevokeHouseholdInvitation(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	id := c.Param("id")
+
+	if !h.canManage(c, id, user.ID) {
+		apierror.Forbidden(c, "forbidden")
+		return
+	}
+
+	invitation, err := h.db.GetHouseholdInvitationByID(c.Request.Context(), c.Param("invitation_id"))
+	if err != nil {
+		apierror.Lookup(c, err, "invitation not found")
+		return
+	}
+	if invitation.HouseholdID != id {
+		apierror.NotFound(c, "invitation not found")
+		return
+	}
+
+	if invitation.Status != database.InvitationStatusPending {
+		apierror.Conflict(c, "invitation is no longer pending")
+		return
+	}
+
+	now := time.Now()
+	invitation.Status = database.InvitationStatusRevoked
+	invitation.RespondedAt = &now
+	invitation.RespondedBy = &user.ID
+
+	if err := h.db.UpdateHouseholdInvitation(c.Request.Context(), invitation); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	audit.Record(c, audit.Entry{
+		Action:     audit.InvitationRevoked,
+		TargetType: "household",
+		TargetID:   id,
+		Details:    map[string]any{"invitation_id": invitation.ID},
+	})
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListMyInvitations lists pending invitations addressed to the authenticated user's verified email
+// @Summary List my invitations
+// @Tags households
+// @Produce json
+// @Success 200 {array} HouseholdInvitation
+// @Router /households/invitations [get]
+func (h *Handler) ListMyInvitations(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	query := params.Query(c)
+	page := query.Page(100)
+	if !query.Valid() {
+		return
+	}
+
+	// Anyone can register with someone else's address, so only a verified
+	// one is shown what was sent to it
+	if !user.EmailVerified {
+		c.JSON(http.StatusOK, []*database.HouseholdInvitation{})
+		return
+	}
+
+	filter := database.HouseholdInvitationFilter{
+		Email:       user.Email,
+		PendingOnly: true,
+		Limit:       page.Limit,
+		Offset:      page.Offset,
+	}
+
+	invitations, err := h.db.ListHouseholdInvitations(c.Request.Context(), filter)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invitations)
+}
+
+// AcceptInvitationByCode joins a household using an invitation code
+// @Summary Accept invitation by code
+// @Tags households
+// @Accept json
+// @Produce json
+// @Success 200 {object} HouseholdMember
+// @Router /households/invitations/accept [post]
+func (h *Handler) AcceptInvitationByCode(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	invitation, err := h.db.GetHouseholdInvitationByCodeHash(c.Request.Context(), database.HashInvitationCode(req.Code))
+	if err != nil {
+		apierror.Lookup(c, err, "invitation not found")
+		return
+	}
+
+	// Codes bound to an email address can only be redeemed by that account
+	if invitation.Email != "" && !strings.EqualFold(invitation.Email, user.Email) {
+		apierror.NotFound(c, "invitation not found")
+		return
+	}
+
+	h.acceptInvitation(c, invitation, user.ID)
+}
+
+// AcceptInvitation accepts an invitation addressed to the authenticated user's verified email
+// @Summary Accept invitation
+// @Tags households
+// @Produce json
+// @Param invitation_id path string true "Invitation ID"
+// @Success 200 {object} HouseholdMember
+// @Router /households/invitations/{invitation_id}/accept [post]
+func (h *Handler) AcceptInvitation(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	invitation, err := h.db.GetHouseholdInvitationByID(c.Request.Context(), c.Param("invitation_id"))
+	if err != nil {
+		apierror.Lookup(c, err, "invitation not found")
+		return
+	}
+	if invitation.Email == "" || !strings.EqualFold(invitation.Email, user.Email) {
+		apierror.NotFound(c, "invitation not found")
+		return
+	}
+	if !user.EmailVerified {
+		apierror.Abort(c, errUnverifiedEmail)
+		return
+	}
+
+	h.acceptInvitation(c, invitation, user.ID)
+}
+
+// DeclineInvitation declines an invitation addressed to the authenticated user's verified email
+// @Summary Decline invitation
+// @Tags households
+// @Param invitation_id path string true "Invitation ID"
+// @Success 204
+// @Router /households/invitations/{invitation_id}/decline [post]
+func (h *Handler) DeclineInvitation(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	invitation, err := h.db.GetHouseholdInvitationByID(c.Request.Context(), c.Param("invitation_id"))
+	if err != nil {
+		apierror.Lookup(c, err, "invitation not found")
+		return
+	}
+	if invitation.Email == "" || !strings.EqualFold(invitation.Email, user.Email) {
+		apierror.NotFound(c, "invitation not found")
+		return
+	}
+	if !user.EmailVerified {
+		apierror.Abort(c, errUnverifiedEmail)
+		return
+	}
+
+	if invitation.Status != database.InvitationStatusPending {
+		apierror.Conflict(c, "invitation is no longer pending")
+		return
+	}
+
+	now := time.Now()
+	invitation.Status = database.InvitationStatusDeclined
+	invitation.RespondedAt = &now
+	invitation.RespondedBy = &user.ID
+
+	if err := h.db.UpdateHouseholdInvitation(c.Request.Context(), invitation); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// acceptInvitation validates an invitation and adds the user to its household
+func (h *Handler) acceptInvitation(c *gin.Context, invitation *database.HouseholdInvitation, userID string) {
+	if invitation.Status != database.InvitationStatusPending {
+		apierror.Conflict(c, "invitation is no longer pending")
+		return
+	}
+
+	now := time.Now()
+	if now.After(invitation.ExpiresAt) {
+		apierror.Abort(c, apierror.New(http.StatusGone, apierror.CodeGone, "invitation has expired"))
+		return
+	}
+
+	if _, err := h.db.GetHouseholdMember(c.Request.Context(), invitation.HouseholdID, userID); err == nil {
+		apierror.Conflict(c, "already a member of this household")
+		return
+	}
+
+	member, err := joinHousehold(c.Request.Context(), h.db, invitation, userID, now)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	audit.Record(c, audit.Entry{
+		Action:     audit.InvitationAccepted,
+		UserID:     userID,
+		TargetType: "household",
+		TargetID:   invitation.HouseholdID,
+		Details:    map[string]any{"invitation_id": invitation.ID, "role": invitation.Role},
+	})
+
+	c.JSON(http.StatusOK, member)
+}
+
+// joinHousehold uses up an invitation and adds the user to its household.
+// Checks made on the invitation beforehand only serve better messages: it
+// is accepted with a conditional update, so of concurrent accepts, or an
+// accept racing a revoke, at most one takes effect.
+func joinHousehold(ctx context.Context, db database.Database, invitation *database.HouseholdInvitation, userID string, now time.Time) (*database.HouseholdMember, error) {
+	member := &database.HouseholdMember{
+		HouseholdID: invitation.HouseholdID,
+		UserID:      userID,
+		Role:        invitation.Role,
+		JoinedAt:    now,
+	}
+	err := db.WithTx(ctx, func(ctx context.Context) error {
+		accepted, err := db.AcceptHouseholdInvitation(ctx, invitation.ID, userID, now)
+		if err != nil {
+			return err
+		}
+		if !accepted {
+			return errInvitationClosed
+		}
+		return db.AddHouseholdMember(ctx, member)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// canManage reports whether the user may manage members and invitations of a household
+func (h *Handler) canManage(c *gin.Context, householdID, userID string) bool {
+	return authz.CanManageHousehold(c.Request.Context(), h.db, householdID, userID) == nil
+}
+
+// sendInvitation emails an invitation to the address it is for
+func (h *Handler) sendInvitation(ctx context.Context, invitation *database.HouseholdInvitation, inviter *auth.User, code string) (bool, error) {
+	household, err := h.db.GetHouseholdByID(ctx, invitation.HouseholdID)
+	if err != nil {
+		return false, err
+	}
+	name := strings.TrimSpace(inviter.FirstName + " " + inviter.LastName)
+	if name == "" {
+		name = inviter.Email
+	}
+	invite := email.HouseholdInvite{
+		HouseholdName: household.Name,
+		InviterName:   name,
+		Code:          code,
+		ExpiresAt:     invitation.ExpiresAt,
+	}
+	// Without a public URL the link is relative, which is no use in an email
+	if h.publicURL != "" {
+		invite.Link = h.invitationLink(code)
+	}
+	return h.email.SendHouseholdInvite(ctx, invitation.Email, invite)
+}
+
+// invitationLink builds the shareable link for an invitation code
+func (h *Handler) invitationLink(code string) string {
+	return h.publicURL + "/invite/" + code
+}
+
+// normalizeRole validates a requested role, defaulting to member. Ownership
+// cannot be granted through invitations.
+func normalizeRole(role string) (string, bool) {
+	switch role {
+	case "", database.HouseholdRoleMember:
+		return database.HouseholdRoleMember, true
+	case database.HouseholdRoleAdmin, database.HouseholdRoleParticipant:
+		return role, true
+	default:
+		return "", false
+	}
+}
+
+// generateInvitationCode returns a random, human-friendly invitation code
+func generateInvitationCode() (string, error) {
+	buf := make([]byte, invitationCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	code := make([]byte, invitationCodeLength)
+	for i, b := range buf {
+		code[i] = invitationCodeAlphabet[int(b)%len(invitationCodeAlphabet)]
+	}
+	return string(code), nil
+}
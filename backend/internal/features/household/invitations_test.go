@@ -0,0 +1,84 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package household
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/database/dbtest"
+)
+
+func TestJoinHousehold(t *testing.T) {
+	dbtest.Run(t, func(ctx context.Context, db database.Database) {
+		owner := dbtest.User(t, ctx, db)
+		now := time.Now()
+		household := &database.Household{ID: uuid.New().String(), Name: "Home", OwnerID: owner.ID, CreatedAt: now, UpdatedAt: now}
+		if err := db.CreateHousehold(ctx, household); err != nil {
+			t.Fatalf("CreateHousehold: %v", err)
+		}
+		invite := func() *database.HouseholdInvitation {
+			invitation := &database.HouseholdInvitation{
+				ID:          uuid.New().String(),
+				HouseholdID: household.ID,
+				InvitedBy:   owner.ID,
+				CodeHash:    database.HashInvitationCode(uuid.New().String()),
+				Role:        database.HouseholdRoleMember,
+				Status:      database.InvitationStatusPending,
+				ExpiresAt:   now.Add(time.Hour),
+				CreatedAt:   now,
+			}
+			if err := db.CreateHouseholdInvitation(ctx, invitation); err != nil {
+				t.Fatalf("CreateHouseholdInvitation: %v", err)
+			}
+			return invitation
+		}
+
+		// Two people accept the same code, both having read it while pending
+		invitation := invite()
+		first, second := dbtest.User(t, ctx, db), dbtest.User(t, ctx, db)
+		if _, err := joinHousehold(ctx, db, invitation, first.ID, now); err != nil {
+			t.Fatalf("first accept: %v", err)
+		}
+		if _, err := joinHousehold(ctx, db, invitation, second.ID, now); !errors.Is(err, errInvitationClosed) {
+			t.Fatalf("second accept = %v, want %v", err, errInvitationClosed)
+		}
+		if _, err := db.GetHouseholdMember(ctx, household.ID, second.ID); err == nil {
+			t.Error("the second accept added a member")
+		}
+
+		// The invitation is revoked after it was read
+		revoked := invite()
+		stale := *revoked
+		revoked.Status = database.InvitationStatusRevoked
+		if err := db.UpdateHouseholdInvitation(ctx, revoked); err != nil {
+			t.Fatalf("UpdateHouseholdInvitation: %v", err)
+		}
+		if _, err := joinHousehold(ctx, db, &stale, second.ID, now); !errors.Is(err, errInvitationClosed) {
+			t.Fatalf("accepting a revoked invitation = %v, want %v", err, errInvitationClosed)
+		}
+		if got, err := db.GetHouseholdInvitationByID(ctx, revoked.ID); err != nil || got.Status != database.InvitationStatusRevoked {
+			t.Errorf("revoked invitation = %+v, %v, want it still revoked", got, err)
+		}
+	})
+}
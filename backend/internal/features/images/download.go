@@ -0,0 +1,63 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package images
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/rghsoftware/space-food/internal/config"
+	"github.com/rghsoftware/space-food/internal/fetch"
+	"github.com/rghsoftware/space-food/internal/storage"
+)
+
+// Downloader copies images hosted on other sites into storage, so imported
+// recipes don't hotlink them
+type Downloader struct {
+	store   storage.Provider
+	fetcher *fetch.Client
+}
+
+// NewDownloader creates a downloader that accepts images up to
+// storage.maxuploadsize, the same limit as uploads, from the addresses the
+// fetch policy allows
+func NewDownloader(cfg *config.Config, store storage.Provider) *Downloader {
+	return &Downloader{
+		store:   store,
+		fetcher: fetch.New(cfg.Fetch, int64(max(cfg.Storage.MaxUploadSize, 1))<<20),
+	}
+}
+
+// IsRemote reports whether an ImageURL points at another site rather than
+// at an image we store
+func IsRemote(imageURL string) bool {
+	u, err := url.Parse(imageURL)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// Download fetches a remote image, processes it like an upload and returns
+// the URL to put in ImageURL
+func (d *Downloader) Download(ctx context.Context, userID, imageURL string) (string, error) {
+	resp, err := d.fetcher.Get(ctx, imageURL, "image/*")
+	if err != nil {
+		return "", fmt.Errorf("image %s: %w", imageURL, err)
+	}
+	return Save(ctx, d.store, userID, resp.Body)
+}
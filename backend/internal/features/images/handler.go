@@ -0,0 +1,199 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package images
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/config"
+	"github.com/rghsoftware/space-food/internal/storage"
+)
+
+// urlPrefix is where stored images are served; recipes keep it in ImageURL
+const urlPrefix = "/api/v1/images/"
+
+// Handler serves stored images
+type Handler struct {
+	store  storage.Provider
+	expiry time.Duration
+}
+
+// NewHandler creates a new image handler
+func NewHandler(cfg *config.Config, store storage.Provider) *Handler {
+	return &Handler{
+		store:  store,
+		expiry: time.Duration(max(cfg.Storage.URLExpiry, 1)) * time.Minute,
+	}
+}
+
+// RegisterRoutes registers image routes. They need no authentication:
+// image IDs are random and unguessable, so links work in <img> tags.
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/:owner/:id", h.ServeImage)
+}
+
+// ServeImage sends a stored image, or with S3 storage redirects to a
+// short-lived signed URL for it
+// @Summary Get image
+// @Tags images
+// @Produce image/jpeg
+// @Param size query string false "thumbnail, medium (default) or large"
+// @Router /images/{owner}/{id} [get]
+func (h *Handler) ServeImage(c *gin.Context) {
+	size := c.DefaultQuery("size", DefaultSize)
+	if !IsSize(size) {
+		apierror.BadRequest(c, "size must be one of thumbnail, medium, large")
+		return
+	}
+	owner, id := c.Param("owner"), c.Param("id")
+	if !validID(owner) || !validID(id) {
+		apierror.NotFound(c, "image not found")
+		return
+	}
+	key := path.Join(prefix(owner), id, size+".jpg")
+
+	if signer, ok := h.store.(storage.Signer); ok {
+		url, err := signer.SignedURL(c.Request.Context(), key, h.expiry)
+		if err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+		// Let clients reuse the redirect while the signature is still good
+		c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", int(h.expiry.Seconds()/2)))
+		c.Redirect(http.StatusFound, url)
+		return
+	}
+
+	file, err := h.store.Open(c.Request.Context(), key)
+	if errors.Is(err, storage.ErrNotFound) {
+		apierror.NotFound(c, "image not found")
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	defer file.Close()
+
+	// A new upload always gets a new ID, so the content never changes
+	c.DataFromReader(http.StatusOK, -1, "image/jpeg", file, map[string]string{
+		"Cache-Control": "public, max-age=31536000, immutable",
+	})
+}
+
+// Save processes an uploaded image, stores its variants for the user and
+// returns the URL to put in ImageURL
+func Save(ctx context.Context, store storage.Provider, userID string, data []byte) (string, error) {
+	variants, err := Process(data)
+	if err != nil {
+		return "", err
+	}
+
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	id := hex.EncodeToString(random)
+
+	base := path.Join(prefix(userID), id)
+	for _, v := range variants {
+		key := path.Join(base, v.Name+".jpg")
+		if err := store.Put(ctx, key, bytes.NewReader(v.Data), int64(len(v.Data)), "image/jpeg"); err != nil {
+			// Don't leave a partial set behind
+			_ = storage.DeleteAll(ctx, store, base+"/")
+			return "", err
+		}
+	}
+	return urlPrefix + userID + "/" + id, nil
+}
+
+// Remove deletes the stored variants behind an ImageURL. URLs of images
+// hosted elsewhere are ignored.
+func Remove(ctx context.Context, store storage.Provider, imageURL string) error {
+	owner, id, ok := strings.Cut(strings.TrimPrefix(imageURL, urlPrefix), "/")
+	if !strings.HasPrefix(imageURL, urlPrefix) || !ok || !validID(owner) || !validID(id) {
+		return nil
+	}
+	return storage.DeleteAll(ctx, store, path.Join(prefix(owner), id)+"/")
+}
+
+// RemoveAll deletes every image stored for a user
+func RemoveAll(ctx context.Context, store storage.Provider, userID string) error {
+	if !validID(userID) {
+		return nil
+	}
+	return storage.DeleteAll(ctx, store, prefix(userID)+"/")
+}
+
+// ForEach calls fn with every image file stored for a user, named
+// <id>/<size>.jpg
+func ForEach(ctx context.Context, store storage.Provider, userID string, fn func(name string, r io.Reader) error) error {
+	if !validID(userID) {
+		return nil
+	}
+	keys, err := store.List(ctx, prefix(userID)+"/")
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		file, err := store.Open(ctx, key)
+		if errors.Is(err, storage.ErrNotFound) {
+			continue // removed since it was listed
+		}
+		if err != nil {
+			return err
+		}
+		err = fn(strings.TrimPrefix(key, prefix(userID)+"/"), file)
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prefix is the storage key prefix holding a user's images
+func prefix(userID string) string {
+	return "images/" + userID
+}
+
+// validID accepts the hex and UUID strings used in image paths
+func validID(s string) bool {
+	if s == "" || len(s) > 64 {
+		return false
+	}
+	for _, c := range s {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,282 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package images
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"image/jpeg"
+
+	// Registered for image.Decode
+	_ "image/gif"
+	_ "image/png"
+)
+
+// maxPixels rejects images whose decoded size would exhaust memory
+const maxPixels = 40_000_000
+
+// jpegQuality is used for every stored variant
+const jpegQuality = 85
+
+// ErrUnsupported is returned for data that is not a JPEG, PNG or GIF image
+var ErrUnsupported = errors.New("unsupported image format; use JPEG, PNG or GIF")
+
+// ErrTooLarge is returned for images with too many pixels to process
+var ErrTooLarge = errors.New("image dimensions are too large")
+
+// Size is a stored variant, scaled so its longest side is at most MaxSide
+type Size struct {
+	Name    string
+	MaxSide int
+}
+
+// Sizes lists the variants kept for every image, largest first
+var Sizes = []Size{
+	{Name: "large", MaxSide: 1600},
+	{Name: "medium", MaxSide: 800},
+	{Name: "thumbnail", MaxSide: 320},
+}
+
+// DefaultSize is served when no size is asked for
+const DefaultSize = "medium"
+
+// Variant is one encoded size of a processed image
+type Variant struct {
+	Name   string
+	Width  int
+	Height int
+	Data   []byte
+}
+
+// Process decodes an image, turns it upright and encodes each of Sizes as
+// JPEG. Re-encoding drops EXIF and other metadata, including location.
+func Process(data []byte) ([]Variant, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+
+	src := flatten(img)
+	if format == "jpeg" {
+		src = orient(src, jpegOrientation(data))
+	}
+
+	// Each size is scaled from the previous, larger one
+	variants := make([]Variant, 0, len(Sizes))
+	for _, size := range Sizes {
+		w, h := fit(src.Bounds().Dx(), src.Bounds().Dy(), size.MaxSide)
+		src = resize(src, w, h)
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, src, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, err
+		}
+		variants = append(variants, Variant{Name: size.Name, Width: w, Height: h, Data: buf.Bytes()})
+	}
+	return variants, nil
+}
+
+// IsSize reports whether name is one of Sizes
+func IsSize(name string) bool {
+	for _, size := range Sizes {
+		if size.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// fit scales w x h down so neither side exceeds maxSide, never up
+func fit(w, h, maxSide int) (int, int) {
+	if w <= maxSide && h <= maxSide {
+		return w, h
+	}
+	if w >= h {
+		return maxSide, max(h*maxSide/w, 1)
+	}
+	return max(w*maxSide/h, 1), maxSide
+}
+
+// flatten copies an image into RGBA at the origin, over a white background
+// since JPEG has no transparency
+func flatten(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Over)
+	return dst
+}
+
+// resize scales by averaging the source pixels each destination pixel
+// covers, which keeps downscaled photos free of aliasing
+func resize(src *image.RGBA, w, h int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	if w == sw && h == sh {
+		return src
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0 := y * sh / h
+		y1 := max((y+1)*sh/h, y0+1)
+		for x := 0; x < w; x++ {
+			x0 := x * sw / w
+			x1 := max((x+1)*sw/w, x0+1)
+
+			var r, g, b, a, n int
+			for sy := y0; sy < y1; sy++ {
+				i := src.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					r += int(src.Pix[i])
+					g += int(src.Pix[i+1])
+					b += int(src.Pix[i+2])
+					a += int(src.Pix[i+3])
+					n++
+					i += 4
+				}
+			}
+
+			i := dst.PixOffset(x, y)
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(b / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
+
+// orient applies an EXIF orientation (1-8) so the image displays upright.
+// The cases describe how the stored pixels sit relative to upright.
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	w, h := sw, sh
+	if orientation >= 5 {
+		w, h = sh, sw
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // mirrored
+				sx, sy = sw-1-x, y
+			case 3: // upside down
+				sx, sy = sw-1-x, sh-1-y
+			case 4: // mirrored upside down
+				sx, sy = x, sh-1-y
+			case 5: // mirrored, rotated
+				sx, sy = y, x
+			case 6: // rotated a quarter turn anticlockwise
+				sx, sy = y, sh-1-x
+			case 7: // mirrored, rotated the other way
+				sx, sy = sw-1-y, sh-1-x
+			case 8: // rotated a quarter turn clockwise
+				sx, sy = sw-1-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):][:4], src.Pix[src.PixOffset(sx, sy):][:4])
+		}
+	}
+	return dst
+}
+
+// jpegOrientation reads the EXIF orientation tag of a JPEG, or 1 when it
+// has none
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		switch {
+		case marker == 0xFF: // fill byte
+			i++
+			continue
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7): // no payload
+			i += 2
+			continue
+		case marker == 0xDA || marker == 0xD9: // image data starts; no EXIF seen
+			return 1
+		}
+
+		n := int(binary.BigEndian.Uint16(data[i+2:]))
+		if n < 2 || i+2+n > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+n]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		i += 2 + n
+	}
+	return 1
+}
+
+// exifOrientation finds the orientation tag in the first IFD of EXIF data
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for k := 0; k < entries; k++ {
+		entry := ifd + 2 + k*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if v := int(order.Uint16(tiff[entry+8:])); v >= 1 && v <= 8 {
+				return v
+			}
+			return 1
+		}
+	}
+	return 1
+}
@@ -0,0 +1,142 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package jobs
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/api/params"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/audit"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/scheduler"
+)
+
+// Handler handles background job administration HTTP requests
+type Handler struct {
+	db        database.Database
+	scheduler *scheduler.Scheduler
+}
+
+// NewHandler creates a new jobs handler
+func NewHandler(db database.Database, jobScheduler *scheduler.Scheduler) *Handler {
+	return &Handler{
+		db:        db,
+		scheduler: jobScheduler,
+	}
+}
+
+// RegisterRoutes registers job routes. The group must be restricted to
+// administrators with middleware.RequireAdmin.
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("", h.ListJobs)
+	router.GET("/runs", h.ListRuns)
+	router.POST("/:name/run", h.RunJob)
+}
+
+// ListJobs lists registered jobs with their schedules and latest status
+// @Summary List background jobs
+// @Tags admin
+// @Produce json
+// @Router /admin/jobs [get]
+func (h *Handler) ListJobs(c *gin.Context) {
+	jobs, err := h.scheduler.Jobs(c.Request.Context())
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, jobs)
+}
+
+// ListRuns lists job runs, newest first
+// @Summary List job runs
+// @Tags admin
+// @Produce json
+// @Param job query string false "Job name"
+// @Param status query string false "running, succeeded or failed"
+// @Param limit query int false "Maximum runs to return (1-200, default 50)"
+// @Param offset query int false "Runs to skip"
+// @Param cursor query string false "Keyset paging: empty for the first page, then next_cursor"
+// @Param include_total query bool false "Count all matching runs"
+// @Router /admin/jobs/runs [get]
+func (h *Handler) ListRuns(c *gin.Context) {
+	query := params.Query(c)
+	jobName := query.String("job")
+	status := query.String("status", scheduler.StatusRunning, scheduler.StatusSucceeded, scheduler.StatusFailed)
+	page := query.Page(50)
+	withTotal := query.Bool("include_total")
+	if !query.Valid() {
+		return
+	}
+
+	ctx := c.Request.Context()
+	filter := database.JobRunFilter{
+		JobName: jobName,
+		Status:  status,
+		After:   page.After,
+		Limit:   page.Fetch(),
+		Offset:  page.Offset,
+	}
+	runs, err := h.db.ListJobRuns(ctx, filter)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	var total *int
+	if withTotal {
+		count, err := h.db.CountJobRuns(ctx, filter)
+		if err != nil {
+			apierror.Respond(c, err)
+			return
+		}
+		total = &count
+	}
+
+	runs, next := params.Trim(runs, page, func(run *database.JobRun) database.Cursor {
+		return database.Cursor{Time: run.StartedAt, ID: run.ID}
+	})
+	params.WriteList(c, runs, page, next, total)
+}
+
+// RunJob starts a job immediately, outside its schedule
+// @Summary Run job now
+// @Tags admin
+// @Produce json
+// @Router /admin/jobs/{name}/run [post]
+func (h *Handler) RunJob(c *gin.Context) {
+	run, err := h.scheduler.Trigger(c.Request.Context(), c.Param("name"))
+	switch {
+	case errors.Is(err, scheduler.ErrJobNotFound):
+		apierror.NotFound(c, err.Error())
+		return
+	case errors.Is(err, scheduler.ErrJobRunning):
+		apierror.Conflict(c, err.Error())
+		return
+	case err != nil:
+		apierror.Respond(c, err)
+		return
+	}
+	audit.Record(c, audit.Entry{Action: audit.JobTriggered, TargetType: "job_run", TargetID: run.ID, Details: map[string]any{"job": run.JobName}})
+
+	c.JSON(http.StatusAccepted, run)
+}
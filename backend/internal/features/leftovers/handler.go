@@ -0,0 +1,479 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package leftovers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/api/params"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/authz"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/events"
+	"github.com/rghsoftware/space-food/internal/features/mealtime"
+	"github.com/rghsoftware/space-food/internal/middleware"
+)
+
+// Handler handles leftover HTTP requests
+type Handler struct {
+	db     database.Database
+	events *events.Bus
+}
+
+// NewHandler creates a new leftovers handler
+func NewHandler(db database.Database, bus *events.Bus) *Handler {
+	return &Handler{
+		db:     db,
+		events: bus,
+	}
+}
+
+// RegisterRoutes registers leftover routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("", h.ListLeftovers)
+	router.POST("", h.CreateLeftover)
+	router.GET("/food-types", h.ListFoodTypes)
+	router.GET("/labels", h.GetLabels)
+	router.PUT("/:id", h.UpdateLeftover)
+	router.POST("/:id/eat", h.EatLeftover)
+	router.DELETE("/:id", h.DeleteLeftover)
+}
+
+// leftoverResponse adds the computed freshness to stored leftovers
+type leftoverResponse struct {
+	*database.Leftover
+	Status   string  `json:"status"`
+	DaysLeft float64 `json:"days_left"`
+}
+
+func toResponse(leftover *database.Leftover, now time.Time) leftoverResponse {
+	daysLeft := leftover.EatBy.Sub(now).Hours() / 24
+	return leftoverResponse{
+		Leftover: leftover,
+		Status:   Status(leftover, now),
+		DaysLeft: float64(int(daysLeft*10)) / 10,
+	}
+}
+
+// ListLeftovers lists the authenticated user's leftovers, soonest eat-by first
+// @Summary List leftovers
+// @Tags leftovers
+// @Produce json
+// @Param eat_soon query bool false "Only leftovers due within a day or already past their eat-by time"
+// @Param include_finished query bool false "Include finished leftovers"
+// @Param storage query string false "Only leftovers in the fridge or the freezer"
+// @Router /leftovers [get]
+func (h *Handler) ListLeftovers(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	query := params.Query(c)
+	includeFinished := query.Bool("include_finished")
+	eatSoon := query.Bool("eat_soon")
+	storage := query.String("storage", database.LeftoverStorageFridge, database.LeftoverStorageFreezer)
+	if !query.Valid() {
+		return
+	}
+
+	now := time.Now()
+	filter := database.LeftoverFilter{
+		UserID:          user.ID,
+		IncludeFinished: includeFinished,
+		Storage:         storage,
+	}
+	if eatSoon {
+		before := now.Add(eatSoonWithin).UTC()
+		filter.EatByBefore = &before
+	}
+
+	leftovers, err := h.db.ListLeftovers(c.Request.Context(), filter)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	resp := make([]leftoverResponse, 0, len(leftovers))
+	for _, leftover := range leftovers {
+		resp = append(resp, toResponse(leftover, now))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// CreateLeftover records leftovers, estimating the eat-by date from the
+// food type and storage unless one is given. Frozen leftovers get the food
+// type's reheat instructions and can be given a thaw time, or the time
+// they'll be eaten to thaw a day before.
+// @Summary Record leftovers
+// @Tags leftovers
+// @Accept json
+// @Produce json
+// @Router /leftovers [post]
+func (h *Handler) CreateLeftover(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req struct {
+		Name      string     `json:"name"`
+		RecipeID  string     `json:"recipe_id"`
+		FoodType  string     `json:"food_type"`
+		Portions  float64    `json:"portions" binding:"required,gt=0"`
+		Container string     `json:"container"`
+		Storage   string     `json:"storage" binding:"omitempty,oneof=fridge freezer"`
+		StoredAt  *time.Time `json:"stored_at"`
+		EatBy     *time.Time `json:"eat_by"`
+		Notes     string     `json:"notes"`
+		Reheat    string     `json:"reheat"`
+		ThawAt    *time.Time `json:"thaw_at"`
+		EatAt     *time.Time `json:"eat_at"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	var recipe *database.Recipe
+	if req.RecipeID != "" {
+		var err error
+		recipe, err = h.db.GetRecipeByID(c.Request.Context(), req.RecipeID)
+		if err != nil || !authz.Owns(user.ID, recipe.UserID) {
+			apierror.BadRequest(c, "recipe not found")
+			return
+		}
+		if req.Name == "" {
+			req.Name = recipe.Title
+		}
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		apierror.BadRequest(c, "name or recipe_id is required")
+		return
+	}
+	if req.FoodType == "" {
+		req.FoodType = GuessFoodType(req.Name, recipe)
+	} else if !IsFoodType(req.FoodType) {
+		apierror.BadRequest(c, "unknown food type")
+		return
+	}
+	if req.Storage == "" {
+		req.Storage = database.LeftoverStorageFridge
+	}
+	thawAt, ok := thawTime(c, req.Storage, req.ThawAt, req.EatAt)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	storedAt := now
+	if req.StoredAt != nil {
+		storedAt = *req.StoredAt
+	}
+
+	leftover := database.Leftover{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		Name:      req.Name,
+		FoodType:  req.FoodType,
+		Portions:  req.Portions,
+		Container: req.Container,
+		Storage:   req.Storage,
+		StoredAt:  storedAt.UTC(),
+		EatBy:     EatBy(req.FoodType, req.Storage, storedAt).UTC(),
+		Notes:     req.Notes,
+		Reheat:    strings.TrimSpace(req.Reheat),
+		ThawAt:    thawAt,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if leftover.Storage == database.LeftoverStorageFreezer {
+		Freeze(&leftover, storedAt)
+	}
+	if recipe != nil {
+		leftover.RecipeID = &recipe.ID
+	}
+	if req.EatBy != nil {
+		leftover.EatBy = req.EatBy.UTC()
+	}
+
+	if err := h.db.CreateLeftover(c.Request.Context(), &leftover); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toResponse(&leftover, now))
+}
+
+// ListFoodTypes returns the eat-by guide used to estimate dates
+// @Summary List leftover food types
+// @Tags leftovers
+// @Produce json
+// @Router /leftovers/food-types [get]
+func (h *Handler) ListFoodTypes(c *gin.Context) {
+	c.JSON(http.StatusOK, FoodTypes())
+}
+
+// UpdateLeftover updates leftovers. Moving them between fridge and freezer
+// restarts the eat-by clock for the new storage, and taking them out of
+// the freezer drops the thaw reminder.
+// @Summary Update leftovers
+// @Tags leftovers
+// @Accept json
+// @Produce json
+// @Router /leftovers/{id} [put]
+func (h *Handler) UpdateLeftover(c *gin.Context) {
+	leftover, ok := h.ownedLeftover(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Name      *string    `json:"name"`
+		FoodType  *string    `json:"food_type"`
+		Portions  *float64   `json:"portions" binding:"omitempty,gte=0"`
+		Container *string    `json:"container"`
+		Storage   *string    `json:"storage" binding:"omitempty,oneof=fridge freezer"`
+		EatBy     *time.Time `json:"eat_by"`
+		Notes     *string    `json:"notes"`
+		Reheat    *string    `json:"reheat"`
+		ThawAt    *time.Time `json:"thaw_at"`
+		EatAt     *time.Time `json:"eat_at"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	storage := leftover.Storage
+	if req.Storage != nil {
+		storage = *req.Storage
+	}
+	thawAt, ok := thawTime(c, storage, req.ThawAt, req.EatAt)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	if req.Name != nil && strings.TrimSpace(*req.Name) != "" {
+		leftover.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.FoodType != nil {
+		if !IsFoodType(*req.FoodType) {
+			apierror.BadRequest(c, "unknown food type")
+			return
+		}
+		leftover.FoodType = *req.FoodType
+	}
+	if req.Container != nil {
+		leftover.Container = *req.Container
+	}
+	if req.Notes != nil {
+		leftover.Notes = *req.Notes
+	}
+	if req.Reheat != nil {
+		leftover.Reheat = strings.TrimSpace(*req.Reheat)
+	}
+	if req.Storage != nil && *req.Storage != leftover.Storage {
+		if *req.Storage == database.LeftoverStorageFreezer {
+			Freeze(leftover, now)
+		} else {
+			leftover.Storage = *req.Storage
+			leftover.EatBy = EatBy(leftover.FoodType, leftover.Storage, now).UTC()
+			leftover.ThawAt = nil
+			leftover.ThawRemindedAt = nil
+		}
+	}
+	if thawAt != nil {
+		leftover.ThawAt = thawAt
+		leftover.ThawRemindedAt = nil
+	}
+	if req.EatBy != nil {
+		leftover.EatBy = req.EatBy.UTC()
+	}
+	if req.Portions != nil {
+		leftover.Portions = *req.Portions
+		leftover.FinishedAt = nil
+		if leftover.Portions == 0 {
+			leftover.FinishedAt = &now
+		}
+	}
+	leftover.UpdatedAt = now
+
+	if err := h.db.UpdateLeftover(c.Request.Context(), leftover); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toResponse(leftover, now))
+}
+
+// EatLeftover logs portions of leftovers as a meal and takes them out of
+// the container
+// @Summary Eat leftovers
+// @Tags leftovers
+// @Accept json
+// @Produce json
+// @Router /leftovers/{id}/eat [post]
+func (h *Handler) EatLeftover(c *gin.Context) {
+	leftover, ok := h.ownedLeftover(c)
+	if !ok {
+		return
+	}
+	if leftover.FinishedAt != nil {
+		apierror.Conflict(c, "these leftovers are already finished")
+		return
+	}
+
+	var req struct {
+		Portions float64 `json:"portions" binding:"omitempty,gt=0"`
+		MealType string  `json:"meal_type"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		apierror.Invalid(c, err)
+		return
+	}
+	if req.Portions == 0 {
+		req.Portions = 1
+	}
+	req.Portions = min(req.Portions, leftover.Portions)
+	if req.MealType != "" && !mealtime.IsMealType(req.MealType) {
+		apierror.BadRequest(c, "invalid meal type")
+		return
+	}
+
+	ctx := c.Request.Context()
+	now := time.Now()
+
+	log := database.NutritionLog{
+		ID:        uuid.New().String(),
+		UserID:    leftover.UserID,
+		Date:      now,
+		MealType:  req.MealType,
+		RecipeID:  leftover.RecipeID,
+		FoodName:  leftover.Name + " (leftovers)",
+		Servings:  req.Portions,
+		CreatedAt: now,
+	}
+	if leftover.RecipeID != nil {
+		if recipe, err := h.db.GetRecipeByID(ctx, *leftover.RecipeID); err == nil && recipe.NutritionInfo != nil {
+			log.NutritionInfo = *recipe.NutritionInfo
+		}
+	}
+
+	leftover.Portions -= req.Portions
+	if leftover.Portions <= 0 {
+		leftover.Portions = 0
+		leftover.FinishedAt = &now
+	}
+	leftover.UpdatedAt = now
+
+	// Log the meal and take the portions in one step, so a failure never
+	// leaves the meal logged with the leftovers untouched
+	err := h.db.WithTx(ctx, func(ctx context.Context) error {
+		if err := h.db.CreateNutritionLog(ctx, &log); err != nil {
+			return err
+		}
+		return h.db.UpdateLeftover(ctx, leftover)
+	})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	h.events.Publish(ctx, events.NewMealLogged(&log))
+
+	c.JSON(http.StatusOK, gin.H{
+		"leftover":      toResponse(leftover, now),
+		"nutrition_log": log,
+	})
+}
+
+// DeleteLeftover deletes leftovers, e.g. when they were thrown out
+// @Summary Delete leftovers
+// @Tags leftovers
+// @Param id path string true "Leftover ID"
+// @Success 204
+// @Router /leftovers/{id} [delete]
+func (h *Handler) DeleteLeftover(c *gin.Context) {
+	leftover, ok := h.ownedLeftover(c)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteLeftover(c.Request.Context(), leftover.ID); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// thawTime works out the thaw time of a create or update request: thawAt
+// as given, or a day before eatAt. It writes an error response when a
+// time is given for leftovers that aren't frozen.
+func thawTime(c *gin.Context, storage string, thawAt, eatAt *time.Time) (*time.Time, bool) {
+	if thawAt == nil && eatAt == nil {
+		return nil, true
+	}
+	if storage != database.LeftoverStorageFreezer {
+		apierror.BadRequest(c, "thaw_at and eat_at are only for frozen leftovers")
+		return nil, false
+	}
+	var at time.Time
+	if thawAt != nil {
+		at = thawAt.UTC()
+	} else {
+		at = ThawAt(*eatAt).UTC()
+	}
+	return &at, true
+}
+
+// ownedLeftover loads the leftovers named in the path, writing an error
+// response unless they belong to the authenticated user
+func (h *Handler) ownedLeftover(c *gin.Context) (*database.Leftover, bool) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return nil, false
+	}
+
+	leftover, err := h.db.GetLeftoverByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierror.Lookup(c, err, "leftovers not found")
+		return nil, false
+	}
+
+	if !authz.Owns(user.ID, leftover.UserID) {
+		apierror.Forbidden(c, "forbidden")
+		return nil, false
+	}
+
+	return leftover, true
+}
@@ -0,0 +1,206 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package leftovers
+
+import (
+	"bytes"
+	"context"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/api/params"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/authz"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/dietary"
+	"github.com/rghsoftware/space-food/internal/features/mealtime"
+	"github.com/rghsoftware/space-food/internal/middleware"
+)
+
+// maxLabels bounds how many leftovers one label sheet covers
+const maxLabels = 50
+
+// labelDate is how dates are written on labels: short, and unambiguous
+// whichever way round the reader writes day and month
+const labelDate = "2 Jan 2006"
+
+// Label is what goes on a container of leftovers
+type Label struct {
+	LeftoverID string   `json:"leftover_id"`
+	Name       string   `json:"name"`
+	Portions   float64  `json:"portions"`
+	Container  string   `json:"container,omitempty"`
+	Storage    string   `json:"storage"`
+	StoredOn   string   `json:"stored_on"`
+	UseBy      string   `json:"use_by"`
+	ThawOn     string   `json:"thaw_on,omitempty"`
+	Reheat     string   `json:"reheat,omitempty"`
+	Contains   []string `json:"contains"` // food groups in the recipe's ingredients, such as dairy or gluten
+}
+
+// NewLabel writes the label for leftovers, with dates in loc. The recipe
+// they came from, if any, gives the food groups they contain, for
+// allergies and diets.
+func NewLabel(leftover *database.Leftover, recipe *database.Recipe, loc *time.Location) Label {
+	label := Label{
+		LeftoverID: leftover.ID,
+		Name:       leftover.Name,
+		Portions:   leftover.Portions,
+		Container:  leftover.Container,
+		Storage:    leftover.Storage,
+		StoredOn:   leftover.StoredAt.In(loc).Format(labelDate),
+		UseBy:      leftover.EatBy.In(loc).Format(labelDate),
+		Reheat:     leftover.Reheat,
+		Contains:   []string{},
+	}
+	if leftover.ThawAt != nil {
+		label.ThawOn = leftover.ThawAt.In(loc).Format(labelDate)
+	}
+	if recipe != nil {
+		for _, ingredient := range recipe.Ingredients {
+			for _, group := range dietary.MatchGroups(ingredient.Name) {
+				label.Contains = appendUnique(label.Contains, group)
+			}
+		}
+	}
+	return label
+}
+
+func appendUnique(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
+
+// labelTemplate lays labels out in a grid that cuts into rectangles, each
+// repeated for the copies asked for
+var labelTemplate = template.Must(template.New("labels").Funcs(template.FuncMap{
+	"portions": func(p float64) string { return strconv.FormatFloat(p, 'f', -1, 64) },
+	"join":     strings.Join,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Labels</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; margin: 1rem; color: #000; }
+.sheet { display: grid; grid-template-columns: repeat(auto-fill, minmax(16rem, 1fr)); gap: 0.5rem; }
+.label { border: 1px dashed #666; padding: 0.5rem 0.75rem; font-size: 0.8rem; line-height: 1.35; break-inside: avoid; }
+.label h2 { font-size: 1rem; margin: 0 0 0.25rem; }
+.label p { margin: 0.15rem 0; }
+@media print { body { margin: 0; } }
+</style>
+</head>
+<body>
+<div class="sheet">{{range .}}
+<div class="label">
+<h2>{{.Name}}</h2>
+<p>{{portions .Portions}} portion{{if ne .Portions 1.0}}s{{end}}{{with .Container}} · {{.}}{{end}}</p>
+<p>{{if eq .Storage "freezer"}}Frozen{{else}}Made{{end}} {{.StoredOn}} · Use by <strong>{{.UseBy}}</strong></p>
+{{with .ThawOn}}<p>Thaw in the fridge on {{.}}</p>{{end}}
+{{with .Reheat}}<p>{{.}}</p>{{end}}
+{{with .Contains}}<p>Contains: {{join . ", "}}</p>{{end}}
+</div>{{end}}
+</div>
+</body>
+</html>
+`))
+
+// GetLabels prints labels for the authenticated user's leftovers, such as
+// the portions of a freezer batch
+// @Summary Leftover labels
+// @Tags leftovers
+// @Produce html
+// @Produce json
+// @Param ids query string true "Comma-separated leftover IDs"
+// @Param copies query int false "Labels per leftover, e.g. one per container (1-20)"
+// @Param format query string false "html (default) or json"
+// @Router /leftovers/labels [get]
+func (h *Handler) GetLabels(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	query := params.Query(c)
+	copies := query.Int("copies", 1, 1, 20)
+	format := query.String("format", "html", "json")
+	if !query.Valid() {
+		return
+	}
+	ids := []string{}
+	for _, id := range strings.Split(c.Query("ids"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 || len(ids) > maxLabels {
+		apierror.BadRequest(c, "ids must list 1 to "+strconv.Itoa(maxLabels)+" leftovers")
+		return
+	}
+
+	ctx := c.Request.Context()
+	loc := mealtime.Location(mealtime.LoadPreferences(c, h.db, user.ID))
+	labels := make([]Label, 0, len(ids))
+	for _, id := range ids {
+		leftover, err := h.db.GetLeftoverByID(ctx, id)
+		if err != nil || !authz.Owns(user.ID, leftover.UserID) {
+			apierror.NotFound(c, "leftovers not found")
+			return
+		}
+		labels = append(labels, NewLabel(leftover, h.sourceRecipe(ctx, leftover), loc))
+	}
+
+	if format == "json" {
+		c.JSON(http.StatusOK, labels)
+		return
+	}
+	sheet := make([]Label, 0, len(labels)*copies)
+	for _, label := range labels {
+		for range copies {
+			sheet = append(sheet, label)
+		}
+	}
+	var buf bytes.Buffer
+	if err := labelTemplate.Execute(&buf, sheet); err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+}
+
+// sourceRecipe loads the recipe leftovers came from, or nil
+func (h *Handler) sourceRecipe(ctx context.Context, leftover *database.Leftover) *database.Recipe {
+	if leftover.RecipeID == nil {
+		return nil
+	}
+	recipe, err := h.db.GetRecipeByID(ctx, *leftover.RecipeID)
+	if err != nil {
+		return nil
+	}
+	return recipe
+}
@@ -0,0 +1,137 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package leftovers
+
+import (
+	"strings"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// FoodType is how long a kind of leftover keeps and how to bring it back
+// from the freezer. Times follow common food safety guidance, taking the
+// cautious end of each range.
+type FoodType struct {
+	Name        string   `json:"name"`
+	FridgeDays  int      `json:"fridge_days"`
+	FreezerDays int      `json:"freezer_days"`
+	Reheat      string   `json:"reheat"`
+	Keywords    []string `json:"-"`
+}
+
+// OtherFoodType is used when no keyword matches
+const OtherFoodType = "other"
+
+// foodTypes is checked in order, so riskier foods come first: "chicken
+// soup" keeps like poultry, not like soup
+var foodTypes = []FoodType{
+	{Name: "seafood", FridgeDays: 2, FreezerDays: 90, Reheat: "Thaw overnight in the fridge. Reheat gently, covered, until steaming, and only once.", Keywords: []string{"fish", "salmon", "tuna", "cod", "shrimp", "prawn", "crab", "lobster", "mussel", "clam", "seafood", "sushi"}},
+	{Name: "poultry", FridgeDays: 3, FreezerDays: 120, Reheat: "Thaw overnight in the fridge. Reheat until piping hot all the way through (75°C / 165°F).", Keywords: []string{"chicken", "turkey", "duck", "poultry", "nugget", "wing"}},
+	{Name: "meat", FridgeDays: 3, FreezerDays: 90, Reheat: "Thaw overnight in the fridge. Reheat until piping hot all the way through (75°C / 165°F).", Keywords: []string{"beef", "pork", "lamb", "steak", "sausage", "ham", "bacon", "meatball", "burger", "mince"}},
+	{Name: "rice", FridgeDays: 3, FreezerDays: 90, Reheat: "Reheat from frozen in the microwave with a splash of water, stirring, until steaming hot. Reheat only once.", Keywords: []string{"rice", "risotto", "quinoa", "couscous", "grain", "paella"}},
+	{Name: "eggs", FridgeDays: 3, FreezerDays: 60, Reheat: "Thaw overnight in the fridge. Warm in a 160°C (325°F) oven or the microwave until hot through.", Keywords: []string{"egg", "omelette", "omelet", "frittata", "quiche"}},
+	{Name: "soup", FridgeDays: 3, FreezerDays: 120, Reheat: "Thaw overnight in the fridge, or warm from frozen in a covered pan over low heat. Simmer, stirring, until piping hot.", Keywords: []string{"soup", "stew", "chili", "chilli", "curry", "broth", "casserole", "dal"}},
+	{Name: "pasta", FridgeDays: 3, FreezerDays: 60, Reheat: "Thaw overnight in the fridge. Reheat covered in a 180°C (350°F) oven or the microwave until hot through.", Keywords: []string{"pasta", "spaghetti", "noodle", "lasagne", "lasagna", "macaroni", "penne", "ramen"}},
+	{Name: "pizza", FridgeDays: 3, FreezerDays: 60, Reheat: "Reheat from frozen in a 200°C (400°F) oven or a dry pan until the base is crisp.", Keywords: []string{"pizza", "calzone"}},
+	{Name: "vegetables", FridgeDays: 3, FreezerDays: 60, Reheat: "Reheat from frozen in a covered pan or the microwave until hot through.", Keywords: []string{"vegetable", "salad", "potato", "beans", "lentil", "tofu"}},
+	{Name: "baked", FridgeDays: 4, FreezerDays: 90, Reheat: "Thaw at room temperature for a few hours. Warm in a low oven if you like.", Keywords: []string{"bread", "muffin", "cake", "pie", "cookie", "brownie", "bake"}},
+	{Name: OtherFoodType, FridgeDays: 3, FreezerDays: 60, Reheat: "Thaw overnight in the fridge. Reheat until piping hot all the way through."},
+}
+
+// Leftover statuses
+const (
+	StatusFresh   = "fresh"
+	StatusEatSoon = "eat_soon"
+	StatusExpired = "expired"
+)
+
+// eatSoonWithin is how close to its eat-by time a leftover is flagged
+const eatSoonWithin = 24 * time.Hour
+
+// FoodTypes returns the eat-by guide
+func FoodTypes() []FoodType {
+	return foodTypes
+}
+
+// LookupFoodType returns the named food type, falling back to "other"
+func LookupFoodType(name string) FoodType {
+	for _, ft := range foodTypes {
+		if ft.Name == name {
+			return ft
+		}
+	}
+	return foodTypes[len(foodTypes)-1]
+}
+
+// IsFoodType reports whether name is a known food type
+func IsFoodType(name string) bool {
+	for _, ft := range foodTypes {
+		if ft.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// GuessFoodType picks a food type from a leftover's name and, when it came
+// from a recipe, the recipe's ingredients
+func GuessFoodType(name string, recipe *database.Recipe) string {
+	texts := []string{name}
+	if recipe != nil {
+		texts = append(texts, recipe.Title)
+		for _, ingredient := range recipe.Ingredients {
+			texts = append(texts, ingredient.Name)
+		}
+	}
+
+	for _, ft := range foodTypes {
+		for _, text := range texts {
+			text = strings.ToLower(text)
+			for _, keyword := range ft.Keywords {
+				if strings.Contains(text, keyword) {
+					return ft.Name
+				}
+			}
+		}
+	}
+	return OtherFoodType
+}
+
+// EatBy estimates when leftovers should be eaten by
+func EatBy(foodType, storage string, storedAt time.Time) time.Time {
+	ft := LookupFoodType(foodType)
+	days := ft.FridgeDays
+	if storage == database.LeftoverStorageFreezer {
+		days = ft.FreezerDays
+	}
+	return storedAt.AddDate(0, 0, days)
+}
+
+// Status reports whether leftovers are fresh, should be eaten soon or are past their eat-by time
+func Status(leftover *database.Leftover, now time.Time) string {
+	switch {
+	case !now.Before(leftover.EatBy):
+		return StatusExpired
+	case leftover.EatBy.Sub(now) <= eatSoonWithin:
+		return StatusEatSoon
+	default:
+		return StatusFresh
+	}
+}
@@ -0,0 +1,73 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package leftovers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/events"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// thawLead is how long before a meal frozen food goes into the fridge, so
+// it has thawed through by then
+const thawLead = 24 * time.Hour
+
+// ThawAt is when frozen food should move to the fridge to be ready to eat
+// at eatAt
+func ThawAt(eatAt time.Time) time.Time {
+	return eatAt.Add(-thawLead)
+}
+
+// Freeze fills in what leftovers frozen at storedAt need: the freezer
+// eat-by time and, unless given, the food type's reheat instructions
+func Freeze(leftover *database.Leftover, storedAt time.Time) {
+	leftover.Storage = database.LeftoverStorageFreezer
+	leftover.EatBy = EatBy(leftover.FoodType, leftover.Storage, storedAt).UTC()
+	if leftover.Reheat == "" {
+		leftover.Reheat = LookupFoodType(leftover.FoodType).Reheat
+	}
+}
+
+// RemindThaw sends a leftover_thaw_due event for each frozen leftover
+// whose thaw time has come, then marks it reminded so it is only sent
+// once. Run by the scheduler.
+func (h *Handler) RemindThaw(ctx context.Context) (string, error) {
+	now := time.Now()
+	due, err := h.db.ListThawDueLeftovers(ctx, now)
+	if err != nil {
+		return "", err
+	}
+
+	failed := 0
+	for _, leftover := range due {
+		reminded := now.UTC()
+		leftover.ThawRemindedAt = &reminded
+		if err := h.db.UpdateLeftover(ctx, leftover); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("leftover_id", leftover.ID).Msg("Failed to mark thaw reminder sent")
+			failed++
+			continue
+		}
+		h.events.Publish(ctx, events.NewLeftoverThawDue(leftover))
+	}
+	return fmt.Sprintf("sent %d thaw reminders, %d failed", len(due)-failed, failed), nil
+}
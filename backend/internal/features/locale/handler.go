@@ -0,0 +1,135 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package locale
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/i18n"
+	"github.com/rghsoftware/space-food/internal/middleware"
+)
+
+// Handler handles locale HTTP requests
+type Handler struct {
+	db database.Database
+}
+
+// NewHandler creates a new locale handler
+func NewHandler(db database.Database) *Handler {
+	return &Handler{db: db}
+}
+
+// RegisterRoutes registers locale routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("", h.GetLocale)
+	router.PUT("", h.UpdateLocale)
+}
+
+// GetLocale returns the language the authenticated user chose, null when
+// they haven't, and the languages the server writes messages in
+// @Summary Get locale
+// @Tags locale
+// @Produce json
+// @Router /me/locale [get]
+func (h *Handler) GetLocale(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	locale, err := h.db.GetUserLocale(c.Request.Context(), user.ID)
+	if apierror.IsNotFound(err) {
+		h.respond(c, nil)
+		return
+	}
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	h.respond(c, locale)
+}
+
+// UpdateLocale sets the language the authenticated user reads the app in,
+// as a BCP 47 tag such as es or pt-BR. An empty locale clears it.
+// @Summary Set locale
+// @Tags locale
+// @Accept json
+// @Produce json
+// @Router /me/locale [put]
+func (h *Handler) UpdateLocale(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req struct {
+		Locale *string `json:"locale" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	if *req.Locale == "" {
+		if err := h.db.DeleteUserLocale(ctx, user.ID); err != nil {
+			apierror.Respond(c, err)
+			return
+		}
+		h.respond(c, nil)
+		return
+	}
+	tag, ok := Normalize(*req.Locale)
+	if !ok {
+		apierror.BadRequest(c, "locale must be a language tag such as es or pt-BR")
+		return
+	}
+
+	locale := &database.UserLocale{UserID: user.ID, Locale: tag, UpdatedAt: time.Now()}
+	if err := h.db.UpsertUserLocale(ctx, locale); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	h.respond(c, locale)
+}
+
+// respond writes the user's locale, nil when they haven't chosen one, with
+// the language server messages are written in for them
+func (h *Handler) respond(c *gin.Context, locale *database.UserLocale) {
+	var tag *string
+	var updatedAt *time.Time
+	chosen := ""
+	if locale != nil {
+		tag, updatedAt, chosen = &locale.Locale, &locale.UpdatedAt, locale.Locale
+	} else {
+		chosen = acceptLanguage(c.GetHeader("Accept-Language"))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"locale":     tag,
+		"updated_at": updatedAt,
+		"messages":   i18n.For(chosen).Locale(), // the catalog used, which falls back to en
+		"available":  i18n.Locales(),
+	})
+}
@@ -0,0 +1,140 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package locale keeps the language each user reads the app in.
+package locale
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/i18n"
+)
+
+// tagPattern matches the BCP 47 tags the app accepts: a language, with an
+// optional script and region, e.g. es, pt-BR or zh-Hant-TW
+var tagPattern = regexp.MustCompile(`^([A-Za-z]{2,3})(-[A-Za-z]{4})?(-(?:[A-Za-z]{2}|[0-9]{3}))?$`)
+
+// Normalize checks a language tag and writes it the standard way: the
+// language in lower case, the script capitalized and the region in upper
+// case
+func Normalize(tag string) (string, bool) {
+	parts := tagPattern.FindStringSubmatch(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+	if parts == nil {
+		return "", false
+	}
+	normalized := strings.ToLower(parts[1])
+	if script := strings.TrimPrefix(parts[2], "-"); script != "" {
+		normalized += "-" + strings.ToUpper(script[:1]) + strings.ToLower(script[1:])
+	}
+	if region := strings.TrimPrefix(parts[3], "-"); region != "" {
+		normalized += "-" + strings.ToUpper(region)
+	}
+	return normalized, true
+}
+
+// names are the English names of common languages, for AI prompts
+var names = map[string]string{
+	"ar": "Arabic", "bn": "Bengali", "ca": "Catalan", "cs": "Czech", "da": "Danish",
+	"de": "German", "el": "Greek", "en": "English", "es": "Spanish", "fa": "Persian",
+	"fi": "Finnish", "fr": "French", "he": "Hebrew", "hi": "Hindi", "hu": "Hungarian",
+	"id": "Indonesian", "it": "Italian", "ja": "Japanese", "ko": "Korean", "ms": "Malay",
+	"nb": "Norwegian Bokmål", "nl": "Dutch", "no": "Norwegian", "pl": "Polish", "pt": "Portuguese",
+	"ro": "Romanian", "ru": "Russian", "sk": "Slovak", "sv": "Swedish", "sw": "Swahili",
+	"ta": "Tamil", "th": "Thai", "tl": "Tagalog", "tr": "Turkish", "uk": "Ukrainian",
+	"ur": "Urdu", "vi": "Vietnamese", "zh": "Chinese",
+}
+
+// Name describes a normalized tag for a prompt, e.g. "Portuguese (pt-BR)"
+func Name(tag string) string {
+	language, _, _ := strings.Cut(tag, "-")
+	if name, ok := names[language]; ok {
+		return name + " (" + tag + ")"
+	}
+	return "the language with the BCP 47 tag " + tag
+}
+
+// Preferred returns the language a user chose, or "" when they haven't
+// chosen one or it can't be read
+func Preferred(ctx context.Context, db database.Database, userID string) string {
+	locale, err := db.GetUserLocale(ctx, userID)
+	if err != nil {
+		return ""
+	}
+	return locale.Locale
+}
+
+// Request returns the language to write to the signed-in user in: the
+// locale they chose, or else the language their client asks for in
+// Accept-Language. It is "" when neither says.
+func Request(c *gin.Context, db database.Database, userID string) string {
+	if preferred := Preferred(c.Request.Context(), db, userID); preferred != "" {
+		return preferred
+	}
+	return acceptLanguage(c.GetHeader("Accept-Language"))
+}
+
+// Printer returns the message printer for the signed-in user's language
+func Printer(c *gin.Context, db database.Database, userID string) *i18n.Printer {
+	return i18n.For(Request(c, db, userID))
+}
+
+// AnswerIn describes the language an AI provider should answer the user
+// in, or returns "" for English, which prompts get anyway
+func AnswerIn(tag string) string {
+	if language, _, _ := strings.Cut(tag, "-"); language == "" || language == i18n.DefaultLocale {
+		return ""
+	}
+	return Name(tag)
+}
+
+// acceptLanguage returns the most preferred valid tag in an
+// Accept-Language header, e.g. "fr-CH, fr;q=0.9, en;q=0.8" gives fr-CH
+func acceptLanguage(header string) string {
+	type choice struct {
+		tag     string
+		quality float64
+	}
+	choices := []choice{}
+	for _, part := range strings.Split(header, ",") {
+		tag, quality := part, 1.0
+		if before, after, ok := strings.Cut(part, ";"); ok {
+			tag = before
+			if q, ok := strings.CutPrefix(strings.TrimSpace(after), "q="); ok {
+				parsed, err := strconv.ParseFloat(q, 64)
+				if err != nil {
+					continue
+				}
+				quality = parsed
+			}
+		}
+		if normalized, ok := Normalize(tag); ok && quality > 0 {
+			choices = append(choices, choice{normalized, quality})
+		}
+	}
+	if len(choices) == 0 {
+		return ""
+	}
+	sort.SliceStable(choices, func(a, b int) bool { return choices[a].quality > choices[b].quality })
+	return choices[0].tag
+}
@@ -0,0 +1,76 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package meal_planning
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// FieldChange is one field of a meal plan an edit would change, from its
+// saved value to the edited one
+type FieldChange struct {
+	Field  string          `json:"field"`
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// plannedMeal is a planned meal without its row IDs, which an edit does
+// not carry
+type plannedMeal struct {
+	RecipeID string
+	Date     string
+	MealType string
+	Servings int
+	Notes    string
+}
+
+// planChanges lists the fields an edit would change in the saved plan
+func planChanges(saved, yours *database.MealPlan) []FieldChange {
+	changes := []FieldChange{}
+	add := func(field string, before, after any) {
+		old, _ := json.Marshal(before)
+		updated, _ := json.Marshal(after)
+		if !bytes.Equal(old, updated) {
+			changes = append(changes, FieldChange{Field: field, Before: old, After: updated})
+		}
+	}
+	add("Title", saved.Title, yours.Title)
+	add("Description", saved.Description, yours.Description)
+	add("StartDate", saved.StartDate.Format("2006-01-02"), yours.StartDate.Format("2006-01-02"))
+	add("EndDate", saved.EndDate.Format("2006-01-02"), yours.EndDate.Format("2006-01-02"))
+	add("Meals", plannedMeals(saved.Meals), plannedMeals(yours.Meals))
+	return changes
+}
+
+func plannedMeals(meals []database.PlannedMeal) []plannedMeal {
+	out := make([]plannedMeal, 0, len(meals))
+	for _, meal := range meals {
+		out = append(out, plannedMeal{
+			RecipeID: meal.RecipeID,
+			Date:     meal.Date.Format("2006-01-02"),
+			MealType: meal.MealType,
+			Servings: meal.Servings,
+			Notes:    meal.Notes,
+		})
+	}
+	return out
+}
@@ -0,0 +1,372 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package meal_planning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/capabilities"
+	"github.com/rghsoftware/space-food/internal/features/dietary"
+	"github.com/rghsoftware/space-food/internal/features/energy"
+	"github.com/rghsoftware/space-food/internal/features/mealtime"
+	"github.com/rghsoftware/space-food/internal/features/recipes"
+	"github.com/rghsoftware/space-food/internal/middleware"
+)
+
+func init() {
+	capabilities.RegisterAIFeature(capabilities.AIFeature{
+		Name:        "meal_plan_generation",
+		Fallback:    "constraint_planner",
+		Description: "plans are built from your recipes, safe foods and leftovers by a rule-based planner",
+	})
+}
+
+// forecastWeeks is how far back check-ins are read to forecast energy
+const forecastWeeks = 8
+
+// generateRequest holds the constraints a generated plan keeps to
+type generateRequest struct {
+	StartDate string   `json:"start_date"` // YYYY-MM-DD; today by default
+	Days      int      `json:"days" binding:"omitempty,min=1,max=14"`
+	MealTypes []string `json:"meal_types" binding:"omitempty,max=4"`
+	// MaxMinutes limits total recipe time by weekday ("monday") with a
+	// "default" for the other days
+	MaxMinutes      map[string]int `json:"max_minutes"`
+	EffortBudget    int            `json:"effort_budget" binding:"omitempty,min=1,max=300"`   // effort points a week
+	ShoppingBudget  int            `json:"shopping_budget" binding:"omitempty,min=1,max=200"` // ingredients to buy
+	ReuseLeftovers  *bool          `json:"reuse_leftovers"`
+	MaxRepeats      int            `json:"max_repeats" binding:"omitempty,min=1,max=14"`
+	AvoidRecentDays *int           `json:"avoid_recent_days" binding:"omitempty,min=0,max=90"`
+	Save            bool           `json:"save"` // save the plan as a meal plan
+	Title           string         `json:"title" binding:"max=200"`
+}
+
+// regenerateSlotRequest plans one slot of a generated plan again, under
+// the constraints the plan was generated with
+type regenerateSlotRequest struct {
+	Constraints generateRequest `json:"constraints"`
+	Slots       []Slot          `json:"slots" binding:"max=100"`
+	Date        string          `json:"date" binding:"required"`
+	MealType    string          `json:"meal_type" binding:"required"`
+}
+
+// GeneratedPlan is a plan made to fit the viewer's constraints
+type GeneratedPlan struct {
+	StartDate     string             `json:"start_date"`
+	EndDate       string             `json:"end_date"`
+	Slots         []Slot             `json:"slots"`
+	Effort        int                `json:"effort"` // of the busiest week
+	EffortBudget  int                `json:"effort_budget,omitempty"`
+	ShoppingItems []string           `json:"shopping_items"` // ingredients not in the pantry
+	Forecast      []DayForecast      `json:"forecast"`
+	MealPlan      *database.MealPlan `json:"meal_plan,omitempty"` // when saved
+}
+
+// weekdays maps the names accepted in max_minutes to weekdays
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// newPlannerFor loads everything a plan is made from for the viewer and
+// checks the constraints, responding with an error when they are invalid
+func (h *Handler) newPlannerFor(c *gin.Context, userID string, req *generateRequest) (*planner, bool) {
+	cons := constraints{
+		MaxMinutes:     map[time.Weekday]int{},
+		EffortBudget:   req.EffortBudget,
+		ShoppingBudget: req.ShoppingBudget,
+		ReuseLeftovers: req.ReuseLeftovers == nil || *req.ReuseLeftovers,
+		MaxRepeats:     req.MaxRepeats,
+		MealTypes:      req.MealTypes,
+		RecentlyEaten:  map[string]int{},
+	}
+	for name, minutes := range req.MaxMinutes {
+		if minutes < 0 || minutes > 1440 {
+			apierror.BadRequest(c, "max_minutes must be between 0 and 1440")
+			return nil, false
+		}
+		name = strings.ToLower(name)
+		if name == "default" {
+			cons.DefaultMinutes = minutes
+			continue
+		}
+		day, ok := weekdays[name]
+		if !ok {
+			apierror.BadRequest(c, fmt.Sprintf("max_minutes has an unknown day %q", name))
+			return nil, false
+		}
+		cons.MaxMinutes[day] = minutes
+	}
+	if len(cons.MealTypes) == 0 {
+		cons.MealTypes = []string{mealtime.MealTypeDinner}
+	}
+	seen := map[string]bool{}
+	for _, mealType := range cons.MealTypes {
+		if !mealtime.IsMealType(mealType) || seen[mealType] {
+			apierror.BadRequest(c, "meal_types must be distinct meal types: breakfast, lunch, dinner or snack")
+			return nil, false
+		}
+		seen[mealType] = true
+	}
+	if cons.MaxRepeats == 0 {
+		cons.MaxRepeats = 1
+	}
+	days := req.Days
+	if days == 0 {
+		days = 7
+	}
+
+	loc := mealtime.Location(mealtime.LoadPreferences(c, h.db, userID))
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	start := today
+	if req.StartDate != "" {
+		var err error
+		start, err = time.ParseInLocation("2006-01-02", req.StartDate, loc)
+		if err != nil {
+			apierror.BadRequest(c, "start_date must be a date in YYYY-MM-DD format")
+			return nil, false
+		}
+	}
+
+	ctx := c.Request.Context()
+	data, err := h.loadPlanData(ctx, userID, req, today, loc)
+	if err != nil {
+		apierror.Respond(c, err)
+		return nil, false
+	}
+	cons.Forecast = data.forecast
+	cons.RecentlyEaten = data.recent
+	return newPlanner(cons, data.recipes, data.safeFoods, data.pantry, data.leftovers, start, days), true
+}
+
+// planData is what a plan is made from
+type planData struct {
+	recipes   []*database.Recipe
+	safeFoods []*database.SafeFood
+	pantry    []*database.PantryItem
+	leftovers []*database.Leftover
+	forecast  map[time.Weekday]float64
+	recent    map[string]int
+}
+
+// loadPlanData reads the viewer's recipes without dietary conflicts, safe
+// foods, pantry, leftovers, energy forecast and recent meals
+func (h *Handler) loadPlanData(ctx context.Context, userID string, req *generateRequest, today time.Time, loc *time.Location) (*planData, error) {
+	data := &planData{recent: map[string]int{}}
+
+	all, err := h.db.ListRecipes(ctx, database.RecipeFilter{UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+	restrictions, err := h.db.ListDietaryRestrictions(ctx, []string{userID})
+	if err != nil {
+		return nil, err
+	}
+	for _, recipe := range all {
+		if !dietary.HasConflicts(recipe, restrictions) {
+			data.recipes = append(data.recipes, recipe)
+		}
+	}
+	if err := recipes.AddEffort(ctx, h.db, data.recipes); err != nil {
+		return nil, err
+	}
+
+	if data.safeFoods, err = h.db.ListSafeFoods(ctx, userID); err != nil {
+		return nil, err
+	}
+	if data.pantry, err = h.db.ListPantryItems(ctx, database.PantryFilter{UserID: userID}); err != nil {
+		return nil, err
+	}
+	if data.leftovers, err = h.db.ListLeftovers(ctx, database.LeftoverFilter{UserID: userID}); err != nil {
+		return nil, err
+	}
+
+	checkIns, err := h.db.ListEnergyCheckIns(ctx, database.EnergyCheckInFilter{
+		UserID: userID,
+		Since:  today.AddDate(0, 0, -7*forecastWeeks).UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	data.forecast = energy.WeekdayForecast(checkIns, loc)
+
+	avoidDays := 14
+	if req.AvoidRecentDays != nil {
+		avoidDays = *req.AvoidRecentDays
+	}
+	if avoidDays > 0 {
+		logs, err := h.db.ListNutritionLogs(ctx, database.NutritionFilter{
+			UserID:    userID,
+			StartDate: today.AddDate(0, 0, -avoidDays),
+			EndDate:   today.AddDate(0, 0, 1),
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, log := range logs {
+			if log.RecipeID != nil {
+				data.recent[*log.RecipeID]++
+			}
+		}
+	}
+	return data, nil
+}
+
+// GeneratePlan plans meals for the coming days from the viewer's recipes,
+// safe foods and leftovers. Every slot keeps to the dietary restrictions,
+// time limits, weekly effort budget and number of ingredients to buy;
+// low-energy days in the viewer's check-in history get the easiest meals.
+// @Summary Generate meal plan
+// @Tags meal-plans
+// @Accept json
+// @Produce json
+// @Success 200 {object} GeneratedPlan
+// @Router /meal-plans/generate [post]
+func (h *Handler) GeneratePlan(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req generateRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		apierror.Invalid(c, err)
+		return
+	}
+	p, ok := h.newPlannerFor(c, user.ID, &req)
+	if !ok {
+		return
+	}
+
+	slots := p.Plan()
+	plan := GeneratedPlan{
+		StartDate:     p.date(0).Format("2006-01-02"),
+		EndDate:       p.date(p.days - 1).Format("2006-01-02"),
+		Slots:         slots,
+		Effort:        p.Effort(),
+		EffortBudget:  p.EffortBudget,
+		ShoppingItems: p.Shopping(),
+		Forecast:      p.Days(),
+	}
+	if !req.Save {
+		c.JSON(http.StatusOK, plan)
+		return
+	}
+
+	saved := savedPlan(user.ID, req.Title, p, slots)
+	if err := h.db.CreateMealPlan(c.Request.Context(), saved); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	plan.MealPlan = saved
+	c.JSON(http.StatusCreated, plan)
+}
+
+// RegenerateSlot plans one slot of a generated plan again, keeping to the
+// same constraints around the other slots and picking something else
+// @Summary Regenerate meal plan slot
+// @Tags meal-plans
+// @Accept json
+// @Produce json
+// @Success 200 {object} Slot
+// @Router /meal-plans/generate/slot [post]
+func (h *Handler) RegenerateSlot(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req regenerateSlotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+	if !mealtime.IsMealType(req.MealType) {
+		apierror.BadRequest(c, "meal_type must be breakfast, lunch, dinner or snack")
+		return
+	}
+	p, ok := h.newPlannerFor(c, user.ID, &req.Constraints)
+	if !ok {
+		return
+	}
+
+	slot, ok := p.Replace(req.Slots, req.Date, req.MealType)
+	if !ok {
+		apierror.BadRequest(c, "date must be a day of the plan")
+		return
+	}
+	c.JSON(http.StatusOK, slot)
+}
+
+// savedPlan turns generated slots into a meal plan. Leftovers and safe
+// foods that are not a recipe are kept as notes; open slots are left out.
+func savedPlan(userID, title string, p *planner, slots []Slot) *database.MealPlan {
+	if title == "" {
+		title = "Meals from " + p.date(0).Format("Mon 2 Jan")
+	}
+	now := time.Now()
+	plan := &database.MealPlan{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Title:     title,
+		StartDate: p.date(0),
+		EndDate:   p.date(p.days - 1),
+		Meals:     []database.PlannedMeal{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	for _, slot := range slots {
+		meal := database.PlannedMeal{
+			ID:         uuid.New().String(),
+			MealPlanID: plan.ID,
+			RecipeID:   slot.RecipeID,
+			MealType:   slot.MealType,
+			Servings:   1,
+		}
+		meal.Date, _ = time.ParseInLocation("2006-01-02", slot.Date, p.start.Location())
+		switch slot.Kind {
+		case SlotOpen:
+			continue
+		case SlotLeftovers:
+			meal.Notes = "Leftovers: " + slot.Title
+		case SlotSafeFood:
+			meal.Notes = "Safe food: " + slot.Title
+		}
+		plan.Meals = append(plan.Meals, meal)
+	}
+	return plan
+}
@@ -23,6 +23,11 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/api/params"
+	"github.com/rghsoftware/space-food/internal/api/precondition"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/audit"
+	"github.com/rghsoftware/space-food/internal/authz"
 	"github.com/rghsoftware/space-food/internal/database"
 	"github.com/rghsoftware/space-food/internal/middleware"
 )
@@ -44,6 +49,8 @@ func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
 	router.GET("", h.ListMealPlans)
 	router.GET("/:id", h.GetMealPlan)
 	router.POST("", h.CreateMealPlan)
+	router.POST("/generate", h.GeneratePlan)
+	router.POST("/generate/slot", h.RegenerateSlot)
 	router.PUT("/:id", h.UpdateMealPlan)
 	router.DELETE("/:id", h.DeleteMealPlan)
 }
@@ -52,7 +59,13 @@ func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
 func (h *Handler) ListMealPlans(c *gin.Context) {
 	user, ok := middleware.GetUserFromContext(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	query := params.Query(c)
+	page := query.Page(50)
+	if !query.Valid() {
 		return
 	}
 
@@ -63,13 +76,13 @@ func (h *Handler) ListMealPlans(c *gin.Context) {
 		UserID:    user.ID,
 		StartDate: startDate,
 		EndDate:   endDate,
-		Limit:     50,
-		Offset:    0,
+		Limit:     page.Limit,
+		Offset:    page.Offset,
 	}
 
 	plans, err := h.db.ListMealPlans(c.Request.Context(), filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Respond(c, err)
 		return
 	}
 
@@ -78,14 +91,31 @@ func (h *Handler) ListMealPlans(c *gin.Context) {
 
 // GetMealPlan retrieves a single meal plan by ID
 func (h *Handler) GetMealPlan(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
 	id := c.Param("id")
 
 	plan, err := h.db.GetMealPlanByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "meal plan not found"})
+		apierror.Lookup(c, err, "meal plan not found")
+		return
+	}
+
+	visible, err := authz.CanViewMealPlan(c.Request.Context(), h.db, user.ID, plan)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	if !visible {
+		apierror.NotFound(c, "meal plan not found")
 		return
 	}
 
+	middleware.SetLastModified(c, plan.UpdatedAt)
 	c.JSON(http.StatusOK, plan)
 }
 
@@ -93,20 +123,20 @@ func (h *Handler) GetMealPlan(c *gin.Context) {
 func (h *Handler) CreateMealPlan(c *gin.Context) {
 	user, ok := middleware.GetUserFromContext(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		apierror.Unauthorized(c, "unauthorized")
 		return
 	}
 
 	var plan database.MealPlan
 	if err := c.ShouldBindJSON(&plan); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Invalid(c, err)
 		return
 	}
 
 	plan.UserID = user.ID
 
 	if err := h.db.CreateMealPlan(c.Request.Context(), &plan); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Respond(c, err)
 		return
 	}
 
@@ -117,7 +147,7 @@ func (h *Handler) CreateMealPlan(c *gin.Context) {
 func (h *Handler) UpdateMealPlan(c *gin.Context) {
 	user, ok := middleware.GetUserFromContext(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		apierror.Unauthorized(c, "unauthorized")
 		return
 	}
 
@@ -126,26 +156,48 @@ func (h *Handler) UpdateMealPlan(c *gin.Context) {
 	// Verify ownership
 	existing, err := h.db.GetMealPlanByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "meal plan not found"})
+		apierror.Lookup(c, err, "meal plan not found")
 		return
 	}
 
-	if existing.UserID != user.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+	if !authz.CanEditMealPlan(user.ID, existing) {
+		apierror.Forbidden(c, "forbidden")
 		return
 	}
 
 	var plan database.MealPlan
 	if err := c.ShouldBindJSON(&plan); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Invalid(c, err)
+		return
+	}
+
+	// Refuse to overwrite changes saved since the client loaded the plan
+	if !precondition.Check(c, existing.UpdatedAt, plan.UpdatedAt, func() any {
+		return planChanges(existing, &plan)
+	}) {
 		return
 	}
 
 	plan.ID = id
 	plan.UserID = user.ID
+	plan.CreatedAt = existing.CreatedAt
+	plan.UpdatedAt = time.Now()
 
-	if err := h.db.UpdateMealPlan(c.Request.Context(), &plan); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	updated, err := h.db.UpdateMealPlanIfUnchanged(c.Request.Context(), &plan, existing.UpdatedAt)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	if !updated {
+		// Saved by someone else since the check above
+		current, err := h.db.GetMealPlanByID(c.Request.Context(), id)
+		if err != nil {
+			apierror.Lookup(c, err, "meal plan not found")
+			return
+		}
+		precondition.Refuse(c, current.UpdatedAt, func() any {
+			return planChanges(current, &plan)
+		})
 		return
 	}
 
@@ -156,7 +208,7 @@ func (h *Handler) UpdateMealPlan(c *gin.Context) {
 func (h *Handler) DeleteMealPlan(c *gin.Context) {
 	user, ok := middleware.GetUserFromContext(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		apierror.Unauthorized(c, "unauthorized")
 		return
 	}
 
@@ -165,19 +217,20 @@ func (h *Handler) DeleteMealPlan(c *gin.Context) {
 	// Verify ownership
 	existing, err := h.db.GetMealPlanByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "meal plan not found"})
+		apierror.Lookup(c, err, "meal plan not found")
 		return
 	}
 
-	if existing.UserID != user.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+	if !authz.CanEditMealPlan(user.ID, existing) {
+		apierror.Forbidden(c, "forbidden")
 		return
 	}
 
 	if err := h.db.DeleteMealPlan(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Respond(c, err)
 		return
 	}
+	audit.Record(c, audit.Entry{Action: audit.MealPlanDeleted, TargetType: "meal_plan", TargetID: id})
 
 	c.Status(http.StatusNoContent)
 }
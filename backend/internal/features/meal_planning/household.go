@@ -0,0 +1,132 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package meal_planning
+
+import (
+	"net/http"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/authz"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/mealtime"
+	"github.com/rghsoftware/space-food/internal/middleware"
+)
+
+// TodayMeal is a meal a household member planned for today
+type TodayMeal struct {
+	MealType    string `json:"meal_type"`
+	RecipeID    string `json:"recipe_id"`
+	RecipeTitle string `json:"recipe_title,omitempty"`
+	Servings    int    `json:"servings"`
+	Notes       string `json:"notes,omitempty"`
+	PlannedBy   string `json:"planned_by"` // the member whose plan it is on
+}
+
+// RegisterHouseholdRoutes registers the plan views a household shares
+func (h *Handler) RegisterHouseholdRoutes(router *gin.RouterGroup) {
+	router.GET("/:id/today", h.GetHouseholdToday)
+}
+
+// GetHouseholdToday lists what the household's members have planned to
+// eat today, in the viewer's time zone, in meal order
+// @Summary Today's household plan
+// @Tags households
+// @Produce json
+// @Param id path string true "Household ID"
+// @Router /households/{id}/today [get]
+func (h *Handler) GetHouseholdToday(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+	householdID := c.Param("id")
+	ctx := c.Request.Context()
+	if _, err := authz.Household(ctx, h.db, householdID, user.ID, authz.ViewMealPlans); err != nil {
+		apierror.Lookup(c, err, "household not found")
+		return
+	}
+
+	members, err := h.db.ListHouseholdMembers(ctx, householdID)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	loc := mealtime.Location(mealtime.LoadPreferences(c, h.db, user.ID))
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	date := today.Format("2006-01-02")
+
+	meals := []TodayMeal{}
+	titles := map[string]string{}
+	for _, member := range members {
+		plans, err := h.db.ListMealPlans(ctx, database.MealPlanFilter{
+			UserID:    member.UserID,
+			StartDate: today,
+			EndDate:   today.AddDate(0, 0, 1),
+		})
+		if err != nil {
+			apierror.Respond(c, err)
+			return
+		}
+		for _, plan := range plans {
+			for _, meal := range plan.Meals {
+				if meal.Date.Format("2006-01-02") != date {
+					continue
+				}
+				title, seen := titles[meal.RecipeID]
+				if !seen {
+					if recipe, err := h.db.GetRecipeByID(ctx, meal.RecipeID); err == nil {
+						title = recipe.Title
+					}
+					titles[meal.RecipeID] = title
+				}
+				meals = append(meals, TodayMeal{
+					MealType:    meal.MealType,
+					RecipeID:    meal.RecipeID,
+					RecipeTitle: title,
+					Servings:    meal.Servings,
+					Notes:       meal.Notes,
+					PlannedBy:   member.UserID,
+				})
+			}
+		}
+	}
+	sort.SliceStable(meals, func(i, j int) bool {
+		return mealOrder(meals[i].MealType) < mealOrder(meals[j].MealType)
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"date":  date,
+		"meals": meals,
+	})
+}
+
+// mealOrder places a meal type in the day, unknown types last
+func mealOrder(mealType string) int {
+	if i := slices.Index(mealtime.MealTypes, mealType); i >= 0 {
+		return i
+	}
+	return len(mealtime.MealTypes)
+}
@@ -0,0 +1,426 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package meal_planning
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/mealtime"
+	"github.com/rghsoftware/space-food/internal/features/recipes"
+	"github.com/rghsoftware/space-food/internal/features/suggestions"
+)
+
+// Kinds of planned slot
+const (
+	SlotRecipe    = "recipe"
+	SlotLeftovers = "leftovers"
+	SlotSafeFood  = "safe_food"
+	SlotOpen      = "open" // nothing fitted the constraints
+)
+
+// lowEnergy is the forecast level at or below which a day is planned as a
+// low-energy day
+const lowEnergy = 2.5
+
+// Slot is one meal of a generated plan
+type Slot struct {
+	Date         string   `json:"date"` // YYYY-MM-DD
+	MealType     string   `json:"meal_type"`
+	Kind         string   `json:"kind"`
+	RecipeID     string   `json:"recipe_id,omitempty"`
+	LeftoverID   string   `json:"leftover_id,omitempty"`
+	SafeFoodID   string   `json:"safe_food_id,omitempty"`
+	Title        string   `json:"title,omitempty"`
+	TotalMinutes int      `json:"total_minutes,omitempty"`
+	Effort       int      `json:"effort"` // 0 for leftovers and open slots
+	Reasons      []string `json:"reasons"`
+}
+
+// DayForecast is the energy expected on a day of the plan
+type DayForecast struct {
+	Date   string   `json:"date"`
+	Energy *float64 `json:"energy"` // 1-5, nil without check-ins on that weekday
+}
+
+// constraints are what a plan has to respect
+type constraints struct {
+	MaxMinutes     map[time.Weekday]int // 0 for no limit
+	DefaultMinutes int
+	EffortBudget   int // effort points a week; 0 for no limit
+	ShoppingBudget int // ingredients to buy for the whole plan; 0 for no limit
+	ReuseLeftovers bool
+	MaxRepeats     int
+	MealTypes      []string
+	Forecast       map[time.Weekday]float64
+	RecentlyEaten  map[string]int // recipe ID to times logged lately
+}
+
+// planner fills the slots of a plan one at a time, keeping track of what
+// earlier slots used up
+type planner struct {
+	constraints
+	recipes    []*database.Recipe // already free of dietary conflicts, with effort scores
+	safeFoods  []*database.SafeFood
+	standalone []*database.SafeFood // safe foods that are not a recipe
+	pantry     []*database.PantryItem
+	leftovers  []*database.Leftover
+	start      time.Time
+	days       int
+
+	used     map[string]int     // recipe or safe food ID to slots
+	effort   map[int]int        // week of the plan to effort used
+	open     map[int]int        // week of the plan to slots still to fill
+	portions map[string]float64 // leftover ID to portions left
+	shopping map[string]bool    // ingredients to buy
+}
+
+func newPlanner(c constraints, recipeList []*database.Recipe, safeFoods []*database.SafeFood, pantry []*database.PantryItem, leftovers []*database.Leftover, start time.Time, days int) *planner {
+	p := &planner{
+		constraints: c,
+		recipes:     recipeList,
+		safeFoods:   safeFoods,
+		pantry:      pantry,
+		leftovers:   leftovers,
+		start:       start,
+		days:        days,
+		used:        map[string]int{},
+		effort:      map[int]int{},
+		open:        map[int]int{},
+		portions:    map[string]float64{},
+		shopping:    map[string]bool{},
+	}
+	// Safe foods that are a recipe are planned as that recipe
+	for _, food := range safeFoods {
+		if food.RecipeID == nil {
+			p.standalone = append(p.standalone, food)
+		}
+	}
+	sort.SliceStable(p.leftovers, func(i, j int) bool { return p.leftovers[i].EatBy.Before(p.leftovers[j].EatBy) })
+	for _, leftover := range leftovers {
+		p.portions[leftover.ID] = leftover.Portions
+	}
+	for day := 0; day < days; day++ {
+		p.open[day/7] += len(c.MealTypes)
+	}
+	return p
+}
+
+// date returns the day of the plan with the given index
+func (p *planner) date(day int) time.Time {
+	return p.start.AddDate(0, 0, day)
+}
+
+// dayOf returns the index of a plan date, or -1 when it is outside the plan
+func (p *planner) dayOf(date string) int {
+	for day := 0; day < p.days; day++ {
+		if p.date(day).Format("2006-01-02") == date {
+			return day
+		}
+	}
+	return -1
+}
+
+// Plan fills every slot of the plan, day by day
+func (p *planner) Plan() []Slot {
+	slots := []Slot{}
+	for day := 0; day < p.days; day++ {
+		leftoversToday := false
+		for _, mealType := range p.MealTypes {
+			var slot Slot
+			if !leftoversToday && mealType != mealtime.MealTypeBreakfast {
+				if s, ok := p.leftoverSlot(day, mealType); ok {
+					slot, leftoversToday = s, true
+				}
+			}
+			if slot.Kind == "" {
+				slot = p.choose(day, mealType, nil)
+			}
+			p.take(day, slot)
+			slots = append(slots, slot)
+		}
+	}
+	return slots
+}
+
+// Replace plans one slot again around the rest of the plan, picking
+// something other than what it holds now
+func (p *planner) Replace(slots []Slot, date, mealType string) (Slot, bool) {
+	day := p.dayOf(date)
+	if day < 0 {
+		return Slot{}, false
+	}
+	var current *Slot
+	for i := range slots {
+		if slots[i].Date == date && slots[i].MealType == mealType && current == nil {
+			current = &slots[i]
+			continue
+		}
+		if d := p.dayOf(slots[i].Date); d >= 0 {
+			p.take(d, slots[i])
+		}
+	}
+	exclude := map[string]bool{}
+	if current != nil {
+		exclude[current.RecipeID+current.SafeFoodID+current.LeftoverID] = true
+	}
+	return p.choose(day, mealType, exclude), true
+}
+
+// take records what a slot uses up
+func (p *planner) take(day int, slot Slot) {
+	week := day / 7
+	p.open[week]--
+	p.effort[week] += slot.Effort
+	switch slot.Kind {
+	case SlotRecipe:
+		p.used[slot.RecipeID]++
+		for _, recipe := range p.recipes {
+			if recipe.ID == slot.RecipeID {
+				for _, name := range p.toBuy(recipe) {
+					p.shopping[name] = true
+				}
+			}
+		}
+	case SlotSafeFood:
+		p.used[slot.SafeFoodID]++
+	case SlotLeftovers:
+		p.portions[slot.LeftoverID]--
+	}
+}
+
+// leftoverSlot plans a portion of the leftovers that need eating soonest,
+// if any are still good on the day
+func (p *planner) leftoverSlot(day int, mealType string) (Slot, bool) {
+	if !p.ReuseLeftovers {
+		return Slot{}, false
+	}
+	date := p.date(day)
+	for _, leftover := range p.leftovers {
+		if p.portions[leftover.ID] < 1 || leftover.EatBy.Before(date) {
+			continue
+		}
+		slot := Slot{
+			Date:       date.Format("2006-01-02"),
+			MealType:   mealType,
+			Kind:       SlotLeftovers,
+			LeftoverID: leftover.ID,
+			Title:      leftover.Name,
+			Reasons:    []string{"Uses up leftovers, good until " + leftover.EatBy.Format("Mon 2 Jan")},
+		}
+		if leftover.RecipeID != nil {
+			slot.RecipeID = *leftover.RecipeID
+		}
+		return slot, true
+	}
+	return Slot{}, false
+}
+
+// candidate is a recipe or safe food that could fill a slot
+type candidate struct {
+	slot  Slot
+	score float64
+}
+
+// choose picks the best recipe or safe food for a slot that keeps within
+// every constraint, or leaves the slot open
+func (p *planner) choose(day int, mealType string, exclude map[string]bool) Slot {
+	date := p.date(day)
+	week := day / 7
+	level, forecast := p.Forecast[date.Weekday()]
+	low := forecast && level <= lowEnergy
+	maxMinutes := p.DefaultMinutes
+	if m, ok := p.MaxMinutes[date.Weekday()]; ok {
+		maxMinutes = m
+	}
+	// Keep enough of the week's effort budget for the slots after this one
+	reserve := (p.open[week] - 1) * recipes.MinEffort
+
+	candidates := []candidate{}
+	for _, recipe := range p.recipes {
+		if exclude[recipe.ID] || !mealtime.RecipeAllowed(recipe, []string{mealType}) {
+			continue
+		}
+		total := recipe.PrepTime + recipe.CookTime
+		if maxMinutes > 0 && total > maxMinutes {
+			continue
+		}
+		if p.used[recipe.ID] >= p.MaxRepeats {
+			continue
+		}
+		effort := recipe.Effort.Score
+		if p.EffortBudget > 0 && p.effort[week]+effort+reserve > p.EffortBudget {
+			continue
+		}
+		toBuy := p.toBuy(recipe)
+		if p.ShoppingBudget > 0 && len(p.shopping)+len(toBuy) > p.ShoppingBudget {
+			continue
+		}
+
+		c := candidate{slot: Slot{
+			Kind:         SlotRecipe,
+			RecipeID:     recipe.ID,
+			Title:        recipe.Title,
+			TotalMinutes: total,
+			Effort:       effort,
+			Reasons:      []string{},
+		}}
+		c.score = recipe.Rating*2 - float64(len(toBuy))
+		if containsString(mealtime.RecipeMealTypes(recipe), mealType) {
+			c.score += 8
+		}
+		// Effort weighs more the lower the energy expected that day
+		weight := 1.0
+		if forecast {
+			weight = 5 - level
+		}
+		c.score -= float64(effort) * weight
+		safe := suggestions.MatchSafeFood(recipe, p.safeFoods) != nil
+		if safe {
+			c.score += 4
+			if low {
+				c.score += 10
+			}
+			c.slot.Reasons = append(c.slot.Reasons, "One of your safe foods")
+		} else if n := p.RecentlyEaten[recipe.ID]; n > 0 {
+			c.score -= float64(n) * 8
+		}
+		c.score -= float64(p.used[recipe.ID]) * 25
+
+		if low && effort <= 4 {
+			c.slot.Reasons = append(c.slot.Reasons, "Easy going for a low-energy day")
+		}
+		if total > 0 && maxMinutes > 0 {
+			c.slot.Reasons = append(c.slot.Reasons, fmt.Sprintf("Ready in %d minutes, within your %d for %s", total, maxMinutes, date.Weekday()))
+		}
+		if len(recipe.Ingredients) > 0 && p.inPantry(recipe) {
+			c.slot.Reasons = append(c.slot.Reasons, "You have everything you need")
+		}
+		if p.RecentlyEaten[recipe.ID] == 0 && p.used[recipe.ID] == 0 && !safe {
+			c.slot.Reasons = append(c.slot.Reasons, "Something you haven't had lately")
+		}
+		candidates = append(candidates, c)
+	}
+
+	for _, food := range p.standalone {
+		if exclude[food.ID] || p.used[food.ID] >= p.MaxRepeats {
+			continue
+		}
+		if p.EffortBudget > 0 && p.effort[week]+recipes.MinEffort+reserve > p.EffortBudget {
+			continue
+		}
+		c := candidate{slot: Slot{
+			Kind:       SlotSafeFood,
+			SafeFoodID: food.ID,
+			Title:      food.Name,
+			Effort:     recipes.MinEffort,
+			Reasons:    []string{"One of your safe foods"},
+		}}
+		c.score = 4 - float64(p.used[food.ID])*25
+		if low {
+			c.score += 10
+			c.slot.Reasons = append(c.slot.Reasons, "Familiar and easy for a low-energy day")
+		}
+		candidates = append(candidates, c)
+	}
+
+	slot := Slot{Kind: SlotOpen, Reasons: []string{"Nothing in your recipes or safe foods fits this slot's limits"}}
+	if len(candidates) > 0 {
+		sort.SliceStable(candidates, func(i, j int) bool {
+			if candidates[i].score != candidates[j].score {
+				return candidates[i].score > candidates[j].score
+			}
+			return strings.ToLower(candidates[i].slot.Title) < strings.ToLower(candidates[j].slot.Title)
+		})
+		slot = candidates[0].slot
+	}
+	slot.Date = date.Format("2006-01-02")
+	slot.MealType = mealType
+	return slot
+}
+
+// toBuy lists the required ingredients of a recipe that are neither in the
+// pantry nor already on the plan's shopping list
+func (p *planner) toBuy(recipe *database.Recipe) []string {
+	names := []string{}
+	for _, ingredient := range recipe.Ingredients {
+		name := strings.ToLower(strings.TrimSpace(ingredient.Name))
+		if ingredient.Optional || p.shopping[name] || suggestions.FindPantryItem(ingredient.Name, p.pantry) != nil {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// inPantry reports whether the pantry has every required ingredient of a
+// recipe
+func (p *planner) inPantry(recipe *database.Recipe) bool {
+	for _, ingredient := range recipe.Ingredients {
+		if !ingredient.Optional && suggestions.FindPantryItem(ingredient.Name, p.pantry) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Shopping lists the ingredients to buy for the planned slots
+func (p *planner) Shopping() []string {
+	names := make([]string, 0, len(p.shopping))
+	for name := range p.shopping {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Effort is the effort of the plan's busiest week
+func (p *planner) Effort() int {
+	busiest := 0
+	for _, effort := range p.effort {
+		busiest = max(busiest, effort)
+	}
+	return busiest
+}
+
+// Days forecasts the energy of each day of the plan
+func (p *planner) Days() []DayForecast {
+	days := make([]DayForecast, 0, p.days)
+	for day := 0; day < p.days; day++ {
+		date := p.date(day)
+		forecast := DayForecast{Date: date.Format("2006-01-02")}
+		if level, ok := p.Forecast[date.Weekday()]; ok {
+			forecast.Energy = &level
+		}
+		days = append(days, forecast)
+	}
+	return days
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,558 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package mealrequests
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/api/params"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/authz"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/mealtime"
+	"github.com/rghsoftware/space-food/internal/middleware"
+)
+
+// Handler handles meal request board HTTP requests
+type Handler struct {
+	db database.Database
+}
+
+// NewHandler creates a new meal request handler
+func NewHandler(db database.Database) *Handler {
+	return &Handler{
+		db: db,
+	}
+}
+
+// RegisterHouseholdRoutes registers the request board of each household
+func (h *Handler) RegisterHouseholdRoutes(router *gin.RouterGroup) {
+	router.GET("/:id/meal-requests", h.ListRequests)
+	router.POST("/:id/meal-requests", h.CreateRequest)
+	router.GET("/:id/meal-requests/:request_id", h.GetRequest)
+	router.DELETE("/:id/meal-requests/:request_id", h.DeleteRequest)
+	router.PUT("/:id/meal-requests/:request_id/vote", h.Vote)
+	router.DELETE("/:id/meal-requests/:request_id/vote", h.WithdrawVote)
+	router.POST("/:id/meal-requests/:request_id/accept", h.AcceptRequest)
+	router.POST("/:id/meal-requests/:request_id/decline", h.DeclineRequest)
+}
+
+// ListRequests lists a household's meal requests, most upvoted first
+// @Summary List meal requests
+// @Tags households
+// @Produce json
+// @Param id path string true "Household ID"
+// @Param status query string false "open (default), accepted, declined or all"
+// @Param limit query int false "Maximum requests to return (1-200, default 50)"
+// @Param offset query int false "Requests to skip"
+// @Router /households/{id}/meal-requests [get]
+func (h *Handler) ListRequests(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	query := params.Query(c)
+	status := query.String("status", database.MealRequestOpen, database.MealRequestAccepted, database.MealRequestDeclined, "all")
+	page := query.Page(50)
+	if !query.Valid() {
+		return
+	}
+	switch status {
+	case "":
+		status = database.MealRequestOpen
+	case "all":
+		status = ""
+	}
+
+	householdID := c.Param("id")
+	ctx := c.Request.Context()
+	if _, err := authz.Household(ctx, h.db, householdID, user.ID, authz.ViewHousehold); err != nil {
+		apierror.Lookup(c, err, "household not found")
+		return
+	}
+
+	requests, err := h.db.ListMealRequests(ctx, database.MealRequestFilter{
+		HouseholdID: householdID,
+		Status:      status,
+		Limit:       page.Limit,
+		Offset:      page.Offset,
+	})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	ids := make([]string, len(requests))
+	for i, request := range requests {
+		ids[i] = request.ID
+	}
+	votes, err := h.db.ListMealRequestVotes(ctx, ids)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	views := make([]RequestView, 0, len(requests))
+	for _, request := range requests {
+		views = append(views, Tally(request, votes[request.ID], user.ID))
+	}
+	c.JSON(http.StatusOK, views)
+}
+
+// CreateRequest proposes a meal, either a recipe from the library or just
+// a title such as "tacos"
+// @Summary Request a meal
+// @Tags households
+// @Accept json
+// @Produce json
+// @Param id path string true "Household ID"
+// @Success 201 {object} RequestView
+// @Router /households/{id}/meal-requests [post]
+func (h *Handler) CreateRequest(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	householdID := c.Param("id")
+	ctx := c.Request.Context()
+	if _, err := authz.Household(ctx, h.db, householdID, user.ID, authz.ViewHousehold); err != nil {
+		apierror.Lookup(c, err, "household not found")
+		return
+	}
+
+	var req struct {
+		RecipeID string `json:"recipe_id"`
+		Title    string `json:"title" binding:"max=200"`
+		Notes    string `json:"notes" binding:"max=1000"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	now := time.Now()
+	request := database.MealRequest{
+		ID:          uuid.New().String(),
+		HouseholdID: householdID,
+		RequestedBy: &user.ID,
+		Title:       strings.TrimSpace(req.Title),
+		Notes:       strings.TrimSpace(req.Notes),
+		Status:      database.MealRequestOpen,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if req.RecipeID != "" {
+		recipe, err := h.db.GetRecipeByID(ctx, req.RecipeID)
+		if err != nil {
+			apierror.Lookup(c, err, "recipe not found")
+			return
+		}
+		if !authz.CanViewRecipe(user.ID, recipe) {
+			apierror.NotFound(c, "recipe not found")
+			return
+		}
+		// One open request per recipe, so votes gather in one place
+		open, err := h.db.ListMealRequests(ctx, database.MealRequestFilter{HouseholdID: householdID, Status: database.MealRequestOpen})
+		if err != nil {
+			apierror.Respond(c, err)
+			return
+		}
+		for _, other := range open {
+			if other.RecipeID != nil && *other.RecipeID == recipe.ID {
+				apierror.Conflict(c, "this recipe has already been requested")
+				return
+			}
+		}
+		request.RecipeID = &recipe.ID
+		if request.Title == "" {
+			request.Title = recipe.Title
+		}
+	}
+	if request.Title == "" {
+		apierror.BadRequest(c, "title or recipe_id is required")
+		return
+	}
+
+	if err := h.db.CreateMealRequest(ctx, &request); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, Tally(&request, nil, user.ID))
+}
+
+// GetRequest retrieves a meal request and its votes
+// @Summary Get meal request
+// @Tags households
+// @Produce json
+// @Param id path string true "Household ID"
+// @Param request_id path string true "Meal request ID"
+// @Success 200 {object} RequestView
+// @Router /households/{id}/meal-requests/{request_id} [get]
+func (h *Handler) GetRequest(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	request, _, ok := h.loadRequest(c, user.ID, authz.ViewHousehold)
+	if !ok {
+		return
+	}
+	h.respond(c, http.StatusOK, request, user.ID)
+}
+
+// DeleteRequest withdraws a meal request. The member who made it can, as
+// can anyone who plans the household's meals.
+// @Summary Delete meal request
+// @Tags households
+// @Param id path string true "Household ID"
+// @Param request_id path string true "Meal request ID"
+// @Success 204
+// @Router /households/{id}/meal-requests/{request_id} [delete]
+func (h *Handler) DeleteRequest(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	request, member, ok := h.loadRequest(c, user.ID, authz.ViewHousehold)
+	if !ok {
+		return
+	}
+	requester := request.RequestedBy != nil && *request.RequestedBy == user.ID
+	if !requester && !authz.Allows(member.Role, authz.EditMealPlans) {
+		apierror.Forbidden(c, "only the member who made a request or someone who plans meals can delete it")
+		return
+	}
+
+	if err := h.db.DeleteMealRequest(c.Request.Context(), request.ID); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Vote upvotes an open request, or marks it as something the member can't
+// eat, replacing any earlier vote of theirs
+// @Summary Vote on meal request
+// @Tags households
+// @Accept json
+// @Produce json
+// @Param id path string true "Household ID"
+// @Param request_id path string true "Meal request ID"
+// @Success 200 {object} RequestView
+// @Router /households/{id}/meal-requests/{request_id}/vote [put]
+func (h *Handler) Vote(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req struct {
+		Vote   string `json:"vote" binding:"required"`
+		Reason string `json:"reason" binding:"max=500"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+	if !IsVote(req.Vote) {
+		apierror.BadRequest(c, "vote must be up or cant_eat")
+		return
+	}
+
+	request, _, ok := h.loadRequest(c, user.ID, authz.ViewHousehold)
+	if !ok {
+		return
+	}
+	if request.Status != database.MealRequestOpen {
+		apierror.Conflict(c, "request is no longer open")
+		return
+	}
+
+	vote := database.MealRequestVote{
+		RequestID: request.ID,
+		UserID:    user.ID,
+		Vote:      req.Vote,
+		Reason:    strings.TrimSpace(req.Reason),
+		CreatedAt: time.Now(),
+	}
+	if err := h.db.UpsertMealRequestVote(c.Request.Context(), &vote); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	h.respond(c, http.StatusOK, request, user.ID)
+}
+
+// WithdrawVote removes the member's vote from a request
+// @Summary Withdraw meal request vote
+// @Tags households
+// @Produce json
+// @Param id path string true "Household ID"
+// @Param request_id path string true "Meal request ID"
+// @Success 200 {object} RequestView
+// @Router /households/{id}/meal-requests/{request_id}/vote [delete]
+func (h *Handler) WithdrawVote(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	request, _, ok := h.loadRequest(c, user.ID, authz.ViewHousehold)
+	if !ok {
+		return
+	}
+	if err := h.db.DeleteMealRequestVote(c.Request.Context(), request.ID, user.ID); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	h.respond(c, http.StatusOK, request, user.ID)
+}
+
+// acceptRequest is the body of an accept call
+type acceptRequest struct {
+	Date       string `json:"date" binding:"required"`      // YYYY-MM-DD
+	MealType   string `json:"meal_type" binding:"required"` // breakfast, lunch, dinner, snack
+	Servings   int    `json:"servings" binding:"omitempty,min=1,max=100"`
+	MealPlanID string `json:"meal_plan_id"` // the plan to add it to; by default the caller's plan covering the date, or a new one for that week
+}
+
+// AcceptRequest puts an open request on the caller's meal plan and marks
+// it accepted. Without a meal_plan_id it goes on whichever of the caller's
+// plans covers the date, or on a new plan for that week. Servings default
+// to the number of household members.
+// @Summary Accept meal request
+// @Tags households
+// @Accept json
+// @Produce json
+// @Param id path string true "Household ID"
+// @Param request_id path string true "Meal request ID"
+// @Router /households/{id}/meal-requests/{request_id}/accept [post]
+func (h *Handler) AcceptRequest(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req acceptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+	if !mealtime.IsMealType(req.MealType) {
+		apierror.BadRequest(c, "meal_type must be breakfast, lunch, dinner or snack")
+		return
+	}
+	loc := mealtime.Location(mealtime.LoadPreferences(c, h.db, user.ID))
+	day, err := time.ParseInLocation("2006-01-02", req.Date, loc)
+	if err != nil {
+		apierror.BadRequest(c, "date must be YYYY-MM-DD")
+		return
+	}
+
+	request, _, ok := h.loadRequest(c, user.ID, authz.EditMealPlans)
+	if !ok {
+		return
+	}
+	if request.Status != database.MealRequestOpen {
+		apierror.Conflict(c, "request is no longer open")
+		return
+	}
+
+	ctx := c.Request.Context()
+	if req.Servings == 0 {
+		members, err := h.db.ListHouseholdMembers(ctx, request.HouseholdID)
+		if err != nil {
+			apierror.Respond(c, err)
+			return
+		}
+		req.Servings = max(len(members), 1)
+	}
+
+	now := time.Now()
+	plan, created, ok := h.planFor(c, user.ID, req.MealPlanID, day, now)
+	if !ok {
+		return
+	}
+	plan.Meals = append(plan.Meals, PlannedMeal(request, plan.ID, day, req.MealType, req.Servings))
+	plan.UpdatedAt = now
+
+	request.Status = database.MealRequestAccepted
+	request.DecidedBy = &user.ID
+	request.DecidedAt = &now
+	request.MealPlanID = &plan.ID
+	request.PlannedDate = &day
+	request.MealType = req.MealType
+	request.UpdatedAt = now
+
+	err = h.db.WithTx(ctx, func(ctx context.Context) error {
+		if created {
+			if err := h.db.CreateMealPlan(ctx, plan); err != nil {
+				return err
+			}
+		} else if err := h.db.UpdateMealPlan(ctx, plan); err != nil {
+			return err
+		}
+		return h.db.UpdateMealRequest(ctx, request)
+	})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	votes, err := h.db.ListMealRequestVotes(ctx, []string{request.ID})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"request":   Tally(request, votes[request.ID], user.ID),
+		"meal_plan": plan,
+	})
+}
+
+// DeclineRequest turns down an open request
+// @Summary Decline meal request
+// @Tags households
+// @Produce json
+// @Param id path string true "Household ID"
+// @Param request_id path string true "Meal request ID"
+// @Success 200 {object} RequestView
+// @Router /households/{id}/meal-requests/{request_id}/decline [post]
+func (h *Handler) DeclineRequest(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	request, _, ok := h.loadRequest(c, user.ID, authz.EditMealPlans)
+	if !ok {
+		return
+	}
+	if request.Status != database.MealRequestOpen {
+		apierror.Conflict(c, "request is no longer open")
+		return
+	}
+
+	now := time.Now()
+	request.Status = database.MealRequestDeclined
+	request.DecidedBy = &user.ID
+	request.DecidedAt = &now
+	request.UpdatedAt = now
+	if err := h.db.UpdateMealRequest(c.Request.Context(), request); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	h.respond(c, http.StatusOK, request, user.ID)
+}
+
+// loadRequest checks the user's household role allows action and loads
+// the request named in the path, which must belong to that household. It
+// writes the error response and returns false when either fails.
+func (h *Handler) loadRequest(c *gin.Context, userID string, action authz.Action) (*database.MealRequest, *database.HouseholdMember, bool) {
+	householdID := c.Param("id")
+	member, err := authz.Household(c.Request.Context(), h.db, householdID, userID, action)
+	if err != nil {
+		apierror.Lookup(c, err, "household not found")
+		return nil, nil, false
+	}
+
+	request, err := h.db.GetMealRequestByID(c.Request.Context(), c.Param("request_id"))
+	if err != nil {
+		apierror.Lookup(c, err, "meal request not found")
+		return nil, nil, false
+	}
+	if request.HouseholdID != householdID {
+		apierror.NotFound(c, "meal request not found")
+		return nil, nil, false
+	}
+	return request, member, true
+}
+
+// planFor finds the meal plan an accepted request goes on: the one asked
+// for, the user's plan covering the day, or else a new plan for that week,
+// which the caller saves and planFor reports as created. It writes the
+// error response and returns false when the asked-for plan can't take the
+// meal.
+func (h *Handler) planFor(c *gin.Context, userID, planID string, day, now time.Time) (plan *database.MealPlan, created, ok bool) {
+	ctx := c.Request.Context()
+	if planID != "" {
+		plan, err := h.db.GetMealPlanByID(ctx, planID)
+		if err != nil {
+			apierror.Lookup(c, err, "meal plan not found")
+			return nil, false, false
+		}
+		if !authz.CanEditMealPlan(userID, plan) {
+			apierror.Forbidden(c, "forbidden")
+			return nil, false, false
+		}
+		if !Covers(plan, day) {
+			apierror.BadRequest(c, "date must be a day of the plan")
+			return nil, false, false
+		}
+		return plan, false, true
+	}
+
+	plans, err := h.db.ListMealPlans(ctx, database.MealPlanFilter{
+		UserID:    userID,
+		StartDate: day,
+		EndDate:   day.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		apierror.Respond(c, err)
+		return nil, false, false
+	}
+	for _, plan := range plans {
+		if Covers(plan, day) {
+			return plan, false, true
+		}
+	}
+	return WeekPlan(userID, day, now), true, true
+}
+
+// respond writes a request with its current votes
+func (h *Handler) respond(c *gin.Context, status int, request *database.MealRequest, userID string) {
+	votes, err := h.db.ListMealRequestVotes(c.Request.Context(), []string{request.ID})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	c.JSON(status, Tally(request, votes[request.ID], userID))
+}
@@ -0,0 +1,104 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package mealrequests runs a household's request board, where members
+// propose meals they would like that week, the others upvote them or say
+// they can't eat them, and whoever plans the meals drops the accepted ones
+// into a meal plan.
+package mealrequests
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// RequestView is a meal request with its votes tallied for the viewer
+type RequestView struct {
+	*database.MealRequest
+	Upvotes int                         `json:"upvotes"`
+	CantEat int                         `json:"cant_eat"`
+	MyVote  string                      `json:"my_vote,omitempty"`
+	Votes   []*database.MealRequestVote `json:"votes"`
+}
+
+// IsVote reports whether s is a vote a member can cast
+func IsVote(s string) bool {
+	return s == database.MealRequestVoteUp || s == database.MealRequestVoteCantEat
+}
+
+// Tally counts the votes on a request and picks out the viewer's
+func Tally(request *database.MealRequest, votes []*database.MealRequestVote, userID string) RequestView {
+	view := RequestView{MealRequest: request, Votes: []*database.MealRequestVote{}}
+	for _, vote := range votes {
+		switch vote.Vote {
+		case database.MealRequestVoteUp:
+			view.Upvotes++
+		case database.MealRequestVoteCantEat:
+			view.CantEat++
+		}
+		if vote.UserID == userID {
+			view.MyVote = vote.Vote
+		}
+		view.Votes = append(view.Votes, vote)
+	}
+	return view
+}
+
+// Covers reports whether a meal plan runs over the given day
+func Covers(plan *database.MealPlan, day time.Time) bool {
+	date := day.Format("2006-01-02")
+	return plan.StartDate.Format("2006-01-02") <= date && date <= plan.EndDate.Format("2006-01-02")
+}
+
+// WeekPlan starts a meal plan for the Monday-to-Sunday week around day,
+// for accepted requests that fall outside the user's existing plans
+func WeekPlan(userID string, day time.Time, now time.Time) *database.MealPlan {
+	start := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	return &database.MealPlan{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Title:     "Meals from " + start.Format("Mon 2 Jan"),
+		StartDate: start,
+		EndDate:   start.AddDate(0, 0, 6),
+		Meals:     []database.PlannedMeal{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// PlannedMeal turns an accepted request into a meal on a plan. Requests
+// without a recipe keep their title as the meal's note, as leftovers do
+// on generated plans.
+func PlannedMeal(request *database.MealRequest, planID string, day time.Time, mealType string, servings int) database.PlannedMeal {
+	meal := database.PlannedMeal{
+		ID:         uuid.New().String(),
+		MealPlanID: planID,
+		Date:       day,
+		MealType:   mealType,
+		Servings:   servings,
+	}
+	if request.RecipeID != nil {
+		meal.RecipeID = *request.RecipeID
+		meal.Notes = request.Notes
+	} else {
+		meal.Notes = request.Title
+	}
+	return meal
+}
@@ -0,0 +1,205 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package mealtime
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/middleware"
+)
+
+// Handler handles meal time preference HTTP requests
+type Handler struct {
+	db database.Database
+}
+
+// NewHandler creates a new meal time handler
+func NewHandler(db database.Database) *Handler {
+	return &Handler{
+		db: db,
+	}
+}
+
+// RegisterRoutes registers meal time routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("", h.GetMealWindows)
+	router.PUT("", h.UpdateMealWindows)
+	router.GET("/now", h.GetCurrentMealTypes)
+}
+
+// mealWindowPayload is the wire format of a meal window, using HH:MM times
+type mealWindowPayload struct {
+	MealType string `json:"meal_type" binding:"required"`
+	Start    string `json:"start" binding:"required"`
+	End      string `json:"end" binding:"required"`
+}
+
+// GetMealWindows returns the authenticated user's meal windows
+// @Summary Get meal windows
+// @Tags meal-windows
+// @Produce json
+// @Router /me/meal-windows [get]
+func (h *Handler) GetMealWindows(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	prefs := LoadPreferences(c, h.db, user.ID)
+	c.JSON(http.StatusOK, toResponse(prefs))
+}
+
+// UpdateMealWindows replaces the authenticated user's meal windows
+// @Summary Update meal windows
+// @Tags meal-windows
+// @Accept json
+// @Produce json
+// @Router /me/meal-windows [put]
+func (h *Handler) UpdateMealWindows(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req struct {
+		Timezone        string              `json:"timezone"`
+		BreakfastAllDay bool                `json:"breakfast_all_day"`
+		Windows         []mealWindowPayload `json:"windows" binding:"dive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	if req.Timezone == "" {
+		req.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(req.Timezone); err != nil {
+		apierror.BadRequest(c, "unknown timezone")
+		return
+	}
+
+	windows := make([]database.MealWindow, 0, len(req.Windows))
+	for _, w := range req.Windows {
+		if !IsMealType(w.MealType) {
+			apierror.BadRequest(c, fmt.Sprintf("invalid meal type: %s", w.MealType))
+			return
+		}
+		start, err := parseClock(w.Start)
+		if err != nil {
+			apierror.BadRequest(c, err.Error())
+			return
+		}
+		end, err := parseClock(w.End)
+		if err != nil {
+			apierror.BadRequest(c, err.Error())
+			return
+		}
+		if start == end {
+			apierror.BadRequest(c, "meal window start and end must differ")
+			return
+		}
+		windows = append(windows, database.MealWindow{MealType: w.MealType, StartMinute: start, EndMinute: end})
+	}
+
+	prefs := database.MealTimePreferences{
+		UserID:          user.ID,
+		Timezone:        req.Timezone,
+		Windows:         windows,
+		BreakfastAllDay: req.BreakfastAllDay,
+		UpdatedAt:       time.Now(),
+	}
+
+	if err := h.db.UpsertMealTimePreferences(c.Request.Context(), &prefs); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toResponse(&prefs))
+}
+
+// GetCurrentMealTypes returns the meal types suggestions are currently limited to
+// @Summary Get current meal types
+// @Tags meal-windows
+// @Produce json
+// @Router /me/meal-windows/now [get]
+func (h *Handler) GetCurrentMealTypes(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	prefs := LoadPreferences(c, h.db, user.ID)
+	now := time.Now()
+
+	c.JSON(http.StatusOK, gin.H{
+		"local_time": now.In(Location(prefs)).Format("15:04"),
+		"timezone":   prefs.Timezone,
+		"meal_types": AllowedMealTypes(prefs, now),
+	})
+}
+
+// LoadPreferences fetches a user's meal time preferences, falling back to
+// the defaults when none have been saved
+func LoadPreferences(c *gin.Context, db database.Database, userID string) *database.MealTimePreferences {
+	prefs, err := db.GetMealTimePreferences(c.Request.Context(), userID)
+	if err != nil {
+		return DefaultPreferences(userID)
+	}
+	return prefs
+}
+
+// toResponse converts preferences to their wire format
+func toResponse(prefs *database.MealTimePreferences) gin.H {
+	windows := make([]mealWindowPayload, 0, len(prefs.Windows))
+	for _, w := range prefs.Windows {
+		windows = append(windows, mealWindowPayload{
+			MealType: w.MealType,
+			Start:    formatClock(w.StartMinute),
+			End:      formatClock(w.EndMinute),
+		})
+	}
+	return gin.H{
+		"timezone":          prefs.Timezone,
+		"breakfast_all_day": prefs.BreakfastAllDay,
+		"windows":           windows,
+	}
+}
+
+// parseClock parses an HH:MM time into minutes after midnight
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// formatClock formats minutes after midnight as HH:MM
+func formatClock(minute int) string {
+	return fmt.Sprintf("%02d:%02d", minute/60, minute%60)
+}
@@ -0,0 +1,157 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package mealtime
+
+import (
+	"strings"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Meal types used for time-of-day filtering
+const (
+	MealTypeBreakfast = "breakfast"
+	MealTypeLunch     = "lunch"
+	MealTypeDinner    = "dinner"
+	MealTypeSnack     = "snack"
+)
+
+// MealTypes lists the recognised meal types
+var MealTypes = []string{MealTypeBreakfast, MealTypeLunch, MealTypeDinner, MealTypeSnack}
+
+// DefaultWindows are used until a user configures their own meal windows
+var DefaultWindows = []database.MealWindow{
+	{MealType: MealTypeBreakfast, StartMinute: 6 * 60, EndMinute: 10*60 + 30},
+	{MealType: MealTypeLunch, StartMinute: 11 * 60, EndMinute: 14*60 + 30},
+	{MealType: MealTypeDinner, StartMinute: 17 * 60, EndMinute: 21*60 + 30},
+}
+
+// DefaultPreferences returns the preferences used for users without saved settings
+func DefaultPreferences(userID string) *database.MealTimePreferences {
+	windows := make([]database.MealWindow, len(DefaultWindows))
+	copy(windows, DefaultWindows)
+	return &database.MealTimePreferences{
+		UserID:   userID,
+		Timezone: "UTC",
+		Windows:  windows,
+	}
+}
+
+// IsMealType reports whether s is a recognised meal type
+func IsMealType(s string) bool {
+	for _, t := range MealTypes {
+		if s == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Location returns the user's time zone, falling back to UTC
+func Location(prefs *database.MealTimePreferences) *time.Location {
+	if prefs == nil || prefs.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(prefs.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// AllowedMealTypes returns the meal types appropriate at the given instant.
+// Snacks are always allowed, and breakfast is allowed all day when the user
+// has opted in.
+func AllowedMealTypes(prefs *database.MealTimePreferences, now time.Time) []string {
+	local := now.In(Location(prefs))
+	minute := local.Hour()*60 + local.Minute()
+
+	allowed := map[string]bool{MealTypeSnack: true}
+	if prefs != nil && prefs.BreakfastAllDay {
+		allowed[MealTypeBreakfast] = true
+	}
+	if prefs != nil {
+		for _, w := range prefs.Windows {
+			if inWindow(w, minute) {
+				allowed[w.MealType] = true
+			}
+		}
+	}
+
+	// Keep a stable order for clients
+	types := []string{}
+	for _, t := range MealTypes {
+		if allowed[t] {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// RecipeMealTypes returns the meal types a recipe is tagged or categorised with
+func RecipeMealTypes(recipe *database.Recipe) []string {
+	seen := map[string]bool{}
+	types := []string{}
+	for _, label := range append(append([]string{}, recipe.Categories...), recipe.Tags...) {
+		t := strings.ToLower(strings.TrimSpace(label))
+		if IsMealType(t) && !seen[t] {
+			seen[t] = true
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// RecipeAllowed reports whether a recipe fits any of the allowed meal types.
+// Recipes without a meal type label are suitable at any time.
+func RecipeAllowed(recipe *database.Recipe, allowed []string) bool {
+	types := RecipeMealTypes(recipe)
+	if len(types) == 0 {
+		return true
+	}
+	for _, t := range types {
+		for _, a := range allowed {
+			if t == a {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FilterRecipes keeps only the recipes suitable for the allowed meal types
+func FilterRecipes(recipes []*database.Recipe, allowed []string) []*database.Recipe {
+	filtered := make([]*database.Recipe, 0, len(recipes))
+	for _, recipe := range recipes {
+		if RecipeAllowed(recipe, allowed) {
+			filtered = append(filtered, recipe)
+		}
+	}
+	return filtered
+}
+
+// inWindow reports whether minute falls inside w, supporting windows that
+// wrap past midnight (e.g. a 22:00-01:00 late snack)
+func inWindow(w database.MealWindow, minute int) bool {
+	if w.StartMinute <= w.EndMinute {
+		return minute >= w.StartMinute && minute < w.EndMinute
+	}
+	return minute >= w.StartMinute || minute < w.EndMinute
+}
@@ -23,19 +23,24 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/api/params"
+	"github.com/rghsoftware/space-food/internal/apierror"
 	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/events"
 	"github.com/rghsoftware/space-food/internal/middleware"
 )
 
 // Handler handles nutrition tracking HTTP requests
 type Handler struct {
-	db database.Database
+	db     database.Database
+	events *events.Bus
 }
 
 // NewHandler creates a new nutrition handler
-func NewHandler(db database.Database) *Handler {
+func NewHandler(db database.Database, bus *events.Bus) *Handler {
 	return &Handler{
-		db: db,
+		db:     db,
+		events: bus,
 	}
 }
 
@@ -51,7 +56,13 @@ func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
 func (h *Handler) ListNutritionLogs(c *gin.Context) {
 	user, ok := middleware.GetUserFromContext(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	query := params.Query(c)
+	page := query.Page(100)
+	if !query.Valid() {
 		return
 	}
 
@@ -63,13 +74,13 @@ func (h *Handler) ListNutritionLogs(c *gin.Context) {
 		UserID:    user.ID,
 		StartDate: startDate,
 		EndDate:   endDate,
-		Limit:     100,
-		Offset:    0,
+		Limit:     page.Limit,
+		Offset:    page.Offset,
 	}
 
 	logs, err := h.db.ListNutritionLogs(c.Request.Context(), filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Respond(c, err)
 		return
 	}
 
@@ -80,7 +91,7 @@ func (h *Handler) ListNutritionLogs(c *gin.Context) {
 func (h *Handler) GetTodayNutritionLog(c *gin.Context) {
 	user, ok := middleware.GetUserFromContext(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		apierror.Unauthorized(c, "unauthorized")
 		return
 	}
 
@@ -88,7 +99,7 @@ func (h *Handler) GetTodayNutritionLog(c *gin.Context) {
 
 	logs, err := h.db.GetNutritionLog(c.Request.Context(), user.ID, today)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Respond(c, err)
 		return
 	}
 
@@ -99,22 +110,23 @@ func (h *Handler) GetTodayNutritionLog(c *gin.Context) {
 func (h *Handler) CreateNutritionLog(c *gin.Context) {
 	user, ok := middleware.GetUserFromContext(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		apierror.Unauthorized(c, "unauthorized")
 		return
 	}
 
 	var log database.NutritionLog
 	if err := c.ShouldBindJSON(&log); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Invalid(c, err)
 		return
 	}
 
 	log.UserID = user.ID
 
 	if err := h.db.CreateNutritionLog(c.Request.Context(), &log); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Respond(c, err)
 		return
 	}
+	h.events.Publish(c.Request.Context(), events.NewMealLogged(&log))
 
 	c.JSON(http.StatusCreated, log)
 }
@@ -123,7 +135,7 @@ func (h *Handler) CreateNutritionLog(c *gin.Context) {
 func (h *Handler) GetNutritionSummary(c *gin.Context) {
 	user, ok := middleware.GetUserFromContext(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		apierror.Unauthorized(c, "unauthorized")
 		return
 	}
 
@@ -141,7 +153,7 @@ func (h *Handler) GetNutritionSummary(c *gin.Context) {
 
 	logs, err := h.db.ListNutritionLogs(c.Request.Context(), filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Respond(c, err)
 		return
 	}
 
@@ -22,6 +22,9 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/api/params"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/authz"
 	"github.com/rghsoftware/space-food/internal/database"
 	"github.com/rghsoftware/space-food/internal/middleware"
 )
@@ -51,19 +54,25 @@ func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
 func (h *Handler) ListPantryItems(c *gin.Context) {
 	user, ok := middleware.GetUserFromContext(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	query := params.Query(c)
+	page := query.Page(100)
+	if !query.Valid() {
 		return
 	}
 
 	filter := database.PantryFilter{
 		UserID: user.ID,
-		Limit:  100,
-		Offset: 0,
+		Limit:  page.Limit,
+		Offset: page.Offset,
 	}
 
 	items, err := h.db.ListPantryItems(c.Request.Context(), filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Respond(c, err)
 		return
 	}
 
@@ -72,14 +81,26 @@ func (h *Handler) ListPantryItems(c *gin.Context) {
 
 // GetPantryItem retrieves a single pantry item by ID
 func (h *Handler) GetPantryItem(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
 	id := c.Param("id")
 
 	item, err := h.db.GetPantryItemByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "pantry item not found"})
+		apierror.Lookup(c, err, "pantry item not found")
+		return
+	}
+
+	if !authz.Owns(user.ID, item.UserID) {
+		apierror.NotFound(c, "pantry item not found")
 		return
 	}
 
+	middleware.SetLastModified(c, item.UpdatedAt)
 	c.JSON(http.StatusOK, item)
 }
 
@@ -87,20 +108,20 @@ func (h *Handler) GetPantryItem(c *gin.Context) {
 func (h *Handler) CreatePantryItem(c *gin.Context) {
 	user, ok := middleware.GetUserFromContext(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		apierror.Unauthorized(c, "unauthorized")
 		return
 	}
 
 	var item database.PantryItem
 	if err := c.ShouldBindJSON(&item); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Invalid(c, err)
 		return
 	}
 
 	item.UserID = user.ID
 
 	if err := h.db.CreatePantryItem(c.Request.Context(), &item); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Respond(c, err)
 		return
 	}
 
@@ -111,7 +132,7 @@ func (h *Handler) CreatePantryItem(c *gin.Context) {
 func (h *Handler) UpdatePantryItem(c *gin.Context) {
 	user, ok := middleware.GetUserFromContext(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		apierror.Unauthorized(c, "unauthorized")
 		return
 	}
 
@@ -120,18 +141,18 @@ func (h *Handler) UpdatePantryItem(c *gin.Context) {
 	// Verify ownership
 	existing, err := h.db.GetPantryItemByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "pantry item not found"})
+		apierror.Lookup(c, err, "pantry item not found")
 		return
 	}
 
-	if existing.UserID != user.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+	if !authz.Owns(user.ID, existing.UserID) {
+		apierror.Forbidden(c, "forbidden")
 		return
 	}
 
 	var item database.PantryItem
 	if err := c.ShouldBindJSON(&item); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Invalid(c, err)
 		return
 	}
 
@@ -139,7 +160,7 @@ func (h *Handler) UpdatePantryItem(c *gin.Context) {
 	item.UserID = user.ID
 
 	if err := h.db.UpdatePantryItem(c.Request.Context(), &item); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Respond(c, err)
 		return
 	}
 
@@ -150,7 +171,7 @@ func (h *Handler) UpdatePantryItem(c *gin.Context) {
 func (h *Handler) DeletePantryItem(c *gin.Context) {
 	user, ok := middleware.GetUserFromContext(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		apierror.Unauthorized(c, "unauthorized")
 		return
 	}
 
@@ -159,17 +180,17 @@ func (h *Handler) DeletePantryItem(c *gin.Context) {
 	// Verify ownership
 	existing, err := h.db.GetPantryItemByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "pantry item not found"})
+		apierror.Lookup(c, err, "pantry item not found")
 		return
 	}
 
-	if existing.UserID != user.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+	if !authz.Owns(user.ID, existing.UserID) {
+		apierror.Forbidden(c, "forbidden")
 		return
 	}
 
 	if err := h.db.DeletePantryItem(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Respond(c, err)
 		return
 	}
 
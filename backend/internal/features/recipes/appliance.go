@@ -0,0 +1,140 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package recipes
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Appliances cook mode can adapt a recipe's steps to, as given in the
+// appliance query parameter
+const (
+	ApplianceAirFryer       = "air_fryer"
+	AppliancePressureCooker = "pressure_cooker"
+	ApplianceSlowCooker     = "slow_cooker"
+)
+
+var (
+	// ovenStep matches steps that cook in the oven
+	ovenStep = regexp.MustCompile(`(?i)\b(?:bake|baked|baking|roast|roasted|roasting|oven)\b`)
+	// preheatStep matches heating the oven before using it
+	preheatStep = regexp.MustCompile(`(?i)\b(?:preheat|pre-heat|heat the oven)\b`)
+	// simmerStep matches long, wet cooking on the hob
+	simmerStep = regexp.MustCompile(`(?i)\b(?:simmer|simmering|braise|braising|stew|stewing|boil|boiling)\b`)
+	// searStep matches browning in fat before the long cook
+	searStep = regexp.MustCompile(`(?i)\b(?:brown|browned|sear|seared|saut[eé]|saut[eé]ed|fry|fried)\b`)
+	// ovenTemperature matches an oven setting such as "200°C", "400 F" or
+	// "180 degrees C"
+	ovenTemperature = regexp.MustCompile(`(?i)\b(\d{3})\s*(?:°|º|degrees?)?\s*([CF])\b`)
+)
+
+// airFryerTemperature lowers an oven setting for an air fryer, which cooks
+// hotter for the same dial
+func airFryerTemperature(step string) string {
+	m := ovenTemperature.FindStringSubmatch(step)
+	if m == nil {
+		return ""
+	}
+	degrees, _ := strconv.Atoi(m[1])
+	unit := strings.ToUpper(m[2])
+	if unit == "C" {
+		degrees -= 20
+	} else {
+		degrees -= 25
+	}
+	return fmt.Sprintf("%d°%s", int(math.Round(float64(degrees)/5))*5, unit)
+}
+
+// scaleTimers shortens or lengthens a step's timers, to no less than a
+// minute
+func scaleTimers(step *CookStep, factor float64) {
+	for i := range step.Timers {
+		seconds := float64(step.Timers[i].Seconds) * factor
+		step.Timers[i].Seconds = int(math.Max(math.Round(seconds/60), 1)) * 60
+	}
+}
+
+// AdaptSteps rewrites the timing of a recipe's steps for an appliance and
+// gives each step it changes a tip on doing it that way. The step text is
+// left as written.
+func AdaptSteps(steps []CookStep, appliance string) {
+	// Recipes usually give the oven temperature once, when preheating
+	temperature := ""
+	for i := range steps {
+		step := &steps[i]
+		if t := airFryerTemperature(step.Text); t != "" {
+			temperature = t
+		}
+		switch appliance {
+		case ApplianceAirFryer:
+			adaptAirFryer(step, temperature)
+		case AppliancePressureCooker:
+			adaptPressureCooker(step)
+		case ApplianceSlowCooker:
+			adaptSlowCooker(step)
+		}
+	}
+}
+
+func adaptAirFryer(step *CookStep, temperature string) {
+	switch {
+	case preheatStep.MatchString(step.Text):
+		step.Tip = "Preheat the air fryer for 3-5 minutes instead"
+		if temperature != "" {
+			step.Tip += ", at " + temperature
+		}
+		step.Tip += "."
+	case ovenStep.MatchString(step.Text):
+		if temperature != "" {
+			step.Tip = "Air fry at " + temperature + " instead"
+		} else {
+			step.Tip = "Air fry about 20°C (25°F) lower than the oven temperature"
+		}
+		step.Tip += " for about a fifth less time. Don't crowd the basket, and turn or shake halfway through."
+		scaleTimers(step, 0.8)
+	}
+}
+
+func adaptPressureCooker(step *CookStep) {
+	switch {
+	case simmerStep.MatchString(step.Text):
+		step.Tip = "Pressure cook on high for about a third of the time, then let the pressure release naturally for 10 minutes. Keep at least 250 ml (1 cup) of liquid in the pot."
+		scaleTimers(step, 1.0/3)
+	case searStep.MatchString(step.Text):
+		step.Tip = "Use the sauté setting, in the inner pot."
+	}
+}
+
+func adaptSlowCooker(step *CookStep) {
+	switch {
+	case simmerStep.MatchString(step.Text), ovenStep.MatchString(step.Text) && !preheatStep.MatchString(step.Text):
+		step.Tip = "Cook covered on low for 6-8 hours, or high for 3-4, instead. Use about a third less liquid, as little of it evaporates."
+		if len(step.Timers) > 0 {
+			step.Timers = []StepTimer{{Label: "Slow cook on low", Seconds: 6 * 60 * 60}}
+		}
+	case preheatStep.MatchString(step.Text):
+		step.Tip = "No need to preheat; the slow cooker warms up with the food in it."
+	case searStep.MatchString(step.Text):
+		step.Tip = "Brown in a pan first for more flavour, or skip this and add everything straight to the slow cooker."
+	}
+}
@@ -0,0 +1,125 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package recipes
+
+import (
+	"context"
+	"math"
+	"regexp"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Effort scores run from MinEffort to MaxEffort
+const (
+	MinEffort = 1
+	MaxEffort = 10
+)
+
+// equipment is the kit recipes are known to need, found by the words they
+// use for it
+var equipment = []struct {
+	name    string
+	pattern *regexp.Regexp
+}{
+	{"oven", regexp.MustCompile(`(?i)\bovens?\b`)},
+	{"pan", regexp.MustCompile(`(?i)\b(?:pans?|skillets?|woks?)\b`)},
+	{"pot", regexp.MustCompile(`(?i)\b(?:pots?|saucepans?|stockpots?)\b`)},
+	{"baking dish", regexp.MustCompile(`(?i)\b(?:baking (?:dish|sheet|tray)|sheet pan|casserole dish|roasting (?:tin|tray))s?\b`)},
+	{"blender", regexp.MustCompile(`(?i)\bblender\b`)},
+	{"food processor", regexp.MustCompile(`(?i)\bfood processor\b`)},
+	{"mixer", regexp.MustCompile(`(?i)\bmixer\b`)},
+	{"grater", regexp.MustCompile(`(?i)\bgrater\b`)},
+	{"sieve", regexp.MustCompile(`(?i)\b(?:sieve|strainer|colander)\b`)},
+	{"rolling pin", regexp.MustCompile(`(?i)\brolling pin\b`)},
+	{"thermometer", regexp.MustCompile(`(?i)\bthermometer\b`)},
+	{"grill", regexp.MustCompile(`(?i)\bgrill\b`)},
+	{"microwave", regexp.MustCompile(`(?i)\bmicrowave\b`)},
+	{"slow cooker", regexp.MustCompile(`(?i)\b(?:slow cooker|crock[- ]?pot)\b`)},
+	{"pressure cooker", regexp.MustCompile(`(?i)\b(?:pressure cooker|instant pot)\b`)},
+	{"air fryer", regexp.MustCompile(`(?i)\bair[- ]?fryer\b`)},
+}
+
+// DetectEquipment finds the kit instructions call for
+func DetectEquipment(instructions string) []string {
+	found := []string{}
+	for _, kit := range equipment {
+		if kit.pattern.MatchString(instructions) {
+			found = append(found, kit.name)
+		}
+	}
+	return found
+}
+
+// Effort scores how much effort a recipe takes from the length of its
+// breakdown into steps, its hands-on time and the equipment it uses. When
+// cooks have rated how hard it was, their ratings count for up to half of
+// the score, more the more of them there are.
+func Effort(recipe *database.Recipe, difficulty *database.RecipeDifficulty) *database.RecipeEffort {
+	steps := CookSteps(recipe.Instructions)
+	waiting := 0
+	for _, step := range steps {
+		for _, timer := range step.Timers {
+			waiting += timer.Seconds
+		}
+	}
+	effort := &database.RecipeEffort{
+		Steps:         len(steps),
+		ActiveMinutes: recipe.PrepTime + max(recipe.CookTime-waiting/60, 0),
+		Equipment:     DetectEquipment(recipe.Instructions),
+	}
+
+	// Up to 3 points each for steps and time and 2 for equipment, on top
+	// of 1, stretched from 1-9 to the 1-10 scale
+	points := 1 +
+		math.Min(float64(effort.Steps)/3, 3) +
+		math.Min(float64(effort.ActiveMinutes)/20, 3) +
+		math.Min(float64(len(effort.Equipment))/2, 2)
+	score := 1 + (points-1)*(MaxEffort-1)/8
+
+	if difficulty != nil && difficulty.Ratings > 0 {
+		effort.Difficulty = difficulty
+		rated := 1 + (difficulty.Average-1)*(MaxEffort-1)/4
+		weight := math.Min(float64(difficulty.Ratings), 5) / 10
+		score = (1-weight)*score + weight*rated
+	}
+	effort.Score = min(max(int(math.Round(score)), MinEffort), MaxEffort)
+	return effort
+}
+
+// AddEffort scores each recipe, with the difficulty ratings of cooks
+// across the instance
+func AddEffort(ctx context.Context, db database.Database, recipes []*database.Recipe) error {
+	ids := make([]string, len(recipes))
+	for i, recipe := range recipes {
+		ids[i] = recipe.ID
+	}
+	difficulties, err := db.ListRecipeDifficulties(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, recipe := range recipes {
+		var difficulty *database.RecipeDifficulty
+		if d, ok := difficulties[recipe.ID]; ok {
+			difficulty = &d
+		}
+		recipe.Effort = Effort(recipe, difficulty)
+	}
+	return nil
+}
@@ -0,0 +1,373 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package recipes
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/authz"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/middleware"
+)
+
+// basicEquipment is kit every kitchen is taken to have, so recipes that
+// only need these can always be made
+var basicEquipment = []string{"pan", "pot", "baking dish", "sieve", "grater", "rolling pin"}
+
+// multiUse is equipment that does the job of other kit, e.g. an Instant
+// Pot is both a pressure cooker and a slow cooker
+var multiUse = map[string][]string{
+	"instant pot":       {"pressure cooker", "slow cooker"},
+	"multicooker":       {"pressure cooker", "slow cooker"},
+	"stand mixer":       {"mixer"},
+	"hand mixer":        {"mixer"},
+	"immersion blender": {"blender"},
+	"stick blender":     {"blender"},
+	"range":             {"oven"},
+	"toaster oven":      {"oven"},
+	"air fryer oven":    {"air fryer", "oven"},
+}
+
+// NormalizeEquipment spells equipment the way it is stored and compared:
+// kit recipes are known to need by its usual name, so "Crock-Pot" is a
+// slow cooker and "cast iron skillet" a pan, and anything else lowercase
+// with single spaces
+func NormalizeEquipment(name string) string {
+	name = strings.Join(strings.Fields(strings.ToLower(name)), " ")
+	if _, ok := multiUse[name]; ok || name == "" {
+		return name
+	}
+	// The longest mention wins, so a "microwave oven" is a microwave
+	best, longest := name, 0
+	for _, kit := range equipment {
+		if loc := kit.pattern.FindStringIndex(name); loc != nil && loc[1]-loc[0] > longest {
+			best, longest = kit.name, loc[1]-loc[0]
+		}
+	}
+	return best
+}
+
+// Kitchen is the equipment a user can cook with
+type Kitchen map[string]bool
+
+// NewKitchen makes a kitchen of the basics and the named equipment,
+// including the kit multi-use equipment stands in for
+func NewKitchen(names []string) Kitchen {
+	kitchen := Kitchen{}
+	for _, name := range basicEquipment {
+		kitchen[name] = true
+	}
+	for _, name := range names {
+		kitchen[name] = true
+		for _, provided := range multiUse[name] {
+			kitchen[provided] = true
+		}
+	}
+	return kitchen
+}
+
+// LoadKitchen reads the equipment of all the user's households
+func LoadKitchen(ctx context.Context, db database.Database, userID string) (Kitchen, error) {
+	households, err := db.ListHouseholdsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(households))
+	for i, household := range households {
+		ids[i] = household.ID
+	}
+	owned, err := db.ListHouseholdEquipment(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(owned))
+	for i, equipment := range owned {
+		names[i] = equipment.Name
+	}
+	return NewKitchen(names), nil
+}
+
+// Missing lists the required equipment the kitchen doesn't have
+func (k Kitchen) Missing(required []string) []string {
+	missing := []string{}
+	for _, name := range required {
+		if !k[name] {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// CanMake reports whether the kitchen has everything a recipe needs. The
+// recipe's Equipment must be set.
+func (k Kitchen) CanMake(recipe *database.Recipe) bool {
+	return len(k.Missing(recipe.Equipment)) == 0
+}
+
+// AddEquipment sets what each recipe needs: the equipment its owner tagged
+// it with, or else what its instructions mention
+func AddEquipment(ctx context.Context, db database.Database, recipes []*database.Recipe) error {
+	ids := make([]string, len(recipes))
+	for i, recipe := range recipes {
+		ids[i] = recipe.ID
+	}
+	tagged, err := db.ListRecipeEquipment(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, recipe := range recipes {
+		if names, ok := tagged[recipe.ID]; ok {
+			recipe.Equipment = names
+		} else {
+			recipe.Equipment = DetectEquipment(recipe.Instructions)
+		}
+	}
+	return nil
+}
+
+// filterCanMake keeps the recipes the user's kitchen has the equipment for
+func (h *Handler) filterCanMake(ctx context.Context, userID string, recipes []*database.Recipe) ([]*database.Recipe, error) {
+	kitchen, err := LoadKitchen(ctx, h.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	makeable := make([]*database.Recipe, 0, len(recipes))
+	for _, recipe := range recipes {
+		if kitchen.CanMake(recipe) {
+			makeable = append(makeable, recipe)
+		}
+	}
+	return makeable, nil
+}
+
+// RegisterHouseholdRoutes registers the equipment a household owns
+func (h *Handler) RegisterHouseholdRoutes(router *gin.RouterGroup) {
+	router.GET("/:id/equipment", h.ListHouseholdEquipment)
+	router.POST("/:id/equipment", h.CreateHouseholdEquipment)
+	router.DELETE("/:id/equipment/:equipment_id", h.DeleteHouseholdEquipment)
+}
+
+// householdMember responds with an error unless the user belongs to the
+// household in the path with a role that allows action
+func (h *Handler) householdMember(c *gin.Context, action authz.Action) (string, bool) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return "", false
+	}
+	if _, err := authz.Household(c.Request.Context(), h.db, c.Param("id"), user.ID, action); err != nil {
+		apierror.Lookup(c, err, "household not found")
+		return "", false
+	}
+	return user.ID, true
+}
+
+// ListHouseholdEquipment lists the equipment a household owns
+// @Summary List household equipment
+// @Tags households
+// @Produce json
+// @Param id path string true "Household ID"
+// @Success 200 {array} database.HouseholdEquipment
+// @Router /households/{id}/equipment [get]
+func (h *Handler) ListHouseholdEquipment(c *gin.Context) {
+	if _, ok := h.householdMember(c, authz.ViewHousehold); !ok {
+		return
+	}
+
+	equipment, err := h.db.ListHouseholdEquipment(c.Request.Context(), []string{c.Param("id")})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, equipment)
+}
+
+// householdEquipmentRequest adds equipment to a household
+type householdEquipmentRequest struct {
+	Name  string `json:"name" binding:"required,max=100"`
+	Notes string `json:"notes" binding:"max=500"`
+}
+
+// CreateHouseholdEquipment adds a piece of equipment to a household
+// @Summary Add household equipment
+// @Tags households
+// @Accept json
+// @Produce json
+// @Param id path string true "Household ID"
+// @Success 201 {object} database.HouseholdEquipment
+// @Router /households/{id}/equipment [post]
+func (h *Handler) CreateHouseholdEquipment(c *gin.Context) {
+	userID, ok := h.householdMember(c, authz.EditSettings)
+	if !ok {
+		return
+	}
+
+	var req householdEquipmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+	name := NormalizeEquipment(req.Name)
+	if name == "" {
+		apierror.BadRequest(c, "name is required")
+		return
+	}
+
+	equipment := &database.HouseholdEquipment{
+		ID:          uuid.New().String(),
+		HouseholdID: c.Param("id"),
+		Name:        name,
+		Notes:       strings.TrimSpace(req.Notes),
+		CreatedBy:   &userID,
+		CreatedAt:   time.Now(),
+	}
+	if err := h.db.CreateHouseholdEquipment(c.Request.Context(), equipment); err != nil {
+		if apierror.IsConflict(err) {
+			apierror.Conflict(c, "the household already has a "+name)
+			return
+		}
+		apierror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, equipment)
+}
+
+// DeleteHouseholdEquipment removes a piece of equipment from a household
+// @Summary Remove household equipment
+// @Tags households
+// @Param id path string true "Household ID"
+// @Param equipment_id path string true "Equipment ID"
+// @Success 204
+// @Router /households/{id}/equipment/{equipment_id} [delete]
+func (h *Handler) DeleteHouseholdEquipment(c *gin.Context) {
+	if _, ok := h.householdMember(c, authz.EditSettings); !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	equipment, err := h.db.GetHouseholdEquipmentByID(ctx, c.Param("equipment_id"))
+	if err != nil {
+		apierror.Lookup(c, err, "equipment not found")
+		return
+	}
+	if equipment.HouseholdID != c.Param("id") {
+		apierror.NotFound(c, "equipment not found")
+		return
+	}
+	if err := h.db.DeleteHouseholdEquipment(ctx, equipment.ID); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RecipeEquipment is what a recipe needs and which of it the viewer lacks
+type RecipeEquipment struct {
+	Required []string `json:"required"`
+	Tagged   bool     `json:"tagged"`  // set by the recipe's owner rather than found in the instructions
+	Missing  []string `json:"missing"` // not in any of the viewer's households
+	CanMake  bool     `json:"can_make"`
+}
+
+// GetEquipment returns the equipment a recipe needs, checked against the
+// viewer's kitchen
+// @Summary Recipe equipment
+// @Tags recipes
+// @Produce json
+// @Param id path string true "Recipe ID"
+// @Success 200 {object} RecipeEquipment
+// @Router /recipes/{id}/equipment [get]
+func (h *Handler) GetEquipment(c *gin.Context) {
+	recipe, userID := h.viewRecipe(c)
+	if recipe == nil {
+		return
+	}
+
+	ctx := c.Request.Context()
+	tagged, err := h.db.ListRecipeEquipment(ctx, []string{recipe.ID})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	if err := AddEquipment(ctx, h.db, []*database.Recipe{recipe}); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	kitchen, err := LoadKitchen(ctx, h.db, userID)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	missing := kitchen.Missing(recipe.Equipment)
+	c.JSON(http.StatusOK, RecipeEquipment{
+		Required: recipe.Equipment,
+		Tagged:   len(tagged[recipe.ID]) > 0,
+		Missing:  missing,
+		CanMake:  len(missing) == 0,
+	})
+}
+
+// recipeEquipmentRequest replaces what a recipe is tagged as needing; an
+// empty list goes back to what the instructions mention
+type recipeEquipmentRequest struct {
+	Equipment []string `json:"equipment" binding:"max=20,dive,max=100"`
+}
+
+// UpdateEquipment tags a recipe with the equipment it needs
+// @Summary Tag recipe equipment
+// @Tags recipes
+// @Accept json
+// @Produce json
+// @Param id path string true "Recipe ID"
+// @Success 200 {object} RecipeEquipment
+// @Router /recipes/{id}/equipment [put]
+func (h *Handler) UpdateEquipment(c *gin.Context) {
+	recipe := h.ownRecipe(c)
+	if recipe == nil {
+		return
+	}
+
+	var req recipeEquipmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+	seen := map[string]bool{}
+	names := []string{}
+	for _, name := range req.Equipment {
+		if name = NormalizeEquipment(name); name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	if err := h.db.SetRecipeEquipment(c.Request.Context(), recipe.ID, names); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	h.GetEquipment(c)
+}
@@ -19,22 +19,73 @@
 package recipes
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/api/params"
+	"github.com/rghsoftware/space-food/internal/api/precondition"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/audit"
+	"github.com/rghsoftware/space-food/internal/authz"
+	"github.com/rghsoftware/space-food/internal/config"
 	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/dietary"
+	"github.com/rghsoftware/space-food/internal/features/images"
+	"github.com/rghsoftware/space-food/internal/features/mealtime"
+	"github.com/rghsoftware/space-food/internal/features/sensory"
+	"github.com/rghsoftware/space-food/internal/fetch"
 	"github.com/rghsoftware/space-food/internal/middleware"
+	"github.com/rghsoftware/space-food/internal/storage"
+	"github.com/rghsoftware/space-food/pkg/logger"
 )
 
+// ChangeHook is called after a recipe is updated or deleted
+type ChangeHook func(ctx context.Context, recipeID string)
+
 // Handler handles recipe HTTP requests
 type Handler struct {
-	db database.Database
+	db            database.Database
+	store         storage.Provider
+	maxUploadSize int64
+	scraper       *Scraper
+	downloader    *images.Downloader // nil when storage.importimages is off
+	publicURL     string
+	onChange      []ChangeHook
 }
 
 // NewHandler creates a new recipe handler
-func NewHandler(db database.Database) *Handler {
-	return &Handler{
-		db: db,
+func NewHandler(cfg *config.Config, db database.Database, store storage.Provider) *Handler {
+	h := &Handler{
+		db:            db,
+		store:         store,
+		maxUploadSize: int64(max(cfg.Storage.MaxUploadSize, 1)) << 20,
+		scraper:       NewScraper(cfg),
+		publicURL:     strings.TrimRight(cfg.Server.PublicURL, "/"),
+	}
+	if cfg.Storage.ImportImages {
+		h.downloader = images.NewDownloader(cfg, store)
+	}
+	return h
+}
+
+// OnChange registers a hook to run whenever a recipe is created, edited or
+// removed, e.g. to drop cached data derived from it
+func (h *Handler) OnChange(hook ChangeHook) {
+	h.onChange = append(h.onChange, hook)
+}
+
+// notifyChange runs the registered change hooks
+func (h *Handler) notifyChange(ctx context.Context, recipeID string) {
+	for _, hook := range h.onChange {
+		hook(ctx, recipeID)
 	}
 }
 
@@ -45,35 +96,173 @@ func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
 	router.POST("", h.CreateRecipe)
 	router.PUT("/:id", h.UpdateRecipe)
 	router.DELETE("/:id", h.DeleteRecipe)
+	router.PUT("/:id/image", h.UploadImage)
+	router.DELETE("/:id/image", h.DeleteImage)
+	router.GET("/:id/history", h.GetHistory)
+	router.GET("/:id/history/:revision", h.GetRevision)
+	router.POST("/:id/history/:revision/restore", h.RestoreRevision)
+	router.GET("/:id/notes", h.GetNotes)
+	router.PUT("/:id/notes", h.UpdateNotes)
+	router.DELETE("/:id/notes", h.DeleteNotes)
+	router.GET("/:id/rating", h.GetRating)
+	router.PUT("/:id/rating", h.UpdateRating)
+	router.DELETE("/:id/rating", h.DeleteRating)
+	router.GET("/:id/print", h.GetPrint)
+	router.GET("/:id/cook-mode", h.GetCookMode)
+	router.GET("/:id/equipment", h.GetEquipment)
+	router.PUT("/:id/equipment", h.UpdateEquipment)
+	router.GET("/:id/sensory", h.GetSensory)
+	router.PUT("/:id/sensory", h.UpdateSensory)
+	router.GET("/:id/shares", h.ListShares)
+	router.POST("/:id/shares", h.CreateShare)
+	router.DELETE("/:id/shares/:share", h.DeleteShare)
 	router.GET("/search", h.SearchRecipes)
 }
 
+// RegisterImportRoutes registers importing recipes from other sites, under
+// its own group so it can be rate limited separately
+func (h *Handler) RegisterImportRoutes(router *gin.RouterGroup) {
+	router.POST("", h.ImportRecipe)
+	router.POST("/shared", h.ImportShared)
+}
+
+// RegisterShareRoutes registers the public, token-authenticated shared
+// recipe route
+func (h *Handler) RegisterShareRoutes(router *gin.RouterGroup) {
+	router.GET("/:token", h.GetShared)
+}
+
 // ListRecipes lists all recipes for the authenticated user
 // @Summary List recipes
 // @Tags recipes
 // @Produce json
+// @Param for_now query bool false "Only recipes suited to the current meal window"
+// @Param exclude_conflicts query bool false "Hide recipes that clash with my dietary restrictions"
+// @Param can_make query bool false "Only recipes my households have the equipment for"
+// @Param max_effort query int false "Only recipes with an effort score up to this (1-10)"
+// @Param max_spice query int false "Only recipes up to this spice level, 0 (not spicy) to 3 (hot)"
+// @Param sensory_safe query bool false "Only recipes that suit my sensory profile"
+// @Param not_made_days query int false "Only recipes my households haven't made in this many days"
+// @Param sort query string false "newest (default), or rating for my households' favourites first within the page"
+// @Param limit query int false "Maximum recipes to return (1-200, default 50)"
+// @Param offset query int false "Recipes to skip"
+// @Param cursor query string false "Keyset paging: empty for the first page, then next_cursor"
+// @Param lang query string false "Include translations into this language (default: my locale)"
 // @Success 200 {array} Recipe
 // @Router /recipes [get]
 func (h *Handler) ListRecipes(c *gin.Context) {
 	user, ok := middleware.GetUserFromContext(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	query := params.Query(c)
+	page := query.Page(50)
+	forNow := query.Bool("for_now")
+	excludeConflicts := query.Bool("exclude_conflicts")
+	maxEffort := query.Int("max_effort", MaxEffort, MinEffort, MaxEffort)
+	canMake := query.Bool("can_make")
+	maxSpice := query.Int("max_spice", sensory.SpiceHot, sensory.SpiceNone, sensory.SpiceHot)
+	sensorySafe := query.Bool("sensory_safe")
+	sortBy := query.String("sort", "newest", "rating")
+	notMadeDays := query.Int("not_made_days", 0, 1, 3650)
+	language := h.viewLanguage(c, query, user.ID)
+	if !query.Valid() {
 		return
 	}
 
 	filter := database.RecipeFilter{
 		UserID: user.ID,
-		Limit:  50,
-		Offset: 0,
+		After:  page.After,
+		Limit:  page.Fetch(),
+		Offset: page.Offset,
 	}
 
 	recipes, err := h.db.ListRecipes(c.Request.Context(), filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Respond(c, err)
 		return
 	}
+	recipes, next := params.Trim(recipes, page, func(r *database.Recipe) database.Cursor {
+		return database.Cursor{Time: r.CreatedAt, ID: r.ID}
+	})
+
+	// Respect the user's meal windows (no dinner recipes at 8am)
+	if forNow {
+		prefs := mealtime.LoadPreferences(c, h.db, user.ID)
+		recipes = mealtime.FilterRecipes(recipes, mealtime.AllowedMealTypes(prefs, time.Now()))
+	}
 
-	c.JSON(http.StatusOK, recipes)
+	if excludeConflicts {
+		restrictions, err := h.db.ListDietaryRestrictions(c.Request.Context(), []string{user.ID})
+		if err != nil {
+			apierror.Respond(c, err)
+			return
+		}
+		safe := make([]*database.Recipe, 0, len(recipes))
+		for _, recipe := range recipes {
+			if !dietary.HasConflicts(recipe, restrictions) {
+				safe = append(safe, recipe)
+			}
+		}
+		recipes = safe
+	}
+
+	if err := AddEffort(c.Request.Context(), h.db, recipes); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	if maxEffort < MaxEffort {
+		easier := make([]*database.Recipe, 0, len(recipes))
+		for _, recipe := range recipes {
+			if recipe.Effort.Score <= maxEffort {
+				easier = append(easier, recipe)
+			}
+		}
+		recipes = easier
+	}
+
+	if err := AddEquipment(c.Request.Context(), h.db, recipes); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	if canMake {
+		recipes, err = h.filterCanMake(c.Request.Context(), user.ID, recipes)
+		if err != nil {
+			apierror.Respond(c, err)
+			return
+		}
+	}
+
+	if _, err := AddSensory(c.Request.Context(), h.db, recipes); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	recipes = h.filterSensory(c.Request.Context(), user.ID, recipes, maxSpice, sensorySafe)
+
+	if _, err := AddRatings(c.Request.Context(), h.db, user.ID, recipes); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	if notMadeDays > 0 {
+		recipes = NotMadeSince(recipes, time.Now().AddDate(0, 0, -notMadeDays))
+	}
+	if sortBy == "rating" {
+		SortByRating(recipes)
+	}
+	if _, err := AddTranslations(c.Request.Context(), h.db, language, recipes); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	if err := dietary.AddTags(c.Request.Context(), h.db, recipes); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	// Filtered keyset pages can come up short; next_cursor still continues
+	// after the last recipe fetched
+	params.WriteList(c, recipes, page, next, nil)
 }
 
 // GetRecipe retrieves a single recipe by ID
@@ -81,17 +270,65 @@ func (h *Handler) ListRecipes(c *gin.Context) {
 // @Tags recipes
 // @Produce json
 // @Param id path string true "Recipe ID"
+// @Param lang query string false "Include the translation into this language (default: my locale)"
 // @Success 200 {object} Recipe
 // @Router /recipes/{id} [get]
 func (h *Handler) GetRecipe(c *gin.Context) {
-	id := c.Param("id")
+	user, _ := middleware.GetUserFromContext(c)
+	query := params.Query(c)
+	language := h.viewLanguage(c, query, user.ID)
+	if !query.Valid() {
+		return
+	}
 
-	recipe, err := h.db.GetRecipeByID(c.Request.Context(), id)
+	// Includes the viewer's own notes, which change independently of the recipe
+	recipe := h.recipeWithNotes(c)
+	if recipe == nil {
+		return
+	}
+	if err := AddEffort(c.Request.Context(), h.db, []*database.Recipe{recipe}); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	if err := AddEquipment(c.Request.Context(), h.db, []*database.Recipe{recipe}); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	changed, err := AddRatings(c.Request.Context(), h.db, user.ID, []*database.Recipe{recipe})
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "recipe not found"})
+		apierror.Respond(c, err)
 		return
 	}
-
+	tagged, err := AddSensory(c.Request.Context(), h.db, []*database.Recipe{recipe})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	if tagged.After(changed) {
+		changed = tagged
+	}
+	translated, err := AddTranslations(c.Request.Context(), h.db, language, []*database.Recipe{recipe})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	if translated.After(changed) {
+		changed = translated
+	}
+	if err := dietary.AddTags(c.Request.Context(), h.db, []*database.Recipe{recipe}); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	for _, tag := range recipe.DietaryTags {
+		if tag.UpdatedAt.After(changed) {
+			changed = tag.UpdatedAt
+		}
+	}
+	// Ratings, cooks, sensory tags, translations and dietary tag overrides
+	// change independently of the recipe too
+	if modified, err := http.ParseTime(c.Writer.Header().Get("Last-Modified")); err == nil && changed.After(modified) {
+		middleware.SetLastModified(c, changed)
+	}
 	c.JSON(http.StatusOK, recipe)
 }
 
@@ -106,22 +343,128 @@ func (h *Handler) GetRecipe(c *gin.Context) {
 func (h *Handler) CreateRecipe(c *gin.Context) {
 	user, ok := middleware.GetUserFromContext(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		apierror.Unauthorized(c, "unauthorized")
 		return
 	}
 
 	var recipe database.Recipe
 	if err := c.ShouldBindJSON(&recipe); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Invalid(c, err)
 		return
 	}
 
 	recipe.UserID = user.ID
 
 	if err := h.db.CreateRecipe(c.Request.Context(), &recipe); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Respond(c, err)
+		return
+	}
+	h.notifyChange(c.Request.Context(), recipe.ID)
+
+	c.JSON(http.StatusCreated, recipe)
+}
+
+// importRequest is the body of POST /recipes/import
+type importRequest struct {
+	URL string `json:"url" binding:"required"`
+}
+
+// ImportRecipe creates a recipe from the recipe data on a web page. Its
+// image is copied into storage unless storage.importimages is off.
+// @Summary Import recipe from URL
+// @Tags recipes
+// @Accept json
+// @Produce json
+// @Param request body importRequest true "Page to import"
+// @Success 201 {object} Recipe
+// @Router /recipes/import [post]
+func (h *Handler) ImportRecipe(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req importRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	recipe, err := h.scraper.Scrape(c.Request.Context(), req.URL)
+	h.recordImport(c.Request.Context(), req.URL, err)
+	if err != nil {
+		respondImportError(c, err)
+		return
+	}
+	h.saveImported(c, user.ID, recipe)
+}
+
+// respondImportError maps a failed import fetch to an API error
+func respondImportError(c *gin.Context, err error) {
+	var statusErr *fetch.StatusError
+	switch {
+	case errors.Is(err, fetch.ErrInvalidURL):
+		apierror.BadRequest(c, err.Error())
+	case errors.Is(err, fetch.ErrBlocked):
+		apierror.Forbidden(c, "recipes can't be imported from that address")
+	case errors.Is(err, fetch.ErrContentType):
+		apierror.BadRequest(c, "that address is not a web page")
+	case errors.Is(err, fetch.ErrTooLarge):
+		apierror.Upstream(c, "that page is too large to import")
+	case errors.Is(err, ErrNoRecipe):
+		apierror.BadRequest(c, err.Error())
+	case errors.As(err, &statusErr) && (statusErr.Code == http.StatusUnauthorized ||
+		statusErr.Code == http.StatusPaymentRequired || statusErr.Code == http.StatusForbidden):
+		apierror.Upstream(c, "that site refused to share the page; it may need a subscription or block automated access")
+	default:
+		apierror.Upstream(c, err.Error())
+	}
+}
+
+// recordImport notes which site an import fetched from and whether a
+// recipe came of it, for the admin dashboard. Addresses that were never
+// fetched are left out, and so is everything but the site's domain.
+func (h *Handler) recordImport(ctx context.Context, rawURL string, err error) {
+	if errors.Is(err, fetch.ErrInvalidURL) || errors.Is(err, fetch.ErrBlocked) {
+		return
+	}
+	u, parseErr := url.Parse(rawURL)
+	if parseErr != nil || u.Hostname() == "" {
 		return
 	}
+	attempt := database.RecipeImportAttempt{
+		ID:        uuid.New().String(),
+		Domain:    strings.TrimPrefix(strings.ToLower(u.Hostname()), "www."),
+		Succeeded: err == nil,
+		CreatedAt: time.Now(),
+	}
+	if err := h.db.RecordRecipeImport(ctx, &attempt); err != nil {
+		logger.Ctx(ctx).Warn().Err(err).Str("domain", attempt.Domain).Msg("Failed to record recipe import")
+	}
+}
+
+// saveImported stores an imported recipe for a user, first copying its
+// image into storage unless storage.importimages is off
+func (h *Handler) saveImported(c *gin.Context, userID string, recipe *database.Recipe) {
+	ctx := c.Request.Context()
+	recipe.UserID = userID
+	if h.downloader != nil && images.IsRemote(recipe.ImageURL) {
+		imageURL, err := h.downloader.Download(ctx, userID, recipe.ImageURL)
+		if err != nil {
+			// Better no picture than a hotlinked one
+			logger.Ctx(ctx).Warn().Err(err).Str("source_url", recipe.SourceURL).Msg("Failed to copy imported recipe image")
+			imageURL = ""
+		}
+		recipe.ImageURL = imageURL
+	}
+
+	if err := h.db.CreateRecipe(ctx, recipe); err != nil {
+		h.removeImage(ctx, recipe.ImageURL)
+		apierror.Respond(c, err)
+		return
+	}
+	h.notifyChange(ctx, recipe.ID)
 
 	c.JSON(http.StatusCreated, recipe)
 }
@@ -138,7 +481,7 @@ func (h *Handler) CreateRecipe(c *gin.Context) {
 func (h *Handler) UpdateRecipe(c *gin.Context) {
 	user, ok := middleware.GetUserFromContext(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		apierror.Unauthorized(c, "unauthorized")
 		return
 	}
 
@@ -147,28 +490,63 @@ func (h *Handler) UpdateRecipe(c *gin.Context) {
 	// Verify ownership
 	existing, err := h.db.GetRecipeByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "recipe not found"})
+		apierror.Lookup(c, err, "recipe not found")
 		return
 	}
 
-	if existing.UserID != user.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+	if !authz.CanEditRecipe(user.ID, existing) {
+		apierror.Forbidden(c, "forbidden")
 		return
 	}
 
 	var recipe database.Recipe
 	if err := c.ShouldBindJSON(&recipe); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Invalid(c, err)
+		return
+	}
+
+	// Refuse to overwrite changes saved since the client loaded the recipe
+	if !precondition.Check(c, existing.UpdatedAt, recipe.UpdatedAt, func() any {
+		return diffContent(contentOf(existing), contentOf(&recipe))
+	}) {
 		return
 	}
 
 	recipe.ID = id
 	recipe.UserID = user.ID
-
-	if err := h.db.UpdateRecipe(c.Request.Context(), &recipe); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	recipe.CreatedAt = existing.CreatedAt
+	recipe.UpdatedAt = time.Now()
+
+	err = h.db.WithTx(c.Request.Context(), func(ctx context.Context) error {
+		updated, err := h.db.UpdateRecipeIfUnchanged(ctx, &recipe, existing.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return precondition.ErrChanged
+		}
+		return h.recordRevision(ctx, existing, &recipe, user.ID, nil)
+	})
+	if errors.Is(err, precondition.ErrChanged) {
+		// Saved by someone else since the check above
+		current, err := h.db.GetRecipeByID(c.Request.Context(), id)
+		if err != nil {
+			apierror.Lookup(c, err, "recipe not found")
+			return
+		}
+		precondition.Refuse(c, current.UpdatedAt, func() any {
+			return diffContent(contentOf(current), contentOf(&recipe))
+		})
 		return
 	}
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	h.notifyChange(c.Request.Context(), id)
+	if recipe.ImageURL != existing.ImageURL {
+		h.removeImage(c.Request.Context(), existing.ImageURL)
+	}
 
 	c.JSON(http.StatusOK, recipe)
 }
@@ -182,7 +560,7 @@ func (h *Handler) UpdateRecipe(c *gin.Context) {
 func (h *Handler) DeleteRecipe(c *gin.Context) {
 	user, ok := middleware.GetUserFromContext(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		apierror.Unauthorized(c, "unauthorized")
 		return
 	}
 
@@ -191,42 +569,215 @@ func (h *Handler) DeleteRecipe(c *gin.Context) {
 	// Verify ownership
 	existing, err := h.db.GetRecipeByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "recipe not found"})
+		apierror.Lookup(c, err, "recipe not found")
 		return
 	}
 
-	if existing.UserID != user.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+	if !authz.CanEditRecipe(user.ID, existing) {
+		apierror.Forbidden(c, "forbidden")
 		return
 	}
 
 	if err := h.db.DeleteRecipe(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Respond(c, err)
+		return
+	}
+	audit.Record(c, audit.Entry{
+		Action:     audit.RecipeDeleted,
+		TargetType: "recipe",
+		TargetID:   id,
+		Details:    map[string]any{"title": existing.Title},
+	})
+	h.notifyChange(c.Request.Context(), id)
+	h.removeImage(c.Request.Context(), existing.ImageURL)
+
+	c.Status(http.StatusNoContent)
+}
+
+// UploadImage replaces a recipe's image with the uploaded one, stored in
+// several sizes with its metadata removed
+// @Summary Upload recipe image
+// @Tags recipes
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Recipe ID"
+// @Param image formData file true "JPEG, PNG or GIF image"
+// @Success 200 {object} Recipe
+// @Router /recipes/{id}/image [put]
+func (h *Handler) UploadImage(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	recipe, err := h.db.GetRecipeByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierror.Lookup(c, err, "recipe not found")
+		return
+	}
+	if !authz.CanEditRecipe(user.ID, recipe) {
+		apierror.Forbidden(c, "forbidden")
+		return
+	}
+
+	// Leave room for the multipart framing around the file
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadSize+64<<10)
+	header, err := c.FormFile("image")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			apierror.Abort(c, apierror.New(http.StatusRequestEntityTooLarge, apierror.CodePayloadTooLarge,
+				fmt.Sprintf("images can be at most %d MB", h.maxUploadSize>>20)))
+			return
+		}
+		apierror.BadRequest(c, "an image file is required in the image field")
+		return
+	}
+	if header.Size > h.maxUploadSize {
+		apierror.Abort(c, apierror.New(http.StatusRequestEntityTooLarge, apierror.CodePayloadTooLarge,
+			fmt.Sprintf("images can be at most %d MB", h.maxUploadSize>>20)))
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	data, err := io.ReadAll(file)
+	file.Close()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	imageURL, err := images.Save(c.Request.Context(), h.store, user.ID, data)
+	if errors.Is(err, images.ErrUnsupported) {
+		apierror.Abort(c, apierror.New(http.StatusUnsupportedMediaType, apierror.CodeUnsupportedMedia, err.Error()))
+		return
+	}
+	if errors.Is(err, images.ErrTooLarge) {
+		apierror.Abort(c, apierror.New(http.StatusRequestEntityTooLarge, apierror.CodePayloadTooLarge, err.Error()))
+		return
+	}
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	previous := recipe.ImageURL
+	recipe.ImageURL = imageURL
+	recipe.UpdatedAt = time.Now()
+	if err := h.db.UpdateRecipe(c.Request.Context(), recipe); err != nil {
+		h.removeImage(c.Request.Context(), imageURL)
+		apierror.Respond(c, err)
+		return
+	}
+	h.removeImage(c.Request.Context(), previous)
+
+	c.JSON(http.StatusOK, recipe)
+}
+
+// DeleteImage removes a recipe's image
+// @Summary Delete recipe image
+// @Tags recipes
+// @Param id path string true "Recipe ID"
+// @Success 204
+// @Router /recipes/{id}/image [delete]
+func (h *Handler) DeleteImage(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
 		return
 	}
 
+	recipe, err := h.db.GetRecipeByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierror.Lookup(c, err, "recipe not found")
+		return
+	}
+	if !authz.CanEditRecipe(user.ID, recipe) {
+		apierror.Forbidden(c, "forbidden")
+		return
+	}
+
+	previous := recipe.ImageURL
+	recipe.ImageURL = ""
+	recipe.UpdatedAt = time.Now()
+	if err := h.db.UpdateRecipe(c.Request.Context(), recipe); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	h.removeImage(c.Request.Context(), previous)
+
 	c.Status(http.StatusNoContent)
 }
 
+// removeImage deletes a stored image no recipe points at any more. Failures
+// only leave an orphaned file, so they are logged rather than returned.
+func (h *Handler) removeImage(ctx context.Context, imageURL string) {
+	if err := images.Remove(ctx, h.store, imageURL); err != nil {
+		logger.Ctx(ctx).Warn().Err(err).Str("image_url", imageURL).Msg("Failed to remove recipe image")
+	}
+}
+
 // SearchRecipes searches recipes
 // @Summary Search recipes
 // @Tags recipes
 // @Produce json
 // @Param q query string true "Search query"
+// @Param can_make query bool false "Only recipes my households have the equipment for"
+// @Param max_spice query int false "Only recipes up to this spice level, 0 (not spicy) to 3 (hot)"
+// @Param sensory_safe query bool false "Only recipes that suit my sensory profile"
 // @Success 200 {array} Recipe
 // @Router /recipes/search [get]
 func (h *Handler) SearchRecipes(c *gin.Context) {
 	query := c.Query("q")
 	if query == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "query parameter required"})
+		apierror.BadRequest(c, "query parameter required")
+		return
+	}
+	filters := params.Query(c)
+	canMake := filters.Bool("can_make")
+	maxSpice := filters.Int("max_spice", sensory.SpiceHot, sensory.SpiceNone, sensory.SpiceHot)
+	sensorySafe := filters.Bool("sensory_safe")
+	if !filters.Valid() {
 		return
 	}
 
 	recipes, err := h.db.SearchRecipes(c.Request.Context(), query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Respond(c, err)
 		return
 	}
+	if err := AddEffort(c.Request.Context(), h.db, recipes); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	if err := AddEquipment(c.Request.Context(), h.db, recipes); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	if canMake {
+		user, ok := middleware.GetUserFromContext(c)
+		if !ok {
+			apierror.Unauthorized(c, "unauthorized")
+			return
+		}
+		recipes, err = h.filterCanMake(c.Request.Context(), user.ID, recipes)
+		if err != nil {
+			apierror.Respond(c, err)
+			return
+		}
+	}
+	if _, err := AddSensory(c.Request.Context(), h.db, recipes); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	if maxSpice < sensory.SpiceHot || sensorySafe {
+		user, _ := middleware.GetUserFromContext(c)
+		recipes = h.filterSensory(c.Request.Context(), user.ID, recipes, maxSpice, sensorySafe)
+	}
 
 	c.JSON(http.StatusOK, recipes)
 }
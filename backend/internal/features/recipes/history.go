@@ -0,0 +1,380 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package recipes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/authz"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/middleware"
+)
+
+// revisionContent is the part of a recipe kept in its history. The image
+// and rating are not versioned: images are deleted when replaced, and a
+// rollback keeps the current ones.
+type revisionContent struct {
+	Title         string
+	Description   string
+	Instructions  string
+	PrepTime      int
+	CookTime      int
+	Servings      int
+	Difficulty    string
+	Categories    []string
+	Tags          []string
+	Ingredients   []revisionIngredient
+	NutritionInfo *database.NutritionInfo
+	Source        string
+	SourceURL     string
+}
+
+// revisionIngredient is an ingredient without its row IDs
+type revisionIngredient struct {
+	Name     string
+	Quantity float64
+	Unit     string
+	Notes    string
+	Optional bool
+}
+
+// FieldChange is one field that differs between two revisions
+type FieldChange struct {
+	Field  string          `json:"field"`
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// RevisionView is a revision as the history endpoints return it, with
+// what changed since the revision before
+type RevisionView struct {
+	Revision     int             `json:"revision"`
+	UserID       string          `json:"user_id"`
+	RestoredFrom *int            `json:"restored_from,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+	Changes      []FieldChange   `json:"changes"`
+	Recipe       json.RawMessage `json:"recipe,omitempty"`
+}
+
+// contentOf extracts the versioned fields of a recipe
+func contentOf(recipe *database.Recipe) revisionContent {
+	content := revisionContent{
+		Title:         recipe.Title,
+		Description:   recipe.Description,
+		Instructions:  recipe.Instructions,
+		PrepTime:      recipe.PrepTime,
+		CookTime:      recipe.CookTime,
+		Servings:      recipe.Servings,
+		Difficulty:    recipe.Difficulty,
+		Categories:    recipe.Categories,
+		Tags:          recipe.Tags,
+		NutritionInfo: recipe.NutritionInfo,
+		Source:        recipe.Source,
+		SourceURL:     recipe.SourceURL,
+	}
+	// An empty list and no list are the same edit
+	if len(content.Categories) == 0 {
+		content.Categories = nil
+	}
+	if len(content.Tags) == 0 {
+		content.Tags = nil
+	}
+	for _, ing := range recipe.Ingredients {
+		content.Ingredients = append(content.Ingredients, revisionIngredient{
+			Name: ing.Name, Quantity: ing.Quantity, Unit: ing.Unit, Notes: ing.Notes, Optional: ing.Optional,
+		})
+	}
+	return content
+}
+
+// applyTo writes the versioned fields onto a recipe
+func (content revisionContent) applyTo(recipe *database.Recipe) {
+	recipe.Title = content.Title
+	recipe.Description = content.Description
+	recipe.Instructions = content.Instructions
+	recipe.PrepTime = content.PrepTime
+	recipe.CookTime = content.CookTime
+	recipe.Servings = content.Servings
+	recipe.Difficulty = content.Difficulty
+	recipe.Categories = content.Categories
+	recipe.Tags = content.Tags
+	recipe.NutritionInfo = content.NutritionInfo
+	recipe.Source = content.Source
+	recipe.SourceURL = content.SourceURL
+	recipe.Ingredients = make([]database.Ingredient, 0, len(content.Ingredients))
+	for i, ing := range content.Ingredients {
+		recipe.Ingredients = append(recipe.Ingredients, database.Ingredient{
+			RecipeID: recipe.ID, Name: ing.Name, Quantity: ing.Quantity, Unit: ing.Unit,
+			Notes: ing.Notes, Optional: ing.Optional, Order: i,
+		})
+	}
+}
+
+// diffContent lists the fields whose values differ, in field order
+func diffContent(before, after revisionContent) []FieldChange {
+	changes := []FieldChange{}
+	b, a := reflect.ValueOf(before), reflect.ValueOf(after)
+	for i := 0; i < b.NumField(); i++ {
+		old, _ := json.Marshal(b.Field(i).Interface())
+		updated, _ := json.Marshal(a.Field(i).Interface())
+		if !bytes.Equal(old, updated) {
+			changes = append(changes, FieldChange{Field: b.Type().Field(i).Name, Before: old, After: updated})
+		}
+	}
+	return changes
+}
+
+// recordRevision saves a recipe's new content in its history. A recipe
+// edited for the first time also gets its previous content saved as the
+// first revision. Saves that change no versioned field are not recorded.
+func (h *Handler) recordRevision(ctx context.Context, before, after *database.Recipe, userID string, restoredFrom *int) error {
+	latest, err := h.db.ListRecipeRevisions(ctx, after.ID, 1)
+	if err != nil {
+		return err
+	}
+
+	if len(latest) == 0 {
+		createdAt := before.UpdatedAt
+		if createdAt.IsZero() {
+			createdAt = before.CreatedAt
+		}
+		if err := h.saveRevision(ctx, before.ID, before.UserID, contentOf(before), nil, createdAt); err != nil {
+			return err
+		}
+	}
+
+	content := contentOf(after)
+	if len(latest) > 0 {
+		var previous revisionContent
+		if json.Unmarshal(latest[0].Content, &previous) == nil && len(diffContent(previous, content)) == 0 {
+			return nil
+		}
+	} else if len(diffContent(contentOf(before), content)) == 0 {
+		return nil
+	}
+	return h.saveRevision(ctx, after.ID, userID, content, restoredFrom, time.Now())
+}
+
+func (h *Handler) saveRevision(ctx context.Context, recipeID, userID string, content revisionContent, restoredFrom *int, at time.Time) error {
+	data, err := json.Marshal(content)
+	if err != nil {
+		return err
+	}
+	return h.db.CreateRecipeRevision(ctx, &database.RecipeRevision{
+		ID:           uuid.New().String(),
+		RecipeID:     recipeID,
+		UserID:       userID,
+		RestoredFrom: restoredFrom,
+		Content:      data,
+		CreatedAt:    at,
+	})
+}
+
+// ownRecipe loads a recipe the caller owns, responding and returning nil
+// otherwise
+func (h *Handler) ownRecipe(c *gin.Context) *database.Recipe {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return nil
+	}
+	recipe, err := h.db.GetRecipeByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierror.Lookup(c, err, "recipe not found")
+		return nil
+	}
+	if !authz.CanEditRecipe(user.ID, recipe) {
+		apierror.Forbidden(c, "forbidden")
+		return nil
+	}
+	return recipe
+}
+
+// revisionParam reads the :revision path parameter
+func revisionParam(c *gin.Context) (int, bool) {
+	n, err := strconv.Atoi(c.Param("revision"))
+	if err != nil || n < 1 {
+		apierror.BadRequest(c, "revision must be a positive number")
+		return 0, false
+	}
+	return n, true
+}
+
+// GetHistory lists a recipe's revisions, newest first, each with the
+// fields it changed
+// @Summary Recipe history
+// @Tags recipes
+// @Produce json
+// @Param id path string true "Recipe ID"
+// @Success 200 {array} RevisionView
+// @Router /recipes/{id}/history [get]
+func (h *Handler) GetHistory(c *gin.Context) {
+	recipe := h.ownRecipe(c)
+	if recipe == nil {
+		return
+	}
+
+	revisions, err := h.db.ListRecipeRevisions(c.Request.Context(), recipe.ID, 0)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	views := make([]RevisionView, 0, len(revisions))
+	for i, revision := range revisions {
+		var older *database.RecipeRevision
+		if i+1 < len(revisions) {
+			older = revisions[i+1]
+		}
+		view, err := revisionView(revision, older)
+		if err != nil {
+			apierror.Internal(c, err)
+			return
+		}
+		views = append(views, view)
+	}
+	c.JSON(http.StatusOK, gin.H{"revisions": views})
+}
+
+// GetRevision returns a recipe as it was at one revision, with the fields
+// that revision changed
+// @Summary Recipe revision
+// @Tags recipes
+// @Produce json
+// @Param id path string true "Recipe ID"
+// @Param revision path int true "Revision number"
+// @Success 200 {object} RevisionView
+// @Router /recipes/{id}/history/{revision} [get]
+func (h *Handler) GetRevision(c *gin.Context) {
+	recipe := h.ownRecipe(c)
+	if recipe == nil {
+		return
+	}
+	n, ok := revisionParam(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	revision, err := h.db.GetRecipeRevision(ctx, recipe.ID, n)
+	if err != nil {
+		apierror.Lookup(c, err, "revision not found")
+		return
+	}
+	var older *database.RecipeRevision
+	if n > 1 {
+		if older, err = h.db.GetRecipeRevision(ctx, recipe.ID, n-1); err != nil && !apierror.IsNotFound(err) {
+			apierror.Respond(c, err)
+			return
+		}
+	}
+
+	view, err := revisionView(revision, older)
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	view.Recipe = revision.Content
+	c.JSON(http.StatusOK, view)
+}
+
+// RestoreRevision rolls a recipe back to an earlier revision. The rollback
+// is itself saved as a new revision, so it can be undone the same way.
+// @Summary Roll back recipe
+// @Tags recipes
+// @Produce json
+// @Param id path string true "Recipe ID"
+// @Param revision path int true "Revision number"
+// @Success 200 {object} Recipe
+// @Router /recipes/{id}/history/{revision}/restore [post]
+func (h *Handler) RestoreRevision(c *gin.Context) {
+	recipe := h.ownRecipe(c)
+	if recipe == nil {
+		return
+	}
+	n, ok := revisionParam(c)
+	if !ok {
+		return
+	}
+	user, _ := middleware.GetUserFromContext(c)
+
+	ctx := c.Request.Context()
+	revision, err := h.db.GetRecipeRevision(ctx, recipe.ID, n)
+	if err != nil {
+		apierror.Lookup(c, err, "revision not found")
+		return
+	}
+	var content revisionContent
+	if err := json.Unmarshal(revision.Content, &content); err != nil {
+		apierror.Internal(c, fmt.Errorf("revision %d of recipe %s: %w", n, recipe.ID, err))
+		return
+	}
+
+	before := *recipe
+	restored := *recipe
+	content.applyTo(&restored)
+	restored.UpdatedAt = time.Now()
+	err = h.db.WithTx(ctx, func(ctx context.Context) error {
+		if err := h.db.UpdateRecipe(ctx, &restored); err != nil {
+			return err
+		}
+		return h.recordRevision(ctx, &before, &restored, user.ID, &n)
+	})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	h.notifyChange(ctx, recipe.ID)
+
+	c.JSON(http.StatusOK, restored)
+}
+
+// revisionView describes a revision and what it changed from older, the
+// revision before it (nil for the first)
+func revisionView(revision, older *database.RecipeRevision) (RevisionView, error) {
+	view := RevisionView{
+		Revision:     revision.Revision,
+		UserID:       revision.UserID,
+		RestoredFrom: revision.RestoredFrom,
+		CreatedAt:    revision.CreatedAt,
+		Changes:      []FieldChange{},
+	}
+	if older == nil {
+		return view, nil
+	}
+	var before, after revisionContent
+	if err := json.Unmarshal(older.Content, &before); err != nil {
+		return view, err
+	}
+	if err := json.Unmarshal(revision.Content, &after); err != nil {
+		return view, err
+	}
+	view.Changes = diffContent(before, after)
+	return view, nil
+}
@@ -0,0 +1,179 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package recipes
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/authz"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/middleware"
+)
+
+// modificationPayload is one standing change in a notes update
+type modificationPayload struct {
+	Ingredient string `json:"ingredient" binding:"max=255"`
+	Change     string `json:"change" binding:"required,max=500"`
+}
+
+// LoadNotes fetches a user's notes on a recipe, falling back to empty
+// notes when none have been saved
+func LoadNotes(ctx context.Context, db database.Database, userID, recipeID string) (*database.RecipeNote, error) {
+	note, err := db.GetRecipeNote(ctx, userID, recipeID)
+	if apierror.IsNotFound(err) {
+		return &database.RecipeNote{
+			UserID:        userID,
+			RecipeID:      recipeID,
+			Modifications: []database.RecipeModification{},
+		}, nil
+	}
+	return note, err
+}
+
+// viewRecipe loads a recipe the user may look at, along with the user's ID
+func (h *Handler) viewRecipe(c *gin.Context) (*database.Recipe, string) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return nil, ""
+	}
+	recipe, err := h.db.GetRecipeByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierror.Lookup(c, err, "recipe not found")
+		return nil, ""
+	}
+	if !authz.CanViewRecipe(user.ID, recipe) {
+		apierror.NotFound(c, "recipe not found")
+		return nil, ""
+	}
+	return recipe, user.ID
+}
+
+// recipeWithNotes loads a recipe the user may look at with their
+// own cook notes, and sets Last-Modified for both
+func (h *Handler) recipeWithNotes(c *gin.Context) *database.Recipe {
+	recipe, userID := h.viewRecipe(c)
+	if recipe == nil {
+		return nil
+	}
+	note, err := h.db.GetRecipeNote(c.Request.Context(), userID, recipe.ID)
+	if err != nil && !apierror.IsNotFound(err) {
+		apierror.Respond(c, err)
+		return nil
+	}
+	recipe.CookNotes = note
+	modified := recipe.UpdatedAt
+	if note != nil && note.UpdatedAt.After(modified) {
+		modified = note.UpdatedAt
+	}
+	middleware.SetLastModified(c, modified)
+	return recipe
+}
+
+// GetNotes returns the authenticated user's notes on a recipe
+// @Summary Get recipe notes
+// @Tags recipes
+// @Produce json
+// @Param id path string true "Recipe ID"
+// @Router /recipes/{id}/notes [get]
+func (h *Handler) GetNotes(c *gin.Context) {
+	recipe, userID := h.viewRecipe(c)
+	if recipe == nil {
+		return
+	}
+
+	note, err := LoadNotes(c.Request.Context(), h.db, userID, recipe.ID)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, note)
+}
+
+// UpdateNotes replaces the authenticated user's notes on a recipe. Notes
+// are personal, so they can be kept on any recipe the user can see.
+// @Summary Update recipe notes
+// @Tags recipes
+// @Accept json
+// @Produce json
+// @Param id path string true "Recipe ID"
+// @Router /recipes/{id}/notes [put]
+func (h *Handler) UpdateNotes(c *gin.Context) {
+	recipe, userID := h.viewRecipe(c)
+	if recipe == nil {
+		return
+	}
+
+	var req struct {
+		Notes         string                `json:"notes" binding:"max=4000"`
+		Modifications []modificationPayload `json:"modifications" binding:"max=50,dive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	note := database.RecipeNote{
+		UserID:        userID,
+		RecipeID:      recipe.ID,
+		Notes:         strings.TrimSpace(req.Notes),
+		Modifications: []database.RecipeModification{},
+		UpdatedAt:     time.Now(),
+	}
+	for _, m := range req.Modifications {
+		change := strings.TrimSpace(m.Change)
+		if change == "" {
+			continue
+		}
+		note.Modifications = append(note.Modifications, database.RecipeModification{
+			Ingredient: strings.TrimSpace(m.Ingredient),
+			Change:     change,
+		})
+	}
+
+	if err := h.db.UpsertRecipeNote(c.Request.Context(), &note); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, note)
+}
+
+// DeleteNotes clears the authenticated user's notes on a recipe
+// @Summary Delete recipe notes
+// @Tags recipes
+// @Param id path string true "Recipe ID"
+// @Success 204
+// @Router /recipes/{id}/notes [delete]
+func (h *Handler) DeleteNotes(c *gin.Context) {
+	recipe, userID := h.viewRecipe(c)
+	if recipe == nil {
+		return
+	}
+
+	if err := h.db.DeleteRecipeNote(c.Request.Context(), userID, recipe.ID); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,220 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package recipes
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Raters returns the people whose ratings and cooking count for the user:
+// the user and everyone they share a household with
+func Raters(ctx context.Context, db database.Database, userID string) ([]string, error) {
+	households, err := db.ListHouseholdsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	ids := []string{userID}
+	seen := map[string]bool{userID: true}
+	for _, household := range households {
+		members, err := db.ListHouseholdMembers(ctx, household.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range members {
+			if !seen[member.UserID] {
+				seen[member.UserID] = true
+				ids = append(ids, member.UserID)
+			}
+		}
+	}
+	return ids, nil
+}
+
+// AddRatings sets how the user's households rate and cook each recipe. It
+// returns when any of that last changed, for Last-Modified.
+func AddRatings(ctx context.Context, db database.Database, userID string, recipes []*database.Recipe) (time.Time, error) {
+	var changed time.Time
+	if len(recipes) == 0 {
+		return changed, nil
+	}
+	raters, err := Raters(ctx, db, userID)
+	if err != nil {
+		return changed, err
+	}
+	ids := make([]string, len(recipes))
+	byID := make(map[string]*database.Recipe, len(recipes))
+	for i, recipe := range recipes {
+		ids[i] = recipe.ID
+		byID[recipe.ID] = recipe
+		recipe.Ratings = &database.RecipeRatings{}
+	}
+
+	ratings, err := db.ListRecipeRatings(ctx, ids, raters)
+	if err != nil {
+		return changed, err
+	}
+	totals := map[string]int{}
+	for _, rating := range ratings {
+		summary := byID[rating.RecipeID].Ratings
+		summary.Ratings++
+		totals[rating.RecipeID] += rating.Stars
+		if rating.UserID == userID {
+			stars := rating.Stars
+			summary.MyStars = &stars
+		}
+		if rating.UpdatedAt.After(changed) {
+			changed = rating.UpdatedAt
+		}
+	}
+	for id, total := range totals {
+		summary := byID[id].Ratings
+		average := float64(total) / float64(summary.Ratings)
+		summary.Stars = &average
+	}
+
+	made, err := db.ListRecipesMade(ctx, ids, raters)
+	if err != nil {
+		return changed, err
+	}
+	for id, m := range made {
+		summary := byID[id].Ratings
+		summary.MadeCount = m.Count
+		last := m.LastAt
+		summary.LastMadeAt = &last
+		if last.After(changed) {
+			changed = last
+		}
+	}
+	return changed, nil
+}
+
+// SortByRating orders recipes by their household's average stars, best
+// first, with unrated recipes last. Ties go to the recipe made more often.
+// AddRatings must have run.
+func SortByRating(recipes []*database.Recipe) {
+	stars := func(r *database.Recipe) float64 {
+		if r.Ratings.Stars == nil {
+			return 0
+		}
+		return *r.Ratings.Stars
+	}
+	sort.SliceStable(recipes, func(i, j int) bool {
+		a, b := stars(recipes[i]), stars(recipes[j])
+		if a != b {
+			return a > b
+		}
+		return recipes[i].Ratings.MadeCount > recipes[j].Ratings.MadeCount
+	})
+}
+
+// NotMadeSince keeps the recipes nobody in the household has made since a
+// time, including those never made. AddRatings must have run.
+func NotMadeSince(recipes []*database.Recipe, since time.Time) []*database.Recipe {
+	kept := make([]*database.Recipe, 0, len(recipes))
+	for _, recipe := range recipes {
+		if last := recipe.Ratings.LastMadeAt; last == nil || last.Before(since) {
+			kept = append(kept, recipe)
+		}
+	}
+	return kept
+}
+
+// GetRating returns how the user's households rate and cook a recipe
+// @Summary Get recipe rating
+// @Tags recipes
+// @Produce json
+// @Param id path string true "Recipe ID"
+// @Router /recipes/{id}/rating [get]
+func (h *Handler) GetRating(c *gin.Context) {
+	recipe, userID := h.viewRecipe(c)
+	if recipe == nil {
+		return
+	}
+	h.respondRating(c, userID, recipe)
+}
+
+// UpdateRating sets the stars the user gives a recipe, from 1 to 5. Like
+// notes, ratings are personal, so any recipe the user can see can be rated.
+// @Summary Rate recipe
+// @Tags recipes
+// @Accept json
+// @Produce json
+// @Param id path string true "Recipe ID"
+// @Router /recipes/{id}/rating [put]
+func (h *Handler) UpdateRating(c *gin.Context) {
+	recipe, userID := h.viewRecipe(c)
+	if recipe == nil {
+		return
+	}
+
+	var req struct {
+		Stars int `json:"stars" binding:"required,min=1,max=5"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	rating := &database.RecipeRating{
+		UserID:    userID,
+		RecipeID:  recipe.ID,
+		Stars:     req.Stars,
+		UpdatedAt: time.Now(),
+	}
+	if err := h.db.UpsertRecipeRating(c.Request.Context(), rating); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	h.respondRating(c, userID, recipe)
+}
+
+// DeleteRating withdraws the user's rating of a recipe
+// @Summary Delete recipe rating
+// @Tags recipes
+// @Param id path string true "Recipe ID"
+// @Success 204
+// @Router /recipes/{id}/rating [delete]
+func (h *Handler) DeleteRating(c *gin.Context) {
+	recipe, userID := h.viewRecipe(c)
+	if recipe == nil {
+		return
+	}
+
+	if err := h.db.DeleteRecipeRating(c.Request.Context(), userID, recipe.ID); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// respondRating writes the household's ratings of a recipe
+func (h *Handler) respondRating(c *gin.Context, userID string, recipe *database.Recipe) {
+	if _, err := AddRatings(c.Request.Context(), h.db, userID, []*database.Recipe{recipe}); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, recipe.Ratings)
+}
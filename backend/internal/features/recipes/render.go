@@ -0,0 +1,323 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package recipes
+
+import (
+	"bytes"
+	"html/template"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/api/params"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/locale"
+	"github.com/rghsoftware/space-food/internal/features/sensory"
+	"github.com/rghsoftware/space-food/internal/middleware"
+)
+
+// CookMode is a recipe laid out for a kitchen display: one step per page,
+// with the timers each step mentions ready to start
+type CookMode struct {
+	RecipeID     string               `json:"recipe_id"`
+	Title        string               `json:"title"`
+	ImageURL     string               `json:"image_url,omitempty"`
+	Servings     int                  `json:"servings,omitempty"`
+	TotalMinutes int                  `json:"total_minutes,omitempty"`
+	Ingredients  []string             `json:"ingredients"`
+	Steps        []CookStep           `json:"steps"`
+	Notes        *database.RecipeNote `json:"notes,omitempty"`     // the viewer's cook notes
+	Appliance    string               `json:"appliance,omitempty"` // the appliance the steps were adapted to
+	KeepAwake    bool                 `json:"keep_awake"`          // always true; clients should hold a wake lock
+}
+
+// CookStep is one page of cook mode
+type CookStep struct {
+	Number  int         `json:"number"`
+	Text    string      `json:"text"`
+	Timers  []StepTimer `json:"timers"`
+	Tip     string      `json:"tip,omitempty"`     // how to do the step with the chosen appliance
+	Cues    []string    `json:"cues"`              // what it is hard on the senses for: strong_smell, loud, splatter
+	Warning string      `json:"warning,omitempty"` // to show before the step, for cues the viewer's sensory profile avoids
+}
+
+// StepTimer is a timer a step calls for, e.g. "simmer for 20 minutes"
+type StepTimer struct {
+	Label   string `json:"label"`
+	Seconds int    `json:"seconds"`
+}
+
+// stepNumber matches numbering at the start of a step: "1.", "2)", "Step 3:"
+var stepNumber = regexp.MustCompile(`^(?:(?i:step)\s*\d+\s*[.):-]?|\d+[.)])(?:\s+|$)`)
+
+// stepDuration matches durations such as "20 minutes", "1-2 hrs" or
+// "30 to 45 secs"
+var stepDuration = regexp.MustCompile(`(?i)\b(\d+(?:\.\d+)?)(?:\s*(?:-|–|to)\s*\d+(?:\.\d+)?)?\s*(hours?|hrs?|minutes?|mins?|seconds?|secs?)\b`)
+
+// splitSteps breaks instructions into steps, one per line, dropping any
+// numbering they were written with
+func splitSteps(instructions string) []string {
+	steps := []string{}
+	for _, line := range strings.Split(instructions, "\n") {
+		line = strings.TrimSpace(stepNumber.ReplaceAllString(strings.TrimSpace(line), ""))
+		if line != "" {
+			steps = append(steps, line)
+		}
+	}
+	return steps
+}
+
+// stepTimers finds the durations a step mentions. A range such as "20-25
+// minutes" is timed to its lower bound, so the cook checks early. Each
+// timer is labelled with the clause that mentions it.
+func stepTimers(step string) []StepTimer {
+	timers := []StepTimer{}
+	for _, m := range stepDuration.FindAllStringSubmatchIndex(step, -1) {
+		n, err := strconv.ParseFloat(step[m[2]:m[3]], 64)
+		if err != nil || n <= 0 {
+			continue
+		}
+		unit := strings.ToLower(step[m[4]:m[5]])
+		seconds := n
+		switch {
+		case strings.HasPrefix(unit, "h"):
+			seconds *= 3600
+		case strings.HasPrefix(unit, "m"):
+			seconds *= 60
+		}
+		timers = append(timers, StepTimer{Label: timerLabel(step, m[0], m[1]), Seconds: int(math.Round(seconds))})
+	}
+	return timers
+}
+
+// timerLabel takes the clause of step around a duration, e.g. "Simmer for
+// 20 minutes" out of "Add the stock. Simmer for 20 minutes, stirring."
+func timerLabel(step string, start, end int) string {
+	from := strings.LastIndexAny(step[:start], ".;!?") + 1
+	to := len(step)
+	if i := strings.IndexAny(step[end:], ".;,!?"); i >= 0 {
+		to = end + i
+	}
+	label := strings.Join(strings.Fields(step[from:to]), " ")
+	if words := strings.Fields(label); len(words) > 8 {
+		label = strings.Join(words[:8], " ") + "…"
+	}
+	return label
+}
+
+// CookSteps breaks instructions into numbered steps with the timers each
+// one calls for
+func CookSteps(instructions string) []CookStep {
+	steps := []CookStep{}
+	for i, step := range splitSteps(instructions) {
+		steps = append(steps, CookStep{Number: i + 1, Text: step, Timers: stepTimers(step), Cues: []string{}})
+	}
+	return steps
+}
+
+// formatQuantity writes a quantity as cooks do, with common fractions
+func formatQuantity(q float64) string {
+	whole, frac := math.Modf(q)
+	for _, f := range []struct {
+		value float64
+		text  string
+	}{
+		{0.125, "1/8"}, {0.25, "1/4"}, {1.0 / 3, "1/3"}, {0.5, "1/2"}, {2.0 / 3, "2/3"}, {0.75, "3/4"},
+	} {
+		if math.Abs(frac-f.value) < 0.01 {
+			if whole == 0 {
+				return f.text
+			}
+			return strconv.Itoa(int(whole)) + " " + f.text
+		}
+	}
+	return strconv.FormatFloat(math.Round(q*100)/100, 'f', -1, 64)
+}
+
+// ingredientLine writes an ingredient as a single line, e.g. "1 1/2 cup
+// flour, sifted (optional)"
+func ingredientLine(ing database.Ingredient) string {
+	parts := []string{}
+	if ing.Quantity > 0 {
+		parts = append(parts, formatQuantity(ing.Quantity))
+	}
+	if ing.Unit != "" {
+		parts = append(parts, ing.Unit)
+	}
+	parts = append(parts, ing.Name)
+	line := strings.Join(parts, " ")
+	if ing.Notes != "" {
+		line += ", " + ing.Notes
+	}
+	if ing.Optional {
+		line += " (optional)"
+	}
+	return line
+}
+
+// GetCookMode returns a recipe laid out for cooking from a kitchen display
+// @Summary Recipe cook mode
+// @Tags recipes
+// @Produce json
+// @Param id path string true "Recipe ID"
+// @Param appliance query string false "Adapt the steps to cooking in air_fryer, pressure_cooker or slow_cooker"
+// @Success 200 {object} CookMode
+// @Router /recipes/{id}/cook-mode [get]
+func (h *Handler) GetCookMode(c *gin.Context) {
+	query := params.Query(c)
+	appliance := query.String("appliance", ApplianceAirFryer, AppliancePressureCooker, ApplianceSlowCooker)
+	if !query.Valid() {
+		return
+	}
+	recipe := h.recipeWithNotes(c)
+	if recipe == nil {
+		return
+	}
+	user, _ := middleware.GetUserFromContext(c)
+	ctx := c.Request.Context()
+	tagged, err := AddSensory(ctx, h.db, []*database.Recipe{recipe})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	if modified, err := http.ParseTime(c.Writer.Header().Get("Last-Modified")); err == nil && tagged.After(modified) {
+		middleware.SetLastModified(c, tagged)
+	}
+
+	mode := CookMode{
+		RecipeID:     recipe.ID,
+		Title:        recipe.Title,
+		ImageURL:     recipe.ImageURL,
+		Servings:     recipe.Servings,
+		TotalMinutes: recipe.PrepTime + recipe.CookTime,
+		Ingredients:  []string{},
+		Notes:        recipe.CookNotes,
+		KeepAwake:    true,
+	}
+	for _, ing := range recipe.Ingredients {
+		mode.Ingredients = append(mode.Ingredients, ingredientLine(ing))
+	}
+	mode.Steps = CookSteps(recipe.Instructions)
+	if appliance != "" {
+		AdaptSteps(mode.Steps, appliance)
+		mode.Appliance = appliance
+	}
+	profile := sensory.LoadProfile(ctx, h.db, user.ID)
+	p := locale.Printer(c, h.db, user.ID)
+	for i := range mode.Steps {
+		step := &mode.Steps[i]
+		step.Cues = StepSensoryCues(recipe, step.Number)
+		step.Warning = StepWarning(p, profile, step.Text, step.Cues)
+	}
+	c.JSON(http.StatusOK, mode)
+}
+
+// printTemplate renders a recipe for paper: no navigation, one column,
+// and the viewer's notes after the method
+var printTemplate = template.Must(template.New("print").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Recipe.Title}}</title>
+<style>
+body { font-family: Georgia, serif; max-width: 42rem; margin: 2rem auto; padding: 0 1rem; line-height: 1.5; color: #000; }
+h1 { margin-bottom: 0.25rem; }
+.meta, .source { color: #444; font-size: 0.9rem; }
+img { max-width: 100%; max-height: 18rem; }
+ul, ol { padding-left: 1.5rem; }
+li { margin-bottom: 0.4rem; break-inside: avoid; }
+section { break-inside: avoid-page; }
+@media print { body { margin: 0; max-width: none; } a { color: #000; text-decoration: none; } }
+</style>
+</head>
+<body>
+<h1>{{.Recipe.Title}}</h1>
+{{with .Meta}}<p class="meta">{{.}}</p>{{end}}
+{{with .Recipe.Description}}<p>{{.}}</p>{{end}}
+{{with .Recipe.ImageURL}}<img src="{{.}}" alt="">{{end}}
+{{with .Ingredients}}<section>
+<h2>Ingredients</h2>
+<ul>{{range .}}
+<li>{{.}}</li>{{end}}
+</ul>
+</section>{{end}}
+{{with .Steps}}<section>
+<h2>Method</h2>
+<ol>{{range .}}
+<li>{{.}}</li>{{end}}
+</ol>
+</section>{{end}}
+{{with .Recipe.CookNotes}}<section>
+<h2>My notes</h2>
+{{with .Modifications}}<ul>{{range .}}
+<li>{{if .Ingredient}}{{.Ingredient}}: {{end}}{{.Change}}</li>{{end}}
+</ul>{{end}}
+{{with .Notes}}<p>{{.}}</p>{{end}}
+</section>{{end}}
+{{if or .Recipe.Source .Recipe.SourceURL}}<p class="source">Source: {{with .Recipe.SourceURL}}<a href="{{.}}">{{or $.Recipe.Source .}}</a>{{else}}{{.Recipe.Source}}{{end}}</p>{{end}}
+</body>
+</html>
+`))
+
+// GetPrint renders a recipe as a print-friendly HTML page. Browsers save
+// it as PDF from their print dialog.
+// @Summary Printable recipe
+// @Tags recipes
+// @Produce html
+// @Param id path string true "Recipe ID"
+// @Router /recipes/{id}/print [get]
+func (h *Handler) GetPrint(c *gin.Context) {
+	recipe := h.recipeWithNotes(c)
+	if recipe == nil {
+		return
+	}
+
+	meta := []string{}
+	if recipe.Servings > 0 {
+		meta = append(meta, "Serves "+strconv.Itoa(recipe.Servings))
+	}
+	if recipe.PrepTime > 0 {
+		meta = append(meta, "Prep "+strconv.Itoa(recipe.PrepTime)+" min")
+	}
+	if recipe.CookTime > 0 {
+		meta = append(meta, "Cook "+strconv.Itoa(recipe.CookTime)+" min")
+	}
+	ingredients := make([]string, 0, len(recipe.Ingredients))
+	for _, ing := range recipe.Ingredients {
+		ingredients = append(ingredients, ingredientLine(ing))
+	}
+
+	var buf bytes.Buffer
+	err := printTemplate.Execute(&buf, map[string]any{
+		"Recipe":      recipe,
+		"Meta":        strings.Join(meta, " · "),
+		"Ingredients": ingredients,
+		"Steps":       splitSteps(recipe.Instructions),
+	})
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+}
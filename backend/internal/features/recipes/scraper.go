@@ -0,0 +1,414 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package recipes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"html"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/rghsoftware/space-food/internal/config"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/fetch"
+)
+
+// ErrNoRecipe is returned for pages without recipe data we can read
+var ErrNoRecipe = errors.New("no recipe found on that page")
+
+var (
+	ldScriptPattern = regexp.MustCompile(`(?is)<script[^>]*type\s*=\s*["']?application/ld\+json["']?[^>]*>(.*?)</script>`)
+	tagPattern      = regexp.MustCompile(`<[^>]*>`)
+	breakPattern    = regexp.MustCompile(`(?i)</p>|<br\s*/?>`)
+	durationPattern = regexp.MustCompile(`(?i)^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:[\d.]+S)?)?$`)
+	numberPattern   = regexp.MustCompile(`\d+(?:\.\d+)?`)
+	quantityPattern = regexp.MustCompile(`^(\d+\s+\d+/\d+|\d+/\d+|\d+(?:\.\d+)?)(?:\s*(?:-|–|to)\s*[\d./]+)?\s*`)
+)
+
+// fractions maps vulgar fraction characters to their ASCII form
+var fractions = strings.NewReplacer(
+	"½", " 1/2", "⅓", " 1/3", "⅔", " 2/3", "¼", " 1/4", "¾", " 3/4",
+	"⅕", " 1/5", "⅖", " 2/5", "⅗", " 3/5", "⅘", " 4/5", "⅙", " 1/6",
+	"⅚", " 5/6", "⅛", " 1/8", "⅜", " 3/8", "⅝", " 5/8", "⅞", " 7/8",
+	"⁄", "/",
+)
+
+// controlChars are turned into spaces, which is harmless outside JSON
+// strings and makes them valid inside
+var controlChars = strings.NewReplacer("\n", " ", "\r", " ", "\t", " ")
+
+// units are the measures recognised after an ingredient's quantity
+var units = map[string]bool{
+	"c": true, "cup": true, "cups": true,
+	"tbsp": true, "tbs": true, "tablespoon": true, "tablespoons": true,
+	"tsp": true, "teaspoon": true, "teaspoons": true,
+	"oz": true, "ounce": true, "ounces": true, "fl": true,
+	"lb": true, "lbs": true, "pound": true, "pounds": true,
+	"g": true, "gram": true, "grams": true, "kg": true, "kilogram": true, "kilograms": true,
+	"ml": true, "milliliter": true, "milliliters": true, "millilitre": true, "millilitres": true,
+	"l": true, "liter": true, "liters": true, "litre": true, "litres": true,
+	"pint": true, "pints": true, "quart": true, "quarts": true, "gallon": true, "gallons": true,
+	"pinch": true, "pinches": true, "dash": true, "dashes": true,
+	"clove": true, "cloves": true, "can": true, "cans": true, "package": true, "packages": true,
+	"slice": true, "slices": true, "stick": true, "sticks": true, "bunch": true, "bunches": true,
+}
+
+// Scraper reads recipes from web pages. Sites with an Adapter registered
+// are read by it; any other site by the schema.org Recipe data most recipe
+// sites embed as JSON-LD.
+type Scraper struct {
+	fetcher *fetch.Client
+}
+
+// NewScraper creates a scraper that fetches pages under the fetch policy
+func NewScraper(cfg *config.Config) *Scraper {
+	return &Scraper{
+		fetcher: fetch.New(cfg.Fetch, int64(max(cfg.Fetch.MaxPageSize, 1))<<20),
+	}
+}
+
+// Scrape fetches a recipe page. The recipe's ImageURL still points at the
+// source site.
+func (s *Scraper) Scrape(ctx context.Context, rawURL string) (*database.Recipe, error) {
+	u, err := s.fetcher.Check(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	adapter := adapterFor(u.Hostname())
+	resp, err := s.fetcher.GetWith(ctx, u.String(), adapter.Header, "text/html", "application/xhtml+xml")
+	if err != nil {
+		return nil, err
+	}
+	if resp.URL.Hostname() != u.Hostname() {
+		// Redirected, e.g. to a regional version of the site
+		adapter = adapterFor(resp.URL.Hostname())
+	}
+	recipe, err := adapter.Extract(resp.Body, resp.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	recipe.SourceURL = resp.URL.String()
+	if recipe.Source == "" {
+		recipe.Source = strings.TrimPrefix(resp.URL.Hostname(), "www.")
+	}
+	if recipe.ImageURL != "" {
+		// Resolve relative image paths against the page
+		if ref, err := resp.URL.Parse(recipe.ImageURL); err == nil {
+			recipe.ImageURL = ref.String()
+		} else {
+			recipe.ImageURL = ""
+		}
+	}
+	return recipe, nil
+}
+
+// parseRecipePage finds the first schema.org Recipe in a page's JSON-LD
+func parseRecipePage(page []byte) (*database.Recipe, error) {
+	for _, match := range ldScriptPattern.FindAllSubmatch(page, -1) {
+		data, ok := decodeLD(match[1])
+		if !ok {
+			continue
+		}
+		if node := findRecipe(data); node != nil {
+			if recipe := recipeFromLD(node); recipe.Title != "" {
+				return recipe, nil
+			}
+		}
+	}
+	return nil, ErrNoRecipe
+}
+
+// decodeLD parses a JSON-LD script, tolerating the HTML escaping and raw
+// line breaks inside strings that some sites emit
+func decodeLD(script []byte) (any, bool) {
+	var data any
+	for _, text := range []string{
+		string(script),
+		html.UnescapeString(string(script)),
+		controlChars.Replace(string(script)),
+	} {
+		if json.Unmarshal([]byte(text), &data) == nil {
+			return data, true
+		}
+	}
+	return nil, false
+}
+
+// findRecipe looks for a node of @type Recipe at the top level, in a list
+// or in an @graph
+func findRecipe(data any) map[string]any {
+	switch v := data.(type) {
+	case []any:
+		for _, item := range v {
+			if node := findRecipe(item); node != nil {
+				return node
+			}
+		}
+	case map[string]any:
+		if isType(v["@type"], "Recipe") {
+			return v
+		}
+		if graph, ok := v["@graph"]; ok {
+			return findRecipe(graph)
+		}
+		if entity, ok := v["mainEntity"].(map[string]any); ok && isType(entity["@type"], "Recipe") {
+			return entity
+		}
+	}
+	return nil
+}
+
+// isType matches a JSON-LD @type, which may be a string or a list
+func isType(t any, name string) bool {
+	switch v := t.(type) {
+	case string:
+		return strings.EqualFold(strings.TrimPrefix(v, "schema:"), name) ||
+			strings.HasSuffix(v, "schema.org/"+name)
+	case []any:
+		for _, item := range v {
+			if isType(item, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// recipeFromLD maps a schema.org Recipe onto ours
+func recipeFromLD(node map[string]any) *database.Recipe {
+	recipe := &database.Recipe{
+		Title:        ldText(node["name"]),
+		Description:  ldText(node["description"]),
+		Instructions: strings.Join(ldInstructions(node["recipeInstructions"]), "\n"),
+		PrepTime:     ldMinutes(node["prepTime"]),
+		CookTime:     ldMinutes(node["cookTime"]),
+		Servings:     int(ldNumber(node["recipeYield"])),
+		ImageURL:     ldImage(node["image"]),
+		Categories:   ldList(node["recipeCategory"]),
+		Tags:         ldList(node["keywords"]),
+	}
+	if publisher, ok := node["publisher"].(map[string]any); ok {
+		recipe.Source = ldText(publisher["name"])
+	}
+	if recipe.PrepTime == 0 && recipe.CookTime == 0 {
+		recipe.CookTime = ldMinutes(node["totalTime"])
+	}
+	if cuisines := ldList(node["recipeCuisine"]); len(cuisines) > 0 {
+		recipe.Categories = append(recipe.Categories, cuisines...)
+	}
+
+	lines := ldList(node["recipeIngredient"])
+	if len(lines) == 0 {
+		lines = ldList(node["ingredients"]) // the older property name
+	}
+	for i, line := range lines {
+		ingredient := parseIngredient(line)
+		ingredient.Order = i
+		recipe.Ingredients = append(recipe.Ingredients, ingredient)
+	}
+
+	if nutrition, ok := node["nutrition"].(map[string]any); ok {
+		info := database.NutritionInfo{
+			Calories:      ldNumber(nutrition["calories"]),
+			Protein:       ldNumber(nutrition["proteinContent"]),
+			Carbohydrates: ldNumber(nutrition["carbohydrateContent"]),
+			Fat:           ldNumber(nutrition["fatContent"]),
+			Fiber:         ldNumber(nutrition["fiberContent"]),
+			Sugar:         ldNumber(nutrition["sugarContent"]),
+			Sodium:        ldNumber(nutrition["sodiumContent"]),
+		}
+		if info != (database.NutritionInfo{}) {
+			recipe.NutritionInfo = &info
+		}
+	}
+	return recipe
+}
+
+// ldText reads a value as plain text, stripping any markup
+func ldText(v any) string {
+	switch t := v.(type) {
+	case string:
+		text := html.UnescapeString(tagPattern.ReplaceAllString(t, " "))
+		return strings.Join(strings.Fields(text), " ")
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case []any:
+		if len(t) > 0 {
+			return ldText(t[0])
+		}
+	case map[string]any:
+		if text := ldText(t["text"]); text != "" {
+			return text
+		}
+		return ldText(t["name"])
+	}
+	return ""
+}
+
+// ldList reads a list, or a comma separated string, as texts
+func ldList(v any) []string {
+	var items []string
+	switch t := v.(type) {
+	case string:
+		for _, part := range strings.Split(t, ",") {
+			if text := ldText(part); text != "" {
+				items = append(items, text)
+			}
+		}
+	case []any:
+		for _, item := range t {
+			if text := ldText(item); text != "" {
+				items = append(items, text)
+			}
+		}
+	}
+	return items
+}
+
+// ldInstructions flattens instructions given as text, a list of steps or
+// HowToSections of steps
+func ldInstructions(v any) []string {
+	var steps []string
+	switch t := v.(type) {
+	case string:
+		for _, line := range strings.Split(breakPattern.ReplaceAllString(t, "\n"), "\n") {
+			if text := ldText(line); text != "" {
+				steps = append(steps, text)
+			}
+		}
+	case []any:
+		for _, item := range t {
+			steps = append(steps, ldInstructions(item)...)
+		}
+	case map[string]any:
+		if elements, ok := t["itemListElement"]; ok {
+			return ldInstructions(elements)
+		}
+		if text := ldText(t); text != "" {
+			steps = append(steps, text)
+		}
+	}
+	return steps
+}
+
+// ldMinutes reads an ISO 8601 duration such as PT1H30M
+func ldMinutes(v any) int {
+	s, _ := v.(string)
+	m := durationPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0
+	}
+	days, _ := strconv.Atoi(m[1])
+	hours, _ := strconv.Atoi(m[2])
+	minutes, _ := strconv.Atoi(m[3])
+	return days*24*60 + hours*60 + minutes
+}
+
+// ldNumber reads the first number in a value such as "4 servings" or
+// "250 kcal"
+func ldNumber(v any) float64 {
+	switch t := v.(type) {
+	case float64:
+		return t
+	case string:
+		n, _ := strconv.ParseFloat(numberPattern.FindString(t), 64)
+		return n
+	case []any:
+		for _, item := range t {
+			if n := ldNumber(item); n > 0 {
+				return n
+			}
+		}
+	}
+	return 0
+}
+
+// ldImage reads an image given as a URL, a list or an ImageObject
+func ldImage(v any) string {
+	switch t := v.(type) {
+	case string:
+		return strings.TrimSpace(t)
+	case []any:
+		for _, item := range t {
+			if u := ldImage(item); u != "" {
+				return u
+			}
+		}
+	case map[string]any:
+		if u := ldImage(t["url"]); u != "" {
+			return u
+		}
+		return ldImage(t["contentUrl"])
+	}
+	return ""
+}
+
+// parseIngredient splits a line such as "1 1/2 cups flour, sifted" into
+// quantity, unit, name and notes. Lines it can't split become the name.
+func parseIngredient(line string) database.Ingredient {
+	line = strings.Join(strings.Fields(fractions.Replace(line)), " ")
+	ingredient := database.Ingredient{Name: line}
+
+	rest := line
+	if m := quantityPattern.FindStringSubmatch(rest); m != nil {
+		ingredient.Quantity = parseQuantity(m[1])
+		rest = rest[len(m[0]):]
+	}
+	if ingredient.Quantity > 0 {
+		if word, after, _ := strings.Cut(rest, " "); units[strings.ToLower(strings.TrimSuffix(word, "."))] {
+			ingredient.Unit = strings.ToLower(strings.TrimSuffix(word, "."))
+			rest = after
+		}
+	}
+
+	if strings.Contains(strings.ToLower(rest), "(optional)") {
+		ingredient.Optional = true
+		rest = strings.TrimSpace(strings.NewReplacer("(optional)", "", "(Optional)", "").Replace(rest))
+	}
+	name, notes, _ := strings.Cut(rest, ",")
+	ingredient.Name = strings.TrimSpace(name)
+	ingredient.Notes = strings.TrimSpace(notes)
+	if ingredient.Name == "" {
+		ingredient.Name = line
+	}
+	return ingredient
+}
+
+// parseQuantity reads "2", "1.5", "1/2" or "1 1/2"
+func parseQuantity(s string) float64 {
+	var total float64
+	for _, part := range strings.Fields(s) {
+		if num, den, ok := strings.Cut(part, "/"); ok {
+			n, _ := strconv.ParseFloat(num, 64)
+			d, _ := strconv.ParseFloat(den, 64)
+			if d != 0 {
+				total += n / d
+			}
+			continue
+		}
+		n, _ := strconv.ParseFloat(part, 64)
+		total += n
+	}
+	return math.Round(total*1000) / 1000
+}
@@ -0,0 +1,80 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package recipes
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Adapter reads recipes from the pages of particular sites whose markup
+// the generic JSON-LD reader gets wrong
+type Adapter struct {
+	Name    string
+	Domains []string    // sites handled, subdomains included
+	Header  http.Header // extra request headers the site needs
+	// Extract reads the recipe from a page fetched from pageURL, returning
+	// ErrNoRecipe when there is none
+	Extract func(page []byte, pageURL *url.URL) (*database.Recipe, error)
+}
+
+// genericAdapter reads the JSON-LD of sites without an adapter
+var genericAdapter = &Adapter{
+	Name: "generic",
+	Extract: func(page []byte, _ *url.URL) (*database.Recipe, error) {
+		return parseRecipePage(page)
+	},
+}
+
+var (
+	adaptersMu sync.RWMutex
+	adapters   = map[string]*Adapter{}
+)
+
+// RegisterAdapter adds a site adapter. An adapter registered later for the
+// same domain replaces the earlier one.
+func RegisterAdapter(adapter Adapter) {
+	adaptersMu.Lock()
+	defer adaptersMu.Unlock()
+	for _, domain := range adapter.Domains {
+		adapters[strings.ToLower(domain)] = &adapter
+	}
+}
+
+// adapterFor picks the adapter of the most specific registered domain host
+// belongs to, or the generic one
+func adapterFor(host string) *Adapter {
+	adaptersMu.RLock()
+	defer adaptersMu.RUnlock()
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for {
+		if adapter, ok := adapters[host]; ok {
+			return adapter
+		}
+		_, parent, found := strings.Cut(host, ".")
+		if !found {
+			return genericAdapter
+		}
+		host = parent
+	}
+}
@@ -0,0 +1,260 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package recipes
+
+import (
+	"encoding/json"
+	"errors"
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+func init() {
+	RegisterAdapter(Adapter{
+		Name:    "allrecipes",
+		Domains: []string{"allrecipes.com"},
+		Header:  http.Header{"Accept-Language": {"en-US,en;q=0.9"}},
+		Extract: extractAllRecipes,
+	})
+	RegisterAdapter(Adapter{
+		Name:    "nytcooking",
+		Domains: []string{"cooking.nytimes.com"},
+		Extract: extractNYTCooking,
+	})
+	RegisterAdapter(Adapter{
+		Name: "cookidoo",
+		Domains: []string{
+			"cookidoo.international", "cookidoo.thermomix.com", "cookidoo.co.uk",
+			"cookidoo.com.au", "cookidoo.ca", "cookidoo.de", "cookidoo.at", "cookidoo.ch",
+			"cookidoo.fr", "cookidoo.be", "cookidoo.nl", "cookidoo.es", "cookidoo.pt",
+			"cookidoo.it", "cookidoo.pl", "cookidoo.cz", "cookidoo.mx",
+		},
+		Extract: extractCookidoo,
+	})
+}
+
+var (
+	allRecipesIngredientPattern = regexp.MustCompile(`(?is)<li[^>]*structured-ingredients__list-item[^>]*>(.*?)</li>`)
+	allRecipesPartPattern       = regexp.MustCompile(`(?is)<span[^>]*data-ingredient-(quantity|unit|name)="true"[^>]*>(.*?)</span>`)
+	nextDataPattern             = regexp.MustCompile(`(?is)<script[^>]*id="__NEXT_DATA__"[^>]*>(.*?)</script>`)
+)
+
+// extractAllRecipes reads the JSON-LD, then takes the ingredients from the
+// page's structured list, which splits quantity, unit and name itself and
+// knows units such as "medium" that parsing a line can't
+func extractAllRecipes(page []byte, _ *url.URL) (*database.Recipe, error) {
+	recipe, err := parseRecipePage(page)
+	if err != nil {
+		return nil, err
+	}
+
+	var ingredients []database.Ingredient
+	for i, item := range allRecipesIngredientPattern.FindAllSubmatch(page, -1) {
+		ingredient := database.Ingredient{Order: i}
+		rest := string(item[1])
+		for _, part := range allRecipesPartPattern.FindAllStringSubmatch(rest, -1) {
+			text := ldText(part[2])
+			switch part[1] {
+			case "quantity":
+				if m := quantityPattern.FindStringSubmatch(strings.TrimSpace(fractions.Replace(text))); m != nil {
+					ingredient.Quantity = parseQuantity(m[1])
+				}
+			case "unit":
+				ingredient.Unit = strings.ToLower(text)
+			case "name":
+				ingredient.Name = text
+			}
+			rest = strings.Replace(rest, part[0], "", 1)
+		}
+		if ingredient.Name == "" {
+			continue
+		}
+		// Whatever follows the spans, such as ", sifted"
+		ingredient.Notes = strings.Trim(ldText(rest), ", ")
+		ingredients = append(ingredients, ingredient)
+	}
+	if len(ingredients) > 0 {
+		recipe.Ingredients = ingredients
+	}
+	return recipe, nil
+}
+
+// extractNYTCooking reads the JSON-LD and fills in what it lacks from the
+// page's app data. Without a subscription the site leaves the method out of
+// both; such recipes import without instructions.
+func extractNYTCooking(page []byte, _ *url.URL) (*database.Recipe, error) {
+	recipe, err := parseRecipePage(page)
+	if err != nil && !errors.Is(err, ErrNoRecipe) {
+		return nil, err
+	}
+
+	var data any
+	var app map[string]any
+	if m := nextDataPattern.FindSubmatch(page); m != nil && json.Unmarshal(m[1], &data) == nil {
+		app = findNYTRecipe(data, 0)
+	}
+	if app == nil {
+		if recipe == nil {
+			return nil, ErrNoRecipe
+		}
+		return recipe, nil
+	}
+
+	if recipe == nil {
+		recipe = &database.Recipe{
+			Title:       ldText(app["title"]),
+			Description: ldText(app["topnote"]),
+			Servings:    int(ldNumber(app["recipeYield"])),
+		}
+		if recipe.Title == "" {
+			return nil, ErrNoRecipe
+		}
+	}
+	if recipe.Instructions == "" {
+		recipe.Instructions = strings.Join(nytSteps(app["steps"]), "\n")
+	}
+	if len(recipe.Ingredients) == 0 {
+		for i, line := range nytIngredients(app["ingredients"]) {
+			ingredient := parseIngredient(line)
+			ingredient.Order = i
+			recipe.Ingredients = append(recipe.Ingredients, ingredient)
+		}
+	}
+	return recipe, nil
+}
+
+// findNYTRecipe finds the object holding both ingredients and steps in the
+// app data, which nests it a few levels down under props
+func findNYTRecipe(data any, depth int) map[string]any {
+	if depth > 8 {
+		return nil
+	}
+	switch v := data.(type) {
+	case map[string]any:
+		_, hasIngredients := v["ingredients"]
+		_, hasSteps := v["steps"]
+		if hasIngredients && hasSteps {
+			return v
+		}
+		for _, child := range v {
+			if found := findNYTRecipe(child, depth+1); found != nil {
+				return found
+			}
+		}
+	case []any:
+		for _, child := range v {
+			if found := findNYTRecipe(child, depth+1); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+// nytIngredients flattens ingredient groups into lines of quantity and text
+func nytIngredients(v any) []string {
+	var lines []string
+	items, _ := v.([]any)
+	for _, item := range items {
+		m, ok := item.(map[string]any)
+		if !ok {
+			if text := ldText(item); text != "" {
+				lines = append(lines, text)
+			}
+			continue
+		}
+		if group, ok := m["ingredients"]; ok {
+			lines = append(lines, nytIngredients(group)...)
+			continue
+		}
+		line := strings.TrimSpace(ldText(m["quantity"]) + " " + ldText(m["text"]))
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// nytSteps flattens step groups into instructions
+func nytSteps(v any) []string {
+	var steps []string
+	items, _ := v.([]any)
+	for _, item := range items {
+		m, ok := item.(map[string]any)
+		if !ok {
+			if text := ldText(item); text != "" {
+				steps = append(steps, text)
+			}
+			continue
+		}
+		if group, ok := m["steps"]; ok {
+			steps = append(steps, nytSteps(group)...)
+			continue
+		}
+		if text := ldText(m["description"]); text != "" {
+			steps = append(steps, text)
+		} else if text := ldText(m["text"]); text != "" {
+			steps = append(steps, text)
+		}
+	}
+	return steps
+}
+
+// extractCookidoo reads the JSON-LD and drops the Thermomix control symbols
+// Cookidoo writes as private-use characters, which show up as boxes
+func extractCookidoo(page []byte, _ *url.URL) (*database.Recipe, error) {
+	recipe, err := parseRecipePage(page)
+	if err != nil {
+		return nil, err
+	}
+
+	recipe.Instructions = stripPrivateUse(recipe.Instructions)
+	for i := range recipe.Ingredients {
+		recipe.Ingredients[i].Name = stripPrivateUse(recipe.Ingredients[i].Name)
+		recipe.Ingredients[i].Notes = stripPrivateUse(recipe.Ingredients[i].Notes)
+	}
+	for _, tag := range recipe.Tags {
+		if strings.EqualFold(tag, "Thermomix") {
+			return recipe, nil
+		}
+	}
+	recipe.Tags = append(recipe.Tags, "Thermomix")
+	return recipe, nil
+}
+
+// stripPrivateUse removes private-use characters, keeping line breaks
+func stripPrivateUse(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		line = strings.Map(func(r rune) rune {
+			if unicode.Is(unicode.Co, r) {
+				return -1
+			}
+			return r
+		}, html.UnescapeString(line))
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,194 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package recipes
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ingredient is the part of an extracted ingredient the tests check
+type ingredient struct {
+	Quantity float64
+	Unit     string
+	Name     string
+}
+
+// TestAdapters runs each site adapter over a saved page from its site, so a
+// change to an adapter, or to the generic reader they build on, shows up
+// as a change in what is extracted. When a site changes its markup, save a
+// fresh page under testdata and update its case.
+func TestAdapters(t *testing.T) {
+	tests := []struct {
+		page        string
+		url         string
+		adapter     string
+		title       string
+		ingredients []ingredient
+		steps       []string
+		tags        []string
+	}{
+		{
+			page:    "generic.html",
+			url:     "https://example.com/recipes/lentil-soup",
+			adapter: "generic",
+			title:   "Weeknight Lentil Soup",
+			ingredients: []ingredient{
+				{1, "cup", "red lentils"},
+				{1.5, "tsp", "ground cumin"},
+				{750, "ml", "vegetable stock"},
+				{1, "", "onion"},
+			},
+			steps: []string{
+				"Soften the onion in a little oil.",
+				"Add the cumin, lentils and stock and simmer for 20 minutes.",
+				"Blend until smooth & season to taste.",
+			},
+		},
+		{
+			page:    "allrecipes.html",
+			url:     "https://www.allrecipes.com/recipe/12345/simple-skillet-potatoes/",
+			adapter: "allrecipes",
+			title:   "Simple Skillet Potatoes",
+			ingredients: []ingredient{
+				{2, "medium", "potatoes"},
+				{1, "medium", "onion"},
+				{2, "tablespoons", "olive oil"},
+				{0, "", "salt"},
+			},
+			steps: []string{
+				"Heat the oil in a large skillet over medium heat.",
+				"Add the potatoes and onion and cook, stirring now and then, until golden, about 25 minutes.",
+				"Season with salt.",
+			},
+		},
+		{
+			page:    "nytcooking.html",
+			url:     "https://cooking.nytimes.com/recipes/1020000-sheet-pan-chickpeas",
+			adapter: "nytcooking",
+			title:   "Sheet-Pan Chickpeas",
+			ingredients: []ingredient{
+				{1, "can", "chickpeas"},
+				{2, "tbsp", "olive oil"},
+				{1, "tsp", "smoked paprika"},
+			},
+			steps: []string{
+				"Heat the oven to 425 degrees.",
+				"Toss the chickpeas with the oil and paprika.",
+				"Roast for 25 minutes, shaking the pan halfway.",
+			},
+		},
+		{
+			page:    "nytcooking-appdata.html",
+			url:     "https://cooking.nytimes.com/recipes/1020001-buttered-noodles",
+			adapter: "nytcooking",
+			title:   "Buttered Noodles",
+			ingredients: []ingredient{
+				{200, "g", "egg noodles"},
+				{2, "tbsp", "butter"},
+				{0, "", "Flaky salt"},
+			},
+			steps: []string{
+				"Boil the noodles until tender, then drain.",
+				"Toss with the butter and salt.",
+			},
+		},
+		{
+			page:    "cookidoo.html",
+			url:     "https://cookidoo.co.uk/recipes/recipe/en-GB/r123456",
+			adapter: "cookidoo",
+			title:   "Tomato Sauce",
+			ingredients: []ingredient{
+				{1, "clove", "garlic"},
+				{400, "g", "canned tomatoes"},
+				{1, "tsp", "dried oregano"},
+			},
+			steps: []string{
+				"Place garlic in mixing bowl and chop 3 sec/speed 7.",
+				"Add tomatoes and oregano and cook 15 min/100°C//speed .",
+			},
+			tags: []string{"Sauces", "Vegetarian", "Thermomix"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.page, func(t *testing.T) {
+			page, err := os.ReadFile(filepath.Join("testdata", tt.page))
+			if err != nil {
+				t.Fatal(err)
+			}
+			pageURL, err := url.Parse(tt.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			adapter := adapterFor(pageURL.Hostname())
+			if adapter.Name != tt.adapter {
+				t.Fatalf("adapter = %q, want %q", adapter.Name, tt.adapter)
+			}
+			recipe, err := adapter.Extract(page, pageURL)
+			if err != nil {
+				t.Fatalf("Extract: %v", err)
+			}
+
+			if recipe.Title != tt.title {
+				t.Errorf("title = %q, want %q", recipe.Title, tt.title)
+			}
+			var ingredients []ingredient
+			for i, got := range recipe.Ingredients {
+				if got.Order != i {
+					t.Errorf("ingredient %d has order %d", i, got.Order)
+				}
+				ingredients = append(ingredients, ingredient{got.Quantity, got.Unit, got.Name})
+			}
+			if !reflect.DeepEqual(ingredients, tt.ingredients) {
+				t.Errorf("ingredients = %+v, want %+v", ingredients, tt.ingredients)
+			}
+			if steps := strings.Split(recipe.Instructions, "\n"); !reflect.DeepEqual(steps, tt.steps) {
+				t.Errorf("steps = %q, want %q", steps, tt.steps)
+			}
+			if tt.tags != nil && !reflect.DeepEqual(recipe.Tags, tt.tags) {
+				t.Errorf("tags = %q, want %q", recipe.Tags, tt.tags)
+			}
+		})
+	}
+}
+
+func TestAdapterFor(t *testing.T) {
+	tests := map[string]string{
+		"allrecipes.com":      "allrecipes",
+		"www.allrecipes.com":  "allrecipes",
+		"WWW.AllRecipes.com.": "allrecipes",
+		"cooking.nytimes.com": "nytcooking",
+		"www.nytimes.com":     "generic",
+		"cookidoo.de":         "cookidoo",
+		"notallrecipes.com":   "generic",
+		"allrecipes.com.evil": "generic",
+		"localhost":           "generic",
+	}
+	for host, want := range tests {
+		if got := adapterFor(host).Name; got != want {
+			t.Errorf("adapterFor(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
@@ -0,0 +1,379 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package recipes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/sensory"
+	"github.com/rghsoftware/space-food/internal/i18n"
+)
+
+// spiceTerms are the ingredients that make a dish spicy, by how hot they
+// usually make it. The longest mention in an ingredient wins, so "chili
+// powder" is mild though a "chili" is not.
+var spiceTerms = map[string]int{
+	"habanero": sensory.SpiceHot, "scotch bonnet": sensory.SpiceHot, "ghost pepper": sensory.SpiceHot,
+	"bhut jolokia": sensory.SpiceHot, "carolina reaper": sensory.SpiceHot, "bird's eye": sensory.SpiceHot,
+	"birds eye": sensory.SpiceHot, "thai chili": sensory.SpiceHot, "thai chile": sensory.SpiceHot,
+
+	"chili": sensory.SpiceMedium, "chilli": sensory.SpiceMedium, "chile": sensory.SpiceMedium,
+	"chilies": sensory.SpiceMedium, "chillies": sensory.SpiceMedium, "chiles": sensory.SpiceMedium,
+	"cayenne": sensory.SpiceMedium, "chili flakes": sensory.SpiceMedium, "chilli flakes": sensory.SpiceMedium,
+	"red pepper flakes": sensory.SpiceMedium, "crushed red pepper": sensory.SpiceMedium,
+	"jalapeño": sensory.SpiceMedium, "jalapeno": sensory.SpiceMedium, "serrano": sensory.SpiceMedium,
+	"chipotle": sensory.SpiceMedium, "sriracha": sensory.SpiceMedium, "hot sauce": sensory.SpiceMedium,
+	"tabasco": sensory.SpiceMedium, "chili oil": sensory.SpiceMedium, "chilli oil": sensory.SpiceMedium,
+	"sambal": sensory.SpiceMedium, "gochujang": sensory.SpiceMedium, "harissa": sensory.SpiceMedium,
+	"piri piri": sensory.SpiceMedium, "peri peri": sensory.SpiceMedium, "curry paste": sensory.SpiceMedium,
+	"wasabi": sensory.SpiceMedium, "horseradish": sensory.SpiceMedium,
+
+	"chili powder": sensory.SpiceMild, "chilli powder": sensory.SpiceMild, "ancho": sensory.SpiceMild,
+	"poblano": sensory.SpiceMild, "curry powder": sensory.SpiceMild, "hot paprika": sensory.SpiceMild,
+	"gochugaru": sensory.SpiceMild, "sweet chili": sensory.SpiceMild, "sweet chilli": sensory.SpiceMild,
+}
+
+// spicePattern matches any spice term as whole words
+var spicePattern = wordsPattern(keys(spiceTerms))
+
+// spiceTags are recipe tags that say how spicy a dish is
+var spiceTags = map[string]int{
+	"mild": sensory.SpiceMild, "spicy": sensory.SpiceMedium, "hot and spicy": sensory.SpiceHot,
+	"very spicy": sensory.SpiceHot, "extra spicy": sensory.SpiceHot, "fiery": sensory.SpiceHot,
+}
+
+// smallAmounts are units little enough to take the heat down a level
+var smallAmounts = map[string]bool{"pinch": true, "pinches": true, "dash": true, "dashes": true, "drop": true, "drops": true}
+
+// Step cue patterns. Air frying is taken out of the text before looking for
+// splatter, since it doesn't spit oil.
+var (
+	loudStep     = regexp.MustCompile(`(?i)\b(blend(s|ed|ing)?|blender|food processor|processor|pulse|grind(s|ing)?|grinder|mixer|juicer|quick[- ]release)\b`)
+	airFrying    = regexp.MustCompile(`(?i)\bair[- ]?fr\w*`)
+	splatterStep = regexp.MustCompile(`(?i)\b((deep|shallow|pan|stir)[- ]?)?(fry|fries|fried|frying)\b|\bsear(s|ed|ing)?\b|\bsizzl\w*|\bhot (oil|fat)\b|\bsmoking hot\b`)
+	pungent      = regexp.MustCompile(`(?i)\b(fish sauce|shrimp paste|dried shrimp|anchov(y|ies)|fish|salmon|mackerel|sardines?|vinegar|cabbage|sauerkraut|kimchi|brussels sprouts|chil(i|e|li)(e?s)?|asafoetida|durian)\b`)
+	heating      = regexp.MustCompile(`(?i)\b(fr(y|ies|ied|ying)|sear\w*|roast\w*|toast\w*|boil\w*|simmer\w*|reduc\w*|bak(e|es|ed|ing)|grill\w*|broil\w*|saut(e|é)\w*|cook\w*|heat\w*|bloom\w*)\b`)
+	smokyStep    = regexp.MustCompile(`(?i)\b(char|charr(ed|ing)|blacken\w*|smoking)\b`)
+)
+
+// wordsPattern matches any of the terms as whole words
+func wordsPattern(terms []string) *regexp.Regexp {
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = regexp.QuoteMeta(term)
+	}
+	// Longest first, so alternatives such as "chili powder" beat "chili"
+	sort.Slice(quoted, func(a, b int) bool { return len(quoted[a]) > len(quoted[b]) })
+	return regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)\b`)
+}
+
+func keys(m map[string]int) []string {
+	list := make([]string, 0, len(m))
+	for key := range m {
+		list = append(list, key)
+	}
+	return list
+}
+
+// DetectSpice works out how spicy a recipe is from its ingredients and
+// tags: the hottest required ingredient, a level less when there's only a
+// pinch or a dash of it, and at least what the tags say. Optional
+// ingredients can be left out, so they don't count.
+func DetectSpice(recipe *database.Recipe) int {
+	level := sensory.SpiceNone
+	for _, ingredient := range recipe.Ingredients {
+		if ingredient.Optional {
+			continue
+		}
+		heat := sensory.SpiceNone
+		for _, match := range spicePattern.FindAllString(ingredient.Name, -1) {
+			heat = max(heat, spiceTerms[strings.ToLower(match)])
+		}
+		if heat > sensory.SpiceMild && strings.Contains(strings.ToLower(ingredient.Name), "mild") {
+			heat = sensory.SpiceMild
+		}
+		if heat > sensory.SpiceMild && smallAmounts[strings.ToLower(ingredient.Unit)] {
+			heat--
+		}
+		level = max(level, heat)
+	}
+	for _, tag := range recipe.Tags {
+		level = max(level, spiceTags[strings.ToLower(strings.TrimSpace(tag))])
+	}
+	return level
+}
+
+// StepCues finds what a step of the method is hard on the senses for:
+// loud appliances, hot oil that can spit, and strong smells such as fish
+// or vinegar being cooked, or charring
+func StepCues(step string) []string {
+	cues := []string{}
+	if loudStep.MatchString(step) {
+		cues = append(cues, sensory.CueLoud)
+	}
+	if splatterStep.MatchString(airFrying.ReplaceAllString(step, "")) {
+		cues = append(cues, sensory.CueSplatter)
+	}
+	if (pungent.MatchString(step) && heating.MatchString(step)) || smokyStep.MatchString(step) {
+		cues = append(cues, sensory.CueStrongSmell)
+	}
+	return cues
+}
+
+// AddSensory sets each recipe's spice level and step cues: what its author
+// tagged it with, or else what its ingredients and method suggest. It
+// returns when the most recently tagged of them was tagged.
+func AddSensory(ctx context.Context, db database.Database, recipes []*database.Recipe) (time.Time, error) {
+	var changed time.Time
+	ids := make([]string, len(recipes))
+	for i, recipe := range recipes {
+		ids[i] = recipe.ID
+	}
+	tagged, err := db.ListRecipeSensoryTags(ctx, ids)
+	if err != nil {
+		return changed, err
+	}
+	for _, recipe := range recipes {
+		tags := tagged[recipe.ID]
+		if tags != nil && tags.UpdatedAt.After(changed) {
+			changed = tags.UpdatedAt
+		}
+		recipe.Sensory = sensoryOf(recipe, tags)
+	}
+	return changed, nil
+}
+
+// sensoryOf works out a recipe's sensory descriptors, preferring the
+// author's tags, which may be nil
+func sensoryOf(recipe *database.Recipe, tags *database.RecipeSensoryTags) *database.RecipeSensory {
+	result := &database.RecipeSensory{SpiceLevel: DetectSpice(recipe), Cues: []string{}, Steps: []database.StepSensory{}}
+	if tags != nil && tags.SpiceLevel != nil {
+		result.SpiceLevel, result.SpiceTagged = *tags.SpiceLevel, true
+	}
+
+	stepTags := map[string][]string{}
+	if tags != nil {
+		for _, step := range tags.Steps {
+			stepTags[step.Text] = step.Cues
+		}
+	}
+	seen := map[string]bool{}
+	for i, text := range splitSteps(recipe.Instructions) {
+		cues, tagged := stepTags[text]
+		if !tagged {
+			cues = StepCues(text)
+		}
+		if len(cues) == 0 && !tagged {
+			continue
+		}
+		result.Steps = append(result.Steps, database.StepSensory{Step: i + 1, Cues: cues, Tagged: tagged})
+		for _, cue := range cues {
+			if !seen[cue] {
+				seen[cue] = true
+				result.Cues = append(result.Cues, cue)
+			}
+		}
+	}
+	sort.Strings(result.Cues)
+	return result
+}
+
+// StepSensoryCues returns the cues of a step, numbered from 1. The recipe's
+// Sensory must be set.
+func StepSensoryCues(recipe *database.Recipe, number int) []string {
+	for _, step := range recipe.Sensory.Steps {
+		if step.Step == number {
+			return step.Cues
+		}
+	}
+	return []string{}
+}
+
+// SensoryConflicts lists what about a recipe a sensory profile avoids:
+// "spice" when it is hotter than the profile allows, and each avoided cue
+// of its steps. The recipe's Sensory must be set.
+func SensoryConflicts(recipe *database.Recipe, profile *database.SensoryProfile) []string {
+	conflicts := []string{}
+	if profile.MaxSpiceLevel != nil && recipe.Sensory.SpiceLevel > *profile.MaxSpiceLevel {
+		conflicts = append(conflicts, "spice")
+	}
+	for _, cue := range recipe.Sensory.Cues {
+		if slices.Contains(profile.AvoidCues, cue) {
+			conflicts = append(conflicts, cue)
+		}
+	}
+	return conflicts
+}
+
+// StepWarning is what to tell a cook before a step: each of its cues the
+// profile avoids, and any smell the profile is averse to that the step
+// mentions. It is empty when there is nothing to warn about.
+func StepWarning(p *i18n.Printer, profile *database.SensoryProfile, text string, cues []string) string {
+	if profile == nil {
+		return ""
+	}
+	warnings := []string{}
+	for _, cue := range cues {
+		if slices.Contains(profile.AvoidCues, cue) {
+			warnings = append(warnings, p.T("sensory.warning."+cue))
+		}
+	}
+	lower := strings.ToLower(text)
+	for _, smell := range profile.SmellAversions {
+		if smell != "" && wordsPattern([]string{smell}).MatchString(lower) {
+			warnings = append(warnings, p.T("sensory.warning.smell", smell))
+		}
+	}
+	return strings.Join(warnings, " ")
+}
+
+// RecipeSensoryView is a recipe's sensory descriptors, with what of them
+// the viewer's sensory profile avoids
+type RecipeSensoryView struct {
+	RecipeID string `json:"recipe_id"`
+	*database.RecipeSensory
+	Avoided []string `json:"avoided"` // "spice" and cues from the viewer's profile
+}
+
+// GetSensory returns a recipe's spice level and the steps that are loud,
+// smelly or spit hot oil
+// @Summary Recipe sensory descriptors
+// @Tags recipes
+// @Produce json
+// @Param id path string true "Recipe ID"
+// @Success 200 {object} RecipeSensoryView
+// @Router /recipes/{id}/sensory [get]
+func (h *Handler) GetSensory(c *gin.Context) {
+	recipe, userID := h.viewRecipe(c)
+	if recipe == nil {
+		return
+	}
+
+	ctx := c.Request.Context()
+	if _, err := AddSensory(ctx, h.db, []*database.Recipe{recipe}); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	profile := sensory.LoadProfile(ctx, h.db, userID)
+	c.JSON(http.StatusOK, RecipeSensoryView{
+		RecipeID:      recipe.ID,
+		RecipeSensory: recipe.Sensory,
+		Avoided:       SensoryConflicts(recipe, profile),
+	})
+}
+
+// recipeSensoryRequest replaces what a recipe is tagged with; leaving the
+// spice level out, or a step, goes back to what the recipe suggests
+type recipeSensoryRequest struct {
+	SpiceLevel *int `json:"spice_level" binding:"omitempty,min=0,max=3"`
+	Steps      []struct {
+		Step int      `json:"step" binding:"min=1"`
+		Cues []string `json:"cues" binding:"max=3"`
+	} `json:"steps" binding:"max=100,dive"`
+}
+
+// UpdateSensory tags a recipe with its spice level and step cues
+// @Summary Tag recipe sensory descriptors
+// @Tags recipes
+// @Accept json
+// @Produce json
+// @Param id path string true "Recipe ID"
+// @Success 200 {object} RecipeSensoryView
+// @Router /recipes/{id}/sensory [put]
+func (h *Handler) UpdateSensory(c *gin.Context) {
+	recipe := h.ownRecipe(c)
+	if recipe == nil {
+		return
+	}
+
+	var req recipeSensoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+	steps := splitSteps(recipe.Instructions)
+	tags := &database.RecipeSensoryTags{
+		RecipeID:   recipe.ID,
+		SpiceLevel: req.SpiceLevel,
+		Steps:      []database.RecipeStepCues{},
+		UpdatedAt:  time.Now(),
+	}
+	for _, step := range req.Steps {
+		if step.Step > len(steps) {
+			apierror.BadRequest(c, fmt.Sprintf("step must be between 1 and %d", len(steps)))
+			return
+		}
+		cues := []string{}
+		for _, cue := range step.Cues {
+			cue = strings.ToLower(strings.TrimSpace(cue))
+			if !sensory.IsCue(cue) {
+				apierror.BadRequest(c, fmt.Sprintf("invalid cue: %s (expected one of %s)", cue, strings.Join(sensory.Cues, ", ")))
+				return
+			}
+			if !slices.Contains(cues, cue) {
+				cues = append(cues, cue)
+			}
+		}
+		sort.Strings(cues)
+		tags.Steps = append(tags.Steps, database.RecipeStepCues{Text: steps[step.Step-1], Cues: cues})
+	}
+
+	ctx := c.Request.Context()
+	var err error
+	if tags.SpiceLevel == nil && len(tags.Steps) == 0 {
+		err = h.db.DeleteRecipeSensoryTags(ctx, recipe.ID)
+	} else {
+		err = h.db.UpsertRecipeSensoryTags(ctx, tags)
+	}
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	h.GetSensory(c)
+}
+
+// filterSensory keeps the recipes that suit the viewer's sensory profile,
+// and no hotter than maxSpice. The recipes' Sensory must be set.
+func (h *Handler) filterSensory(ctx context.Context, userID string, recipes []*database.Recipe, maxSpice int, suitMe bool) []*database.Recipe {
+	var profile *database.SensoryProfile
+	if suitMe {
+		profile = sensory.LoadProfile(ctx, h.db, userID)
+	}
+	kept := make([]*database.Recipe, 0, len(recipes))
+	for _, recipe := range recipes {
+		if recipe.Sensory.SpiceLevel > maxSpice {
+			continue
+		}
+		if profile != nil && len(SensoryConflicts(recipe, profile)) > 0 {
+			continue
+		}
+		kept = append(kept, recipe)
+	}
+	return kept
+}
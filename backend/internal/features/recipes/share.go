@@ -0,0 +1,333 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package recipes
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/fetch"
+	"github.com/rghsoftware/space-food/internal/middleware"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// ShareTokenPrefix marks recipe share tokens so they are recognisable in URLs
+const ShareTokenPrefix = "sfrec_"
+
+// ShareFormat identifies the public JSON representation of a shared
+// recipe; ShareVersion is bumped on incompatible changes
+const (
+	ShareFormat  = "space-food/recipe"
+	ShareVersion = 1
+)
+
+// sharePath is where share links are served, relative to the instance
+const sharePath = "/api/v1/shared/recipes/"
+
+// ErrNotShared is returned when a URL does not serve a shared Space Food recipe
+var ErrNotShared = errors.New("that address is not a Space Food recipe share link")
+
+// SharedRecipe is the public representation of a recipe behind a share
+// link, which other instances import. The recipe uses the same fields as
+// its history.
+type SharedRecipe struct {
+	Format    string          `json:"format"`
+	Version   int             `json:"version"`
+	URL       string          `json:"url"`
+	Author    string          `json:"author,omitempty"`
+	ImageURL  string          `json:"image_url,omitempty"` // relative to URL for images stored on the instance
+	UpdatedAt time.Time       `json:"updated_at"`
+	Recipe    revisionContent `json:"recipe"`
+}
+
+// generateShareToken returns a new share token, the hash to store, and a
+// short display prefix
+func generateShareToken() (token, hash, prefix string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+	token = ShareTokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return token, hashShareToken(token), token[:len(ShareTokenPrefix)+6], nil
+}
+
+// hashShareToken returns the stored form of a share token
+func hashShareToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// shareURL builds the link for a share token
+func (h *Handler) shareURL(token string) string {
+	return h.publicURL + sharePath + token
+}
+
+// authorName credits a recipe's owner by first name and last initial,
+// keeping the rest of their account private
+func authorName(user *database.User) string {
+	name := strings.TrimSpace(user.FirstName)
+	if last := strings.TrimSpace(user.LastName); last != "" {
+		initial, _ := utf8.DecodeRuneInString(last)
+		name = strings.TrimSpace(name + " " + string(initial) + ".")
+	}
+	return name
+}
+
+// ListShares lists the share links of a recipe
+// @Summary List recipe share links
+// @Tags recipes
+// @Produce json
+// @Param id path string true "Recipe ID"
+// @Router /recipes/{id}/shares [get]
+func (h *Handler) ListShares(c *gin.Context) {
+	recipe := h.ownRecipe(c)
+	if recipe == nil {
+		return
+	}
+
+	shares, err := h.db.ListRecipeShares(c.Request.Context(), recipe.ID)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shares)
+}
+
+// CreateShare creates a share link for a recipe. The link is only
+// returned here.
+// @Summary Create recipe share link
+// @Tags recipes
+// @Produce json
+// @Param id path string true "Recipe ID"
+// @Router /recipes/{id}/shares [post]
+func (h *Handler) CreateShare(c *gin.Context) {
+	recipe := h.ownRecipe(c)
+	if recipe == nil {
+		return
+	}
+
+	token, hash, prefix, err := generateShareToken()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	share := database.RecipeShare{
+		ID:        uuid.New().String(),
+		RecipeID:  recipe.ID,
+		UserID:    recipe.UserID,
+		TokenHash: hash,
+		Prefix:    prefix,
+		CreatedAt: time.Now(),
+	}
+	if err := h.db.CreateRecipeShare(c.Request.Context(), &share); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"url":   h.shareURL(token),
+		"share": share,
+	})
+}
+
+// DeleteShare revokes a share link. Recipes already imported elsewhere
+// are not affected.
+// @Summary Delete recipe share link
+// @Tags recipes
+// @Param id path string true "Recipe ID"
+// @Param share path string true "Share ID"
+// @Success 204
+// @Router /recipes/{id}/shares/{share} [delete]
+func (h *Handler) DeleteShare(c *gin.Context) {
+	recipe := h.ownRecipe(c)
+	if recipe == nil {
+		return
+	}
+
+	ctx := c.Request.Context()
+	shares, err := h.db.ListRecipeShares(ctx, recipe.ID)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	for _, share := range shares {
+		if share.ID == c.Param("share") {
+			if err := h.db.DeleteRecipeShare(ctx, share.ID); err != nil {
+				apierror.Respond(c, err)
+				return
+			}
+			c.Status(http.StatusNoContent)
+			return
+		}
+	}
+	apierror.NotFound(c, "share link not found")
+}
+
+// GetShared serves the public representation of a shared recipe
+// @Summary Get shared recipe
+// @Tags recipes
+// @Produce json
+// @Param token path string true "Share token"
+// @Success 200 {object} SharedRecipe
+// @Router /shared/recipes/{token} [get]
+func (h *Handler) GetShared(c *gin.Context) {
+	token := c.Param("token")
+	if !strings.HasPrefix(token, ShareTokenPrefix) {
+		apierror.NotFound(c, "shared recipe not found")
+		return
+	}
+
+	ctx := c.Request.Context()
+	share, err := h.db.GetRecipeShareByHash(ctx, hashShareToken(token))
+	if err != nil {
+		apierror.Lookup(c, err, "shared recipe not found")
+		return
+	}
+	recipe, err := h.db.GetRecipeByID(ctx, share.RecipeID)
+	if err != nil {
+		apierror.Lookup(c, err, "shared recipe not found")
+		return
+	}
+
+	if err := h.db.TouchRecipeShare(ctx, share.ID, time.Now()); err != nil {
+		logger.Ctx(ctx).Warn().Err(err).Str("share_id", share.ID).Msg("Failed to record recipe share use")
+	}
+
+	doc := SharedRecipe{
+		Format:    ShareFormat,
+		Version:   ShareVersion,
+		URL:       h.shareURL(token),
+		UpdatedAt: recipe.UpdatedAt,
+		Recipe:    contentOf(recipe),
+	}
+	if owner, err := h.db.GetUserByID(ctx, share.UserID); err == nil {
+		doc.Author = authorName(owner)
+	}
+	switch {
+	case strings.HasPrefix(recipe.ImageURL, "/"):
+		doc.ImageURL = recipe.ImageURL + "?size=large"
+	case recipe.ImageURL != "":
+		doc.ImageURL = recipe.ImageURL
+	}
+
+	middleware.SetLastModified(c, recipe.UpdatedAt)
+	c.JSON(http.StatusOK, doc)
+}
+
+// FetchShared fetches a recipe from another instance's share link. The
+// recipe's ImageURL is absolute and still points at that instance.
+func (s *Scraper) FetchShared(ctx context.Context, rawURL string) (*database.Recipe, error) {
+	resp, err := s.fetcher.Get(ctx, rawURL, "application/json")
+	var statusErr *fetch.StatusError
+	switch {
+	case errors.Is(err, fetch.ErrContentType):
+		return nil, ErrNotShared
+	case errors.As(err, &statusErr) && (statusErr.Code == http.StatusNotFound || statusErr.Code == http.StatusGone):
+		return nil, fmt.Errorf("%w: it may have been revoked", ErrNotShared)
+	case err != nil:
+		return nil, err
+	}
+
+	var doc SharedRecipe
+	if err := json.Unmarshal(resp.Body, &doc); err != nil || doc.Format != ShareFormat {
+		return nil, ErrNotShared
+	}
+	if doc.Version > ShareVersion {
+		return nil, fmt.Errorf("%w: it uses a newer format (version %d) than this server reads", ErrNotShared, doc.Version)
+	}
+	if strings.TrimSpace(doc.Recipe.Title) == "" {
+		return nil, ErrNoRecipe
+	}
+
+	recipe := &database.Recipe{}
+	doc.Recipe.applyTo(recipe)
+	recipe.Source, recipe.SourceURL = sharedAttribution(&doc, resp)
+	if doc.ImageURL != "" {
+		// Images stored on the instance are given relative to it
+		if ref, err := resp.URL.Parse(doc.ImageURL); err == nil {
+			recipe.ImageURL = ref.String()
+		}
+	}
+	return recipe, nil
+}
+
+// sharedAttribution credits who shared a recipe and the instance it came
+// from, named by the host it was actually fetched from, while keeping the
+// credit for a site the recipe was originally imported from
+func sharedAttribution(doc *SharedRecipe, resp *fetch.Response) (source, sourceURL string) {
+	shared := "shared on " + resp.URL.Host
+	if doc.Author != "" {
+		shared = "shared by " + doc.Author + " on " + resp.URL.Host
+	}
+	if doc.Recipe.Source != "" {
+		source = doc.Recipe.Source + ", " + shared
+	} else {
+		source = strings.ToUpper(shared[:1]) + shared[1:]
+	}
+	return source, resp.URL.String()
+}
+
+// ImportShared creates a recipe from another Space Food instance's share
+// link, reading its JSON representation instead of scraping a page
+// @Summary Import shared recipe
+// @Tags recipes
+// @Accept json
+// @Produce json
+// @Param request body importRequest true "Share link to import"
+// @Success 201 {object} Recipe
+// @Router /recipes/import/shared [post]
+func (h *Handler) ImportShared(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req importRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	recipe, err := h.scraper.FetchShared(c.Request.Context(), req.URL)
+	if errors.Is(err, ErrNotShared) {
+		apierror.BadRequest(c, err.Error())
+		return
+	}
+	if err != nil {
+		respondImportError(c, err)
+		return
+	}
+	h.saveImported(c, user.ID, recipe)
+}
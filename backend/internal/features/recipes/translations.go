@@ -0,0 +1,80 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package recipes
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/api/params"
+	"github.com/rghsoftware/space-food/internal/api/precondition"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/locale"
+)
+
+// TranslationOutdated reports whether the recipe changed after it was
+// translated
+func TranslationOutdated(translation *database.RecipeTranslation, recipe *database.Recipe) bool {
+	return precondition.Version(translation.SourceUpdatedAt) != precondition.Version(recipe.UpdatedAt)
+}
+
+// viewLanguage is the language to show recipes in: lang in the query, or
+// else the user's locale. It is "" for the original alone, and responds
+// 400 when lang isn't a language tag.
+func (h *Handler) viewLanguage(c *gin.Context, query *params.Parser, userID string) string {
+	lang := query.String("lang")
+	if lang == "" {
+		return locale.Preferred(c.Request.Context(), h.db, userID)
+	}
+	language, ok := locale.Normalize(lang)
+	if !ok {
+		query.Fail("lang", "bcp47", "")
+	}
+	return language
+}
+
+// AddTranslations sets each recipe's translation into the language, when
+// it has one. It returns when any of them last changed, for Last-Modified.
+func AddTranslations(ctx context.Context, db database.Database, language string, recipes []*database.Recipe) (time.Time, error) {
+	var changed time.Time
+	if language == "" || len(recipes) == 0 {
+		return changed, nil
+	}
+	ids := make([]string, len(recipes))
+	for i, recipe := range recipes {
+		ids[i] = recipe.ID
+	}
+	translations, err := db.ListRecipeTranslationsIn(ctx, ids, language)
+	if err != nil {
+		return changed, err
+	}
+	for _, recipe := range recipes {
+		translation, ok := translations[recipe.ID]
+		if !ok {
+			continue
+		}
+		translation.Outdated = TranslationOutdated(translation, recipe)
+		recipe.Translation = translation
+		if translation.UpdatedAt.After(changed) {
+			changed = translation.UpdatedAt
+		}
+	}
+	return changed, nil
+}
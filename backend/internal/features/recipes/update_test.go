@@ -0,0 +1,116 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package recipes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/api/precondition"
+	"github.com/rghsoftware/space-food/internal/auth"
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// recipeStore keeps a single recipe, saving it as the database would
+type recipeStore struct {
+	database.Database
+	recipe       database.Recipe
+	beforeUpdate func() // runs once, as the first save starts
+}
+
+func (s *recipeStore) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (s *recipeStore) GetRecipeByID(ctx context.Context, id string) (*database.Recipe, error) {
+	recipe := s.recipe
+	return &recipe, nil
+}
+
+func (s *recipeStore) UpdateRecipeIfUnchanged(ctx context.Context, recipe *database.Recipe, savedAt time.Time) (bool, error) {
+	if hook := s.beforeUpdate; hook != nil {
+		s.beforeUpdate = nil
+		hook()
+	}
+	if !s.recipe.UpdatedAt.Equal(savedAt) {
+		return false, nil
+	}
+	s.recipe = *recipe
+	return true, nil
+}
+
+func (s *recipeStore) ListRecipeRevisions(ctx context.Context, recipeID string, limit int) ([]*database.RecipeRevision, error) {
+	return nil, nil
+}
+
+func (s *recipeStore) CreateRecipeRevision(ctx context.Context, revision *database.RecipeRevision) error {
+	return nil
+}
+
+func TestUpdateRecipeRace(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	user := &auth.User{ID: "owner"}
+	loaded := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := &recipeStore{recipe: database.Recipe{ID: "recipe", UserID: user.ID, Title: "Soup", UpdatedAt: loaded}}
+	h := &Handler{db: store}
+
+	// Both edits were made to the version loaded, so both pass the check
+	// before either is saved
+	update := func(title string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "id", Value: "recipe"}}
+		c.Set("user", user)
+		c.Request = httptest.NewRequest(http.MethodPut, "/recipes/recipe", strings.NewReader(`{"Title":"`+title+`"}`))
+		c.Request.Header.Set("If-Match", `"`+precondition.Version(loaded)+`"`)
+		h.UpdateRecipe(c)
+		return w
+	}
+	var second *httptest.ResponseRecorder
+	store.beforeUpdate = func() { second = update("Stew") }
+	first := update("Broth")
+
+	if second.Code != http.StatusOK {
+		t.Fatalf("the edit saved first got %d: %s", second.Code, second.Body)
+	}
+	if first.Code != http.StatusConflict {
+		t.Fatalf("the edit saved second got %d, want %d: %s", first.Code, http.StatusConflict, first.Body)
+	}
+	var body struct {
+		Error struct {
+			Code    string
+			Details precondition.Conflict
+		}
+	}
+	if err := json.Unmarshal(first.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decoding the conflict: %v", err)
+	}
+	if body.Error.Code != "edit_conflict" || body.Error.Details.Version != precondition.Version(store.recipe.UpdatedAt) {
+		t.Errorf("conflict = %+v, want edit_conflict at the version saved first", body.Error)
+	}
+	if store.recipe.Title != "Stew" {
+		t.Errorf("saved title = %q, want the first save kept", store.recipe.Title)
+	}
+}
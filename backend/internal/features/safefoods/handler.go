@@ -0,0 +1,246 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package safefoods
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/authz"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/middleware"
+)
+
+// Handler handles safe food HTTP requests
+type Handler struct {
+	db database.Database
+}
+
+// NewHandler creates a new safe foods handler
+func NewHandler(db database.Database) *Handler {
+	return &Handler{
+		db: db,
+	}
+}
+
+// RegisterRoutes registers the current user's safe food routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("", h.ListSafeFoods)
+	router.POST("", h.CreateSafeFood)
+	router.PUT("/:id", h.UpdateSafeFood)
+	router.DELETE("/:id", h.DeleteSafeFood)
+}
+
+// ListSafeFoods lists the authenticated user's safe foods
+// @Summary List my safe foods
+// @Tags safe-foods
+// @Produce json
+// @Router /me/safe-foods [get]
+func (h *Handler) ListSafeFoods(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	foods, err := h.db.ListSafeFoods(c.Request.Context(), user.ID)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, foods)
+}
+
+// CreateSafeFood marks a food as safe. Safe foods are protected unless
+// protected is set to false.
+// @Summary Add safe food
+// @Tags safe-foods
+// @Accept json
+// @Produce json
+// @Router /me/safe-foods [post]
+func (h *Handler) CreateSafeFood(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req struct {
+		Name      string `json:"name"`
+		RecipeID  string `json:"recipe_id"`
+		Protected *bool  `json:"protected"`
+		Notes     string `json:"notes"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	now := time.Now()
+	food := database.SafeFood{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		Name:      NormalizeName(req.Name),
+		Protected: req.Protected == nil || *req.Protected,
+		Notes:     req.Notes,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if req.RecipeID != "" {
+		recipe, err := h.db.GetRecipeByID(c.Request.Context(), req.RecipeID)
+		if err != nil || !authz.Owns(user.ID, recipe.UserID) {
+			apierror.BadRequest(c, "recipe not found")
+			return
+		}
+		food.RecipeID = &recipe.ID
+		if food.Name == "" {
+			food.Name = NormalizeName(recipe.Title)
+		}
+	}
+	if food.Name == "" {
+		apierror.BadRequest(c, "name or recipe_id is required")
+		return
+	}
+
+	if !h.nameAvailable(c, user.ID, food.Name, "") {
+		return
+	}
+
+	if err := h.db.CreateSafeFood(c.Request.Context(), &food); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, food)
+}
+
+// UpdateSafeFood renames a safe food or changes its protected status
+// @Summary Update safe food
+// @Tags safe-foods
+// @Accept json
+// @Produce json
+// @Router /me/safe-foods/{id} [put]
+func (h *Handler) UpdateSafeFood(c *gin.Context) {
+	food, ok := h.ownedSafeFood(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Name      *string `json:"name"`
+		Protected *bool   `json:"protected"`
+		Notes     *string `json:"notes"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	if req.Name != nil {
+		name := NormalizeName(*req.Name)
+		if name == "" {
+			apierror.BadRequest(c, "name cannot be empty")
+			return
+		}
+		if name != food.Name && !h.nameAvailable(c, food.UserID, name, food.ID) {
+			return
+		}
+		food.Name = name
+	}
+	if req.Protected != nil {
+		food.Protected = *req.Protected
+	}
+	if req.Notes != nil {
+		food.Notes = *req.Notes
+	}
+	food.UpdatedAt = time.Now()
+
+	if err := h.db.UpdateSafeFood(c.Request.Context(), food); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, food)
+}
+
+// DeleteSafeFood removes a food from the safe foods list
+// @Summary Delete safe food
+// @Tags safe-foods
+// @Param id path string true "Safe food ID"
+// @Success 204
+// @Router /me/safe-foods/{id} [delete]
+func (h *Handler) DeleteSafeFood(c *gin.Context) {
+	food, ok := h.ownedSafeFood(c)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteSafeFood(c.Request.Context(), food.ID); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// nameAvailable checks that the user has no other safe food with the name,
+// writing an error response when they do
+func (h *Handler) nameAvailable(c *gin.Context, userID, name, exceptID string) bool {
+	foods, err := h.db.ListSafeFoods(c.Request.Context(), userID)
+	if err != nil {
+		apierror.Respond(c, err)
+		return false
+	}
+
+	for _, food := range foods {
+		if food.Name == name && food.ID != exceptID {
+			apierror.Conflict(c, "this food is already on your safe foods list")
+			return false
+		}
+	}
+	return true
+}
+
+// ownedSafeFood loads the safe food named in the path, writing an error
+// response unless it belongs to the authenticated user
+func (h *Handler) ownedSafeFood(c *gin.Context) (*database.SafeFood, bool) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return nil, false
+	}
+
+	food, err := h.db.GetSafeFoodByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierror.Lookup(c, err, "safe food not found")
+		return nil, false
+	}
+
+	if !authz.Owns(user.ID, food.UserID) {
+		apierror.Forbidden(c, "forbidden")
+		return nil, false
+	}
+
+	return food, true
+}
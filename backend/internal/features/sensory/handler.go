@@ -0,0 +1,165 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package sensory
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/middleware"
+)
+
+// Handler handles sensory profile HTTP requests
+type Handler struct {
+	db database.Database
+}
+
+// NewHandler creates a new sensory profile handler
+func NewHandler(db database.Database) *Handler {
+	return &Handler{
+		db: db,
+	}
+}
+
+// RegisterRoutes registers sensory profile routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("", h.GetProfile)
+	router.PUT("", h.UpdateProfile)
+	router.DELETE("", h.DeleteProfile)
+}
+
+// GetProfile returns the authenticated user's sensory profile
+// @Summary Get sensory profile
+// @Tags sensory-profile
+// @Produce json
+// @Router /me/sensory-profile [get]
+func (h *Handler) GetProfile(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	c.JSON(http.StatusOK, LoadProfile(c.Request.Context(), h.db, user.ID))
+}
+
+// UpdateProfile replaces the authenticated user's sensory profile
+// @Summary Update sensory profile
+// @Tags sensory-profile
+// @Accept json
+// @Produce json
+// @Router /me/sensory-profile [put]
+func (h *Handler) UpdateProfile(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req struct {
+		AvoidTextures         []string `json:"avoid_textures"`
+		PreferredTemperatures []string `json:"preferred_temperatures"`
+		SmellAversions        []string `json:"smell_aversions"`
+		MaxSpiceLevel         *int     `json:"max_spice_level" binding:"omitempty,min=0,max=3"`
+		AvoidCues             []string `json:"avoid_cues"`
+		EatingStyle           string   `json:"eating_style" binding:"omitempty,oneof=cutlery finger_food no_preference"`
+		Notes                 string   `json:"notes"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	temperatures := normalizeList(req.PreferredTemperatures)
+	for _, t := range temperatures {
+		if !IsTemperature(t) {
+			apierror.BadRequest(c, fmt.Sprintf("invalid temperature: %s", t))
+			return
+		}
+	}
+	cues := normalizeList(req.AvoidCues)
+	for _, cue := range cues {
+		if !IsCue(cue) {
+			apierror.BadRequest(c, fmt.Sprintf("invalid cue: %s (expected one of %s)", cue, strings.Join(Cues, ", ")))
+			return
+		}
+	}
+	if req.EatingStyle == "" {
+		req.EatingStyle = EatingStyleNoPreference
+	}
+
+	profile := database.SensoryProfile{
+		UserID:                user.ID,
+		AvoidTextures:         normalizeList(req.AvoidTextures),
+		PreferredTemperatures: temperatures,
+		SmellAversions:        normalizeList(req.SmellAversions),
+		MaxSpiceLevel:         req.MaxSpiceLevel,
+		AvoidCues:             cues,
+		EatingStyle:           req.EatingStyle,
+		Notes:                 req.Notes,
+		UpdatedAt:             time.Now(),
+	}
+
+	if err := h.db.UpsertSensoryProfile(c.Request.Context(), &profile); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// DeleteProfile clears the authenticated user's sensory profile
+// @Summary Delete sensory profile
+// @Tags sensory-profile
+// @Success 204
+// @Router /me/sensory-profile [delete]
+func (h *Handler) DeleteProfile(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	if err := h.db.DeleteSensoryProfile(c.Request.Context(), user.ID); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// normalizeList lowercases and trims entries, dropping blanks and duplicates
+func normalizeList(values []string) []string {
+	result := []string{}
+	seen := map[string]bool{}
+	for _, v := range values {
+		v = strings.ToLower(strings.TrimSpace(v))
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		result = append(result, v)
+	}
+	return result
+}
@@ -0,0 +1,132 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package sensory
+
+import (
+	"context"
+	"strings"
+
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// Serving temperatures
+const (
+	TemperatureHot  = "hot"
+	TemperatureWarm = "warm"
+	TemperatureRoom = "room_temperature"
+	TemperatureCold = "cold"
+)
+
+// Eating styles
+const (
+	EatingStyleCutlery      = "cutlery"
+	EatingStyleFingerFood   = "finger_food"
+	EatingStyleNoPreference = "no_preference"
+)
+
+// Spice levels, from not spicy at all to hot
+const (
+	SpiceNone   = 0
+	SpiceMild   = 1
+	SpiceMedium = 2
+	SpiceHot    = 3
+)
+
+// Cues are what a cooking step can be hard on the senses for
+const (
+	CueStrongSmell = "strong_smell" // e.g. frying fish or boiling vinegar
+	CueLoud        = "loud"         // e.g. a blender or food processor
+	CueSplatter    = "splatter"     // hot oil that can spit, e.g. deep frying
+)
+
+// Cues lists every step cue
+var Cues = []string{CueLoud, CueSplatter, CueStrongSmell}
+
+// temperatureTags are the recipe tags that tell how a dish is served
+var temperatureTags = map[string][]string{
+	TemperatureHot:  {"hot", "soup", "stew"},
+	TemperatureWarm: {"warm"},
+	TemperatureRoom: {"room temperature", "no-cook", "no cook"},
+	TemperatureCold: {"cold", "chilled", "no-cook", "no cook"},
+}
+
+// fingerFoodTags mark recipes that can be eaten without cutlery
+var fingerFoodTags = []string{"finger food", "finger-food", "handheld", "sandwich", "wrap"}
+
+// IsTemperature reports whether t is a known serving temperature
+func IsTemperature(t string) bool {
+	_, ok := temperatureTags[t]
+	return ok
+}
+
+// IsCue reports whether c is a known step cue
+func IsCue(c string) bool {
+	for _, cue := range Cues {
+		if cue == c {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadProfile fetches a user's sensory profile, falling back to an empty
+// profile when none has been saved
+func LoadProfile(ctx context.Context, db database.Database, userID string) *database.SensoryProfile {
+	profile, err := db.GetSensoryProfile(ctx, userID)
+	if err != nil {
+		return &database.SensoryProfile{
+			UserID:                userID,
+			AvoidTextures:         []string{},
+			PreferredTemperatures: []string{},
+			SmellAversions:        []string{},
+			AvoidCues:             []string{},
+			EatingStyle:           EatingStyleNoPreference,
+		}
+	}
+	return profile
+}
+
+// ServedAtPreferred reports whether a recipe is tagged with one of the
+// profile's preferred serving temperatures
+func ServedAtPreferred(recipe *database.Recipe, profile *database.SensoryProfile) bool {
+	for _, t := range profile.PreferredTemperatures {
+		if hasTag(recipe, temperatureTags[t]...) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFingerFood reports whether a recipe is tagged as eaten by hand
+func IsFingerFood(recipe *database.Recipe) bool {
+	return hasTag(recipe, fingerFoodTags...)
+}
+
+// hasTag reports whether a recipe has any of the tags or categories
+func hasTag(recipe *database.Recipe, tags ...string) bool {
+	for _, label := range append(append([]string{}, recipe.Tags...), recipe.Categories...) {
+		label = strings.ToLower(strings.TrimSpace(label))
+		for _, tag := range tags {
+			if label == tag {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,217 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package setup
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/config"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/pkg/logger"
+	"golang.org/x/crypto/hkdf"
+)
+
+// aiSetting is the instance setting holding the AI provider chosen in the
+// wizard
+const aiSetting = "setup_ai"
+
+// sealInfo separates the key sealing API keys from other uses of the
+// server secret
+const sealInfo = "space-food setup ai api key"
+
+// errUnsealable is returned for a sealed API key the server secret cannot
+// open, such as after auth.jwtsecret has changed
+var errUnsealable = errors.New("the saved AI API key cannot be decrypted with the current auth.jwtsecret")
+
+// AIChoice is the AI provider chosen in the wizard, with the settings it
+// needs. It is laid over the config file, so the chosen provider is used
+// even when the file enables another.
+type AIChoice struct {
+	Provider string `json:"provider" binding:"required,oneof=ollama openai gemini claude"`
+	Host     string `json:"host,omitempty"`     // Ollama's address
+	APIKey   string `json:"api_key,omitempty"`  // for hosted providers
+	BaseURL  string `json:"base_url,omitempty"` // for servers that speak the OpenAI API
+	Model    string `json:"model,omitempty"`    // the provider's default model when empty
+}
+
+// storedAI is an AIChoice as kept in the instance setting. The API key is
+// sealed with the server secret, so the setting, and the backups and
+// exports that copy it, never hold it in the clear.
+type storedAI struct {
+	Provider     string `json:"provider"`
+	Host         string `json:"host,omitempty"`
+	BaseURL      string `json:"base_url,omitempty"`
+	Model        string `json:"model,omitempty"`
+	SealedAPIKey string `json:"sealed_api_key,omitempty"`
+	APIKey       string `json:"api_key,omitempty"` // only in settings saved before keys were sealed
+}
+
+// apply enables the chosen provider in cfg and makes it the default
+func (a AIChoice) apply(cfg *config.Config) {
+	cfg.AI.DefaultProvider = a.Provider
+	switch a.Provider {
+	case "ollama":
+		cfg.AI.Ollama.Enabled = true
+		setIf(&cfg.AI.Ollama.Host, a.Host)
+		setIf(&cfg.AI.Ollama.Model, a.Model)
+	case "openai":
+		cfg.AI.OpenAI.Enabled = true
+		setIf(&cfg.AI.OpenAI.APIKey, a.APIKey)
+		setIf(&cfg.AI.OpenAI.BaseURL, a.BaseURL)
+		setIf(&cfg.AI.OpenAI.Model, a.Model)
+	case "gemini":
+		cfg.AI.Gemini.Enabled = true
+		setIf(&cfg.AI.Gemini.APIKey, a.APIKey)
+		setIf(&cfg.AI.Gemini.Model, a.Model)
+	case "claude":
+		cfg.AI.Claude.Enabled = true
+		setIf(&cfg.AI.Claude.APIKey, a.APIKey)
+		setIf(&cfg.AI.Claude.Model, a.Model)
+	}
+}
+
+// setIf replaces a setting from the file with one given in the wizard
+func setIf(setting *string, value string) {
+	if value != "" {
+		*setting = value
+	}
+}
+
+// validate checks the choice leaves the provider usable, with the
+// configuration's own error
+func (a AIChoice) validate(cfg *config.Config) error {
+	probe := *cfg
+	a.apply(&probe)
+	return probe.Validate()
+}
+
+// saveAI stores the choice with its API key sealed
+func saveAI(ctx context.Context, db database.Database, secret string, choice AIChoice) error {
+	stored := storedAI{Provider: choice.Provider, Host: choice.Host, BaseURL: choice.BaseURL, Model: choice.Model}
+	if choice.APIKey != "" {
+		sealed, err := seal(secret, choice.APIKey)
+		if err != nil {
+			return err
+		}
+		stored.SealedAPIKey = sealed
+	}
+	value, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+	return db.SetInstanceSetting(ctx, aiSetting, string(value))
+}
+
+// RestoreAI lays the AI provider chosen in the wizard, if any, over the
+// configuration. Run at startup, before features read the AI settings. A
+// key saved in the clear by an earlier version is sealed in place.
+func RestoreAI(ctx context.Context, db database.Database, live *config.Live) error {
+	value, err := db.GetInstanceSetting(ctx, aiSetting)
+	if apierror.IsNotFound(err) || err == nil && value == "" {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var stored storedAI
+	if err := json.Unmarshal([]byte(value), &stored); err != nil {
+		return fmt.Errorf("invalid %s setting: %w", aiSetting, err)
+	}
+
+	secret := live.Get().Auth.JWTSecret
+	choice := AIChoice{Provider: stored.Provider, Host: stored.Host, BaseURL: stored.BaseURL, Model: stored.Model, APIKey: stored.APIKey}
+	switch {
+	case stored.APIKey != "":
+		if err := saveAI(ctx, db, secret, choice); err != nil {
+			return fmt.Errorf("failed to seal the %s setting: %w", aiSetting, err)
+		}
+	case stored.SealedAPIKey != "":
+		choice.APIKey, err = unseal(secret, stored.SealedAPIKey)
+		if err != nil {
+			// The provider stays chosen; the key from the config file, if
+			// any, is used instead
+			logger.Ctx(ctx).Warn().Err(err).Str("provider", choice.Provider).Msg("Ignoring the AI API key saved by setup; set it in the config file instead")
+		}
+	}
+	live.SetOverlay(choice.apply)
+	return nil
+}
+
+// sealingKey derives the key that seals API keys from the server secret
+func sealingKey(secret string) ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, []byte(secret), nil, []byte(sealInfo)), key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// seal encrypts plaintext with AES-GCM under the server secret, returning
+// the nonce and ciphertext in base64
+func seal(secret, plaintext string) (string, error) {
+	gcm, err := sealer(secret)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(plaintext), nil)), nil
+}
+
+// unseal reverses seal
+func unseal(secret, sealed string) (string, error) {
+	gcm, err := sealer(secret)
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(data) < gcm.NonceSize() {
+		return "", errUnsealable
+	}
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", errUnsealable
+	}
+	return string(plaintext), nil
+}
+
+// sealer is the AES-GCM cipher keyed from the server secret
+func sealer(secret string) (cipher.AEAD, error) {
+	key, err := sealingKey(secret)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,95 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package setup
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/rghsoftware/space-food/internal/config"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/database/dbtest"
+)
+
+const apiKey = "sk-test-0123456789"
+
+func newLive(secret string) *config.Live {
+	cfg := &config.Config{}
+	cfg.Auth.JWTSecret = secret
+	return config.NewLive(cfg)
+}
+
+func TestSavedAIKeyIsSealed(t *testing.T) {
+	tests := []struct {
+		name    string
+		save    func(ctx context.Context, db database.Database) error
+		secret  string // the secret at restore
+		wantKey string
+	}{
+		{
+			name: "sealed",
+			save: func(ctx context.Context, db database.Database) error {
+				return saveAI(ctx, db, "server-secret", AIChoice{Provider: "openai", APIKey: apiKey})
+			},
+			secret:  "server-secret",
+			wantKey: apiKey,
+		},
+		{
+			name: "saved in the clear by an earlier version",
+			save: func(ctx context.Context, db database.Database) error {
+				return db.SetInstanceSetting(ctx, aiSetting, `{"provider":"openai","api_key":"`+apiKey+`"}`)
+			},
+			secret:  "server-secret",
+			wantKey: apiKey,
+		},
+		{
+			name: "server secret changed",
+			save: func(ctx context.Context, db database.Database) error {
+				return saveAI(ctx, db, "old-secret", AIChoice{Provider: "openai", APIKey: apiKey})
+			},
+			secret:  "server-secret",
+			wantKey: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbtest.Run(t, func(ctx context.Context, db database.Database) {
+				if err := tt.save(ctx, db); err != nil {
+					t.Fatalf("save: %v", err)
+				}
+				live := newLive(tt.secret)
+				if err := RestoreAI(ctx, db, live); err != nil {
+					t.Fatalf("RestoreAI: %v", err)
+				}
+
+				cfg := live.Get()
+				if cfg.AI.DefaultProvider != "openai" || !cfg.AI.OpenAI.Enabled {
+					t.Errorf("provider = %q, enabled %v, want openai", cfg.AI.DefaultProvider, cfg.AI.OpenAI.Enabled)
+				}
+				if cfg.AI.OpenAI.APIKey != tt.wantKey {
+					t.Errorf("api key = %q, want %q", cfg.AI.OpenAI.APIKey, tt.wantKey)
+				}
+				if setting, err := db.GetInstanceSetting(ctx, aiSetting); err != nil || strings.Contains(setting, apiKey) {
+					t.Errorf("setting = %s, %v: the key is stored in the clear", setting, err)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,244 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package setup is the first-run wizard. On an instance without accounts
+// it creates the first administrator and sets the AI provider and
+// registration mode in one step, so nothing has to be configured before
+// the first login. Completing it takes the one-time token the server logs
+// at startup, and once an account exists it is locked.
+package setup
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/audit"
+	"github.com/rghsoftware/space-food/internal/auth"
+	"github.com/rghsoftware/space-food/internal/config"
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// providers are the AI providers the wizard offers
+var providers = []string{"ollama", "openai", "gemini", "claude"}
+
+// errCompleted is returned once the instance has an account
+var errCompleted = apierror.New(http.StatusConflict, apierror.CodeConflict, "setup has already been completed")
+
+// errPasswordLoginDisabled is returned when the administrator setup would
+// create could never sign in
+var errPasswordLoginDisabled = apierror.New(http.StatusForbidden, apierror.CodeForbidden,
+	"password login is disabled, so setup cannot create the administrator; run spacefood user create --admin with the email your identity provider verifies, then sign in with single sign-on")
+
+// Handler serves the setup wizard
+type Handler struct {
+	db           database.Database
+	live         *config.Live
+	authProvider auth.AuthProvider
+
+	mu sync.Mutex // lets only one request complete setup
+}
+
+// NewHandler creates a new setup handler
+func NewHandler(db database.Database, live *config.Live, authProvider auth.AuthProvider) *Handler {
+	return &Handler{
+		db:           db,
+		live:         live,
+		authProvider: authProvider,
+	}
+}
+
+// RegisterRoutes registers setup routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("", h.GetSetup)
+	router.POST("", h.CompleteSetup)
+}
+
+// CompleteRequest is what the wizard asks for
+type CompleteRequest struct {
+	Token string `json:"token" binding:"required"` // logged by the server at startup
+	Admin struct {
+		Email     string `json:"email" binding:"required,email"`
+		Password  string `json:"password" binding:"required"`
+		FirstName string `json:"first_name"`
+		LastName  string `json:"last_name"`
+	} `json:"admin" binding:"required"`
+	AI               *AIChoice `json:"ai"` // keeps the config file's AI settings when absent
+	RegistrationMode string    `json:"registration_mode" binding:"omitempty,oneof=open invite_only closed"`
+}
+
+// GetSetup reports whether the instance still needs setting up and, while
+// it does, the choices the wizard offers
+// @Summary Get setup status
+// @Tags setup
+// @Produce json
+// @Router /setup [get]
+func (h *Handler) GetSetup(c *gin.Context) {
+	required, err := h.required(c.Request.Context())
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	if !required {
+		c.JSON(http.StatusOK, gin.H{"required": false})
+		return
+	}
+
+	cfg := h.live.Get()
+	c.JSON(http.StatusOK, gin.H{
+		"required": true,
+		"ai": gin.H{
+			"providers":        providers,
+			"configured":       cfg.AI.EnabledProviders(),
+			"default_provider": cfg.AI.ActiveProvider(),
+		},
+		"registration_mode":  registrationMode(cfg),
+		"registration_modes": []string{auth.RegistrationOpen, auth.RegistrationInviteOnly, auth.RegistrationClosed},
+	})
+}
+
+// CompleteSetup creates the first administrator and saves the instance
+// options chosen, then locks the wizard
+// @Summary Complete setup
+// @Tags setup
+// @Accept json
+// @Produce json
+// @Param request body CompleteRequest true "Setup choices"
+// @Router /setup [post]
+func (h *Handler) CompleteSetup(c *gin.Context) {
+	provider, ok := h.authProvider.(auth.AdminProvider)
+	if !ok {
+		apierror.Abort(c, apierror.New(http.StatusNotImplemented, apierror.CodeNotImplemented, "the auth provider does not support creating accounts"))
+		return
+	}
+
+	var req CompleteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+	if cfg := h.live.Get(); cfg.Auth.OIDC.Enabled && cfg.Auth.DisablePasswordLogin {
+		apierror.Abort(c, errPasswordLoginDisabled)
+		return
+	}
+	if req.AI != nil {
+		if err := req.AI.validate(h.live.Get()); err != nil {
+			apierror.BadRequest(c, err.Error())
+			return
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ctx := c.Request.Context()
+	var user *auth.User
+	err := h.db.WithTx(ctx, func(ctx context.Context) error {
+		required, err := h.required(ctx)
+		if err != nil {
+			return err
+		}
+		if !required {
+			return errCompleted
+		}
+		valid, err := checkToken(ctx, h.db, req.Token)
+		if err != nil {
+			return err
+		}
+		if !valid {
+			return errInvalidToken
+		}
+
+		if req.RegistrationMode != "" {
+			if err := h.db.SetInstanceSetting(ctx, auth.RegistrationModeSetting, req.RegistrationMode); err != nil {
+				return err
+			}
+		}
+		if req.AI != nil {
+			if err := saveAI(ctx, h.db, h.live.Get().Auth.JWTSecret, *req.AI); err != nil {
+				return err
+			}
+		}
+
+		user, err = provider.CreateUser(ctx, auth.RegisterRequest{
+			Email:     req.Admin.Email,
+			Password:  req.Admin.Password,
+			FirstName: req.Admin.FirstName,
+			LastName:  req.Admin.LastName,
+		}, true)
+		if err != nil {
+			return err
+		}
+		return h.db.SetInstanceSetting(ctx, tokenSetting, "")
+	})
+	switch {
+	case errors.Is(err, auth.ErrWeakPassword):
+		apierror.BadRequest(c, auth.ErrWeakPassword.Error())
+		return
+	case errors.Is(err, auth.ErrUserAlreadyExists):
+		apierror.Conflict(c, "an account with this email already exists")
+		return
+	case err != nil:
+		apierror.Respond(c, err)
+		return
+	}
+
+	if req.AI != nil {
+		h.live.SetOverlay(req.AI.apply)
+	}
+	details := map[string]any{}
+	if req.RegistrationMode != "" {
+		details["registration_mode"] = req.RegistrationMode
+	}
+	if req.AI != nil {
+		details["ai_provider"] = req.AI.Provider
+	}
+	audit.Record(c, audit.Entry{
+		Action:     audit.SetupCompleted,
+		UserID:     user.ID,
+		TargetType: "user",
+		TargetID:   user.ID,
+		Details:    details,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"user": user,
+	})
+}
+
+// required reports whether the instance is still to be set up: it is until
+// it has an account
+func (h *Handler) required(ctx context.Context) (bool, error) {
+	count, err := h.db.CountUsers(ctx)
+	if err != nil {
+		return false, err
+	}
+	return count == 0, nil
+}
+
+// registrationMode is the mode the instance starts in before the wizard
+// changes it
+func registrationMode(cfg *config.Config) string {
+	if cfg.Auth.Registration == "" {
+		return auth.RegistrationOpen
+	}
+	return cfg.Auth.Registration
+}
@@ -0,0 +1,90 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package setup
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// tokenSetting is the instance setting holding a hash of the setup token
+const tokenSetting = "setup_token"
+
+// errInvalidToken is returned when setup is attempted without the token
+// from the server log
+var errInvalidToken = apierror.New(http.StatusForbidden, apierror.CodeForbidden, "the setup token is missing or wrong; it is printed in the server log")
+
+// IssueToken gives an instance without accounts a new one-time setup token
+// and logs it. Completing setup needs the token, so a freshly exposed
+// instance cannot be claimed by whoever reaches it first. Each start
+// replaces the token; once an account exists it does nothing.
+func IssueToken(ctx context.Context, db database.Database) error {
+	token, err := issueToken(ctx, db)
+	if err != nil || token == "" {
+		return err
+	}
+	logger.Ctx(ctx).Warn().Str("setup_token", token).Msg("This instance has not been set up; complete setup in the app with this token")
+	return nil
+}
+
+// issueToken stores a new setup token and returns it, or returns "" when
+// the instance is already set up
+func issueToken(ctx context.Context, db database.Database) (string, error) {
+	count, err := db.CountUsers(ctx)
+	if err != nil || count > 0 {
+		return "", err
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate setup token: %w", err)
+	}
+	token := hex.EncodeToString(b)
+	if err := db.SetInstanceSetting(ctx, tokenSetting, hashToken(token)); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// checkToken reports whether token is the one issued at startup
+func checkToken(ctx context.Context, db database.Database, token string) (bool, error) {
+	stored, err := db.GetInstanceSetting(ctx, tokenSetting)
+	if apierror.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return stored != "" && subtle.ConstantTimeCompare([]byte(hashToken(token)), []byte(stored)) == 1, nil
+}
+
+// hashToken returns the stored form of a setup token
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
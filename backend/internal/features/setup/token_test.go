@@ -0,0 +1,64 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package setup
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/database/dbtest"
+)
+
+func TestSetupToken(t *testing.T) {
+	dbtest.Run(t, func(ctx context.Context, db database.Database) {
+		if valid, err := checkToken(ctx, db, ""); err != nil || valid {
+			t.Fatalf("checkToken before any token was issued = %v, %v, want refused", valid, err)
+		}
+
+		first, err := issueToken(ctx, db)
+		if err != nil || first == "" {
+			t.Fatalf("issueToken = %q, %v, want a token", first, err)
+		}
+		token, err := issueToken(ctx, db)
+		if err != nil || token == "" {
+			t.Fatalf("issueToken = %q, %v, want a token", token, err)
+		}
+
+		for _, tt := range []struct {
+			token string
+			want  bool
+		}{
+			{token, true},
+			{first, false}, // replaced at the next start
+			{"", false},
+			{token + "0", false},
+		} {
+			if valid, err := checkToken(ctx, db, tt.token); err != nil || valid != tt.want {
+				t.Errorf("checkToken(%q) = %v, %v, want %v", tt.token, valid, err, tt.want)
+			}
+		}
+
+		// An instance with an account is set up and gets no token
+		dbtest.User(t, ctx, db)
+		if token, err := issueToken(ctx, db); err != nil || token != "" {
+			t.Errorf("issueToken after setup = %q, %v, want none", token, err)
+		}
+	})
+}
@@ -20,27 +20,38 @@ package shopping_list
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/api/params"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/authz"
 	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/events"
+	"github.com/rghsoftware/space-food/internal/features/budget"
 	"github.com/rghsoftware/space-food/internal/middleware"
+	"github.com/rghsoftware/space-food/pkg/logger"
 )
 
 // Handler handles shopping list HTTP requests
 type Handler struct {
-	db database.Database
+	db     database.Database
+	events *events.Bus
 }
 
 // NewHandler creates a new shopping list handler
-func NewHandler(db database.Database) *Handler {
+func NewHandler(db database.Database, bus *events.Bus) *Handler {
 	return &Handler{
-		db: db,
+		db:     db,
+		events: bus,
 	}
 }
 
 // RegisterRoutes registers shopping list routes
 func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
 	router.GET("", h.ListShoppingListItems)
+	router.GET("/aisles", h.ListAisles)
+	router.GET("/store-templates", h.ListStoreTemplates)
 	router.GET("/:id", h.GetShoppingListItem)
 	router.POST("", h.CreateShoppingListItem)
 	router.PUT("/:id", h.UpdateShoppingListItem)
@@ -52,35 +63,74 @@ func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
 func (h *Handler) ListShoppingListItems(c *gin.Context) {
 	user, ok := middleware.GetUserFromContext(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	query := params.Query(c)
+	page := query.Page(200)
+	store := query.String("store")
+	if !query.Valid() {
 		return
 	}
 
 	filter := database.ShoppingListFilter{
 		UserID: user.ID,
-		Limit:  200,
-		Offset: 0,
+		Limit:  page.Limit,
+		Offset: page.Offset,
+	}
+
+	ctx := c.Request.Context()
+	items, err := h.db.ListShoppingListItems(ctx, filter)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
 	}
 
-	items, err := h.db.ListShoppingListItems(c.Request.Context(), filter)
+	// Walk the store in aisle order when the household has a layout
+	layout, err := h.userLayout(ctx, user.ID, store)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Respond(c, err)
 		return
 	}
+	if layout == nil && store != "" {
+		apierror.NotFound(c, "store layout not found")
+		return
+	}
+	if layout != nil {
+		SortByAisle(items, layout)
+	}
 
 	c.JSON(http.StatusOK, items)
 }
 
 // GetShoppingListItem retrieves a single shopping list item by ID
 func (h *Handler) GetShoppingListItem(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
 	id := c.Param("id")
 
 	item, err := h.db.GetShoppingListItemByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "shopping list item not found"})
+		apierror.Lookup(c, err, "shopping list item not found")
+		return
+	}
+
+	visible, err := authz.CanViewShoppingItem(c.Request.Context(), h.db, user.ID, item)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	if !visible {
+		apierror.NotFound(c, "shopping list item not found")
 		return
 	}
 
+	middleware.SetLastModified(c, item.UpdatedAt)
 	c.JSON(http.StatusOK, item)
 }
 
@@ -88,22 +138,23 @@ func (h *Handler) GetShoppingListItem(c *gin.Context) {
 func (h *Handler) CreateShoppingListItem(c *gin.Context) {
 	user, ok := middleware.GetUserFromContext(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		apierror.Unauthorized(c, "unauthorized")
 		return
 	}
 
 	var item database.ShoppingListItem
 	if err := c.ShouldBindJSON(&item); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Invalid(c, err)
 		return
 	}
 
 	item.UserID = user.ID
 
 	if err := h.db.CreateShoppingListItem(c.Request.Context(), &item); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Respond(c, err)
 		return
 	}
+	h.events.Publish(c.Request.Context(), events.NewShoppingListUpdated(events.ShoppingListItemAdded, &item))
 
 	c.JSON(http.StatusCreated, item)
 }
@@ -112,7 +163,7 @@ func (h *Handler) CreateShoppingListItem(c *gin.Context) {
 func (h *Handler) UpdateShoppingListItem(c *gin.Context) {
 	user, ok := middleware.GetUserFromContext(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		apierror.Unauthorized(c, "unauthorized")
 		return
 	}
 
@@ -121,18 +172,18 @@ func (h *Handler) UpdateShoppingListItem(c *gin.Context) {
 	// Verify ownership
 	existing, err := h.db.GetShoppingListItemByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "shopping list item not found"})
+		apierror.Lookup(c, err, "shopping list item not found")
 		return
 	}
 
-	if existing.UserID != user.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+	if !authz.CanEditShoppingItem(user.ID, existing) {
+		apierror.Forbidden(c, "forbidden")
 		return
 	}
 
 	var item database.ShoppingListItem
 	if err := c.ShouldBindJSON(&item); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Invalid(c, err)
 		return
 	}
 
@@ -140,9 +191,10 @@ func (h *Handler) UpdateShoppingListItem(c *gin.Context) {
 	item.UserID = user.ID
 
 	if err := h.db.UpdateShoppingListItem(c.Request.Context(), &item); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Respond(c, err)
 		return
 	}
+	h.events.Publish(c.Request.Context(), events.NewShoppingListUpdated(events.ShoppingListItemUpdated, &item))
 
 	c.JSON(http.StatusOK, item)
 }
@@ -151,7 +203,7 @@ func (h *Handler) UpdateShoppingListItem(c *gin.Context) {
 func (h *Handler) DeleteShoppingListItem(c *gin.Context) {
 	user, ok := middleware.GetUserFromContext(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		apierror.Unauthorized(c, "unauthorized")
 		return
 	}
 
@@ -160,19 +212,20 @@ func (h *Handler) DeleteShoppingListItem(c *gin.Context) {
 	// Verify ownership
 	existing, err := h.db.GetShoppingListItemByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "shopping list item not found"})
+		apierror.Lookup(c, err, "shopping list item not found")
 		return
 	}
 
-	if existing.UserID != user.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+	if !authz.CanEditShoppingItem(user.ID, existing) {
+		apierror.Forbidden(c, "forbidden")
 		return
 	}
 
 	if err := h.db.DeleteShoppingListItem(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Respond(c, err)
 		return
 	}
+	h.events.Publish(c.Request.Context(), events.NewShoppingListUpdated(events.ShoppingListItemRemoved, existing))
 
 	c.Status(http.StatusNoContent)
 }
@@ -181,7 +234,7 @@ func (h *Handler) DeleteShoppingListItem(c *gin.Context) {
 func (h *Handler) ToggleShoppingListItem(c *gin.Context) {
 	user, ok := middleware.GetUserFromContext(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		apierror.Unauthorized(c, "unauthorized")
 		return
 	}
 
@@ -190,21 +243,42 @@ func (h *Handler) ToggleShoppingListItem(c *gin.Context) {
 	// Verify ownership
 	existing, err := h.db.GetShoppingListItemByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "shopping list item not found"})
+		apierror.Lookup(c, err, "shopping list item not found")
 		return
 	}
 
-	if existing.UserID != user.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+	if !authz.CanEditShoppingItem(user.ID, existing) {
+		apierror.Forbidden(c, "forbidden")
 		return
 	}
 
+	h.toggle(c, existing)
+}
+
+// toggle ticks an item off, or reopens it, and responds with it
+func (h *Handler) toggle(c *gin.Context, existing *database.ShoppingListItem) {
 	existing.Completed = !existing.Completed
 
 	if err := h.db.UpdateShoppingListItem(c.Request.Context(), existing); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apierror.Respond(c, err)
 		return
 	}
+	// Ticking off an item with a price records the purchase for budget
+	// tracking; reopening it takes the purchase back
+	ctx := c.Request.Context()
+	purchaseErr := budget.UndoPurchase(ctx, h.db, existing)
+	if existing.Completed && purchaseErr == nil {
+		purchaseErr = budget.RecordPurchase(ctx, h.db, existing, time.Now())
+	}
+	if purchaseErr != nil {
+		logger.Ctx(ctx).Warn().Err(purchaseErr).Str("item_id", existing.ID).Msg("Failed to record shopping list purchase")
+	}
+
+	action := events.ShoppingListItemReopened
+	if existing.Completed {
+		action = events.ShoppingListItemCompleted
+	}
+	h.events.Publish(c.Request.Context(), events.NewShoppingListUpdated(action, existing))
 
 	c.JSON(http.StatusOK, existing)
 }
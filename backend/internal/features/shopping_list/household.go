@@ -0,0 +1,114 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package shopping_list
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/authz"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/middleware"
+)
+
+// maxHouseholdItems bounds how many items of each member's list the
+// household list shows
+const maxHouseholdItems = 200
+
+// ListHouseholdItems lists the items on every member's shopping list, in
+// the aisle order of the household's default store, so anyone shopping
+// for the household can work from one list
+// @Summary Household shopping list
+// @Tags households
+// @Produce json
+// @Param id path string true "Household ID"
+// @Router /households/{id}/shopping-list [get]
+func (h *Handler) ListHouseholdItems(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+	householdID := c.Param("id")
+	if !h.householdMember(c, user.ID, authz.ViewHousehold) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	members, err := h.db.ListHouseholdMembers(ctx, householdID)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	items := []*database.ShoppingListItem{}
+	for _, member := range members {
+		memberItems, err := h.db.ListShoppingListItems(ctx, database.ShoppingListFilter{
+			UserID: member.UserID,
+			Limit:  maxHouseholdItems,
+		})
+		if err != nil {
+			apierror.Respond(c, err)
+			return
+		}
+		items = append(items, memberItems...)
+	}
+
+	layouts, err := h.db.ListStoreLayouts(ctx, []string{householdID})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	for _, layout := range layouts {
+		if layout.IsDefault {
+			SortByAisle(items, layout)
+			break
+		}
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// ToggleHouseholdItem ticks an item on a member's shopping list off, or
+// reopens it
+// @Summary Check off a household shopping list item
+// @Tags households
+// @Produce json
+// @Param id path string true "Household ID"
+// @Param item_id path string true "Shopping list item ID"
+// @Router /households/{id}/shopping-list/{item_id}/toggle [patch]
+func (h *Handler) ToggleHouseholdItem(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+	ctx := c.Request.Context()
+	item, err := h.db.GetShoppingListItemByID(ctx, c.Param("item_id"))
+	if err != nil {
+		apierror.Lookup(c, err, "shopping list item not found")
+		return
+	}
+	if err := authz.CanCheckShoppingItem(ctx, h.db, c.Param("id"), user.ID, item); err != nil {
+		apierror.Lookup(c, err, "shopping list item not found")
+		return
+	}
+
+	h.toggle(c, item)
+}
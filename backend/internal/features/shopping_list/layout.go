@@ -0,0 +1,533 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package shopping_list
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/api/params"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/authz"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/middleware"
+)
+
+// maxCategories is the most aisles a store layout can have
+const maxCategories = 100
+
+// StoreTemplate is a ready-made aisle order to start a layout from
+type StoreTemplate struct {
+	Name       string   `json:"name"`
+	Categories []string `json:"categories"`
+}
+
+// storeTemplates are the layouts offered to start from, most common first
+var storeTemplates = []StoreTemplate{
+	{"supermarket", []string{"produce", "bakery", "deli", "meat", "seafood", "dairy", "eggs", "pantry", "canned goods", "baking", "spices", "snacks", "beverages", "frozen", "household"}},
+	{"discount", []string{"bakery", "pantry", "canned goods", "snacks", "beverages", "dairy", "eggs", "meat", "frozen", "produce", "household"}},
+	{"warehouse", []string{"household", "beverages", "snacks", "pantry", "canned goods", "bakery", "produce", "meat", "seafood", "dairy", "eggs", "frozen"}},
+	{"market", []string{"produce", "bakery", "dairy", "eggs", "meat", "seafood", "deli", "spices"}},
+}
+
+// categoryAliases map common ways of naming a category onto the names the
+// templates use
+var categoryAliases = map[string]string{
+	"fruit":       "produce",
+	"fruits":      "produce",
+	"vegetables":  "produce",
+	"veg":         "produce",
+	"fresh":       "produce",
+	"bread":       "bakery",
+	"milk":        "dairy",
+	"cheese":      "dairy",
+	"poultry":     "meat",
+	"butcher":     "meat",
+	"fish":        "seafood",
+	"canned":      "canned goods",
+	"cans":        "canned goods",
+	"tinned":      "canned goods",
+	"dry goods":   "pantry",
+	"grains":      "pantry",
+	"pasta":       "pantry",
+	"herbs":       "spices",
+	"drinks":      "beverages",
+	"frozen food": "frozen",
+	"cleaning":    "household",
+}
+
+// Category spells a shopping list category the way layouts list it:
+// lowercase, single spaces and common aliases resolved
+func Category(category string) string {
+	category = strings.Join(strings.Fields(strings.ToLower(category)), " ")
+	if alias, ok := categoryAliases[category]; ok {
+		return alias
+	}
+	return category
+}
+
+// normalizeCategories cleans up a layout's categories, dropping blanks and
+// repeats. It reports false when one is too long.
+func normalizeCategories(categories []string) ([]string, bool) {
+	seen := map[string]bool{}
+	normalized := []string{}
+	for _, category := range categories {
+		category = Category(category)
+		if len(category) > 50 {
+			return nil, false
+		}
+		if category != "" && !seen[category] {
+			seen[category] = true
+			normalized = append(normalized, category)
+		}
+	}
+	return normalized, true
+}
+
+// SortByAisle orders items as a layout walks the store. Items in
+// categories the layout doesn't list come after, by category, and items
+// without a category last; items within an aisle keep their order.
+func SortByAisle(items []*database.ShoppingListItem, layout *database.StoreLayout) {
+	position := map[string]int{}
+	for i, category := range layout.Categories {
+		position[category] = i
+	}
+	rank := func(item *database.ShoppingListItem) (int, string) {
+		category := Category(item.Category)
+		if category == "" {
+			return len(layout.Categories) + 1, ""
+		}
+		if p, ok := position[category]; ok {
+			return p, ""
+		}
+		return len(layout.Categories), category
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		ri, ci := rank(items[i])
+		rj, cj := rank(items[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return ci < cj
+	})
+}
+
+// Aisle is one stop on the way round a store
+type Aisle struct {
+	Category string                       `json:"category"` // empty for items without a category
+	Items    []*database.ShoppingListItem `json:"items"`
+}
+
+// groupByAisle splits items sorted by SortByAisle into aisles
+func groupByAisle(items []*database.ShoppingListItem) []Aisle {
+	aisles := []Aisle{}
+	for _, item := range items {
+		category := Category(item.Category)
+		if len(aisles) == 0 || aisles[len(aisles)-1].Category != category {
+			aisles = append(aisles, Aisle{Category: category, Items: []*database.ShoppingListItem{}})
+		}
+		aisles[len(aisles)-1].Items = append(aisles[len(aisles)-1].Items, item)
+	}
+	return aisles
+}
+
+// RegisterHouseholdRoutes registers the store layouts of a household and
+// the shopping list its members share
+func (h *Handler) RegisterHouseholdRoutes(router *gin.RouterGroup) {
+	router.GET("/:id/stores", h.ListStoreLayouts)
+	router.POST("/:id/stores", h.CreateStoreLayout)
+	router.PUT("/:id/stores/:store_id", h.UpdateStoreLayout)
+	router.POST("/:id/stores/:store_id/move", h.MoveCategory)
+	router.DELETE("/:id/stores/:store_id", h.DeleteStoreLayout)
+	router.GET("/:id/shopping-list", h.ListHouseholdItems)
+	router.PATCH("/:id/shopping-list/:item_id/toggle", h.ToggleHouseholdItem)
+}
+
+// userLayout picks the layout to sort the user's list by: the one named by
+// store, as an ID or a store name, or else the default of one of their
+// households. It returns nil when there is no such layout.
+func (h *Handler) userLayout(ctx context.Context, userID, store string) (*database.StoreLayout, error) {
+	households, err := h.db.ListHouseholdsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(households))
+	for i, household := range households {
+		ids[i] = household.ID
+	}
+	layouts, err := h.db.ListStoreLayouts(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, layout := range layouts {
+		if store == "" && layout.IsDefault || store != "" && (layout.ID == store || strings.EqualFold(layout.Name, store)) {
+			return layout, nil
+		}
+	}
+	return nil, nil
+}
+
+// ListAisles returns the items still to buy grouped into aisles, in the
+// order of a store layout
+// @Summary Shopping list by aisle
+// @Tags shopping-list
+// @Produce json
+// @Param store query string false "Store layout ID or store name; the household default otherwise"
+// @Router /shopping-list/aisles [get]
+func (h *Handler) ListAisles(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	query := params.Query(c)
+	store := query.String("store")
+	if !query.Valid() {
+		return
+	}
+
+	ctx := c.Request.Context()
+	layout, err := h.userLayout(ctx, user.ID, store)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	if layout == nil && store != "" {
+		apierror.NotFound(c, "store layout not found")
+		return
+	}
+	if layout == nil {
+		// Without a layout, aisles follow the most common one
+		layout = &database.StoreLayout{Categories: storeTemplates[0].Categories}
+	}
+
+	completed := false
+	items, err := h.db.ListShoppingListItems(ctx, database.ShoppingListFilter{UserID: user.ID, Completed: &completed})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	SortByAisle(items, layout)
+
+	response := gin.H{"aisles": groupByAisle(items)}
+	if layout.ID != "" {
+		response["layout"] = layout
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// ListStoreTemplates returns the ready-made aisle orders
+// @Summary Store layout templates
+// @Tags shopping-list
+// @Produce json
+// @Success 200 {array} StoreTemplate
+// @Router /shopping-list/store-templates [get]
+func (h *Handler) ListStoreTemplates(c *gin.Context) {
+	c.JSON(http.StatusOK, storeTemplates)
+}
+
+// householdMember responds with an error unless the user belongs to the
+// household in the path with a role that allows action
+func (h *Handler) householdMember(c *gin.Context, userID string, action authz.Action) bool {
+	if _, err := authz.Household(c.Request.Context(), h.db, c.Param("id"), userID, action); err != nil {
+		apierror.Lookup(c, err, "household not found")
+		return false
+	}
+	return true
+}
+
+// householdLayout loads the layout in the path for editing, checking the
+// user may change its household's settings
+func (h *Handler) householdLayout(c *gin.Context) *database.StoreLayout {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return nil
+	}
+	if !h.householdMember(c, user.ID, authz.EditSettings) {
+		return nil
+	}
+	layout, err := h.db.GetStoreLayoutByID(c.Request.Context(), c.Param("store_id"))
+	if err != nil {
+		apierror.Lookup(c, err, "store layout not found")
+		return nil
+	}
+	if layout.HouseholdID != c.Param("id") {
+		apierror.NotFound(c, "store layout not found")
+		return nil
+	}
+	return layout
+}
+
+// ListStoreLayouts lists a household's store layouts
+// @Summary List store layouts
+// @Tags households
+// @Produce json
+// @Param id path string true "Household ID"
+// @Success 200 {array} database.StoreLayout
+// @Router /households/{id}/stores [get]
+func (h *Handler) ListStoreLayouts(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+	if !h.householdMember(c, user.ID, authz.ViewHousehold) {
+		return
+	}
+
+	layouts, err := h.db.ListStoreLayouts(c.Request.Context(), []string{c.Param("id")})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, layouts)
+}
+
+// storeLayoutRequest creates or replaces a store layout. Categories are
+// given in aisle order, or taken from a template.
+type storeLayoutRequest struct {
+	Name       string   `json:"name" binding:"required,max=100"`
+	Categories []string `json:"categories" binding:"omitempty,max=100"`
+	Template   string   `json:"template"`
+	IsDefault  bool     `json:"is_default"`
+}
+
+// categories resolves the aisle order a request asks for, responding with
+// an error when it is invalid
+func (r *storeLayoutRequest) categories(c *gin.Context, fallback []string) ([]string, bool) {
+	if r.Template != "" && len(r.Categories) > 0 {
+		apierror.BadRequest(c, "give categories or a template, not both")
+		return nil, false
+	}
+	if r.Template != "" {
+		for _, template := range storeTemplates {
+			if template.Name == r.Template {
+				return append([]string{}, template.Categories...), true
+			}
+		}
+		apierror.BadRequest(c, "unknown store template")
+		return nil, false
+	}
+	if len(r.Categories) == 0 {
+		return fallback, true
+	}
+	categories, ok := normalizeCategories(r.Categories)
+	if !ok {
+		apierror.BadRequest(c, "categories can be at most 50 characters")
+		return nil, false
+	}
+	return categories, true
+}
+
+// CreateStoreLayout adds a store to a household. The first store becomes
+// the default.
+// @Summary Create store layout
+// @Tags households
+// @Accept json
+// @Produce json
+// @Param id path string true "Household ID"
+// @Success 201 {object} database.StoreLayout
+// @Router /households/{id}/stores [post]
+func (h *Handler) CreateStoreLayout(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+	if !h.householdMember(c, user.ID, authz.EditSettings) {
+		return
+	}
+
+	var req storeLayoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+	categories, ok := req.categories(c, append([]string{}, storeTemplates[0].Categories...))
+	if !ok {
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		apierror.BadRequest(c, "name is required")
+		return
+	}
+
+	ctx := c.Request.Context()
+	existing, err := h.db.ListStoreLayouts(ctx, []string{c.Param("id")})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	now := time.Now()
+	userID := user.ID
+	layout := &database.StoreLayout{
+		ID:          uuid.New().String(),
+		HouseholdID: c.Param("id"),
+		Name:        name,
+		Categories:  categories,
+		IsDefault:   req.IsDefault || len(existing) == 0,
+		CreatedBy:   &userID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	err = h.db.WithTx(ctx, func(ctx context.Context) error {
+		if err := h.db.CreateStoreLayout(ctx, layout); err != nil {
+			return err
+		}
+		if layout.IsDefault {
+			return h.db.SetDefaultStoreLayout(ctx, layout.HouseholdID, layout.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, layout)
+}
+
+// UpdateStoreLayout renames a store, replaces its aisle order or makes it
+// the household's default
+// @Summary Update store layout
+// @Tags households
+// @Accept json
+// @Produce json
+// @Param id path string true "Household ID"
+// @Param store_id path string true "Store layout ID"
+// @Success 200 {object} database.StoreLayout
+// @Router /households/{id}/stores/{store_id} [put]
+func (h *Handler) UpdateStoreLayout(c *gin.Context) {
+	layout := h.householdLayout(c)
+	if layout == nil {
+		return
+	}
+
+	var req storeLayoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+	categories, ok := req.categories(c, layout.Categories)
+	if !ok {
+		return
+	}
+	if name := strings.TrimSpace(req.Name); name != "" {
+		layout.Name = name
+	}
+	layout.Categories = categories
+	layout.UpdatedAt = time.Now()
+
+	ctx := c.Request.Context()
+	err := h.db.WithTx(ctx, func(ctx context.Context) error {
+		if err := h.db.UpdateStoreLayout(ctx, layout); err != nil {
+			return err
+		}
+		if req.IsDefault && !layout.IsDefault {
+			layout.IsDefault = true
+			return h.db.SetDefaultStoreLayout(ctx, layout.HouseholdID, layout.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, layout)
+}
+
+// MoveCategory moves one category to a new place in the aisle order, as
+// when it is dragged and dropped. A category the layout doesn't list yet is
+// inserted there.
+// @Summary Move store layout category
+// @Tags households
+// @Accept json
+// @Produce json
+// @Param id path string true "Household ID"
+// @Param store_id path string true "Store layout ID"
+// @Success 200 {object} database.StoreLayout
+// @Router /households/{id}/stores/{store_id}/move [post]
+func (h *Handler) MoveCategory(c *gin.Context) {
+	layout := h.householdLayout(c)
+	if layout == nil {
+		return
+	}
+
+	var req struct {
+		Category string `json:"category" binding:"required,max=50"`
+		Position *int   `json:"position" binding:"required,min=0"` // 0 for the first aisle
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+	category := Category(req.Category)
+	if category == "" {
+		apierror.BadRequest(c, "category is required")
+		return
+	}
+
+	categories := make([]string, 0, len(layout.Categories)+1)
+	for _, existing := range layout.Categories {
+		if existing != category {
+			categories = append(categories, existing)
+		}
+	}
+	if len(categories) >= maxCategories {
+		apierror.BadRequest(c, "a store layout can have at most 100 categories")
+		return
+	}
+	position := min(*req.Position, len(categories))
+	categories = append(categories[:position], append([]string{category}, categories[position:]...)...)
+
+	layout.Categories = categories
+	layout.UpdatedAt = time.Now()
+	if err := h.db.UpdateStoreLayout(c.Request.Context(), layout); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, layout)
+}
+
+// DeleteStoreLayout removes a store from a household. When it was the
+// default, the household has none until another is chosen.
+// @Summary Delete store layout
+// @Tags households
+// @Param id path string true "Household ID"
+// @Param store_id path string true "Store layout ID"
+// @Success 204
+// @Router /households/{id}/stores/{store_id} [delete]
+func (h *Handler) DeleteStoreLayout(c *gin.Context) {
+	layout := h.householdLayout(c)
+	if layout == nil {
+		return
+	}
+	if err := h.db.DeleteStoreLayout(c.Request.Context(), layout.ID); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
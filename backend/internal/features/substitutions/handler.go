@@ -0,0 +1,358 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package substitutions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/ai"
+	"github.com/rghsoftware/space-food/internal/api/params"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/authz"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/aicache"
+	"github.com/rghsoftware/space-food/internal/features/aiprompts"
+	"github.com/rghsoftware/space-food/internal/features/aiusage"
+	"github.com/rghsoftware/space-food/internal/features/capabilities"
+	"github.com/rghsoftware/space-food/internal/features/locale"
+	"github.com/rghsoftware/space-food/internal/middleware"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+func init() {
+	capabilities.RegisterAIFeature(capabilities.AIFeature{
+		Name:        aiusage.FeatureSubstitutions,
+		UsesAI:      true,
+		Fallback:    "curated_table",
+		Description: "substitutions come from the curated table only; ingredients it has no entry for get none",
+	})
+}
+
+// cacheKind labels substitution outputs in the AI cache
+const cacheKind = "substitution"
+
+// maxAIIngredients bounds how many ingredients one AI request asks about
+const maxAIIngredients = 10
+
+// maxAIOptions is how many options the prompt asks for per ingredient
+const maxAIOptions = 3
+
+// maxAIConfidence caps the confidence of AI options, which are unchecked
+const maxAIConfidence = 0.7
+
+// What happened with the AI fallback
+const (
+	AIOff        = "off"         // no provider is configured, or skip_ai was set
+	AIUnneeded   = "unneeded"    // the table had options for every ingredient
+	AIGenerated  = "generated"   // the provider filled the gaps
+	AICached     = "cached"      // a stored answer filled them
+	AIOverBudget = "over_budget" // the user or instance is out of AI budget
+	AIFailed     = "failed"      // the provider could not be reached or gave an unusable answer
+)
+
+// Handler handles substitution requests
+type Handler struct {
+	db      database.Database
+	ai      *ai.Source
+	prompts *aiprompts.Registry
+	cache   *aicache.Cache
+	tracker *aiusage.Tracker
+}
+
+// NewHandler creates a new substitution handler
+func NewHandler(db database.Database, source *ai.Source, prompts *aiprompts.Registry, cache *aicache.Cache, tracker *aiusage.Tracker) *Handler {
+	return &Handler{
+		db:      db,
+		ai:      source,
+		prompts: prompts,
+		cache:   cache,
+		tracker: tracker,
+	}
+}
+
+// RegisterRecipeRoutes registers substitution routes under /recipes
+func (h *Handler) RegisterRecipeRoutes(router *gin.RouterGroup) {
+	router.GET("/:id/substitutions", h.GetSubstitutions)
+}
+
+// Substitutions is what can stand in for a recipe's missing or restricted
+// ingredients
+type Substitutions struct {
+	RecipeID    string        `json:"recipe_id"`
+	Suggestions []*Suggestion `json:"substitutions"`
+	AI          string        `json:"ai"`
+	AIModel     string        `json:"ai_model,omitempty"`     // the provider and model whose options are included
+	AIPromptID  string        `json:"ai_prompt_id,omitempty"` // the prompt they answered
+}
+
+// GetSubstitutions suggests substitutes for the recipe's ingredients that
+// aren't in the pantry or clash with a dietary restriction
+// @Summary Ingredient substitutions
+// @Tags recipes
+// @Produce json
+// @Param id path string true "Recipe ID"
+// @Param ingredient query string false "Only ingredients whose name contains this, even if on hand"
+// @Param household_id query string false "Avoid the restrictions of all members of this household"
+// @Param skip_ai query bool false "Only use the curated table"
+// @Success 200 {object} Substitutions
+// @Router /recipes/{id}/substitutions [get]
+func (h *Handler) GetSubstitutions(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	query := params.Query(c)
+	only := query.String("ingredient")
+	householdID := query.String("household_id")
+	skipAI := query.Bool("skip_ai")
+	if !query.Valid() {
+		return
+	}
+
+	ctx := c.Request.Context()
+	recipe, err := h.db.GetRecipeByID(ctx, c.Param("id"))
+	if err != nil {
+		apierror.Lookup(c, err, "recipe not found")
+		return
+	}
+
+	userIDs := []string{user.ID}
+	if householdID != "" {
+		userIDs, ok = h.householdMemberIDs(c, householdID, user.ID)
+		if !ok {
+			return
+		}
+	}
+	restrictions, err := h.db.ListDietaryRestrictions(ctx, userIDs)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	pantry, err := h.db.ListPantryItems(ctx, database.PantryFilter{UserID: user.ID})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	result := &Substitutions{
+		RecipeID:    recipe.ID,
+		Suggestions: Suggest(recipe, pantry, restrictions, only),
+		AI:          AIOff,
+	}
+	if provider := h.ai.Provider(); provider != nil && !skipAI {
+		status, answer := h.fillFromAI(ctx, provider, user.ID, locale.Request(c, h.db, user.ID), recipe, result.Suggestions, pantry, restrictions)
+		result.AI = status
+		if answer != nil {
+			result.AIModel, result.AIPromptID = answer.Model, answer.PromptID
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// aiAnswer is the JSON the provider is asked for
+type aiAnswer struct {
+	Substitutions []struct {
+		Ingredient string `json:"ingredient"`
+		Options    []struct {
+			Use        []string `json:"use"`
+			Amount     string   `json:"amount"`
+			Notes      string   `json:"notes"`
+			Confidence float64  `json:"confidence"`
+		} `json:"options"`
+	} `json:"substitutions"`
+	// Model and PromptID are set from the response, so cached answers
+	// keep the model and prompt that gave them
+	Model    string `json:"model,omitempty"`
+	PromptID string `json:"prompt_id,omitempty"`
+}
+
+// Validate bounds an answer to what was asked: named ingredients, at most
+// three options each, each using something, with a confidence from 0 to 1
+func (a *aiAnswer) Validate() error {
+	if len(a.Substitutions) > maxAIIngredients {
+		return fmt.Errorf("%d ingredients answered, at most %d asked", len(a.Substitutions), maxAIIngredients)
+	}
+	for _, entry := range a.Substitutions {
+		if strings.TrimSpace(entry.Ingredient) == "" {
+			return fmt.Errorf("an answer names no ingredient")
+		}
+		if len(entry.Options) > maxAIOptions {
+			return fmt.Errorf("%d options for %s, at most %d asked", len(entry.Options), entry.Ingredient, maxAIOptions)
+		}
+		for _, option := range entry.Options {
+			if len(option.Use) == 0 {
+				return fmt.Errorf("an option for %s uses nothing", entry.Ingredient)
+			}
+			if option.Confidence < 0 || option.Confidence > 1 {
+				return fmt.Errorf("confidence %v for %s is not from 0 to 1", option.Confidence, entry.Ingredient)
+			}
+		}
+	}
+	return nil
+}
+
+// fillFromAI asks the AI provider for options for the ingredients the
+// table had none for. Its answers pass the same restriction filter, and
+// failures leave those ingredients without options rather than failing
+// the request. It returns what happened and the answer used, if any.
+func (h *Handler) fillFromAI(ctx context.Context, provider ai.Provider, userID, language string, recipe *database.Recipe, suggestionList []*Suggestion, pantry []*database.PantryItem, restrictions []*database.DietaryRestriction) (string, *aiAnswer) {
+	needed := map[string]*Suggestion{}
+	names := []string{}
+	for _, suggestion := range suggestionList {
+		if len(suggestion.Options) == 0 && len(names) < maxAIIngredients {
+			needed[strings.ToLower(suggestion.Ingredient)] = suggestion
+			names = append(names, suggestion.Ingredient)
+		}
+	}
+	if len(names) == 0 {
+		return AIUnneeded, nil
+	}
+
+	ingredients := make([]string, 0, len(recipe.Ingredients))
+	for _, ingredient := range recipe.Ingredients {
+		ingredients = append(ingredients, ingredient.Name)
+	}
+	avoid := make([]string, 0, len(restrictions))
+	for _, restriction := range restrictions {
+		avoid = appendUnique(avoid, strings.ToLower(restriction.Name))
+	}
+
+	log := logger.Ctx(ctx)
+	// Ingredient names stay as the recipe writes them, to match the pantry
+	// and the restriction check
+	req, promptID, err := h.prompts.Request(aiprompts.Substitutions, aiprompts.SubstitutionsData{
+		Title:       recipe.Title,
+		Ingredients: ingredients,
+		Needed:      names,
+		Avoid:       avoid,
+		Language:    locale.AnswerIn(language),
+	})
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to build substitution AI prompt")
+		return AIFailed, nil
+	}
+
+	var answer aiAnswer
+	status := AICached
+	key, err := aicache.Key(cacheKind, map[string]any{
+		"model":  provider.Model(),
+		"system": req.System,
+		"prompt": req.Prompt,
+	})
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to key substitution AI cache")
+		return AIFailed, nil
+	}
+	found, err := h.cache.Get(ctx, key, &answer)
+	if err != nil {
+		log.Warn().Err(err).Str("key", key).Msg("Failed to read substitution AI cache")
+	}
+	if !found {
+		status, answer = h.generate(ctx, provider, userID, recipe.ID, key, req, promptID)
+		if status != AIGenerated {
+			return status, nil
+		}
+	}
+
+	for _, entry := range answer.Substitutions {
+		suggestion := needed[strings.ToLower(strings.TrimSpace(entry.Ingredient))]
+		if suggestion == nil {
+			continue
+		}
+		for _, option := range entry.Options {
+			use := []string{}
+			for _, ingredient := range option.Use {
+				if ingredient = strings.TrimSpace(ingredient); ingredient != "" {
+					use = append(use, ingredient)
+				}
+			}
+			suggestion.addOption(Option{
+				Use:         strings.Join(use, " + "),
+				Ingredients: use,
+				Amount:      strings.TrimSpace(option.Amount),
+				Notes:       strings.TrimSpace(option.Notes),
+				Confidence:  math.Round(math.Min(math.Max(option.Confidence, 0), maxAIConfidence)*100) / 100,
+				Source:      SourceAI,
+			}, pantry, restrictions)
+		}
+		suggestion.rank()
+	}
+	return status, &answer
+}
+
+// generate asks the provider on the user's behalf and caches the answer
+func (h *Handler) generate(ctx context.Context, provider ai.Provider, userID, recipeID, key string, req ai.Request, promptID string) (string, aiAnswer) {
+	var answer aiAnswer
+	log := logger.Ctx(ctx)
+
+	err := h.tracker.Check(ctx, userID)
+	var budgetErr *aiusage.BudgetError
+	if errors.As(err, &budgetErr) {
+		return AIOverBudget, answer
+	}
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to check AI budget")
+		return AIFailed, answer
+	}
+
+	resp, err := ai.GenerateJSON(ai.WithCaller(ctx, userID, aiusage.FeatureSubstitutions), provider, req, &answer)
+	if resp == nil {
+		log.Warn().Err(err).Str("provider", provider.Name()).Msg("AI substitution request failed")
+		return AIFailed, answer
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("provider", resp.Provider).Msg("Unusable AI substitution response")
+		return AIFailed, answer
+	}
+	answer.Model, answer.PromptID = resp.Model, promptID
+	if err := h.cache.Set(ctx, key, cacheKind, recipeID, answer); err != nil {
+		log.Warn().Err(err).Str("key", key).Msg("Failed to cache AI substitutions")
+	}
+	return AIGenerated, answer
+}
+
+// householdMemberIDs returns the member IDs of a household after checking
+// that the requesting user belongs to it. It writes the error response and
+// returns false when the check fails.
+func (h *Handler) householdMemberIDs(c *gin.Context, householdID, userID string) ([]string, bool) {
+	if _, err := authz.Household(c.Request.Context(), h.db, householdID, userID, authz.ViewHousehold); err != nil {
+		apierror.Lookup(c, err, "household not found")
+		return nil, false
+	}
+
+	members, err := h.db.ListHouseholdMembers(c.Request.Context(), householdID)
+	if err != nil {
+		apierror.Respond(c, err)
+		return nil, false
+	}
+
+	ids := make([]string, 0, len(members))
+	for _, member := range members {
+		ids = append(ids, member.UserID)
+	}
+	return ids, true
+}
@@ -0,0 +1,215 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package substitutions
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/dietary"
+	"github.com/rghsoftware/space-food/internal/features/suggestions"
+)
+
+// Why an ingredient needs a substitute
+const (
+	ReasonMissing    = "missing"    // not in the pantry
+	ReasonRestricted = "restricted" // clashes with a dietary restriction
+	ReasonAsked      = "asked"      // on hand, but asked about by name
+)
+
+// Where an option came from
+const (
+	SourceTable = "table"
+	SourceAI    = "ai"
+)
+
+// Option is one way to stand in for an ingredient
+type Option struct {
+	Use         string   `json:"use"` // e.g. "milk + lemon juice"
+	Ingredients []string `json:"ingredients"`
+	Amount      string   `json:"amount,omitempty"`
+	Notes       string   `json:"notes,omitempty"`
+	Confidence  float64  `json:"confidence"` // 0-1, how close the result comes to the original
+	InPantry    bool     `json:"in_pantry"`  // everything it takes is in the pantry
+	Source      string   `json:"source"`
+}
+
+// Suggestion is the substitutes for one ingredient of a recipe
+type Suggestion struct {
+	Ingredient   string   `json:"ingredient"`
+	Reason       string   `json:"reason"`
+	Restrictions []string `json:"restrictions,omitempty"` // what a restricted ingredient clashes with
+	Options      []Option `json:"options"`
+	Filtered     int      `json:"filtered"` // options left out because they clash with a restriction
+}
+
+// words splits text into lowercase words with a trailing plural "s"
+// removed, so "eggs" matches the table's "egg"
+func words(s string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+	for i, f := range fields {
+		if len(f) > 3 && strings.HasSuffix(f, "s") && !strings.HasSuffix(f, "ss") {
+			fields[i] = strings.TrimSuffix(f, "s")
+		}
+	}
+	return fields
+}
+
+// lookup finds an ingredient's entry in the table. Entries match as a run
+// of words in the ingredient's name; the one ending last wins, since names
+// end in what the ingredient is ("red wine vinegar" is a vinegar), and then
+// the longest ("peanut butter" over "butter").
+func lookup(ingredient string) []substitute {
+	name := words(ingredient)
+	var best []substitute
+	bestEnd, bestLen := -1, 0
+	for key, substitutes := range table {
+		keyWords := words(key)
+		for start := 0; start+len(keyWords) <= len(name); start++ {
+			if !equalWords(name[start:start+len(keyWords)], keyWords) {
+				continue
+			}
+			end := start + len(keyWords)
+			if end > bestEnd || end == bestEnd && len(keyWords) > bestLen {
+				best, bestEnd, bestLen = substitutes, end, len(keyWords)
+			}
+		}
+	}
+	return best
+}
+
+func equalWords(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// inPantry reports whether everything an option takes is on hand
+func inPantry(ingredients []string, pantry []*database.PantryItem) bool {
+	for _, ingredient := range ingredients {
+		if !pantryStaples[strings.ToLower(ingredient)] && suggestions.FindPantryItem(ingredient, pantry) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// clashes reports whether an option uses anything the restrictions rule out
+func clashes(ingredients []string, restrictions []*database.DietaryRestriction) bool {
+	recipe := &database.Recipe{}
+	for _, ingredient := range ingredients {
+		recipe.Ingredients = append(recipe.Ingredients, database.Ingredient{Name: ingredient})
+	}
+	return dietary.HasConflicts(recipe, restrictions)
+}
+
+// addOption adds an option to a suggestion unless the restrictions rule it
+// out
+func (s *Suggestion) addOption(option Option, pantry []*database.PantryItem, restrictions []*database.DietaryRestriction) {
+	if len(option.Ingredients) == 0 {
+		return
+	}
+	if clashes(option.Ingredients, restrictions) {
+		s.Filtered++
+		return
+	}
+	option.InPantry = inPantry(option.Ingredients, pantry)
+	s.Options = append(s.Options, option)
+}
+
+// rank puts options that can be made from the pantry first, then the
+// closest
+func (s *Suggestion) rank() {
+	sort.SliceStable(s.Options, func(i, j int) bool {
+		a, b := s.Options[i], s.Options[j]
+		if a.InPantry != b.InPantry {
+			return a.InPantry
+		}
+		return a.Confidence > b.Confidence
+	})
+}
+
+// Suggest finds the recipe's ingredients that need a substitute, because
+// they are not in the pantry or clash with a restriction, and offers the
+// table's substitutes for each. Optional ingredients that are merely
+// missing are left out. When only is given, just the ingredients whose
+// name contains it are considered, on hand or not.
+func Suggest(recipe *database.Recipe, pantry []*database.PantryItem, restrictions []*database.DietaryRestriction, only string) []*Suggestion {
+	restricted := map[string][]string{}
+	for _, conflict := range dietary.Conflicts(recipe, restrictions) {
+		restricted[conflict.Ingredient] = appendUnique(restricted[conflict.Ingredient], conflict.Restriction)
+	}
+
+	suggestionList := []*Suggestion{}
+	seen := map[string]bool{}
+	for _, ingredient := range recipe.Ingredients {
+		name := strings.TrimSpace(ingredient.Name)
+		key := strings.ToLower(name)
+		if name == "" || seen[key] {
+			continue
+		}
+		if only != "" && !strings.Contains(key, strings.ToLower(only)) {
+			continue
+		}
+		seen[key] = true
+
+		suggestion := &Suggestion{Ingredient: name, Options: []Option{}}
+		onHand := pantryStaples[key] || suggestions.FindPantryItem(name, pantry) != nil
+		switch {
+		case len(restricted[ingredient.Name]) > 0:
+			suggestion.Reason = ReasonRestricted
+			suggestion.Restrictions = restricted[ingredient.Name]
+		case !onHand && (!ingredient.Optional || only != ""):
+			suggestion.Reason = ReasonMissing
+		case only != "":
+			suggestion.Reason = ReasonAsked
+		default:
+			continue
+		}
+
+		for _, sub := range lookup(name) {
+			suggestion.addOption(Option{
+				Use:         strings.Join(sub.use, " + "),
+				Ingredients: sub.use,
+				Amount:      sub.amount,
+				Notes:       sub.notes,
+				Confidence:  sub.confidence,
+				Source:      SourceTable,
+			}, pantry, restrictions)
+		}
+		suggestion.rank()
+		suggestionList = append(suggestionList, suggestion)
+	}
+	return suggestionList
+}
+
+func appendUnique(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
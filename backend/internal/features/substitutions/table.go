@@ -0,0 +1,206 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package substitutions
+
+// substitute is one way to stand in for an ingredient: what to use
+// instead, how much, and how close the result comes, from 0 to 1
+type substitute struct {
+	use        []string // ingredients, e.g. {"milk", "lemon juice"}
+	amount     string
+	notes      string
+	confidence float64
+}
+
+// table is the curated substitution table, keyed by the ingredient a
+// recipe calls for, singular and lowercase. Options are listed best first.
+var table = map[string][]substitute{
+	"buttermilk": {
+		{[]string{"milk", "lemon juice"}, "1 cup milk + 1 tbsp lemon juice per cup", "Stir and let stand 5 minutes until it curdles", 0.9},
+		{[]string{"milk", "vinegar"}, "1 cup milk + 1 tbsp white vinegar per cup", "Stir and let stand 5 minutes", 0.9},
+		{[]string{"yogurt", "milk"}, "3/4 cup yogurt + 1/4 cup milk per cup", "", 0.85},
+		{[]string{"oat milk", "lemon juice"}, "1 cup oat milk + 1 tbsp lemon juice per cup", "Dairy-free; curdles less", 0.7},
+	},
+	"butter": {
+		{[]string{"vegetable oil"}, "3/4 the amount", "For sautéing and most cakes; bakes are less rich", 0.75},
+		{[]string{"olive oil"}, "3/4 the amount", "Best in savoury dishes", 0.7},
+		{[]string{"coconut oil"}, "Same amount", "Solid when cool, so it works in pastry", 0.75},
+		{[]string{"margarine"}, "Same amount", "", 0.85},
+	},
+	"milk": {
+		{[]string{"oat milk"}, "Same amount", "", 0.85},
+		{[]string{"soy milk"}, "Same amount", "", 0.85},
+		{[]string{"evaporated milk", "water"}, "Half evaporated milk, half water", "", 0.9},
+		{[]string{"water", "butter"}, "1 cup water + 1 tbsp butter per cup", "For baking only", 0.6},
+	},
+	"heavy cream": {
+		{[]string{"milk", "butter"}, "3/4 cup milk + 1/4 cup melted butter per cup", "Won't whip", 0.8},
+		{[]string{"coconut cream"}, "Same amount", "Dairy-free; tastes of coconut", 0.7},
+		{[]string{"evaporated milk"}, "Same amount", "Won't whip", 0.7},
+	},
+	"sour cream": {
+		{[]string{"greek yogurt"}, "Same amount", "", 0.9},
+		{[]string{"cream cheese", "milk"}, "Beat cream cheese with a splash of milk until smooth", "", 0.7},
+	},
+	"yogurt": {
+		{[]string{"sour cream"}, "Same amount", "", 0.85},
+		{[]string{"buttermilk"}, "Same amount", "Thinner; for baking and marinades", 0.7},
+	},
+	"cream cheese": {
+		{[]string{"ricotta", "greek yogurt"}, "Equal parts, blended smooth", "", 0.7},
+		{[]string{"mascarpone"}, "Same amount", "Richer and less tangy", 0.8},
+	},
+	"egg": {
+		{[]string{"ground flaxseed", "water"}, "1 tbsp ground flaxseed + 3 tbsp water per egg", "Rest 5 minutes to gel; for binding in bakes", 0.7},
+		{[]string{"banana"}, "1/4 cup mashed banana per egg", "Adds banana flavour; for sweet bakes", 0.6},
+		{[]string{"applesauce"}, "1/4 cup per egg", "For moist cakes and muffins", 0.6},
+		{[]string{"aquafaba"}, "3 tbsp chickpea water per egg", "Whips like egg white", 0.7},
+	},
+	"self-raising flour": {
+		{[]string{"flour", "baking powder", "salt"}, "1 cup flour + 1 1/2 tsp baking powder + 1/4 tsp salt per cup", "", 0.95},
+	},
+	"flour": {
+		{[]string{"gluten-free flour blend"}, "Same amount", "Add 1/4 tsp xanthan gum per cup if the blend has none", 0.75},
+		{[]string{"rice flour"}, "Same amount for thickening", "Gluten-free; bakes are more crumbly", 0.6},
+	},
+	"cornstarch": {
+		{[]string{"flour"}, "2 tbsp flour per 1 tbsp cornstarch", "For thickening; cook a little longer", 0.85},
+		{[]string{"arrowroot"}, "Same amount", "", 0.9},
+		{[]string{"potato starch"}, "Same amount", "", 0.9},
+	},
+	"baking powder": {
+		{[]string{"baking soda", "cream of tartar"}, "1/4 tsp baking soda + 1/2 tsp cream of tartar per tsp", "", 0.9},
+		{[]string{"baking soda", "yogurt"}, "1/4 tsp baking soda + 1/2 cup yogurt per tsp, with 1/2 cup less liquid", "", 0.7},
+	},
+	"baking soda": {
+		{[]string{"baking powder"}, "3 times the amount", "Bakes come out a little less brown", 0.7},
+	},
+	"brown sugar": {
+		{[]string{"sugar", "molasses"}, "1 cup sugar + 1 tbsp molasses per cup", "", 0.9},
+		{[]string{"sugar"}, "Same amount", "Less caramel flavour and a crisper bake", 0.7},
+	},
+	"honey": {
+		{[]string{"maple syrup"}, "Same amount", "", 0.85},
+		{[]string{"golden syrup"}, "Same amount", "", 0.85},
+		{[]string{"sugar", "water"}, "1 1/4 cups sugar + 1/4 cup water per cup", "", 0.6},
+	},
+	"maple syrup": {
+		{[]string{"honey"}, "Same amount", "", 0.85},
+		{[]string{"brown sugar", "water"}, "1 cup brown sugar + 1/4 cup water per cup, simmered", "", 0.6},
+	},
+	"lemon juice": {
+		{[]string{"lime juice"}, "Same amount", "", 0.9},
+		{[]string{"vinegar"}, "Half the amount", "For acidity, not flavour", 0.6},
+	},
+	"lime juice": {
+		{[]string{"lemon juice"}, "Same amount", "", 0.9},
+	},
+	"white wine": {
+		{[]string{"stock", "lemon juice"}, "Same amount of stock + 1 tsp lemon juice per cup", "", 0.75},
+		{[]string{"white wine vinegar", "water"}, "1 part vinegar to 3 parts water", "", 0.6},
+	},
+	"red wine": {
+		{[]string{"stock", "red wine vinegar"}, "Same amount of stock + 1 tbsp vinegar per cup", "", 0.7},
+		{[]string{"grape juice", "vinegar"}, "Same amount of juice + 1 tbsp vinegar per cup", "Sweeter", 0.6},
+	},
+	"stock": {
+		{[]string{"stock cube", "water"}, "1 cube per 2 cups water", "Saltier; season at the end", 0.9},
+		{[]string{"water", "soy sauce"}, "Same amount of water + 1 tsp soy sauce per cup", "", 0.6},
+	},
+	"broth": {
+		{[]string{"stock cube", "water"}, "1 cube per 2 cups water", "Saltier; season at the end", 0.9},
+	},
+	"garlic": {
+		{[]string{"garlic powder"}, "1/8 tsp per clove", "", 0.8},
+		{[]string{"garlic paste"}, "1/2 tsp per clove", "", 0.9},
+	},
+	"onion": {
+		{[]string{"shallot"}, "3 shallots per onion", "Milder and sweeter", 0.85},
+		{[]string{"leek"}, "1 leek per onion", "", 0.75},
+		{[]string{"onion powder"}, "1 tbsp per onion", "No texture", 0.6},
+	},
+	"shallot": {
+		{[]string{"onion"}, "1/3 onion per shallot", "", 0.8},
+	},
+	"breadcrumb": {
+		{[]string{"panko"}, "Same amount", "", 0.9},
+		{[]string{"crushed crackers"}, "Same amount", "", 0.8},
+		{[]string{"rolled oats"}, "Same amount", "For binding; not for coating", 0.7},
+	},
+	"soy sauce": {
+		{[]string{"tamari"}, "Same amount", "Usually gluten-free", 0.95},
+		{[]string{"coconut aminos"}, "Same amount, plus a pinch of salt", "Soy- and gluten-free; sweeter", 0.75},
+		{[]string{"worcestershire sauce"}, "Half the amount", "", 0.6},
+	},
+	"tomato paste": {
+		{[]string{"tomato ketchup"}, "Same amount", "Sweeter", 0.6},
+		{[]string{"passata"}, "3 times the amount, simmered down", "", 0.75},
+	},
+	"parmesan": {
+		{[]string{"pecorino"}, "Same amount", "Saltier", 0.9},
+		{[]string{"nutritional yeast"}, "Half the amount", "Dairy-free", 0.6},
+	},
+	"ricotta": {
+		{[]string{"cottage cheese"}, "Same amount, blended smooth", "", 0.85},
+	},
+	"mayonnaise": {
+		{[]string{"greek yogurt"}, "Same amount", "Tangier and lighter", 0.75},
+		{[]string{"sour cream"}, "Same amount", "", 0.7},
+	},
+	"vinegar": {
+		{[]string{"lemon juice"}, "Twice the amount", "", 0.7},
+	},
+	"rice vinegar": {
+		{[]string{"apple cider vinegar"}, "Same amount, plus a pinch of sugar", "", 0.8},
+	},
+	"fish sauce": {
+		{[]string{"soy sauce", "lime juice"}, "Same amount of soy sauce + a squeeze of lime", "Not as funky", 0.6},
+	},
+	"ginger": {
+		{[]string{"ground ginger"}, "1/4 tsp per tbsp fresh", "", 0.7},
+	},
+	"mustard": {
+		{[]string{"mustard powder", "water"}, "1 tsp powder + 1 tsp water per tbsp", "", 0.85},
+	},
+	"vanilla extract": {
+		{[]string{"maple syrup"}, "Same amount", "", 0.6},
+		{[]string{"vanilla sugar"}, "1 tbsp per tsp, with that much less sugar", "", 0.7},
+	},
+	"chili": {
+		{[]string{"chili flakes"}, "1/2 tsp per chili", "", 0.75},
+		{[]string{"cayenne"}, "1/4 tsp per chili", "", 0.7},
+	},
+	"peanut butter": {
+		{[]string{"sunflower seed butter"}, "Same amount", "Nut-free", 0.85},
+		{[]string{"tahini"}, "Same amount", "Nut-free; more bitter", 0.7},
+	},
+	"tahini": {
+		{[]string{"sunflower seed butter"}, "Same amount", "", 0.75},
+		{[]string{"peanut butter"}, "Same amount", "", 0.65},
+	},
+	"pine nut": {
+		{[]string{"sunflower seeds"}, "Same amount, toasted", "Nut-free", 0.75},
+	},
+	"chocolate": {
+		{[]string{"cocoa powder", "butter"}, "3 tbsp cocoa + 1 tbsp butter per 30 g (1 oz)", "", 0.8},
+	},
+}
+
+// pantryStaples are taken to be on hand whether or not the pantry lists
+// them
+var pantryStaples = map[string]bool{"water": true}
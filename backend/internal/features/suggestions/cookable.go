@@ -0,0 +1,231 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package suggestions
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/api/params"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/dietary"
+	"github.com/rghsoftware/space-food/internal/features/energy"
+	"github.com/rghsoftware/space-food/internal/features/locale"
+	"github.com/rghsoftware/space-food/internal/features/recipes"
+	"github.com/rghsoftware/space-food/internal/features/sensory"
+	"github.com/rghsoftware/space-food/internal/i18n"
+	"github.com/rghsoftware/space-food/internal/middleware"
+)
+
+// CookableOption is a recipe ranked by how well it can be made right now
+type CookableOption struct {
+	RecipeID           string   `json:"recipe_id"`
+	Title              string   `json:"title"`
+	TotalMinutes       int      `json:"total_minutes"` // 0 when unknown
+	Effort             int      `json:"effort"`        // 1-10
+	PantryCoverage     float64  `json:"pantry_coverage"`
+	MissingIngredients []string `json:"missing_ingredients"`
+	Reasons            []string `json:"reasons"`
+	Score              float64  `json:"score"`
+}
+
+// cookableInput is what ranking needs to know about the user's situation
+type cookableInput struct {
+	EnergyLevel int // 0 when unknown
+	Minutes     int // time available; 0 for no limit
+	Pantry      []*database.PantryItem
+	Printer     *i18n.Printer // writes the reasons
+}
+
+// comfortableEffort is the highest effort score that suits an energy
+// level: 2 on the lowest, up to the whole scale on the highest
+func comfortableEffort(level int) int {
+	return level * recipes.MaxEffort / 5
+}
+
+// RankCookable scores recipes by how ready the user is to make them.
+// Having the ingredients matters most. Effort counts against a recipe
+// once it goes beyond what the user's energy allows, and recipes that
+// take longer than the time available are left out; those with no
+// recorded times stay in but gain nothing for speed.
+func RankCookable(list []*database.Recipe, in cookableInput, limit int) []CookableOption {
+	comfortable := recipes.MaxEffort
+	if in.EnergyLevel > 0 {
+		comfortable = comfortableEffort(in.EnergyLevel)
+	}
+
+	options := []CookableOption{}
+	for _, recipe := range list {
+		total := recipe.PrepTime + recipe.CookTime
+		if in.Minutes > 0 && total > in.Minutes {
+			continue
+		}
+
+		option := CookableOption{
+			RecipeID:           recipe.ID,
+			Title:              recipe.Title,
+			TotalMinutes:       total,
+			MissingIngredients: []string{},
+			Reasons:            []string{},
+		}
+
+		required, have := 0, 0
+		for _, ingredient := range recipe.Ingredients {
+			if ingredient.Optional {
+				continue
+			}
+			required++
+			if FindPantryItem(ingredient.Name, in.Pantry) != nil {
+				have++
+			} else {
+				option.MissingIngredients = append(option.MissingIngredients, ingredient.Name)
+			}
+		}
+		option.PantryCoverage = 1
+		if required > 0 {
+			option.PantryCoverage = float64(have) / float64(required)
+		}
+		option.Score = option.PantryCoverage * 60
+
+		effort := recipes.MaxEffort
+		if recipe.Effort != nil {
+			effort = recipe.Effort.Score
+		}
+		option.Effort = effort
+		if effort <= comfortable {
+			option.Score += float64(recipes.MaxEffort-effort) * 2
+		} else {
+			option.Score -= float64(effort-comfortable) * 6
+		}
+
+		if in.Minutes > 0 && total > 0 {
+			option.Score += float64(in.Minutes-total) / float64(in.Minutes) * 10
+		}
+		option.Score += recipe.Rating * 2
+
+		switch {
+		case required > 0 && have == required:
+			option.Reasons = append(option.Reasons, in.Printer.T("suggestions.have_everything"))
+		case len(option.MissingIngredients) == 1:
+			option.Reasons = append(option.Reasons, in.Printer.T("suggestions.only_missing", option.MissingIngredients[0]))
+		case required > 0:
+			option.Reasons = append(option.Reasons, in.Printer.T("suggestions.have_some", have, required))
+		}
+		if in.EnergyLevel > 0 {
+			if effort <= comfortable {
+				option.Reasons = append(option.Reasons, in.Printer.T("suggestions.suits_energy"))
+			} else {
+				option.Reasons = append(option.Reasons, in.Printer.T("suggestions.above_energy"))
+			}
+		}
+		if in.Minutes > 0 && total > 0 {
+			option.Reasons = append(option.Reasons, in.Printer.T("suggestions.ready_within", total, in.Minutes))
+		}
+
+		options = append(options, option)
+	}
+
+	sort.SliceStable(options, func(a, b int) bool { return options[a].Score > options[b].Score })
+	if len(options) > limit {
+		options = options[:limit]
+	}
+	for i := range options {
+		options[i].Score = float64(int(options[i].Score*10)) / 10
+		options[i].PantryCoverage = float64(int(options[i].PantryCoverage*100)) / 100
+	}
+	return options
+}
+
+// Cookable ranks my recipes by what I can make right now: how much of
+// each I have in the pantry, how its effort compares with my latest
+// energy check-in, and whether it fits the time I have
+// @Summary What can I make right now?
+// @Tags suggestions
+// @Produce json
+// @Param minutes query int false "Time available in minutes (1-720); no limit when left out"
+// @Param energy_level query int false "Energy from 1 to 5; defaults to the latest check-in"
+// @Param limit query int false "Maximum options to return (1-50, default 10)"
+// @Router /suggestions/cookable [get]
+func (h *Handler) Cookable(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	query := params.Query(c)
+	minutes := query.Int("minutes", 0, 1, 720)
+	level := query.Int("energy_level", 0, 1, 5)
+	limit := query.Int("limit", 10, 1, 50)
+	if !query.Valid() {
+		return
+	}
+
+	ctx := c.Request.Context()
+	if level == 0 {
+		level, _ = energy.CurrentLevel(ctx, h.db, user.ID)
+	}
+
+	all, err := h.db.ListRecipes(ctx, database.RecipeFilter{UserID: user.ID})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	if _, err := recipes.AddSensory(ctx, h.db, all); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	restrictions, err := h.db.ListDietaryRestrictions(ctx, []string{user.ID})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	profile := sensory.LoadProfile(ctx, h.db, user.ID)
+	compatible := make([]*database.Recipe, 0, len(all))
+	for _, recipe := range all {
+		if !dietary.HasConflicts(recipe, restrictions) && !sensoryClash(recipe, profile) {
+			compatible = append(compatible, recipe)
+		}
+	}
+	if err := recipes.AddEffort(ctx, h.db, compatible); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	pantry, err := h.db.ListPantryItems(ctx, database.PantryFilter{UserID: user.ID})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	options := RankCookable(compatible, cookableInput{
+		EnergyLevel: level,
+		Minutes:     minutes,
+		Pantry:      pantry,
+		Printer:     locale.Printer(c, h.db, user.ID),
+	}, limit)
+
+	c.JSON(http.StatusOK, gin.H{
+		"energy_level": level,   // 0 when unknown
+		"minutes":      minutes, // 0 for no limit
+		"options":      options,
+	})
+}
@@ -0,0 +1,142 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package suggestions
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/dietary"
+	"github.com/rghsoftware/space-food/internal/features/energy"
+	"github.com/rghsoftware/space-food/internal/features/locale"
+	"github.com/rghsoftware/space-food/internal/features/mealtime"
+	"github.com/rghsoftware/space-food/internal/features/recipes"
+	"github.com/rghsoftware/space-food/internal/features/sensory"
+	"github.com/rghsoftware/space-food/internal/middleware"
+)
+
+// Handler handles meal suggestion HTTP requests
+type Handler struct {
+	db database.Database
+}
+
+// NewHandler creates a new suggestion handler
+func NewHandler(db database.Database) *Handler {
+	return &Handler{
+		db: db,
+	}
+}
+
+// RegisterRoutes registers suggestion routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.POST("/low-energy", h.LowEnergy)
+	router.GET("/cookable", h.Cookable)
+}
+
+// LowEnergy suggests three quick meals for when cooking feels like too much.
+// The body is optional; energy defaults to the latest check-in.
+// @Summary Suggest low-energy meals
+// @Tags suggestions
+// @Accept json
+// @Produce json
+// @Router /suggestions/low-energy [post]
+func (h *Handler) LowEnergy(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req struct {
+		EnergyLevel int `json:"energy_level" binding:"omitempty,min=1,max=5"`
+		MaxMinutes  int `json:"max_minutes" binding:"omitempty,min=1,max=120"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	if req.EnergyLevel == 0 {
+		req.EnergyLevel, _ = energy.CurrentLevel(ctx, h.db, user.ID)
+	}
+	if req.MaxMinutes == 0 {
+		req.MaxMinutes = 15
+	}
+
+	all, err := h.db.ListRecipes(ctx, database.RecipeFilter{UserID: user.ID})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	if _, err := recipes.AddSensory(ctx, h.db, all); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	restrictions, err := h.db.ListDietaryRestrictions(ctx, []string{user.ID})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	profile := sensory.LoadProfile(ctx, h.db, user.ID)
+	compatible := make([]*database.Recipe, 0, len(all))
+	for _, recipe := range all {
+		if !dietary.HasConflicts(recipe, restrictions) && !sensoryClash(recipe, profile) {
+			compatible = append(compatible, recipe)
+		}
+	}
+
+	pantry, err := h.db.ListPantryItems(ctx, database.PantryFilter{UserID: user.ID})
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	safeFoods, err := h.db.ListSafeFoods(ctx, user.ID)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	now := time.Now()
+	allowed := mealtime.AllowedMealTypes(mealtime.LoadPreferences(c, h.db, user.ID), now)
+
+	options := RankLowEnergy(compatible, lowEnergyInput{
+		EnergyLevel: req.EnergyLevel,
+		MaxMinutes:  req.MaxMinutes,
+		Pantry:      pantry,
+		SafeFoods:   safeFoods,
+		Sensory:     profile,
+		InWindow:    func(r *database.Recipe) bool { return mealtime.RecipeAllowed(r, allowed) },
+		Now:         now,
+		Printer:     locale.Printer(c, h.db, user.ID),
+	}, 3)
+
+	c.JSON(http.StatusOK, gin.H{
+		"energy_level": req.EnergyLevel, // 0 when unknown
+		"max_minutes":  req.MaxMinutes,
+		"options":      options,
+	})
+}
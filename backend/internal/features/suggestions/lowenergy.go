@@ -0,0 +1,300 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package suggestions
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/recipes"
+	"github.com/rghsoftware/space-food/internal/features/sensory"
+	"github.com/rghsoftware/space-food/internal/i18n"
+)
+
+// quickTags mark recipes as low effort even when they have no recorded times
+var quickTags = map[string]bool{
+	"quick":      true,
+	"no-cook":    true,
+	"no cook":    true,
+	"low-energy": true,
+	"low energy": true,
+	"easy":       true,
+	"5-minute":   true,
+	"10-minute":  true,
+	"15-minute":  true,
+}
+
+// expiringWithin is how soon a pantry item must expire to be worth using up
+const expiringWithin = 3 * 24 * time.Hour
+
+// Option is a ranked low-energy meal suggestion
+type Option struct {
+	RecipeID           string   `json:"recipe_id"`
+	Title              string   `json:"title"`
+	TotalMinutes       int      `json:"total_minutes"` // 0 when unknown
+	PantryCoverage     float64  `json:"pantry_coverage"`
+	MissingIngredients []string `json:"missing_ingredients"`
+	SafeFood           bool     `json:"safe_food"`
+	Reasons            []string `json:"reasons"`
+	Score              float64  `json:"score"`
+}
+
+// lowEnergyInput is what ranking needs to know about the user's situation
+type lowEnergyInput struct {
+	EnergyLevel int
+	MaxMinutes  int
+	Pantry      []*database.PantryItem
+	SafeFoods   []*database.SafeFood
+	Sensory     *database.SensoryProfile
+	InWindow    func(*database.Recipe) bool
+	Now         time.Time
+	Printer     *i18n.Printer // writes the reasons
+}
+
+// RankLowEnergy scores quick recipes by how little effort they take right
+// now. Having the ingredients matters most, then speed, then how few
+// ingredients there are to handle; on the lowest energy levels speed and
+// simplicity count for more. Safe foods get a boost as the least effort of
+// all is eating something familiar, and so do dishes that suit the user's
+// sensory profile.
+func RankLowEnergy(recipes []*database.Recipe, in lowEnergyInput, limit int) []Option {
+	effortWeight := 1.0
+	if in.EnergyLevel > 0 && in.EnergyLevel <= 2 {
+		effortWeight = 1.5
+	}
+
+	options := []Option{}
+	for _, recipe := range recipes {
+		total := recipe.PrepTime + recipe.CookTime
+		tagged := hasQuickTag(recipe)
+		if total > in.MaxMinutes || (total == 0 && !tagged) {
+			continue
+		}
+
+		option := Option{
+			RecipeID:           recipe.ID,
+			Title:              recipe.Title,
+			TotalMinutes:       total,
+			MissingIngredients: []string{},
+			Reasons:            []string{},
+		}
+
+		required, have := 0, 0
+		expiring := []string{}
+		for _, ingredient := range recipe.Ingredients {
+			item := FindPantryItem(ingredient.Name, in.Pantry)
+			if item != nil && item.ExpiryDate != nil && item.ExpiryDate.Sub(in.Now) < expiringWithin && item.ExpiryDate.After(in.Now) {
+				expiring = append(expiring, item.Name)
+			}
+			if ingredient.Optional {
+				continue
+			}
+			required++
+			if item != nil {
+				have++
+			} else {
+				option.MissingIngredients = append(option.MissingIngredients, ingredient.Name)
+			}
+		}
+
+		option.PantryCoverage = 1
+		if required > 0 {
+			option.PantryCoverage = float64(have) / float64(required)
+		}
+		option.Score = option.PantryCoverage * 50
+
+		if total > 0 {
+			option.Score += float64(in.MaxMinutes-total) / float64(in.MaxMinutes) * 20 * effortWeight
+		} else {
+			option.Score += 10 * effortWeight
+		}
+		option.Score += float64(max(0, 8-required)) * 2 * effortWeight
+
+		if in.InWindow != nil && in.InWindow(recipe) {
+			option.Score += 10
+		}
+		option.Score += recipe.Rating * 2
+		option.Score += float64(min(len(expiring), 2)) * 5
+		option.SafeFood = MatchSafeFood(recipe, in.SafeFoods) != nil
+		if option.SafeFood {
+			option.Score += 15
+		}
+		preferredTemperature := in.Sensory != nil && sensory.ServedAtPreferred(recipe, in.Sensory)
+		if preferredTemperature {
+			option.Score += 8
+		}
+		fingerFood := in.Sensory != nil && in.Sensory.EatingStyle == sensory.EatingStyleFingerFood && sensory.IsFingerFood(recipe)
+		if fingerFood {
+			option.Score += 8
+		}
+
+		if option.SafeFood {
+			option.Reasons = append(option.Reasons, in.Printer.T("suggestions.safe_food"))
+		}
+		switch {
+		case required > 0 && have == required:
+			option.Reasons = append(option.Reasons, in.Printer.T("suggestions.have_everything"))
+		case len(option.MissingIngredients) == 1:
+			option.Reasons = append(option.Reasons, in.Printer.T("suggestions.only_missing", option.MissingIngredients[0]))
+		}
+		if total > 0 {
+			option.Reasons = append(option.Reasons, in.Printer.T("suggestions.ready_in", total))
+		} else if tagged {
+			option.Reasons = append(option.Reasons, in.Printer.T("suggestions.tagged_quick"))
+		}
+		if required > 0 && required <= 4 {
+			option.Reasons = append(option.Reasons, in.Printer.T("suggestions.few_ingredients", required))
+		}
+		if preferredTemperature {
+			option.Reasons = append(option.Reasons, in.Printer.T("suggestions.served_as_liked"))
+		}
+		if fingerFood {
+			option.Reasons = append(option.Reasons, in.Printer.T("suggestions.finger_food"))
+		}
+		if len(expiring) > 0 {
+			option.Reasons = append(option.Reasons, in.Printer.T("suggestions.uses_expiring", strings.Join(expiring, ", ")))
+		}
+
+		options = append(options, option)
+	}
+
+	sort.SliceStable(options, func(a, b int) bool { return options[a].Score > options[b].Score })
+	if len(options) > limit {
+		options = options[:limit]
+	}
+	for i := range options {
+		options[i].Score = float64(int(options[i].Score*10)) / 10
+		options[i].PantryCoverage = float64(int(options[i].PantryCoverage*100)) / 100
+	}
+	return options
+}
+
+// hasQuickTag reports whether a recipe is tagged or categorised as low effort
+func hasQuickTag(recipe *database.Recipe) bool {
+	for _, label := range append(append([]string{}, recipe.Tags...), recipe.Categories...) {
+		if quickTags[strings.ToLower(strings.TrimSpace(label))] {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchSafeFood returns the safe food a recipe is, either because it is
+// linked to the recipe or because every word of its name is in the recipe
+// title, so "mac and cheese" matches "Baked Mac and Cheese"
+func MatchSafeFood(recipe *database.Recipe, foods []*database.SafeFood) *database.SafeFood {
+	for _, food := range foods {
+		if food.RecipeID != nil && *food.RecipeID == recipe.ID {
+			return food
+		}
+	}
+
+	title := words(recipe.Title)
+	for _, food := range foods {
+		if containsAll(title, words(food.Name)) {
+			return food
+		}
+	}
+	return nil
+}
+
+// sensoryClash reports whether a recipe has a texture or smell the user
+// avoids, is spicier than they like, or has a step that is loud, smelly or
+// spits oil when they avoid that. Textures are looked for in the title,
+// tags and categories; smells also in the ingredients. Spice and steps are
+// only checked when the recipe's Sensory is set.
+func sensoryClash(recipe *database.Recipe, profile *database.SensoryProfile) bool {
+	if recipe.Sensory != nil && len(recipes.SensoryConflicts(recipe, profile)) > 0 {
+		return true
+	}
+	labels := [][]string{words(recipe.Title)}
+	for _, label := range append(append([]string{}, recipe.Tags...), recipe.Categories...) {
+		labels = append(labels, words(label))
+	}
+	for _, texture := range profile.AvoidTextures {
+		for _, label := range labels {
+			if containsAll(label, words(texture)) {
+				return true
+			}
+		}
+	}
+
+	for _, ingredient := range recipe.Ingredients {
+		labels = append(labels, words(ingredient.Name))
+	}
+	for _, smell := range profile.SmellAversions {
+		for _, label := range labels {
+			if containsAll(label, words(smell)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FindPantryItem returns the pantry item matching an ingredient, comparing
+// whole words so "egg" matches "eggs" and "large eggs" but not "eggplant"
+func FindPantryItem(ingredient string, pantry []*database.PantryItem) *database.PantryItem {
+	ingredientWords := words(ingredient)
+	for _, item := range pantry {
+		if item.Quantity < 0 {
+			continue
+		}
+		itemWords := words(item.Name)
+		if len(itemWords) > 0 && (containsAll(ingredientWords, itemWords) || containsAll(itemWords, ingredientWords)) {
+			return item
+		}
+	}
+	return nil
+}
+
+// words splits text into lowercase words with a trailing plural "s" removed
+func words(s string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+	for i, f := range fields {
+		if len(f) > 3 && strings.HasSuffix(f, "s") && !strings.HasSuffix(f, "ss") {
+			fields[i] = strings.TrimSuffix(f, "s")
+		}
+	}
+	return fields
+}
+
+// containsAll reports whether every word of needle appears in haystack
+func containsAll(haystack, needle []string) bool {
+	if len(needle) == 0 {
+		return false
+	}
+	for _, n := range needle {
+		found := false
+		for _, h := range haystack {
+			if h == n {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,292 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package syncfeature
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/events"
+	"github.com/rghsoftware/space-food/internal/features/budget"
+	"github.com/rghsoftware/space-food/internal/features/cooking"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// Synced entities, as named by the change log triggers
+const (
+	entityRecipe           = "recipe"
+	entityMealPlan         = "meal_plan"
+	entityShoppingListItem = "shopping_list_item"
+	entityCookingSession   = "cooking_session"
+)
+
+// record is a synced record with what sync needs to know about it
+type record struct {
+	value     any // the database record, as its endpoints return it
+	userID    string
+	updatedAt time.Time
+}
+
+// kind reads and writes one entity. Entities without create, update or
+// remove can't have that done to them by a push.
+type kind struct {
+	load   func(ctx context.Context, db database.Database, id string) (*record, error)
+	fields []string // the fields a push may set, named as in the record's JSON
+	create func(h *Handler, ctx context.Context, userID, id string, data json.RawMessage, now time.Time) (*record, error)
+	update func(h *Handler, ctx context.Context, current *record, data json.RawMessage, now time.Time) (*record, error)
+	remove func(h *Handler, ctx context.Context, current *record) error
+}
+
+// The fields a push may set on each entity
+var (
+	mealPlanFields         = []string{"Title", "Description", "StartDate", "EndDate", "Meals"}
+	shoppingListItemFields = []string{"Name", "Quantity", "Unit", "Category", "Notes", "Completed", "Price", "Store"}
+	cookingSessionFields   = []string{"current_step", "status", "notes"}
+)
+
+var kinds = map[string]*kind{
+	entityRecipe: {
+		// Recipes are edited online, where revisions and imports live
+		load: func(ctx context.Context, db database.Database, id string) (*record, error) {
+			recipe, err := db.GetRecipeByID(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			return &record{value: recipe, userID: recipe.UserID, updatedAt: recipe.UpdatedAt}, nil
+		},
+	},
+	entityMealPlan: {
+		load:   loadMealPlan,
+		fields: mealPlanFields,
+		create: createMealPlan,
+		update: updateMealPlan,
+		remove: removeMealPlan,
+	},
+	entityShoppingListItem: {
+		load:   loadShoppingListItem,
+		fields: shoppingListItemFields,
+		create: createShoppingListItem,
+		update: updateShoppingListItem,
+		remove: removeShoppingListItem,
+	},
+	entityCookingSession: {
+		// Sessions are started online, from a recipe; offline the cook
+		// moves through the steps, pauses and finishes
+		load:   loadCookingSession,
+		fields: cookingSessionFields,
+		update: updateCookingSession,
+	},
+}
+
+// patch sets the fields of v present in data. Fields that aren't allowed
+// are refused rather than ignored, so a client never believes a change
+// was saved when it wasn't.
+func patch(v any, data json.RawMessage, allowed []string) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return invalid("data must be an object of fields to set")
+	}
+	for name := range fields {
+		known := false
+		for _, field := range allowed {
+			// Matched like encoding/json matches field names
+			if strings.EqualFold(name, field) {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return invalid("%s can't be changed by sync", name)
+		}
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return invalid("invalid data: %v", err)
+	}
+	return nil
+}
+
+func loadMealPlan(ctx context.Context, db database.Database, id string) (*record, error) {
+	plan, err := db.GetMealPlanByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &record{value: plan, userID: plan.UserID, updatedAt: plan.UpdatedAt}, nil
+}
+
+func validMealPlan(plan *database.MealPlan) error {
+	if strings.TrimSpace(plan.Title) == "" {
+		return invalid("Title is required")
+	}
+	if plan.EndDate.Before(plan.StartDate) {
+		return invalid("EndDate can't be before StartDate")
+	}
+	return nil
+}
+
+func createMealPlan(h *Handler, ctx context.Context, userID, id string, data json.RawMessage, now time.Time) (*record, error) {
+	plan := &database.MealPlan{}
+	if err := patch(plan, data, mealPlanFields); err != nil {
+		return nil, err
+	}
+	plan.ID = id
+	plan.UserID = userID
+	plan.CreatedAt = now
+	plan.UpdatedAt = now
+	if err := validMealPlan(plan); err != nil {
+		return nil, err
+	}
+	if err := h.db.CreateMealPlan(ctx, plan); err != nil {
+		return nil, err
+	}
+	return &record{value: plan, userID: userID, updatedAt: plan.UpdatedAt}, nil
+}
+
+func updateMealPlan(h *Handler, ctx context.Context, current *record, data json.RawMessage, now time.Time) (*record, error) {
+	plan := *current.value.(*database.MealPlan)
+	if err := patch(&plan, data, mealPlanFields); err != nil {
+		return nil, err
+	}
+	plan.UpdatedAt = now
+	if err := validMealPlan(&plan); err != nil {
+		return nil, err
+	}
+	if err := h.db.UpdateMealPlan(ctx, &plan); err != nil {
+		return nil, err
+	}
+	return &record{value: &plan, userID: plan.UserID, updatedAt: plan.UpdatedAt}, nil
+}
+
+func removeMealPlan(h *Handler, ctx context.Context, current *record) error {
+	return h.db.DeleteMealPlan(ctx, current.value.(*database.MealPlan).ID)
+}
+
+func loadShoppingListItem(ctx context.Context, db database.Database, id string) (*record, error) {
+	item, err := db.GetShoppingListItemByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &record{value: item, userID: item.UserID, updatedAt: item.UpdatedAt}, nil
+}
+
+func createShoppingListItem(h *Handler, ctx context.Context, userID, id string, data json.RawMessage, now time.Time) (*record, error) {
+	item := &database.ShoppingListItem{}
+	if err := patch(item, data, shoppingListItemFields); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(item.Name) == "" {
+		return nil, invalid("Name is required")
+	}
+	item.ID = id
+	item.UserID = userID
+	item.CreatedAt = now
+	item.UpdatedAt = now
+	if err := h.db.CreateShoppingListItem(ctx, item); err != nil {
+		return nil, err
+	}
+	h.events.Publish(ctx, events.NewShoppingListUpdated(events.ShoppingListItemAdded, item))
+	return &record{value: item, userID: userID, updatedAt: item.UpdatedAt}, nil
+}
+
+func updateShoppingListItem(h *Handler, ctx context.Context, current *record, data json.RawMessage, now time.Time) (*record, error) {
+	before := current.value.(*database.ShoppingListItem)
+	item := *before
+	if err := patch(&item, data, shoppingListItemFields); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(item.Name) == "" {
+		return nil, invalid("Name is required")
+	}
+	item.UpdatedAt = now
+	if err := h.db.UpdateShoppingListItem(ctx, &item); err != nil {
+		return nil, err
+	}
+
+	action := events.ShoppingListItemUpdated
+	if item.Completed != before.Completed {
+		// Ticked off or reopened while offline: record or take back the
+		// purchase, as the toggle does
+		purchaseErr := budget.UndoPurchase(ctx, h.db, &item)
+		if item.Completed && purchaseErr == nil {
+			purchaseErr = budget.RecordPurchase(ctx, h.db, &item, now)
+		}
+		if purchaseErr != nil {
+			logger.Ctx(ctx).Warn().Err(purchaseErr).Str("item_id", item.ID).Msg("Failed to record shopping list purchase")
+		}
+		action = events.ShoppingListItemReopened
+		if item.Completed {
+			action = events.ShoppingListItemCompleted
+		}
+	}
+	h.events.Publish(ctx, events.NewShoppingListUpdated(action, &item))
+	return &record{value: &item, userID: item.UserID, updatedAt: item.UpdatedAt}, nil
+}
+
+func removeShoppingListItem(h *Handler, ctx context.Context, current *record) error {
+	item := current.value.(*database.ShoppingListItem)
+	if err := h.db.DeleteShoppingListItem(ctx, item.ID); err != nil {
+		return err
+	}
+	h.events.Publish(ctx, events.NewShoppingListUpdated(events.ShoppingListItemRemoved, item))
+	return nil
+}
+
+func loadCookingSession(ctx context.Context, db database.Database, id string) (*record, error) {
+	session, err := db.GetCookingSessionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &record{value: session, userID: session.UserID, updatedAt: session.UpdatedAt}, nil
+}
+
+func updateCookingSession(h *Handler, ctx context.Context, current *record, data json.RawMessage, now time.Time) (*record, error) {
+	session := *current.value.(*database.CookingSession)
+	if cooking.IsFinished(&session) {
+		return nil, errFinished
+	}
+	wanted := struct {
+		CurrentStep int    `json:"current_step"`
+		Status      string `json:"status"`
+		Notes       string `json:"notes"`
+	}{session.CurrentStep, session.Status, session.Notes}
+	if err := patch(&wanted, data, cookingSessionFields); err != nil {
+		return nil, err
+	}
+	if wanted.CurrentStep < 0 || wanted.CurrentStep >= len(session.Steps) {
+		return nil, invalid("current_step must be between 0 and %d", len(session.Steps)-1)
+	}
+	switch wanted.Status {
+	case database.CookingSessionActive, database.CookingSessionPaused,
+		database.CookingSessionCompleted, database.CookingSessionAbandoned:
+	default:
+		return nil, invalid("status must be active, paused, completed or abandoned")
+	}
+	if utf8.RuneCountInString(wanted.Notes) > cooking.MaxNotesLength {
+		return nil, invalid("notes can be at most %d characters", cooking.MaxNotesLength)
+	}
+	session.Notes = wanted.Notes
+
+	if err := cooking.ApplyState(ctx, h.db, &session, wanted.CurrentStep, wanted.Status, now); err != nil {
+		return nil, err
+	}
+	return &record{value: &session, userID: session.UserID, updatedAt: session.UpdatedAt}, nil
+}
@@ -0,0 +1,174 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package syncfeature lets offline-capable clients keep a local copy of a
+// user's recipes, meal plans, shopping list and cooking sessions. Clients
+// pull what changed since their cursor and push the edits they queued
+// while offline, which the server merges with changes made meanwhile.
+package syncfeature
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/api/params"
+	"github.com/rghsoftware/space-food/internal/api/precondition"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/config"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/events"
+	"github.com/rghsoftware/space-food/internal/middleware"
+)
+
+// Handler handles offline sync HTTP requests
+type Handler struct {
+	db            database.Database
+	events        *events.Bus
+	tombstoneDays int
+}
+
+// NewHandler creates a new sync handler
+func NewHandler(cfg *config.Config, db database.Database, bus *events.Bus) *Handler {
+	return &Handler{
+		db:            db,
+		events:        bus,
+		tombstoneDays: cfg.Sync.TombstoneDays,
+	}
+}
+
+// RegisterRoutes registers sync routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/changes", h.ListChanges)
+	router.POST("/push", h.Push)
+}
+
+// change is one changed record in a pull
+type change struct {
+	Cursor    int64     `json:"cursor"`
+	Entity    string    `json:"entity"`
+	ID        string    `json:"id"`
+	Deleted   bool      `json:"deleted"`
+	ChangedAt time.Time `json:"changed_at"`
+	Version   string    `json:"version,omitempty"` // send back as base_version when pushing an edit
+	Data      any       `json:"data,omitempty"`    // the record as its own endpoints return it
+}
+
+// ListChanges returns the records that changed after a cursor, oldest
+// change first. A record changed several times appears once, at its latest
+// change.
+// @Summary Pull changes for offline sync
+// @Tags sync
+// @Produce json
+// @Param since query int false "Cursor of the last change already pulled; 0 downloads everything"
+// @Param limit query int false "Changes per page, at most 500"
+// @Router /sync/changes [get]
+func (h *Handler) ListChanges(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	query := params.Query(c)
+	since := int64(query.Int("since", 0, 0, math.MaxInt))
+	limit := query.Int("limit", 100, 1, 500)
+	if !query.Valid() {
+		return
+	}
+
+	ctx := c.Request.Context()
+	cursor, err := h.db.GetSyncCursor(ctx, user.ID)
+	if apierror.IsNotFound(err) {
+		cursor, err = &database.SyncCursor{UserID: user.ID}, nil
+	}
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	// A cursor from before purged tombstones may have missed deletions, and
+	// one past the counter belongs to data since restored from a backup
+	if since > cursor.Seq || (since > 0 && since < cursor.PurgedSeq) {
+		apierror.Abort(c, apierror.New(http.StatusGone, apierror.CodeGone,
+			"this sync cursor has expired; download everything again with since=0"))
+		return
+	}
+
+	rows, err := h.db.ListSyncChanges(ctx, user.ID, since, limit+1)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	hasMore := len(rows) > limit
+	if hasMore {
+		rows = rows[:limit]
+	}
+
+	changes := []change{}
+	next := since
+	for _, row := range rows {
+		next = row.Seq
+		item := change{
+			Cursor:    row.Seq,
+			Entity:    row.Entity,
+			ID:        row.EntityID,
+			Deleted:   row.Deleted,
+			ChangedAt: row.ChangedAt,
+		}
+		k, known := kinds[row.Entity]
+		if !known {
+			continue
+		}
+		if !row.Deleted {
+			record, err := k.load(ctx, h.db, row.EntityID)
+			switch {
+			case apierror.IsNotFound(err):
+				// Deleted since the page was read; the next pull has it
+				continue
+			case err != nil:
+				apierror.Respond(c, err)
+				return
+			}
+			item.Data = record.value
+			item.Version = precondition.Version(record.updatedAt)
+		} else if since == 0 {
+			// A client starting from nothing has nothing to delete
+			continue
+		}
+		changes = append(changes, item)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"changes":  changes,
+		"cursor":   next,
+		"has_more": hasMore,
+	})
+}
+
+// PurgeTombstones is the scheduled job forgetting records deleted more than
+// sync.tombstonedays ago
+func (h *Handler) PurgeTombstones(ctx context.Context) (string, error) {
+	n, err := h.db.PurgeSyncTombstones(ctx, time.Now().AddDate(0, 0, -h.tombstoneDays))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("removed %d sync tombstones", n), nil
+}
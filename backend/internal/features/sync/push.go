@@ -0,0 +1,251 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package syncfeature
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/api/precondition"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/middleware"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// maxPushOperations is how many queued operations one push may carry
+const maxPushOperations = 100
+
+// Push results
+const (
+	statusApplied  = "applied"  // saved as sent
+	statusMerged   = "merged"   // saved over changes made on the server since the client's version
+	statusConflict = "conflict" // not saved; data holds the record as the server has it, unless deleted
+	statusRejected = "rejected" // not saved and never will be; reason says why
+	statusError    = "error"    // not saved because of a problem on the server; push it again later
+)
+
+// errFinished is an edit to a cooking session that finished on the server
+var errFinished = errors.New("this cooking session has finished")
+
+// invalidError is an operation the server refuses, with the reason given
+// to the client
+type invalidError struct {
+	reason string
+}
+
+func (e *invalidError) Error() string { return e.reason }
+
+func invalid(format string, args ...any) error {
+	return &invalidError{reason: fmt.Sprintf(format, args...)}
+}
+
+// operation is one change a client queued while offline. Clients choose the
+// IDs of records they create, so later operations in the queue can refer
+// to them.
+type operation struct {
+	OpID        string          `json:"op_id"` // the client's name for the operation, echoed in its result
+	Entity      string          `json:"entity" binding:"required"`
+	Action      string          `json:"action" binding:"required,oneof=create update delete"`
+	ID          string          `json:"id" binding:"required,uuid"`
+	BaseVersion string          `json:"base_version"` // the version the client changed, from a pull
+	Data        json.RawMessage `json:"data"`         // the fields to set; for updates only the changed ones
+}
+
+// result is what became of an operation
+type result struct {
+	OpID    string `json:"op_id,omitempty"`
+	Entity  string `json:"entity"`
+	ID      string `json:"id"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Version string `json:"version,omitempty"`
+	Data    any    `json:"data,omitempty"`
+}
+
+// Push applies the operations a client queued while offline, in order.
+// Each succeeds or fails on its own. Updates are applied as patches to the
+// record as it is now, so edits made elsewhere to other fields are kept;
+// where both changed a field, the push wins. A record deleted on the
+// server stays deleted, and a delete of a record changed on the server
+// since the client's version is refused.
+// @Summary Push changes made offline
+// @Tags sync
+// @Accept json
+// @Produce json
+// @Router /sync/push [post]
+func (h *Handler) Push(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req struct {
+		Operations []operation `json:"operations" binding:"required,min=1,dive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+	if len(req.Operations) > maxPushOperations {
+		apierror.BadRequest(c, fmt.Sprintf("push at most %d operations at a time", maxPushOperations))
+		return
+	}
+
+	ctx := c.Request.Context()
+	now := time.Now()
+	original := map[string]time.Time{} // versions before this push, by entity and ID
+	results := make([]result, 0, len(req.Operations))
+	for _, op := range req.Operations {
+		res := h.apply(ctx, user.ID, op, original, now)
+		results = append(results, res)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
+
+// apply carries out one operation
+func (h *Handler) apply(ctx context.Context, userID string, op operation, original map[string]time.Time, now time.Time) result {
+	res := result{OpID: op.OpID, Entity: op.Entity, ID: op.ID}
+	k, ok := kinds[op.Entity]
+	if !ok {
+		return res.reject("unknown entity")
+	}
+
+	switch {
+	case op.Action == "create" && k.create == nil:
+		return res.reject("%s records can't be created by sync", op.Entity)
+	case op.Action == "update" && k.update == nil:
+		return res.reject("%s records can't be changed by sync", op.Entity)
+	case op.Action == "delete" && k.remove == nil:
+		return res.reject("%s records can't be deleted by sync", op.Entity)
+	}
+
+	current, err := k.load(ctx, h.db, op.ID)
+	if apierror.IsNotFound(err) {
+		current, err = nil, nil
+	}
+	if err != nil {
+		return res.fail(ctx, err)
+	}
+	if current != nil && current.userID != userID {
+		if op.Action == "create" {
+			return res.reject("this id is already in use")
+		}
+		return res.reject("not found")
+	}
+
+	// The record changed since the client's version. Earlier operations in
+	// this push don't count: the client edited offline from the version the
+	// record had before the push.
+	key := op.Entity + ":" + op.ID
+	stale := false
+	if op.BaseVersion != "" && current != nil {
+		base, err := time.Parse(time.RFC3339Nano, op.BaseVersion)
+		if err != nil {
+			return res.reject("base_version must be a version from a pull")
+		}
+		version := current.updatedAt
+		if before, ok := original[key]; ok {
+			version = before
+		}
+		stale = precondition.Version(base) != precondition.Version(version)
+	}
+	if _, ok := original[key]; !ok && current != nil {
+		original[key] = current.updatedAt
+	}
+
+	var saved *record
+	switch op.Action {
+	case "create":
+		if current != nil {
+			// Pushed before, and the answer was lost
+			return res.with(statusApplied, current)
+		}
+		saved, err = k.create(h, ctx, userID, op.ID, op.Data, now)
+		res.Status = statusApplied
+
+	case "update":
+		if current == nil {
+			res.Status, res.Reason = statusConflict, "deleted on the server"
+			return res
+		}
+		saved, err = k.update(h, ctx, current, op.Data, now)
+		if errors.Is(err, errFinished) {
+			res.Reason = err.Error()
+			return res.with(statusConflict, current)
+		}
+		res.Status = statusApplied
+		if stale {
+			res.Status = statusMerged
+		}
+
+	case "delete":
+		if current == nil {
+			res.Status = statusApplied
+			return res
+		}
+		if stale {
+			res.Reason = "changed on the server since your version, so it was kept"
+			return res.with(statusConflict, current)
+		}
+		if err := k.remove(h, ctx, current); err != nil {
+			return res.fail(ctx, err)
+		}
+		res.Status = statusApplied
+		return res
+	}
+
+	if err != nil {
+		return res.fail(ctx, err)
+	}
+	return res.with(res.Status, saved)
+}
+
+// with sets the result's status and the record it leaves behind
+func (r result) with(status string, rec *record) result {
+	r.Status = status
+	r.Data = rec.value
+	r.Version = precondition.Version(rec.updatedAt)
+	return r
+}
+
+func (r result) reject(format string, args ...any) result {
+	r.Status = statusRejected
+	r.Reason = fmt.Sprintf(format, args...)
+	return r
+}
+
+// fail reports an operation that couldn't be carried out, rejecting
+// invalid ones and logging unexpected errors
+func (r result) fail(ctx context.Context, err error) result {
+	var invalidErr *invalidError
+	if errors.As(err, &invalidErr) {
+		return r.reject("%s", invalidErr.reason)
+	}
+	logger.Ctx(ctx).Error().Err(err).Str("entity", r.Entity).Str("id", r.ID).Msg("Failed to apply sync operation")
+	r.Status = statusError
+	r.Reason = "couldn't be saved; push it again later"
+	return r
+}
@@ -0,0 +1,388 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package translation translates recipes into other languages with the
+// configured AI provider, and keeps the translations alongside the
+// original for everyone who reads the recipe in that language.
+package translation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/ai"
+	"github.com/rghsoftware/space-food/internal/api/params"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/authz"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/features/aiprompts"
+	"github.com/rghsoftware/space-food/internal/features/aiusage"
+	"github.com/rghsoftware/space-food/internal/features/capabilities"
+	"github.com/rghsoftware/space-food/internal/features/locale"
+	"github.com/rghsoftware/space-food/internal/features/recipes"
+	"github.com/rghsoftware/space-food/internal/middleware"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+func init() {
+	capabilities.RegisterAIFeature(capabilities.AIFeature{
+		Name:        aiusage.FeatureTranslation,
+		UsesAI:      true,
+		Fallback:    "manual",
+		Description: "recipes cannot be machine translated; authors can still write translations themselves",
+	})
+}
+
+// Handler handles recipe translation HTTP requests
+type Handler struct {
+	db      database.Database
+	ai      *ai.Source
+	prompts *aiprompts.Registry
+	tracker *aiusage.Tracker
+}
+
+// NewHandler creates a new translation handler
+func NewHandler(db database.Database, source *ai.Source, prompts *aiprompts.Registry, tracker *aiusage.Tracker) *Handler {
+	return &Handler{
+		db:      db,
+		ai:      source,
+		prompts: prompts,
+		tracker: tracker,
+	}
+}
+
+// RegisterRecipeRoutes registers translation routes under /recipes
+func (h *Handler) RegisterRecipeRoutes(router *gin.RouterGroup) {
+	router.POST("/:id/translate", h.tracker.RequireBudget(), h.TranslateRecipe)
+	router.GET("/:id/translations", h.ListTranslations)
+	router.PUT("/:id/translations/:lang", h.UpdateTranslation)
+	router.DELETE("/:id/translations/:lang", h.DeleteTranslation)
+}
+
+// TranslateRecipe translates a recipe's title, description, ingredients
+// and instructions with the AI provider and stores the translation. A
+// translation that is still current is returned as it is, unless the
+// recipe's author asks for a fresh one with force.
+// @Summary Translate recipe
+// @Tags recipes
+// @Produce json
+// @Param id path string true "Recipe ID"
+// @Param lang query string false "Language to translate into, e.g. es; defaults to your locale"
+// @Param force query bool false "Translate again even if a current translation exists (author only)"
+// @Success 201 {object} database.RecipeTranslation
+// @Router /recipes/{id}/translate [post]
+func (h *Handler) TranslateRecipe(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	query := params.Query(c)
+	lang := query.String("lang")
+	force := query.Bool("force")
+	if !query.Valid() {
+		return
+	}
+
+	ctx := c.Request.Context()
+	if lang == "" {
+		lang = locale.Preferred(ctx, h.db, user.ID)
+		if lang == "" {
+			apierror.BadRequest(c, "say which language to translate into with lang, or set your locale")
+			return
+		}
+	}
+	language, ok := locale.Normalize(lang)
+	if !ok {
+		apierror.BadRequest(c, "lang must be a language tag such as es or pt-BR")
+		return
+	}
+
+	recipe, ok := h.viewRecipe(c, user.ID)
+	if !ok {
+		return
+	}
+	if force && !authz.CanEditRecipe(user.ID, recipe) {
+		apierror.Forbidden(c, "only the recipe's author can replace its translations")
+		return
+	}
+
+	existing, err := h.db.GetRecipeTranslation(ctx, recipe.ID, language)
+	if err != nil && !apierror.IsNotFound(err) {
+		apierror.Respond(c, err)
+		return
+	}
+	if existing != nil && !force && !recipes.TranslationOutdated(existing, recipe) {
+		c.JSON(http.StatusOK, existing)
+		return
+	}
+
+	provider := h.ai.Provider()
+	if provider == nil {
+		apierror.Abort(c, apierror.New(http.StatusServiceUnavailable, apierror.CodeServiceUnavailable,
+			"translation needs an AI provider, and none is configured on this instance"))
+		return
+	}
+	translation, err := h.translate(ctx, provider, user.ID, recipe, language)
+	if err != nil {
+		logger.Ctx(ctx).Warn().Err(err).Str("provider", provider.Name()).Str("recipe_id", recipe.ID).Msg("AI translation failed")
+		apierror.Abort(c, apierror.New(http.StatusBadGateway, apierror.CodeUpstream,
+			"the AI provider couldn't translate this recipe; try again later"))
+		return
+	}
+
+	now := time.Now()
+	translation.CreatedAt = now
+	if existing != nil {
+		translation.CreatedAt = existing.CreatedAt
+	}
+	translation.UpdatedAt = now
+	if err := h.db.UpsertRecipeTranslation(ctx, translation); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, translation)
+}
+
+// aiRecipe is the text sent to the provider, and the shape of its answer
+type aiRecipe struct {
+	Title        string                          `json:"title"`
+	Description  string                          `json:"description"`
+	Instructions string                          `json:"instructions"`
+	Ingredients  []database.TranslatedIngredient `json:"ingredients"`
+
+	want int // ingredients the answer must have
+}
+
+// Validate checks that a translation has a title and one ingredient for
+// each of the recipe's
+func (r *aiRecipe) Validate() error {
+	if strings.TrimSpace(r.Title) == "" {
+		return fmt.Errorf("translation has no title")
+	}
+	if len(r.Ingredients) != r.want {
+		return fmt.Errorf("translation has %d ingredients, the recipe %d", len(r.Ingredients), r.want)
+	}
+	return nil
+}
+
+// translate asks the provider for a translation on the user's behalf
+func (h *Handler) translate(ctx context.Context, provider ai.Provider, userID string, recipe *database.Recipe, language string) (*database.RecipeTranslation, error) {
+	source := aiRecipe{
+		Title:        recipe.Title,
+		Description:  recipe.Description,
+		Instructions: recipe.Instructions,
+		Ingredients:  make([]database.TranslatedIngredient, 0, len(recipe.Ingredients)),
+	}
+	for _, ingredient := range recipe.Ingredients {
+		source.Ingredients = append(source.Ingredients, database.TranslatedIngredient{Name: ingredient.Name, Notes: ingredient.Notes})
+	}
+	payload, err := json.Marshal(source)
+	if err != nil {
+		return nil, err
+	}
+
+	req, promptID, err := h.prompts.Request(aiprompts.Translation, aiprompts.TranslationData{
+		Language: locale.Name(language),
+		Recipe:   string(payload),
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Room for the whole recipe again, at roughly four characters a token
+	req.MaxTokens = min(max(len(payload)/2, 1024), 8192)
+
+	answer := aiRecipe{want: len(source.Ingredients)}
+	resp, err := ai.GenerateJSON(ai.WithCaller(ctx, userID, aiusage.FeatureTranslation), provider, req, &answer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &database.RecipeTranslation{
+		RecipeID:          recipe.ID,
+		Language:          language,
+		Title:             strings.TrimSpace(answer.Title),
+		Description:       strings.TrimSpace(answer.Description),
+		Instructions:      strings.TrimSpace(answer.Instructions),
+		Ingredients:       answer.Ingredients,
+		MachineTranslated: true,
+		Model:             resp.Model,
+		PromptID:          promptID,
+		SourceUpdatedAt:   recipe.UpdatedAt,
+	}, nil
+}
+
+// ListTranslations lists the languages a recipe has been translated into
+// @Summary List recipe translations
+// @Tags recipes
+// @Produce json
+// @Param id path string true "Recipe ID"
+// @Router /recipes/{id}/translations [get]
+func (h *Handler) ListTranslations(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+	recipe, ok := h.viewRecipe(c, user.ID)
+	if !ok {
+		return
+	}
+
+	translations, err := h.db.ListRecipeTranslations(c.Request.Context(), recipe.ID)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	for _, translation := range translations {
+		translation.Outdated = recipes.TranslationOutdated(translation, recipe)
+	}
+	c.JSON(http.StatusOK, translations)
+}
+
+// UpdateTranslation saves the author's own translation, or their
+// corrections to a machine translation, which is then no longer marked as
+// machine translated
+// @Summary Write a recipe translation
+// @Tags recipes
+// @Accept json
+// @Produce json
+// @Param id path string true "Recipe ID"
+// @Param lang path string true "Language, e.g. es"
+// @Router /recipes/{id}/translations/{lang} [put]
+func (h *Handler) UpdateTranslation(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+	language, ok := locale.Normalize(c.Param("lang"))
+	if !ok {
+		apierror.BadRequest(c, "lang must be a language tag such as es or pt-BR")
+		return
+	}
+	recipe, ok := h.viewRecipe(c, user.ID)
+	if !ok {
+		return
+	}
+	if !authz.CanEditRecipe(user.ID, recipe) {
+		apierror.Forbidden(c, "only the recipe's author can edit its translations")
+		return
+	}
+
+	var req struct {
+		Title        string                          `json:"title" binding:"required,max=255"`
+		Description  string                          `json:"description"`
+		Instructions string                          `json:"instructions"`
+		Ingredients  []database.TranslatedIngredient `json:"ingredients"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+	if len(req.Ingredients) != len(recipe.Ingredients) {
+		apierror.BadRequest(c, fmt.Sprintf("ingredients must list all %d of the recipe's ingredients, in order", len(recipe.Ingredients)))
+		return
+	}
+
+	ctx := c.Request.Context()
+	existing, err := h.db.GetRecipeTranslation(ctx, recipe.ID, language)
+	if err != nil && !apierror.IsNotFound(err) {
+		apierror.Respond(c, err)
+		return
+	}
+	now := time.Now()
+	translation := &database.RecipeTranslation{
+		RecipeID:        recipe.ID,
+		Language:        language,
+		Title:           req.Title,
+		Description:     req.Description,
+		Instructions:    req.Instructions,
+		Ingredients:     req.Ingredients,
+		SourceUpdatedAt: recipe.UpdatedAt,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if existing != nil {
+		translation.CreatedAt = existing.CreatedAt
+	}
+	if err := h.db.UpsertRecipeTranslation(ctx, translation); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, translation)
+}
+
+// DeleteTranslation removes a recipe's translation into a language
+// @Summary Delete a recipe translation
+// @Tags recipes
+// @Param id path string true "Recipe ID"
+// @Param lang path string true "Language, e.g. es"
+// @Success 204
+// @Router /recipes/{id}/translations/{lang} [delete]
+func (h *Handler) DeleteTranslation(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+	language, ok := locale.Normalize(c.Param("lang"))
+	if !ok {
+		apierror.NotFound(c, "translation not found")
+		return
+	}
+	recipe, ok := h.viewRecipe(c, user.ID)
+	if !ok {
+		return
+	}
+	if !authz.CanEditRecipe(user.ID, recipe) {
+		apierror.Forbidden(c, "only the recipe's author can delete its translations")
+		return
+	}
+
+	ctx := c.Request.Context()
+	if _, err := h.db.GetRecipeTranslation(ctx, recipe.ID, language); err != nil {
+		apierror.Lookup(c, err, "translation not found")
+		return
+	}
+	if err := h.db.DeleteRecipeTranslation(ctx, recipe.ID, language); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// viewRecipe loads the recipe in the path, checking the user may read it
+func (h *Handler) viewRecipe(c *gin.Context, userID string) (*database.Recipe, bool) {
+	recipe, err := h.db.GetRecipeByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierror.Lookup(c, err, "recipe not found")
+		return nil, false
+	}
+	if !authz.CanViewRecipe(userID, recipe) {
+		apierror.NotFound(c, "recipe not found")
+		return nil, false
+	}
+	return recipe, true
+}
@@ -0,0 +1,372 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package webhooks
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/api/params"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/authz"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/events"
+	"github.com/rghsoftware/space-food/internal/middleware"
+)
+
+// Handler handles webhook HTTP requests
+type Handler struct {
+	db         database.Database
+	dispatcher *Dispatcher
+}
+
+// NewHandler creates a new webhook handler
+func NewHandler(db database.Database, dispatcher *Dispatcher) *Handler {
+	return &Handler{
+		db:         db,
+		dispatcher: dispatcher,
+	}
+}
+
+// RegisterRoutes registers the current user's webhook routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("", h.ListWebhooks)
+	router.POST("", h.CreateWebhook)
+	router.GET("/events", h.ListEventTypes)
+	router.PUT("/:id", h.UpdateWebhook)
+	router.DELETE("/:id", h.DeleteWebhook)
+	router.POST("/:id/rotate-secret", h.RotateSecret)
+	router.POST("/:id/test", h.TestWebhook)
+	router.GET("/:id/deliveries", h.ListDeliveries)
+}
+
+// webhookWithSecret is returned only when a secret is created or rotated
+type webhookWithSecret struct {
+	*database.Webhook
+	Secret string `json:"secret"`
+}
+
+// ListWebhooks lists the authenticated user's webhooks
+// @Summary List webhooks
+// @Tags webhooks
+// @Produce json
+// @Router /me/webhooks [get]
+func (h *Handler) ListWebhooks(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	webhooks, err := h.db.ListWebhooks(c.Request.Context(), user.ID)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// ListEventTypes lists the event types webhooks can subscribe to
+// @Summary List webhook event types
+// @Tags webhooks
+// @Produce json
+// @Router /me/webhooks/events [get]
+func (h *Handler) ListEventTypes(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"events": events.Types()})
+}
+
+// CreateWebhook registers a webhook. The signing secret is only returned
+// in this response.
+// @Summary Create webhook
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Router /me/webhooks [post]
+func (h *Handler) CreateWebhook(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req struct {
+		URL         string   `json:"url" binding:"required"`
+		Events      []string `json:"events"`
+		Description string   `json:"description"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+	if err := h.dispatcher.CheckURL(req.URL); err != nil {
+		apierror.BadRequest(c, err.Error())
+		return
+	}
+	eventTypes, err := validateEvents(req.Events)
+	if err != nil {
+		apierror.BadRequest(c, err.Error())
+		return
+	}
+
+	secret, err := GenerateSecret()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+
+	now := time.Now()
+	webhook := database.Webhook{
+		ID:          uuid.New().String(),
+		UserID:      user.ID,
+		URL:         req.URL,
+		Secret:      secret,
+		Events:      eventTypes,
+		Description: req.Description,
+		Active:      true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := h.db.CreateWebhook(c.Request.Context(), &webhook); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhookWithSecret{Webhook: &webhook, Secret: secret})
+}
+
+// UpdateWebhook changes a webhook's URL, events, description or active state
+// @Summary Update webhook
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Router /me/webhooks/{id} [put]
+func (h *Handler) UpdateWebhook(c *gin.Context) {
+	webhook, ok := h.ownedWebhook(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		URL         *string   `json:"url"`
+		Events      *[]string `json:"events"`
+		Description *string   `json:"description"`
+		Active      *bool     `json:"active"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Invalid(c, err)
+		return
+	}
+
+	if req.URL != nil {
+		if err := h.dispatcher.CheckURL(*req.URL); err != nil {
+			apierror.BadRequest(c, err.Error())
+			return
+		}
+		webhook.URL = *req.URL
+	}
+	if req.Events != nil {
+		eventTypes, err := validateEvents(*req.Events)
+		if err != nil {
+			apierror.BadRequest(c, err.Error())
+			return
+		}
+		webhook.Events = eventTypes
+	}
+	if req.Description != nil {
+		webhook.Description = *req.Description
+	}
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+	webhook.UpdatedAt = time.Now()
+
+	if err := h.db.UpdateWebhook(c.Request.Context(), webhook); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// DeleteWebhook deletes a webhook and its delivery history
+// @Summary Delete webhook
+// @Tags webhooks
+// @Param id path string true "Webhook ID"
+// @Success 204
+// @Router /me/webhooks/{id} [delete]
+func (h *Handler) DeleteWebhook(c *gin.Context) {
+	webhook, ok := h.ownedWebhook(c)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteWebhook(c.Request.Context(), webhook.ID); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RotateSecret replaces a webhook's signing secret and returns the new one
+// @Summary Rotate webhook secret
+// @Tags webhooks
+// @Produce json
+// @Router /me/webhooks/{id}/rotate-secret [post]
+func (h *Handler) RotateSecret(c *gin.Context) {
+	webhook, ok := h.ownedWebhook(c)
+	if !ok {
+		return
+	}
+
+	secret, err := GenerateSecret()
+	if err != nil {
+		apierror.Internal(c, err)
+		return
+	}
+	webhook.Secret = secret
+	webhook.UpdatedAt = time.Now()
+
+	if err := h.db.UpdateWebhook(c.Request.Context(), webhook); err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhookWithSecret{Webhook: webhook, Secret: secret})
+}
+
+// TestWebhook sends a ping event to a webhook and reports how it went
+// @Summary Test webhook
+// @Tags webhooks
+// @Produce json
+// @Router /me/webhooks/{id}/test [post]
+func (h *Handler) TestWebhook(c *gin.Context) {
+	webhook, ok := h.ownedWebhook(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.dispatcher.Test(c.Request.Context(), webhook)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+// ListDeliveries lists a webhook's recent deliveries, newest first
+// @Summary List webhook deliveries
+// @Tags webhooks
+// @Produce json
+// @Param status query string false "pending, succeeded or failed"
+// @Param limit query int false "Maximum deliveries to return (1-200, default 50)"
+// @Param offset query int false "Deliveries to skip"
+// @Param cursor query string false "Keyset paging: empty for the first page, then next_cursor"
+// @Param include_total query bool false "Count all matching deliveries"
+// @Router /me/webhooks/{id}/deliveries [get]
+func (h *Handler) ListDeliveries(c *gin.Context) {
+	webhook, ok := h.ownedWebhook(c)
+	if !ok {
+		return
+	}
+
+	query := params.Query(c)
+	status := query.String("status", database.WebhookDeliveryPending, database.WebhookDeliverySucceeded, database.WebhookDeliveryFailed)
+	page := query.Page(50)
+	withTotal := query.Bool("include_total")
+	if !query.Valid() {
+		return
+	}
+
+	ctx := c.Request.Context()
+	filter := database.WebhookDeliveryFilter{
+		WebhookID: webhook.ID,
+		Status:    status,
+		After:     page.After,
+		Limit:     page.Fetch(),
+		Offset:    page.Offset,
+	}
+	deliveries, err := h.db.ListWebhookDeliveries(ctx, filter)
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+
+	var total *int
+	if withTotal {
+		count, err := h.db.CountWebhookDeliveries(ctx, filter)
+		if err != nil {
+			apierror.Respond(c, err)
+			return
+		}
+		total = &count
+	}
+
+	deliveries, next := params.Trim(deliveries, page, func(d *database.WebhookDelivery) database.Cursor {
+		return database.Cursor{Time: d.CreatedAt, ID: d.ID}
+	})
+	params.WriteList(c, deliveries, page, next, total)
+}
+
+// ownedWebhook loads the webhook named in the path, writing an error
+// response unless it belongs to the authenticated user
+func (h *Handler) ownedWebhook(c *gin.Context) (*database.Webhook, bool) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return nil, false
+	}
+
+	webhook, err := h.db.GetWebhookByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierror.Lookup(c, err, "webhook not found")
+		return nil, false
+	}
+
+	if !authz.Owns(user.ID, webhook.UserID) {
+		apierror.Forbidden(c, "forbidden")
+		return nil, false
+	}
+
+	return webhook, true
+}
+
+// validateEvents checks event types, dropping duplicates
+func validateEvents(eventTypes []string) ([]string, error) {
+	result := []string{}
+	seen := map[string]bool{}
+	for _, t := range eventTypes {
+		t = strings.TrimSpace(t)
+		if !events.IsType(t) {
+			return nil, fmt.Errorf("unknown event type: %s", t)
+		}
+		if !seen[t] {
+			seen[t] = true
+			result = append(result, t)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,315 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/config"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/events"
+	"github.com/rghsoftware/space-food/internal/fetch"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// Delivery request headers
+const (
+	HeaderEvent     = "X-SpaceFood-Event"
+	HeaderDelivery  = "X-SpaceFood-Delivery"
+	HeaderTimestamp = "X-SpaceFood-Timestamp"
+	HeaderSignature = "X-SpaceFood-Signature"
+)
+
+// retryBackoff is the wait before each retry; the last step repeats
+var retryBackoff = []time.Duration{
+	time.Minute,
+	5 * time.Minute,
+	30 * time.Minute,
+	2 * time.Hour,
+	12 * time.Hour,
+}
+
+// Webhook URL errors
+var (
+	errInvalidURL = errors.New("url must be an absolute http or https URL")
+	errPrivateURL = errors.New("url must be a public address; set webhooks.allowprivatenetworks to deliver to your own network")
+)
+
+// retryBatch is how many due deliveries one retry run attempts
+const retryBatch = 100
+
+// Dispatcher delivers events to the webhooks subscribed to them. Each
+// delivery is recorded before it is attempted, so failed attempts are
+// retried by the "webhook-retry" job even across restarts.
+type Dispatcher struct {
+	db      database.Database
+	cfg     config.WebhooksConfig
+	client  *fetch.Client
+	timeout time.Duration
+}
+
+// NewDispatcher creates a new webhook dispatcher. Deliveries only reach
+// public addresses, checked as each connection is made and on every
+// redirect, unless webhooks.allowprivatenetworks is set.
+func NewDispatcher(db database.Database, cfg config.WebhooksConfig) *Dispatcher {
+	return &Dispatcher{
+		db:  db,
+		cfg: cfg,
+		client: fetch.New(config.FetchConfig{
+			AllowPrivateNetworks: cfg.AllowPrivateNetworks,
+			Timeout:              cfg.Timeout,
+		}, 0),
+		timeout: time.Duration(max(cfg.Timeout, 1)) * time.Second,
+	}
+}
+
+// CheckURL validates a webhook URL, refusing private and loopback hosts
+// given as addresses unless they are allowed. Hosts given by name are
+// checked when deliveries connect.
+func (d *Dispatcher) CheckURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errInvalidURL
+	}
+	u.User = nil
+	if _, err := d.client.Check(u.String()); err != nil {
+		return errPrivateURL
+	}
+	return nil
+}
+
+// Handle is the event bus subscriber that fans an event out to the user's
+// webhooks
+func (d *Dispatcher) Handle(ctx context.Context, event events.Event) {
+	log := logger.Ctx(ctx)
+
+	webhooks, err := d.db.ListWebhooks(ctx, event.UserID)
+	if err != nil {
+		log.Error().Err(err).Str("event", event.Type).Msg("Failed to list webhooks")
+		return
+	}
+
+	for _, webhook := range webhooks {
+		if !Subscribed(webhook, event.Type) {
+			continue
+		}
+		delivery, err := d.enqueue(ctx, webhook, event)
+		if err != nil {
+			log.Error().Err(err).Str("webhook", webhook.ID).Msg("Failed to record webhook delivery")
+			continue
+		}
+		d.attempt(ctx, webhook, delivery)
+	}
+}
+
+// Test sends a ping event to a webhook right away and returns the delivery
+func (d *Dispatcher) Test(ctx context.Context, webhook *database.Webhook) (*database.WebhookDelivery, error) {
+	event := events.New(events.Ping, webhook.UserID, map[string]string{"webhook_id": webhook.ID})
+	delivery, err := d.enqueue(ctx, webhook, event)
+	if err != nil {
+		return nil, err
+	}
+	d.attempt(ctx, webhook, delivery)
+	return delivery, nil
+}
+
+// RetryDue attempts pending deliveries whose retry time has come
+func (d *Dispatcher) RetryDue(ctx context.Context) (string, error) {
+	due, err := d.db.ListDueWebhookDeliveries(ctx, time.Now().UTC(), retryBatch)
+	if err != nil {
+		return "", err
+	}
+
+	delivered := 0
+	for _, delivery := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		webhook, err := d.db.GetWebhookByID(ctx, delivery.WebhookID)
+		if err != nil || !webhook.Active {
+			d.giveUp(ctx, delivery, "webhook is disabled")
+			continue
+		}
+		d.attempt(ctx, webhook, delivery)
+		if delivery.Status == database.WebhookDeliverySucceeded {
+			delivered++
+		}
+	}
+	return fmt.Sprintf("delivered %d of %d due deliveries", delivered, len(due)), nil
+}
+
+// Purge removes settled deliveries older than the retention period
+func (d *Dispatcher) Purge(ctx context.Context) (string, error) {
+	before := time.Now().UTC().AddDate(0, 0, -max(d.cfg.Retention, 1))
+	n, err := d.db.PurgeWebhookDeliveries(ctx, before)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("removed %d deliveries", n), nil
+}
+
+// Subscribed reports whether a webhook wants events of the given type
+func Subscribed(webhook *database.Webhook, eventType string) bool {
+	if !webhook.Active {
+		return false
+	}
+	if len(webhook.Events) == 0 {
+		return true
+	}
+	for _, t := range webhook.Events {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign returns the signature header value for a delivery: an HMAC-SHA256
+// of "<timestamp>.<body>" keyed with the webhook secret
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// GenerateSecret creates a new signing secret
+func GenerateSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// enqueue records a pending delivery. Its next attempt is set past the
+// request timeout so the retry job leaves it alone while it is in flight.
+func (d *Dispatcher) enqueue(ctx context.Context, webhook *database.Webhook, event events.Event) (*database.WebhookDelivery, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	lease := now.Add(d.timeout + time.Minute)
+	delivery := &database.WebhookDelivery{
+		ID:            uuid.New().String(),
+		WebhookID:     webhook.ID,
+		EventID:       event.ID,
+		EventType:     event.Type,
+		Payload:       payload,
+		Status:        database.WebhookDeliveryPending,
+		NextAttemptAt: &lease,
+		CreatedAt:     now,
+	}
+	if err := d.db.CreateWebhookDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// attempt sends a delivery once and records the outcome, scheduling a
+// retry or giving up after the configured number of attempts
+func (d *Dispatcher) attempt(ctx context.Context, webhook *database.Webhook, delivery *database.WebhookDelivery) {
+	delivery.Attempts++
+	status, err := d.send(ctx, webhook, delivery)
+	delivery.ResponseStatus = status
+
+	now := time.Now().UTC()
+	switch {
+	case err == nil:
+		delivery.Status = database.WebhookDeliverySucceeded
+		delivery.LastError = ""
+		delivery.NextAttemptAt = nil
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= d.cfg.MaxAttempts:
+		delivery.Status = database.WebhookDeliveryFailed
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = nil
+	default:
+		next := now.Add(retryBackoff[min(delivery.Attempts, len(retryBackoff))-1])
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = &next
+	}
+
+	if err := d.db.UpdateWebhookDelivery(ctx, delivery); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("delivery", delivery.ID).Msg("Failed to record webhook delivery")
+	}
+}
+
+// giveUp marks a delivery as failed without attempting it
+func (d *Dispatcher) giveUp(ctx context.Context, delivery *database.WebhookDelivery, reason string) {
+	delivery.Status = database.WebhookDeliveryFailed
+	delivery.LastError = reason
+	delivery.NextAttemptAt = nil
+	if err := d.db.UpdateWebhookDelivery(ctx, delivery); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("delivery", delivery.ID).Msg("Failed to record webhook delivery")
+	}
+}
+
+// send posts a delivery to the webhook URL. Any 2xx response counts as
+// delivered.
+func (d *Dispatcher) send(ctx context.Context, webhook *database.Webhook, delivery *database.WebhookDelivery) (int, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	// The address policy refuses URLs with credentials in them, so they
+	// are sent as basic auth instead
+	if user := req.URL.User; user != nil {
+		password, _ := user.Password()
+		req.SetBasicAuth(user.Username(), password)
+		req.URL.User = nil
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "SpaceFood-Webhooks/1.0")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, delivery.ID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("receiver responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,101 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rghsoftware/space-food/internal/config"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/fetch"
+)
+
+func TestSendAddressPolicy(t *testing.T) {
+	var received http.Header
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	tests := []struct {
+		name         string
+		allowPrivate bool
+		url          string
+		status       int
+		err          error
+	}{
+		// httptest listens on loopback, which deliveries may only reach
+		// when private networks are allowed
+		{"loopback refused", false, receiver.URL, 0, fetch.ErrBlocked},
+		{"named loopback refused", false, strings.Replace(receiver.URL, "127.0.0.1", "localhost", 1), 0, fetch.ErrBlocked},
+		{"private allowed", true, receiver.URL, http.StatusNoContent, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDispatcher(nil, config.WebhooksConfig{Timeout: 5, AllowPrivateNetworks: tt.allowPrivate})
+			webhook := &database.Webhook{URL: tt.url, Secret: "secret"}
+			delivery := &database.WebhookDelivery{ID: "d1", EventType: "ping", Payload: []byte(`{}`)}
+
+			status, err := d.send(context.Background(), webhook, delivery)
+			if status != tt.status || !errors.Is(err, tt.err) {
+				t.Fatalf("send = %d, %v, want %d, %v", status, err, tt.status, tt.err)
+			}
+		})
+	}
+
+	// Credentials in the URL are sent as basic auth
+	d := NewDispatcher(nil, config.WebhooksConfig{Timeout: 5, AllowPrivateNetworks: true})
+	webhook := &database.Webhook{URL: strings.Replace(receiver.URL, "http://", "http://hook:pw@", 1), Secret: "secret"}
+	delivery := &database.WebhookDelivery{ID: "d2", EventType: "ping", Payload: []byte(`{}`)}
+	if _, err := d.send(context.Background(), webhook, delivery); err != nil {
+		t.Fatalf("send with credentials: %v", err)
+	}
+	if got := received.Get("Authorization"); got != "Basic aG9vazpwdw==" {
+		t.Errorf("Authorization = %q, want basic auth for hook:pw", got)
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		url          string
+		allowPrivate bool
+		want         error
+	}{
+		{"https://hooks.example.com/in", false, nil},
+		{"https://user:pw@hooks.example.com/in", false, nil},
+		{"ftp://hooks.example.com/in", false, errInvalidURL},
+		{"/relative", false, errInvalidURL},
+		{"http://127.0.0.1:8123/api/webhook/meals", false, errPrivateURL},
+		{"http://192.168.1.10:8123/api/webhook/meals", false, errPrivateURL},
+		{"http://169.254.169.254/latest/meta-data", false, errPrivateURL},
+		{"http://192.168.1.10:8123/api/webhook/meals", true, nil},
+	}
+	for _, tt := range tests {
+		d := NewDispatcher(nil, config.WebhooksConfig{Timeout: 5, AllowPrivateNetworks: tt.allowPrivate})
+		if err := d.CheckURL(tt.url); err != tt.want {
+			t.Errorf("CheckURL(%s) with private networks %v = %v, want %v", tt.url, tt.allowPrivate, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package weeklyreview
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/api/params"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/events"
+	"github.com/rghsoftware/space-food/internal/features/locale"
+	"github.com/rghsoftware/space-food/internal/features/mealtime"
+	"github.com/rghsoftware/space-food/internal/i18n"
+	"github.com/rghsoftware/space-food/internal/middleware"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// Reviews are sent on Sunday evening in each user's time zone
+const (
+	sendWeekday = time.Sunday
+	sendHour    = 18
+)
+
+// Handler handles weekly review HTTP requests
+type Handler struct {
+	db     database.Database
+	events *events.Bus
+}
+
+// NewHandler creates a new weekly review handler
+func NewHandler(db database.Database, bus *events.Bus) *Handler {
+	return &Handler{
+		db:     db,
+		events: bus,
+	}
+}
+
+// RegisterRoutes registers weekly review routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/weekly-review", h.GetReview)
+}
+
+// GetReview sums up the authenticated user's last seven days, in their
+// meal window time zone
+// @Summary Weekly review
+// @Tags weekly-review
+// @Produce json
+// @Param date query string false "Last day of the week to review (YYYY-MM-DD, default today)"
+// @Router /me/weekly-review [get]
+func (h *Handler) GetReview(c *gin.Context) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		apierror.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	loc := mealtime.Location(mealtime.LoadPreferences(c, h.db, user.ID))
+	query := params.Query(c)
+	end, given := query.Date("date", loc)
+	if !query.Valid() {
+		return
+	}
+	if !given {
+		end = time.Now()
+	}
+
+	review, err := Build(c.Request.Context(), h.db, user.ID, loc, end, locale.Printer(c, h.db, user.ID))
+	if err != nil {
+		apierror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, review)
+}
+
+// SendDue sends a weekly_review_ready event with the review of each active
+// user for whom it is now Sunday evening. Run by the scheduler every hour.
+func (h *Handler) SendDue(ctx context.Context) (string, error) {
+	users, err := h.db.ListUsers(ctx, database.UserFilter{})
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	sent, failed := 0, 0
+	for _, user := range users {
+		if ctx.Err() != nil {
+			break
+		}
+		if !user.Active {
+			continue
+		}
+		prefs, err := h.db.GetMealTimePreferences(ctx, user.ID)
+		if err != nil {
+			prefs = mealtime.DefaultPreferences(user.ID)
+		}
+		loc := mealtime.Location(prefs)
+		local := now.In(loc)
+		if local.Weekday() != sendWeekday || local.Hour() != sendHour {
+			continue
+		}
+
+		review, err := Build(ctx, h.db, user.ID, loc, local, i18n.For(locale.Preferred(ctx, h.db, user.ID)))
+		if err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("user_id", user.ID).Msg("Failed to build weekly review")
+			failed++
+			continue
+		}
+		h.events.Publish(ctx, events.NewWeeklyReviewReady(user.ID, review.Summary, review))
+		sent++
+	}
+	return fmt.Sprintf("sent %d weekly reviews, %d failed", sent, failed), nil
+}
@@ -0,0 +1,265 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package weeklyreview sums up a user's week: what they cooked, the foods
+// they tried for the first time, the sessions they set aside and what is
+// planned for the week ahead.
+package weeklyreview
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/i18n"
+)
+
+// historyDays is how far back a food must not have been eaten to count as
+// new
+const historyDays = 90
+
+// Review is a summary of the seven days up to and including End
+type Review struct {
+	Start    string        `json:"start"` // YYYY-MM-DD
+	End      string        `json:"end"`
+	Timezone string        `json:"timezone"`
+	Summary  string        `json:"summary"`
+	Locale   string        `json:"locale"` // the language of the summary
+	Cooked   []CookedMeal  `json:"cooked"`
+	NewFoods []NewFood     `json:"new_foods"`
+	SetAside []SetAside    `json:"set_aside"`
+	Upcoming []PlannedMeal `json:"upcoming"` // the seven days after End
+}
+
+// CookedMeal is a recipe cooked to the end during the week
+type CookedMeal struct {
+	RecipeID    *string   `json:"recipe_id,omitempty"`
+	Title       string    `json:"title"`
+	Times       int       `json:"times"`
+	LastCooked  time.Time `json:"last_cooked"`
+	WouldRepeat *int      `json:"would_make_again,omitempty"` // from the latest reflection, 1-5
+}
+
+// NewFood is something eaten or cooked for the first time in a while
+type NewFood struct {
+	Name     string  `json:"name"`
+	RecipeID *string `json:"recipe_id,omitempty"`
+}
+
+// SetAside is a cooking session stopped before the end. It is listed so
+// the cook can pick the recipe up again, not to keep score.
+type SetAside struct {
+	RecipeID    *string   `json:"recipe_id,omitempty"`
+	Title       string    `json:"title"`
+	ReachedStep int       `json:"reached_step"` // counted from 1
+	StepCount   int       `json:"step_count"`
+	SetAsideAt  time.Time `json:"set_aside_at"`
+}
+
+// PlannedMeal is a meal on one of the user's meal plans
+type PlannedMeal struct {
+	Date        string `json:"date"` // YYYY-MM-DD
+	MealType    string `json:"meal_type"`
+	RecipeID    string `json:"recipe_id"`
+	RecipeTitle string `json:"recipe_title,omitempty"`
+	Servings    int    `json:"servings"`
+}
+
+// Build puts together the review of the seven days ending on the day
+// containing end, in loc, summed up in the printer's language
+func Build(ctx context.Context, db database.Database, userID string, loc *time.Location, end time.Time, p *i18n.Printer) (*Review, error) {
+	end = end.In(loc)
+	until := time.Date(end.Year(), end.Month(), end.Day()+1, 0, 0, 0, 0, loc)
+	since := until.AddDate(0, 0, -7)
+
+	review := &Review{
+		Start:    since.Format("2006-01-02"),
+		End:      until.AddDate(0, 0, -1).Format("2006-01-02"),
+		Timezone: loc.String(),
+		Cooked:   []CookedMeal{},
+		NewFoods: []NewFood{},
+		SetAside: []SetAside{},
+		Upcoming: []PlannedMeal{},
+	}
+	inWeek := func(t time.Time) bool { return !t.Before(since) && t.Before(until) }
+
+	sessions, err := db.ListCookingSessions(ctx, database.CookingSessionFilter{UserID: userID, IncludeFinished: true})
+	if err != nil {
+		return nil, err
+	}
+	// Recipes cooked before the week, so cooking one for the first time
+	// counts as a new food
+	cookedBefore := map[string]bool{}
+	for _, session := range sessions {
+		if session.Status == database.CookingSessionCompleted && session.FinishedAt != nil && session.FinishedAt.Before(since) {
+			cookedBefore[cookedKey(session)] = true
+		}
+	}
+
+	cooked := map[string]*CookedMeal{}
+	newFoods := map[string]bool{}
+	for _, session := range sessions {
+		if session.FinishedAt == nil || !inWeek(*session.FinishedAt) {
+			continue
+		}
+		switch session.Status {
+		case database.CookingSessionCompleted:
+			key := cookedKey(session)
+			meal, ok := cooked[key]
+			if !ok {
+				meal = &CookedMeal{RecipeID: session.RecipeID, Title: session.RecipeTitle}
+				cooked[key] = meal
+			}
+			meal.Times++
+			if session.FinishedAt.After(meal.LastCooked) {
+				meal.LastCooked = *session.FinishedAt
+				if session.Reflection != nil {
+					meal.WouldRepeat = session.Reflection.WouldMakeAgain
+				}
+			}
+			if !cookedBefore[key] && !newFoods[foodKey(session.RecipeTitle)] {
+				newFoods[foodKey(session.RecipeTitle)] = true
+				review.NewFoods = append(review.NewFoods, NewFood{Name: session.RecipeTitle, RecipeID: session.RecipeID})
+			}
+		case database.CookingSessionAbandoned:
+			review.SetAside = append(review.SetAside, SetAside{
+				RecipeID:    session.RecipeID,
+				Title:       session.RecipeTitle,
+				ReachedStep: session.CurrentStep + 1,
+				StepCount:   len(session.Steps),
+				SetAsideAt:  *session.FinishedAt,
+			})
+		}
+	}
+	for _, meal := range cooked {
+		review.Cooked = append(review.Cooked, *meal)
+	}
+	sort.Slice(review.Cooked, func(i, j int) bool { return review.Cooked[i].LastCooked.After(review.Cooked[j].LastCooked) })
+	sort.Slice(review.SetAside, func(i, j int) bool { return review.SetAside[i].SetAsideAt.After(review.SetAside[j].SetAsideAt) })
+
+	// Foods logged this week that weren't in the log in the months before
+	logs, err := db.ListNutritionLogs(ctx, database.NutritionFilter{
+		UserID:    userID,
+		StartDate: since.AddDate(0, 0, -historyDays),
+		EndDate:   until,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(logs, func(i, j int) bool { return logs[i].Date.Before(logs[j].Date) })
+	eatenBefore := map[string]bool{}
+	for _, log := range logs {
+		key := foodKey(log.FoodName)
+		if key == "" {
+			continue
+		}
+		date := log.Date.Format("2006-01-02")
+		if date < review.Start {
+			eatenBefore[key] = true
+		} else if date <= review.End && !eatenBefore[key] && !newFoods[key] {
+			newFoods[key] = true
+			review.NewFoods = append(review.NewFoods, NewFood{Name: log.FoodName, RecipeID: log.RecipeID})
+		}
+	}
+
+	plans, err := db.ListMealPlans(ctx, database.MealPlanFilter{
+		UserID:    userID,
+		StartDate: until,
+		EndDate:   until.AddDate(0, 0, 7),
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Planned meals, like logs, are dated by day without a time zone
+	first, last := until.Format("2006-01-02"), until.AddDate(0, 0, 6).Format("2006-01-02")
+	titles := map[string]string{}
+	for _, plan := range plans {
+		for _, meal := range plan.Meals {
+			date := meal.Date.Format("2006-01-02")
+			if date < first || date > last {
+				continue
+			}
+			title, seen := titles[meal.RecipeID]
+			if !seen {
+				if recipe, err := db.GetRecipeByID(ctx, meal.RecipeID); err == nil {
+					title = recipe.Title
+				}
+				titles[meal.RecipeID] = title
+			}
+			review.Upcoming = append(review.Upcoming, PlannedMeal{
+				Date:        date,
+				MealType:    meal.MealType,
+				RecipeID:    meal.RecipeID,
+				RecipeTitle: title,
+				Servings:    meal.Servings,
+			})
+		}
+	}
+	sort.SliceStable(review.Upcoming, func(i, j int) bool { return review.Upcoming[i].Date < review.Upcoming[j].Date })
+
+	review.Summary = summarize(p, review)
+	review.Locale = p.Locale()
+	return review, nil
+}
+
+// summarize describes the week in a sentence or two. Set-aside sessions
+// are mentioned as something to come back to, never as a failure.
+func summarize(p *i18n.Printer, review *Review) string {
+	times := 0
+	for _, meal := range review.Cooked {
+		times += meal.Times
+	}
+
+	parts := []string{}
+	switch times {
+	case 0:
+		parts = append(parts, p.T("review.quiet_week"))
+	default:
+		parts = append(parts, p.Plural("review.cooked", times))
+	}
+	switch n := len(review.NewFoods); n {
+	case 0:
+	case 1:
+		parts = append(parts, p.T("review.tried_one", review.NewFoods[0].Name))
+	default:
+		parts = append(parts, p.T("review.tried_many", n))
+	}
+	if len(review.SetAside) > 0 {
+		parts = append(parts, p.T("review.set_aside"))
+	}
+	if n := len(review.Upcoming); n > 0 {
+		parts = append(parts, p.Plural("review.planned", n))
+	}
+	return strings.Join(parts, " ")
+}
+
+// cookedKey groups sessions of the same recipe, or of the same title for
+// sessions not started from a recipe
+func cookedKey(session *database.CookingSession) string {
+	if session.RecipeID != nil {
+		return *session.RecipeID
+	}
+	return foodKey(session.RecipeTitle)
+}
+
+// foodKey is how food names are compared
+func foodKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
@@ -0,0 +1,312 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package fetch retrieves documents from addresses users supply, such as
+// recipe pages to import, without letting them reach the server's own
+// network.
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/config"
+)
+
+// maxRedirects is how many redirects a fetch follows
+const maxRedirects = 5
+
+// userAgent identifies the server to the sites it fetches from
+const userAgent = "SpaceFood/1.0 (+https://github.com/rghsoftware/space-food)"
+
+var (
+	// ErrInvalidURL is returned for anything but an absolute http(s) URL
+	ErrInvalidURL = errors.New("url must be an http or https address")
+	// ErrBlocked is returned for domains the fetch policy excludes and for
+	// private, loopback and other internal addresses
+	ErrBlocked = errors.New("address is not allowed")
+	// ErrTooLarge is returned when a response exceeds the size limit
+	ErrTooLarge = errors.New("response is too large")
+	// ErrContentType is returned when a response is not of an accepted type
+	ErrContentType = errors.New("unexpected content type")
+)
+
+// internalPrefixes are ranges outside the ones netip already classifies
+// that can lead back into private networks
+var internalPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "this network"
+	netip.MustParsePrefix("100.64.0.0/10"),  // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // reserved
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64
+	netip.MustParsePrefix("64:ff9b:1::/48"), // local-use NAT64
+	netip.MustParsePrefix("2001::/32"),      // Teredo
+	netip.MustParsePrefix("2002::/16"),      // 6to4
+}
+
+// Client fetches URLs under the instance's fetch policy (the fetch section
+// of the config)
+type Client struct {
+	allow        []string
+	deny         []string
+	allowPrivate bool
+	maxSize      int64
+	httpClient   *http.Client
+}
+
+// StatusError reports a response other than 200 OK
+type StatusError struct {
+	Host string
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s responded with status %d", e.Host, e.Code)
+}
+
+// Response is a fetched document
+type Response struct {
+	URL         *url.URL // after redirects
+	ContentType string   // media type without parameters, lower case
+	Body        []byte
+}
+
+// New creates a client that reads at most maxSize bytes of a response
+func New(cfg config.FetchConfig, maxSize int64) *Client {
+	c := &Client{
+		allow:        domains(cfg.AllowDomains),
+		deny:         domains(cfg.DenyDomains),
+		allowPrivate: cfg.AllowPrivateNetworks,
+		maxSize:      maxSize,
+	}
+	// The address is checked as the connection is made, after DNS, so a
+	// name can't resolve to a public address when checked and a private
+	// one when used
+	dialer := &net.Dialer{Timeout: 10 * time.Second, Control: c.checkAddress}
+	c.httpClient = &http.Client{
+		Timeout: time.Duration(max(cfg.Timeout, 1)) * time.Second,
+		Transport: &http.Transport{
+			// No proxy: it would connect on our behalf, past the check
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			ForceAttemptHTTP2:   true,
+			TLSHandshakeTimeout: 10 * time.Second,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return c.checkURL(req.URL)
+		},
+	}
+	return c
+}
+
+// Check parses a URL and applies the domain policy to it, so a request can
+// be rejected before any work is done
+func (c *Client) Check(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, ErrInvalidURL
+	}
+	if err := c.checkURL(u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// Get fetches a URL, accepting only a 200 response whose content type is
+// one of accept, such as "text/html" or "image/*"
+func (c *Client) Get(ctx context.Context, rawURL string, accept ...string) (*Response, error) {
+	return c.GetWith(ctx, rawURL, nil, accept...)
+}
+
+// GetWith is Get with extra request headers
+func (c *Client) GetWith(ctx context.Context, rawURL string, header http.Header, accept ...string) (*Response, error) {
+	u, err := c.Check(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, ErrInvalidURL
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Accept", strings.Join(accept, ", "))
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, requestError("fetch", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{Host: u.Host, Code: resp.StatusCode}
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	mediaType = strings.ToLower(mediaType)
+	if !accepted(mediaType, accept) {
+		return nil, fmt.Errorf("%w: %s", ErrContentType, mediaType)
+	}
+	if resp.ContentLength > c.maxSize {
+		return nil, ErrTooLarge
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, c.maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", u.Host, err)
+	}
+	if int64(len(body)) > c.maxSize {
+		return nil, ErrTooLarge
+	}
+	return &Response{URL: resp.Request.URL, ContentType: mediaType, Body: body}, nil
+}
+
+// Do sends a request under the client's address policy, for callers that
+// need more than a GET, such as webhook deliveries. The caller closes the
+// response body.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	if err := c.checkURL(req.URL); err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, requestError("reach", req.URL, err)
+	}
+	return resp, nil
+}
+
+// requestError describes a failed request without the resolved addresses
+// or the URL, which users should not see
+func requestError(action string, u *url.URL, err error) error {
+	if errors.Is(err, ErrBlocked) || errors.Is(err, ErrInvalidURL) {
+		return ErrBlocked
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return fmt.Errorf("failed to %s %s: host not found", action, u.Host)
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	return fmt.Errorf("failed to %s %s: %w", action, u.Host, err)
+}
+
+// checkURL applies the scheme and domain rules, and the address rules to
+// hosts given as IP literals
+func (c *Client) checkURL(u *url.URL) error {
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User != nil {
+		return ErrInvalidURL
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if matchDomain(host, c.deny) {
+		return ErrBlocked
+	}
+	if len(c.allow) > 0 && !matchDomain(host, c.allow) {
+		return ErrBlocked
+	}
+	if ip, err := netip.ParseAddr(host); err == nil && !c.allowPrivate && !IsPublic(ip) {
+		return ErrBlocked
+	}
+	return nil
+}
+
+// checkAddress is the dialer's hook, run with the resolved address of
+// every connection
+func (c *Client) checkAddress(network, address string, _ syscall.RawConn) error {
+	if c.allowPrivate {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return ErrBlocked
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil || !IsPublic(ip) {
+		return ErrBlocked
+	}
+	return nil
+}
+
+// IsPublic reports whether ip is a globally routable unicast address, not a
+// loopback, private, link-local (cloud metadata) or otherwise internal one
+func IsPublic(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return false
+	}
+	for _, prefix := range internalPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchDomain reports whether host is one of domains or a subdomain of one
+func matchDomain(host string, domains []string) bool {
+	for _, domain := range domains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// domains normalizes configured domain names
+func domains(names []string) []string {
+	var out []string
+	for _, name := range names {
+		name = strings.Trim(strings.ToLower(strings.TrimSpace(name)), ".")
+		if name != "" {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// accepted matches a media type against accept, where "image/*" stands
+// for any image type
+func accepted(mediaType string, accept []string) bool {
+	if len(accept) == 0 {
+		return true
+	}
+	for _, a := range accept {
+		if a == mediaType || (strings.HasSuffix(a, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(a, "*"))) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package fetch
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rghsoftware/space-food/internal/config"
+)
+
+func TestDoRedirects(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer target.Close()
+	redirectTo := func(location string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, location, http.StatusTemporaryRedirect)
+		}))
+	}
+	// The same server by a name the policy denies
+	denied := redirectTo(strings.Replace(target.URL, "127.0.0.1", "localhost", 1))
+	defer denied.Close()
+	allowed := redirectTo(target.URL)
+	defer allowed.Close()
+	metadata := redirectTo("http://169.254.169.254/latest/meta-data")
+	defer metadata.Close()
+
+	tests := []struct {
+		name   string
+		cfg    config.FetchConfig
+		url    string
+		status int
+		err    error
+	}{
+		{"allowed redirect", config.FetchConfig{AllowPrivateNetworks: true, DenyDomains: []string{"localhost"}}, allowed.URL, http.StatusNoContent, nil},
+		{"redirect to denied domain", config.FetchConfig{AllowPrivateNetworks: true, DenyDomains: []string{"localhost"}}, denied.URL, 0, ErrBlocked},
+		{"redirect to unlisted domain", config.FetchConfig{AllowPrivateNetworks: true, AllowDomains: []string{"127.0.0.1"}}, denied.URL, 0, ErrBlocked},
+		{"private network refused", config.FetchConfig{}, metadata.URL, 0, ErrBlocked},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Timeout = 5
+			req, err := http.NewRequest(http.MethodPost, tt.url, strings.NewReader("{}"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := New(tt.cfg, 0).Do(req)
+			if resp != nil {
+				resp.Body.Close()
+			}
+			status := 0
+			if err == nil {
+				status = resp.StatusCode
+			}
+			if status != tt.status || !errors.Is(err, tt.err) {
+				t.Fatalf("Do = %d, %v, want %d, %v", status, err, tt.status, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package health serves the liveness and readiness probes used by Docker
+// and Kubernetes.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/config"
+	"github.com/rghsoftware/space-food/internal/database"
+)
+
+// aiCheckInterval is how long an AI provider check result is reused, so
+// frequent probes do not turn into a stream of provider API calls
+const aiCheckInterval = time.Minute
+
+// Check statuses
+const (
+	StatusOK      = "ok"
+	StatusFailing = "failing"
+)
+
+// CheckResult is the outcome of one readiness check
+type CheckResult struct {
+	Status    string `json:"status"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Checker answers liveness and readiness probes
+type Checker struct {
+	db         database.Database
+	cfg        config.HealthConfig
+	httpClient *http.Client
+
+	shuttingDown atomic.Bool
+
+	aiMu      sync.Mutex
+	ai        config.AIConfig
+	aiChecked time.Time
+	aiErr     error
+}
+
+// NewChecker creates a checker for the server's dependencies
+func NewChecker(cfg *config.Config, db database.Database) *Checker {
+	return &Checker{
+		db:         db,
+		cfg:        cfg.Health,
+		ai:         cfg.AI,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// SetAI checks the reloaded AI settings from the next probe on
+func (h *Checker) SetAI(cfg config.AIConfig) {
+	h.aiMu.Lock()
+	defer h.aiMu.Unlock()
+	h.ai = cfg
+	h.aiChecked = time.Time{}
+}
+
+// MarkShuttingDown makes readiness fail while the server drains, so load
+// balancers stop routing new requests to it
+func (h *Checker) MarkShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+// Live reports that the process is up and serving requests. It checks no
+// dependencies, so a database outage does not get the container restarted.
+func (h *Checker) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": StatusOK})
+}
+
+// Ready reports whether the server can handle traffic: the database answers,
+// every migration of this build is applied and, when health.checkai is set,
+// the active AI provider is reachable
+func (h *Checker) Ready(c *gin.Context) {
+	if h.shuttingDown.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "shutting_down"})
+		return
+	}
+
+	checks := map[string]func(context.Context) error{
+		"database":   h.db.Health,
+		"migrations": h.checkMigrations,
+	}
+	if h.cfg.CheckAI {
+		checks["ai"] = h.checkAI
+	}
+
+	results := h.run(c.Request.Context(), checks)
+
+	status, code := "ready", http.StatusOK
+	for _, result := range results {
+		if result.Status != StatusOK {
+			status, code = "not_ready", http.StatusServiceUnavailable
+		}
+	}
+	c.JSON(code, gin.H{"status": status, "checks": results})
+}
+
+// run performs the checks concurrently, each bounded by health.timeout
+func (h *Checker) run(ctx context.Context, checks map[string]func(context.Context) error) map[string]CheckResult {
+	timeout := time.Duration(max(h.cfg.Timeout, 1)) * time.Second
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := make(map[string]CheckResult, len(checks))
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := check(ctx)
+			result := CheckResult{Status: StatusOK, LatencyMS: time.Since(start).Milliseconds()}
+			if err != nil {
+				result.Status = StatusFailing
+				result.Error = err.Error()
+			}
+
+			mu.Lock()
+			results[name] = result
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func (h *Checker) checkMigrations(ctx context.Context) error {
+	return h.db.Migrations().Check(ctx)
+}
+
+// checkAI asks the active provider for its model list, reusing the last
+// answer for aiCheckInterval
+func (h *Checker) checkAI(ctx context.Context) error {
+	h.aiMu.Lock()
+	defer h.aiMu.Unlock()
+	if !h.aiChecked.IsZero() && time.Since(h.aiChecked) < aiCheckInterval {
+		return h.aiErr
+	}
+
+	h.aiErr = h.pingAI(ctx)
+	h.aiChecked = time.Now()
+	return h.aiErr
+}
+
+func (h *Checker) pingAI(ctx context.Context) error {
+	provider := h.ai.ActiveProvider()
+
+	var url string
+	header := http.Header{}
+	switch provider {
+	case "ollama":
+		url = h.ai.Ollama.Host + "/api/tags"
+	case "openai":
+		url = "https://api.openai.com/v1/models"
+		header.Set("Authorization", "Bearer "+h.ai.OpenAI.APIKey)
+	case "gemini":
+		url = "https://generativelanguage.googleapis.com/v1beta/models"
+		header.Set("x-goog-api-key", h.ai.Gemini.APIKey)
+	case "claude":
+		url = "https://api.anthropic.com/v1/models"
+		header.Set("x-api-key", h.ai.Claude.APIKey)
+		header.Set("anthropic-version", "2023-06-01")
+	default:
+		return errors.New("no AI provider is configured")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", provider, err)
+	}
+	req.Header = header
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s is unreachable: %w", provider, err)
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("%s rejected the API key", provider)
+	case resp.StatusCode >= 300:
+		return fmt.Errorf("%s responded with HTTP %d", provider, resp.StatusCode)
+	}
+	return nil
+}
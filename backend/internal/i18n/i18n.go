@@ -0,0 +1,136 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package i18n writes the text the server generates for people, such as
+// suggestion reasons, weekly review summaries and spoken cooking prompts,
+// in their language. Messages live in a JSON catalog per language under
+// locales, keyed by an ID; English is complete and every other catalog
+// falls back to it.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// DefaultLocale is the language of the catalog the others fall back to
+const DefaultLocale = "en"
+
+//go:embed locales/*.json
+var catalogFS embed.FS
+
+// catalogs are the messages of each language, by tag
+var catalogs = loadCatalogs()
+
+func loadCatalogs() map[string]map[string]string {
+	files, err := catalogFS.ReadDir("locales")
+	if err != nil {
+		panic(fmt.Sprintf("i18n: failed to list catalogs: %v", err))
+	}
+	loaded := make(map[string]map[string]string, len(files))
+	for _, file := range files {
+		data, err := catalogFS.ReadFile("locales/" + file.Name())
+		if err != nil {
+			panic(fmt.Sprintf("i18n: failed to read %s: %v", file.Name(), err))
+		}
+		messages := map[string]string{}
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalog %s: %v", file.Name(), err))
+		}
+		loaded[strings.TrimSuffix(file.Name(), path.Ext(file.Name()))] = messages
+	}
+	return loaded
+}
+
+// Locales lists the languages with a catalog
+func Locales() []string {
+	tags := make([]string, 0, len(catalogs))
+	for tag := range catalogs {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// Printer writes messages in one language
+type Printer struct {
+	locale   string
+	messages []map[string]string // most specific first, ending with English
+}
+
+// For returns the printer for a normalized language tag: its own catalog,
+// or else its language's (pt for pt-BR), falling back to English for
+// messages the catalog lacks. An empty or unknown tag gets English.
+func For(tag string) *Printer {
+	p := &Printer{locale: DefaultLocale}
+	language, _, _ := strings.Cut(tag, "-")
+	for _, candidate := range []string{tag, language} {
+		if messages, ok := catalogs[candidate]; ok && candidate != DefaultLocale {
+			p.locale = candidate
+			p.messages = append(p.messages, messages)
+			break
+		}
+	}
+	p.messages = append(p.messages, catalogs[DefaultLocale])
+	return p
+}
+
+// Locale is the language of the catalog the printer uses, e.g. es
+func (p *Printer) Locale() string {
+	return p.locale
+}
+
+// T formats the message with the ID, as fmt.Sprintf does. Messages
+// without verbs are written as they are, so a translation may leave an
+// argument out, such as the count of "once". A message no catalog has is
+// written as its ID, so a missing translation shows rather than failing.
+func (p *Printer) T(id string, args ...any) string {
+	for _, messages := range p.messages {
+		if message, ok := messages[id]; ok {
+			if len(args) == 0 || !strings.Contains(message, "%") {
+				return message
+			}
+			return fmt.Sprintf(message, args...)
+		}
+	}
+	return id
+}
+
+// Plural formats the form of the message for a count: ID.one or ID.other,
+// chosen by the language's rule. The count is the first argument, followed
+// by any others.
+func (p *Printer) Plural(id string, n int, args ...any) string {
+	form := ".other"
+	if p.one(n) {
+		form = ".one"
+	}
+	return p.T(id+form, append([]any{n}, args...)...)
+}
+
+// one reports whether a count takes the singular: just 1 in most of the
+// catalog languages, but 0 and 1 in French
+func (p *Printer) one(n int) bool {
+	if strings.HasPrefix(p.locale, "fr") {
+		return n == 0 || n == 1
+	}
+	return n == 1
+}
@@ -0,0 +1,70 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package mail sends email. Messages are rendered from the templates in
+// templates/ and handed to a Sender: SMTP in production, or the log while
+// developing, so nothing leaves the machine.
+package mail
+
+import (
+	"context"
+
+	"github.com/rghsoftware/space-food/internal/config"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// Message is an email ready to send, with plain text and HTML versions of
+// the same content
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Sender delivers messages
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// New creates the sender the configuration asks for. It returns nil when
+// email is off, so callers can tell email is unavailable.
+func New(cfg config.MailConfig) Sender {
+	switch {
+	case !cfg.Enabled:
+		return nil
+	case cfg.Mode == "log":
+		return LogSender{}
+	default:
+		return NewSMTPSender(cfg)
+	}
+}
+
+// LogSender writes messages to the log instead of sending them
+type LogSender struct{}
+
+// Send logs the message, with its plain text body so links in it can be
+// followed
+func (LogSender) Send(ctx context.Context, msg *Message) error {
+	logger.Ctx(ctx).Info().
+		Str("to", msg.To).
+		Str("subject", msg.Subject).
+		Str("body", msg.Text).
+		Msg("Email not sent (mail.mode is log)")
+	return nil
+}
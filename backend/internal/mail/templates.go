@@ -0,0 +1,69 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Templates. Each has a .txt file defining "subject" and "text", and a
+// .html file defining "content" and "footer" for the shared layout.
+const (
+	HouseholdInvite = "household_invite"
+	PasswordReset   = "password_reset"
+	WeeklyDigest    = "weekly_digest"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// Render fills in a template for one recipient
+func Render(name, to string, data any) (*Message, error) {
+	text, err := texttemplate.ParseFS(templateFS, "templates/"+name+".txt")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load email template %s: %w", name, err)
+	}
+	html, err := htmltemplate.ParseFS(templateFS, "templates/layout.html", "templates/"+name+".html")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load email template %s: %w", name, err)
+	}
+
+	msg := &Message{To: to}
+	var buf bytes.Buffer
+	if err := text.ExecuteTemplate(&buf, "subject", data); err != nil {
+		return nil, fmt.Errorf("failed to render email template %s: %w", name, err)
+	}
+	msg.Subject = strings.TrimSpace(buf.String())
+	buf.Reset()
+	if err := text.ExecuteTemplate(&buf, "text", data); err != nil {
+		return nil, fmt.Errorf("failed to render email template %s: %w", name, err)
+	}
+	msg.Text = buf.String()
+	buf.Reset()
+	if err := html.ExecuteTemplate(&buf, "layout", data); err != nil {
+		return nil, fmt.Errorf("failed to render email template %s: %w", name, err)
+	}
+	msg.HTML = buf.String()
+	return msg, nil
+}
@@ -0,0 +1,74 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// AccessLog logs each request once it is answered, with its status and
+// latency, and the user AuthMiddleware put on the request's logger. It also tags the request's logger with the feature the route
+// belongs to, so everything logged on the request's behalf, down to the
+// database, says which feature it came from. It must run after RequestID.
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		route := c.FullPath()
+		if feature := featureOf(route); feature != "" {
+			c.Request = c.Request.WithContext(logger.With(c.Request.Context(), "feature", feature))
+		}
+
+		c.Next()
+
+		status := c.Writer.Status()
+		log := logger.Ctx(c.Request.Context())
+		event := log.Info()
+		switch {
+		case status >= http.StatusInternalServerError:
+			event = log.Error()
+		case status >= http.StatusBadRequest:
+			event = log.Warn()
+		}
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+		event.
+			Str("method", c.Request.Method).
+			Str("route", route).
+			Int("status", status).
+			Dur("latency", time.Since(start)).
+			Str("client_ip", c.ClientIP()).
+			Msg("Request")
+	}
+}
+
+// featureOf names the feature a route belongs to: its first segment under
+// the API, or the second under /me
+func featureOf(route string) string {
+	parts := strings.Split(strings.TrimPrefix(strings.TrimPrefix(route, "/api/v1"), "/"), "/")
+	if parts[0] == "me" && len(parts) > 1 {
+		return parts[1]
+	}
+	return parts[0]
+}
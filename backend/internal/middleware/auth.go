@@ -19,11 +19,12 @@
 package middleware
 
 import (
-	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/apierror"
 	"github.com/rghsoftware/space-food/internal/auth"
+	"github.com/rghsoftware/space-food/pkg/logger"
 )
 
 // AuthMiddleware creates a middleware for JWT authentication
@@ -32,16 +33,14 @@ func AuthMiddleware(authProvider auth.AuthProvider) gin.HandlerFunc {
 		// Get token from Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "missing authorization header"})
-			c.Abort()
+			apierror.Unauthorized(c, "missing authorization header")
 			return
 		}
 
 		// Extract token
 		parts := strings.SplitN(authHeader, " ", 2)
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization header format"})
-			c.Abort()
+			apierror.Unauthorized(c, "invalid authorization header format")
 			return
 		}
 
@@ -50,13 +49,13 @@ func AuthMiddleware(authProvider auth.AuthProvider) gin.HandlerFunc {
 		// Validate token
 		user, err := authProvider.ValidateToken(c.Request.Context(), token)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
-			c.Abort()
+			apierror.Unauthorized(c, "invalid token")
 			return
 		}
 
-		// Set user in context
+		// Set user in context, and on the request's logger
 		c.Set("user", user)
+		c.Request = c.Request.WithContext(logger.With(c.Request.Context(), "user_id", user.ID))
 		c.Next()
 	}
 }
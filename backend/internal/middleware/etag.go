@@ -0,0 +1,133 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bufferedWriter holds a response body back so it can be hashed, unless
+// the handler asked to stream it
+type bufferedWriter struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	streaming bool
+}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	if w.streaming {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.body.Write(b)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	if w.streaming {
+		return w.ResponseWriter.WriteString(s)
+	}
+	return w.body.WriteString(s)
+}
+
+// ConditionalGET adds an ETag to successful GET responses and answers
+// 304 Not Modified when it matches If-None-Match. Handlers that know when
+// their data last changed can call SetLastModified so If-Modified-Since is
+// honoured too. Clients polling unchanged data then skip the body.
+func ConditionalGET() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		buffered := &bufferedWriter{ResponseWriter: original}
+		c.Writer = buffered
+		c.Next()
+		c.Writer = original
+
+		if buffered.streaming {
+			return
+		}
+		if original.Status() != http.StatusOK {
+			original.Write(buffered.body.Bytes())
+			return
+		}
+
+		header := original.Header()
+		etag := header.Get("ETag")
+		if etag == "" {
+			sum := sha256.Sum256(buffered.body.Bytes())
+			etag = `"` + hex.EncodeToString(sum[:16]) + `"`
+			header.Set("ETag", etag)
+		}
+
+		if notModified(c.Request, etag, header.Get("Last-Modified")) {
+			header.Del("Content-Type")
+			header.Del("Content-Length")
+			original.WriteHeader(http.StatusNotModified)
+			original.WriteHeaderNow()
+			return
+		}
+		original.Write(buffered.body.Bytes())
+	}
+}
+
+// SkipConditionalGET sends the response straight to the client without an
+// ETag, for downloads too large to hold in memory. Call it before writing
+// the body.
+func SkipConditionalGET(c *gin.Context) {
+	if w, ok := c.Writer.(*bufferedWriter); ok {
+		w.streaming = true
+	}
+}
+
+// SetLastModified records when the data in a response last changed
+func SetLastModified(c *gin.Context, t time.Time) {
+	if !t.IsZero() {
+		c.Header("Last-Modified", t.UTC().Format(http.TimeFormat))
+	}
+}
+
+// notModified applies the If-None-Match and If-Modified-Since
+// preconditions; If-None-Match wins when both are sent
+func notModified(r *http.Request, etag, lastModified string) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	ims, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || lastModified == "" {
+		return false
+	}
+	modified, err := http.ParseTime(lastModified)
+	return err == nil && !modified.After(ims)
+}
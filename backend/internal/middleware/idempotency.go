@@ -0,0 +1,177 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// Idempotency headers: clients name a request with IdempotencyKeyHeader,
+// and replayed responses carry IdempotentReplayedHeader
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// maxIdempotencyKeyLength bounds the keys clients may send
+const maxIdempotencyKeyLength = 255
+
+var (
+	errIdempotencyInProgress = apierror.New(http.StatusConflict, apierror.CodeConflict, "a request with this Idempotency-Key is still being processed")
+	errIdempotencyKeyReused  = apierror.New(http.StatusUnprocessableEntity, apierror.CodeIdempotencyReused, "this Idempotency-Key was already used for a different request")
+)
+
+// recordingWriter passes a response through while keeping a copy of it
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency makes POSTs to the listed routes safe to retry. A request
+// sent with an Idempotency-Key runs once; sending the same request with
+// the same key within window gets the first response back instead of
+// creating something twice. Keys belong to the user, and only successful
+// responses are kept, so a failed request can be retried with its key.
+// Routes are matched exactly, by their pattern. It must run after
+// AuthMiddleware.
+func Idempotency(db database.Database, window time.Duration, routes ...string) gin.HandlerFunc {
+	listed := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		listed[route] = true
+	}
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		user, ok := GetUserFromContext(c)
+		if key == "" || !ok || c.Request.Method != http.MethodPost || !listed[c.FullPath()] {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			apierror.BadRequest(c, "Idempotency-Key must be at most 255 characters")
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			apierror.BadRequest(c, "failed to read the request body")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		sum := sha256.Sum256(append([]byte(c.Request.Method+" "+c.Request.URL.Path+"\n"), body...))
+		claim := &database.IdempotencyKey{
+			UserID:      user.ID,
+			Key:         key,
+			RequestHash: hex.EncodeToString(sum[:]),
+			CreatedAt:   time.Now(),
+		}
+
+		ctx := c.Request.Context()
+		existing, err := db.GetIdempotencyKey(ctx, user.ID, key)
+		switch {
+		case err == nil && existing.CreatedAt.After(claim.CreatedAt.Add(-window)):
+			replay(c, existing, claim.RequestHash)
+			return
+		case err == nil:
+			// Expired, but not purged yet
+			err = db.DeleteIdempotencyKey(ctx, user.ID, key)
+		case apierror.IsNotFound(err):
+			err = nil
+		}
+		if err == nil {
+			err = db.CreateIdempotencyKey(ctx, claim)
+		}
+		if apierror.IsConflict(err) {
+			// A request with this key got in between
+			apierror.Abort(c, errIdempotencyInProgress)
+			return
+		}
+		if err != nil {
+			apierror.Respond(c, err)
+			return
+		}
+
+		recorder := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		// The response is written by now, so store it even if the
+		// client has gone
+		ctx = context.WithoutCancel(ctx)
+		defer func() {
+			if r := recover(); r != nil {
+				release(ctx, db, claim)
+				panic(r)
+			}
+		}()
+		c.Next()
+
+		if recorder.Status() >= http.StatusBadRequest {
+			release(ctx, db, claim)
+			return
+		}
+		claim.StatusCode = recorder.Status()
+		claim.ContentType = recorder.Header().Get("Content-Type")
+		claim.Body = recorder.body.Bytes()
+		if err := db.CompleteIdempotencyKey(ctx, claim); err != nil {
+			logger.Ctx(ctx).Error().Err(err).Str("user_id", claim.UserID).Msg("Failed to store idempotent response")
+		}
+	}
+}
+
+// replay answers a retried request with the response to the first one
+func replay(c *gin.Context, existing *database.IdempotencyKey, requestHash string) {
+	if existing.RequestHash != requestHash {
+		apierror.Abort(c, errIdempotencyKeyReused)
+		return
+	}
+	if existing.StatusCode == 0 {
+		apierror.Abort(c, errIdempotencyInProgress)
+		return
+	}
+	c.Header(IdempotentReplayedHeader, "true")
+	c.Data(existing.StatusCode, existing.ContentType, existing.Body)
+	c.Abort()
+}
+
+// release gives up a key after its request failed, so it can be retried
+func release(ctx context.Context, db database.Database, claim *database.IdempotencyKey) {
+	if err := db.DeleteIdempotencyKey(ctx, claim.UserID, claim.Key); err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("user_id", claim.UserID).Msg("Failed to release idempotency key")
+	}
+}
@@ -0,0 +1,50 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/apierror"
+)
+
+// RequirePasswordChange blocks users whose password an administrator reset
+// from everything except the routes they need to change it, listed by path
+// prefix. It must run after AuthMiddleware.
+func RequirePasswordChange(exemptPrefixes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if hasAnyPrefix(c.FullPath(), exemptPrefixes) {
+			c.Next()
+			return
+		}
+
+		user, ok := GetUserFromContext(c)
+		if !ok {
+			apierror.Unauthorized(c, "unauthorized")
+			return
+		}
+		if user.MustChangePassword {
+			apierror.Abort(c, apierror.New(http.StatusForbidden, apierror.CodePasswordChange, "password change required"))
+			return
+		}
+
+		c.Next()
+	}
+}
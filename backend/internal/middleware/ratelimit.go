@@ -0,0 +1,139 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/apierror"
+)
+
+// idleBucketTTL is how long an unused bucket is kept before being swept
+const idleBucketTTL = 10 * time.Minute
+
+// RateLimiter is an in-memory token bucket limiter keyed by caller. Each
+// key may burst up to the limit for its period and refills continuously.
+type RateLimiter struct {
+	mu        sync.Mutex
+	perSecond float64
+	burst     float64
+	idleTTL   time.Duration
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a limiter allowing perMinute requests per key
+func NewRateLimiter(perMinute int) *RateLimiter {
+	return NewRateLimiterPer(perMinute, time.Minute)
+}
+
+// NewRateLimiterPer creates a limiter allowing limit requests per key in
+// each period
+func NewRateLimiterPer(limit int, period time.Duration) *RateLimiter {
+	return &RateLimiter{
+		perSecond: float64(limit) / period.Seconds(),
+		burst:     float64(limit),
+		// A bucket is only dropped once it would have refilled anyway
+		idleTTL:   max(idleBucketTTL, period),
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow takes a token for key, returning how long to wait when none is left
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastSweep) > idleBucketTTL {
+		for k, b := range l.buckets {
+			if now.Sub(b.last) > l.idleTTL {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.perSecond)
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / l.perSecond * float64(time.Second))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+// RateLimit rejects requests once the caller identified by keyFunc runs out
+// of tokens, answering 429 with a Retry-After header
+func RateLimit(limiter *RateLimiter, keyFunc func(*gin.Context) string, message string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ok, wait := limiter.Allow(keyFunc(c))
+		if !ok {
+			TooManyRequests(c, wait, message)
+			return
+		}
+		c.Next()
+	}
+}
+
+// TooManyRequests answers 429 with a Retry-After header, for handlers that
+// rate limit by something only they know, such as a field of the body
+func TooManyRequests(c *gin.Context, wait time.Duration, message string) {
+	retryAfter := int(math.Ceil(wait.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	apierror.Abort(c, apierror.New(http.StatusTooManyRequests, apierror.CodeRateLimited, message).
+		WithDetails(gin.H{"retry_after": retryAfter}))
+}
+
+// UserKey identifies the authenticated user, falling back to the client IP
+func UserKey(c *gin.Context) string {
+	if user, ok := GetUserFromContext(c); ok {
+		return "user:" + user.ID
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// ClientIPKey identifies the caller by IP address
+func ClientIPKey(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// InstanceKey puts every caller in the same bucket
+func InstanceKey(*gin.Context) string {
+	return "instance"
+}
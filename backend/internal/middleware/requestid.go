@@ -0,0 +1,67 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// maxRequestIDLength bounds request IDs accepted from clients and proxies
+const maxRequestIDLength = 64
+
+// RequestID tags each request with an ID, reusing a well-formed
+// X-Request-ID from the client or a proxy, and echoes it in the response
+// so error reports can be matched to server logs. The request's context
+// carries a logger that adds the ID to every line; see logger.Ctx.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(apierror.RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.New().String()
+		}
+		c.Set("request_id", id)
+		c.Header(apierror.RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logger.With(c.Request.Context(), "request_id", id))
+		c.Next()
+	}
+}
+
+// GetRequestID retrieves the request ID from context
+func GetRequestID(c *gin.Context) string {
+	return c.GetString("request_id")
+}
+
+// validRequestID accepts short IDs made of letters, digits, '-', '_' and '.'
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, ch := range id {
+		switch {
+		case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch >= '0' && ch <= '9':
+		case ch == '-', ch == '_', ch == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
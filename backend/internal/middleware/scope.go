@@ -0,0 +1,83 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/apierror"
+	"github.com/rghsoftware/space-food/internal/auth"
+)
+
+// EnforceTokenScope limits what requests authenticated with an API token may
+// do. Session tokens pass through untouched. mealLogPrefixes are the routes
+// the meal-log-write scope may change; credentialPrefixes are closed to all
+// API tokens so a leaked token cannot mint or hide further access.
+// It must run after AuthMiddleware.
+func EnforceTokenScope(mealLogPrefixes, credentialPrefixes []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := GetUserFromContext(c)
+		if !ok || user.TokenScope == "" {
+			c.Next()
+			return
+		}
+
+		path := c.FullPath()
+		if hasAnyPrefix(path, credentialPrefixes) {
+			rejectScope(c, "API tokens cannot manage credentials")
+			return
+		}
+
+		readOnly := c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead
+
+		switch user.TokenScope {
+		case auth.ScopeFull:
+		case auth.ScopeMealLogWrite:
+			if !readOnly && !hasAnyPrefix(path, mealLogPrefixes) {
+				rejectScope(c, "token scope only allows writing meal logs")
+				return
+			}
+		case auth.ScopeRead:
+			if !readOnly {
+				rejectScope(c, "token scope is read-only")
+				return
+			}
+		default:
+			rejectScope(c, "unknown token scope")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func rejectScope(c *gin.Context, msg string) {
+	apierror.Abort(c, apierror.New(http.StatusForbidden, apierror.CodeInsufficientScope, msg))
+}
+
+func hasAnyPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
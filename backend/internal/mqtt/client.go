@@ -0,0 +1,247 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/config"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// Availability payloads published to the status topic
+const (
+	StatusOnline  = "online"
+	StatusOffline = "offline"
+)
+
+// ErrNotConnected is returned when publishing while the broker is unreachable
+var ErrNotConnected = errors.New("not connected to MQTT broker")
+
+// maxReconnectDelay caps the wait between reconnect attempts
+const maxReconnectDelay = time.Minute
+
+// Client is a minimal MQTT 3.1.1 client that publishes at QoS 0. It keeps
+// reconnecting in the background, and announces itself on <prefix>/status
+// with a retained "online", backed by a last will of "offline", so Home
+// Assistant can tell when the server is gone.
+type Client struct {
+	cfg config.MQTTConfig
+
+	mu   sync.Mutex // guards conn and serialises writes
+	conn net.Conn
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewClient creates a new MQTT client
+func NewClient(cfg config.MQTTConfig) *Client {
+	return &Client{
+		cfg: cfg,
+	}
+}
+
+// StatusTopic is where the client announces its availability
+func (c *Client) StatusTopic() string {
+	return c.cfg.TopicPrefix + "/status"
+}
+
+// Start connects to the broker in the background, reconnecting with
+// backoff until Close is called
+func (c *Client) Start(ctx context.Context) {
+	ctx, c.cancel = context.WithCancel(ctx)
+	c.done = make(chan struct{})
+
+	go func() {
+		defer close(c.done)
+		log := logger.Get()
+
+		delay := time.Second
+		for ctx.Err() == nil {
+			conn, err := c.connect(ctx)
+			if err != nil {
+				log.Warn().Err(err).Str("broker", c.cfg.Broker).Dur("retry_in", delay).Msg("MQTT connection failed")
+				select {
+				case <-ctx.Done():
+				case <-time.After(delay):
+				}
+				delay = min(delay*2, maxReconnectDelay)
+				continue
+			}
+
+			delay = time.Second
+			log.Info().Str("broker", c.cfg.Broker).Msg("Connected to MQTT broker")
+			c.setConn(conn)
+			if err := c.Publish(c.StatusTopic(), []byte(StatusOnline), true); err != nil {
+				log.Warn().Err(err).Msg("Failed to publish MQTT status")
+			}
+
+			err = c.serve(ctx, conn)
+			c.setConn(nil)
+			conn.Close()
+			if ctx.Err() == nil {
+				log.Warn().Err(err).Msg("MQTT connection lost")
+			}
+		}
+	}()
+}
+
+// Close announces that the server is going offline and disconnects
+func (c *Client) Close() {
+	if c.cancel == nil {
+		return
+	}
+
+	// Cancel under the lock so the connection loop can't mistake the
+	// disconnect below for a dropped connection and reconnect
+	c.mu.Lock()
+	c.cancel()
+	if c.conn != nil {
+		c.conn.SetWriteDeadline(time.Now().Add(time.Second))
+		c.conn.Write(publishPacket(c.StatusTopic(), []byte(StatusOffline), true))
+		c.conn.Write([]byte{packetDisconnect << 4, 0})
+		c.conn.Close()
+	}
+	c.mu.Unlock()
+
+	<-c.done
+}
+
+// Publish sends a message at QoS 0. Messages published while the broker
+// is unreachable are dropped.
+func (c *Client) Publish(topic string, payload []byte, retain bool) error {
+	if len(topic)+len(payload)+2 > maxRemainingLength {
+		return fmt.Errorf("message for %s is too large", topic)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return ErrNotConnected
+	}
+	c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	_, err := c.conn.Write(publishPacket(topic, payload, retain))
+	return err
+}
+
+// connect dials the broker and completes the MQTT handshake
+func (c *Client) connect(ctx context.Context) (net.Conn, error) {
+	u, err := url.Parse(c.cfg.Broker)
+	if err != nil {
+		return nil, fmt.Errorf("invalid broker URL: %w", err)
+	}
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	var conn net.Conn
+	switch u.Scheme {
+	case "tcp", "mqtt":
+		conn, err = dialer.DialContext(ctx, "tcp", hostPort(u, "1883"))
+	case "ssl", "tls", "mqtts":
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: u.Hostname()}}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", hostPort(u, "8883"))
+	default:
+		return nil, fmt.Errorf("unsupported broker scheme %q", u.Scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	lastWill := &will{topic: c.StatusTopic(), payload: []byte(StatusOffline)}
+	if _, err := conn.Write(connectPacket(c.cfg.ClientID, c.cfg.Username, c.cfg.Password, c.keepAlive(), lastWill)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	packetType, body, err := readPacket(bufio.NewReader(conn))
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := checkConnack(packetType, body); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+// serve keeps a connection alive with pings until it fails or ctx ends
+func (c *Client) serve(ctx context.Context, conn net.Conn) error {
+	keepAlive := time.Duration(c.keepAlive()) * time.Second
+	readErr := make(chan error, 1)
+
+	// Anything the broker sends counts as a sign of life; a connection
+	// silent for one and a half keep-alive periods is dead
+	go func() {
+		r := bufio.NewReader(conn)
+		for {
+			conn.SetReadDeadline(time.Now().Add(keepAlive * 3 / 2))
+			if _, _, err := readPacket(r); err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(keepAlive * 3 / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-readErr:
+			return err
+		case <-ticker.C:
+			c.mu.Lock()
+			conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			_, err := conn.Write([]byte{packetPingreq << 4, 0})
+			c.mu.Unlock()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (c *Client) setConn(conn net.Conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conn = conn
+}
+
+func (c *Client) keepAlive() uint16 {
+	return uint16(min(max(c.cfg.KeepAlive, 10), 65535))
+}
+
+// hostPort returns the URL host with the default port when none is given
+func hostPort(u *url.URL, defaultPort string) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	return net.JoinHostPort(u.Hostname(), defaultPort)
+}
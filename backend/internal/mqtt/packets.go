@@ -0,0 +1,177 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package mqtt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// MQTT 3.1.1 control packet types
+const (
+	packetConnect    = 1
+	packetConnack    = 2
+	packetPublish    = 3
+	packetPingreq    = 12
+	packetDisconnect = 14
+)
+
+// Connect flags
+const (
+	flagCleanSession = 0x02
+	flagWill         = 0x04
+	flagWillRetain   = 0x20
+	flagPassword     = 0x40
+	flagUsername     = 0x80
+)
+
+// protocolLevel is MQTT 3.1.1
+const protocolLevel = 4
+
+// maxRemainingLength is the largest length the four byte encoding allows
+const maxRemainingLength = 268435455
+
+// connackErrors explains CONNACK return codes
+var connackErrors = map[byte]string{
+	1: "unacceptable protocol version",
+	2: "client identifier rejected",
+	3: "server unavailable",
+	4: "bad user name or password",
+	5: "not authorized",
+}
+
+// will is the message the broker publishes when the connection drops
+type will struct {
+	topic   string
+	payload []byte
+}
+
+// connectPacket encodes a CONNECT packet
+func connectPacket(clientID, username, password string, keepAlive uint16, w *will) []byte {
+	flags := byte(flagCleanSession)
+	var payload []byte
+	payload = appendString(payload, clientID)
+	if w != nil {
+		flags |= flagWill | flagWillRetain
+		payload = appendString(payload, w.topic)
+		payload = appendBytes(payload, w.payload)
+	}
+	if username != "" {
+		flags |= flagUsername
+		payload = appendString(payload, username)
+		if password != "" {
+			flags |= flagPassword
+			payload = appendString(payload, password)
+		}
+	}
+
+	var body []byte
+	body = appendString(body, "MQTT")
+	body = append(body, protocolLevel, flags, byte(keepAlive>>8), byte(keepAlive))
+	body = append(body, payload...)
+	return packet(packetConnect<<4, body)
+}
+
+// publishPacket encodes a QoS 0 PUBLISH packet
+func publishPacket(topic string, payload []byte, retain bool) []byte {
+	header := byte(packetPublish << 4)
+	if retain {
+		header |= 0x01
+	}
+	body := appendString(nil, topic)
+	body = append(body, payload...)
+	return packet(header, body)
+}
+
+// packet prefixes a body with the fixed header
+func packet(header byte, body []byte) []byte {
+	out := []byte{header}
+	out = appendRemainingLength(out, len(body))
+	return append(out, body...)
+}
+
+// appendRemainingLength encodes a length seven bits at a time
+func appendRemainingLength(b []byte, n int) []byte {
+	for {
+		digit := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			digit |= 0x80
+		}
+		b = append(b, digit)
+		if n == 0 {
+			return b
+		}
+	}
+}
+
+func appendString(b []byte, s string) []byte {
+	return appendBytes(b, []byte(s))
+}
+
+func appendBytes(b []byte, data []byte) []byte {
+	b = append(b, byte(len(data)>>8), byte(len(data)))
+	return append(b, data...)
+}
+
+// readPacket reads one control packet, returning its type and body
+func readPacket(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return 0, nil, errors.New("malformed remaining length")
+		}
+		digit, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(digit&0x7f) * multiplier
+		if digit&0x80 == 0 {
+			break
+		}
+		multiplier *= 128
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return header >> 4, body, nil
+}
+
+// checkConnack validates the broker's reply to CONNECT
+func checkConnack(packetType byte, body []byte) error {
+	if packetType != packetConnack || len(body) != 2 {
+		return errors.New("expected CONNACK")
+	}
+	if code := body[1]; code != 0 {
+		if reason, ok := connackErrors[code]; ok {
+			return fmt.Errorf("connection refused: %s", reason)
+		}
+		return fmt.Errorf("connection refused: code %d", code)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/rghsoftware/space-food/internal/events"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// Publisher forwards domain events to MQTT topics under
+// <prefix>/<user_id>/...
+type Publisher struct {
+	client *Client
+	prefix string
+}
+
+// NewPublisher creates a new event publisher
+func NewPublisher(client *Client, prefix string) *Publisher {
+	return &Publisher{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Handle is the event bus subscriber. Meals, auto-paused cooking sessions,
+// thaw reminders and weekly reviews are published as they happen. Each shopping list item has a retained topic holding its current
+// state, cleared when the item is removed, so a kitchen display that
+// connects later still sees the whole list.
+func (p *Publisher) Handle(ctx context.Context, event events.Event) {
+	userTopic := p.prefix + "/" + event.UserID
+
+	var topic string
+	var payload []byte
+	var retain bool
+	var err error
+
+	switch event.Type {
+	case events.MealLogged:
+		topic = userTopic + "/meal_logged"
+		payload, err = json.Marshal(event)
+	case events.CookingSessionPaused:
+		topic = userTopic + "/cooking_session_paused"
+		payload, err = json.Marshal(event)
+	case events.LeftoverThawDue:
+		topic = userTopic + "/leftover_thaw_due"
+		payload, err = json.Marshal(event)
+	case events.WeeklyReviewReady:
+		topic = userTopic + "/weekly_review_ready"
+		payload, err = json.Marshal(event)
+	case events.ShoppingListUpdated:
+		data, ok := event.Data.(events.ShoppingListUpdatedData)
+		if !ok {
+			return
+		}
+		topic = userTopic + "/shopping_list/" + data.ItemID
+		retain = true
+		if data.Action != events.ShoppingListItemRemoved {
+			payload, err = json.Marshal(data)
+		}
+	default:
+		return
+	}
+	if err != nil {
+		logger.Ctx(ctx).Error().Err(err).Str("event", event.Type).Msg("Failed to encode MQTT message")
+		return
+	}
+
+	if err := p.client.Publish(topic, payload, retain); err != nil {
+		logger.Ctx(ctx).Warn().Err(err).Str("topic", topic).Msg("Failed to publish MQTT message")
+	}
+}
@@ -0,0 +1,347 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rghsoftware/space-food/internal/config"
+	"github.com/rghsoftware/space-food/internal/database"
+	"github.com/rghsoftware/space-food/internal/telemetry"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// Job run statuses
+const (
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Job run triggers
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+var (
+	ErrJobNotFound = errors.New("job not found")
+	ErrJobRunning  = errors.New("job is already running")
+)
+
+// JobFunc performs a job's work and returns a short summary of what it did,
+// e.g. "removed 12 entries"
+type JobFunc func(ctx context.Context) (string, error)
+
+// JobStatus describes a registered job and its persisted schedule state
+type JobStatus struct {
+	database.ScheduledJob
+	Running bool `json:"running"`
+}
+
+type job struct {
+	name     string
+	spec     string
+	schedule Schedule
+	run      JobFunc
+}
+
+// Scheduler runs registered jobs on their schedules. Schedules live in the
+// database, so a restart picks up where the previous process left off and
+// a missed run executes once on startup. Claiming a run is an atomic update,
+// so several replicas sharing a database never run the same job twice.
+type Scheduler struct {
+	db  database.Database
+	cfg config.JobsConfig
+
+	mu      sync.Mutex
+	jobs    map[string]*job
+	running map[string]bool
+
+	// runCtx is cancelled by Stop so long jobs can wind down
+	runCtx context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewScheduler creates a new job scheduler with the built-in housekeeping jobs
+func NewScheduler(db database.Database, cfg config.JobsConfig) *Scheduler {
+	s := &Scheduler{
+		db:      db,
+		cfg:     cfg,
+		jobs:    make(map[string]*job),
+		running: make(map[string]bool),
+		runCtx:  context.Background(),
+	}
+
+	s.Register("job-runs-prune", "@daily", func(ctx context.Context) (string, error) {
+		before := time.Now().UTC().AddDate(0, 0, -max(cfg.RunRetention, 1))
+		n, err := db.PurgeJobRuns(ctx, before)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("removed %d runs", n), nil
+	})
+
+	return s
+}
+
+// Register adds a job. Features call it while the router is being set up,
+// before Start. It panics on an invalid schedule or a duplicate name, as
+// both are programming errors.
+func (s *Scheduler) Register(name, spec string, run JobFunc) {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		panic(fmt.Sprintf("scheduler: job %s: %v", name, err))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.jobs[name]; exists {
+		panic(fmt.Sprintf("scheduler: job %s registered twice", name))
+	}
+	s.jobs[name] = &job{name: name, spec: spec, schedule: schedule, run: run}
+}
+
+// Start persists the registered schedules and begins running due jobs in
+// the background
+func (s *Scheduler) Start(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	if n, err := s.db.InterruptJobRuns(ctx, now, "interrupted by server restart"); err != nil {
+		return fmt.Errorf("failed to close interrupted job runs: %w", err)
+	} else if n > 0 {
+		logger.Get().Warn().Int64("runs", n).Msg("Marked interrupted job runs as failed")
+	}
+
+	for _, j := range s.sortedJobs() {
+		err := s.db.SyncScheduledJob(ctx, &database.ScheduledJob{
+			Name:      j.name,
+			Schedule:  j.spec,
+			NextRunAt: j.schedule.Next(now),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save schedule for job %s: %w", j.name, err)
+		}
+	}
+
+	s.runCtx, s.cancel = context.WithCancel(context.Background())
+	interval := time.Duration(max(s.cfg.PollInterval, 1)) * time.Second
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		s.runDue(s.runCtx)
+		for {
+			select {
+			case <-s.runCtx.Done():
+				return
+			case <-ticker.C:
+				s.runDue(s.runCtx)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop stops scheduling and waits for running jobs to finish until ctx expires
+func (s *Scheduler) Stop(ctx context.Context) {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		logger.Get().Warn().Msg("Stopped waiting for background jobs to finish")
+	}
+}
+
+// Jobs lists the registered jobs with their schedule state
+func (s *Scheduler) Jobs(ctx context.Context) ([]*JobStatus, error) {
+	persisted, err := s.db.ListScheduledJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*database.ScheduledJob, len(persisted))
+	for _, p := range persisted {
+		byName[p.Name] = p
+	}
+
+	now := time.Now().UTC()
+	statuses := []*JobStatus{}
+	for _, j := range s.sortedJobs() {
+		status := &JobStatus{Running: s.isRunning(j.name)}
+		if p, ok := byName[j.name]; ok {
+			status.ScheduledJob = *p
+		} else {
+			// Registered but the scheduler has not started yet
+			status.ScheduledJob = database.ScheduledJob{Name: j.name, Schedule: j.spec, NextRunAt: j.schedule.Next(now)}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Trigger runs a job now, outside its schedule, and returns the new run
+func (s *Scheduler) Trigger(ctx context.Context, name string) (*database.JobRun, error) {
+	s.mu.Lock()
+	j, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+
+	return s.execute(ctx, j, TriggerManual)
+}
+
+// runDue starts every job whose next run time has passed
+func (s *Scheduler) runDue(ctx context.Context) {
+	now := time.Now().UTC()
+	for _, j := range s.sortedJobs() {
+		claimed, err := s.db.ClaimScheduledJob(ctx, j.name, now, j.schedule.Next(now))
+		if err != nil {
+			logger.Get().Error().Err(err).Str("job", j.name).Msg("Failed to claim job run")
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		if _, err := s.execute(ctx, j, TriggerSchedule); err != nil && !errors.Is(err, ErrJobRunning) {
+			logger.Get().Error().Err(err).Str("job", j.name).Msg("Failed to start job")
+		}
+	}
+}
+
+// execute records a run and performs it in the background
+func (s *Scheduler) execute(ctx context.Context, j *job, trigger string) (*database.JobRun, error) {
+	s.mu.Lock()
+	if s.running[j.name] {
+		s.mu.Unlock()
+		return nil, ErrJobRunning
+	}
+	s.running[j.name] = true
+	s.mu.Unlock()
+
+	run := &database.JobRun{
+		ID:        uuid.New().String(),
+		JobName:   j.name,
+		Trigger:   trigger,
+		Status:    StatusRunning,
+		StartedAt: time.Now().UTC(),
+	}
+	if err := s.db.CreateJobRun(ctx, run); err != nil {
+		s.clearRunning(j.name)
+		return nil, err
+	}
+
+	// The caller's context may be a request; the run outlives it
+	result := *run
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer s.clearRunning(j.name)
+		s.perform(j, &result)
+	}()
+
+	return run, nil
+}
+
+// perform runs a job to completion and records the outcome
+func (s *Scheduler) perform(j *job, run *database.JobRun) {
+	log := logger.Get()
+
+	// Whatever the job logs says which job and run it belongs to
+	runCtx := logger.With(logger.With(s.runCtx, "job", j.name), "job_run_id", run.ID)
+	runCtx, span := telemetry.Start(runCtx, "job "+j.name)
+	span.SetAttributes("job.name", j.name, "job.trigger", run.Trigger)
+	summary, err := func() (summary string, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		return j.run(runCtx)
+	}()
+	span.End(err)
+
+	// Record the outcome even when the run was cancelled by Stop
+	ctx := context.Background()
+
+	finished := time.Now().UTC()
+	run.FinishedAt = &finished
+	run.Result = summary
+	run.Status = StatusSucceeded
+	if err != nil {
+		run.Status = StatusFailed
+		run.Error = err.Error()
+		log.Error().Err(err).Str("job", j.name).Msg("Job failed")
+	} else {
+		log.Info().Str("job", j.name).Str("result", summary).Dur("took", finished.Sub(run.StartedAt)).Msg("Job finished")
+	}
+
+	if err := s.db.FinishJobRun(ctx, run); err != nil {
+		log.Error().Err(err).Str("job", j.name).Msg("Failed to record job run")
+	}
+	if err := s.db.UpdateScheduledJobStatus(ctx, j.name, run.StartedAt, run.Status); err != nil {
+		log.Error().Err(err).Str("job", j.name).Msg("Failed to record job status")
+	}
+}
+
+func (s *Scheduler) isRunning(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running[name]
+}
+
+func (s *Scheduler) clearRunning(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.running, name)
+}
+
+// sortedJobs returns the registered jobs in name order
+func (s *Scheduler) sortedJobs() []*job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := make([]*job, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		jobs = append(jobs, j)
+	}
+	sort.Slice(jobs, func(a, b int) bool { return jobs[a].name < jobs[b].name })
+	return jobs
+}
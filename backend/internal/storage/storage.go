@@ -0,0 +1,95 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package storage keeps uploaded files on local disk or in an S3-compatible
+// object store such as MinIO.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/config"
+)
+
+// ErrNotFound is returned when no file is stored under a key
+var ErrNotFound = errors.New("file not found")
+
+// ErrInvalidKey is returned for keys that are empty, absolute or climb out
+// of the storage root
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// Provider stores files under slash-separated keys such as
+// images/<user>/<id>/medium.jpg
+type Provider interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes a file; deleting a missing file is not an error
+	Delete(ctx context.Context, key string) error
+	// List returns the keys starting with prefix
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// Signer is implemented by providers whose files clients can fetch
+// directly with a time-limited URL
+type Signer interface {
+	SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+// New creates the provider selected by storage.type
+func New(cfg config.StorageConfig) (Provider, error) {
+	switch cfg.Type {
+	case "", "local":
+		return NewLocal(cfg.LocalPath), nil
+	case "s3":
+		return NewS3(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported storage type: %s", cfg.Type)
+	}
+}
+
+// DeleteAll removes every file whose key starts with prefix
+func DeleteAll(ctx context.Context, p Provider, prefix string) error {
+	keys, err := p.List(ctx, prefix)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := p.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validKey rejects keys that could escape the storage root
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, `\`) {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
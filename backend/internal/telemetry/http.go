@@ -0,0 +1,88 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package telemetry
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghsoftware/space-food/internal/apierror"
+)
+
+// Middleware traces each request and records its latency. Routes are
+// labelled by their pattern, such as /api/v1/recipes/:id, to keep the
+// number of series bounded.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		method := c.Request.Method
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		ctx := Extract(c.Request.Context(), c.GetHeader("traceparent"))
+		ctx, span := StartKind(ctx, method+" "+route, KindServer)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(
+			"http.request.method", method,
+			"http.route", route,
+			"http.response.status_code", status,
+			"request_id", c.GetString("request_id"),
+		)
+		var err error
+		if status >= http.StatusInternalServerError {
+			err = errStatus(status)
+		}
+		span.End(err)
+
+		httpRequests.Inc(method, route, strconv.Itoa(status))
+		httpDuration.Observe(time.Since(start).Seconds(), method, route)
+	}
+}
+
+// MetricsHandler serves the default registry to Prometheus. When token is
+// set, scrapers must send it as a bearer token.
+func MetricsHandler(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token != "" {
+			given := c.GetHeader("Authorization")
+			if subtle.ConstantTimeCompare([]byte(given), []byte("Bearer "+token)) != 1 {
+				apierror.Unauthorized(c, "a valid metrics token is required")
+				return
+			}
+		}
+		c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		c.Status(http.StatusOK)
+		Default.WriteTo(c.Writer)
+	}
+}
+
+type errStatus int
+
+func (e errStatus) Error() string {
+	return "HTTP " + strconv.Itoa(int(e))
+}
@@ -0,0 +1,105 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package telemetry
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Metrics recorded by the server
+var (
+	httpRequests = Default.NewCounterVec("spacefood_http_requests_total",
+		"HTTP requests handled.", "method", "route", "status")
+	httpDuration = Default.NewHistogramVec("spacefood_http_request_duration_seconds",
+		"HTTP request latency in seconds.", DefaultBuckets, "method", "route")
+	dbQueryDuration = Default.NewHistogramVec("spacefood_db_query_duration_seconds",
+		"Database query latency in seconds.", DefaultBuckets, "operation")
+	dbQueryErrors = Default.NewCounterVec("spacefood_db_query_errors_total",
+		"Database queries that failed.", "operation")
+	aiRequests = Default.NewCounterVec("spacefood_ai_requests_total",
+		"AI provider requests.", "provider", "operation", "outcome")
+	aiDuration = Default.NewHistogramVec("spacefood_ai_request_duration_seconds",
+		"AI provider request latency in seconds.", []float64{0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 60, 120}, "provider", "operation")
+	aiTokens = Default.NewCounterVec("spacefood_ai_tokens_total",
+		"Tokens sent to and received from AI providers.", "provider", "direction")
+)
+
+func init() {
+	Default.NewGaugeFunc("go_goroutines", "Number of goroutines that currently exist.", nil,
+		func(emit func(float64, ...string)) {
+			emit(float64(runtime.NumGoroutine()))
+		})
+	Default.NewGaugeFunc("go_memstats_heap_alloc_bytes", "Bytes of allocated heap objects.", nil,
+		func(emit func(float64, ...string)) {
+			var m runtime.MemStats
+			runtime.ReadMemStats(&m)
+			emit(float64(m.HeapAlloc))
+		})
+}
+
+// ObserveDBQuery records the outcome of a database query
+func ObserveDBQuery(operation string, elapsed time.Duration, err error) {
+	dbQueryDuration.Observe(elapsed.Seconds(), operation)
+	if err != nil {
+		dbQueryErrors.Inc(operation)
+	}
+}
+
+// SQLOperation names a statement by its first keyword, such as SELECT
+func SQLOperation(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "UNKNOWN"
+	}
+	return strings.ToUpper(fields[0])
+}
+
+// AICall times one request to an AI provider
+type AICall struct {
+	provider  string
+	operation string
+	start     time.Time
+	span      *Span
+}
+
+// StartAICall begins timing an AI provider request; call End with the
+// token counts from the response
+func StartAICall(ctx context.Context, provider, operation string) (context.Context, *AICall) {
+	ctx, span := StartKind(ctx, "ai "+operation, KindClient)
+	span.SetAttributes("gen_ai.system", provider, "gen_ai.operation.name", operation)
+	return ctx, &AICall{provider: provider, operation: operation, start: time.Now(), span: span}
+}
+
+// End records the request's duration, tokens and outcome
+func (a *AICall) End(inputTokens, outputTokens int64, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	aiRequests.Inc(a.provider, a.operation, outcome)
+	aiDuration.Observe(time.Since(a.start).Seconds(), a.provider, a.operation)
+	aiTokens.Add(float64(inputTokens), a.provider, "input")
+	aiTokens.Add(float64(outputTokens), a.provider, "output")
+
+	a.span.SetAttributes("gen_ai.usage.input_tokens", inputTokens, "gen_ai.usage.output_tokens", outputTokens)
+	a.span.End(err)
+}
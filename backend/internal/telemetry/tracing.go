@@ -0,0 +1,408 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rghsoftware/space-food/internal/config"
+	"github.com/rghsoftware/space-food/pkg/logger"
+)
+
+// Span kinds, as numbered by OTLP
+const (
+	KindInternal = 1
+	KindServer   = 2
+	KindClient   = 3
+)
+
+// Export batching
+const (
+	exportQueueSize = 2048
+	exportBatchSize = 256
+	exportInterval  = 5 * time.Second
+)
+
+// spanContext identifies a span and is what propagates to child spans
+type spanContext struct {
+	traceID [16]byte
+	spanID  [8]byte
+	sampled bool
+}
+
+type spanContextKey struct{}
+
+// Span is one timed operation in a trace. A nil *Span, returned when
+// tracing is off or the trace is not sampled, ignores every call.
+type Span struct {
+	tracer   *Tracer
+	sc       spanContext
+	parentID [8]byte
+	name     string
+	kind     int
+	start    time.Time
+
+	mu    sync.Mutex
+	end   time.Time
+	attrs map[string]any
+	err   string
+	ended bool
+}
+
+// Tracer records spans and exports them to an OpenTelemetry collector
+type Tracer struct {
+	cfg        config.TracingConfig
+	httpClient *http.Client
+	queue      chan *Span
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// current is the tracer spans are started with; nil while tracing is off
+var current atomic.Pointer[Tracer]
+
+// NewTracer creates a tracer exporting to cfg.Endpoint
+func NewTracer(cfg config.TracingConfig) *Tracer {
+	return &Tracer{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		queue:      make(chan *Span, exportQueueSize),
+	}
+}
+
+// Start begins exporting in the background and makes t the tracer used by
+// Start and StartKind
+func (t *Tracer) Start(ctx context.Context) {
+	ctx, t.cancel = context.WithCancel(ctx)
+	t.done = make(chan struct{})
+	current.Store(t)
+
+	go func() {
+		defer close(t.done)
+		ticker := time.NewTicker(exportInterval)
+		defer ticker.Stop()
+
+		batch := make([]*Span, 0, exportBatchSize)
+		flush := func() {
+			if len(batch) > 0 {
+				t.export(batch)
+				batch = batch[:0]
+			}
+		}
+		for {
+			select {
+			case span := <-t.queue:
+				batch = append(batch, span)
+				if len(batch) >= exportBatchSize {
+					flush()
+				}
+			case <-ticker.C:
+				flush()
+			case <-ctx.Done():
+				// Drain what was queued before shutdown
+				for {
+					select {
+					case span := <-t.queue:
+						batch = append(batch, span)
+					default:
+						flush()
+						return
+					}
+				}
+			}
+		}
+	}()
+}
+
+// Shutdown stops recording spans and exports the ones already ended
+func (t *Tracer) Shutdown(ctx context.Context) {
+	current.CompareAndSwap(t, nil)
+	if t.cancel == nil {
+		return
+	}
+	t.cancel()
+	select {
+	case <-t.done:
+	case <-ctx.Done():
+	}
+}
+
+// Start begins an internal span as a child of the span in ctx
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	return StartKind(ctx, name, KindInternal)
+}
+
+// StartKind begins a span of the given kind as a child of the span in ctx
+func StartKind(ctx context.Context, name string, kind int) (context.Context, *Span) {
+	t := current.Load()
+	if t == nil {
+		return ctx, nil
+	}
+
+	parent, hasParent := ctx.Value(spanContextKey{}).(spanContext)
+	sc := spanContext{spanID: newSpanID()}
+	if hasParent {
+		sc.traceID = parent.traceID
+		sc.sampled = parent.sampled
+	} else {
+		sc.traceID = newTraceID()
+		sc.sampled = t.sample(sc.traceID)
+	}
+	ctx = context.WithValue(ctx, spanContextKey{}, sc)
+	if !sc.sampled {
+		return ctx, nil
+	}
+
+	span := &Span{tracer: t, sc: sc, name: name, kind: kind, start: time.Now()}
+	if hasParent {
+		span.parentID = parent.spanID
+	}
+	return ctx, span
+}
+
+// Extract continues a trace from a W3C traceparent header, so spans join
+// the trace of a caller such as a reverse proxy
+func Extract(ctx context.Context, traceparent string) context.Context {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) != 4 || parts[0] != "00" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return ctx
+	}
+	var sc spanContext
+	if _, err := hex.Decode(sc.traceID[:], []byte(parts[1])); err != nil || sc.traceID == [16]byte{} {
+		return ctx
+	}
+	if _, err := hex.Decode(sc.spanID[:], []byte(parts[2])); err != nil || sc.spanID == [8]byte{} {
+		return ctx
+	}
+	flags, err := strconv.ParseUint(parts[3], 16, 8)
+	if err != nil {
+		return ctx
+	}
+	sc.sampled = flags&1 == 1
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// TraceID returns the hex ID of the trace in ctx, or "" when there is none
+func TraceID(ctx context.Context) string {
+	sc, ok := ctx.Value(spanContextKey{}).(spanContext)
+	if !ok {
+		return ""
+	}
+	return hex.EncodeToString(sc.traceID[:])
+}
+
+// SetAttributes adds key/value pairs to the span; values may be strings,
+// bools, integers or floats
+func (s *Span) SetAttributes(kv ...any) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.attrs == nil {
+		s.attrs = map[string]any{}
+	}
+	for i := 0; i+1 < len(kv); i += 2 {
+		if key, ok := kv[i].(string); ok {
+			s.attrs[key] = kv[i+1]
+		}
+	}
+}
+
+// End finishes the span, marking it failed when err is not nil, and queues
+// it for export
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	if err != nil {
+		s.err = err.Error()
+	}
+	s.mu.Unlock()
+
+	select {
+	case s.tracer.queue <- s:
+	default:
+		// Drop spans rather than block requests when the collector lags
+	}
+}
+
+// sample decides whether a new trace is recorded, consistently by trace ID
+func (t *Tracer) sample(traceID [16]byte) bool {
+	ratio := t.cfg.SampleRatio
+	if ratio >= 1 {
+		return true
+	}
+	if ratio <= 0 {
+		return false
+	}
+	return float64(binary.BigEndian.Uint64(traceID[8:])) < ratio*(1<<64)
+}
+
+// export sends a batch of spans to the collector
+func (t *Tracer) export(spans []*Span) {
+	body, err := json.Marshal(t.encode(spans))
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), t.httpClient.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(t.cfg.Endpoint, "/")+"/v1/traces", bytes.NewReader(body))
+	if err != nil {
+		logger.Get().Warn().Err(err).Msg("Invalid trace exporter endpoint")
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range t.cfg.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		logger.Get().Warn().Err(err).Int("spans", len(spans)).Msg("Failed to export traces")
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		logger.Get().Warn().Int("status", resp.StatusCode).Int("spans", len(spans)).Msg("Trace collector rejected spans")
+	}
+}
+
+// OTLP/JSON payload types
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpSpan struct {
+		TraceID           string          `json:"traceId"`
+		SpanID            string          `json:"spanId"`
+		ParentSpanID      string          `json:"parentSpanId,omitempty"`
+		Name              string          `json:"name"`
+		Kind              int             `json:"kind"`
+		StartTimeUnixNano string          `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+		Attributes        []otlpAttribute `json:"attributes,omitempty"`
+		Status            otlpStatus      `json:"status"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code"` // 0 unset, 2 error
+		Message string `json:"message,omitempty"`
+	}
+	otlpAttribute struct {
+		Key   string         `json:"key"`
+		Value map[string]any `json:"value"`
+	}
+)
+
+func (t *Tracer) encode(spans []*Span) otlpRequest {
+	out := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		s.mu.Lock()
+		span := otlpSpan{
+			TraceID:           hex.EncodeToString(s.sc.traceID[:]),
+			SpanID:            hex.EncodeToString(s.sc.spanID[:]),
+			Name:              s.name,
+			Kind:              s.kind,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+		}
+		if s.parentID != [8]byte{} {
+			span.ParentSpanID = hex.EncodeToString(s.parentID[:])
+		}
+		for _, key := range sortedKeys(s.attrs) {
+			span.Attributes = append(span.Attributes, attribute(key, s.attrs[key]))
+		}
+		if s.err != "" {
+			span.Status = otlpStatus{Code: 2, Message: s.err}
+		}
+		s.mu.Unlock()
+		out = append(out, span)
+	}
+
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: []otlpAttribute{attribute("service.name", t.cfg.ServiceName)}},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: "space-food"}, Spans: out}},
+	}}}
+}
+
+func attribute(key string, value any) otlpAttribute {
+	var v map[string]any
+	switch value := value.(type) {
+	case string:
+		v = map[string]any{"stringValue": value}
+	case bool:
+		v = map[string]any{"boolValue": value}
+	case int:
+		v = map[string]any{"intValue": strconv.Itoa(value)}
+	case int64:
+		v = map[string]any{"intValue": strconv.FormatInt(value, 10)}
+	case float64:
+		v = map[string]any{"doubleValue": value}
+	default:
+		v = map[string]any{"stringValue": fmt.Sprint(value)}
+	}
+	return otlpAttribute{Key: key, Value: v}
+}
+
+func newTraceID() [16]byte {
+	var id [16]byte
+	rand.Read(id[:])
+	return id
+}
+
+func newSpanID() [8]byte {
+	var id [8]byte
+	rand.Read(id[:])
+	return id
+}
@@ -0,0 +1,142 @@
+/*
+ * Space Food - Self-Hosted Meal Planning Application
+ * Copyright (C) 2025 RGH Software
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program. If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package web serves the web app built into the binary, so one binary is a
+// complete deployment. The app's build is copied into dist before
+// compiling, e.g. with `flutter build web --output backend/internal/web/dist`;
+// a binary built without one serves only the API.
+package web
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"io/fs"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed all:dist
+var dist embed.FS
+
+// immutable lets browsers keep an asset requested with its version for a
+// year without asking again; the version changes with the content
+const immutable = "public, max-age=31536000, immutable"
+
+// assetReference matches the files index.html loads, so it can ask for
+// them by version
+var assetReference = regexp.MustCompile(`(\s(?:src|href)=")([^"?#:]+)(")`)
+
+// asset is one file of the build
+type asset struct {
+	data    []byte
+	version string // a hash of the content
+}
+
+// App serves the web app
+type App struct {
+	assets map[string]*asset // by path within the build
+	index  *asset            // index.html, with its assets versioned
+}
+
+// Load reads the build embedded in the binary, or returns nil when the
+// binary was built without one
+func Load() *App {
+	root, err := fs.Sub(dist, "dist")
+	if err != nil {
+		return nil
+	}
+	app := &App{assets: map[string]*asset{}}
+	fs.WalkDir(root, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || strings.HasPrefix(path.Base(name), ".") {
+			return nil
+		}
+		data, err := fs.ReadFile(root, name)
+		if err != nil {
+			return nil
+		}
+		app.assets[name] = newAsset(data)
+		return nil
+	})
+
+	index, ok := app.assets["index.html"]
+	if !ok {
+		return nil
+	}
+	// Point index.html at versioned URLs, which browsers can cache for good
+	app.index = newAsset(assetReference.ReplaceAllFunc(index.data, func(match []byte) []byte {
+		parts := assetReference.FindSubmatch(match)
+		name := strings.TrimPrefix(strings.TrimPrefix(string(parts[2]), "./"), "/")
+		referenced, ok := app.assets[name]
+		if !ok || name == "index.html" {
+			return match
+		}
+		return []byte(string(parts[1]) + string(parts[2]) + "?v=" + referenced.version + string(parts[3]))
+	}))
+	return app
+}
+
+func newAsset(data []byte) *asset {
+	sum := sha256.Sum256(data)
+	return &asset{data: data, version: hex.EncodeToString(sum[:8])}
+}
+
+// Handler serves the app's files, and index.html for any other path so the
+// app can route it itself. API paths, methods other than GET and HEAD, and
+// missing files, which have an extension, are passed to notFound.
+func (a *App) Handler(notFound gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		urlPath := c.Request.URL.Path
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead ||
+			urlPath == "/api" || strings.HasPrefix(urlPath, "/api/") {
+			notFound(c)
+			return
+		}
+
+		name := strings.TrimPrefix(urlPath, "/")
+		if item, ok := a.assets[name]; ok && name != "index.html" {
+			if c.Query("v") == item.version {
+				c.Header("Cache-Control", immutable)
+			} else {
+				c.Header("Cache-Control", "no-cache")
+			}
+			serve(c, name, item)
+			return
+		}
+		if path.Ext(name) != "" && name != "index.html" {
+			notFound(c)
+			return
+		}
+		// The page must be checked on every load to pick up a new release
+		c.Header("Cache-Control", "no-cache")
+		serve(c, "index.html", a.index)
+	}
+}
+
+// serve writes a file, answering conditional requests from its version
+func serve(c *gin.Context, name string, item *asset) {
+	c.Header("ETag", `"`+item.version+`"`)
+	c.Header("X-Content-Type-Options", "nosniff")
+	http.ServeContent(c.Writer, c.Request, name, time.Time{}, bytes.NewReader(item.data))
+}
@@ -19,6 +19,7 @@
 package logger
 
 import (
+	"context"
 	"os"
 
 	"github.com/rs/zerolog"
@@ -27,7 +28,16 @@ import (
 
 // Init initializes the logger
 func Init(level string, format string) {
-	// Set log level
+	SetLevel(level)
+
+	// Set format
+	if format == "console" {
+		log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+	}
+}
+
+// SetLevel changes the minimum level logged; unknown levels mean info
+func SetLevel(level string) {
 	switch level {
 	case "debug":
 		zerolog.SetGlobalLevel(zerolog.DebugLevel)
@@ -40,14 +50,25 @@ func Init(level string, format string) {
 	default:
 		zerolog.SetGlobalLevel(zerolog.InfoLevel)
 	}
-
-	// Set format
-	if format == "console" {
-		log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
-	}
 }
 
 // Get returns a logger instance
 func Get() *zerolog.Logger {
 	return &log.Logger
 }
+
+// Ctx returns the logger carried by ctx, with the fields of the request or
+// job it belongs to, or the global logger when it carries none. Code
+// running on behalf of a request logs through it so its lines can be traced
+// back to the request.
+func Ctx(ctx context.Context) *zerolog.Logger {
+	if l := zerolog.Ctx(ctx); l.GetLevel() != zerolog.Disabled {
+		return l
+	}
+	return Get()
+}
+
+// With returns a copy of ctx whose logger also adds a field to every line
+func With(ctx context.Context, key, value string) context.Context {
+	return Ctx(ctx).With().Str(key, value).Logger().WithContext(ctx)
+}